@@ -52,6 +52,41 @@ const (
 	// Admin access
 	GetUsers int64 = 0x0000000000001000
 	EditUser int64 = 0x0000000000002000
+
+	GetProposals   int64 = 0x0000000000010000
+	CreateProposal int64 = 0x0000000000020000
+	EditProposal   int64 = 0x0000000000040000
+	RemoveProposal int64 = 0x0000000000080000
+
+	GetInvoices   int64 = 0x0000000000100000
+	CreateInvoice int64 = 0x0000000000200000
+	EditInvoice   int64 = 0x0000000000400000
+	RemoveInvoice int64 = 0x0000000000800000
+
+	GetSettings  int64 = 0x0000000001000000
+	EditSettings int64 = 0x0000000002000000
+
+	GetContracts   int64 = 0x0000000004000000
+	CreateContract int64 = 0x0000000008000000
+	EditContract   int64 = 0x0000000010000000
+	RemoveContract int64 = 0x0000000020000000
+
+	GetQuotations   int64 = 0x0000000040000000
+	CreateQuotation int64 = 0x0000000080000000
+	EditQuotation   int64 = 0x0000000100000000
+	RemoveQuotation int64 = 0x0000000200000000
+
+	GetClients   int64 = 0x0000000400000000
+	CreateClient int64 = 0x0000000800000000
+	EditClient   int64 = 0x0000001000000000
+	RemoveClient int64 = 0x0000002000000000
+
+	GetConsultations   int64 = 0x0000004000000000
+	CreateConsultation int64 = 0x0000008000000000
+	EditConsultation   int64 = 0x0000010000000000
+
+	GetForms  int64 = 0x0000020000000000
+	EditForms int64 = 0x0000040000000000
 )
 
 const UserAccess int64 = GetNotes |
@@ -63,7 +98,34 @@ const UserAccess int64 = GetNotes |
 	GetFiles |
 	UploadFile |
 	DownloadFile |
-	RemoveFile
+	RemoveFile |
+	GetProposals |
+	CreateProposal |
+	EditProposal |
+	RemoveProposal |
+	GetInvoices |
+	CreateInvoice |
+	EditInvoice |
+	RemoveInvoice |
+	GetSettings |
+	EditSettings |
+	GetContracts |
+	CreateContract |
+	EditContract |
+	RemoveContract |
+	GetQuotations |
+	CreateQuotation |
+	EditQuotation |
+	RemoveQuotation |
+	GetClients |
+	CreateClient |
+	EditClient |
+	RemoveClient |
+	GetConsultations |
+	CreateConsultation |
+	EditConsultation |
+	GetForms |
+	EditForms
 
 const AdminAccess int64 = UserAccess |
 	GetUsers |
@@ -71,6 +133,42 @@ const AdminAccess int64 = UserAccess |
 
 const NewUserAccess int64 = AdminAccess
 
+// Role is a user's role within an agency
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+)
+
+// memberAccess is the access of agency members, who cannot delete the
+// agency's records or manage its settings and forms
+const memberAccess int64 = UserAccess &^ (RemoveProposal |
+	RemoveInvoice |
+	RemoveContract |
+	RemoveQuotation |
+	RemoveClient |
+	GetSettings |
+	EditSettings |
+	EditForms)
+
+// RoleAccess returns the access a role grants within an agency
+func RoleAccess(role Role) int64 {
+	switch role {
+	case RoleOwner, RoleAdmin:
+		return UserAccess
+	case RoleMember:
+		return memberAccess
+	}
+	return 0
+}
+
+// Allows reports whether the role grants the access within an agency
+func (r Role) Allows(access int64) bool {
+	return RoleAccess(r)&access == access
+}
+
 func (s *Service) Auth(token string, access int64) (*AccessTokenClaims, error) {
 	user, err := s.ValidateAccessToken(token)
 	if err != nil {
@@ -95,40 +193,96 @@ func (s *Service) UpdateAccess(userAccess int64, access int64) (int64, error) {
 	return userAccess, nil
 }
 
-// Here you can implement ABAC (Attribute-Based Access Control) to check if the user has the right attributes to access the resource
+// ABAC (Attribute-Based Access Control) checks the attributes of the user
+// against those of the resource they act on, on top of their access
+
+// UserAttr holds the attributes of the user acting on a resource
 type UserAttr struct {
-	Department string
-	Position   string
+	ID       uuid.UUID
+	AgencyID uuid.UUID
+	Role     Role
 }
 
-func (s *Service) HasAccessABAC(access int64, userAccess int64, userAttr UserAttr) bool {
+// Resource holds the attributes of the resource being acted on. Personal
+// resources, such as notes and files, belong to no agency.
+type Resource struct {
+	OwnerID  uuid.UUID
+	AgencyID uuid.UUID
+}
+
+// readAccess is the access that only reads resources
+const readAccess int64 = GetNotes |
+	GetEmails |
+	GetFiles |
+	DownloadFile |
+	GetProposals |
+	GetInvoices |
+	GetSettings |
+	GetContracts |
+	GetQuotations |
+	GetClients |
+	GetConsultations |
+	GetForms
+
+func (s *Service) HasAccessABAC(access int64, userAccess int64, userAttr UserAttr, resource Resource) bool {
 	if userAccess == 0 {
 		return false
 	}
 	if userAccess&access != access {
 		return false
 	}
-	return CheckUserAttr(access, &userAttr)
+	return CheckUserAttr(access, &userAttr, &resource)
 }
 
-func CheckUserAttr(access int64, userAttr *UserAttr) bool {
-	if access == EditNote {
-		return userAttr.Department == "IT" && userAttr.Position == "Developer"
+// CheckUserAttr reports whether the user's attributes allow the access to
+// the resource. Personal resources are only open to their owner. Agency
+// resources are open to the agency's members as far as their role allows,
+// though members may only change the records they own.
+func CheckUserAttr(access int64, userAttr *UserAttr, resource *Resource) bool {
+	if resource.AgencyID == uuid.Nil {
+		return resource.OwnerID != uuid.Nil && resource.OwnerID == userAttr.ID
 	}
-	if access == RemoveNote {
-		return userAttr.Department == "IT" && userAttr.Position == "Admin"
+	if userAttr.AgencyID != resource.AgencyID || !userAttr.Role.Allows(access) {
+		return false
+	}
+	if userAttr.Role == RoleMember && access&^readAccess != 0 {
+		return resource.OwnerID == userAttr.ID
 	}
 	return true
 }
 
+// Authorize is the check services make before acting on a resource. It
+// returns a ForbiddenError when the user's attributes do not allow the
+// access, such as for another user's or another agency's resource.
+func Authorize(access int64, userAttr UserAttr, resource Resource) error {
+	if !CheckUserAttr(access, &userAttr, &resource) {
+		return pkg.ForbiddenError{Err: fmt.Errorf("user %s does not have access to this resource", userAttr.ID)}
+	}
+	return nil
+}
+
+// Second factors a session token is completed with
+const (
+	TwoFactorSMS     = "sms"
+	TwoFactorTOTP    = "totp"
+	TwoFactorPasskey = "passkey"
+)
+
 type SessionTokenClaims struct {
 	ID    uuid.UUID `json:"id"`
 	Phone string    `json:"phone"`
+	// The second factor the user must complete sign-in with
+	Method string `json:"method"`
+	// TokenID identifies the token, so it can be used only once. Tokens
+	// issued before it was added have none.
+	TokenID   uuid.UUID `json:"jti"`
+	ExpiresAt time.Time `json:"exp"`
 }
 
 func (s *Service) GenerateSessionToken(
 	userID string,
 	phone string,
+	method string,
 ) (string, error) {
 	// Load the private key
 	privateKey, err := getPrivateKey()
@@ -143,11 +297,17 @@ func (s *Service) GenerateSessionToken(
 	if err != nil {
 		return "", fmt.Errorf("error parsing private key: %w", err)
 	}
+	tokenID, err := uuid.NewRandom()
+	if err != nil {
+		return "", fmt.Errorf("error generating token ID: %w", err)
+	}
 	// Create the token
 	token := jwt.NewWithClaims(&jwt.SigningMethodEd25519{}, jwt.MapClaims{
-		"id":    userID,
-		"phone": phone,
-		"exp":   time.Now().Add(s.cfg.AccessTokenExp).Unix(),
+		"id":     userID,
+		"phone":  phone,
+		"method": method,
+		"jti":    tokenID.String(),
+		"exp":    time.Now().Add(s.cfg.AccessTokenExp).Unix(),
 	})
 	// Sign the token
 	tokenString, err := token.SignedString(privateKeyParsed)
@@ -183,20 +343,44 @@ func extractSessionTokenClaims(claims jwt.MapClaims) (*SessionTokenClaims, error
 		return nil, fmt.Errorf("claims missing 'phone' field: %w", errors.New("invalid claims"))
 	}
 
+	// Session tokens issued before TOTP was added are all for SMS
+	method, _ := claims["method"].(string)
+	if method == "" {
+		method = TwoFactorSMS
+	}
+
+	var tokenID uuid.UUID
+	if jti, ok := claims["jti"].(string); ok {
+		tokenID, err = uuid.Parse(jti)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing token ID: %w", err)
+		}
+	}
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return nil, fmt.Errorf("claims missing 'exp' field: %w", errors.New("invalid claims"))
+	}
+
 	sessionTokenClaims := SessionTokenClaims{
-		ID:    UUID,
-		Phone: phone,
+		ID:        UUID,
+		Phone:     phone,
+		Method:    method,
+		TokenID:   tokenID,
+		ExpiresAt: exp.Time,
 	}
 	return &sessionTokenClaims, nil
 }
 
 func (s *Service) GenerateTokens(
 	refreshTokenID string,
+	sessionID string,
 	userID string,
 	access int64,
 	avatar string,
 	email string,
 	subscriptionActive bool,
+	agencyID string,
+	agencyRole Role,
 ) (string, string, error) {
 	// Load the private key
 	privateKey, err := getPrivateKey()
@@ -218,6 +402,9 @@ func (s *Service) GenerateTokens(
 		"avatar":              avatar,
 		"email":               email,
 		"subscription_active": subscriptionActive,
+		"agency_id":           agencyID,
+		"agency_role":         string(agencyRole),
+		"sid":                 sessionID,
 		"exp":                 time.Now().Add(s.cfg.AccessTokenExp).Unix(),
 	})
 	// Sign the token
@@ -245,6 +432,18 @@ type AccessTokenClaims struct {
 	Avatar             string    `json:"avatar"`
 	Email              string    `json:"email"`
 	SubscriptionActive bool      `json:"subscription_active"`
+	// The agency the user is working in and their role there, if any
+	AgencyID   uuid.UUID `json:"agency_id"`
+	AgencyRole Role      `json:"agency_role"`
+	// The session the token was issued for, so revoking the session ends it
+	SessionID uuid.UUID `json:"sid"`
+	// The API key the request was made with, if it was not made with a token
+	APIKeyID uuid.UUID `json:"-"`
+}
+
+// Attr returns the attributes of the token's user
+func (c *AccessTokenClaims) Attr() UserAttr {
+	return UserAttr{ID: c.ID, AgencyID: c.AgencyID, Role: c.AgencyRole}
 }
 
 func (s *Service) ValidateAccessToken(tokenString string) (*AccessTokenClaims, error) {
@@ -371,5 +570,22 @@ func extractAccessTokenClaims(claims jwt.MapClaims) (*AccessTokenClaims, error)
 		Email:              email,
 		SubscriptionActive: subscriptionActive,
 	}
+	// Agency claims are optional: users without an agency, and tokens issued
+	// before agencies were added to them, have none
+	if agencyID, _ := claims["agency_id"].(string); agencyID != "" {
+		accessTokenClaims.AgencyID, err = uuid.Parse(agencyID)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing agency ID: %w", err)
+		}
+		role, _ := claims["agency_role"].(string)
+		accessTokenClaims.AgencyRole = Role(role)
+	}
+	// Tokens issued before sessions were added have no session
+	if sessionID, _ := claims["sid"].(string); sessionID != "" {
+		accessTokenClaims.SessionID, err = uuid.Parse(sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing session ID: %w", err)
+		}
+	}
 	return &accessTokenClaims, nil
 }
\ No newline at end of file
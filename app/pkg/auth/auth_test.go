@@ -0,0 +1,98 @@
+package auth_test
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRoleAllows(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		role   auth.Role
+		access int64
+		want   bool
+	}{
+		{auth.RoleOwner, auth.RemoveClient, true},
+		{auth.RoleAdmin, auth.EditSettings, true},
+		{auth.RoleAdmin, auth.GetUsers, false},
+		{auth.RoleMember, auth.CreateProposal, true},
+		{auth.RoleMember, auth.GetClients | auth.EditClient, true},
+		{auth.RoleMember, auth.RemoveProposal, false},
+		{auth.RoleMember, auth.GetClients | auth.RemoveClient, false},
+		{auth.RoleMember, auth.EditForms, false},
+		{auth.Role("guest"), auth.GetNotes, false},
+	}
+	for _, tt := range tests {
+		if got := tt.role.Allows(tt.access); got != tt.want {
+			t.Errorf("Role(%q).Allows(%#x) = %v, want %v", tt.role, tt.access, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	t.Parallel()
+	owner := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	other := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	agency := uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	otherAgency := uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+	tests := []struct {
+		name     string
+		access   int64
+		user     auth.UserAttr
+		resource auth.Resource
+		allowed  bool
+	}{
+		{"personal owner", auth.EditNote, auth.UserAttr{ID: owner}, auth.Resource{OwnerID: owner}, true},
+		{"personal other user", auth.GetNotes, auth.UserAttr{ID: other}, auth.Resource{OwnerID: owner}, false},
+		{"personal other user in same agency", auth.DownloadFile, auth.UserAttr{ID: other, AgencyID: agency, Role: auth.RoleOwner}, auth.Resource{OwnerID: owner}, false},
+		{"personal without owner", auth.GetFiles, auth.UserAttr{}, auth.Resource{}, false},
+		{"agency member reads", auth.GetClients, auth.UserAttr{ID: other, AgencyID: agency, Role: auth.RoleMember}, auth.Resource{OwnerID: owner, AgencyID: agency}, true},
+		{"agency member edits own", auth.EditClient, auth.UserAttr{ID: owner, AgencyID: agency, Role: auth.RoleMember}, auth.Resource{OwnerID: owner, AgencyID: agency}, true},
+		{"agency member edits other's", auth.EditClient, auth.UserAttr{ID: other, AgencyID: agency, Role: auth.RoleMember}, auth.Resource{OwnerID: owner, AgencyID: agency}, false},
+		{"agency member removes own", auth.RemoveClient, auth.UserAttr{ID: owner, AgencyID: agency, Role: auth.RoleMember}, auth.Resource{OwnerID: owner, AgencyID: agency}, false},
+		{"agency admin edits other's", auth.EditClient, auth.UserAttr{ID: other, AgencyID: agency, Role: auth.RoleAdmin}, auth.Resource{OwnerID: owner, AgencyID: agency}, true},
+		{"other agency", auth.GetClients, auth.UserAttr{ID: owner, AgencyID: otherAgency, Role: auth.RoleOwner}, auth.Resource{OwnerID: owner, AgencyID: agency}, false},
+		{"no agency", auth.GetClients, auth.UserAttr{ID: owner}, auth.Resource{OwnerID: owner, AgencyID: agency}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := auth.Authorize(tt.access, tt.user, tt.resource)
+			if tt.allowed {
+				if err != nil {
+					t.Fatalf("Authorize() = %v, want nil", err)
+				}
+				return
+			}
+			var forbidden pkg.ForbiddenError
+			if !errors.As(err, &forbidden) {
+				t.Fatalf("Authorize() = %v, want ForbiddenError", err)
+			}
+		})
+	}
+}
+
+func TestScopeAccess(t *testing.T) {
+	t.Parallel()
+	access, err := auth.ScopeAccess([]string{"notes:read", "clients:write"})
+	if err != nil {
+		t.Fatalf("ScopeAccess() = %v", err)
+	}
+	want := auth.GetNotes | auth.CreateClient | auth.EditClient | auth.RemoveClient
+	if access != want {
+		t.Errorf("ScopeAccess() = %#x, want %#x", access, want)
+	}
+	if scopes := auth.AccessScopes(access); len(scopes) != 2 || scopes[0] != "clients:write" || scopes[1] != "notes:read" {
+		t.Errorf("AccessScopes() = %v, want [clients:write notes:read]", scopes)
+	}
+	if _, err := auth.ScopeAccess([]string{"notes:admin"}); err == nil {
+		t.Error("ScopeAccess() accepted an unknown scope")
+	}
+	if scopes := auth.AccessScopes(auth.UserAccess); len(scopes) != len(auth.Scopes)-2 {
+		t.Errorf("AccessScopes(UserAccess) = %d scopes, want all but the user scopes", len(scopes))
+	}
+}
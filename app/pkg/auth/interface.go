@@ -6,10 +6,10 @@ type AuthService interface {
 	ValidateAccessToken(token string) (*AccessTokenClaims, error)
 	HasAccess(access int64, userAccess int64) bool
 	UpdateAccess(userAccess int64, access int64) (int64, error)
-	HasAccessABAC(access int64, userAccess int64, userAttr UserAttr) bool
-	GenerateSessionToken(userID, phone string) (string, error)
+	HasAccessABAC(access int64, userAccess int64, userAttr UserAttr, resource Resource) bool
+	GenerateSessionToken(userID, phone, method string) (string, error)
 	ValidateSessionToken(token string) (*SessionTokenClaims, error)
-	GenerateTokens(refreshTokenID, userID string, access int64, avatar, email string, subscriptionActive bool) (string, string, error)
+	GenerateTokens(refreshTokenID, sessionID, userID string, access int64, avatar, email string, subscriptionActive bool, agencyID string, agencyRole Role) (string, string, error)
 	ValidateRefreshToken(token string) (*RefreshTokenClaims, error)
 }
 
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Scopes maps the scopes an API key can be granted to the access each
+// carries. Read scopes hold the Get access of an area, write scopes the rest.
+var Scopes = map[string]int64{
+	"notes:read":          GetNotes,
+	"notes:write":         CreateNote | EditNote | RemoveNote,
+	"emails:read":         GetEmails,
+	"emails:send":         SendEmail,
+	"files:read":          GetFiles | DownloadFile,
+	"files:write":         UploadFile | RemoveFile,
+	"users:read":          GetUsers,
+	"users:write":         EditUser,
+	"proposals:read":      GetProposals,
+	"proposals:write":     CreateProposal | EditProposal | RemoveProposal,
+	"invoices:read":       GetInvoices,
+	"invoices:write":      CreateInvoice | EditInvoice | RemoveInvoice,
+	"settings:read":       GetSettings,
+	"settings:write":      EditSettings,
+	"contracts:read":      GetContracts,
+	"contracts:write":     CreateContract | EditContract | RemoveContract,
+	"quotations:read":     GetQuotations,
+	"quotations:write":    CreateQuotation | EditQuotation | RemoveQuotation,
+	"clients:read":        GetClients,
+	"clients:write":       CreateClient | EditClient | RemoveClient,
+	"consultations:read":  GetConsultations,
+	"consultations:write": CreateConsultation | EditConsultation,
+	"forms:read":          GetForms,
+	"forms:write":         EditForms,
+}
+
+// ScopeAccess returns the access granted by a set of scopes
+func ScopeAccess(scopes []string) (int64, error) {
+	var access int64
+	for _, scope := range scopes {
+		a, ok := Scopes[scope]
+		if !ok {
+			return 0, fmt.Errorf("unknown scope %q", scope)
+		}
+		access |= a
+	}
+	return access, nil
+}
+
+// AccessScopes returns the scopes whose access is wholly within access, in
+// name order
+func AccessScopes(access int64) []string {
+	scopes := make([]string, 0, len(Scopes))
+	for scope, a := range Scopes {
+		if access&a == a {
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
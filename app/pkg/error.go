@@ -66,3 +66,29 @@ type ForbiddenError struct{
 func (e ForbiddenError) Error() string {
 	return e.Err.Error()
 }
+
+type MethodNotAllowedError struct {
+	Method string
+}
+
+func (e MethodNotAllowedError) Error() string {
+	return fmt.Sprintf("method %s not allowed", e.Method)
+}
+
+type ConflictError struct {
+	Message string
+	Err     error
+}
+
+func (e ConflictError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Message, e.Err)
+}
+
+type TooManyRequestsError struct {
+	Message string
+	Err     error
+}
+
+func (e TooManyRequestsError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Message, e.Err)
+}
@@ -0,0 +1,116 @@
+// Package mailbox is a mailbox for development: an SMTP server that keeps
+// the emails it receives in memory, so they can be inspected and asserted
+// on without sending them anywhere.
+package mailbox
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message is an email the mailbox received
+type Message struct {
+	ID         string    `json:"id"`
+	ReceivedAt time.Time `json:"receivedAt"`
+	// From and To are the sender and recipients the email was sent to,
+	// which can differ from its headers
+	From        string              `json:"from"`
+	To          []string            `json:"to"`
+	Subject     string              `json:"subject"`
+	Headers     map[string][]string `json:"headers"`
+	HTML        string              `json:"html"`
+	Text        string              `json:"text"`
+	Attachments []Attachment        `json:"attachments"`
+	// Links are the URLs the HTML links to, such as sign in links
+	Links []string `json:"links"`
+	// Raw is the message as it was received
+	Raw []byte `json:"-"`
+}
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Size        int    `json:"size"`
+	Content     []byte `json:"-"`
+}
+
+// Mailbox keeps the latest messages, up to its capacity
+type Mailbox struct {
+	mu       sync.Mutex
+	capacity int
+	// messages are oldest first
+	messages []*Message
+}
+
+// New returns an empty mailbox that keeps up to capacity messages
+func New(capacity int) *Mailbox {
+	return &Mailbox{capacity: capacity}
+}
+
+// Add keeps a message, dropping the oldest when the mailbox is full
+func (b *Mailbox) Add(m *Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages = append(b.messages, m)
+	if over := len(b.messages) - b.capacity; over > 0 {
+		b.messages = slices.Delete(b.messages, 0, over)
+	}
+}
+
+// Messages returns the messages sent to a recipient, or all messages when
+// to is empty, newest first
+func (b *Mailbox) Messages(to string) []*Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	messages := make([]*Message, 0, len(b.messages))
+	for i := len(b.messages) - 1; i >= 0; i-- {
+		m := b.messages[i]
+		if to == "" || slices.ContainsFunc(m.To, func(rcpt string) bool { return strings.EqualFold(rcpt, to) }) {
+			messages = append(messages, m)
+		}
+	}
+	return messages
+}
+
+// Message returns a message by its ID
+func (b *Mailbox) Message(id string) (*Message, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, m := range b.messages {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// Clear removes every message
+func (b *Mailbox) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages = nil
+}
+
+// ErrNoMessage is returned by Wait when no message arrives in time
+var ErrNoMessage = errors.New("no message received")
+
+// Wait returns the newest message sent to a recipient, waiting up to a
+// timeout for one to arrive. Tests use it to wait for emails that are sent
+// in the background.
+func (b *Mailbox) Wait(to string, timeout time.Duration) (*Message, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if messages := b.Messages(to); len(messages) > 0 {
+			return messages[0], nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w for %s in %s", ErrNoMessage, to, timeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
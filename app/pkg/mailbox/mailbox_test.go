@@ -0,0 +1,126 @@
+package mailbox_test
+
+import (
+	"app/pkg/mailbox"
+	"errors"
+	"net"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// message is a multipart email like the ones the email providers send: a
+// quoted-printable HTML body with a link, a text body and an attachment
+const message = "From: Acme Studio <hello@acme.test>\r\n" +
+	"To: jane@example.com\r\n" +
+	"Subject: =?UTF-8?q?Sign_in_to_Acme_=E2=80=94_Studio?=\r\n" +
+	"List-Unsubscribe: <https://core.acme.test/api/v1/email-unsubscribe/token>\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=mixed\r\n" +
+	"\r\n" +
+	"--mixed\r\n" +
+	"Content-Type: multipart/alternative; boundary=alt\r\n" +
+	"\r\n" +
+	"--alt\r\n" +
+	"Content-Type: text/plain; charset=UTF-8\r\n" +
+	"\r\n" +
+	"Sign in: https://acme.test/login?token=abc&email=jane\r\n" +
+	"--alt\r\n" +
+	"Content-Type: text/html; charset=UTF-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"<p><a href=3D\"https://acme.test/login?token=3Dabc&amp;email=3Djane\">Sign=\r\n" +
+	" in</a></p>\r\n" +
+	"--alt--\r\n" +
+	"--mixed\r\n" +
+	"Content-Type: application/pdf\r\n" +
+	"Content-Disposition: attachment; filename=\"proposal.pdf\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0xLjQK\r\n" +
+	"--mixed--\r\n"
+
+func serve(t *testing.T, box *mailbox.Mailbox) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &mailbox.Server{Mailbox: box}
+	go s.Serve(l)
+	t.Cleanup(func() { s.Close() })
+	return l.Addr().String()
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	box := mailbox.New(10)
+	addr := serve(t, box)
+
+	// The server accepts any credentials
+	auth := smtp.PlainAuth("", "user", "password", "127.0.0.1")
+	if err := smtp.SendMail(addr, auth, "hello@acme.test", []string{"jane@example.com", "sam@example.com"}, []byte(message)); err != nil {
+		t.Fatalf("SendMail() = %v", err)
+	}
+
+	m, err := box.Wait("JANE@example.com", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ID == "" || m.From != "hello@acme.test" || len(m.To) != 2 {
+		t.Errorf("envelope = %q from %q to %v", m.ID, m.From, m.To)
+	}
+	if want := "Sign in to Acme — Studio"; m.Subject != want {
+		t.Errorf("Subject = %q, want %q", m.Subject, want)
+	}
+	if got := m.Headers["List-Unsubscribe"]; len(got) != 1 || !strings.Contains(got[0], "email-unsubscribe") {
+		t.Errorf("List-Unsubscribe = %v", got)
+	}
+	if !strings.Contains(m.HTML, "Sign in</a>") {
+		t.Errorf("HTML = %q, want the decoded body", m.HTML)
+	}
+	if !strings.HasPrefix(m.Text, "Sign in: https://acme.test/login") {
+		t.Errorf("Text = %q", m.Text)
+	}
+	if want := "https://acme.test/login?token=abc&email=jane"; len(m.Links) != 1 || m.Links[0] != want {
+		t.Errorf("Links = %v, want [%s]", m.Links, want)
+	}
+	if len(m.Attachments) != 1 {
+		t.Fatalf("Attachments = %+v, want the proposal", m.Attachments)
+	}
+	if a := m.Attachments[0]; a.Filename != "proposal.pdf" || a.ContentType != "application/pdf" || string(a.Content) != "%PDF-1.4\n" {
+		t.Errorf("attachment = %q %q %q", a.Filename, a.ContentType, a.Content)
+	}
+	if got, ok := box.Message(m.ID); !ok || got != m {
+		t.Errorf("Message(%s) = %v, %v", m.ID, got, ok)
+	}
+	if got := box.Messages("nobody@example.com"); len(got) != 0 {
+		t.Errorf("Messages() for another recipient = %d messages, want none", len(got))
+	}
+}
+
+func TestMailbox(t *testing.T) {
+	t.Parallel()
+	box := mailbox.New(2)
+	for _, id := range []string{"1", "2", "3"} {
+		box.Add(&mailbox.Message{ID: id, To: []string{"jane@example.com"}})
+	}
+
+	// The oldest message is dropped, and the newest comes first
+	messages := box.Messages("")
+	if len(messages) != 2 || messages[0].ID != "3" || messages[1].ID != "2" {
+		t.Fatalf("Messages() = %+v, want 3 and 2", messages)
+	}
+	if _, ok := box.Message("1"); ok {
+		t.Error("Message() returned a dropped message")
+	}
+
+	box.Clear()
+	if messages := box.Messages(""); len(messages) != 0 {
+		t.Errorf("Messages() after Clear() = %d messages", len(messages))
+	}
+	if _, err := box.Wait("jane@example.com", 20*time.Millisecond); !errors.Is(err, mailbox.ErrNoMessage) {
+		t.Errorf("Wait() = %v, want ErrNoMessage", err)
+	}
+}
@@ -0,0 +1,106 @@
+package mailbox
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+)
+
+// href matches the targets of links in HTML
+var href = regexp.MustCompile(`(?i)href\s*=\s*"([^"]*)"`)
+
+// Parse reads a MIME message into its subject, headers, HTML and text
+// bodies, and attachments
+func Parse(raw []byte) (*Message, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("error reading message: %w", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	m := &Message{
+		Subject:     subject,
+		Headers:     msg.Header,
+		Attachments: []Attachment{},
+		Links:       []string{},
+		Raw:         raw,
+	}
+	if err := m.readPart(textproto.MIMEHeader(msg.Header), msg.Body); err != nil {
+		return nil, err
+	}
+	for _, match := range href.FindAllStringSubmatch(m.HTML, -1) {
+		m.Links = append(m.Links, html.UnescapeString(match[1]))
+	}
+	return m, nil
+}
+
+// readPart reads a part of a message, and the parts it is made of. The
+// first HTML and text parts are the bodies, and the other parts are
+// attachments.
+func (m *Message) readPart(header textproto.MIMEHeader, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		r := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := r.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("error reading %s part: %w", mediaType, err)
+			}
+			if err := m.readPart(part.Header, part); err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := io.ReadAll(decode(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return fmt.Errorf("error decoding %s part: %w", mediaType, err)
+	}
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	switch {
+	case disposition != "attachment" && filename == "" && mediaType == "text/html" && m.HTML == "":
+		m.HTML = string(content)
+	case disposition != "attachment" && filename == "" && mediaType == "text/plain" && m.Text == "":
+		m.Text = string(content)
+	default:
+		m.Attachments = append(m.Attachments, Attachment{
+			Filename:    filename,
+			ContentType: mediaType,
+			Size:        len(content),
+			Content:     content,
+		})
+	}
+	return nil
+}
+
+func decode(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
@@ -0,0 +1,238 @@
+package mailbox
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxMessageSize is the largest message the server accepts
+const MaxMessageSize = 25 << 20
+
+// sessionTimeout is how long a client may take over each command
+const sessionTimeout = 5 * time.Minute
+
+// Server is an SMTP server that delivers every message it receives to a
+// mailbox, whoever it is addressed to. It accepts any credentials, and is
+// only meant for development.
+type Server struct {
+	Addr    string
+	Mailbox *Mailbox
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+	closed   bool
+}
+
+// ListenAndServe listens on the server's address and serves SMTP until the
+// server is closed
+func (s *Server) ListenAndServe() error {
+	l, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve serves SMTP on a listener until the server is closed
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return net.ErrClosed
+	}
+	s.listener = l
+	s.conns = map[net.Conn]struct{}{}
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return net.ErrClosed
+			}
+			return err
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serve(conn)
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// Close stops the server, and closes the connections of its clients
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+// session is the envelope of the message a client is sending
+type session struct {
+	from string
+	to   []string
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+	c := textproto.NewConn(conn)
+	reply := func(format string, args ...any) bool {
+		return c.PrintfLine(format, args...) == nil
+	}
+	if !reply("220 localhost ESMTP mailbox") {
+		return
+	}
+	var env session
+	for {
+		_ = conn.SetDeadline(time.Now().Add(sessionTimeout))
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		ok := true
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			ok = reply("250 localhost")
+		case "EHLO":
+			ok = reply("250-localhost") && reply("250-8BITMIME") && reply("250-SIZE %d", MaxMessageSize) && reply("250 AUTH PLAIN LOGIN")
+		case "AUTH":
+			ok = s.auth(c, arg)
+		case "MAIL":
+			from, found := path(arg, "FROM:")
+			if !found {
+				ok = reply("501 Syntax: MAIL FROM:<address>")
+				break
+			}
+			env = session{from: from}
+			ok = reply("250 OK")
+		case "RCPT":
+			to, found := path(arg, "TO:")
+			switch {
+			case !found:
+				ok = reply("501 Syntax: RCPT TO:<address>")
+			case env.from == "" && len(env.to) == 0:
+				ok = reply("503 MAIL first")
+			default:
+				env.to = append(env.to, to)
+				ok = reply("250 OK")
+			}
+		case "DATA":
+			if len(env.to) == 0 {
+				ok = reply("503 RCPT first")
+				break
+			}
+			ok = s.data(c, env)
+			env = session{}
+		case "RSET":
+			env = session{}
+			ok = reply("250 OK")
+		case "NOOP":
+			ok = reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			ok = reply("502 Command not implemented")
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// auth accepts any credentials, reading those that are not given with
+// the command
+func (s *Server) auth(c *textproto.Conn, arg string) bool {
+	mechanism, initial, _ := strings.Cut(arg, " ")
+	prompts := 0
+	switch strings.ToUpper(mechanism) {
+	case "PLAIN":
+		if initial == "" {
+			prompts = 1
+		}
+	case "LOGIN":
+		prompts = 2
+		if initial != "" {
+			prompts = 1
+		}
+	default:
+		return c.PrintfLine("504 Unrecognized authentication type") == nil
+	}
+	for range prompts {
+		if err := c.PrintfLine("334 "); err != nil {
+			return false
+		}
+		if _, err := c.ReadLine(); err != nil {
+			return false
+		}
+	}
+	return c.PrintfLine("235 Authentication succeeded") == nil
+}
+
+// data reads a message and delivers it to the mailbox
+func (s *Server) data(c *textproto.Conn, env session) bool {
+	if err := c.PrintfLine("354 End data with <CR><LF>.<CR><LF>"); err != nil {
+		return false
+	}
+	r := c.DotReader()
+	raw, err := io.ReadAll(io.LimitReader(r, MaxMessageSize+1))
+	if err != nil {
+		return false
+	}
+	if len(raw) > MaxMessageSize {
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			return false
+		}
+		return c.PrintfLine("552 Message exceeds %d bytes", MaxMessageSize) == nil
+	}
+	m, err := Parse(raw)
+	if err != nil {
+		slog.Warn("Mailbox could not parse a message", "error", err, "from", env.from)
+		return c.PrintfLine("554 %s", strings.ReplaceAll(err.Error(), "\n", " ")) == nil
+	}
+	m.ID = uuid.NewString()
+	m.ReceivedAt = time.Now()
+	m.From = env.from
+	m.To = env.to
+	s.Mailbox.Add(m)
+	slog.Debug("Mailbox received a message", "id", m.ID, "to", m.To, "subject", m.Subject)
+	return c.PrintfLine("250 OK: queued as %s", m.ID) == nil
+}
+
+// path returns the address of a MAIL FROM or RCPT TO argument, which may
+// be followed by parameters
+func path(arg, prefix string) (string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", false
+	}
+	address, _, _ := strings.Cut(strings.TrimSpace(arg[len(prefix):]), " ")
+	return strings.TrimSuffix(strings.TrimPrefix(address, "<"), ">"), true
+}
@@ -0,0 +1,80 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code
+type Currency string
+
+const (
+	AUD Currency = "AUD"
+	NZD Currency = "NZD"
+	USD Currency = "USD"
+	GBP Currency = "GBP"
+	EUR Currency = "EUR"
+	JPY Currency = "JPY"
+)
+
+// DefaultCurrency is the currency given to amounts read from columns and
+// JSON that do not carry a currency of their own
+var DefaultCurrency = AUD
+
+type currencyInfo struct {
+	exponent int
+	symbol   string
+}
+
+var currencies = map[Currency]currencyInfo{
+	AUD: {2, "$"},
+	NZD: {2, "$"},
+	USD: {2, "$"},
+	GBP: {2, "£"},
+	EUR: {2, "€"},
+	JPY: {0, "¥"},
+}
+
+// Exponent returns the number of minor unit digits. Unknown currencies
+// use two.
+func (c Currency) Exponent() int {
+	if info, ok := currencies[c]; ok {
+		return info.exponent
+	}
+	return 2
+}
+
+// Symbol returns the display symbol, or the code for unknown currencies
+func (c Currency) Symbol() string {
+	if info, ok := currencies[c]; ok {
+		return info.symbol
+	}
+	return string(c) + " "
+}
+
+// Rounding is how a result that falls between two minor units is rounded
+type Rounding int
+
+const (
+	// HalfEven rounds ties to the nearest even minor unit (banker's rounding)
+	HalfEven Rounding = iota
+	// HalfUp rounds ties away from zero, as Postgres numeric does
+	HalfUp
+	// Down truncates towards zero
+	Down
+)
+
+var roundings = map[string]Rounding{
+	"half_even": HalfEven,
+	"half_up":   HalfUp,
+	"down":      Down,
+}
+
+// ParseRounding returns the rounding mode named half_even, half_up or down
+func ParseRounding(s string) (Rounding, error) {
+	r, ok := roundings[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return 0, fmt.Errorf("money: unknown rounding mode %q", s)
+	}
+	return r, nil
+}
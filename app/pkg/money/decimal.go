@@ -0,0 +1,146 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const decimalPlaces = 4
+
+// Decimal is a fixed-point number with four decimal places, used for
+// quantities and percentage rates such as GST
+type Decimal struct {
+	v int64
+}
+
+// NewDecimal returns a whole number as a Decimal
+func NewDecimal(n int64) Decimal {
+	return Decimal{v: n * pow10[decimalPlaces]}
+}
+
+// ParseDecimal parses a string such as "10.00" or "2.5"
+func ParseDecimal(s string) (Decimal, error) {
+	v, err := parseFixed(s, decimalPlaces)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return Decimal{v: v}, nil
+}
+
+// MustParseDecimal is ParseDecimal for literals. It panics on error.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// DecimalFromFloat rounds a float to four decimal places
+func DecimalFromFloat(f float64) Decimal {
+	return Decimal{v: int64(math.RoundToEven(f * float64(pow10[decimalPlaces])))}
+}
+
+// Float64 returns the nearest float, for display only
+func (d Decimal) Float64() float64 {
+	return float64(d.v) / float64(pow10[decimalPlaces])
+}
+
+// IsZero reports whether d is zero
+func (d Decimal) IsZero() bool {
+	return d.v == 0
+}
+
+// Cmp returns -1, 0 or +1 as d is less than, equal to or greater than o
+func (d Decimal) Cmp(o Decimal) int {
+	switch {
+	case d.v < o.v:
+		return -1
+	case d.v > o.v:
+		return 1
+	}
+	return 0
+}
+
+// String renders d with at least two decimal places, e.g. "10.00" or "2.125"
+func (d Decimal) String() string {
+	return formatFixed(d.v, decimalPlaces, 2)
+}
+
+// Scan implements sql.Scanner
+func (d *Decimal) Scan(src any) error {
+	s, err := scanString(src, decimalPlaces)
+	if err != nil {
+		return err
+	}
+	v, err := parseFixed(s, decimalPlaces)
+	if err != nil {
+		return err
+	}
+	d.v = v
+	return nil
+}
+
+// Value implements driver.Valuer
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// MarshalJSON encodes d as a string so no precision is lost in transit
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a string or a number
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	s, err := jsonString(b)
+	if err != nil {
+		return err
+	}
+	v, err := parseFixed(s, decimalPlaces)
+	if err != nil {
+		return err
+	}
+	d.v = v
+	return nil
+}
+
+// scanString converts a database value to a decimal string
+func scanString(src any, places int) (string, error) {
+	switch v := src.(type) {
+	case nil:
+		return "", nil
+	case []byte:
+		return string(v), nil
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', places, 64), nil
+	default:
+		return "", fmt.Errorf("money: cannot scan %T", src)
+	}
+}
+
+// jsonString reads a JSON string or number as a decimal string
+func jsonString(b []byte) (string, error) {
+	if string(b) == "null" {
+		return "", nil
+	}
+	var n json.Number
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+	if err := json.Unmarshal(b, &n); err != nil {
+		return "", fmt.Errorf("money: %w", err)
+	}
+	return n.String(), nil
+}
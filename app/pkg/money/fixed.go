@@ -0,0 +1,105 @@
+package money
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var pow10 = [...]int64{1, 10, 100, 1000, 10000, 100000, 1000000}
+
+// parseFixed converts a decimal string to an integer scaled by 10^places.
+// More fractional digits than places is an error rather than a silent
+// rounding.
+func parseFixed(s string, places int) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	neg := false
+	switch s[0] {
+	case '-':
+		neg = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("money: invalid amount %q", s)
+	}
+	if len(frac) > places {
+		trimmed := strings.TrimRight(frac[places:], "0")
+		if trimmed != "" {
+			return 0, fmt.Errorf("money: %q has more than %d decimal places", s, places)
+		}
+		frac = frac[:places]
+	}
+	for _, r := range whole + frac {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("money: invalid amount %q", s)
+		}
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || w > math.MaxInt64/pow10[places] {
+		return 0, fmt.Errorf("money: amount %q out of range", s)
+	}
+	var f int64
+	if frac != "" {
+		f, _ = strconv.ParseInt(frac, 10, 64)
+		f *= pow10[places-len(frac)]
+	}
+	v := w*pow10[places] + f
+	if neg {
+		v = -v
+	}
+	return v, nil
+}
+
+// formatFixed renders an integer scaled by 10^places with at least
+// minPlaces fractional digits, trimming trailing zeros beyond that
+func formatFixed(v int64, places, minPlaces int) string {
+	sign := ""
+	u := uint64(v)
+	if v < 0 {
+		sign = "-"
+		u = uint64(-v)
+	}
+	if places == 0 {
+		return sign + strconv.FormatUint(u, 10)
+	}
+	unit := uint64(pow10[places])
+	frac := fmt.Sprintf("%0*d", places, u%unit)
+	for len(frac) > minPlaces && frac[len(frac)-1] == '0' {
+		frac = frac[:len(frac)-1]
+	}
+	if frac == "" {
+		return sign + strconv.FormatUint(u/unit, 10)
+	}
+	return sign + strconv.FormatUint(u/unit, 10) + "." + frac
+}
+
+// mulDiv returns a × b ÷ d rounded with mode. d must be positive. It
+// panics if the result does not fit in an int64.
+func mulDiv(a, b, d int64, mode Rounding) int64 {
+	num := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	den := big.NewInt(d)
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() != 0 && mode != Down {
+		twice := new(big.Int).Abs(r)
+		twice.Lsh(twice, 1)
+		c := twice.Cmp(den)
+		if c > 0 || (c == 0 && (mode == HalfUp || q.Bit(0) == 1)) {
+			q.Add(q, big.NewInt(int64(num.Sign())))
+		}
+	}
+	if !q.IsInt64() {
+		panic("money: arithmetic overflow")
+	}
+	return q.Int64()
+}
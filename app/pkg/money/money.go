@@ -0,0 +1,255 @@
+// Package money provides an exact, currency-aware amount type for prices
+// stored in DECIMAL columns. Amounts are held as an integer count of the
+// currency's minor unit, so sums never drift and any rounding is explicit.
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrCurrencyMismatch reports amounts in different currencies being combined
+var ErrCurrencyMismatch = errors.New("money: currency mismatch")
+
+// Money is an amount in a currency's minor unit (cents for AUD). The zero
+// value is zero with no currency and adopts the currency of whatever it is
+// combined with.
+type Money struct {
+	minor    int64
+	currency Currency
+}
+
+// New returns an amount of minor units in currency c
+func New(minor int64, c Currency) Money {
+	return Money{minor: minor, currency: c}
+}
+
+// Zero returns a zero amount in currency c
+func Zero(c Currency) Money {
+	return Money{currency: c}
+}
+
+// Parse parses a string such as "1234.50" in currency c. More decimal
+// places than the currency allows is an error.
+func Parse(s string, c Currency) (Money, error) {
+	minor, err := parseFixed(s, c.Exponent())
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{minor: minor, currency: c}, nil
+}
+
+// MustParse is Parse for literals. It panics on error.
+func MustParse(s string, c Currency) Money {
+	m, err := Parse(s, c)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Sum adds amounts, which must share a currency
+func Sum(amounts ...Money) Money {
+	var total Money
+	for _, m := range amounts {
+		total = total.Add(m)
+	}
+	return total
+}
+
+// Minor returns the amount in minor units
+func (m Money) Minor() int64 {
+	return m.minor
+}
+
+// Currency returns the currency, or DefaultCurrency if none was set
+func (m Money) Currency() Currency {
+	if m.currency == "" {
+		return DefaultCurrency
+	}
+	return m.currency
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.minor < 0
+}
+
+// Cmp returns -1, 0 or +1 as m is less than, equal to or greater than o
+func (m Money) Cmp(o Money) int {
+	m.match(o)
+	switch {
+	case m.minor < o.minor:
+		return -1
+	case m.minor > o.minor:
+		return 1
+	}
+	return 0
+}
+
+// Add returns m + o
+func (m Money) Add(o Money) Money {
+	return Money{minor: m.minor + o.minor, currency: m.match(o)}
+}
+
+// Sub returns m - o
+func (m Money) Sub(o Money) Money {
+	return Money{minor: m.minor - o.minor, currency: m.match(o)}
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{minor: -m.minor, currency: m.currency}
+}
+
+// MulInt returns m × n, which is always exact
+func (m Money) MulInt(n int64) Money {
+	return Money{minor: mulDiv(m.minor, n, 1, Down), currency: m.currency}
+}
+
+// Mul returns m × q rounded to the minor unit with mode
+func (m Money) Mul(q Decimal, mode Rounding) Money {
+	return Money{minor: mulDiv(m.minor, q.v, pow10[decimalPlaces], mode), currency: m.currency}
+}
+
+// Percent returns rate percent of m rounded to the minor unit with mode,
+// e.g. the GST on an amount at a rate of 10.00
+func (m Money) Percent(rate Decimal, mode Rounding) Money {
+	return Money{minor: mulDiv(m.minor, rate.v, 100*pow10[decimalPlaces], mode), currency: m.currency}
+}
+
+// IncludedPercent returns the rate percent tax already included in m,
+// rounded to the minor unit with mode, e.g. the GST in a GST inclusive price:
+// m × rate ÷ (100 + rate). It panics if rate is not above -100.
+func (m Money) IncludedPercent(rate Decimal, mode Rounding) Money {
+	whole := 100*pow10[decimalPlaces] + rate.v
+	if whole <= 0 {
+		panic("money: included rate must be above -100")
+	}
+	return Money{minor: mulDiv(m.minor, rate.v, whole, mode), currency: m.currency}
+}
+
+// Prorate returns the share of m that part is of whole, rounded to the minor
+// unit with mode, e.g. the portion of a discount that falls on taxable items.
+// A zero whole has no share.
+func (m Money) Prorate(part, whole Money, mode Rounding) Money {
+	part.match(whole)
+	if whole.minor == 0 {
+		return Money{currency: m.currency}
+	}
+	// mulDiv divides by a positive amount, so a negative whole moves its
+	// sign to part: m × part ÷ whole = m × −part ÷ |whole|
+	p, w := part.minor, whole.minor
+	if w < 0 {
+		p, w = -p, -w
+	}
+	return Money{minor: mulDiv(m.minor, p, w, mode), currency: m.currency}
+}
+
+// String renders the amount without a symbol, e.g. "1234.50"
+func (m Money) String() string {
+	exp := m.Currency().Exponent()
+	return formatFixed(m.minor, exp, exp)
+}
+
+// Format renders the amount for display, e.g. "$1,234.50"
+func (m Money) Format() string {
+	s := m.String()
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	if hasFrac {
+		b.WriteString("." + frac)
+	}
+	return sign + m.Currency().Symbol() + b.String()
+}
+
+// SameCurrency returns ErrCurrencyMismatch unless the amounts share a
+// currency. Amounts without a currency match any other. Services check the
+// amounts they read or are sent before adding them up.
+func SameCurrency(amounts ...Money) error {
+	var c Currency
+	for _, m := range amounts {
+		switch {
+		case m.currency == "" || m.currency == c:
+		case c == "":
+			c = m.currency
+		default:
+			return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, c, m.currency)
+		}
+	}
+	return nil
+}
+
+// match returns the currency shared by m and o. A zero value without a
+// currency takes the other side's currency. Amounts are checked with
+// SameCurrency before they are combined, so two different currencies here
+// are a programming error and panic.
+func (m Money) match(o Money) Currency {
+	switch {
+	case m.currency == "":
+		return o.currency
+	case o.currency == "" || o.currency == m.currency:
+		return m.currency
+	}
+	panic(fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, o.currency))
+}
+
+// Scan implements sql.Scanner. The amount keeps its currency if it has one
+// and otherwise takes DefaultCurrency.
+func (m *Money) Scan(src any) error {
+	c := m.Currency()
+	s, err := scanString(src, c.Exponent())
+	if err != nil {
+		return err
+	}
+	minor, err := parseFixed(s, c.Exponent())
+	if err != nil {
+		return err
+	}
+	m.minor, m.currency = minor, c
+	return nil
+}
+
+// Value implements driver.Valuer
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// MarshalJSON encodes the amount as a string such as "1234.50" so no
+// precision is lost in transit
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON accepts a string or a number in the amount's currency
+func (m *Money) UnmarshalJSON(b []byte) error {
+	c := m.Currency()
+	s, err := jsonString(b)
+	if err != nil {
+		return err
+	}
+	minor, err := parseFixed(s, c.Exponent())
+	if err != nil {
+		return err
+	}
+	m.minor, m.currency = minor, c
+	return nil
+}
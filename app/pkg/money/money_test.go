@@ -0,0 +1,215 @@
+package money_test
+
+import (
+	"app/pkg/money"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in      string
+		c       money.Currency
+		minor   int64
+		str     string
+		wantErr bool
+	}{
+		{"1234.50", money.AUD, 123450, "1234.50", false},
+		{"1234.5", money.AUD, 123450, "1234.50", false},
+		{"-0.05", money.AUD, -5, "-0.05", false},
+		{"10.000", money.AUD, 1000, "10.00", false},
+		{"", money.AUD, 0, "0.00", false},
+		{"500", money.JPY, 500, "500", false},
+		{"1.005", money.AUD, 0, "", true},
+		{"1.5", money.JPY, 0, "", true},
+		{"abc", money.AUD, 0, "", true},
+	}
+	for _, tt := range tests {
+		m, err := money.Parse(tt.in, tt.c)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if m.Minor() != tt.minor || m.String() != tt.str {
+			t.Errorf("Parse(%q) = %d %q, want %d %q", tt.in, m.Minor(), m.String(), tt.minor, tt.str)
+		}
+	}
+}
+
+func TestPercentRounding(t *testing.T) {
+	t.Parallel()
+	rate := money.MustParseDecimal("10.00")
+	tests := []struct {
+		amount string
+		mode   money.Rounding
+		want   string
+	}{
+		{"0.25", money.HalfEven, "0.02"},
+		{"0.35", money.HalfEven, "0.04"},
+		{"0.25", money.HalfUp, "0.03"},
+		{"-0.25", money.HalfUp, "-0.03"},
+		{"-0.25", money.HalfEven, "-0.02"},
+		{"0.29", money.Down, "0.02"},
+		{"1300.00", money.HalfEven, "130.00"},
+	}
+	for _, tt := range tests {
+		got := money.MustParse(tt.amount, money.AUD).Percent(rate, tt.mode).String()
+		if got != tt.want {
+			t.Errorf("%s × 10%% (mode %d) = %s, want %s", tt.amount, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestParseRounding(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in      string
+		want    money.Rounding
+		wantErr bool
+	}{
+		{"half_even", money.HalfEven, false},
+		{"half_up", money.HalfUp, false},
+		{" HALF_UP ", money.HalfUp, false},
+		{"down", money.Down, false},
+		{"", 0, true},
+		{"bankers", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := money.ParseRounding(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseRounding(%q) = %d, %v, want %d, wantErr %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	t.Parallel()
+	var total money.Money
+	total = total.Add(money.MustParse("0.10", money.AUD))
+	total = total.Add(money.MustParse("0.20", money.AUD))
+	if total.String() != "0.30" || total.Currency() != money.AUD {
+		t.Errorf("0.10 + 0.20 = %s %s, want 0.30 AUD", total, total.Currency())
+	}
+	qty := money.MustParseDecimal("2.5")
+	if got := money.MustParse("19.99", money.AUD).Mul(qty, money.HalfEven).String(); got != "49.98" {
+		t.Errorf("19.99 × 2.5 = %s, want 49.98", got)
+	}
+	discount := money.MustParse("100.00", money.AUD)
+	if got := discount.Prorate(money.MustParse("200.00", money.AUD), money.MustParse("300.00", money.AUD), money.HalfEven).String(); got != "66.67" {
+		t.Errorf("100.00 × 200/300 = %s, want 66.67", got)
+	}
+	if got := money.MustParse("1234567.8", money.AUD).Format(); got != "$1,234,567.80" {
+		t.Errorf("Format() = %s, want $1,234,567.80", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("adding AUD to USD did not panic")
+		}
+	}()
+	money.MustParse("1", money.AUD).Add(money.MustParse("1", money.USD))
+}
+
+func TestIncludedPercent(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		amount, rate string
+		mode         money.Rounding
+		want         string
+	}{
+		{"110.00", "10.00", money.HalfEven, "10.00"},
+		{"100.00", "10.00", money.HalfEven, "9.09"},
+		{"100.00", "12.345", money.HalfEven, "10.99"},
+		{"0.11", "10.00", money.HalfUp, "0.01"},
+		{"-110.00", "10.00", money.HalfEven, "-10.00"},
+		{"110.00", "0", money.HalfEven, "0.00"},
+	}
+	for _, tt := range tests {
+		got := money.MustParse(tt.amount, money.AUD).IncludedPercent(money.MustParseDecimal(tt.rate), tt.mode).String()
+		if got != tt.want {
+			t.Errorf("%s incl. %s%% = %s, want %s", tt.amount, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestProrate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		amount, part, whole string
+		mode                money.Rounding
+		want                string
+	}{
+		{"100.00", "200.00", "300.00", money.HalfEven, "66.67"},
+		{"100.00", "-200.00", "-300.00", money.HalfEven, "66.67"},
+		{"100.00", "200.00", "-300.00", money.HalfEven, "-66.67"},
+		{"-100.00", "200.00", "300.00", money.HalfEven, "-66.67"},
+		{"-100.00", "-200.00", "-300.00", money.HalfUp, "-66.67"},
+		{"0.05", "-1.00", "-2.00", money.HalfEven, "0.02"},
+		{"0.05", "-1.00", "-2.00", money.HalfUp, "0.03"},
+		{"0.05", "1.00", "-2.00", money.HalfUp, "-0.03"},
+		{"100.00", "200.00", "0.00", money.HalfEven, "0.00"},
+	}
+	for _, tt := range tests {
+		part, whole := money.MustParse(tt.part, money.AUD), money.MustParse(tt.whole, money.AUD)
+		got := money.MustParse(tt.amount, money.AUD).Prorate(part, whole, tt.mode).String()
+		if got != tt.want {
+			t.Errorf("%s × %s/%s (mode %d) = %s, want %s", tt.amount, tt.part, tt.whole, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestSameCurrency(t *testing.T) {
+	t.Parallel()
+	aud, usd := money.MustParse("1", money.AUD), money.MustParse("1", money.USD)
+	tests := []struct {
+		name    string
+		amounts []money.Money
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"same", []money.Money{aud, aud}, false},
+		{"zero value", []money.Money{{}, usd, {}}, false},
+		{"mixed", []money.Money{aud, {}, usd}, true},
+	}
+	for _, tt := range tests {
+		err := money.SameCurrency(tt.amounts...)
+		if got := errors.Is(err, money.ErrCurrencyMismatch); got != tt.wantErr {
+			t.Errorf("%s: SameCurrency() error = %v, want mismatch %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestScanValueJSON(t *testing.T) {
+	t.Parallel()
+	var m money.Money
+	if err := m.Scan([]byte("99.90")); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if m.Minor() != 9990 || m.Currency() != money.DefaultCurrency {
+		t.Errorf("Scan() = %d %s, want 9990 %s", m.Minor(), m.Currency(), money.DefaultCurrency)
+	}
+	v, err := m.Value()
+	if err != nil || v != "99.90" {
+		t.Errorf("Value() = %v, %v, want 99.90", v, err)
+	}
+
+	var body struct {
+		Price money.Money   `json:"price"`
+		Rate  money.Decimal `json:"rate"`
+	}
+	if err := json.Unmarshal([]byte(`{"price":12.5,"rate":"10"}`), &body); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(b) != `{"price":"12.50","rate":"10.00"}` {
+		t.Errorf("Marshal() = %s", b)
+	}
+}
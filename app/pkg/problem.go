@@ -0,0 +1,112 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// Error codes are stable, machine-readable names for each kind of error.
+// Clients should branch on these rather than on messages or statuses.
+const (
+	CodeBadRequest       = "bad_request"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeConflict         = "conflict"
+	CodeTooManyRequests  = "too_many_requests"
+	CodeValidation       = "validation_failed"
+	CodeInternal         = "internal_error"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem responses
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details response. Code and RequestID are
+// extension members; Errors holds the field-level details of a validation
+// failure.
+type Problem struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Instance  string            `json:"instance,omitempty"`
+	Code      string            `json:"errorCode"`
+	RequestID string            `json:"requestId,omitempty"`
+	Errors    []ValidationError `json:"errors,omitempty"`
+}
+
+// NewProblem describes an error as a problem. Errors that are not one of
+// the pkg error types are treated as internal, and their details are not
+// exposed.
+func NewProblem(err error) Problem {
+	var unauthorizedError UnauthorizedError
+	var forbiddenError ForbiddenError
+	var notFoundError NotFoundError
+	var methodNotAllowedError MethodNotAllowedError
+	var conflictError ConflictError
+	var tooManyRequestsError TooManyRequestsError
+	var badRequestError BadRequestError
+	var validationErrors ValidationErrors
+	var internalError InternalError
+	switch {
+	case errors.As(err, &unauthorizedError):
+		return newProblem(CodeUnauthorized, http.StatusUnauthorized, "Unauthorized")
+	case errors.As(err, &forbiddenError):
+		return newProblem(CodeForbidden, http.StatusForbidden, "You do not have access to this resource")
+	case errors.As(err, &notFoundError):
+		return newProblem(CodeNotFound, http.StatusNotFound, notFoundError.Message)
+	case errors.As(err, &methodNotAllowedError):
+		return newProblem(CodeMethodNotAllowed, http.StatusMethodNotAllowed, "Method not allowed")
+	case errors.As(err, &conflictError):
+		return newProblem(CodeConflict, http.StatusConflict, conflictError.Message)
+	case errors.As(err, &tooManyRequestsError):
+		return newProblem(CodeTooManyRequests, http.StatusTooManyRequests, tooManyRequestsError.Message)
+	case errors.As(err, &badRequestError):
+		return newProblem(CodeBadRequest, http.StatusBadRequest, badRequestError.Message)
+	case errors.As(err, &validationErrors):
+		p := newProblem(CodeValidation, http.StatusUnprocessableEntity, validationErrors.Error())
+		p.Errors = validationErrors
+		return p
+	case errors.As(err, &internalError):
+		return newProblem(CodeInternal, http.StatusInternalServerError, internalError.Message)
+	default:
+		return newProblem(CodeInternal, http.StatusInternalServerError, "An internal error occurred")
+	}
+}
+
+func newProblem(code string, status int, detail string) Problem {
+	return Problem{
+		Type:   "/problems/" + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+type requestIDKey struct{}
+
+// NewRequestID returns a new ID for a request that did not come with one
+func NewRequestID() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.NewString()
+	}
+	return id.String()
+}
+
+// WithRequestID returns a context carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID, or an empty string when the
+// context has none
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,62 @@
+package pkg_test
+
+import (
+	"app/pkg"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestNewProblem(t *testing.T) {
+	t.Parallel()
+	cause := errors.New("cause")
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{"unauthorized", pkg.UnauthorizedError{Err: cause}, http.StatusUnauthorized, pkg.CodeUnauthorized, "Unauthorized"},
+		{"forbidden", pkg.ForbiddenError{Err: cause}, http.StatusForbidden, pkg.CodeForbidden, "You do not have access to this resource"},
+		{"not found", pkg.NotFoundError{Message: "Note not found", Err: cause}, http.StatusNotFound, pkg.CodeNotFound, "Note not found"},
+		{"method not allowed", pkg.MethodNotAllowedError{Method: http.MethodPatch}, http.StatusMethodNotAllowed, pkg.CodeMethodNotAllowed, "Method not allowed"},
+		{"conflict", pkg.ConflictError{Message: "Contract changed", Err: cause}, http.StatusConflict, pkg.CodeConflict, "Contract changed"},
+		{"too many requests", pkg.TooManyRequestsError{Message: "Too many attempts", Err: cause}, http.StatusTooManyRequests, pkg.CodeTooManyRequests, "Too many attempts"},
+		{"bad request", pkg.BadRequestError{Message: "Invalid ID", Err: cause}, http.StatusBadRequest, pkg.CodeBadRequest, "Invalid ID"},
+		{"internal", pkg.InternalError{Message: "Error selecting note", Err: cause}, http.StatusInternalServerError, pkg.CodeInternal, "Error selecting note"},
+		{"wrapped forbidden", fmt.Errorf("wrapped: %w", pkg.ForbiddenError{Err: cause}), http.StatusForbidden, pkg.CodeForbidden, "You do not have access to this resource"},
+		{"unknown", cause, http.StatusInternalServerError, pkg.CodeInternal, "An internal error occurred"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p := pkg.NewProblem(tt.err)
+			if p.Status != tt.status || p.Code != tt.code || p.Detail != tt.detail {
+				t.Fatalf("NewProblem() = %d %q %q, want %d %q %q", p.Status, p.Code, p.Detail, tt.status, tt.code, tt.detail)
+			}
+			if p.Type != "/problems/"+tt.code {
+				t.Errorf("Type = %q, want /problems/%s", p.Type, tt.code)
+			}
+			if p.Title != http.StatusText(tt.status) {
+				t.Errorf("Title = %q, want %q", p.Title, http.StatusText(tt.status))
+			}
+		})
+	}
+}
+
+func TestNewProblemValidation(t *testing.T) {
+	t.Parallel()
+	errs := pkg.ValidationErrors{
+		{Field: "title", Tag: "required", Message: "Title is required"},
+		{Field: "content", Tag: "min10", Message: "Content is too short"},
+	}
+	p := pkg.NewProblem(errs)
+	if p.Status != http.StatusUnprocessableEntity || p.Code != pkg.CodeValidation {
+		t.Fatalf("NewProblem() = %d %q, want %d %q", p.Status, p.Code, http.StatusUnprocessableEntity, pkg.CodeValidation)
+	}
+	if len(p.Errors) != 2 || p.Errors[0].Field != "title" || p.Errors[1].Field != "content" {
+		t.Errorf("Errors = %+v, want the field errors", p.Errors)
+	}
+}
@@ -44,6 +44,41 @@ const (
 	// Admin access
 	GetUsers int64 = 0x0000000000001000
 	EditUser int64 = 0x0000000000002000
+
+	GetProposals   int64 = 0x0000000000010000
+	CreateProposal int64 = 0x0000000000020000
+	EditProposal   int64 = 0x0000000000040000
+	RemoveProposal int64 = 0x0000000000080000
+
+	GetInvoices   int64 = 0x0000000000100000
+	CreateInvoice int64 = 0x0000000000200000
+	EditInvoice   int64 = 0x0000000000400000
+	RemoveInvoice int64 = 0x0000000000800000
+
+	GetSettings  int64 = 0x0000000001000000
+	EditSettings int64 = 0x0000000002000000
+
+	GetContracts   int64 = 0x0000000004000000
+	CreateContract int64 = 0x0000000008000000
+	EditContract   int64 = 0x0000000010000000
+	RemoveContract int64 = 0x0000000020000000
+
+	GetQuotations   int64 = 0x0000000040000000
+	CreateQuotation int64 = 0x0000000080000000
+	EditQuotation   int64 = 0x0000000100000000
+	RemoveQuotation int64 = 0x0000000200000000
+
+	GetClients   int64 = 0x0000000400000000
+	CreateClient int64 = 0x0000000800000000
+	EditClient   int64 = 0x0000001000000000
+	RemoveClient int64 = 0x0000002000000000
+
+	GetConsultations   int64 = 0x0000004000000000
+	CreateConsultation int64 = 0x0000008000000000
+	EditConsultation   int64 = 0x0000010000000000
+
+	GetForms  int64 = 0x0000020000000000
+	EditForms int64 = 0x0000040000000000
 )
 
 type SessionTokenClaims struct {
@@ -43,6 +43,9 @@ type Config struct {
 	WriteTimeout   time.Duration
 	ContextTimeout time.Duration
 	ToastDuration  int
+	// MailboxSMTPPort is the port of the development mailbox's SMTP
+	// server. The mailbox is disabled when it is empty.
+	MailboxSMTPPort string
 }
 
 func LoadConfig() *Config {
@@ -56,20 +59,21 @@ func LoadConfig() *Config {
 		ToastDuration   = 4000
 	)
 	return &Config{
-		LogLevel:       MustSetEnv(true, "LOG_LEVEL"),
-		HTTPPort:       MustSetEnv(true, "HTTP_PORT"),
-		SSEPort:        MustSetEnv(true, "SSE_PORT"),
-		Domain:         MustSetEnv(true, "DOMAIN"),
-		AdminURL:       MustSetEnv(true, "ADMIN_URL"),
-		SSEURL:         MustSetEnv(true, "SSE_URL"),
-		NATSURL:        MustSetEnv(true, "NATS_URL"),
-		CoreURL:        MustSetEnv(true, "CORE_URL"),
-		CoreURI:        MustSetEnv(true, "CORE_URI"),
-		ReadTimeout:    ReadTimeout,
-		IdleTimeout:    IdleTimeout,
-		WriteTimeout:   WriteTimeout,
-		ContextTimeout: ContextTimeout,
-		ToastDuration:  ToastDuration,
+		LogLevel:        MustSetEnv(true, "LOG_LEVEL"),
+		HTTPPort:        MustSetEnv(true, "HTTP_PORT"),
+		SSEPort:         MustSetEnv(true, "SSE_PORT"),
+		Domain:          MustSetEnv(true, "DOMAIN"),
+		AdminURL:        MustSetEnv(true, "ADMIN_URL"),
+		SSEURL:          MustSetEnv(true, "SSE_URL"),
+		NATSURL:         MustSetEnv(true, "NATS_URL"),
+		CoreURL:         MustSetEnv(true, "CORE_URL"),
+		CoreURI:         MustSetEnv(true, "CORE_URI"),
+		ReadTimeout:     ReadTimeout,
+		IdleTimeout:     IdleTimeout,
+		WriteTimeout:    WriteTimeout,
+		ContextTimeout:  ContextTimeout,
+		ToastDuration:   ToastDuration,
+		MailboxSMTPPort: os.Getenv("MAILBOX_SMTP_PORT"),
 	}
 }
 
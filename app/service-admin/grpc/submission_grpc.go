@@ -0,0 +1,38 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	pb "service-admin/proto"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func (c *Conn) ProcessSubmission(ctx context.Context, token string, agencyID, submissionID string) (*pb.ProcessSubmissionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.ContextTimeout)
+	defer cancel()
+	errCh := make(chan error, 1)
+	var result *pb.ProcessSubmissionResponse
+	go func() {
+		client := pb.NewSubmissionServiceClient(c.conn)
+		c := metadata.AppendToOutgoingContext(ctx, "Authorization", token)
+		res, err := client.ProcessSubmission(c, &pb.SubmissionID{
+			AgencyId: agencyID,
+			Id:       submissionID,
+		})
+		if err != nil {
+			errCh <- err
+			return
+		}
+		result = res
+		errCh <- nil
+	}()
+
+	select {
+	case err := <-errCh:
+		return result, err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("error processing submission: %w", ctx.Err())
+	}
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"app/pkg"
+	"app/pkg/mailbox"
 	"context"
+	"errors"
 	"log/slog"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
@@ -41,8 +44,23 @@ func main() {
 	defer conn.Close()
 	slog.Info("gRPC connection established", "address", cfg.CoreURI)
 
+	// Run the development mailbox, which keeps the emails core sends to it
+	var box *mailbox.Mailbox
+	var mailboxServer *mailbox.Server
+	if cfg.MailboxSMTPPort != "" {
+		box = mailbox.New(500)
+		mailboxServer = &mailbox.Server{Addr: ":" + cfg.MailboxSMTPPort, Mailbox: box}
+		go func() {
+			slog.Warn("Development mailbox listening, unauthenticated, on", "port", cfg.MailboxSMTPPort, "url", cfg.AdminURL+"/mailbox")
+			if err := mailboxServer.ListenAndServe(); err != nil && !errors.Is(err, net.ErrClosed) {
+				slog.Error("Error serving SMTP", "error", err)
+				panic(err)
+			}
+		}()
+	}
+
 	// Set up REST handlers
-	restHandler := setupRESTHandlers(cfg, conn, broker)
+	restHandler := setupRESTHandlers(cfg, conn, broker, box)
 	// Run the REST server
 	restServer := rest.Run(restHandler)
 
@@ -66,13 +84,18 @@ func main() {
 	if err := sseServer.Shutdown(ctx); err != nil {
 		slog.Error("SSE server forced to shutdown", "error", err)
 	}
+	if mailboxServer != nil {
+		if err := mailboxServer.Close(); err != nil {
+			slog.Error("Mailbox server forced to shutdown", "error", err)
+		}
+	}
 
 	slog.Info("Servers stopped gracefully")
 }
 
-func setupRESTHandlers(cfg *config.Config, conn *grpc.Conn, broker *pubsub.EventBroker) *rest.Handler {
+func setupRESTHandlers(cfg *config.Config, conn *grpc.Conn, broker *pubsub.EventBroker, box *mailbox.Mailbox) *rest.Handler {
 	authService := auth.NewService(cfg)
-	handler := rest.NewHandler(cfg, conn, broker, authService)
+	handler := rest.NewHandler(cfg, conn, broker, authService, box)
 	return handler
 }
 
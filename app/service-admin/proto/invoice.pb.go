@@ -0,0 +1,1558 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v6.31.1
+// source: invoice.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Amounts and quantities are carried as decimal strings such as "1234.50".
+type Invoice struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Id                   string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt            string                 `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt            string                 `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	AgencyId             string                 `protobuf:"bytes,4,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	ProposalId           string                 `protobuf:"bytes,5,opt,name=proposal_id,json=proposalId,proto3" json:"proposal_id,omitempty"`
+	ContractId           string                 `protobuf:"bytes,6,opt,name=contract_id,json=contractId,proto3" json:"contract_id,omitempty"`
+	ClientId             string                 `protobuf:"bytes,7,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	InvoiceNumber        string                 `protobuf:"bytes,8,opt,name=invoice_number,json=invoiceNumber,proto3" json:"invoice_number,omitempty"`
+	Slug                 string                 `protobuf:"bytes,9,opt,name=slug,proto3" json:"slug,omitempty"`
+	Status               string                 `protobuf:"bytes,10,opt,name=status,proto3" json:"status,omitempty"`
+	ClientBusinessName   string                 `protobuf:"bytes,11,opt,name=client_business_name,json=clientBusinessName,proto3" json:"client_business_name,omitempty"`
+	ClientContactName    string                 `protobuf:"bytes,12,opt,name=client_contact_name,json=clientContactName,proto3" json:"client_contact_name,omitempty"`
+	ClientEmail          string                 `protobuf:"bytes,13,opt,name=client_email,json=clientEmail,proto3" json:"client_email,omitempty"`
+	ClientPhone          string                 `protobuf:"bytes,14,opt,name=client_phone,json=clientPhone,proto3" json:"client_phone,omitempty"`
+	ClientAddress        string                 `protobuf:"bytes,15,opt,name=client_address,json=clientAddress,proto3" json:"client_address,omitempty"`
+	ClientAbn            string                 `protobuf:"bytes,16,opt,name=client_abn,json=clientAbn,proto3" json:"client_abn,omitempty"`
+	IssueDate            string                 `protobuf:"bytes,17,opt,name=issue_date,json=issueDate,proto3" json:"issue_date,omitempty"`
+	DueDate              string                 `protobuf:"bytes,18,opt,name=due_date,json=dueDate,proto3" json:"due_date,omitempty"`
+	Subtotal             string                 `protobuf:"bytes,19,opt,name=subtotal,proto3" json:"subtotal,omitempty"`
+	DiscountAmount       string                 `protobuf:"bytes,20,opt,name=discount_amount,json=discountAmount,proto3" json:"discount_amount,omitempty"`
+	DiscountDescription  string                 `protobuf:"bytes,21,opt,name=discount_description,json=discountDescription,proto3" json:"discount_description,omitempty"`
+	GstAmount            string                 `protobuf:"bytes,22,opt,name=gst_amount,json=gstAmount,proto3" json:"gst_amount,omitempty"`
+	Total                string                 `protobuf:"bytes,23,opt,name=total,proto3" json:"total,omitempty"`
+	AmountPaid           string                 `protobuf:"bytes,24,opt,name=amount_paid,json=amountPaid,proto3" json:"amount_paid,omitempty"`
+	GstRegistered        bool                   `protobuf:"varint,25,opt,name=gst_registered,json=gstRegistered,proto3" json:"gst_registered,omitempty"`
+	GstRate              string                 `protobuf:"bytes,26,opt,name=gst_rate,json=gstRate,proto3" json:"gst_rate,omitempty"`
+	PaymentTerms         string                 `protobuf:"bytes,27,opt,name=payment_terms,json=paymentTerms,proto3" json:"payment_terms,omitempty"`
+	PaymentTermsCustom   string                 `protobuf:"bytes,28,opt,name=payment_terms_custom,json=paymentTermsCustom,proto3" json:"payment_terms_custom,omitempty"`
+	Notes                string                 `protobuf:"bytes,29,opt,name=notes,proto3" json:"notes,omitempty"`
+	PublicNotes          string                 `protobuf:"bytes,30,opt,name=public_notes,json=publicNotes,proto3" json:"public_notes,omitempty"`
+	ViewCount            int32                  `protobuf:"varint,31,opt,name=view_count,json=viewCount,proto3" json:"view_count,omitempty"`
+	LastViewedAt         string                 `protobuf:"bytes,32,opt,name=last_viewed_at,json=lastViewedAt,proto3" json:"last_viewed_at,omitempty"`
+	SentAt               string                 `protobuf:"bytes,33,opt,name=sent_at,json=sentAt,proto3" json:"sent_at,omitempty"`
+	PaidAt               string                 `protobuf:"bytes,34,opt,name=paid_at,json=paidAt,proto3" json:"paid_at,omitempty"`
+	PaymentMethod        string                 `protobuf:"bytes,35,opt,name=payment_method,json=paymentMethod,proto3" json:"payment_method,omitempty"`
+	PaymentReference     string                 `protobuf:"bytes,36,opt,name=payment_reference,json=paymentReference,proto3" json:"payment_reference,omitempty"`
+	PaymentNotes         string                 `protobuf:"bytes,37,opt,name=payment_notes,json=paymentNotes,proto3" json:"payment_notes,omitempty"`
+	PdfUrl               string                 `protobuf:"bytes,38,opt,name=pdf_url,json=pdfUrl,proto3" json:"pdf_url,omitempty"`
+	OnlinePaymentEnabled bool                   `protobuf:"varint,39,opt,name=online_payment_enabled,json=onlinePaymentEnabled,proto3" json:"online_payment_enabled,omitempty"`
+	StripePaymentLinkUrl string                 `protobuf:"bytes,40,opt,name=stripe_payment_link_url,json=stripePaymentLinkUrl,proto3" json:"stripe_payment_link_url,omitempty"`
+	CreatedBy            string                 `protobuf:"bytes,41,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	// Only set when a single invoice is returned
+	LineItems     []*InvoiceLineItem `protobuf:"bytes,42,rep,name=line_items,json=lineItems,proto3" json:"line_items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Invoice) Reset() {
+	*x = Invoice{}
+	mi := &file_invoice_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Invoice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Invoice) ProtoMessage() {}
+
+func (x *Invoice) ProtoReflect() protoreflect.Message {
+	mi := &file_invoice_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Invoice.ProtoReflect.Descriptor instead.
+func (*Invoice) Descriptor() ([]byte, []int) {
+	return file_invoice_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Invoice) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Invoice) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Invoice) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *Invoice) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *Invoice) GetProposalId() string {
+	if x != nil {
+		return x.ProposalId
+	}
+	return ""
+}
+
+func (x *Invoice) GetContractId() string {
+	if x != nil {
+		return x.ContractId
+	}
+	return ""
+}
+
+func (x *Invoice) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *Invoice) GetInvoiceNumber() string {
+	if x != nil {
+		return x.InvoiceNumber
+	}
+	return ""
+}
+
+func (x *Invoice) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *Invoice) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Invoice) GetClientBusinessName() string {
+	if x != nil {
+		return x.ClientBusinessName
+	}
+	return ""
+}
+
+func (x *Invoice) GetClientContactName() string {
+	if x != nil {
+		return x.ClientContactName
+	}
+	return ""
+}
+
+func (x *Invoice) GetClientEmail() string {
+	if x != nil {
+		return x.ClientEmail
+	}
+	return ""
+}
+
+func (x *Invoice) GetClientPhone() string {
+	if x != nil {
+		return x.ClientPhone
+	}
+	return ""
+}
+
+func (x *Invoice) GetClientAddress() string {
+	if x != nil {
+		return x.ClientAddress
+	}
+	return ""
+}
+
+func (x *Invoice) GetClientAbn() string {
+	if x != nil {
+		return x.ClientAbn
+	}
+	return ""
+}
+
+func (x *Invoice) GetIssueDate() string {
+	if x != nil {
+		return x.IssueDate
+	}
+	return ""
+}
+
+func (x *Invoice) GetDueDate() string {
+	if x != nil {
+		return x.DueDate
+	}
+	return ""
+}
+
+func (x *Invoice) GetSubtotal() string {
+	if x != nil {
+		return x.Subtotal
+	}
+	return ""
+}
+
+func (x *Invoice) GetDiscountAmount() string {
+	if x != nil {
+		return x.DiscountAmount
+	}
+	return ""
+}
+
+func (x *Invoice) GetDiscountDescription() string {
+	if x != nil {
+		return x.DiscountDescription
+	}
+	return ""
+}
+
+func (x *Invoice) GetGstAmount() string {
+	if x != nil {
+		return x.GstAmount
+	}
+	return ""
+}
+
+func (x *Invoice) GetTotal() string {
+	if x != nil {
+		return x.Total
+	}
+	return ""
+}
+
+func (x *Invoice) GetAmountPaid() string {
+	if x != nil {
+		return x.AmountPaid
+	}
+	return ""
+}
+
+func (x *Invoice) GetGstRegistered() bool {
+	if x != nil {
+		return x.GstRegistered
+	}
+	return false
+}
+
+func (x *Invoice) GetGstRate() string {
+	if x != nil {
+		return x.GstRate
+	}
+	return ""
+}
+
+func (x *Invoice) GetPaymentTerms() string {
+	if x != nil {
+		return x.PaymentTerms
+	}
+	return ""
+}
+
+func (x *Invoice) GetPaymentTermsCustom() string {
+	if x != nil {
+		return x.PaymentTermsCustom
+	}
+	return ""
+}
+
+func (x *Invoice) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *Invoice) GetPublicNotes() string {
+	if x != nil {
+		return x.PublicNotes
+	}
+	return ""
+}
+
+func (x *Invoice) GetViewCount() int32 {
+	if x != nil {
+		return x.ViewCount
+	}
+	return 0
+}
+
+func (x *Invoice) GetLastViewedAt() string {
+	if x != nil {
+		return x.LastViewedAt
+	}
+	return ""
+}
+
+func (x *Invoice) GetSentAt() string {
+	if x != nil {
+		return x.SentAt
+	}
+	return ""
+}
+
+func (x *Invoice) GetPaidAt() string {
+	if x != nil {
+		return x.PaidAt
+	}
+	return ""
+}
+
+func (x *Invoice) GetPaymentMethod() string {
+	if x != nil {
+		return x.PaymentMethod
+	}
+	return ""
+}
+
+func (x *Invoice) GetPaymentReference() string {
+	if x != nil {
+		return x.PaymentReference
+	}
+	return ""
+}
+
+func (x *Invoice) GetPaymentNotes() string {
+	if x != nil {
+		return x.PaymentNotes
+	}
+	return ""
+}
+
+func (x *Invoice) GetPdfUrl() string {
+	if x != nil {
+		return x.PdfUrl
+	}
+	return ""
+}
+
+func (x *Invoice) GetOnlinePaymentEnabled() bool {
+	if x != nil {
+		return x.OnlinePaymentEnabled
+	}
+	return false
+}
+
+func (x *Invoice) GetStripePaymentLinkUrl() string {
+	if x != nil {
+		return x.StripePaymentLinkUrl
+	}
+	return ""
+}
+
+func (x *Invoice) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *Invoice) GetLineItems() []*InvoiceLineItem {
+	if x != nil {
+		return x.LineItems
+	}
+	return nil
+}
+
+type InvoiceLineItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	InvoiceId     string                 `protobuf:"bytes,2,opt,name=invoice_id,json=invoiceId,proto3" json:"invoice_id,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Quantity      string                 `protobuf:"bytes,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	UnitPrice     string                 `protobuf:"bytes,5,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	Amount        string                 `protobuf:"bytes,6,opt,name=amount,proto3" json:"amount,omitempty"`
+	IsTaxable     bool                   `protobuf:"varint,7,opt,name=is_taxable,json=isTaxable,proto3" json:"is_taxable,omitempty"`
+	SortOrder     int32                  `protobuf:"varint,8,opt,name=sort_order,json=sortOrder,proto3" json:"sort_order,omitempty"`
+	Category      string                 `protobuf:"bytes,9,opt,name=category,proto3" json:"category,omitempty"`
+	PackageId     string                 `protobuf:"bytes,10,opt,name=package_id,json=packageId,proto3" json:"package_id,omitempty"`
+	AddonId       string                 `protobuf:"bytes,11,opt,name=addon_id,json=addonId,proto3" json:"addon_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvoiceLineItem) Reset() {
+	*x = InvoiceLineItem{}
+	mi := &file_invoice_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvoiceLineItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvoiceLineItem) ProtoMessage() {}
+
+func (x *InvoiceLineItem) ProtoReflect() protoreflect.Message {
+	mi := &file_invoice_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvoiceLineItem.ProtoReflect.Descriptor instead.
+func (*InvoiceLineItem) Descriptor() ([]byte, []int) {
+	return file_invoice_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *InvoiceLineItem) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *InvoiceLineItem) GetInvoiceId() string {
+	if x != nil {
+		return x.InvoiceId
+	}
+	return ""
+}
+
+func (x *InvoiceLineItem) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *InvoiceLineItem) GetQuantity() string {
+	if x != nil {
+		return x.Quantity
+	}
+	return ""
+}
+
+func (x *InvoiceLineItem) GetUnitPrice() string {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return ""
+}
+
+func (x *InvoiceLineItem) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *InvoiceLineItem) GetIsTaxable() bool {
+	if x != nil {
+		return x.IsTaxable
+	}
+	return false
+}
+
+func (x *InvoiceLineItem) GetSortOrder() int32 {
+	if x != nil {
+		return x.SortOrder
+	}
+	return 0
+}
+
+func (x *InvoiceLineItem) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *InvoiceLineItem) GetPackageId() string {
+	if x != nil {
+		return x.PackageId
+	}
+	return ""
+}
+
+func (x *InvoiceLineItem) GetAddonId() string {
+	if x != nil {
+		return x.AddonId
+	}
+	return ""
+}
+
+type InvoiceID struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgencyId      string                 `protobuf:"bytes,1,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvoiceID) Reset() {
+	*x = InvoiceID{}
+	mi := &file_invoice_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvoiceID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvoiceID) ProtoMessage() {}
+
+func (x *InvoiceID) ProtoReflect() protoreflect.Message {
+	mi := &file_invoice_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvoiceID.ProtoReflect.Descriptor instead.
+func (*InvoiceID) Descriptor() ([]byte, []int) {
+	return file_invoice_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *InvoiceID) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *InvoiceID) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type InvoiceListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgencyId      string                 `protobuf:"bytes,1,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Page          int64                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	Limit         int64                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvoiceListRequest) Reset() {
+	*x = InvoiceListRequest{}
+	mi := &file_invoice_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvoiceListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvoiceListRequest) ProtoMessage() {}
+
+func (x *InvoiceListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_invoice_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvoiceListRequest.ProtoReflect.Descriptor instead.
+func (*InvoiceListRequest) Descriptor() ([]byte, []int) {
+	return file_invoice_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *InvoiceListRequest) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *InvoiceListRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *InvoiceListRequest) GetPage() int64 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *InvoiceListRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Creates a draft invoice from an accepted proposal or a signed contract.
+type InvoiceSourceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgencyId      string                 `protobuf:"bytes,1,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	SourceId      string                 `protobuf:"bytes,2,opt,name=source_id,json=sourceId,proto3" json:"source_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvoiceSourceRequest) Reset() {
+	*x = InvoiceSourceRequest{}
+	mi := &file_invoice_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvoiceSourceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvoiceSourceRequest) ProtoMessage() {}
+
+func (x *InvoiceSourceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_invoice_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvoiceSourceRequest.ProtoReflect.Descriptor instead.
+func (*InvoiceSourceRequest) Descriptor() ([]byte, []int) {
+	return file_invoice_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *InvoiceSourceRequest) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *InvoiceSourceRequest) GetSourceId() string {
+	if x != nil {
+		return x.SourceId
+	}
+	return ""
+}
+
+type CreateInvoiceRequest struct {
+	state               protoimpl.MessageState    `protogen:"open.v1"`
+	AgencyId            string                    `protobuf:"bytes,1,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	ClientId            string                    `protobuf:"bytes,2,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	ClientBusinessName  string                    `protobuf:"bytes,3,opt,name=client_business_name,json=clientBusinessName,proto3" json:"client_business_name,omitempty"`
+	ClientContactName   string                    `protobuf:"bytes,4,opt,name=client_contact_name,json=clientContactName,proto3" json:"client_contact_name,omitempty"`
+	ClientEmail         string                    `protobuf:"bytes,5,opt,name=client_email,json=clientEmail,proto3" json:"client_email,omitempty"`
+	ClientPhone         string                    `protobuf:"bytes,6,opt,name=client_phone,json=clientPhone,proto3" json:"client_phone,omitempty"`
+	ClientAddress       string                    `protobuf:"bytes,7,opt,name=client_address,json=clientAddress,proto3" json:"client_address,omitempty"`
+	ClientAbn           string                    `protobuf:"bytes,8,opt,name=client_abn,json=clientAbn,proto3" json:"client_abn,omitempty"`
+	IssueDate           string                    `protobuf:"bytes,9,opt,name=issue_date,json=issueDate,proto3" json:"issue_date,omitempty"`
+	DueDate             string                    `protobuf:"bytes,10,opt,name=due_date,json=dueDate,proto3" json:"due_date,omitempty"`
+	PaymentTerms        string                    `protobuf:"bytes,11,opt,name=payment_terms,json=paymentTerms,proto3" json:"payment_terms,omitempty"`
+	PaymentTermsCustom  string                    `protobuf:"bytes,12,opt,name=payment_terms_custom,json=paymentTermsCustom,proto3" json:"payment_terms_custom,omitempty"`
+	Notes               string                    `protobuf:"bytes,13,opt,name=notes,proto3" json:"notes,omitempty"`
+	PublicNotes         string                    `protobuf:"bytes,14,opt,name=public_notes,json=publicNotes,proto3" json:"public_notes,omitempty"`
+	DiscountAmount      string                    `protobuf:"bytes,15,opt,name=discount_amount,json=discountAmount,proto3" json:"discount_amount,omitempty"`
+	DiscountDescription string                    `protobuf:"bytes,16,opt,name=discount_description,json=discountDescription,proto3" json:"discount_description,omitempty"`
+	LineItems           []*InvoiceLineItemRequest `protobuf:"bytes,17,rep,name=line_items,json=lineItems,proto3" json:"line_items,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *CreateInvoiceRequest) Reset() {
+	*x = CreateInvoiceRequest{}
+	mi := &file_invoice_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateInvoiceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateInvoiceRequest) ProtoMessage() {}
+
+func (x *CreateInvoiceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_invoice_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateInvoiceRequest.ProtoReflect.Descriptor instead.
+func (*CreateInvoiceRequest) Descriptor() ([]byte, []int) {
+	return file_invoice_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateInvoiceRequest) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetClientBusinessName() string {
+	if x != nil {
+		return x.ClientBusinessName
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetClientContactName() string {
+	if x != nil {
+		return x.ClientContactName
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetClientEmail() string {
+	if x != nil {
+		return x.ClientEmail
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetClientPhone() string {
+	if x != nil {
+		return x.ClientPhone
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetClientAddress() string {
+	if x != nil {
+		return x.ClientAddress
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetClientAbn() string {
+	if x != nil {
+		return x.ClientAbn
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetIssueDate() string {
+	if x != nil {
+		return x.IssueDate
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetDueDate() string {
+	if x != nil {
+		return x.DueDate
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetPaymentTerms() string {
+	if x != nil {
+		return x.PaymentTerms
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetPaymentTermsCustom() string {
+	if x != nil {
+		return x.PaymentTermsCustom
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetPublicNotes() string {
+	if x != nil {
+		return x.PublicNotes
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetDiscountAmount() string {
+	if x != nil {
+		return x.DiscountAmount
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetDiscountDescription() string {
+	if x != nil {
+		return x.DiscountDescription
+	}
+	return ""
+}
+
+func (x *CreateInvoiceRequest) GetLineItems() []*InvoiceLineItemRequest {
+	if x != nil {
+		return x.LineItems
+	}
+	return nil
+}
+
+// Unset fields are left unchanged.
+type EditInvoiceRequest struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	AgencyId             string                 `protobuf:"bytes,1,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	Id                   string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	ClientBusinessName   *string                `protobuf:"bytes,3,opt,name=client_business_name,json=clientBusinessName,proto3,oneof" json:"client_business_name,omitempty"`
+	ClientContactName    *string                `protobuf:"bytes,4,opt,name=client_contact_name,json=clientContactName,proto3,oneof" json:"client_contact_name,omitempty"`
+	ClientEmail          *string                `protobuf:"bytes,5,opt,name=client_email,json=clientEmail,proto3,oneof" json:"client_email,omitempty"`
+	ClientPhone          *string                `protobuf:"bytes,6,opt,name=client_phone,json=clientPhone,proto3,oneof" json:"client_phone,omitempty"`
+	ClientAddress        *string                `protobuf:"bytes,7,opt,name=client_address,json=clientAddress,proto3,oneof" json:"client_address,omitempty"`
+	ClientAbn            *string                `protobuf:"bytes,8,opt,name=client_abn,json=clientAbn,proto3,oneof" json:"client_abn,omitempty"`
+	IssueDate            *string                `protobuf:"bytes,9,opt,name=issue_date,json=issueDate,proto3,oneof" json:"issue_date,omitempty"`
+	DueDate              *string                `protobuf:"bytes,10,opt,name=due_date,json=dueDate,proto3,oneof" json:"due_date,omitempty"`
+	PaymentTerms         *string                `protobuf:"bytes,11,opt,name=payment_terms,json=paymentTerms,proto3,oneof" json:"payment_terms,omitempty"`
+	PaymentTermsCustom   *string                `protobuf:"bytes,12,opt,name=payment_terms_custom,json=paymentTermsCustom,proto3,oneof" json:"payment_terms_custom,omitempty"`
+	Notes                *string                `protobuf:"bytes,13,opt,name=notes,proto3,oneof" json:"notes,omitempty"`
+	PublicNotes          *string                `protobuf:"bytes,14,opt,name=public_notes,json=publicNotes,proto3,oneof" json:"public_notes,omitempty"`
+	DiscountAmount       *string                `protobuf:"bytes,15,opt,name=discount_amount,json=discountAmount,proto3,oneof" json:"discount_amount,omitempty"`
+	DiscountDescription  *string                `protobuf:"bytes,16,opt,name=discount_description,json=discountDescription,proto3,oneof" json:"discount_description,omitempty"`
+	OnlinePaymentEnabled *bool                  `protobuf:"varint,17,opt,name=online_payment_enabled,json=onlinePaymentEnabled,proto3,oneof" json:"online_payment_enabled,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *EditInvoiceRequest) Reset() {
+	*x = EditInvoiceRequest{}
+	mi := &file_invoice_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditInvoiceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditInvoiceRequest) ProtoMessage() {}
+
+func (x *EditInvoiceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_invoice_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditInvoiceRequest.ProtoReflect.Descriptor instead.
+func (*EditInvoiceRequest) Descriptor() ([]byte, []int) {
+	return file_invoice_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *EditInvoiceRequest) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetClientBusinessName() string {
+	if x != nil && x.ClientBusinessName != nil {
+		return *x.ClientBusinessName
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetClientContactName() string {
+	if x != nil && x.ClientContactName != nil {
+		return *x.ClientContactName
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetClientEmail() string {
+	if x != nil && x.ClientEmail != nil {
+		return *x.ClientEmail
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetClientPhone() string {
+	if x != nil && x.ClientPhone != nil {
+		return *x.ClientPhone
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetClientAddress() string {
+	if x != nil && x.ClientAddress != nil {
+		return *x.ClientAddress
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetClientAbn() string {
+	if x != nil && x.ClientAbn != nil {
+		return *x.ClientAbn
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetIssueDate() string {
+	if x != nil && x.IssueDate != nil {
+		return *x.IssueDate
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetDueDate() string {
+	if x != nil && x.DueDate != nil {
+		return *x.DueDate
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetPaymentTerms() string {
+	if x != nil && x.PaymentTerms != nil {
+		return *x.PaymentTerms
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetPaymentTermsCustom() string {
+	if x != nil && x.PaymentTermsCustom != nil {
+		return *x.PaymentTermsCustom
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetNotes() string {
+	if x != nil && x.Notes != nil {
+		return *x.Notes
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetPublicNotes() string {
+	if x != nil && x.PublicNotes != nil {
+		return *x.PublicNotes
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetDiscountAmount() string {
+	if x != nil && x.DiscountAmount != nil {
+		return *x.DiscountAmount
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetDiscountDescription() string {
+	if x != nil && x.DiscountDescription != nil {
+		return *x.DiscountDescription
+	}
+	return ""
+}
+
+func (x *EditInvoiceRequest) GetOnlinePaymentEnabled() bool {
+	if x != nil && x.OnlinePaymentEnabled != nil {
+		return *x.OnlinePaymentEnabled
+	}
+	return false
+}
+
+// The line item id is only used when editing or removing.
+type InvoiceLineItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgencyId      string                 `protobuf:"bytes,1,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	InvoiceId     string                 `protobuf:"bytes,2,opt,name=invoice_id,json=invoiceId,proto3" json:"invoice_id,omitempty"`
+	Id            string                 `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Quantity      string                 `protobuf:"bytes,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	UnitPrice     string                 `protobuf:"bytes,6,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	IsTaxable     *bool                  `protobuf:"varint,7,opt,name=is_taxable,json=isTaxable,proto3,oneof" json:"is_taxable,omitempty"`
+	SortOrder     *int32                 `protobuf:"varint,8,opt,name=sort_order,json=sortOrder,proto3,oneof" json:"sort_order,omitempty"`
+	Category      string                 `protobuf:"bytes,9,opt,name=category,proto3" json:"category,omitempty"`
+	PackageId     string                 `protobuf:"bytes,10,opt,name=package_id,json=packageId,proto3" json:"package_id,omitempty"`
+	AddonId       string                 `protobuf:"bytes,11,opt,name=addon_id,json=addonId,proto3" json:"addon_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvoiceLineItemRequest) Reset() {
+	*x = InvoiceLineItemRequest{}
+	mi := &file_invoice_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvoiceLineItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvoiceLineItemRequest) ProtoMessage() {}
+
+func (x *InvoiceLineItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_invoice_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvoiceLineItemRequest.ProtoReflect.Descriptor instead.
+func (*InvoiceLineItemRequest) Descriptor() ([]byte, []int) {
+	return file_invoice_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *InvoiceLineItemRequest) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *InvoiceLineItemRequest) GetInvoiceId() string {
+	if x != nil {
+		return x.InvoiceId
+	}
+	return ""
+}
+
+func (x *InvoiceLineItemRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *InvoiceLineItemRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *InvoiceLineItemRequest) GetQuantity() string {
+	if x != nil {
+		return x.Quantity
+	}
+	return ""
+}
+
+func (x *InvoiceLineItemRequest) GetUnitPrice() string {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return ""
+}
+
+func (x *InvoiceLineItemRequest) GetIsTaxable() bool {
+	if x != nil && x.IsTaxable != nil {
+		return *x.IsTaxable
+	}
+	return false
+}
+
+func (x *InvoiceLineItemRequest) GetSortOrder() int32 {
+	if x != nil && x.SortOrder != nil {
+		return *x.SortOrder
+	}
+	return 0
+}
+
+func (x *InvoiceLineItemRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *InvoiceLineItemRequest) GetPackageId() string {
+	if x != nil {
+		return x.PackageId
+	}
+	return ""
+}
+
+func (x *InvoiceLineItemRequest) GetAddonId() string {
+	if x != nil {
+		return x.AddonId
+	}
+	return ""
+}
+
+type InvoiceStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgencyId      string                 `protobuf:"bytes,1,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvoiceStatusRequest) Reset() {
+	*x = InvoiceStatusRequest{}
+	mi := &file_invoice_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvoiceStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvoiceStatusRequest) ProtoMessage() {}
+
+func (x *InvoiceStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_invoice_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvoiceStatusRequest.ProtoReflect.Descriptor instead.
+func (*InvoiceStatusRequest) Descriptor() ([]byte, []int) {
+	return file_invoice_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *InvoiceStatusRequest) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *InvoiceStatusRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *InvoiceStatusRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// An empty amount pays the outstanding balance in full.
+type InvoicePaymentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgencyId      string                 `protobuf:"bytes,1,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Amount        string                 `protobuf:"bytes,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Method        string                 `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+	Reference     string                 `protobuf:"bytes,5,opt,name=reference,proto3" json:"reference,omitempty"`
+	Notes         string                 `protobuf:"bytes,6,opt,name=notes,proto3" json:"notes,omitempty"`
+	PaidAt        string                 `protobuf:"bytes,7,opt,name=paid_at,json=paidAt,proto3" json:"paid_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvoicePaymentRequest) Reset() {
+	*x = InvoicePaymentRequest{}
+	mi := &file_invoice_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvoicePaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvoicePaymentRequest) ProtoMessage() {}
+
+func (x *InvoicePaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_invoice_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvoicePaymentRequest.ProtoReflect.Descriptor instead.
+func (*InvoicePaymentRequest) Descriptor() ([]byte, []int) {
+	return file_invoice_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *InvoicePaymentRequest) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *InvoicePaymentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *InvoicePaymentRequest) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *InvoicePaymentRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *InvoicePaymentRequest) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+func (x *InvoicePaymentRequest) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *InvoicePaymentRequest) GetPaidAt() string {
+	if x != nil {
+		return x.PaidAt
+	}
+	return ""
+}
+
+var File_invoice_proto protoreflect.FileDescriptor
+
+const file_invoice_proto_rawDesc = "" +
+	"\n" +
+	"\rinvoice.proto\x12\x05proto\"\xba\v\n" +
+	"\aInvoice\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\tR\tupdatedAt\x12\x1b\n" +
+	"\tagency_id\x18\x04 \x01(\tR\bagencyId\x12\x1f\n" +
+	"\vproposal_id\x18\x05 \x01(\tR\n" +
+	"proposalId\x12\x1f\n" +
+	"\vcontract_id\x18\x06 \x01(\tR\n" +
+	"contractId\x12\x1b\n" +
+	"\tclient_id\x18\a \x01(\tR\bclientId\x12%\n" +
+	"\x0einvoice_number\x18\b \x01(\tR\rinvoiceNumber\x12\x12\n" +
+	"\x04slug\x18\t \x01(\tR\x04slug\x12\x16\n" +
+	"\x06status\x18\n" +
+	" \x01(\tR\x06status\x120\n" +
+	"\x14client_business_name\x18\v \x01(\tR\x12clientBusinessName\x12.\n" +
+	"\x13client_contact_name\x18\f \x01(\tR\x11clientContactName\x12!\n" +
+	"\fclient_email\x18\r \x01(\tR\vclientEmail\x12!\n" +
+	"\fclient_phone\x18\x0e \x01(\tR\vclientPhone\x12%\n" +
+	"\x0eclient_address\x18\x0f \x01(\tR\rclientAddress\x12\x1d\n" +
+	"\n" +
+	"client_abn\x18\x10 \x01(\tR\tclientAbn\x12\x1d\n" +
+	"\n" +
+	"issue_date\x18\x11 \x01(\tR\tissueDate\x12\x19\n" +
+	"\bdue_date\x18\x12 \x01(\tR\adueDate\x12\x1a\n" +
+	"\bsubtotal\x18\x13 \x01(\tR\bsubtotal\x12'\n" +
+	"\x0fdiscount_amount\x18\x14 \x01(\tR\x0ediscountAmount\x121\n" +
+	"\x14discount_description\x18\x15 \x01(\tR\x13discountDescription\x12\x1d\n" +
+	"\n" +
+	"gst_amount\x18\x16 \x01(\tR\tgstAmount\x12\x14\n" +
+	"\x05total\x18\x17 \x01(\tR\x05total\x12\x1f\n" +
+	"\vamount_paid\x18\x18 \x01(\tR\n" +
+	"amountPaid\x12%\n" +
+	"\x0egst_registered\x18\x19 \x01(\bR\rgstRegistered\x12\x19\n" +
+	"\bgst_rate\x18\x1a \x01(\tR\agstRate\x12#\n" +
+	"\rpayment_terms\x18\x1b \x01(\tR\fpaymentTerms\x120\n" +
+	"\x14payment_terms_custom\x18\x1c \x01(\tR\x12paymentTermsCustom\x12\x14\n" +
+	"\x05notes\x18\x1d \x01(\tR\x05notes\x12!\n" +
+	"\fpublic_notes\x18\x1e \x01(\tR\vpublicNotes\x12\x1d\n" +
+	"\n" +
+	"view_count\x18\x1f \x01(\x05R\tviewCount\x12$\n" +
+	"\x0elast_viewed_at\x18  \x01(\tR\flastViewedAt\x12\x17\n" +
+	"\asent_at\x18! \x01(\tR\x06sentAt\x12\x17\n" +
+	"\apaid_at\x18\" \x01(\tR\x06paidAt\x12%\n" +
+	"\x0epayment_method\x18# \x01(\tR\rpaymentMethod\x12+\n" +
+	"\x11payment_reference\x18$ \x01(\tR\x10paymentReference\x12#\n" +
+	"\rpayment_notes\x18% \x01(\tR\fpaymentNotes\x12\x17\n" +
+	"\apdf_url\x18& \x01(\tR\x06pdfUrl\x124\n" +
+	"\x16online_payment_enabled\x18' \x01(\bR\x14onlinePaymentEnabled\x125\n" +
+	"\x17stripe_payment_link_url\x18( \x01(\tR\x14stripePaymentLinkUrl\x12\x1d\n" +
+	"\n" +
+	"created_by\x18) \x01(\tR\tcreatedBy\x125\n" +
+	"\n" +
+	"line_items\x18* \x03(\v2\x16.proto.InvoiceLineItemR\tlineItems\"\xc9\x02\n" +
+	"\x0fInvoiceLineItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"invoice_id\x18\x02 \x01(\tR\tinvoiceId\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\tR\bquantity\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x05 \x01(\tR\tunitPrice\x12\x16\n" +
+	"\x06amount\x18\x06 \x01(\tR\x06amount\x12\x1d\n" +
+	"\n" +
+	"is_taxable\x18\a \x01(\bR\tisTaxable\x12\x1d\n" +
+	"\n" +
+	"sort_order\x18\b \x01(\x05R\tsortOrder\x12\x1a\n" +
+	"\bcategory\x18\t \x01(\tR\bcategory\x12\x1d\n" +
+	"\n" +
+	"package_id\x18\n" +
+	" \x01(\tR\tpackageId\x12\x19\n" +
+	"\baddon_id\x18\v \x01(\tR\aaddonId\"8\n" +
+	"\tInvoiceID\x12\x1b\n" +
+	"\tagency_id\x18\x01 \x01(\tR\bagencyId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"s\n" +
+	"\x12InvoiceListRequest\x12\x1b\n" +
+	"\tagency_id\x18\x01 \x01(\tR\bagencyId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x03R\x04page\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x03R\x05limit\"P\n" +
+	"\x14InvoiceSourceRequest\x12\x1b\n" +
+	"\tagency_id\x18\x01 \x01(\tR\bagencyId\x12\x1b\n" +
+	"\tsource_id\x18\x02 \x01(\tR\bsourceId\"\xa2\x05\n" +
+	"\x14CreateInvoiceRequest\x12\x1b\n" +
+	"\tagency_id\x18\x01 \x01(\tR\bagencyId\x12\x1b\n" +
+	"\tclient_id\x18\x02 \x01(\tR\bclientId\x120\n" +
+	"\x14client_business_name\x18\x03 \x01(\tR\x12clientBusinessName\x12.\n" +
+	"\x13client_contact_name\x18\x04 \x01(\tR\x11clientContactName\x12!\n" +
+	"\fclient_email\x18\x05 \x01(\tR\vclientEmail\x12!\n" +
+	"\fclient_phone\x18\x06 \x01(\tR\vclientPhone\x12%\n" +
+	"\x0eclient_address\x18\a \x01(\tR\rclientAddress\x12\x1d\n" +
+	"\n" +
+	"client_abn\x18\b \x01(\tR\tclientAbn\x12\x1d\n" +
+	"\n" +
+	"issue_date\x18\t \x01(\tR\tissueDate\x12\x19\n" +
+	"\bdue_date\x18\n" +
+	" \x01(\tR\adueDate\x12#\n" +
+	"\rpayment_terms\x18\v \x01(\tR\fpaymentTerms\x120\n" +
+	"\x14payment_terms_custom\x18\f \x01(\tR\x12paymentTermsCustom\x12\x14\n" +
+	"\x05notes\x18\r \x01(\tR\x05notes\x12!\n" +
+	"\fpublic_notes\x18\x0e \x01(\tR\vpublicNotes\x12'\n" +
+	"\x0fdiscount_amount\x18\x0f \x01(\tR\x0ediscountAmount\x121\n" +
+	"\x14discount_description\x18\x10 \x01(\tR\x13discountDescription\x12<\n" +
+	"\n" +
+	"line_items\x18\x11 \x03(\v2\x1d.proto.InvoiceLineItemRequestR\tlineItems\"\xf5\a\n" +
+	"\x12EditInvoiceRequest\x12\x1b\n" +
+	"\tagency_id\x18\x01 \x01(\tR\bagencyId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x125\n" +
+	"\x14client_business_name\x18\x03 \x01(\tH\x00R\x12clientBusinessName\x88\x01\x01\x123\n" +
+	"\x13client_contact_name\x18\x04 \x01(\tH\x01R\x11clientContactName\x88\x01\x01\x12&\n" +
+	"\fclient_email\x18\x05 \x01(\tH\x02R\vclientEmail\x88\x01\x01\x12&\n" +
+	"\fclient_phone\x18\x06 \x01(\tH\x03R\vclientPhone\x88\x01\x01\x12*\n" +
+	"\x0eclient_address\x18\a \x01(\tH\x04R\rclientAddress\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"client_abn\x18\b \x01(\tH\x05R\tclientAbn\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"issue_date\x18\t \x01(\tH\x06R\tissueDate\x88\x01\x01\x12\x1e\n" +
+	"\bdue_date\x18\n" +
+	" \x01(\tH\aR\adueDate\x88\x01\x01\x12(\n" +
+	"\rpayment_terms\x18\v \x01(\tH\bR\fpaymentTerms\x88\x01\x01\x125\n" +
+	"\x14payment_terms_custom\x18\f \x01(\tH\tR\x12paymentTermsCustom\x88\x01\x01\x12\x19\n" +
+	"\x05notes\x18\r \x01(\tH\n" +
+	"R\x05notes\x88\x01\x01\x12&\n" +
+	"\fpublic_notes\x18\x0e \x01(\tH\vR\vpublicNotes\x88\x01\x01\x12,\n" +
+	"\x0fdiscount_amount\x18\x0f \x01(\tH\fR\x0ediscountAmount\x88\x01\x01\x126\n" +
+	"\x14discount_description\x18\x10 \x01(\tH\rR\x13discountDescription\x88\x01\x01\x129\n" +
+	"\x16online_payment_enabled\x18\x11 \x01(\bH\x0eR\x14onlinePaymentEnabled\x88\x01\x01B\x17\n" +
+	"\x15_client_business_nameB\x16\n" +
+	"\x14_client_contact_nameB\x0f\n" +
+	"\r_client_emailB\x0f\n" +
+	"\r_client_phoneB\x11\n" +
+	"\x0f_client_addressB\r\n" +
+	"\v_client_abnB\r\n" +
+	"\v_issue_dateB\v\n" +
+	"\t_due_dateB\x10\n" +
+	"\x0e_payment_termsB\x17\n" +
+	"\x15_payment_terms_customB\b\n" +
+	"\x06_notesB\x0f\n" +
+	"\r_public_notesB\x12\n" +
+	"\x10_discount_amountB\x17\n" +
+	"\x15_discount_descriptionB\x19\n" +
+	"\x17_online_payment_enabled\"\xfd\x02\n" +
+	"\x16InvoiceLineItemRequest\x12\x1b\n" +
+	"\tagency_id\x18\x01 \x01(\tR\bagencyId\x12\x1d\n" +
+	"\n" +
+	"invoice_id\x18\x02 \x01(\tR\tinvoiceId\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\tR\x02id\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bquantity\x18\x05 \x01(\tR\bquantity\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x06 \x01(\tR\tunitPrice\x12\"\n" +
+	"\n" +
+	"is_taxable\x18\a \x01(\bH\x00R\tisTaxable\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"sort_order\x18\b \x01(\x05H\x01R\tsortOrder\x88\x01\x01\x12\x1a\n" +
+	"\bcategory\x18\t \x01(\tR\bcategory\x12\x1d\n" +
+	"\n" +
+	"package_id\x18\n" +
+	" \x01(\tR\tpackageId\x12\x19\n" +
+	"\baddon_id\x18\v \x01(\tR\aaddonIdB\r\n" +
+	"\v_is_taxableB\r\n" +
+	"\v_sort_order\"[\n" +
+	"\x14InvoiceStatusRequest\x12\x1b\n" +
+	"\tagency_id\x18\x01 \x01(\tR\bagencyId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\"\xc1\x01\n" +
+	"\x15InvoicePaymentRequest\x12\x1b\n" +
+	"\tagency_id\x18\x01 \x01(\tR\bagencyId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\tR\x06amount\x12\x16\n" +
+	"\x06method\x18\x04 \x01(\tR\x06method\x12\x1c\n" +
+	"\treference\x18\x05 \x01(\tR\treference\x12\x14\n" +
+	"\x05notes\x18\x06 \x01(\tR\x05notes\x12\x17\n" +
+	"\apaid_at\x18\a \x01(\tR\x06paidAtB\x0eZ\fgofast/protob\x06proto3"
+
+var (
+	file_invoice_proto_rawDescOnce sync.Once
+	file_invoice_proto_rawDescData []byte
+)
+
+func file_invoice_proto_rawDescGZIP() []byte {
+	file_invoice_proto_rawDescOnce.Do(func() {
+		file_invoice_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_invoice_proto_rawDesc), len(file_invoice_proto_rawDesc)))
+	})
+	return file_invoice_proto_rawDescData
+}
+
+var file_invoice_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_invoice_proto_goTypes = []any{
+	(*Invoice)(nil),                // 0: proto.Invoice
+	(*InvoiceLineItem)(nil),        // 1: proto.InvoiceLineItem
+	(*InvoiceID)(nil),              // 2: proto.InvoiceID
+	(*InvoiceListRequest)(nil),     // 3: proto.InvoiceListRequest
+	(*InvoiceSourceRequest)(nil),   // 4: proto.InvoiceSourceRequest
+	(*CreateInvoiceRequest)(nil),   // 5: proto.CreateInvoiceRequest
+	(*EditInvoiceRequest)(nil),     // 6: proto.EditInvoiceRequest
+	(*InvoiceLineItemRequest)(nil), // 7: proto.InvoiceLineItemRequest
+	(*InvoiceStatusRequest)(nil),   // 8: proto.InvoiceStatusRequest
+	(*InvoicePaymentRequest)(nil),  // 9: proto.InvoicePaymentRequest
+}
+var file_invoice_proto_depIdxs = []int32{
+	1, // 0: proto.Invoice.line_items:type_name -> proto.InvoiceLineItem
+	7, // 1: proto.CreateInvoiceRequest.line_items:type_name -> proto.InvoiceLineItemRequest
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_invoice_proto_init() }
+func file_invoice_proto_init() {
+	if File_invoice_proto != nil {
+		return
+	}
+	file_invoice_proto_msgTypes[6].OneofWrappers = []any{}
+	file_invoice_proto_msgTypes[7].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_invoice_proto_rawDesc), len(file_invoice_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_invoice_proto_goTypes,
+		DependencyIndexes: file_invoice_proto_depIdxs,
+		MessageInfos:      file_invoice_proto_msgTypes,
+	}.Build()
+	File_invoice_proto = out.File
+	file_invoice_proto_goTypes = nil
+	file_invoice_proto_depIdxs = nil
+}
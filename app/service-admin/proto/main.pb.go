@@ -256,7 +256,7 @@ const file_main_proto_rawDesc = "" +
 	"\n" +
 	"main.proto\x12\x05proto\x1a\n" +
 	"user.proto\x1a\n" +
-	"note.proto\"\a\n" +
+	"note.proto\x1a\x0eproposal.proto\x1a\rinvoice.proto\x1a\x10submission.proto\"\a\n" +
 	"\x05Empty\"\x14\n" +
 	"\x02ID\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"7\n" +
@@ -281,7 +281,31 @@ const file_main_proto_rawDesc = "" +
 	"CreateNote\x12\x12.proto.NoteRequest\x1a\v.proto.Note\"\x00\x12-\n" +
 	"\bEditNote\x12\x12.proto.NoteRequest\x1a\v.proto.Note\"\x00\x12'\n" +
 	"\n" +
-	"RemoveNote\x12\t.proto.ID\x1a\f.proto.Empty\"\x00B\x0eZ\fgofast/protob\x06proto3"
+	"RemoveNote\x12\t.proto.ID\x1a\f.proto.Empty\"\x002\x8f\x04\n" +
+	"\x0fProposalService\x12?\n" +
+	"\fGetProposals\x12\x1a.proto.ProposalListRequest\x1a\x0f.proto.Proposal\"\x000\x01\x127\n" +
+	"\x0fGetProposalByID\x12\x11.proto.ProposalID\x1a\x0f.proto.Proposal\"\x00\x12A\n" +
+	"\x0eCreateProposal\x12\x1c.proto.CreateProposalRequest\x1a\x0f.proto.Proposal\"\x00\x12=\n" +
+	"\fEditProposal\x12\x1a.proto.EditProposalRequest\x1a\x0f.proto.Proposal\"\x00\x12I\n" +
+	"\x15UpdateProposalSection\x12\x1d.proto.ProposalSectionRequest\x1a\x0f.proto.Proposal\"\x00\x129\n" +
+	"\x11DuplicateProposal\x12\x11.proto.ProposalID\x1a\x0f.proto.Proposal\"\x00\x12E\n" +
+	"\x12TransitionProposal\x12\x1c.proto.ProposalStatusRequest\x1a\x0f.proto.Proposal\"\x00\x123\n" +
+	"\x0eRemoveProposal\x12\x11.proto.ProposalID\x1a\f.proto.Empty\"\x002\xb0\x06\n" +
+	"\x0eInvoiceService\x12<\n" +
+	"\vGetInvoices\x12\x19.proto.InvoiceListRequest\x1a\x0e.proto.Invoice\"\x000\x01\x124\n" +
+	"\x0eGetInvoiceByID\x12\x10.proto.InvoiceID\x1a\x0e.proto.Invoice\"\x00\x12>\n" +
+	"\rCreateInvoice\x12\x1b.proto.CreateInvoiceRequest\x1a\x0e.proto.Invoice\"\x00\x12J\n" +
+	"\x19CreateInvoiceFromProposal\x12\x1b.proto.InvoiceSourceRequest\x1a\x0e.proto.Invoice\"\x00\x12J\n" +
+	"\x19CreateInvoiceFromContract\x12\x1b.proto.InvoiceSourceRequest\x1a\x0e.proto.Invoice\"\x00\x12:\n" +
+	"\vEditInvoice\x12\x19.proto.EditInvoiceRequest\x1a\x0e.proto.Invoice\"\x00\x12E\n" +
+	"\x12AddInvoiceLineItem\x12\x1d.proto.InvoiceLineItemRequest\x1a\x0e.proto.Invoice\"\x00\x12F\n" +
+	"\x13EditInvoiceLineItem\x12\x1d.proto.InvoiceLineItemRequest\x1a\x0e.proto.Invoice\"\x00\x12H\n" +
+	"\x15RemoveInvoiceLineItem\x12\x1d.proto.InvoiceLineItemRequest\x1a\x0e.proto.Invoice\"\x00\x12B\n" +
+	"\x11TransitionInvoice\x12\x1b.proto.InvoiceStatusRequest\x1a\x0e.proto.Invoice\"\x00\x12F\n" +
+	"\x14RecordInvoicePayment\x12\x1c.proto.InvoicePaymentRequest\x1a\x0e.proto.Invoice\"\x00\x121\n" +
+	"\rRemoveInvoice\x12\x10.proto.InvoiceID\x1a\f.proto.Empty\"\x002a\n" +
+	"\x11SubmissionService\x12L\n" +
+	"\x11ProcessSubmission\x12\x13.proto.SubmissionID\x1a .proto.ProcessSubmissionResponse\"\x00B\x0eZ\fgofast/protob\x06proto3"
 
 var (
 	file_main_proto_rawDescOnce sync.Once
@@ -297,39 +321,99 @@ func file_main_proto_rawDescGZIP() []byte {
 
 var file_main_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_main_proto_goTypes = []any{
-	(*Empty)(nil),         // 0: proto.Empty
-	(*ID)(nil),            // 1: proto.ID
-	(*PageRequest)(nil),   // 2: proto.PageRequest
-	(*CountResponse)(nil), // 3: proto.CountResponse
-	(*AuthResponse)(nil),  // 4: proto.AuthResponse
-	(*User)(nil),          // 5: proto.User
-	(*NoteRequest)(nil),   // 6: proto.NoteRequest
-	(*Note)(nil),          // 7: proto.Note
+	(*Empty)(nil),                     // 0: proto.Empty
+	(*ID)(nil),                        // 1: proto.ID
+	(*PageRequest)(nil),               // 2: proto.PageRequest
+	(*CountResponse)(nil),             // 3: proto.CountResponse
+	(*AuthResponse)(nil),              // 4: proto.AuthResponse
+	(*User)(nil),                      // 5: proto.User
+	(*NoteRequest)(nil),               // 6: proto.NoteRequest
+	(*ProposalListRequest)(nil),       // 7: proto.ProposalListRequest
+	(*ProposalID)(nil),                // 8: proto.ProposalID
+	(*CreateProposalRequest)(nil),     // 9: proto.CreateProposalRequest
+	(*EditProposalRequest)(nil),       // 10: proto.EditProposalRequest
+	(*ProposalSectionRequest)(nil),    // 11: proto.ProposalSectionRequest
+	(*ProposalStatusRequest)(nil),     // 12: proto.ProposalStatusRequest
+	(*InvoiceListRequest)(nil),        // 13: proto.InvoiceListRequest
+	(*InvoiceID)(nil),                 // 14: proto.InvoiceID
+	(*CreateInvoiceRequest)(nil),      // 15: proto.CreateInvoiceRequest
+	(*InvoiceSourceRequest)(nil),      // 16: proto.InvoiceSourceRequest
+	(*EditInvoiceRequest)(nil),        // 17: proto.EditInvoiceRequest
+	(*InvoiceLineItemRequest)(nil),    // 18: proto.InvoiceLineItemRequest
+	(*InvoiceStatusRequest)(nil),      // 19: proto.InvoiceStatusRequest
+	(*InvoicePaymentRequest)(nil),     // 20: proto.InvoicePaymentRequest
+	(*SubmissionID)(nil),              // 21: proto.SubmissionID
+	(*Note)(nil),                      // 22: proto.Note
+	(*Proposal)(nil),                  // 23: proto.Proposal
+	(*Invoice)(nil),                   // 24: proto.Invoice
+	(*ProcessSubmissionResponse)(nil), // 25: proto.ProcessSubmissionResponse
 }
 var file_main_proto_depIdxs = []int32{
-	0, // 0: proto.AuthService.Refresh:input_type -> proto.Empty
-	0, // 1: proto.UserService.GetAllUsers:input_type -> proto.Empty
-	1, // 2: proto.UserService.GetUserByID:input_type -> proto.ID
-	5, // 3: proto.UserService.EditUser:input_type -> proto.User
-	0, // 4: proto.NoteService.GetAllNotes:input_type -> proto.Empty
-	1, // 5: proto.NoteService.GetNoteByID:input_type -> proto.ID
-	6, // 6: proto.NoteService.CreateNote:input_type -> proto.NoteRequest
-	6, // 7: proto.NoteService.EditNote:input_type -> proto.NoteRequest
-	1, // 8: proto.NoteService.RemoveNote:input_type -> proto.ID
-	4, // 9: proto.AuthService.Refresh:output_type -> proto.AuthResponse
-	5, // 10: proto.UserService.GetAllUsers:output_type -> proto.User
-	5, // 11: proto.UserService.GetUserByID:output_type -> proto.User
-	5, // 12: proto.UserService.EditUser:output_type -> proto.User
-	7, // 13: proto.NoteService.GetAllNotes:output_type -> proto.Note
-	7, // 14: proto.NoteService.GetNoteByID:output_type -> proto.Note
-	7, // 15: proto.NoteService.CreateNote:output_type -> proto.Note
-	7, // 16: proto.NoteService.EditNote:output_type -> proto.Note
-	0, // 17: proto.NoteService.RemoveNote:output_type -> proto.Empty
-	9, // [9:18] is the sub-list for method output_type
-	0, // [0:9] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: proto.AuthService.Refresh:input_type -> proto.Empty
+	0,  // 1: proto.UserService.GetAllUsers:input_type -> proto.Empty
+	1,  // 2: proto.UserService.GetUserByID:input_type -> proto.ID
+	5,  // 3: proto.UserService.EditUser:input_type -> proto.User
+	0,  // 4: proto.NoteService.GetAllNotes:input_type -> proto.Empty
+	1,  // 5: proto.NoteService.GetNoteByID:input_type -> proto.ID
+	6,  // 6: proto.NoteService.CreateNote:input_type -> proto.NoteRequest
+	6,  // 7: proto.NoteService.EditNote:input_type -> proto.NoteRequest
+	1,  // 8: proto.NoteService.RemoveNote:input_type -> proto.ID
+	7,  // 9: proto.ProposalService.GetProposals:input_type -> proto.ProposalListRequest
+	8,  // 10: proto.ProposalService.GetProposalByID:input_type -> proto.ProposalID
+	9,  // 11: proto.ProposalService.CreateProposal:input_type -> proto.CreateProposalRequest
+	10, // 12: proto.ProposalService.EditProposal:input_type -> proto.EditProposalRequest
+	11, // 13: proto.ProposalService.UpdateProposalSection:input_type -> proto.ProposalSectionRequest
+	8,  // 14: proto.ProposalService.DuplicateProposal:input_type -> proto.ProposalID
+	12, // 15: proto.ProposalService.TransitionProposal:input_type -> proto.ProposalStatusRequest
+	8,  // 16: proto.ProposalService.RemoveProposal:input_type -> proto.ProposalID
+	13, // 17: proto.InvoiceService.GetInvoices:input_type -> proto.InvoiceListRequest
+	14, // 18: proto.InvoiceService.GetInvoiceByID:input_type -> proto.InvoiceID
+	15, // 19: proto.InvoiceService.CreateInvoice:input_type -> proto.CreateInvoiceRequest
+	16, // 20: proto.InvoiceService.CreateInvoiceFromProposal:input_type -> proto.InvoiceSourceRequest
+	16, // 21: proto.InvoiceService.CreateInvoiceFromContract:input_type -> proto.InvoiceSourceRequest
+	17, // 22: proto.InvoiceService.EditInvoice:input_type -> proto.EditInvoiceRequest
+	18, // 23: proto.InvoiceService.AddInvoiceLineItem:input_type -> proto.InvoiceLineItemRequest
+	18, // 24: proto.InvoiceService.EditInvoiceLineItem:input_type -> proto.InvoiceLineItemRequest
+	18, // 25: proto.InvoiceService.RemoveInvoiceLineItem:input_type -> proto.InvoiceLineItemRequest
+	19, // 26: proto.InvoiceService.TransitionInvoice:input_type -> proto.InvoiceStatusRequest
+	20, // 27: proto.InvoiceService.RecordInvoicePayment:input_type -> proto.InvoicePaymentRequest
+	14, // 28: proto.InvoiceService.RemoveInvoice:input_type -> proto.InvoiceID
+	21, // 29: proto.SubmissionService.ProcessSubmission:input_type -> proto.SubmissionID
+	4,  // 30: proto.AuthService.Refresh:output_type -> proto.AuthResponse
+	5,  // 31: proto.UserService.GetAllUsers:output_type -> proto.User
+	5,  // 32: proto.UserService.GetUserByID:output_type -> proto.User
+	5,  // 33: proto.UserService.EditUser:output_type -> proto.User
+	22, // 34: proto.NoteService.GetAllNotes:output_type -> proto.Note
+	22, // 35: proto.NoteService.GetNoteByID:output_type -> proto.Note
+	22, // 36: proto.NoteService.CreateNote:output_type -> proto.Note
+	22, // 37: proto.NoteService.EditNote:output_type -> proto.Note
+	0,  // 38: proto.NoteService.RemoveNote:output_type -> proto.Empty
+	23, // 39: proto.ProposalService.GetProposals:output_type -> proto.Proposal
+	23, // 40: proto.ProposalService.GetProposalByID:output_type -> proto.Proposal
+	23, // 41: proto.ProposalService.CreateProposal:output_type -> proto.Proposal
+	23, // 42: proto.ProposalService.EditProposal:output_type -> proto.Proposal
+	23, // 43: proto.ProposalService.UpdateProposalSection:output_type -> proto.Proposal
+	23, // 44: proto.ProposalService.DuplicateProposal:output_type -> proto.Proposal
+	23, // 45: proto.ProposalService.TransitionProposal:output_type -> proto.Proposal
+	0,  // 46: proto.ProposalService.RemoveProposal:output_type -> proto.Empty
+	24, // 47: proto.InvoiceService.GetInvoices:output_type -> proto.Invoice
+	24, // 48: proto.InvoiceService.GetInvoiceByID:output_type -> proto.Invoice
+	24, // 49: proto.InvoiceService.CreateInvoice:output_type -> proto.Invoice
+	24, // 50: proto.InvoiceService.CreateInvoiceFromProposal:output_type -> proto.Invoice
+	24, // 51: proto.InvoiceService.CreateInvoiceFromContract:output_type -> proto.Invoice
+	24, // 52: proto.InvoiceService.EditInvoice:output_type -> proto.Invoice
+	24, // 53: proto.InvoiceService.AddInvoiceLineItem:output_type -> proto.Invoice
+	24, // 54: proto.InvoiceService.EditInvoiceLineItem:output_type -> proto.Invoice
+	24, // 55: proto.InvoiceService.RemoveInvoiceLineItem:output_type -> proto.Invoice
+	24, // 56: proto.InvoiceService.TransitionInvoice:output_type -> proto.Invoice
+	24, // 57: proto.InvoiceService.RecordInvoicePayment:output_type -> proto.Invoice
+	0,  // 58: proto.InvoiceService.RemoveInvoice:output_type -> proto.Empty
+	25, // 59: proto.SubmissionService.ProcessSubmission:output_type -> proto.ProcessSubmissionResponse
+	30, // [30:60] is the sub-list for method output_type
+	0,  // [0:30] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_main_proto_init() }
@@ -339,6 +423,9 @@ func file_main_proto_init() {
 	}
 	file_user_proto_init()
 	file_note_proto_init()
+	file_proposal_proto_init()
+	file_invoice_proto_init()
+	file_submission_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
@@ -347,7 +434,7 @@ func file_main_proto_init() {
 			NumEnums:      0,
 			NumMessages:   5,
 			NumExtensions: 0,
-			NumServices:   3,
+			NumServices:   6,
 		},
 		GoTypes:           file_main_proto_goTypes,
 		DependencyIndexes: file_main_proto_depIdxs,
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"app/pkg/mailbox"
 	"service-admin/auth"
 	"service-admin/grpc"
 	"service-admin/pubsub"
@@ -12,6 +13,8 @@ type Handler struct {
 	conn        *grpc.Conn
 	broker      *pubsub.EventBroker
 	authService *auth.Service
+	// mailbox is the development mailbox, which is nil when it is disabled
+	mailbox *mailbox.Mailbox
 }
 
 func NewHandler(
@@ -19,11 +22,13 @@ func NewHandler(
 	conn *grpc.Conn,
 	broker *pubsub.EventBroker,
 	authService *auth.Service,
+	mailbox *mailbox.Mailbox,
 ) *Handler {
 	return &Handler{
 		cfg:         config,
 		conn:        conn,
 		broker:      broker,
 		authService: authService,
+		mailbox:     mailbox,
 	}
 }
@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"app/pkg/mailbox"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	mailboxpage "service-admin/web/pages/mailbox"
+	"strconv"
+	"time"
+)
+
+// maxMailboxWait caps how long the messages API waits for an email
+const maxMailboxWait = 5 * time.Second
+
+// handleMailbox shows the development mailbox. Like the rest of the mailbox
+// it is not behind authentication, since the link to sign in to the admin
+// is itself emailed to it.
+func (h *Handler) handleMailbox(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("_method") == "DELETE" {
+		h.mailbox.Clear()
+		http.Redirect(w, r, "/mailbox", http.StatusSeeOther)
+		return
+	}
+
+	to := r.URL.Query().Get("to")
+	messages := h.mailbox.Messages(to)
+	var selected *mailbox.Message
+	if id := r.URL.Query().Get("id"); id != "" {
+		selected, _ = h.mailbox.Message(id)
+	} else if len(messages) > 0 {
+		selected = messages[0]
+	}
+	err := mailboxpage.MailboxPage(h.cfg, messages, selected, to).Render(r.Context(), w)
+	if err != nil {
+		handleError(w, r, http.StatusInternalServerError, "Error rendering mailbox", err)
+	}
+}
+
+// handleMailboxHTML serves the HTML of an email to the mailbox's iframe.
+// The sandbox policy keeps the email's scripts from running.
+func (h *Handler) handleMailboxHTML(w http.ResponseWriter, r *http.Request) {
+	m, ok := h.mailbox.Message(r.PathValue("id"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Security-Policy", "sandbox allow-popups allow-popups-to-escape-sandbox; script-src 'none'")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, err := w.Write([]byte(m.HTML))
+	if err != nil {
+		slog.Error("Error writing email HTML", "error", err)
+	}
+}
+
+func (h *Handler) handleMailboxRaw(w http.ResponseWriter, r *http.Request) {
+	m, ok := h.mailbox.Message(r.PathValue("id"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, err := w.Write(m.Raw)
+	if err != nil {
+		slog.Error("Error writing raw email", "error", err)
+	}
+}
+
+func (h *Handler) handleMailboxAttachment(w http.ResponseWriter, r *http.Request) {
+	m, ok := h.mailbox.Message(r.PathValue("id"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	i, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil || i < 0 || i >= len(m.Attachments) {
+		http.NotFound(w, r)
+		return
+	}
+	a := m.Attachments[i]
+	filename := a.Filename
+	if filename == "" {
+		filename = fmt.Sprintf("attachment-%d", i+1)
+	}
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, err = w.Write(a.Content)
+	if err != nil {
+		slog.Error("Error writing email attachment", "error", err)
+	}
+}
+
+// handleMailboxMessages returns the emails in the mailbox, newest first.
+// End to end tests filter them by recipient, and can wait for an email to
+// arrive, for example to follow the link in a sign in email:
+//
+//	GET /api/mailbox/messages?to=jane@example.com&wait=5s
+func (h *Handler) handleMailboxMessages(w http.ResponseWriter, r *http.Request) {
+	to := r.URL.Query().Get("to")
+	if wait := r.URL.Query().Get("wait"); wait != "" {
+		timeout, err := time.ParseDuration(wait)
+		if err != nil {
+			writeMailboxJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid wait duration"})
+			return
+		}
+		_, _ = h.mailbox.Wait(to, min(timeout, maxMailboxWait))
+	}
+	writeMailboxJSON(w, http.StatusOK, h.mailbox.Messages(to))
+}
+
+func (h *Handler) handleMailboxMessage(w http.ResponseWriter, r *http.Request) {
+	m, ok := h.mailbox.Message(r.PathValue("id"))
+	if !ok {
+		writeMailboxJSON(w, http.StatusNotFound, map[string]string{"error": "Email not found"})
+		return
+	}
+	writeMailboxJSON(w, http.StatusOK, m)
+}
+
+func (h *Handler) handleMailboxClear(w http.ResponseWriter, _ *http.Request) {
+	h.mailbox.Clear()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeMailboxJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Error encoding mailbox response", "error", err)
+	}
+}
@@ -43,7 +43,7 @@ func (h *Handler) handleNotes(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		} else if err != nil {
-			handleError(w, r, http.StatusInternalServerError, "Error creating note", err)
+			handleError(w, r, statusFromError(err), "Error creating note", err)
 			return
 		}
 
@@ -74,7 +74,7 @@ func (h *Handler) handleNotes(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		if err != nil {
-			handleError(w, r, http.StatusInternalServerError, "Error updating note", err)
+			handleError(w, r, statusFromError(err), "Error updating note", err)
 			return
 		}
 		triggerPayload := fmt.Sprintf(`{"note-saved": {"id": "%s"}}`, noteID)
@@ -93,7 +93,7 @@ func (h *Handler) handleNotes(w http.ResponseWriter, r *http.Request) {
 		noteID := r.FormValue("id")
 		err := h.conn.RemoveNote(ctx, token, noteID)
 		if err != nil {
-			handleError(w, r, http.StatusInternalServerError, "Error deleting note", err)
+			handleError(w, r, statusFromError(err), "Error deleting note", err)
 			return
 		}
 		toast.SendToast(
@@ -106,7 +106,7 @@ func (h *Handler) handleNotes(w http.ResponseWriter, r *http.Request) {
 
 	allNotes, err := h.conn.GetAllNotes(ctx, token)
 	if err != nil {
-		handleError(w, r, http.StatusInternalServerError, "Error getting notes", err)
+		handleError(w, r, statusFromError(err), "Error getting notes", err)
 		return
 	}
 	// 1 sec cache
@@ -5,6 +5,9 @@ import (
 	"log/slog"
 	"net/http"
 	"service-admin/web/pages"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func Run(h *Handler) *http.Server {
@@ -46,6 +49,20 @@ func Run(h *Handler) *http.Server {
 	mux.HandleFunc("/users", h.handleUsers)
 	mux.HandleFunc("/users/calculate-access", h.handleCalculateAccess)
 
+	// Form submissions
+	mux.HandleFunc("/submissions/process", h.handleSubmissionProcess)
+
+	// Development mailbox
+	if h.mailbox != nil {
+		mux.HandleFunc("/mailbox", h.handleMailbox)
+		mux.HandleFunc("GET /mailbox/{id}/html", h.handleMailboxHTML)
+		mux.HandleFunc("GET /mailbox/{id}/raw", h.handleMailboxRaw)
+		mux.HandleFunc("GET /mailbox/{id}/attachments/{index}", h.handleMailboxAttachment)
+		mux.HandleFunc("GET /api/mailbox/messages", h.handleMailboxMessages)
+		mux.HandleFunc("GET /api/mailbox/messages/{id}", h.handleMailboxMessage)
+		mux.HandleFunc("DELETE /api/mailbox/messages", h.handleMailboxClear)
+	}
+
 	handler := loggingMiddleware(mux)
 
 	server := &http.Server{
@@ -74,3 +91,20 @@ func handleError(w http.ResponseWriter, r *http.Request, status int, message str
 		http.Redirect(w, r, fmt.Sprintf("/error?status=%d&message=%s", status, message), http.StatusSeeOther)
 	}
 }
+
+// statusFromError returns the HTTP status for an error returned by the core
+// service, so permission failures are not reported as crashes
+func statusFromError(err error) int {
+	switch status.Code(err) {
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
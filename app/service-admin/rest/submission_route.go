@@ -0,0 +1,57 @@
+package rest
+
+import (
+	"net/http"
+	"service-admin/auth"
+	"service-admin/web/components/toast"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// handleSubmissionProcess replays the processing of a form submission that
+// failed to become a client and consultation
+func (h *Handler) handleSubmissionProcess(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	token, user := getAuth(h, w, r)
+	if token == "" {
+		return
+	}
+	if r.Method != http.MethodPost {
+		handleError(w, r, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		return
+	}
+	if user.Access&auth.EditForms == 0 {
+		handleError(w, r, http.StatusForbidden, "User does not have access to process submissions", nil)
+		return
+	}
+
+	submissionID := r.FormValue("id")
+	res, err := h.conn.ProcessSubmission(ctx, token, r.FormValue("agency_id"), submissionID)
+	st, ok := status.FromError(err)
+	if ok && (st.Code() == codes.InvalidArgument || st.Code() == codes.NotFound) {
+		err = h.broker.Notify(ctx, user.ID.String(), "error-"+submissionID, st.Message())
+		if err != nil {
+			handleError(w, r, http.StatusInternalServerError, "Error sending toast", err)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		handleError(w, r, statusFromError(err), "Error processing submission", err)
+		return
+	}
+
+	message := "The submission has been processed into a consultation."
+	if res.GetReplayed() {
+		message = "The submission had already been processed."
+	}
+	toast.SendToast(
+		ctx,
+		h.broker,
+		user.ID.String(),
+		toast.Data{Type: "success", Title: "Submission Processed", Message: message},
+	)
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,29 @@
+package mailbox
+
+import (
+	"app/pkg/mailbox"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+)
+
+// messageURL links to a message, keeping the recipient filter
+func messageURL(id, to string) string {
+	query := url.Values{"id": {id}}
+	if to != "" {
+		query.Set("to", to)
+	}
+	return "/mailbox?" + query.Encode()
+}
+
+func attachmentName(a mailbox.Attachment, i int) string {
+	if a.Filename != "" {
+		return a.Filename
+	}
+	return fmt.Sprintf("attachment-%d", i+1)
+}
+
+func headerNames(headers map[string][]string) []string {
+	return slices.Sorted(maps.Keys(headers))
+}
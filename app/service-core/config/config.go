@@ -1,6 +1,8 @@
 package config
 
 import (
+	"app/pkg/money"
+	"encoding/base64"
 	"os"
 	"strings"
 	"time"
@@ -28,6 +30,34 @@ func MustSetEnv(active bool, key string) string {
 	return os.Getenv(key)
 }
 
+// MustSetKey returns the 32-byte key an environment variable holds,
+// base64-encoded
+func MustSetKey(key string) []byte {
+	value := MustSetEnv(true, key)
+	if isRunningTest() {
+		return make([]byte, 32)
+	}
+	b, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(b) != 32 {
+		panic("Invalid environment variable: " + key + " must be 32 bytes, base64-encoded")
+	}
+	return b
+}
+
+// MustSetRounding returns the rounding mode an environment variable names,
+// half-even when it is unset
+func MustSetRounding(key string) money.Rounding {
+	value := os.Getenv(key)
+	if value == "" {
+		return money.HalfEven
+	}
+	r, err := money.ParseRounding(value)
+	if err != nil {
+		panic("Invalid environment variable: " + key + " must be one of half_even, half_up, down")
+	}
+	return r
+}
+
 type Config struct {
 	// General
 	LogLevel  string
@@ -45,6 +75,12 @@ type Config struct {
 	ContextTimeout  time.Duration
 	AccessTokenExp  time.Duration
 	RefreshTokenExp time.Duration
+	// Rounding of GST, discounts and line item amounts
+	Rounding money.Rounding
+
+	// Email outbox workers, and how often they look for emails that are due
+	EmailWorkers      int
+	EmailPollInterval time.Duration
 
 	// Database
 	DatabaseProvider string
@@ -69,6 +105,12 @@ type Config struct {
 	FacebookClientSecret  string
 	TwilioServiceSID      string
 
+	// WebAuthn relying party ID: the domain passkeys are registered for.
+	// Defaults to the client URL's host.
+	WebAuthnRPID string
+	// Key TOTP secrets are encrypted with at rest: 32 bytes, base64-encoded
+	TOTPEncryptionKey []byte
+
 	// Payment
 	PaymentProvider            string
 	SubscriptionSafePeriodDays int
@@ -97,12 +139,24 @@ type Config struct {
 	SesAccessKey string
 	SesSecretKey string
 	SesRegion    string
+	// Delivery webhooks. A provider's webhook is enabled when its secret is
+	// set, whichever provider sends email. SES events come through SNS,
+	// from the topic of SesConfigurationSet.
+	PostmarkWebhookUsername  string
+	PostmarkWebhookPassword  string
+	SendgridWebhookPublicKey string
+	ResendWebhookSecret      string
+	SesConfigurationSet      string
+	SesWebhookTopicArn       string
 	// SMTP
 	SMTPHost     string
 	SMTPPort     string
 	SMTPUsername string
 	SMTPPassword string
 
+	// Development mailbox of the admin service
+	MailboxSMTPAddr string
+
 	// Files
 	FileProvider string
 	LocalFileDir string
@@ -130,6 +184,8 @@ func LoadConfig() *Config {
 		RefreshTokenExp            = 30 * 24 * time.Hour
 		MaxFileSize                = 10 << 20
 		SubscriptionSafePeriodDays = 2
+		EmailWorkers               = 4
+		EmailPollInterval          = 5 * time.Second
 	)
 	return &Config{
 		LogLevel:                     MustSetEnv(true, "LOG_LEVEL"),
@@ -145,6 +201,9 @@ func LoadConfig() *Config {
 		AccessTokenExp:               AccessTokenExp,
 		RefreshTokenExp:              RefreshTokenExp,
 		MaxFileSize:                  MaxFileSize,
+		Rounding:                     MustSetRounding("ROUNDING_MODE"),
+		EmailWorkers:                 EmailWorkers,
+		EmailPollInterval:            EmailPollInterval,
 		SubscriptionSafePeriodDays:   SubscriptionSafePeriodDays,
 		DatabaseProvider:             MustSetEnv(true, "DATABASE_PROVIDER"),
 		PostgresHost:                 MustSetEnv(os.Getenv("DATABASE_PROVIDER") == "postgres", "POSTGRES_HOST"),
@@ -163,6 +222,8 @@ func LoadConfig() *Config {
 		FacebookClientID:             os.Getenv("FACEBOOK_CLIENT_ID"),
 		FacebookClientSecret:         os.Getenv("FACEBOOK_CLIENT_SECRET"),
 		TwilioServiceSID:             os.Getenv("TWILIO_SERVICE_SID"),
+		WebAuthnRPID:                 os.Getenv("WEBAUTHN_RP_ID"),
+		TOTPEncryptionKey:            MustSetKey("TOTP_ENCRYPTION_KEY"),
 		PaymentProvider:              MustSetEnv(true, "PAYMENT_PROVIDER"),
 		StripeAPIKey:                 MustSetEnv(os.Getenv("PAYMENT_PROVIDER") == "stripe", "STRIPE_API_KEY"),
 		StripeWebhookSecret:          MustSetEnv(os.Getenv("PAYMENT_PROVIDER") == "stripe", "STRIPE_WEBHOOK_SECRET"),
@@ -182,10 +243,17 @@ func LoadConfig() *Config {
 		SesAccessKey:                 MustSetEnv(os.Getenv("EMAIL_PROVIDER") == "ses", "SES_ACCESS_KEY"),
 		SesSecretKey:                 MustSetEnv(os.Getenv("EMAIL_PROVIDER") == "ses", "SES_SECRET_KEY"),
 		SesRegion:                    MustSetEnv(os.Getenv("EMAIL_PROVIDER") == "ses", "SES_REGION"),
+		PostmarkWebhookUsername:      os.Getenv("POSTMARK_WEBHOOK_USERNAME"),
+		PostmarkWebhookPassword:      os.Getenv("POSTMARK_WEBHOOK_PASSWORD"),
+		SendgridWebhookPublicKey:     os.Getenv("SENDGRID_WEBHOOK_PUBLIC_KEY"),
+		ResendWebhookSecret:          os.Getenv("RESEND_WEBHOOK_SECRET"),
+		SesConfigurationSet:          os.Getenv("SES_CONFIGURATION_SET"),
+		SesWebhookTopicArn:           os.Getenv("SES_WEBHOOK_TOPIC_ARN"),
 		SMTPHost:                     MustSetEnv(os.Getenv("EMAIL_PROVIDER") == "smtp", "SMTP_HOST"),
 		SMTPPort:                     MustSetEnv(os.Getenv("EMAIL_PROVIDER") == "smtp", "SMTP_PORT"),
 		SMTPUsername:                 os.Getenv("SMTP_USERNAME"),
 		SMTPPassword:                 os.Getenv("SMTP_PASSWORD"),
+		MailboxSMTPAddr:              MustSetEnv(os.Getenv("EMAIL_PROVIDER") == "mailbox", "MAILBOX_SMTP_ADDR"),
 		FileProvider:                 MustSetEnv(true, "FILE_PROVIDER"),
 		LocalFileDir:                 MustSetEnv(os.Getenv("FILE_PROVIDER") == "local", "LOCAL_FILE_DIR"),
 		BucketName:                   MustSetEnv(os.Getenv("FILE_PROVIDER") != "local", "BUCKET_NAME"),
@@ -209,6 +277,9 @@ func LoadTestConfig() *Config {
 		RefreshTokenExp            = 30 * 24 * time.Hour
 		MaxFileSize                = 10 << 20
 		SubscriptionSafePeriodDays = 2
+		EmailWorkers               = 4
+		EmailPollInterval          = 5 * time.Second
+		Rounding                   = money.HalfEven
 	)
 	return &Config{
 		LogLevel:                     "debug",
@@ -224,6 +295,9 @@ func LoadTestConfig() *Config {
 		AccessTokenExp:               AccessTokenExp,
 		RefreshTokenExp:              RefreshTokenExp,
 		MaxFileSize:                  MaxFileSize,
+		Rounding:                     Rounding,
+		EmailWorkers:                 EmailWorkers,
+		EmailPollInterval:            EmailPollInterval,
 		DatabaseProvider:             "postgres",
 		PostgresHost:                 "localhost",
 		PostgresPort:                 "5432",
@@ -241,6 +315,8 @@ func LoadTestConfig() *Config {
 		FacebookClientID:             "facebook_client_id",
 		FacebookClientSecret:         "facebook_client_secret",
 		TwilioServiceSID:             "twilio_service_sid",
+		WebAuthnRPID:                 "localhost",
+		TOTPEncryptionKey:            make([]byte, 32),
 		PaymentProvider:              "stripe",
 		SubscriptionSafePeriodDays:   SubscriptionSafePeriodDays,
 		StripeAPIKey:                 "stripe_api_key",
@@ -0,0 +1,73 @@
+// Package activity records changes to agency records in the activity log
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+)
+
+type Store interface {
+	InsertActivityLog(ctx context.Context, arg query.InsertActivityLogParams) error
+}
+
+// Entry is one change to an entity. The values and metadata are encoded as
+// JSON; nil values are stored as null and nil metadata as an empty object.
+type Entry struct {
+	AgencyID   uuid.UUID
+	UserID     uuid.UUID
+	Action     string
+	EntityType string
+	EntityID   uuid.UUID
+	OldValues  any
+	NewValues  any
+	Metadata   any
+}
+
+// Insert writes an entry, for changes that must be logged in the same
+// transaction as the change itself
+func Insert(ctx context.Context, st Store, e Entry) error {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+	params := query.InsertActivityLogParams{
+		ID:         id,
+		AgencyID:   e.AgencyID,
+		UserID:     uuid.NullUUID{UUID: e.UserID, Valid: e.UserID != uuid.Nil},
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   uuid.NullUUID{UUID: e.EntityID, Valid: e.EntityID != uuid.Nil},
+		OldValues:  nullJSON(e.OldValues),
+		NewValues:  nullJSON(e.NewValues),
+		Metadata:   json.RawMessage(`{}`),
+	}
+	if m := nullJSON(e.Metadata); m.Valid {
+		params.Metadata = m.RawMessage
+	}
+	return st.InsertActivityLog(ctx, params)
+}
+
+// Log writes an entry. Failures are logged and never fail the operation
+// itself.
+func Log(ctx context.Context, st Store, e Entry) {
+	if err := Insert(ctx, st, e); err != nil {
+		slog.Error("Error logging activity", "error", err, "action", e.Action,
+			"entity_type", e.EntityType, "entity_id", e.EntityID)
+	}
+}
+
+func nullJSON(v any) pqtype.NullRawMessage {
+	if v == nil {
+		return pqtype.NullRawMessage{}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return pqtype.NullRawMessage{}
+	}
+	return pqtype.NullRawMessage{RawMessage: b, Valid: true}
+}
@@ -0,0 +1,100 @@
+package activity_test
+
+import (
+	"context"
+	"errors"
+	"service-core/domain/activity"
+	"service-core/storage/query"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type mockStore struct {
+	entries []query.InsertActivityLogParams
+	err     error
+}
+
+func (m *mockStore) InsertActivityLog(ctx context.Context, arg query.InsertActivityLogParams) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.entries = append(m.entries, arg)
+	return nil
+}
+
+func TestInsert(t *testing.T) {
+	t.Parallel()
+	agencyID := uuid.MustParse("00000000-0000-0000-0000-000000000100")
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000200")
+	entityID := uuid.MustParse("00000000-0000-0000-0000-000000000300")
+	tests := []struct {
+		name         string
+		entry        activity.Entry
+		wantUser     bool
+		wantOld      string
+		wantNew      string
+		wantMetadata string
+	}{
+		{
+			name: "all values",
+			entry: activity.Entry{
+				AgencyID: agencyID, UserID: userID, Action: "client.updated",
+				EntityType: "client", EntityID: entityID,
+				OldValues: map[string]any{"email": "a@example.com"},
+				NewValues: map[string]any{"email": "b@example.com"},
+				Metadata:  map[string]any{"source": "form"},
+			},
+			wantUser:     true,
+			wantOld:      `{"email":"a@example.com"}`,
+			wantNew:      `{"email":"b@example.com"}`,
+			wantMetadata: `{"source":"form"}`,
+		},
+		{
+			name: "system change without values",
+			entry: activity.Entry{
+				AgencyID: agencyID, Action: "form_submission.processed",
+				EntityType: "form_submission", EntityID: entityID,
+			},
+			wantMetadata: `{}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := &mockStore{}
+			if err := activity.Insert(context.Background(), store, tt.entry); err != nil {
+				t.Fatalf("Insert() error = %v", err)
+			}
+			if len(store.entries) != 1 {
+				t.Fatalf("Insert() wrote %d entries, want 1", len(store.entries))
+			}
+			got := store.entries[0]
+			if got.ID == uuid.Nil || got.AgencyID != agencyID || got.EntityID.UUID != entityID || !got.EntityID.Valid {
+				t.Errorf("Insert() = %+v, want agency %s and entity %s", got, agencyID, entityID)
+			}
+			if got.UserID.Valid != tt.wantUser {
+				t.Errorf("UserID.Valid = %v, want %v", got.UserID.Valid, tt.wantUser)
+			}
+			if string(got.OldValues.RawMessage) != tt.wantOld || got.OldValues.Valid != (tt.wantOld != "") {
+				t.Errorf("OldValues = %s, want %s", got.OldValues.RawMessage, tt.wantOld)
+			}
+			if string(got.NewValues.RawMessage) != tt.wantNew || got.NewValues.Valid != (tt.wantNew != "") {
+				t.Errorf("NewValues = %s, want %s", got.NewValues.RawMessage, tt.wantNew)
+			}
+			if string(got.Metadata) != tt.wantMetadata {
+				t.Errorf("Metadata = %s, want %s", got.Metadata, tt.wantMetadata)
+			}
+		})
+	}
+}
+
+func TestLogIgnoresFailure(t *testing.T) {
+	t.Parallel()
+	store := &mockStore{err: errors.New("connection reset")}
+	// Log must not panic or surface the error
+	activity.Log(context.Background(), store, activity.Entry{Action: "client.created", EntityType: "client"})
+	if err := activity.Insert(context.Background(), store, activity.Entry{}); err == nil {
+		t.Error("Insert() error = nil, want the store's error")
+	}
+}
@@ -0,0 +1,47 @@
+package apikey
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"errors"
+	"time"
+)
+
+// AuthService accepts API keys wherever access tokens are accepted.
+// Credentials that are not API keys are passed to the wrapped service.
+type AuthService struct {
+	auth.AuthService
+	keys    *Service
+	timeout time.Duration
+}
+
+// NewAuthService wraps an auth service to also accept API keys
+func NewAuthService(authService auth.AuthService, keys *Service, timeout time.Duration) *AuthService {
+	return &AuthService{
+		AuthService: authService,
+		keys:        keys,
+		timeout:     timeout,
+	}
+}
+
+// Auth authenticates a request made with an access token or an API key and
+// checks it has the access required
+func (a *AuthService) Auth(token string, access int64) (*auth.AccessTokenClaims, error) {
+	if !IsKey(token) {
+		return a.AuthService.Auth(token, access)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+	claims, err := a.keys.Authenticate(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !a.HasAccess(access, claims.Access) {
+		return nil, pkg.ForbiddenError{Err: errors.New("API key does not have access to this resource")}
+	}
+	return claims, nil
+}
+
+// Ensure AuthService implements the auth.AuthService interface
+var _ auth.AuthService = (*AuthService)(nil)
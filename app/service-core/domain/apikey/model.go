@@ -0,0 +1,67 @@
+package apikey
+
+import (
+	"app/pkg/auth"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KeyPrefix starts every API key, so keys can be told apart from access
+// tokens and found by secret scanners
+const KeyPrefix = "wk_"
+
+// displayLength is how much of a key is kept in the clear to identify it
+const displayLength = len(KeyPrefix) + 8
+
+// CreateRequest names a new key and sets what it may do. Keys without an
+// expiry last until they are revoked.
+type CreateRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// Key describes an API key without its secret
+type Key struct {
+	ID         uuid.UUID  `json:"id"`
+	AgencyID   *uuid.UUID `json:"agencyId"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
+
+// CreatedKey is a new key with its secret, which is only ever shown once
+type CreatedKey struct {
+	Key
+	Secret string `json:"key"`
+}
+
+func toKey(k query.ApiKey) Key {
+	key := Key{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     auth.AccessScopes(k.Access),
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  nullTime(k.ExpiresAt.Time, k.ExpiresAt.Valid),
+		LastUsedAt: nullTime(k.LastUsedAt.Time, k.LastUsedAt.Valid),
+		RevokedAt:  nullTime(k.RevokedAt.Time, k.RevokedAt.Valid),
+	}
+	if k.AgencyID.Valid {
+		key.AgencyID = &k.AgencyID.UUID
+	}
+	return key
+}
+
+func nullTime(t time.Time, valid bool) *time.Time {
+	if !valid {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,224 @@
+package apikey
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"service-core/domain/activity"
+	"service-core/storage/query"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// store defines the database interface for API key operations
+type store interface {
+	InsertAPIKey(ctx context.Context, arg query.InsertAPIKeyParams) (query.ApiKey, error)
+	SelectUserAPIKeys(ctx context.Context, userID uuid.UUID) ([]query.ApiKey, error)
+	SelectAgencyAPIKeys(ctx context.Context, agencyID uuid.UUID) ([]query.ApiKey, error)
+	SelectAPIKeyByHash(ctx context.Context, keyHash string) (query.SelectAPIKeyByHashRow, error)
+	RevokeUserAPIKey(ctx context.Context, arg query.RevokeUserAPIKeyParams) (query.ApiKey, error)
+	RevokeAgencyAPIKey(ctx context.Context, arg query.RevokeAgencyAPIKeyParams) (query.ApiKey, error)
+	UpdateAPIKeyLastUsed(ctx context.Context, id uuid.UUID) error
+	InsertActivityLog(ctx context.Context, arg query.InsertActivityLogParams) error
+}
+
+// Service manages API keys and authenticates requests made with them
+type Service struct {
+	store store
+}
+
+// NewService creates a new API key service
+func NewService(store store) *Service {
+	return &Service{
+		store: store,
+	}
+}
+
+// CreateUserKey creates a personal key that acts as the user, within the
+// given access
+func (s *Service) CreateUserKey(ctx context.Context, userID uuid.UUID, userAccess int64, req CreateRequest) (*CreatedKey, error) {
+	return s.create(ctx, uuid.NullUUID{}, userID, userAccess, req)
+}
+
+// CreateAgencyKey creates a key for an agency. The key acts as the user who
+// created it, and only for the agency, so it stops working if they leave.
+// It may not be granted more than the user's access and role allow.
+func (s *Service) CreateAgencyKey(ctx context.Context, agencyID, userID uuid.UUID, userAccess int64, role auth.Role, req CreateRequest) (*CreatedKey, error) {
+	created, err := s.create(ctx, uuid.NullUUID{UUID: agencyID, Valid: true}, userID, userAccess&auth.RoleAccess(role), req)
+	if err != nil {
+		return nil, err
+	}
+	s.logActivity(ctx, agencyID, userID, created.ID, "api_key.created", map[string]any{
+		"name":   created.Name,
+		"prefix": created.Prefix,
+		"scopes": created.Scopes,
+	})
+	return created, nil
+}
+
+func (s *Service) create(ctx context.Context, agencyID uuid.NullUUID, userID uuid.UUID, allowed int64, req CreateRequest) (*CreatedKey, error) {
+	access, err := validate(req, allowed, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	secret, err := generateKey()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating API key", Err: err}
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating API key ID", Err: err}
+	}
+	params := query.InsertAPIKeyParams{
+		ID:       id,
+		UserID:   userID,
+		AgencyID: agencyID,
+		Name:     strings.TrimSpace(req.Name),
+		Prefix:   secret[:displayLength],
+		KeyHash:  hashKey(secret),
+		Access:   access,
+	}
+	if req.ExpiresAt != nil {
+		params.ExpiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+	k, err := s.store.InsertAPIKey(ctx, params)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error inserting API key", Err: err}
+	}
+	return &CreatedKey{Key: toKey(k), Secret: secret}, nil
+}
+
+// ListUserKeys returns a user's personal keys, including revoked ones
+func (s *Service) ListUserKeys(ctx context.Context, userID uuid.UUID) ([]Key, error) {
+	keys, err := s.store.SelectUserAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting API keys", Err: err}
+	}
+	return toKeys(keys), nil
+}
+
+// ListAgencyKeys returns an agency's keys, including revoked ones
+func (s *Service) ListAgencyKeys(ctx context.Context, agencyID uuid.UUID) ([]Key, error) {
+	keys, err := s.store.SelectAgencyAPIKeys(ctx, agencyID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting API keys", Err: err}
+	}
+	return toKeys(keys), nil
+}
+
+// RevokeUserKey revokes one of a user's personal keys
+func (s *Service) RevokeUserKey(ctx context.Context, userID, id uuid.UUID) (*Key, error) {
+	k, err := s.store.RevokeUserAPIKey(ctx, query.RevokeUserAPIKeyParams{ID: id, UserID: userID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "API key not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error revoking API key", Err: err}
+	}
+	key := toKey(k)
+	return &key, nil
+}
+
+// RevokeAgencyKey revokes one of an agency's keys
+func (s *Service) RevokeAgencyKey(ctx context.Context, agencyID, userID, id uuid.UUID) (*Key, error) {
+	k, err := s.store.RevokeAgencyAPIKey(ctx, query.RevokeAgencyAPIKeyParams{ID: id, AgencyID: agencyID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "API key not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error revoking API key", Err: err}
+	}
+	key := toKey(k)
+	s.logActivity(ctx, agencyID, userID, key.ID, "api_key.revoked", map[string]any{
+		"name":   key.Name,
+		"prefix": key.Prefix,
+	})
+	return &key, nil
+}
+
+// Authenticate returns the claims of a request made with an API key. The
+// key's access is capped by its user's current access, so keys lose what
+// their user loses. Agency keys carry their agency.
+func (s *Service) Authenticate(ctx context.Context, key string) (*auth.AccessTokenClaims, error) {
+	if !IsKey(key) {
+		return nil, pkg.UnauthorizedError{Err: errors.New("invalid API key")}
+	}
+	k, err := s.store.SelectAPIKeyByHash(ctx, hashKey(key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.UnauthorizedError{Err: errors.New("invalid API key")}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting API key", Err: err}
+	}
+	now := time.Now()
+	switch {
+	case k.RevokedAt.Valid:
+		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("API key %s has been revoked", k.ID)}
+	case k.ExpiresAt.Valid && !k.ExpiresAt.Time.After(now):
+		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("API key %s has expired", k.ID)}
+	case k.Suspended:
+		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("user %s is suspended", k.UserID)}
+	}
+	if err := s.store.UpdateAPIKeyLastUsed(ctx, k.ID); err != nil {
+		slog.Error("Error recording API key use", "error", err, "api_key_id", k.ID)
+	}
+	return &auth.AccessTokenClaims{
+		ID:                 k.UserID,
+		Access:             k.Access & k.UserAccess,
+		Avatar:             k.Avatar,
+		Email:              k.Email,
+		SubscriptionActive: k.SubscriptionEnd.After(now),
+		AgencyID:           k.AgencyID.UUID,
+		APIKeyID:           k.ID,
+	}, nil
+}
+
+// IsKey reports whether a credential is an API key rather than a token
+func IsKey(credential string) bool {
+	return strings.HasPrefix(credential, KeyPrefix)
+}
+
+// generateKey returns a new key with 256 bits of randomness
+func generateKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return KeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashKey returns the stored form of a key. Keys are random enough that a
+// plain SHA-256 cannot be reversed, and it lets keys be looked up directly.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func toKeys(keys []query.ApiKey) []Key {
+	result := make([]Key, len(keys))
+	for i, k := range keys {
+		result[i] = toKey(k)
+	}
+	return result
+}
+
+func (s *Service) logActivity(ctx context.Context, agencyID, userID, keyID uuid.UUID, action string, newValues any) {
+	activity.Log(ctx, s.store, activity.Entry{
+		AgencyID:   agencyID,
+		UserID:     userID,
+		Action:     action,
+		EntityType: "api_key",
+		EntityID:   keyID,
+		NewValues:  newValues,
+	})
+}
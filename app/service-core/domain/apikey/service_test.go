@@ -0,0 +1,196 @@
+package apikey_test
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"service-core/domain/apikey"
+	"service-core/storage/query"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type mockStore struct {
+	mu   sync.Mutex
+	keys map[string]query.SelectAPIKeyByHashRow
+	used []uuid.UUID
+}
+
+func (m *mockStore) InsertAPIKey(ctx context.Context, arg query.InsertAPIKeyParams) (query.ApiKey, error) {
+	return query.ApiKey{
+		ID:        arg.ID,
+		UserID:    arg.UserID,
+		AgencyID:  arg.AgencyID,
+		Name:      arg.Name,
+		Prefix:    arg.Prefix,
+		KeyHash:   arg.KeyHash,
+		Access:    arg.Access,
+		ExpiresAt: arg.ExpiresAt,
+	}, nil
+}
+
+func (m *mockStore) SelectUserAPIKeys(ctx context.Context, userID uuid.UUID) ([]query.ApiKey, error) {
+	return nil, nil
+}
+
+func (m *mockStore) SelectAgencyAPIKeys(ctx context.Context, agencyID uuid.UUID) ([]query.ApiKey, error) {
+	return nil, nil
+}
+
+func (m *mockStore) SelectAPIKeyByHash(ctx context.Context, keyHash string) (query.SelectAPIKeyByHashRow, error) {
+	k, ok := m.keys[keyHash]
+	if !ok {
+		return query.SelectAPIKeyByHashRow{}, sql.ErrNoRows
+	}
+	return k, nil
+}
+
+func (m *mockStore) RevokeUserAPIKey(ctx context.Context, arg query.RevokeUserAPIKeyParams) (query.ApiKey, error) {
+	return query.ApiKey{}, sql.ErrNoRows
+}
+
+func (m *mockStore) RevokeAgencyAPIKey(ctx context.Context, arg query.RevokeAgencyAPIKeyParams) (query.ApiKey, error) {
+	return query.ApiKey{}, sql.ErrNoRows
+}
+
+func (m *mockStore) UpdateAPIKeyLastUsed(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.used = append(m.used, id)
+	return nil
+}
+
+func (m *mockStore) InsertActivityLog(ctx context.Context, arg query.InsertActivityLogParams) error {
+	return nil
+}
+
+func hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	agencyID := uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	past := sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true}
+	future := sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+	row := func(id string, change func(*query.SelectAPIKeyByHashRow)) query.SelectAPIKeyByHashRow {
+		k := query.SelectAPIKeyByHashRow{
+			ID:              uuid.MustParse(id),
+			UserID:          userID,
+			Access:          auth.GetNotes | auth.CreateNote,
+			UserAccess:      auth.UserAccess,
+			Email:           "user@example.com",
+			SubscriptionEnd: time.Now().Add(24 * time.Hour),
+		}
+		if change != nil {
+			change(&k)
+		}
+		return k
+	}
+	store := &mockStore{keys: map[string]query.SelectAPIKeyByHashRow{
+		hash("wk_valid"):   row("00000000-0000-0000-0000-000000000101", nil),
+		hash("wk_future"):  row("00000000-0000-0000-0000-000000000102", func(k *query.SelectAPIKeyByHashRow) { k.ExpiresAt = future }),
+		hash("wk_expired"): row("00000000-0000-0000-0000-000000000103", func(k *query.SelectAPIKeyByHashRow) { k.ExpiresAt = past }),
+		hash("wk_revoked"): row("00000000-0000-0000-0000-000000000104", func(k *query.SelectAPIKeyByHashRow) { k.RevokedAt = past }),
+		hash("wk_suspend"): row("00000000-0000-0000-0000-000000000105", func(k *query.SelectAPIKeyByHashRow) { k.Suspended = true }),
+		hash("wk_demoted"): row("00000000-0000-0000-0000-000000000106", func(k *query.SelectAPIKeyByHashRow) { k.UserAccess = auth.GetNotes }),
+		hash("wk_agency"): row("00000000-0000-0000-0000-000000000107", func(k *query.SelectAPIKeyByHashRow) {
+			k.AgencyID = uuid.NullUUID{UUID: agencyID, Valid: true}
+		}),
+	}}
+	s := apikey.NewService(store)
+
+	tests := []struct {
+		name     string
+		key      string
+		access   int64
+		agencyID uuid.UUID
+		ok       bool
+	}{
+		{"valid", "wk_valid", auth.GetNotes | auth.CreateNote, uuid.Nil, true},
+		{"not yet expired", "wk_future", auth.GetNotes | auth.CreateNote, uuid.Nil, true},
+		{"expired", "wk_expired", 0, uuid.Nil, false},
+		{"revoked", "wk_revoked", 0, uuid.Nil, false},
+		{"suspended user", "wk_suspend", 0, uuid.Nil, false},
+		{"capped by user access", "wk_demoted", auth.GetNotes, uuid.Nil, true},
+		{"agency key", "wk_agency", auth.GetNotes | auth.CreateNote, agencyID, true},
+		{"unknown key", "wk_unknown", 0, uuid.Nil, false},
+		{"not a key", "eyJhbGciOiJFZERTQSJ9", 0, uuid.Nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			claims, err := s.Authenticate(context.Background(), tt.key)
+			if !tt.ok {
+				var unauthorized pkg.UnauthorizedError
+				if !errors.As(err, &unauthorized) {
+					t.Fatalf("Authenticate() = %v, want UnauthorizedError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() = %v, want nil", err)
+			}
+			if claims.ID != userID || claims.Access != tt.access || claims.AgencyID != tt.agencyID {
+				t.Errorf("claims = %v %#x %v, want %v %#x %v", claims.ID, claims.Access, claims.AgencyID, userID, tt.access, tt.agencyID)
+			}
+			if claims.APIKeyID == uuid.Nil {
+				t.Error("claims do not record the API key")
+			}
+		})
+	}
+}
+
+func TestCreateUserKey(t *testing.T) {
+	t.Parallel()
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name  string
+		req   apikey.CreateRequest
+		field string
+	}{
+		{"valid", apikey.CreateRequest{Name: "CI", Scopes: []string{"notes:read", "notes:write"}}, ""},
+		{"missing name", apikey.CreateRequest{Name: " ", Scopes: []string{"notes:read"}}, "name"},
+		{"no scopes", apikey.CreateRequest{Name: "CI"}, "scopes"},
+		{"unknown scope", apikey.CreateRequest{Name: "CI", Scopes: []string{"notes:admin"}}, "scopes"},
+		{"scope beyond access", apikey.CreateRequest{Name: "CI", Scopes: []string{"users:write"}}, "scopes"},
+		{"expired", apikey.CreateRequest{Name: "CI", Scopes: []string{"notes:read"}, ExpiresAt: &past}, "expiresAt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := apikey.NewService(&mockStore{})
+			created, err := s.CreateUserKey(context.Background(), userID, auth.UserAccess, tt.req)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("CreateUserKey() = %v, want nil", err)
+				}
+				if !strings.HasPrefix(created.Secret, apikey.KeyPrefix) || !strings.HasPrefix(created.Secret, created.Prefix) {
+					t.Errorf("key %q does not start with %q and its prefix %q", created.Secret, apikey.KeyPrefix, created.Prefix)
+				}
+				if len(created.Scopes) != 2 {
+					t.Errorf("Scopes = %v, want the requested scopes", created.Scopes)
+				}
+				return
+			}
+			var validation pkg.ValidationErrors
+			if !errors.As(err, &validation) {
+				t.Fatalf("CreateUserKey() = %v, want ValidationErrors", err)
+			}
+			if validation[0].Field != tt.field {
+				t.Errorf("error field = %q, want %q", validation[0].Field, tt.field)
+			}
+		})
+	}
+}
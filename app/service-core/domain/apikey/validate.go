@@ -0,0 +1,61 @@
+package apikey
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"strings"
+	"time"
+)
+
+// validate checks a create request and returns the access its scopes grant.
+// A key may not be granted more than allowed, the creator's own access.
+func validate(req CreateRequest, allowed int64, now time.Time) (int64, error) {
+	var errors pkg.ValidationErrors
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "name",
+			Tag:     "required",
+			Message: "Name is required",
+		})
+	}
+	if len(name) > 100 {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "name",
+			Tag:     "max",
+			Message: "Name must be at most 100 characters",
+		})
+	}
+	access, err := auth.ScopeAccess(req.Scopes)
+	switch {
+	case len(req.Scopes) == 0:
+		errors = append(errors, pkg.ValidationError{
+			Field:   "scopes",
+			Tag:     "required",
+			Message: "At least one scope is required",
+		})
+	case err != nil:
+		errors = append(errors, pkg.ValidationError{
+			Field:   "scopes",
+			Tag:     "oneof",
+			Message: "Invalid scopes: " + err.Error(),
+		})
+	case access&^allowed != 0:
+		errors = append(errors, pkg.ValidationError{
+			Field:   "scopes",
+			Tag:     "access",
+			Message: "A key cannot be granted scopes you do not have",
+		})
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "expiresAt",
+			Tag:     "future",
+			Message: "Expiry must be in the future",
+		})
+	}
+	if len(errors) == 0 {
+		return access, nil
+	}
+	return 0, errors
+}
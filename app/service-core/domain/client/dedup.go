@@ -0,0 +1,168 @@
+package client
+
+import (
+	"service-core/storage/query"
+	"strings"
+	"unicode"
+)
+
+// Match is the reason two clients are considered duplicates
+type Match string
+
+const (
+	MatchEmail        Match = "email"
+	MatchABN          Match = "abn"
+	MatchBusinessName Match = "businessName"
+)
+
+// DuplicateGroup is a set of clients that appear to be the same business.
+// Clients are ordered oldest first, so the first is the usual merge target.
+type DuplicateGroup struct {
+	Matches []Match        `json:"matches"`
+	Clients []query.Client `json:"clients"`
+}
+
+// legalSuffixes are dropped from the end of a business name before
+// comparing, longest first
+var legalSuffixes = []string{
+	"proprietary limited",
+	"pty limited",
+	"pty ltd",
+	"limited",
+	"ltd",
+	"incorporated",
+	"inc",
+	"llc",
+	"pty",
+}
+
+// NormaliseEmail returns an email address in the form used for comparison
+func NormaliseEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormaliseABN returns the digits of an ABN, or an empty string unless it
+// has the eleven digits of a valid ABN
+func NormaliseABN(abn string) string {
+	var b strings.Builder
+	for _, r := range abn {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() != 11 {
+		return ""
+	}
+	return b.String()
+}
+
+// NormaliseBusinessName returns a business name reduced for comparison: case,
+// punctuation, spacing, a leading "the" and trailing legal suffixes such as
+// "Pty Ltd" are ignored and "&" is read as "and".
+func NormaliseBusinessName(name string) string {
+	name = strings.ToLower(strings.ReplaceAll(name, "&", " and "))
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > 1 && words[0] == "the" {
+		words = words[1:]
+	}
+	name = strings.Join(words, " ")
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, suffix := range legalSuffixes {
+			if rest, ok := strings.CutSuffix(name, " "+suffix); ok {
+				name, trimmed = rest, true
+				break
+			}
+		}
+	}
+	return strings.ReplaceAll(name, " ", "")
+}
+
+// FindDuplicates groups clients sharing a normalised email, ABN or business
+// name. Matches are transitive: if A shares an email with B and B an ABN
+// with C, all three are one group. Clients without a duplicate are omitted.
+func FindDuplicates(clients []query.Client) []DuplicateGroup {
+	parent := make([]int, len(clients))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	// Joining to the lower index keeps the oldest client as each group's root
+	union := func(a, b int) {
+		ra, rb := find(a), find(b)
+		if ra > rb {
+			ra, rb = rb, ra
+		}
+		parent[rb] = ra
+	}
+
+	type key struct {
+		match Match
+		value string
+	}
+	first := map[key]int{}
+	matched := map[int]map[Match]bool{}
+	for i, c := range clients {
+		keys := []key{
+			{MatchEmail, NormaliseEmail(c.Email)},
+			{MatchABN, NormaliseABN(c.Abn)},
+			{MatchBusinessName, NormaliseBusinessName(c.BusinessName)},
+		}
+		for _, k := range keys {
+			if k.value == "" {
+				continue
+			}
+			j, ok := first[k]
+			if !ok {
+				first[k] = i
+				continue
+			}
+			union(i, j)
+			if matched[i] == nil {
+				matched[i] = map[Match]bool{}
+			}
+			matched[i][k.match] = true
+		}
+	}
+
+	index := map[int]int{}
+	var groups []DuplicateGroup
+	var reasons []map[Match]bool
+	for i, c := range clients {
+		root := find(i)
+		g, ok := index[root]
+		if !ok {
+			g = len(groups)
+			index[root] = g
+			groups = append(groups, DuplicateGroup{})
+			reasons = append(reasons, map[Match]bool{})
+		}
+		groups[g].Clients = append(groups[g].Clients, c)
+		for m := range matched[i] {
+			reasons[g][m] = true
+		}
+	}
+	for g := range groups {
+		for _, m := range []Match{MatchEmail, MatchABN, MatchBusinessName} {
+			if reasons[g][m] {
+				groups[g].Matches = append(groups[g].Matches, m)
+			}
+		}
+	}
+
+	duplicates := []DuplicateGroup{}
+	for _, g := range groups {
+		if len(g.Clients) > 1 {
+			duplicates = append(duplicates, g)
+		}
+	}
+	return duplicates
+}
@@ -0,0 +1,107 @@
+package client_test
+
+import (
+	"service-core/domain/client"
+	"service-core/storage/query"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNormaliseBusinessName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Smith & Co Pty Ltd", "smithandco"},
+		{"SMITH AND CO", "smithandco"},
+		{"The Smith and Co. Pty. Limited", "smithandco"},
+		{"  smith-and-co  ", "smithandco"},
+		{"Acme Inc", "acme"},
+		{"Pty Ltd", "pty"},
+		{"The", "the"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := client.NormaliseBusinessName(tt.name); got != tt.want {
+			t.Errorf("NormaliseBusinessName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNormaliseABN(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		abn  string
+		want string
+	}{
+		{"51 824 753 556", "51824753556"},
+		{"51824753556", "51824753556"},
+		{"51-824-753-556", "51824753556"},
+		{"5182475355", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := client.NormaliseABN(tt.abn); got != tt.want {
+			t.Errorf("NormaliseABN(%q) = %q, want %q", tt.abn, got, tt.want)
+		}
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	t.Parallel()
+	id := func(n byte) uuid.UUID { return uuid.UUID{15: n} }
+	clients := []query.Client{
+		{ID: id(1), BusinessName: "Smith & Co Pty Ltd", Email: "jo@smith.example"},
+		{ID: id(2), BusinessName: "Harbour Cafe", Email: "hello@harbour.example", Abn: "51824753556"},
+		{ID: id(3), BusinessName: "Smith and Co", Email: "accounts@smith.example"},
+		{ID: id(4), BusinessName: "Unrelated", Email: "me@unrelated.example"},
+		{ID: id(5), BusinessName: "Harbour Café Group", Email: "Hello@Harbour.example"},
+		{ID: id(6), BusinessName: "HC Holdings", Email: "hc@holdings.example", Abn: "51824753556"},
+		{ID: id(7), BusinessName: "Jo Smith", Email: "JO@smith.example "},
+	}
+
+	groups := client.FindDuplicates(clients)
+	if len(groups) != 2 {
+		t.Fatalf("FindDuplicates() returned %d groups, want 2", len(groups))
+	}
+
+	want := []struct {
+		ids     []byte
+		matches []client.Match
+	}{
+		{[]byte{1, 3, 7}, []client.Match{client.MatchEmail, client.MatchBusinessName}},
+		{[]byte{2, 5, 6}, []client.Match{client.MatchEmail, client.MatchABN}},
+	}
+	for i, w := range want {
+		g := groups[i]
+		if len(g.Clients) != len(w.ids) {
+			t.Fatalf("group %d has %d clients, want %d", i, len(g.Clients), len(w.ids))
+		}
+		for j, n := range w.ids {
+			if g.Clients[j].ID != id(n) {
+				t.Errorf("group %d client %d = %s, want %s", i, j, g.Clients[j].ID, id(n))
+			}
+		}
+		if len(g.Matches) != len(w.matches) {
+			t.Fatalf("group %d matches = %v, want %v", i, g.Matches, w.matches)
+		}
+		for j, m := range w.matches {
+			if g.Matches[j] != m {
+				t.Errorf("group %d matches = %v, want %v", i, g.Matches, w.matches)
+			}
+		}
+	}
+}
+
+func TestFindDuplicatesNone(t *testing.T) {
+	t.Parallel()
+	clients := []query.Client{
+		{ID: uuid.UUID{15: 1}, BusinessName: "Alpha", Email: "a@alpha.example"},
+		{ID: uuid.UUID{15: 2}, BusinessName: "Beta", Email: "b@beta.example"},
+	}
+	if groups := client.FindDuplicates(clients); len(groups) != 0 {
+		t.Errorf("FindDuplicates() = %v, want no groups", groups)
+	}
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Status is the lifecycle state of a client
+type Status string
+
+const (
+	StatusActive   Status = "active"
+	StatusArchived Status = "archived"
+)
+
+// IsValid reports whether s is a known client status
+func (s Status) IsValid() bool {
+	return s == StatusActive || s == StatusArchived
+}
+
+// maxMergeSources bounds how many clients can be merged into one at a time
+const maxMergeSources = 20
+
+// CreateRequest is the input for creating a client
+type CreateRequest struct {
+	BusinessName string `json:"businessName"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	ContactName  string `json:"contactName"`
+	Notes        string `json:"notes"`
+	ABN          string `json:"abn"`
+}
+
+// UpdateRequest edits a client. Nil fields are left unchanged.
+type UpdateRequest struct {
+	BusinessName *string `json:"businessName"`
+	Email        *string `json:"email"`
+	Phone        *string `json:"phone"`
+	ContactName  *string `json:"contactName"`
+	Notes        *string `json:"notes"`
+	ABN          *string `json:"abn"`
+}
+
+// StatusRequest archives or restores a client
+type StatusRequest struct {
+	Status Status `json:"status"`
+}
+
+// MergeRequest merges the source clients into the target client. Every
+// document linked to a source is moved to the target and the sources are
+// deleted.
+type MergeRequest struct {
+	TargetID  uuid.UUID   `json:"targetId"`
+	SourceIDs []uuid.UUID `json:"sourceIds"`
+}
+
+// Documents counts the documents linked to a client
+type Documents struct {
+	Consultations   int64 `json:"consultations"`
+	Proposals       int64 `json:"proposals"`
+	Contracts       int64 `json:"contracts"`
+	Invoices        int64 `json:"invoices"`
+	Quotations      int64 `json:"quotations"`
+	FormSubmissions int64 `json:"formSubmissions"`
+}
+
+// Total returns the number of linked documents of every type
+func (d Documents) Total() int64 {
+	return d.Consultations + d.Proposals + d.Contracts + d.Invoices + d.Quotations + d.FormSubmissions
+}
+
+func (d *Documents) add(o Documents) {
+	d.Consultations += o.Consultations
+	d.Proposals += o.Proposals
+	d.Contracts += o.Contracts
+	d.Invoices += o.Invoices
+	d.Quotations += o.Quotations
+	d.FormSubmissions += o.FormSubmissions
+}
+
+// Detail is a client with counts of its linked documents
+type Detail struct {
+	Client    query.Client `json:"client"`
+	Documents Documents    `json:"documents"`
+}
+
+// ListResponse is a page of clients for an agency
+type ListResponse struct {
+	Count   int64          `json:"count"`
+	Clients []query.Client `json:"clients"`
+}
+
+// MergeResult is the merged client and the documents moved onto it
+type MergeResult struct {
+	Client     query.Client `json:"client"`
+	MergedIDs  []uuid.UUID  `json:"mergedIds"`
+	Reassigned Documents    `json:"reassigned"`
+}
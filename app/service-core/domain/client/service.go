@@ -0,0 +1,591 @@
+package client
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"service-core/domain/activity"
+	"service-core/storage/query"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// store defines the database interface for client operations
+type store interface {
+	CountClients(ctx context.Context, arg query.CountClientsParams) (int64, error)
+	SelectClients(ctx context.Context, arg query.SelectClientsParams) ([]query.Client, error)
+	SelectAgencyClients(ctx context.Context, agencyID uuid.UUID) ([]query.Client, error)
+	SelectClient(ctx context.Context, id uuid.UUID) (query.Client, error)
+	SelectClientByEmail(ctx context.Context, arg query.SelectClientByEmailParams) (query.Client, error)
+	InsertClient(ctx context.Context, arg query.InsertClientParams) (query.Client, error)
+	UpdateClient(ctx context.Context, arg query.UpdateClientParams) (query.Client, error)
+	UpdateClientStatus(ctx context.Context, arg query.UpdateClientStatusParams) (query.Client, error)
+	DeleteClient(ctx context.Context, id uuid.UUID) error
+	CountClientDocuments(ctx context.Context, clientID uuid.UUID) (query.CountClientDocumentsRow, error)
+	InsertActivityLog(ctx context.Context, arg query.InsertActivityLogParams) error
+}
+
+// transactor runs a function in a database transaction
+type transactor interface {
+	InTx(ctx context.Context, fn func(q query.Querier) error) error
+}
+
+// Service handles agency client records
+type Service struct {
+	tx    transactor
+	store store
+}
+
+// NewService creates a new client service
+func NewService(tx transactor, store store) *Service {
+	return &Service{
+		tx:    tx,
+		store: store,
+	}
+}
+
+// ListClients returns a page of an agency's clients, optionally filtered by
+// status and a search on business name, email and contact name
+func (s *Service) ListClients(
+	ctx context.Context,
+	agencyID uuid.UUID,
+	status string,
+	search string,
+	page int32,
+	limit int32,
+) (*ListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+	search = strings.TrimSpace(search)
+	count, err := s.store.CountClients(ctx, query.CountClientsParams{
+		AgencyID: agencyID,
+		Status:   status,
+		Search:   search,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error counting clients", Err: err}
+	}
+	clients, err := s.store.SelectClients(ctx, query.SelectClientsParams{
+		AgencyID:  agencyID,
+		Status:    status,
+		Search:    search,
+		RowLimit:  limit,
+		RowOffset: (page - 1) * limit,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting clients", Err: err}
+	}
+	if clients == nil {
+		clients = []query.Client{}
+	}
+	return &ListResponse{
+		Count:   count,
+		Clients: clients,
+	}, nil
+}
+
+// GetClient returns a client belonging to the agency with counts of its
+// linked documents
+func (s *Service) GetClient(ctx context.Context, agencyID, id uuid.UUID) (*Detail, error) {
+	c, err := s.get(ctx, agencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.documents(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Detail{Client: *c, Documents: docs}, nil
+}
+
+// CreateClient creates a client. Emails are unique per agency regardless of
+// case.
+func (s *Service) CreateClient(ctx context.Context, user auth.UserAttr, req CreateRequest) (*query.Client, error) {
+	if err := auth.Authorize(auth.CreateClient, user, auth.Resource{OwnerID: user.ID, AgencyID: user.AgencyID}); err != nil {
+		return nil, err
+	}
+	params := query.InsertClientParams{
+		AgencyID:     user.AgencyID,
+		BusinessName: strings.TrimSpace(req.BusinessName),
+		Email:        NormaliseEmail(req.Email),
+		Phone:        nullString(req.Phone),
+		ContactName:  nullString(req.ContactName),
+		Notes:        nullString(req.Notes),
+		Abn:          NormaliseABN(req.ABN),
+		CreatedBy:    uuid.NullUUID{UUID: user.ID, Valid: true},
+	}
+	err := validate(&schema{
+		businessName: params.BusinessName,
+		email:        params.Email,
+		phone:        req.Phone,
+		abn:          req.ABN,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEmail(ctx, user.AgencyID, uuid.Nil, params.Email); err != nil {
+		return nil, err
+	}
+
+	params.ID, err = uuid.NewV7()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating client ID", Err: err}
+	}
+	c, err := s.store.InsertClient(ctx, params)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error inserting client", Err: err}
+	}
+	s.logActivity(ctx, &c, user.ID, "client.created", nil, map[string]any{
+		"businessName": c.BusinessName,
+		"email":        c.Email,
+	}, nil)
+	return &c, nil
+}
+
+// FindOrCreate returns the agency's client with the request's email,
+// creating the client when there is none. It runs on the given queries so
+// callers can link the client within their own transaction, and reports
+// whether the client was created. Activity is written by the caller.
+func (s *Service) FindOrCreate(
+	ctx context.Context,
+	q query.Querier,
+	agencyID uuid.UUID,
+	req CreateRequest,
+) (*query.Client, bool, error) {
+	email := NormaliseEmail(req.Email)
+	existing, err := q.SelectClientByEmail(ctx, query.SelectClientByEmailParams{
+		AgencyID: agencyID,
+		Email:    email,
+	})
+	if err == nil {
+		return &existing, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, pkg.InternalError{Message: "Error selecting client", Err: err}
+	}
+
+	params := query.InsertClientParams{
+		AgencyID:     agencyID,
+		BusinessName: strings.TrimSpace(req.BusinessName),
+		Email:        email,
+		Phone:        nullString(req.Phone),
+		ContactName:  nullString(req.ContactName),
+		Notes:        nullString(req.Notes),
+		Abn:          NormaliseABN(req.ABN),
+	}
+	err = validate(&schema{
+		businessName: params.BusinessName,
+		email:        params.Email,
+		phone:        req.Phone,
+		abn:          req.ABN,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	params.ID, err = uuid.NewV7()
+	if err != nil {
+		return nil, false, pkg.InternalError{Message: "Error generating client ID", Err: err}
+	}
+	c, err := q.InsertClient(ctx, params)
+	if err != nil {
+		return nil, false, pkg.InternalError{Message: "Error inserting client", Err: err}
+	}
+	return &c, true, nil
+}
+
+// UpdateClient edits a client's details
+func (s *Service) UpdateClient(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	req UpdateRequest,
+) (*query.Client, error) {
+	existing, err := s.get(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(auth.EditClient, user, resource(existing)); err != nil {
+		return nil, err
+	}
+	params := query.UpdateClientParams{
+		ID:           existing.ID,
+		BusinessName: existing.BusinessName,
+		Email:        existing.Email,
+		Phone:        existing.Phone,
+		ContactName:  existing.ContactName,
+		Notes:        existing.Notes,
+		Abn:          existing.Abn,
+	}
+	abn := existing.Abn
+	if req.BusinessName != nil {
+		params.BusinessName = strings.TrimSpace(*req.BusinessName)
+	}
+	if req.Email != nil {
+		params.Email = NormaliseEmail(*req.Email)
+	}
+	if req.Phone != nil {
+		params.Phone = nullString(*req.Phone)
+	}
+	if req.ContactName != nil {
+		params.ContactName = nullString(*req.ContactName)
+	}
+	if req.Notes != nil {
+		params.Notes = nullString(*req.Notes)
+	}
+	if req.ABN != nil {
+		abn = *req.ABN
+		params.Abn = NormaliseABN(abn)
+	}
+	err = validate(&schema{
+		businessName: params.BusinessName,
+		email:        params.Email,
+		phone:        params.Phone.String,
+		abn:          abn,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if params.Email != NormaliseEmail(existing.Email) {
+		if err := s.checkEmail(ctx, user.AgencyID, existing.ID, params.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := s.store.UpdateClient(ctx, params)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating client", Err: err}
+	}
+	s.logActivity(ctx, &c, user.ID, "client.updated", map[string]any{
+		"businessName": existing.BusinessName,
+		"email":        existing.Email,
+	}, req, nil)
+	return &c, nil
+}
+
+// SetStatus archives or restores a client
+func (s *Service) SetStatus(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	req StatusRequest,
+) (*query.Client, error) {
+	if !req.Status.IsValid() {
+		return nil, pkg.ValidationErrors{{
+			Field:   "status",
+			Tag:     "oneof",
+			Message: "Status must be active or archived",
+		}}
+	}
+	existing, err := s.get(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(auth.EditClient, user, resource(existing)); err != nil {
+		return nil, err
+	}
+	if Status(existing.Status) == req.Status {
+		return existing, nil
+	}
+	c, err := s.store.UpdateClientStatus(ctx, query.UpdateClientStatusParams{
+		ID:     existing.ID,
+		Status: string(req.Status),
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating client status", Err: err}
+	}
+	action := "client.archived"
+	if req.Status == StatusActive {
+		action = "client.restored"
+	}
+	s.logActivity(ctx, &c, user.ID, action,
+		map[string]any{"status": existing.Status},
+		map[string]any{"status": c.Status},
+		nil,
+	)
+	return &c, nil
+}
+
+// DeleteClient removes a client. Linked documents are kept and unlinked.
+func (s *Service) DeleteClient(ctx context.Context, user auth.UserAttr, id uuid.UUID) error {
+	existing, err := s.get(ctx, user.AgencyID, id)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(auth.RemoveClient, user, resource(existing)); err != nil {
+		return err
+	}
+	if err := s.store.DeleteClient(ctx, id); err != nil {
+		return pkg.InternalError{Message: "Error deleting client", Err: err}
+	}
+	s.logActivity(ctx, existing, user.ID, "client.deleted", map[string]any{
+		"businessName": existing.BusinessName,
+		"email":        existing.Email,
+	}, nil, nil)
+	return nil
+}
+
+// FindDuplicates returns the agency's clients that share a normalised
+// email, ABN or business name, grouped by the business they appear to be
+func (s *Service) FindDuplicates(ctx context.Context, agencyID uuid.UUID) ([]DuplicateGroup, error) {
+	clients, err := s.store.SelectAgencyClients(ctx, agencyID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting clients", Err: err}
+	}
+	return FindDuplicates(clients), nil
+}
+
+// MergeClients moves every consultation, proposal, contract, invoice,
+// quotation and form submission of the source clients onto the target,
+// fills the target's empty details from the sources and deletes the
+// sources. Everything, including the activity log entry, is written in one
+// transaction.
+func (s *Service) MergeClients(ctx context.Context, user auth.UserAttr, req MergeRequest) (*MergeResult, error) {
+	sourceIDs, err := validateMerge(req)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.get(ctx, user.AgencyID, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(auth.EditClient, user, resource(target)); err != nil {
+		return nil, err
+	}
+	sources := make([]query.Client, 0, len(sourceIDs))
+	for _, id := range sourceIDs {
+		source, err := s.get(ctx, user.AgencyID, id)
+		if err != nil {
+			return nil, err
+		}
+		// Merged clients are deleted
+		if err := auth.Authorize(auth.RemoveClient, user, resource(source)); err != nil {
+			return nil, err
+		}
+		sources = append(sources, *source)
+	}
+
+	merged := make([]map[string]any, 0, len(sources))
+	for _, source := range sources {
+		merged = append(merged, map[string]any{
+			"id":           source.ID,
+			"businessName": source.BusinessName,
+			"email":        source.Email,
+			"abn":          source.Abn,
+		})
+	}
+	var result *MergeResult
+	err = s.tx.InTx(ctx, func(q query.Querier) error {
+		result = &MergeResult{MergedIDs: sourceIDs}
+		// Sources are deleted before the target is updated, so the target
+		// can take over a source's email
+		for _, source := range sources {
+			moved, err := reassign(ctx, q, source.ID, target.ID)
+			if err != nil {
+				return err
+			}
+			result.Reassigned.add(moved)
+			if err := q.DeleteClient(ctx, source.ID); err != nil {
+				return pkg.InternalError{Message: "Error deleting merged client", Err: err}
+			}
+		}
+		var err error
+		result.Client, err = q.UpdateClient(ctx, mergeParams(*target, sources))
+		if err != nil {
+			return pkg.InternalError{Message: "Error updating merged client", Err: err}
+		}
+		err = activity.Insert(ctx, q, clientEntry(&result.Client, user.ID, "client.merged",
+			map[string]any{"clients": merged},
+			map[string]any{"businessName": result.Client.BusinessName, "email": result.Client.Email},
+			map[string]any{"mergedIds": sourceIDs, "reassigned": result.Reassigned},
+		))
+		if err != nil {
+			return pkg.InternalError{Message: "Error logging client merge", Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// reassign moves every document linked to one client onto another
+func reassign(ctx context.Context, q query.Querier, from, to uuid.UUID) (Documents, error) {
+	var moved Documents
+	var err error
+	f := uuid.NullUUID{UUID: from, Valid: true}
+	t := uuid.NullUUID{UUID: to, Valid: true}
+	if moved.Consultations, err = q.ReassignConsultationsClient(ctx, query.ReassignConsultationsClientParams{FromClientID: f, ToClientID: t}); err != nil {
+		return moved, pkg.InternalError{Message: "Error reassigning consultations", Err: err}
+	}
+	if moved.Proposals, err = q.ReassignProposalsClient(ctx, query.ReassignProposalsClientParams{FromClientID: f, ToClientID: t}); err != nil {
+		return moved, pkg.InternalError{Message: "Error reassigning proposals", Err: err}
+	}
+	if moved.Contracts, err = q.ReassignContractsClient(ctx, query.ReassignContractsClientParams{FromClientID: f, ToClientID: t}); err != nil {
+		return moved, pkg.InternalError{Message: "Error reassigning contracts", Err: err}
+	}
+	if moved.Invoices, err = q.ReassignInvoicesClient(ctx, query.ReassignInvoicesClientParams{FromClientID: f, ToClientID: t}); err != nil {
+		return moved, pkg.InternalError{Message: "Error reassigning invoices", Err: err}
+	}
+	if moved.Quotations, err = q.ReassignQuotationsClient(ctx, query.ReassignQuotationsClientParams{FromClientID: f, ToClientID: t}); err != nil {
+		return moved, pkg.InternalError{Message: "Error reassigning quotations", Err: err}
+	}
+	if moved.FormSubmissions, err = q.ReassignFormSubmissionsClient(ctx, query.ReassignFormSubmissionsClientParams{FromClientID: f, ToClientID: t}); err != nil {
+		return moved, pkg.InternalError{Message: "Error reassigning form submissions", Err: err}
+	}
+	return moved, nil
+}
+
+// mergeParams keeps the target's details, filling any that are empty from
+// the sources in order. Notes from every client are kept.
+func mergeParams(target query.Client, sources []query.Client) query.UpdateClientParams {
+	params := query.UpdateClientParams{
+		ID:           target.ID,
+		BusinessName: target.BusinessName,
+		Email:        target.Email,
+		Phone:        target.Phone,
+		ContactName:  target.ContactName,
+		Notes:        target.Notes,
+		Abn:          target.Abn,
+	}
+	for _, source := range sources {
+		if params.Email == "" {
+			params.Email = source.Email
+		}
+		if params.Phone.String == "" {
+			params.Phone = source.Phone
+		}
+		if params.ContactName.String == "" {
+			params.ContactName = source.ContactName
+		}
+		if params.Abn == "" {
+			params.Abn = source.Abn
+		}
+		if note := strings.TrimSpace(source.Notes.String); note != "" && !strings.Contains(params.Notes.String, note) {
+			params.Notes = nullString(strings.TrimSpace(params.Notes.String + "\n\n" + note))
+		}
+	}
+	return params
+}
+
+func validateMerge(req MergeRequest) ([]uuid.UUID, error) {
+	seen := map[uuid.UUID]bool{req.TargetID: true}
+	var ids []uuid.UUID
+	for _, id := range req.SourceIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if req.TargetID == uuid.Nil {
+		return nil, pkg.ValidationErrors{{
+			Field:   "targetId",
+			Tag:     "required",
+			Message: "A client to merge into is required",
+		}}
+	}
+	if len(ids) == 0 {
+		return nil, pkg.ValidationErrors{{
+			Field:   "sourceIds",
+			Tag:     "min",
+			Message: "At least one other client to merge is required",
+		}}
+	}
+	if len(ids) > maxMergeSources {
+		return nil, pkg.ValidationErrors{{
+			Field:   "sourceIds",
+			Tag:     "max",
+			Message: fmt.Sprintf("At most %d clients can be merged at once", maxMergeSources),
+		}}
+	}
+	return ids, nil
+}
+
+func (s *Service) get(ctx context.Context, agencyID, id uuid.UUID) (*query.Client, error) {
+	c, err := s.store.SelectClient(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Client not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting client", Err: err}
+	}
+	// Clients from other agencies are reported as missing rather than forbidden
+	if c.AgencyID != agencyID {
+		return nil, pkg.NotFoundError{Message: "Client not found", Err: fmt.Errorf("client %s belongs to another agency", id)}
+	}
+	return &c, nil
+}
+
+// resource returns the attributes a client is authorized against. Clients
+// with no creator can only be changed by agency owners and admins.
+func resource(c *query.Client) auth.Resource {
+	return auth.Resource{OwnerID: c.CreatedBy.UUID, AgencyID: c.AgencyID}
+}
+
+// checkEmail fails if another client of the agency already uses the email
+func (s *Service) checkEmail(ctx context.Context, agencyID, id uuid.UUID, email string) error {
+	other, err := s.store.SelectClientByEmail(ctx, query.SelectClientByEmailParams{
+		AgencyID: agencyID,
+		Email:    email,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return pkg.InternalError{Message: "Error selecting client", Err: err}
+	}
+	if other.ID == id {
+		return nil
+	}
+	return pkg.BadRequestError{
+		Message: "A client with this email already exists",
+		Err:     fmt.Errorf("email in use by client %s", other.ID),
+	}
+}
+
+func (s *Service) documents(ctx context.Context, clientID uuid.UUID) (Documents, error) {
+	row, err := s.store.CountClientDocuments(ctx, clientID)
+	if err != nil {
+		return Documents{}, pkg.InternalError{Message: "Error counting client documents", Err: err}
+	}
+	return Documents(row), nil
+}
+
+// logActivity records a client change in the agency activity log
+func (s *Service) logActivity(
+	ctx context.Context,
+	c *query.Client,
+	userID uuid.UUID,
+	action string,
+	oldValues any,
+	newValues any,
+	metadata any,
+) {
+	activity.Log(ctx, s.store, clientEntry(c, userID, action, oldValues, newValues, metadata))
+}
+
+// clientEntry returns the activity log entry for a client change
+func clientEntry(c *query.Client, userID uuid.UUID, action string, oldValues, newValues, metadata any) activity.Entry {
+	return activity.Entry{
+		AgencyID:   c.AgencyID,
+		UserID:     userID,
+		Action:     action,
+		EntityType: "client",
+		EntityID:   c.ID,
+		OldValues:  oldValues,
+		NewValues:  newValues,
+		Metadata:   metadata,
+	}
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
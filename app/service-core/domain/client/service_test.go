@@ -0,0 +1,242 @@
+package client_test
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"service-core/domain/client"
+	"service-core/storage/query"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// document is a record of some kind linked to a client
+type document struct {
+	kind     string
+	clientID uuid.UUID
+}
+
+type mockStore struct {
+	*query.Queries
+	clients   map[uuid.UUID]query.Client
+	documents []document
+	activity  []query.InsertActivityLogParams
+	updateErr error
+}
+
+// InTx runs fn against the store, restoring it if fn fails, as rolling back
+// would
+func (m *mockStore) InTx(ctx context.Context, fn func(q query.Querier) error) error {
+	clients, documents, activity := maps.Clone(m.clients), slices.Clone(m.documents), slices.Clone(m.activity)
+	if err := fn(m); err != nil {
+		m.clients, m.documents, m.activity = clients, documents, activity
+		return err
+	}
+	return nil
+}
+
+func (m *mockStore) SelectClient(ctx context.Context, id uuid.UUID) (query.Client, error) {
+	c, ok := m.clients[id]
+	if !ok {
+		return query.Client{}, sql.ErrNoRows
+	}
+	return c, nil
+}
+
+func (m *mockStore) UpdateClient(ctx context.Context, arg query.UpdateClientParams) (query.Client, error) {
+	if m.updateErr != nil {
+		return query.Client{}, m.updateErr
+	}
+	c, ok := m.clients[arg.ID]
+	if !ok {
+		return query.Client{}, sql.ErrNoRows
+	}
+	for _, other := range m.clients {
+		if other.ID != c.ID && other.AgencyID == c.AgencyID && other.Email == arg.Email {
+			return query.Client{}, errors.New("duplicate key value violates unique constraint")
+		}
+	}
+	c.BusinessName, c.Email, c.Phone, c.ContactName, c.Notes, c.Abn =
+		arg.BusinessName, arg.Email, arg.Phone, arg.ContactName, arg.Notes, arg.Abn
+	m.clients[c.ID] = c
+	return c, nil
+}
+
+func (m *mockStore) DeleteClient(ctx context.Context, id uuid.UUID) error {
+	delete(m.clients, id)
+	return nil
+}
+
+func (m *mockStore) InsertActivityLog(ctx context.Context, arg query.InsertActivityLogParams) error {
+	m.activity = append(m.activity, arg)
+	return nil
+}
+
+func (m *mockStore) reassign(kind string, from, to uuid.NullUUID) int64 {
+	var n int64
+	for i, d := range m.documents {
+		if d.kind == kind && d.clientID == from.UUID {
+			m.documents[i].clientID = to.UUID
+			n++
+		}
+	}
+	return n
+}
+
+func (m *mockStore) ReassignConsultationsClient(ctx context.Context, arg query.ReassignConsultationsClientParams) (int64, error) {
+	return m.reassign("consultation", arg.FromClientID, arg.ToClientID), nil
+}
+
+func (m *mockStore) ReassignProposalsClient(ctx context.Context, arg query.ReassignProposalsClientParams) (int64, error) {
+	return m.reassign("proposal", arg.FromClientID, arg.ToClientID), nil
+}
+
+func (m *mockStore) ReassignContractsClient(ctx context.Context, arg query.ReassignContractsClientParams) (int64, error) {
+	return m.reassign("contract", arg.FromClientID, arg.ToClientID), nil
+}
+
+func (m *mockStore) ReassignInvoicesClient(ctx context.Context, arg query.ReassignInvoicesClientParams) (int64, error) {
+	return m.reassign("invoice", arg.FromClientID, arg.ToClientID), nil
+}
+
+func (m *mockStore) ReassignQuotationsClient(ctx context.Context, arg query.ReassignQuotationsClientParams) (int64, error) {
+	return m.reassign("quotation", arg.FromClientID, arg.ToClientID), nil
+}
+
+func (m *mockStore) ReassignFormSubmissionsClient(ctx context.Context, arg query.ReassignFormSubmissionsClientParams) (int64, error) {
+	return m.reassign("form_submission", arg.FromClientID, arg.ToClientID), nil
+}
+
+func TestMergeClients(t *testing.T) {
+	t.Parallel()
+	agencyID := uuid.MustParse("00000000-0000-0000-0000-000000000100")
+	otherAgencyID := uuid.MustParse("00000000-0000-0000-0000-000000000200")
+	targetID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	sourceID := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	secondID := uuid.MustParse("00000000-0000-0000-0000-000000000003")
+	foreignID := uuid.MustParse("00000000-0000-0000-0000-000000000004")
+	user := auth.UserAttr{ID: uuid.MustParse("00000000-0000-0000-0000-000000000010"), AgencyID: agencyID, Role: auth.RoleOwner}
+
+	newStore := func() *mockStore {
+		return &mockStore{
+			clients: map[uuid.UUID]query.Client{
+				targetID: {ID: targetID, AgencyID: agencyID, BusinessName: "Acme",
+					Notes: sql.NullString{String: "Met at expo", Valid: true}},
+				sourceID: {ID: sourceID, AgencyID: agencyID, BusinessName: "Acme Pty Ltd", Email: "hello@acme.example",
+					Phone: sql.NullString{String: "0400 000 000", Valid: true}, Abn: "51824753556",
+					Notes: sql.NullString{String: "Prefers email", Valid: true}},
+				secondID: {ID: secondID, AgencyID: agencyID, BusinessName: "ACME", Email: "accounts@acme.example",
+					Phone:       sql.NullString{String: "0411 111 111", Valid: true},
+					ContactName: sql.NullString{String: "Jo Smith", Valid: true}},
+				foreignID: {ID: foreignID, AgencyID: otherAgencyID, BusinessName: "Acme", Email: "acme@other.example"},
+			},
+			documents: []document{
+				{"consultation", sourceID},
+				{"proposal", sourceID},
+				{"invoice", secondID},
+				{"invoice", secondID},
+				{"form_submission", secondID},
+				{"contract", targetID},
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		req        client.MergeRequest
+		updateErr  error
+		wantErr    error
+		want       query.Client
+		reassigned client.Documents
+	}{
+		{
+			name: "fills empty details from the sources in order",
+			req:  client.MergeRequest{TargetID: targetID, SourceIDs: []uuid.UUID{sourceID, secondID}},
+			want: query.Client{ID: targetID, AgencyID: agencyID, BusinessName: "Acme", Email: "hello@acme.example",
+				Phone:       sql.NullString{String: "0400 000 000", Valid: true},
+				ContactName: sql.NullString{String: "Jo Smith", Valid: true},
+				Notes:       sql.NullString{String: "Met at expo\n\nPrefers email", Valid: true},
+				Abn:         "51824753556"},
+			reassigned: client.Documents{Consultations: 1, Proposals: 1, Invoices: 2, FormSubmissions: 1},
+		},
+		{
+			name: "later sources fill what earlier ones lack",
+			req:  client.MergeRequest{TargetID: targetID, SourceIDs: []uuid.UUID{secondID, sourceID}},
+			want: query.Client{ID: targetID, AgencyID: agencyID, BusinessName: "Acme", Email: "accounts@acme.example",
+				Phone:       sql.NullString{String: "0411 111 111", Valid: true},
+				ContactName: sql.NullString{String: "Jo Smith", Valid: true},
+				Notes:       sql.NullString{String: "Met at expo\n\nPrefers email", Valid: true},
+				Abn:         "51824753556"},
+			reassigned: client.Documents{Consultations: 1, Proposals: 1, Invoices: 2, FormSubmissions: 1},
+		},
+		{
+			name:    "source from another agency",
+			req:     client.MergeRequest{TargetID: targetID, SourceIDs: []uuid.UUID{sourceID, foreignID}},
+			wantErr: pkg.NotFoundError{},
+		},
+		{
+			name:      "failed update rolls back",
+			req:       client.MergeRequest{TargetID: targetID, SourceIDs: []uuid.UUID{sourceID, secondID}},
+			updateErr: errors.New("connection reset"),
+			wantErr:   pkg.InternalError{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newStore()
+			store.updateErr = tt.updateErr
+			before := newStore()
+			s := client.NewService(store, store)
+
+			got, err := s.MergeClients(context.Background(), user, tt.req)
+			if tt.wantErr != nil {
+				switch tt.wantErr.(type) {
+				case pkg.NotFoundError:
+					var notFound pkg.NotFoundError
+					if !errors.As(err, &notFound) {
+						t.Fatalf("MergeClients() error = %v, want not found", err)
+					}
+				case pkg.InternalError:
+					var internal pkg.InternalError
+					if !errors.As(err, &internal) {
+						t.Fatalf("MergeClients() error = %v, want internal", err)
+					}
+				}
+				// Nothing is reassigned, deleted or logged
+				if !maps.Equal(store.clients, before.clients) || !slices.Equal(store.documents, before.documents) || len(store.activity) != 0 {
+					t.Errorf("failed merge changed the store: clients %v, documents %v, activity %d",
+						store.clients, store.documents, len(store.activity))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MergeClients() error = %v", err)
+			}
+			if got.Client != tt.want {
+				t.Errorf("merged client = %+v, want %+v", got.Client, tt.want)
+			}
+			if got.Reassigned != tt.reassigned {
+				t.Errorf("Reassigned = %+v, want %+v", got.Reassigned, tt.reassigned)
+			}
+			for _, id := range tt.req.SourceIDs {
+				if _, ok := store.clients[id]; ok {
+					t.Errorf("source %s was not deleted", id)
+				}
+			}
+			for _, d := range store.documents {
+				if d.clientID != targetID {
+					t.Errorf("%s still linked to %s", d.kind, d.clientID)
+				}
+			}
+			if len(store.activity) != 1 || store.activity[0].Action != "client.merged" || store.activity[0].EntityID.UUID != targetID {
+				t.Errorf("activity = %+v, want one client.merged entry for the target", store.activity)
+			}
+		})
+	}
+}
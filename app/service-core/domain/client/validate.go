@@ -0,0 +1,56 @@
+package client
+
+import (
+	"app/pkg"
+	"net/mail"
+)
+
+type schema struct {
+	businessName string
+	email        string
+	phone        string
+	abn          string
+}
+
+func validate(s *schema) error {
+	var errors pkg.ValidationErrors
+	if s.businessName == "" {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "businessName",
+			Tag:     "required",
+			Message: "Business name is required",
+		})
+	}
+	if len(s.businessName) > 255 {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "businessName",
+			Tag:     "max",
+			Message: "Business name must be at most 255 characters",
+		})
+	}
+	if _, err := mail.ParseAddress(s.email); err != nil || len(s.email) > 255 {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "email",
+			Tag:     "email",
+			Message: "Email must be a valid email address",
+		})
+	}
+	if len(s.phone) > 50 {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "phone",
+			Tag:     "max",
+			Message: "Phone must be at most 50 characters",
+		})
+	}
+	if s.abn != "" && NormaliseABN(s.abn) == "" {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "abn",
+			Tag:     "abn",
+			Message: "ABN must have 11 digits",
+		})
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
@@ -0,0 +1,196 @@
+package consultation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Change is one field-level difference between two consultation
+// snapshots. Fields inside JSON objects are named by their path, such as
+// "customData.pages". Lists report the items added and removed; other
+// values report the old and new value, omitted when empty.
+type Change struct {
+	Field   string `json:"field"`
+	Old     any    `json:"old,omitempty"`
+	New     any    `json:"new,omitempty"`
+	Added   []any  `json:"added,omitempty"`
+	Removed []any  `json:"removed,omitempty"`
+}
+
+// Diff returns the changes from one snapshot to another in field order.
+// Empty strings, lists and objects are treated as unset.
+func Diff(from, to Snapshot) []Change {
+	a, b := fields(from), fields(to)
+	changes := []Change{}
+	for _, key := range snapshotKeys {
+		changes = diffValue(changes, key, a[key], b[key])
+	}
+	return changes
+}
+
+// snapshotKeys are the snapshot's JSON keys in declaration order
+var snapshotKeys = func() []string {
+	t := reflect.TypeOf(Snapshot{})
+	keys := make([]string, t.NumField())
+	for i := range keys {
+		keys[i] = strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+	}
+	return keys
+}()
+
+func fields(s Snapshot) map[string]any {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return map[string]any{}
+	}
+	m := map[string]any{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&m); err != nil {
+		return map[string]any{}
+	}
+	return m
+}
+
+func diffValue(changes []Change, path string, a, b any) []Change {
+	a, b = normalise(a), normalise(b)
+	am, aIsMap := a.(map[string]any)
+	bm, bIsMap := b.(map[string]any)
+	if (aIsMap || a == nil) && (bIsMap || b == nil) && (aIsMap || bIsMap) {
+		keys := make([]string, 0, len(am)+len(bm))
+		for k := range am {
+			keys = append(keys, k)
+		}
+		for k := range bm {
+			if _, ok := am[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			changes = diffValue(changes, path+"."+k, am[k], bm[k])
+		}
+		return changes
+	}
+
+	al, aIsList := a.([]any)
+	bl, bIsList := b.([]any)
+	if (aIsList || a == nil) && (bIsList || b == nil) && (aIsList || bIsList) {
+		added, removed := diffList(al, bl)
+		switch {
+		case len(added) > 0 || len(removed) > 0:
+			return append(changes, Change{Field: path, Added: added, Removed: removed})
+		case !reflect.DeepEqual(al, bl):
+			// Same items in a new order
+			return append(changes, Change{Field: path, Old: a, New: b})
+		}
+		return changes
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		changes = append(changes, Change{Field: path, Old: a, New: b})
+	}
+	return changes
+}
+
+// diffList returns the items of b not in a and of a not in b, counting
+// repeated items
+func diffList(a, b []any) (added, removed []any) {
+	return missing(b, a), missing(a, b)
+}
+
+// missing returns the items of list not matched by an item of other
+func missing(list, other []any) []any {
+	counts := map[string]int{}
+	for _, v := range other {
+		counts[canonical(v)]++
+	}
+	var out []any
+	for _, v := range list {
+		k := canonical(v)
+		if counts[k] > 0 {
+			counts[k]--
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func canonical(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// normalise reads empty strings, lists and objects as unset
+func normalise(v any) any {
+	switch x := v.(type) {
+	case string:
+		if strings.TrimSpace(x) == "" {
+			return nil
+		}
+	case []any:
+		if len(x) == 0 {
+			return nil
+		}
+	case map[string]any:
+		if len(x) == 0 {
+			return nil
+		}
+	}
+	return v
+}
+
+// isEmptyJSON reports whether a JSON value is missing, null or an empty list,
+// object or string
+func isEmptyJSON(m json.RawMessage) bool {
+	if len(bytes.TrimSpace(m)) == 0 {
+		return true
+	}
+	var v any
+	if err := json.Unmarshal(m, &v); err != nil {
+		return false
+	}
+	return normalise(v) == nil
+}
+
+// Summary describes a set of changes for the version history, such as
+// "Updated business name, primary goals and 2 more fields"
+func Summary(changes []Change) string {
+	var labels []string
+	seen := map[string]bool{}
+	for _, c := range changes {
+		field, _, _ := strings.Cut(c.Field, ".")
+		if !seen[field] {
+			seen[field] = true
+			labels = append(labels, label(field))
+		}
+	}
+	switch n := len(labels); {
+	case n == 0:
+		return "No changes"
+	case n == 1:
+		return "Updated " + labels[0]
+	case n <= 3:
+		return "Updated " + strings.Join(labels[:n-1], ", ") + " and " + labels[n-1]
+	default:
+		return fmt.Sprintf("Updated %s and %d more fields", strings.Join(labels[:2], ", "), n-2)
+	}
+}
+
+// label turns a camel case field name into lower case words
+func label(field string) string {
+	var b strings.Builder
+	for i, r := range field {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
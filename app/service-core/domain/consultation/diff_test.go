@@ -0,0 +1,154 @@
+package consultation_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"service-core/domain/consultation"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	base := consultation.Snapshot{
+		BusinessName: "Harbour Cafe",
+		Email:        "hello@harbour.example",
+		PrimaryGoals: json.RawMessage(`["more bookings","online orders"]`),
+		CustomData:   json.RawMessage(`{"pages":5,"brand":{"colours":"blue"}}`),
+		Status:       consultation.StatusDraft,
+	}
+
+	tests := []struct {
+		name string
+		edit func(s *consultation.Snapshot)
+		want []consultation.Change
+	}{
+		{
+			name: "no changes",
+			edit: func(s *consultation.Snapshot) {},
+			want: []consultation.Change{},
+		},
+		{
+			name: "empty values are unset",
+			edit: func(s *consultation.Snapshot) {
+				s.Phone = " "
+				s.DesignStyles = json.RawMessage(`[]`)
+			},
+			want: []consultation.Change{},
+		},
+		{
+			name: "text field",
+			edit: func(s *consultation.Snapshot) { s.BusinessName = "Harbour Café" },
+			want: []consultation.Change{{Field: "businessName", Old: "Harbour Cafe", New: "Harbour Café"}},
+		},
+		{
+			name: "cleared field",
+			edit: func(s *consultation.Snapshot) { s.Email = "" },
+			want: []consultation.Change{{Field: "email", Old: "hello@harbour.example"}},
+		},
+		{
+			name: "list items",
+			edit: func(s *consultation.Snapshot) {
+				s.PrimaryGoals = json.RawMessage(`["online orders","brand awareness"]`)
+			},
+			want: []consultation.Change{{
+				Field:   "primaryGoals",
+				Added:   []any{"brand awareness"},
+				Removed: []any{"more bookings"},
+			}},
+		},
+		{
+			name: "list order",
+			edit: func(s *consultation.Snapshot) {
+				s.PrimaryGoals = json.RawMessage(`["online orders","more bookings"]`)
+			},
+			want: []consultation.Change{{
+				Field: "primaryGoals",
+				Old:   []any{"more bookings", "online orders"},
+				New:   []any{"online orders", "more bookings"},
+			}},
+		},
+		{
+			name: "nested custom data",
+			edit: func(s *consultation.Snapshot) {
+				s.CustomData = json.RawMessage(`{"pages":8,"brand":{"colours":"blue","logo":true}}`)
+			},
+			want: []consultation.Change{
+				{Field: "customData.brand.logo", New: true},
+				{Field: "customData.pages", Old: json.Number("5"), New: json.Number("8")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			next := base
+			tt.edit(&next)
+			got := consultation.Diff(base, next)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummary(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		fields []string
+		want   string
+	}{
+		{nil, "No changes"},
+		{[]string{"businessName"}, "Updated business name"},
+		{[]string{"customData.pages", "customData.brand"}, "Updated custom data"},
+		{[]string{"email", "primaryGoals", "timeline"}, "Updated email, primary goals and timeline"},
+		{[]string{"email", "phone", "website", "industry"}, "Updated email, phone and 2 more fields"},
+	}
+	for _, tt := range tests {
+		var changes []consultation.Change
+		for _, f := range tt.fields {
+			changes = append(changes, consultation.Change{Field: f})
+		}
+		if got := consultation.Summary(changes); got != tt.want {
+			t.Errorf("Summary(%v) = %q, want %q", tt.fields, got, tt.want)
+		}
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	t.Parallel()
+	if got := consultation.Completion(consultation.Snapshot{}); got != 0 {
+		t.Errorf("Completion(empty) = %d, want 0", got)
+	}
+	partial := consultation.Snapshot{
+		BusinessName:      "Harbour Cafe",
+		ContactPerson:     "Sam",
+		PrimaryChallenges: json.RawMessage(`[]`),
+		PrimaryGoals:      json.RawMessage(`["more bookings"]`),
+	}
+	// 3 of 17 questions answered; an empty list is unanswered
+	if got := consultation.Completion(partial); got != 17 {
+		t.Errorf("Completion(partial) = %d, want 17", got)
+	}
+	full := consultation.Snapshot{
+		BusinessName:      "a",
+		ContactPerson:     "a",
+		Email:             "a@b.example",
+		Phone:             "1",
+		Website:           "a",
+		Industry:          "a",
+		BusinessType:      "a",
+		WebsiteStatus:     "a",
+		PrimaryChallenges: json.RawMessage(`["a"]`),
+		UrgencyLevel:      "a",
+		PrimaryGoals:      json.RawMessage(`["a"]`),
+		ConversionGoal:    "a",
+		BudgetRange:       "a",
+		Timeline:          "a",
+		DesignStyles:      json.RawMessage(`["a"]`),
+		AdmiredWebsites:   json.RawMessage(`["a"]`),
+		ConsultationNotes: "a",
+	}
+	if got := consultation.Completion(full); got != 100 {
+		t.Errorf("Completion(full) = %d, want 100", got)
+	}
+}
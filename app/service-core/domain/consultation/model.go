@@ -0,0 +1,73 @@
+package consultation
+
+import (
+	"encoding/json"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// Status is the lifecycle state of a consultation
+type Status string
+
+const (
+	StatusDraft     Status = "draft"
+	StatusCompleted Status = "completed"
+	StatusArchived  Status = "archived"
+	StatusConverted Status = "converted"
+)
+
+// IsValid reports whether s is a known consultation status
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusDraft, StatusCompleted, StatusArchived, StatusConverted:
+		return true
+	}
+	return false
+}
+
+// UpdateRequest edits a consultation. Nil fields are left unchanged; JSON
+// fields replace the stored value when set.
+type UpdateRequest struct {
+	ClientID          *uuid.UUID       `json:"clientId"`
+	BusinessName      *string          `json:"businessName"`
+	ContactPerson     *string          `json:"contactPerson"`
+	Email             *string          `json:"email"`
+	Phone             *string          `json:"phone"`
+	Website           *string          `json:"website"`
+	SocialLinkedin    *string          `json:"socialLinkedin"`
+	SocialFacebook    *string          `json:"socialFacebook"`
+	SocialInstagram   *string          `json:"socialInstagram"`
+	Industry          *string          `json:"industry"`
+	BusinessType      *string          `json:"businessType"`
+	WebsiteStatus     *string          `json:"websiteStatus"`
+	PrimaryChallenges *json.RawMessage `json:"primaryChallenges"`
+	UrgencyLevel      *string          `json:"urgencyLevel"`
+	PrimaryGoals      *json.RawMessage `json:"primaryGoals"`
+	ConversionGoal    *string          `json:"conversionGoal"`
+	BudgetRange       *string          `json:"budgetRange"`
+	Timeline          *string          `json:"timeline"`
+	DesignStyles      *json.RawMessage `json:"designStyles"`
+	AdmiredWebsites   *json.RawMessage `json:"admiredWebsites"`
+	ConsultationNotes *string          `json:"consultationNotes"`
+	CustomData        *json.RawMessage `json:"customData"`
+	Status            *Status          `json:"status"`
+}
+
+// CreateRequest is the input for creating a consultation. New
+// consultations start as drafts unless a status is given.
+type CreateRequest struct {
+	UpdateRequest
+}
+
+// ListResponse is a page of consultations for an agency
+type ListResponse struct {
+	Count         int64                `json:"count"`
+	Consultations []query.Consultation `json:"consultations"`
+}
+
+// Version is a consultation version with its decoded diff
+type Version struct {
+	query.ConsultationVersion
+	Changes []Change `json:"changes"`
+}
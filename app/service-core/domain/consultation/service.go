@@ -0,0 +1,398 @@
+package consultation
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"service-core/domain/activity"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+)
+
+// store defines the database interface for consultation operations
+type store interface {
+	CountConsultations(ctx context.Context, arg query.CountConsultationsParams) (int64, error)
+	SelectConsultations(ctx context.Context, arg query.SelectConsultationsParams) ([]query.Consultation, error)
+	SelectConsultation(ctx context.Context, id uuid.UUID) (query.Consultation, error)
+	SelectConsultationVersions(ctx context.Context, consultationID uuid.UUID) ([]query.ConsultationVersion, error)
+	SelectConsultationVersion(ctx context.Context, arg query.SelectConsultationVersionParams) (query.ConsultationVersion, error)
+	InsertActivityLog(ctx context.Context, arg query.InsertActivityLogParams) error
+}
+
+// Service handles agency consultations and their version history
+type Service struct {
+	db    *sql.DB
+	store store
+}
+
+// NewService creates a new consultation service
+func NewService(db *sql.DB, store store) *Service {
+	return &Service{
+		db:    db,
+		store: store,
+	}
+}
+
+// ListConsultations returns a page of an agency's consultations, optionally
+// filtered by status
+func (s *Service) ListConsultations(
+	ctx context.Context,
+	agencyID uuid.UUID,
+	status string,
+	page int32,
+	limit int32,
+) (*ListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+	count, err := s.store.CountConsultations(ctx, query.CountConsultationsParams{
+		AgencyID: agencyID,
+		Status:   status,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error counting consultations", Err: err}
+	}
+	consultations, err := s.store.SelectConsultations(ctx, query.SelectConsultationsParams{
+		AgencyID:  agencyID,
+		Status:    status,
+		RowLimit:  limit,
+		RowOffset: (page - 1) * limit,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting consultations", Err: err}
+	}
+	if consultations == nil {
+		consultations = []query.Consultation{}
+	}
+	return &ListResponse{
+		Count:         count,
+		Consultations: consultations,
+	}, nil
+}
+
+// GetConsultation returns a consultation belonging to the agency
+func (s *Service) GetConsultation(ctx context.Context, agencyID, id uuid.UUID) (*query.Consultation, error) {
+	return s.get(ctx, agencyID, id)
+}
+
+// CreateConsultation creates a consultation and records it as version 1
+func (s *Service) CreateConsultation(
+	ctx context.Context,
+	user auth.UserAttr,
+	req CreateRequest,
+) (*query.Consultation, error) {
+	if err := auth.Authorize(auth.CreateConsultation, user, auth.Resource{OwnerID: user.ID, AgencyID: user.AgencyID}); err != nil {
+		return nil, err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error starting consultation create", Err: err}
+	}
+	defer tx.Rollback()
+
+	c, err := s.Create(ctx, query.New(tx), user.AgencyID, user.ID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, pkg.InternalError{Message: "Error committing consultation", Err: err}
+	}
+	s.logActivity(ctx, c, user.ID, "consultation.created", nil, map[string]any{
+		"businessName": c.BusinessName.String,
+		"status":       c.Status,
+	}, nil)
+	return c, nil
+}
+
+// Create inserts a consultation with its first version on the given
+// queries, so callers can create it within their own transaction. Activity
+// is written by the caller.
+func (s *Service) Create(
+	ctx context.Context,
+	q query.Querier,
+	agencyID uuid.UUID,
+	userID uuid.UUID,
+	req CreateRequest,
+) (*query.Consultation, error) {
+	snapshot := Snapshot{Status: StatusDraft}.apply(req.UpdateRequest)
+	if err := validate(snapshot); err != nil {
+		return nil, err
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating consultation ID", Err: err}
+	}
+
+	c, err := q.InsertConsultation(ctx, snapshot.insertParams(id, agencyID, userID))
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error inserting consultation", Err: err}
+	}
+	if snapshot.Status == StatusCompleted {
+		c, err = q.UpdateConsultation(ctx, snapshot.updateParams(c.ID, sql.NullTime{Time: c.CreatedAt, Valid: true}))
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error updating consultation", Err: err}
+		}
+	}
+	if _, err := s.writeVersion(ctx, q, &c, userID, Diff(Snapshot{}, snapshot), "Consultation created"); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// UpdateConsultation applies changes to a consultation, recalculates its
+// completion percentage and records a version with a field-level diff. A
+// request that changes nothing writes no version.
+func (s *Service) UpdateConsultation(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	req UpdateRequest,
+) (*query.Consultation, error) {
+	return s.update(ctx, user, id, func(current Snapshot) (Snapshot, string) {
+		return current.apply(req), ""
+	})
+}
+
+// ListVersions returns a consultation's versions, newest first
+func (s *Service) ListVersions(ctx context.Context, agencyID, id uuid.UUID) ([]Version, error) {
+	c, err := s.get(ctx, agencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.store.SelectConsultationVersions(ctx, c.ID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting consultation versions", Err: err}
+	}
+	versions := make([]Version, 0, len(rows))
+	for _, row := range rows {
+		versions = append(versions, newVersion(row))
+	}
+	return versions, nil
+}
+
+// GetVersion returns one version of a consultation
+func (s *Service) GetVersion(ctx context.Context, agencyID, id uuid.UUID, number int32) (*Version, error) {
+	c, err := s.get(ctx, agencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	row, err := s.version(ctx, c.ID, number)
+	if err != nil {
+		return nil, err
+	}
+	v := newVersion(*row)
+	return &v, nil
+}
+
+// RestoreVersion returns a consultation to its content at a prior version.
+// The status is left as it is. The restore is itself recorded as a new
+// version, so it can be undone.
+func (s *Service) RestoreVersion(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	number int32,
+) (*query.Consultation, error) {
+	c, err := s.get(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	row, err := s.version(ctx, c.ID, number)
+	if err != nil {
+		return nil, err
+	}
+	if !row.Snapshot.Valid {
+		return nil, pkg.BadRequestError{
+			Message: fmt.Sprintf("Version %d was recorded without a snapshot and cannot be restored", number),
+			Err:     errors.New("version has no snapshot"),
+		}
+	}
+	var restored Snapshot
+	if err := json.Unmarshal(row.Snapshot.RawMessage, &restored); err != nil {
+		return nil, pkg.InternalError{Message: "Error reading consultation version", Err: err}
+	}
+	return s.update(ctx, user, id, func(current Snapshot) (Snapshot, string) {
+		restored.Status = current.Status
+		return restored, fmt.Sprintf("Restored version %d", number)
+	})
+}
+
+// update locks a consultation, checks the user may edit it, applies change
+// to its current snapshot and stores the result with a new version in one
+// transaction
+func (s *Service) update(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	change func(current Snapshot) (next Snapshot, summary string),
+) (*query.Consultation, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error starting consultation update", Err: err}
+	}
+	defer tx.Rollback()
+	q := query.New(tx)
+
+	existing, err := q.SelectConsultationForUpdate(ctx, id)
+	if err != nil || existing.AgencyID != user.AgencyID {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Consultation not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting consultation", Err: err}
+	}
+	resource := auth.Resource{OwnerID: existing.CreatedBy.UUID, AgencyID: existing.AgencyID}
+	if err := auth.Authorize(auth.EditConsultation, user, resource); err != nil {
+		return nil, err
+	}
+	current := snapshotOf(existing)
+	next, summary := change(current)
+	if err := validate(next); err != nil {
+		return nil, err
+	}
+	changes := Diff(current, next)
+	if len(changes) == 0 {
+		return &existing, nil
+	}
+	if summary == "" {
+		summary = Summary(changes)
+	}
+
+	params := next.updateParams(existing.ID, completedAt(existing.CompletedAt, current.Status, next.Status, time.Now()))
+	c, err := q.UpdateConsultation(ctx, params)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating consultation", Err: err}
+	}
+	v, err := s.writeVersion(ctx, q, &c, user.ID, changes, summary)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, pkg.InternalError{Message: "Error committing consultation update", Err: err}
+	}
+	s.logActivity(ctx, &c, user.ID, "consultation.updated", nil, map[string]any{
+		"changedFields": changes,
+	}, map[string]any{"versionNumber": v.VersionNumber, "summary": summary})
+	return &c, nil
+}
+
+// writeVersion records the consultation's current content as its next
+// version
+func (s *Service) writeVersion(
+	ctx context.Context,
+	q query.Querier,
+	c *query.Consultation,
+	userID uuid.UUID,
+	changes []Change,
+	summary string,
+) (*query.ConsultationVersion, error) {
+	latest, err := q.SelectLatestConsultationVersionNumber(ctx, c.ID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting consultation version", Err: err}
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating version ID", Err: err}
+	}
+	changed, err := json.Marshal(changes)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error encoding consultation changes", Err: err}
+	}
+	snapshot, err := json.Marshal(snapshotOf(*c))
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error encoding consultation snapshot", Err: err}
+	}
+	v, err := q.InsertConsultationVersion(ctx, query.InsertConsultationVersionParams{
+		ID:                   id,
+		ConsultationID:       c.ID,
+		UserID:               userID,
+		AgencyID:             uuid.NullUUID{UUID: c.AgencyID, Valid: true},
+		VersionNumber:        latest + 1,
+		Status:               c.Status,
+		CompletionPercentage: c.CompletionPercentage,
+		ChangeSummary:        sql.NullString{String: summary, Valid: summary != ""},
+		ChangedFields:        changed,
+		Snapshot:             pqtype.NullRawMessage{RawMessage: snapshot, Valid: true},
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error inserting consultation version", Err: err}
+	}
+	return &v, nil
+}
+
+func (s *Service) get(ctx context.Context, agencyID, id uuid.UUID) (*query.Consultation, error) {
+	c, err := s.store.SelectConsultation(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Consultation not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting consultation", Err: err}
+	}
+	// Consultations from other agencies are reported as missing rather than forbidden
+	if c.AgencyID != agencyID {
+		return nil, pkg.NotFoundError{Message: "Consultation not found", Err: fmt.Errorf("consultation %s belongs to another agency", id)}
+	}
+	return &c, nil
+}
+
+func (s *Service) version(ctx context.Context, consultationID uuid.UUID, number int32) (*query.ConsultationVersion, error) {
+	v, err := s.store.SelectConsultationVersion(ctx, query.SelectConsultationVersionParams{
+		ConsultationID: consultationID,
+		VersionNumber:  number,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Consultation version not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting consultation version", Err: err}
+	}
+	return &v, nil
+}
+
+// newVersion decodes a stored version's diff. Versions written before
+// structured diffs hold a list of field names, which are reported as
+// changes without values.
+func newVersion(row query.ConsultationVersion) Version {
+	v := Version{ConsultationVersion: row, Changes: []Change{}}
+	if err := json.Unmarshal(row.ChangedFields, &v.Changes); err == nil {
+		return v
+	}
+	var names []string
+	if err := json.Unmarshal(row.ChangedFields, &names); err == nil {
+		for _, name := range names {
+			v.Changes = append(v.Changes, Change{Field: name})
+		}
+	}
+	return v
+}
+
+// logActivity records a change to a consultation in the agency activity log
+func (s *Service) logActivity(
+	ctx context.Context,
+	c *query.Consultation,
+	userID uuid.UUID,
+	action string,
+	oldValues any,
+	newValues any,
+	metadata any,
+) {
+	activity.Log(ctx, s.store, activity.Entry{
+		AgencyID:   c.AgencyID,
+		UserID:     userID,
+		Action:     action,
+		EntityType: "consultation",
+		EntityID:   c.ID,
+		OldValues:  oldValues,
+		NewValues:  newValues,
+		Metadata:   metadata,
+	})
+}
@@ -0,0 +1,268 @@
+package consultation
+
+import (
+	"database/sql"
+	"encoding/json"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+)
+
+// Snapshot is the editable content of a consultation. Each version stores
+// the snapshot taken after its change so the version can be restored.
+type Snapshot struct {
+	ClientID          *uuid.UUID      `json:"clientId"`
+	BusinessName      string          `json:"businessName"`
+	ContactPerson     string          `json:"contactPerson"`
+	Email             string          `json:"email"`
+	Phone             string          `json:"phone"`
+	Website           string          `json:"website"`
+	SocialLinkedin    string          `json:"socialLinkedin"`
+	SocialFacebook    string          `json:"socialFacebook"`
+	SocialInstagram   string          `json:"socialInstagram"`
+	Industry          string          `json:"industry"`
+	BusinessType      string          `json:"businessType"`
+	WebsiteStatus     string          `json:"websiteStatus"`
+	PrimaryChallenges json.RawMessage `json:"primaryChallenges"`
+	UrgencyLevel      string          `json:"urgencyLevel"`
+	PrimaryGoals      json.RawMessage `json:"primaryGoals"`
+	ConversionGoal    string          `json:"conversionGoal"`
+	BudgetRange       string          `json:"budgetRange"`
+	Timeline          string          `json:"timeline"`
+	DesignStyles      json.RawMessage `json:"designStyles"`
+	AdmiredWebsites   json.RawMessage `json:"admiredWebsites"`
+	ConsultationNotes string          `json:"consultationNotes"`
+	CustomData        json.RawMessage `json:"customData"`
+	Status            Status          `json:"status"`
+}
+
+// snapshotOf returns the editable content of a stored consultation
+func snapshotOf(c query.Consultation) Snapshot {
+	s := Snapshot{
+		BusinessName:      c.BusinessName.String,
+		ContactPerson:     c.ContactPerson.String,
+		Email:             c.Email.String,
+		Phone:             c.Phone.String,
+		Website:           c.Website.String,
+		SocialLinkedin:    c.SocialLinkedin.String,
+		SocialFacebook:    c.SocialFacebook.String,
+		SocialInstagram:   c.SocialInstagram.String,
+		Industry:          c.Industry.String,
+		BusinessType:      c.BusinessType.String,
+		WebsiteStatus:     c.WebsiteStatus.String,
+		PrimaryChallenges: rawJSON(c.PrimaryChallenges),
+		UrgencyLevel:      c.UrgencyLevel.String,
+		PrimaryGoals:      rawJSON(c.PrimaryGoals),
+		ConversionGoal:    c.ConversionGoal.String,
+		BudgetRange:       c.BudgetRange.String,
+		Timeline:          c.Timeline.String,
+		DesignStyles:      rawJSON(c.DesignStyles),
+		AdmiredWebsites:   rawJSON(c.AdmiredWebsites),
+		ConsultationNotes: c.ConsultationNotes.String,
+		CustomData:        rawJSON(c.CustomData),
+		Status:            Status(c.Status),
+	}
+	if c.ClientID.Valid {
+		id := c.ClientID.UUID
+		s.ClientID = &id
+	}
+	return s
+}
+
+// apply returns the snapshot with the requested changes applied
+func (s Snapshot) apply(req UpdateRequest) Snapshot {
+	if req.ClientID != nil {
+		s.ClientID = req.ClientID
+		if *req.ClientID == uuid.Nil {
+			s.ClientID = nil
+		}
+	}
+	setString(&s.BusinessName, req.BusinessName)
+	setString(&s.ContactPerson, req.ContactPerson)
+	setString(&s.Email, req.Email)
+	setString(&s.Phone, req.Phone)
+	setString(&s.Website, req.Website)
+	setString(&s.SocialLinkedin, req.SocialLinkedin)
+	setString(&s.SocialFacebook, req.SocialFacebook)
+	setString(&s.SocialInstagram, req.SocialInstagram)
+	setString(&s.Industry, req.Industry)
+	setString(&s.BusinessType, req.BusinessType)
+	setString(&s.WebsiteStatus, req.WebsiteStatus)
+	setJSON(&s.PrimaryChallenges, req.PrimaryChallenges)
+	setString(&s.UrgencyLevel, req.UrgencyLevel)
+	setJSON(&s.PrimaryGoals, req.PrimaryGoals)
+	setString(&s.ConversionGoal, req.ConversionGoal)
+	setString(&s.BudgetRange, req.BudgetRange)
+	setString(&s.Timeline, req.Timeline)
+	setJSON(&s.DesignStyles, req.DesignStyles)
+	setJSON(&s.AdmiredWebsites, req.AdmiredWebsites)
+	setString(&s.ConsultationNotes, req.ConsultationNotes)
+	setJSON(&s.CustomData, req.CustomData)
+	if req.Status != nil {
+		s.Status = *req.Status
+	}
+	return s
+}
+
+// completionFields are the questions of the four consultation steps that
+// count towards the completion percentage
+func (s Snapshot) completionFields() []bool {
+	return []bool{
+		s.BusinessName != "",
+		s.ContactPerson != "",
+		s.Email != "",
+		s.Phone != "",
+		s.Website != "",
+		s.Industry != "",
+		s.BusinessType != "",
+		s.WebsiteStatus != "",
+		!isEmptyJSON(s.PrimaryChallenges),
+		s.UrgencyLevel != "",
+		!isEmptyJSON(s.PrimaryGoals),
+		s.ConversionGoal != "",
+		s.BudgetRange != "",
+		s.Timeline != "",
+		!isEmptyJSON(s.DesignStyles),
+		!isEmptyJSON(s.AdmiredWebsites),
+		s.ConsultationNotes != "",
+	}
+}
+
+// Completion returns the percentage of consultation questions answered,
+// rounded down
+func Completion(s Snapshot) int32 {
+	fields := s.completionFields()
+	answered := 0
+	for _, ok := range fields {
+		if ok {
+			answered++
+		}
+	}
+	return int32(answered * 100 / len(fields))
+}
+
+// insertParams returns insert params for a new consultation with the
+// snapshot's content
+func (s Snapshot) insertParams(id, agencyID, userID uuid.UUID) query.InsertConsultationParams {
+	u := s.updateParams(id, sql.NullTime{})
+	return query.InsertConsultationParams{
+		ID:                   id,
+		UserID:               uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+		AgencyID:             agencyID,
+		ClientID:             u.ClientID,
+		BusinessName:         u.BusinessName,
+		ContactPerson:        u.ContactPerson,
+		Email:                u.Email,
+		Phone:                u.Phone,
+		Website:              u.Website,
+		SocialLinkedin:       u.SocialLinkedin,
+		SocialFacebook:       u.SocialFacebook,
+		SocialInstagram:      u.SocialInstagram,
+		Industry:             u.Industry,
+		BusinessType:         u.BusinessType,
+		WebsiteStatus:        u.WebsiteStatus,
+		PrimaryChallenges:    u.PrimaryChallenges,
+		UrgencyLevel:         u.UrgencyLevel,
+		PrimaryGoals:         u.PrimaryGoals,
+		ConversionGoal:       u.ConversionGoal,
+		BudgetRange:          u.BudgetRange,
+		Timeline:             u.Timeline,
+		DesignStyles:         u.DesignStyles,
+		AdmiredWebsites:      u.AdmiredWebsites,
+		ConsultationNotes:    u.ConsultationNotes,
+		CustomData:           u.CustomData,
+		CreatedBy:            uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+		Status:               u.Status,
+		CompletionPercentage: u.CompletionPercentage,
+	}
+}
+
+// updateParams returns update params storing the snapshot's content with
+// its completion percentage
+func (s Snapshot) updateParams(id uuid.UUID, completedAt sql.NullTime) query.UpdateConsultationParams {
+	params := query.UpdateConsultationParams{
+		ID:                   id,
+		BusinessName:         nullString(s.BusinessName),
+		ContactPerson:        nullString(s.ContactPerson),
+		Email:                nullString(s.Email),
+		Phone:                nullString(s.Phone),
+		Website:              nullString(s.Website),
+		SocialLinkedin:       nullString(s.SocialLinkedin),
+		SocialFacebook:       nullString(s.SocialFacebook),
+		SocialInstagram:      nullString(s.SocialInstagram),
+		Industry:             nullString(s.Industry),
+		BusinessType:         nullString(s.BusinessType),
+		WebsiteStatus:        nullString(s.WebsiteStatus),
+		PrimaryChallenges:    arrayJSON(s.PrimaryChallenges),
+		UrgencyLevel:         nullString(s.UrgencyLevel),
+		PrimaryGoals:         arrayJSON(s.PrimaryGoals),
+		ConversionGoal:       nullString(s.ConversionGoal),
+		BudgetRange:          nullString(s.BudgetRange),
+		Timeline:             nullString(s.Timeline),
+		DesignStyles:         arrayJSON(s.DesignStyles),
+		AdmiredWebsites:      arrayJSON(s.AdmiredWebsites),
+		ConsultationNotes:    nullString(s.ConsultationNotes),
+		CustomData:           nullRawJSON(s.CustomData),
+		Status:               string(s.Status),
+		CompletionPercentage: Completion(s),
+		CompletedAt:          completedAt,
+	}
+	if s.ClientID != nil {
+		params.ClientID = uuid.NullUUID{UUID: *s.ClientID, Valid: true}
+	}
+	return params
+}
+
+// completedAt returns the completion time to store after a change of status:
+// set when a consultation is completed and kept until it is reopened as a
+// draft
+func completedAt(current sql.NullTime, from, to Status, now time.Time) sql.NullTime {
+	switch {
+	case to == StatusDraft:
+		return sql.NullTime{}
+	case to == StatusCompleted && from != StatusCompleted:
+		return sql.NullTime{Time: now, Valid: true}
+	}
+	return current
+}
+
+func rawJSON(m pqtype.NullRawMessage) json.RawMessage {
+	if !m.Valid || len(m.RawMessage) == 0 {
+		return nil
+	}
+	return m.RawMessage
+}
+
+// arrayJSON stores an empty list for the list columns, matching the
+// column defaults
+func arrayJSON(m json.RawMessage) pqtype.NullRawMessage {
+	if isEmptyJSON(m) {
+		return pqtype.NullRawMessage{RawMessage: json.RawMessage(`[]`), Valid: true}
+	}
+	return pqtype.NullRawMessage{RawMessage: m, Valid: true}
+}
+
+func nullRawJSON(m json.RawMessage) pqtype.NullRawMessage {
+	if isEmptyJSON(m) {
+		return pqtype.NullRawMessage{}
+	}
+	return pqtype.NullRawMessage{RawMessage: m, Valid: true}
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func setString(dst *string, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func setJSON(dst *json.RawMessage, src *json.RawMessage) {
+	if src != nil {
+		*dst = *src
+	}
+}
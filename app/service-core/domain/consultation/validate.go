@@ -0,0 +1,58 @@
+package consultation
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"net/mail"
+)
+
+func validate(s Snapshot) error {
+	var errors pkg.ValidationErrors
+	if s.Email != "" {
+		if _, err := mail.ParseAddress(s.Email); err != nil {
+			errors = append(errors, pkg.ValidationError{
+				Field:   "email",
+				Tag:     "email",
+				Message: "Email must be a valid email address",
+			})
+		}
+	}
+	if !s.Status.IsValid() {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "status",
+			Tag:     "oneof",
+			Message: "Status must be draft, completed, archived or converted",
+		})
+	}
+	lists := []struct {
+		field string
+		value json.RawMessage
+	}{
+		{"primaryChallenges", s.PrimaryChallenges},
+		{"primaryGoals", s.PrimaryGoals},
+		{"designStyles", s.DesignStyles},
+		{"admiredWebsites", s.AdmiredWebsites},
+	}
+	for _, l := range lists {
+		var v []any
+		if len(l.value) > 0 && string(l.value) != "null" && json.Unmarshal(l.value, &v) != nil {
+			errors = append(errors, pkg.ValidationError{
+				Field:   l.field,
+				Tag:     "array",
+				Message: "Must be a list",
+			})
+		}
+	}
+	var custom map[string]any
+	if len(s.CustomData) > 0 && string(s.CustomData) != "null" && json.Unmarshal(s.CustomData, &custom) != nil {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "customData",
+			Tag:     "object",
+			Message: "Custom data must be an object",
+		})
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
@@ -0,0 +1,211 @@
+package contract
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"service-core/domain/proposal"
+	"service-core/storage/query"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const slugAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// newSlug returns a random 12 character public URL slug
+func newSlug() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = slugAlphabet[int(b[i])%len(slugAlphabet)]
+	}
+	return string(b), nil
+}
+
+// newContract returns a draft contract snapshotting the proposal's client
+// details and price
+func newContract(
+	p *query.Proposal,
+	pricing *proposal.PricingBreakdown,
+	tmpl *query.ContractTemplate,
+	userID uuid.UUID,
+	req CreateRequest,
+	now time.Time,
+) query.Contract {
+	c := query.Contract{
+		CreatedAt:           now,
+		AgencyID:            p.AgencyID,
+		ProposalID:          p.ID,
+		ClientID:            p.ClientID,
+		Version:             1,
+		Status:              string(StatusDraft),
+		ClientBusinessName:  p.ClientBusinessName,
+		ClientContactName:   p.ClientContactName,
+		ClientEmail:         p.ClientEmail,
+		ClientPhone:         p.ClientPhone,
+		ClientAddress:       req.ClientAddress,
+		ServicesDescription: req.ServicesDescription,
+		CommencementDate:    nullTime(req.CommencementDate),
+		CompletionDate:      nullTime(req.CompletionDate),
+		SpecialConditions:   req.SpecialConditions,
+		TotalPrice:          pricing.OneTime.Total,
+		PriceIncludesGst:    pricing.GSTRegistered,
+		PaymentTerms:        paymentTerms(pricing),
+		ValidUntil:          sql.NullTime{Time: now.Add(defaultValidity), Valid: true},
+		CreatedBy:           uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+	}
+	if req.ValidUntil != nil {
+		c.ValidUntil = sql.NullTime{Time: *req.ValidUntil, Valid: true}
+	}
+	if c.ServicesDescription == "" {
+		name := pricing.PackageName
+		if name == "" {
+			name = "Services"
+		}
+		c.ServicesDescription = fmt.Sprintf("%s as described in proposal %s", name, p.ProposalNumber)
+	}
+	if tmpl != nil {
+		c.TemplateID = uuid.NullUUID{UUID: tmpl.ID, Valid: true}
+		sig := decodeConfig[SignatureConfig](tmpl.SignatureConfig)
+		c.AgencySignatoryName = nullString(sig.AgencySignatory)
+		c.AgencySignatoryTitle = nullString(sig.AgencyTitle)
+	}
+	return c
+}
+
+// paymentTerms describes when the proposal's one-time and recurring fees
+// are due
+func paymentTerms(pricing *proposal.PricingBreakdown) string {
+	var terms []string
+	if !pricing.OneTime.Total.IsZero() {
+		terms = append(terms, fmt.Sprintf("One-time fees of %s due on contract signing", pricing.OneTime.Total.Format()))
+	}
+	if !pricing.Monthly.Total.IsZero() {
+		t := fmt.Sprintf("Monthly fees of %s billed in advance", pricing.Monthly.Total.Format())
+		if m := minimumTerm(pricing.MinimumTermMonths); m != "" {
+			t += " for a minimum term of " + m
+		}
+		terms = append(terms, t)
+	}
+	if len(terms) == 0 {
+		return "Payment terms to be agreed upon signing."
+	}
+	return strings.Join(terms, ". ") + "."
+}
+
+func applyUpdate(c *query.Contract, req UpdateRequest) {
+	setString(&c.ClientBusinessName, req.ClientBusinessName)
+	setString(&c.ClientContactName, req.ClientContactName)
+	setString(&c.ClientEmail, req.ClientEmail)
+	setString(&c.ClientPhone, req.ClientPhone)
+	setString(&c.ClientAddress, req.ClientAddress)
+	setString(&c.ServicesDescription, req.ServicesDescription)
+	setString(&c.SpecialConditions, req.SpecialConditions)
+	setString(&c.PaymentTerms, req.PaymentTerms)
+	if req.CommencementDate != nil {
+		c.CommencementDate = nullTime(req.CommencementDate)
+	}
+	if req.CompletionDate != nil {
+		c.CompletionDate = nullTime(req.CompletionDate)
+	}
+	if req.ValidUntil != nil {
+		c.ValidUntil = nullTime(req.ValidUntil)
+	}
+	if req.TotalPrice != nil {
+		c.TotalPrice = *req.TotalPrice
+	}
+	if req.AgencySignatoryName != nil {
+		c.AgencySignatoryName = nullString(*req.AgencySignatoryName)
+	}
+	if req.AgencySignatoryTitle != nil {
+		c.AgencySignatoryTitle = nullString(*req.AgencySignatoryTitle)
+	}
+}
+
+func insertParams(c query.Contract) query.InsertContractParams {
+	return query.InsertContractParams{
+		ID:                    c.ID,
+		AgencyID:              c.AgencyID,
+		ProposalID:            c.ProposalID,
+		TemplateID:            c.TemplateID,
+		ClientID:              c.ClientID,
+		ContractNumber:        c.ContractNumber,
+		Slug:                  c.Slug,
+		Status:                c.Status,
+		ClientBusinessName:    c.ClientBusinessName,
+		ClientContactName:     c.ClientContactName,
+		ClientEmail:           c.ClientEmail,
+		ClientPhone:           c.ClientPhone,
+		ClientAddress:         c.ClientAddress,
+		ServicesDescription:   c.ServicesDescription,
+		CommencementDate:      c.CommencementDate,
+		CompletionDate:        c.CompletionDate,
+		SpecialConditions:     c.SpecialConditions,
+		TotalPrice:            c.TotalPrice,
+		PriceIncludesGst:      c.PriceIncludesGst,
+		PaymentTerms:          c.PaymentTerms,
+		GeneratedCoverHtml:    c.GeneratedCoverHtml,
+		GeneratedTermsHtml:    c.GeneratedTermsHtml,
+		GeneratedScheduleHtml: c.GeneratedScheduleHtml,
+		ValidUntil:            c.ValidUntil,
+		AgencySignatoryName:   c.AgencySignatoryName,
+		AgencySignatoryTitle:  c.AgencySignatoryTitle,
+		IncludedScheduleIds:   c.IncludedScheduleIds,
+		CreatedBy:             c.CreatedBy,
+	}
+}
+
+func updateParams(c query.Contract) query.UpdateContractParams {
+	return query.UpdateContractParams{
+		ID:                    c.ID,
+		ClientBusinessName:    c.ClientBusinessName,
+		ClientContactName:     c.ClientContactName,
+		ClientEmail:           c.ClientEmail,
+		ClientPhone:           c.ClientPhone,
+		ClientAddress:         c.ClientAddress,
+		ServicesDescription:   c.ServicesDescription,
+		CommencementDate:      c.CommencementDate,
+		CompletionDate:        c.CompletionDate,
+		SpecialConditions:     c.SpecialConditions,
+		TotalPrice:            c.TotalPrice,
+		PriceIncludesGst:      c.PriceIncludesGst,
+		PaymentTerms:          c.PaymentTerms,
+		GeneratedCoverHtml:    c.GeneratedCoverHtml,
+		GeneratedTermsHtml:    c.GeneratedTermsHtml,
+		GeneratedScheduleHtml: c.GeneratedScheduleHtml,
+		ValidUntil:            c.ValidUntil,
+		AgencySignatoryName:   c.AgencySignatoryName,
+		AgencySignatoryTitle:  c.AgencySignatoryTitle,
+		IncludedScheduleIds:   c.IncludedScheduleIds,
+		Version:               c.Version,
+	}
+}
+
+func setString(dst *string, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil || t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+// truncate limits a request-supplied value to a column's width
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
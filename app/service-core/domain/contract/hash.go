@@ -0,0 +1,154 @@
+package contract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// hashVersion prefixes hashed payloads so the layout can change later
+// without old hashes becoming ambiguous
+const hashVersion = "contract/v1\n"
+
+// signedContent is everything a signatory agrees to. Status, view tracking
+// and signature columns are excluded so they can change without breaking
+// the hash.
+type signedContent struct {
+	ContractID          uuid.UUID `json:"contractId"`
+	AgencyID            uuid.UUID `json:"agencyId"`
+	ContractNumber      string    `json:"contractNumber"`
+	Version             int32     `json:"version"`
+	ClientBusinessName  string    `json:"clientBusinessName"`
+	ClientContactName   string    `json:"clientContactName"`
+	ClientEmail         string    `json:"clientEmail"`
+	ClientPhone         string    `json:"clientPhone"`
+	ClientAddress       string    `json:"clientAddress"`
+	ServicesDescription string    `json:"servicesDescription"`
+	CommencementDate    string    `json:"commencementDate"`
+	CompletionDate      string    `json:"completionDate"`
+	SpecialConditions   string    `json:"specialConditions"`
+	TotalPrice          string    `json:"totalPrice"`
+	PriceIncludesGst    bool      `json:"priceIncludesGst"`
+	PaymentTerms        string    `json:"paymentTerms"`
+	CoverHTML           string    `json:"coverHtml"`
+	TermsHTML           string    `json:"termsHtml"`
+	ScheduleHTML        string    `json:"scheduleHtml"`
+}
+
+// ContentHash returns the hex SHA-256 of the contract content a signatory
+// agrees to
+func ContentHash(c query.Contract) string {
+	day := func(t time.Time, ok bool) string {
+		if !ok {
+			return ""
+		}
+		return t.UTC().Format(time.DateOnly)
+	}
+	return digest(signedContent{
+		ContractID:          c.ID,
+		AgencyID:            c.AgencyID,
+		ContractNumber:      c.ContractNumber,
+		Version:             c.Version,
+		ClientBusinessName:  c.ClientBusinessName,
+		ClientContactName:   c.ClientContactName,
+		ClientEmail:         c.ClientEmail,
+		ClientPhone:         c.ClientPhone,
+		ClientAddress:       c.ClientAddress,
+		ServicesDescription: c.ServicesDescription,
+		CommencementDate:    day(c.CommencementDate.Time, c.CommencementDate.Valid),
+		CompletionDate:      day(c.CompletionDate.Time, c.CompletionDate.Valid),
+		SpecialConditions:   c.SpecialConditions,
+		TotalPrice:          c.TotalPrice.String(),
+		PriceIncludesGst:    c.PriceIncludesGst,
+		PaymentTerms:        c.PaymentTerms,
+		CoverHTML:           c.GeneratedCoverHtml.String,
+		TermsHTML:           c.GeneratedTermsHtml.String,
+		ScheduleHTML:        c.GeneratedScheduleHtml.String,
+	})
+}
+
+// signatureRecord is the hashed form of a signature. It binds the signatory
+// to the content hash and to the previous signature in the chain.
+type signatureRecord struct {
+	ContractID     uuid.UUID `json:"contractId"`
+	Party          string    `json:"party"`
+	SignatoryName  string    `json:"signatoryName"`
+	SignatoryTitle string    `json:"signatoryTitle"`
+	SignedAt       string    `json:"signedAt"`
+	IPAddress      string    `json:"ipAddress"`
+	UserAgent      string    `json:"userAgent"`
+	ContentHash    string    `json:"contentHash"`
+	PreviousHash   string    `json:"previousHash"`
+}
+
+// SignatureHash returns the hex SHA-256 of a signing record
+func SignatureHash(s query.ContractSignature) string {
+	return digest(signatureRecord{
+		ContractID:     s.ContractID,
+		Party:          s.Party,
+		SignatoryName:  s.SignatoryName,
+		SignatoryTitle: s.SignatoryTitle,
+		SignedAt:       s.SignedAt.UTC().Format(time.RFC3339Nano),
+		IPAddress:      s.IpAddress,
+		UserAgent:      s.UserAgent,
+		ContentHash:    s.ContentHash,
+		PreviousHash:   s.PreviousHash,
+	})
+}
+
+func digest(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		// Only plain strings and numbers are marshalled
+		panic(err)
+	}
+	sum := sha256.Sum256(append([]byte(hashVersion), b...))
+	return hex.EncodeToString(sum[:])
+}
+
+// verify checks a contract's content and signature chain against the
+// stored hashes
+func verify(c query.Contract, signatures []query.ContractSignature, now time.Time) *Verification {
+	v := &Verification{
+		Signed:      c.ContentHash.Valid,
+		ContentHash: ContentHash(c),
+		StoredHash:  c.ContentHash.String,
+		Signatures:  []SignatureCheck{},
+		CheckedAt:   now,
+	}
+	v.Valid = !v.Signed || v.ContentHash == v.StoredHash
+
+	previous := ""
+	for _, s := range signatures {
+		check := SignatureCheck{
+			Party:          Party(s.Party),
+			SignatoryName:  s.SignatoryName,
+			SignedAt:       s.SignedAt,
+			ContentMatches: s.ContentHash == v.ContentHash,
+			ChainIntact:    s.PreviousHash == previous,
+			HashValid:      SignatureHash(s) == s.SignatureHash,
+		}
+		if !check.ContentMatches || !check.ChainIntact || !check.HashValid {
+			v.Valid = false
+		}
+		v.Signatures = append(v.Signatures, check)
+		previous = s.SignatureHash
+	}
+
+	// A signature column without its record means the record was removed
+	signedParties := 0
+	if c.AgencySignedAt.Valid {
+		signedParties++
+	}
+	if c.ClientSignedAt.Valid {
+		signedParties++
+	}
+	if signedParties != len(signatures) {
+		v.Valid = false
+	}
+	return v
+}
@@ -0,0 +1,95 @@
+package contract_test
+
+import (
+	"app/pkg/money"
+	"database/sql"
+	"service-core/domain/contract"
+	"service-core/storage/query"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testContract() query.Contract {
+	return query.Contract{
+		ID:                  uuid.MustParse("0195f3a2-7c4e-7000-8000-000000000001"),
+		AgencyID:            uuid.MustParse("0195f3a2-7c4e-7000-8000-000000000002"),
+		ContractNumber:      "CON-2026-0001",
+		Version:             1,
+		Status:              "sent",
+		ClientBusinessName:  "Smith & Co",
+		ClientEmail:         "jo@smith.example",
+		ServicesDescription: "Website build",
+		CommencementDate:    sql.NullTime{Time: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		TotalPrice:          money.MustParse("4950.00", money.AUD),
+		PriceIncludesGst:    true,
+		GeneratedTermsHtml:  sql.NullString{String: "<p>Terms</p>", Valid: true},
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	t.Parallel()
+	base := contract.ContentHash(testContract())
+	if len(base) != 64 {
+		t.Fatalf("ContentHash length = %d, want 64", len(base))
+	}
+
+	// Status, tracking and signature columns may change after signing
+	c := testContract()
+	c.Status = "signed"
+	c.ViewCount = 3
+	c.ClientSignedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	c.ContentHash = sql.NullString{String: base, Valid: true}
+	if got := contract.ContentHash(c); got != base {
+		t.Errorf("ContentHash changed with status and signature columns")
+	}
+
+	edits := map[string]func(c *query.Contract){
+		"price":   func(c *query.Contract) { c.TotalPrice = money.MustParse("4950.01", money.AUD) },
+		"terms":   func(c *query.Contract) { c.GeneratedTermsHtml.String = "<p>Terms.</p>" },
+		"client":  func(c *query.Contract) { c.ClientBusinessName = "Smith and Co" },
+		"date":    func(c *query.Contract) { c.CommencementDate.Time = c.CommencementDate.Time.AddDate(0, 0, 1) },
+		"version": func(c *query.Contract) { c.Version++ },
+	}
+	for name, edit := range edits {
+		c := testContract()
+		edit(&c)
+		if got := contract.ContentHash(c); got == base {
+			t.Errorf("ContentHash unchanged after editing %s", name)
+		}
+	}
+}
+
+func TestSignatureHash(t *testing.T) {
+	t.Parallel()
+	signedAt := time.Date(2026, 4, 2, 3, 4, 5, 123456000, time.UTC)
+	s := query.ContractSignature{
+		ContractID:    uuid.MustParse("0195f3a2-7c4e-7000-8000-000000000001"),
+		Party:         "client",
+		SignatoryName: "Jo Smith",
+		SignedAt:      signedAt,
+		IpAddress:     "203.0.113.7",
+		UserAgent:     "Mozilla/5.0",
+		ContentHash:   contract.ContentHash(testContract()),
+	}
+	base := contract.SignatureHash(s)
+
+	// The same instant in another zone hashes identically
+	local := s
+	local.SignedAt = signedAt.In(time.FixedZone("AEST", 10*60*60))
+	if got := contract.SignatureHash(local); got != base {
+		t.Errorf("SignatureHash depends on the time zone of SignedAt")
+	}
+
+	chained := s
+	chained.PreviousHash = base
+	if got := contract.SignatureHash(chained); got == base {
+		t.Errorf("SignatureHash unchanged with a previous hash")
+	}
+	renamed := s
+	renamed.SignatoryName = "J Smith"
+	if got := contract.SignatureHash(renamed); got == base {
+		t.Errorf("SignatureHash unchanged after editing the signatory")
+	}
+}
@@ -0,0 +1,184 @@
+package contract
+
+import (
+	"database/sql"
+	"html"
+	"regexp"
+	"service-core/domain/proposal"
+	"service-core/storage/query"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MergeData holds merge field values by source and field name. Templates
+// reference them as {{source.field}}, e.g. {{agency.business_name}}, using
+// the same names as the SvelteKit data pipeline.
+type MergeData map[string]map[string]string
+
+var mergeField = regexp.MustCompile(`\{\{(\w+)\.([a-zA-Z0-9_.]+)\}\}`)
+
+// Resolve replaces merge fields in an HTML template. Values are HTML
+// escaped; unknown fields are left in place so they show up in review.
+func Resolve(template string, data MergeData) string {
+	return mergeField.ReplaceAllStringFunc(template, func(match string) string {
+		parts := mergeField.FindStringSubmatch(match)
+		value, ok := data[parts[1]][parts[2]]
+		if !ok {
+			return match
+		}
+		return html.EscapeString(value)
+	})
+}
+
+const dateLayout = "2 January 2006"
+
+func formatDate(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.Format(dateLayout)
+}
+
+func agencyFields(agency query.Agency, profile query.AgencyProfile) map[string]string {
+	trading := profile.TradingName
+	if trading == "" {
+		trading = agency.Name
+	}
+	legal := profile.LegalEntityName
+	if legal == "" {
+		legal = agency.Name
+	}
+	return map[string]string{
+		"business_name":       agency.Name,
+		"trading_name":        trading,
+		"legal_entity_name":   legal,
+		"abn":                 profile.Abn,
+		"acn":                 profile.Acn,
+		"email":               agency.Email,
+		"phone":               agency.Phone,
+		"website":             agency.Website,
+		"logo_url":            agency.LogoUrl,
+		"address_line1":       profile.AddressLine1,
+		"address_line2":       profile.AddressLine2,
+		"city":                profile.City,
+		"state":               profile.State,
+		"postcode":            profile.Postcode,
+		"country":             profile.Country,
+		"full_address":        fullAddress(profile),
+		"bank_name":           profile.BankName,
+		"bank_bsb":            profile.Bsb,
+		"bank_account_number": profile.AccountNumber,
+		"bank_account_name":   profile.AccountName,
+		"gst_registered":      yesNo(profile.GstRegistered),
+		"gst_rate":            profile.GstRate.String(),
+		"primary_color":       agency.PrimaryColor,
+		"secondary_color":     agency.SecondaryColor,
+		"accent_color":        agency.AccentColor,
+		"tagline":             profile.Tagline,
+	}
+}
+
+func fullAddress(profile query.AgencyProfile) string {
+	var parts []string
+	for _, p := range []string{
+		profile.AddressLine1,
+		profile.AddressLine2,
+		profile.City,
+		profile.State,
+		profile.Postcode,
+		profile.Country,
+	} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func clientFields(c query.Contract, p *query.Proposal) map[string]string {
+	fields := map[string]string{
+		"business_name":  c.ClientBusinessName,
+		"contact_person": c.ClientContactName,
+		"email":          c.ClientEmail,
+		"phone":          c.ClientPhone,
+		"address":        c.ClientAddress,
+	}
+	if p != nil {
+		fields["website"] = p.ClientWebsite
+	}
+	return fields
+}
+
+func proposalFields(p *query.Proposal, pricing *proposal.PricingBreakdown) map[string]string {
+	if p == nil {
+		return nil
+	}
+	fields := map[string]string{
+		"number":      p.ProposalNumber,
+		"title":       p.Title,
+		"date":        p.CreatedAt.Format(dateLayout),
+		"valid_until": formatDate(p.ValidUntil),
+	}
+	if pricing != nil {
+		var addons []string
+		for _, line := range pricing.LineItems {
+			if line.AddonID != nil {
+				addons = append(addons, line.Description)
+			}
+		}
+		fields["package_name"] = pricing.PackageName
+		fields["addons"] = strings.Join(addons, ", ")
+		fields["subtotal"] = pricing.OneTime.Net.Format()
+		fields["gst"] = pricing.OneTime.GST.Format()
+		fields["total"] = pricing.OneTime.Total.Format()
+		fields["monthly_total"] = pricing.Monthly.Total.Format()
+		fields["minimum_term"] = minimumTerm(pricing.MinimumTermMonths)
+	}
+	return fields
+}
+
+func contractFields(c query.Contract, minimumTermMonths int32) map[string]string {
+	return map[string]string{
+		"number":                 c.ContractNumber,
+		"date":                   c.CreatedAt.Format(dateLayout),
+		"valid_until":            formatDate(c.ValidUntil),
+		"commencement_date":      formatDate(c.CommencementDate),
+		"completion_date":        formatDate(c.CompletionDate),
+		"start_date":             formatDate(c.CommencementDate),
+		"end_date":               formatDate(c.CompletionDate),
+		"total_price":            c.TotalPrice.Format(),
+		"payment_terms":          c.PaymentTerms,
+		"minimum_term":           minimumTerm(minimumTermMonths),
+		"services_description":   c.ServicesDescription,
+		"special_conditions":     c.SpecialConditions,
+		"agency_signatory_name":  c.AgencySignatoryName.String,
+		"agency_signatory_title": c.AgencySignatoryTitle.String,
+	}
+}
+
+func computedFields(now time.Time, c query.Contract) map[string]string {
+	return map[string]string{
+		"current_date":         now.Format(dateLayout),
+		"current_year":         strconv.Itoa(now.Year()),
+		"total_contract_value": c.TotalPrice.Format(),
+	}
+}
+
+func minimumTerm(months int32) string {
+	switch {
+	case months <= 0:
+		return ""
+	case months == 1:
+		return "1 month"
+	default:
+		return strconv.Itoa(int(months)) + " months"
+	}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
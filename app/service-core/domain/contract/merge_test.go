@@ -0,0 +1,31 @@
+package contract_test
+
+import (
+	"service-core/domain/contract"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+	data := contract.MergeData{
+		"agency": {"business_name": "Acme Digital", "abn": "12 345 678 901"},
+		"client": {"business_name": "Smith & Co <Pty>"},
+	}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"<p>{{agency.business_name}}</p>", "<p>Acme Digital</p>"},
+		{"ABN {{agency.abn}}", "ABN 12 345 678 901"},
+		{"{{client.business_name}}", "Smith &amp; Co &lt;Pty&gt;"},
+		{"{{agency.unknown}}", "{{agency.unknown}}"},
+		{"{{proposal.total}}", "{{proposal.total}}"},
+		{"{{ agency.business_name }}", "{{ agency.business_name }}"},
+		{"{{agency.business_name}} for {{client.business_name}}", "Acme Digital for Smith &amp; Co &lt;Pty&gt;"},
+	}
+	for _, tt := range tests {
+		if got := contract.Resolve(tt.template, data); got != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
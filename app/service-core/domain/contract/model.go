@@ -0,0 +1,173 @@
+package contract
+
+import (
+	"app/pkg/money"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is the lifecycle state of a contract
+type Status string
+
+const (
+	StatusDraft      Status = "draft"
+	StatusSent       Status = "sent"
+	StatusViewed     Status = "viewed"
+	StatusSigned     Status = "signed"
+	StatusCompleted  Status = "completed"
+	StatusExpired    Status = "expired"
+	StatusTerminated Status = "terminated"
+)
+
+// transitions lists the statuses a contract may be moved to explicitly.
+// Viewed is only reached by recording a view and signed only by the client
+// signing.
+var transitions = map[Status][]Status{
+	StatusDraft:  {StatusSent, StatusTerminated},
+	StatusSent:   {StatusExpired, StatusTerminated},
+	StatusViewed: {StatusExpired, StatusTerminated},
+	StatusSigned: {StatusCompleted, StatusTerminated},
+}
+
+// CanTransition reports whether a contract may be moved from one status to
+// another
+func CanTransition(from, to Status) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEditable reports whether a contract in this status may still be changed.
+// Content is frozen once the contract is sent, so the client signs what they
+// were shown, and once either party has signed.
+func (s Status) IsEditable() bool {
+	return s == StatusDraft
+}
+
+// AgencyCanSign reports whether the agency may sign a contract in this
+// status: before sending it, or while or after the client signs
+func (s Status) AgencyCanSign() bool {
+	return s == StatusDraft || s == StatusSent || s == StatusViewed || s == StatusSigned
+}
+
+// Party identifies who signed a contract
+type Party string
+
+const (
+	PartyAgency Party = "agency"
+	PartyClient Party = "client"
+)
+
+// defaultValidity is how long a new contract can be signed for
+const defaultValidity = 30 * 24 * time.Hour
+
+// CoverPageConfig controls the generated cover page. It mirrors the
+// cover_page_config JSON written by the contract template editor.
+type CoverPageConfig struct {
+	ShowLogo          bool          `json:"showLogo"`
+	ShowAgencyAddress bool          `json:"showAgencyAddress"`
+	ShowClientAddress bool          `json:"showClientAddress"`
+	CustomFields      []CustomField `json:"customFields"`
+}
+
+// CustomField is an extra cover page row filled from a merge field
+type CustomField struct {
+	Label      string `json:"label"`
+	MergeField string `json:"mergeField"`
+}
+
+// SignatureConfig is the signing setup from a contract template
+type SignatureConfig struct {
+	AgencySignatory    string `json:"agencySignatory"`
+	AgencyTitle        string `json:"agencyTitle"`
+	RequireClientTitle bool   `json:"requireClientTitle"`
+}
+
+// CreateRequest generates a contract from an accepted proposal. The agency's
+// default template is used when no template is given, and schedules are
+// matched to the proposal's package unless ScheduleIDs lists them.
+type CreateRequest struct {
+	ProposalID          uuid.UUID   `json:"proposalId"`
+	TemplateID          *uuid.UUID  `json:"templateId"`
+	ScheduleIDs         []uuid.UUID `json:"scheduleIds"`
+	ClientAddress       string      `json:"clientAddress"`
+	ServicesDescription string      `json:"servicesDescription"`
+	CommencementDate    *time.Time  `json:"commencementDate"`
+	CompletionDate      *time.Time  `json:"completionDate"`
+	SpecialConditions   string      `json:"specialConditions"`
+	ValidUntil          *time.Time  `json:"validUntil"`
+}
+
+// UpdateRequest edits an unsigned contract. Nil fields are left unchanged.
+// The cover, terms and schedules are re-rendered after every update.
+type UpdateRequest struct {
+	ClientBusinessName   *string      `json:"clientBusinessName"`
+	ClientContactName    *string      `json:"clientContactName"`
+	ClientEmail          *string      `json:"clientEmail"`
+	ClientPhone          *string      `json:"clientPhone"`
+	ClientAddress        *string      `json:"clientAddress"`
+	ServicesDescription  *string      `json:"servicesDescription"`
+	CommencementDate     *time.Time   `json:"commencementDate"`
+	CompletionDate       *time.Time   `json:"completionDate"`
+	SpecialConditions    *string      `json:"specialConditions"`
+	TotalPrice           *money.Money `json:"totalPrice"`
+	PaymentTerms         *string      `json:"paymentTerms"`
+	ValidUntil           *time.Time   `json:"validUntil"`
+	AgencySignatoryName  *string      `json:"agencySignatoryName"`
+	AgencySignatoryTitle *string      `json:"agencySignatoryTitle"`
+	ScheduleIDs          *[]uuid.UUID `json:"scheduleIds"`
+}
+
+// TransitionRequest moves a contract to a new status
+type TransitionRequest struct {
+	Status Status `json:"status"`
+}
+
+// SignRequest is a signatory's name and title
+type SignRequest struct {
+	SignatoryName  string `json:"signatoryName"`
+	SignatoryTitle string `json:"signatoryTitle"`
+}
+
+// Origin is where a signature came from, taken from the signing request
+type Origin struct {
+	IPAddress string
+	UserAgent string
+}
+
+// Detail is a contract with its signing records
+type Detail struct {
+	Contract   query.Contract            `json:"contract"`
+	Signatures []query.ContractSignature `json:"signatures"`
+}
+
+// ListResponse is a page of contracts for an agency
+type ListResponse struct {
+	Count     int64            `json:"count"`
+	Contracts []query.Contract `json:"contracts"`
+}
+
+// Verification compares a contract and its signatures with their hashes
+type Verification struct {
+	Valid       bool             `json:"valid"`
+	Signed      bool             `json:"signed"`
+	ContentHash string           `json:"contentHash"`
+	StoredHash  string           `json:"storedHash"`
+	Signatures  []SignatureCheck `json:"signatures"`
+	CheckedAt   time.Time        `json:"checkedAt"`
+}
+
+// SignatureCheck is the verification result for one signature
+type SignatureCheck struct {
+	Party          Party     `json:"party"`
+	SignatoryName  string    `json:"signatoryName"`
+	SignedAt       time.Time `json:"signedAt"`
+	ContentMatches bool      `json:"contentMatches"`
+	ChainIntact    bool      `json:"chainIntact"`
+	HashValid      bool      `json:"hashValid"`
+}
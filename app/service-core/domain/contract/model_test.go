@@ -0,0 +1,33 @@
+package contract_test
+
+import (
+	"service-core/domain/contract"
+	"testing"
+)
+
+func TestStatusIsEditable(t *testing.T) {
+	t.Parallel()
+	if !contract.StatusDraft.IsEditable() {
+		t.Errorf("expected %s to be editable", contract.StatusDraft)
+	}
+	// Sent content is what the client signs, so it is frozen
+	for _, s := range []contract.Status{contract.StatusSent, contract.StatusViewed, contract.StatusSigned, contract.StatusCompleted} {
+		if s.IsEditable() {
+			t.Errorf("expected %s not to be editable", s)
+		}
+	}
+}
+
+func TestStatusAgencyCanSign(t *testing.T) {
+	t.Parallel()
+	for _, s := range []contract.Status{contract.StatusDraft, contract.StatusSent, contract.StatusViewed, contract.StatusSigned} {
+		if !s.AgencyCanSign() {
+			t.Errorf("expected the agency to be able to sign in %s", s)
+		}
+	}
+	for _, s := range []contract.Status{contract.StatusCompleted, contract.StatusExpired, contract.StatusTerminated} {
+		if s.AgencyCanSign() {
+			t.Errorf("expected the agency not to be able to sign in %s", s)
+		}
+	}
+}
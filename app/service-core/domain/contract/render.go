@@ -0,0 +1,121 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"service-core/storage/query"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// matchSchedules picks the schedules to include in a contract. Explicitly
+// listed IDs win. Otherwise each section category contributes one schedule:
+// the one written for the selected package, or the general one (no package)
+// when the package has none. Schedules for other packages are skipped.
+func matchSchedules(schedules []query.ContractSchedule, packageID uuid.NullUUID, ids []uuid.UUID) []query.ContractSchedule {
+	if len(ids) > 0 {
+		wanted := make(map[uuid.UUID]bool, len(ids))
+		for _, id := range ids {
+			wanted[id] = true
+		}
+		var matched []query.ContractSchedule
+		for _, s := range schedules {
+			if wanted[s.ID] {
+				matched = append(matched, s)
+			}
+		}
+		return matched
+	}
+
+	specific := make(map[string]bool)
+	for _, s := range schedules {
+		if packageID.Valid && s.PackageID.Valid && s.PackageID.UUID == packageID.UUID {
+			specific[s.SectionCategory] = true
+		}
+	}
+	var matched []query.ContractSchedule
+	for _, s := range schedules {
+		switch {
+		case s.PackageID.Valid && packageID.Valid && s.PackageID.UUID == packageID.UUID:
+			matched = append(matched, s)
+		case !s.PackageID.Valid && !specific[s.SectionCategory]:
+			matched = append(matched, s)
+		}
+	}
+	return matched
+}
+
+func scheduleIDs(schedules []query.ContractSchedule) json.RawMessage {
+	ids := make([]uuid.UUID, 0, len(schedules))
+	for _, s := range schedules {
+		ids = append(ids, s.ID)
+	}
+	b, _ := json.Marshal(ids)
+	return b
+}
+
+func parseScheduleIDs(raw json.RawMessage) []uuid.UUID {
+	var ids []uuid.UUID
+	_ = json.Unmarshal(raw, &ids)
+	return ids
+}
+
+// renderSchedules renders each schedule as a numbered section
+func renderSchedules(schedules []query.ContractSchedule, data MergeData) string {
+	var b strings.Builder
+	for i, s := range schedules {
+		fmt.Fprintf(&b, "<section class=\"contract-schedule\" data-category=\"%s\">\n<h2>Schedule %d: %s</h2>\n%s\n</section>\n",
+			html.EscapeString(s.SectionCategory), i+1, html.EscapeString(s.Name), Resolve(s.Content, data))
+	}
+	return b.String()
+}
+
+// renderCover renders the cover page from the template's cover settings
+func renderCover(cfg CoverPageConfig, c query.Contract, data MergeData) string {
+	agency := data["agency"]
+	esc := html.EscapeString
+
+	var b strings.Builder
+	b.WriteString("<div class=\"contract-cover\">\n")
+	if cfg.ShowLogo && agency["logo_url"] != "" {
+		fmt.Fprintf(&b, "<img class=\"contract-logo\" src=\"%s\" alt=\"%s\">\n", esc(agency["logo_url"]), esc(agency["business_name"]))
+	}
+	b.WriteString("<h1>Services Agreement</h1>\n")
+	fmt.Fprintf(&b, "<p class=\"contract-number\">%s</p>\n", esc(c.ContractNumber))
+
+	b.WriteString("<div class=\"contract-parties\">\n")
+	fmt.Fprintf(&b, "<div class=\"contract-party\"><h3>Provider</h3><p>%s</p>", esc(agency["legal_entity_name"]))
+	if agency["abn"] != "" {
+		fmt.Fprintf(&b, "<p>ABN %s</p>", esc(agency["abn"]))
+	}
+	if cfg.ShowAgencyAddress && agency["full_address"] != "" {
+		fmt.Fprintf(&b, "<p>%s</p>", esc(agency["full_address"]))
+	}
+	b.WriteString("</div>\n")
+	fmt.Fprintf(&b, "<div class=\"contract-party\"><h3>Client</h3><p>%s</p>", esc(c.ClientBusinessName))
+	if c.ClientContactName != "" {
+		fmt.Fprintf(&b, "<p>Attention: %s</p>", esc(c.ClientContactName))
+	}
+	if cfg.ShowClientAddress && c.ClientAddress != "" {
+		fmt.Fprintf(&b, "<p>%s</p>", esc(c.ClientAddress))
+	}
+	b.WriteString("</div>\n</div>\n")
+
+	if len(cfg.CustomFields) > 0 {
+		b.WriteString("<dl class=\"contract-fields\">\n")
+		for _, f := range cfg.CustomFields {
+			fmt.Fprintf(&b, "<dt>%s</dt><dd>%s</dd>\n", esc(f.Label), Resolve(f.MergeField, data))
+		}
+		b.WriteString("</dl>\n")
+	}
+	b.WriteString("</div>\n")
+	return b.String()
+}
+
+func decodeConfig[T any](raw json.RawMessage) T {
+	var v T
+	_ = json.Unmarshal(raw, &v)
+	return v
+}
@@ -0,0 +1,692 @@
+package contract
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"service-core/config"
+	"service-core/domain/activity"
+	"service-core/domain/numbering"
+	"service-core/domain/proposal"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// store defines the database interface for contract operations
+type store interface {
+	CountContracts(ctx context.Context, arg query.CountContractsParams) (int64, error)
+	SelectContracts(ctx context.Context, arg query.SelectContractsParams) ([]query.Contract, error)
+	SelectContract(ctx context.Context, id uuid.UUID) (query.Contract, error)
+	SelectContractBySlug(ctx context.Context, slug string) (query.Contract, error)
+	InsertContract(ctx context.Context, arg query.InsertContractParams) (query.Contract, error)
+	UpdateContract(ctx context.Context, arg query.UpdateContractParams) (query.Contract, error)
+	UpdateContractStatus(ctx context.Context, arg query.UpdateContractStatusParams) (query.Contract, error)
+	RecordContractView(ctx context.Context, id uuid.UUID) (query.Contract, error)
+	DeleteContract(ctx context.Context, id uuid.UUID) error
+	SelectContractSignatures(ctx context.Context, contractID uuid.UUID) ([]query.ContractSignature, error)
+
+	SelectContractTemplate(ctx context.Context, id uuid.UUID) (query.ContractTemplate, error)
+	SelectDefaultContractTemplate(ctx context.Context, agencyID uuid.UUID) (query.ContractTemplate, error)
+	SelectContractSchedules(ctx context.Context, templateID uuid.UUID) ([]query.ContractSchedule, error)
+
+	SelectAgency(ctx context.Context, id uuid.UUID) (query.Agency, error)
+	SelectAgencyProfile(ctx context.Context, agencyID uuid.UUID) (query.AgencyProfile, error)
+	InsertActivityLog(ctx context.Context, arg query.InsertActivityLogParams) error
+}
+
+// proposalService loads accepted proposals and their pricing
+type proposalService interface {
+	GetProposal(ctx context.Context, agencyID, id uuid.UUID) (*query.Proposal, error)
+	Price(ctx context.Context, p *query.Proposal) (*proposal.PricingBreakdown, error)
+}
+
+// numberer allocates agency document numbers
+type numberer interface {
+	Allocate(ctx context.Context, agencyID uuid.UUID, docType numbering.DocumentType) (string, error)
+}
+
+// Service handles contract generation and signing
+type Service struct {
+	cfg             *config.Config
+	db              *sql.DB
+	store           store
+	proposalService proposalService
+	numberer        numberer
+}
+
+// NewService creates a new contract service. The database handle is used
+// to record signatures and their hash chain in a single transaction.
+func NewService(
+	cfg *config.Config,
+	db *sql.DB,
+	store store,
+	proposalService proposalService,
+	numberer numberer,
+) *Service {
+	return &Service{
+		cfg:             cfg,
+		db:              db,
+		store:           store,
+		proposalService: proposalService,
+		numberer:        numberer,
+	}
+}
+
+// ListContracts returns a page of an agency's contracts, optionally filtered by status
+func (s *Service) ListContracts(
+	ctx context.Context,
+	agencyID uuid.UUID,
+	status string,
+	page int32,
+	limit int32,
+) (*ListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+	count, err := s.store.CountContracts(ctx, query.CountContractsParams{
+		AgencyID: agencyID,
+		Status:   status,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error counting contracts", Err: err}
+	}
+	contracts, err := s.store.SelectContracts(ctx, query.SelectContractsParams{
+		AgencyID:  agencyID,
+		Status:    status,
+		RowLimit:  limit,
+		RowOffset: (page - 1) * limit,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting contracts", Err: err}
+	}
+	if contracts == nil {
+		contracts = []query.Contract{}
+	}
+	return &ListResponse{
+		Count:     count,
+		Contracts: contracts,
+	}, nil
+}
+
+// GetContract returns a contract belonging to the agency with its signatures
+func (s *Service) GetContract(ctx context.Context, agencyID, id uuid.UUID) (*Detail, error) {
+	c, err := s.get(ctx, agencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	signatures, err := s.signatures(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Detail{Contract: *c, Signatures: signatures}, nil
+}
+
+// CreateContract generates a draft contract from an accepted proposal. The
+// cover page, terms and schedules are rendered from the template with the
+// agency, client and proposal merge fields.
+func (s *Service) CreateContract(
+	ctx context.Context,
+	user auth.UserAttr,
+	req CreateRequest,
+) (*Detail, error) {
+	if err := auth.Authorize(auth.CreateContract, user, auth.Resource{OwnerID: user.ID, AgencyID: user.AgencyID}); err != nil {
+		return nil, err
+	}
+	p, err := s.proposalService.GetProposal(ctx, user.AgencyID, req.ProposalID)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status(p.Status) != proposal.StatusAccepted {
+		return nil, pkg.BadRequestError{
+			Message: "Contracts can only be generated from accepted proposals",
+			Err:     fmt.Errorf("proposal %s has status %s", p.ID, p.Status),
+		}
+	}
+	pricing, err := s.proposalService.Price(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := s.template(ctx, user.AgencyID, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+
+	c := newContract(p, pricing, tmpl, user.ID, req, time.Now())
+	if err := validate(c); err != nil {
+		return nil, err
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating contract ID", Err: err}
+	}
+	c.ID = id
+	c.ContractNumber, err = s.numberer.Allocate(ctx, user.AgencyID, numbering.Contract)
+	if err != nil {
+		return nil, err
+	}
+	c.Slug, err = newSlug()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating contract slug", Err: err}
+	}
+	if err := s.render(ctx, &c, tmpl, p, pricing, req.ScheduleIDs); err != nil {
+		return nil, err
+	}
+
+	inserted, err := s.store.InsertContract(ctx, insertParams(c))
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error inserting contract", Err: err}
+	}
+	s.logActivity(ctx, &inserted, user.ID, "contract.created", nil, map[string]any{
+		"contractNumber": inserted.ContractNumber,
+		"totalPrice":     inserted.TotalPrice,
+	}, map[string]any{"proposalId": p.ID, "proposalNumber": p.ProposalNumber})
+	return &Detail{Contract: inserted, Signatures: []query.ContractSignature{}}, nil
+}
+
+// UpdateContract edits an unsigned contract and re-renders its content
+func (s *Service) UpdateContract(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	req UpdateRequest,
+) (*Detail, error) {
+	existing, err := s.get(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(auth.EditContract, user, resource(existing)); err != nil {
+		return nil, err
+	}
+	if !Status(existing.Status).IsEditable() || existing.ContentHash.Valid {
+		return nil, pkg.BadRequestError{
+			Message: "Only unsigned draft contracts can be edited",
+			Err:     fmt.Errorf("contract %s has status %s", existing.ID, existing.Status),
+		}
+	}
+
+	c := *existing
+	applyUpdate(&c, req)
+	if err := validate(c); err != nil {
+		return nil, err
+	}
+
+	var tmpl *query.ContractTemplate
+	if c.TemplateID.Valid {
+		t, err := s.store.SelectContractTemplate(ctx, c.TemplateID.UUID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.InternalError{Message: "Error selecting contract template", Err: err}
+		}
+		if err == nil {
+			tmpl = &t
+		}
+	}
+	p, err := s.proposalService.GetProposal(ctx, user.AgencyID, c.ProposalID)
+	if err != nil {
+		return nil, err
+	}
+	pricing, err := s.proposalService.Price(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	ids := parseScheduleIDs(c.IncludedScheduleIds)
+	if req.ScheduleIDs != nil {
+		ids = *req.ScheduleIDs
+	}
+	if err := s.render(ctx, &c, tmpl, p, pricing, ids); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.store.UpdateContract(ctx, updateParams(c))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.ConflictError{Message: "Contract was changed, please reload and try again", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error updating contract", Err: err}
+	}
+	s.logActivity(ctx, &updated, user.ID, "contract.updated", map[string]any{"version": existing.Version}, req, nil)
+	return &Detail{Contract: updated, Signatures: []query.ContractSignature{}}, nil
+}
+
+// TransitionContract moves a contract to a new status. The update only
+// applies if the status has not changed since it was read.
+func (s *Service) TransitionContract(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	req TransitionRequest,
+) (*query.Contract, error) {
+	existing, err := s.get(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(auth.EditContract, user, resource(existing)); err != nil {
+		return nil, err
+	}
+	from := Status(existing.Status)
+	if !CanTransition(from, req.Status) {
+		message := fmt.Sprintf("Cannot change contract status from %s to %s", from, req.Status)
+		if req.Status == StatusSigned {
+			message = "Contracts are marked as signed when the client signs"
+		}
+		return nil, pkg.BadRequestError{Message: message, Err: errors.New("invalid status transition")}
+	}
+
+	params := query.UpdateContractStatusParams{
+		ID:         existing.ID,
+		Status:     string(req.Status),
+		FromStatus: existing.Status,
+	}
+	if req.Status == StatusSent {
+		params.SentAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	c, err := s.store.UpdateContractStatus(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.BadRequestError{Message: "Contract status changed, please reload and try again", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error updating contract status", Err: err}
+	}
+	s.logActivity(ctx, &c, user.ID, "contract."+string(req.Status),
+		map[string]any{"status": from},
+		map[string]any{"status": req.Status},
+		nil,
+	)
+	return &c, nil
+}
+
+// SignAsAgency records the agency's signature. The agency may countersign
+// before sending or after the client has signed, but only once.
+func (s *Service) SignAsAgency(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	req SignRequest,
+	origin Origin,
+) (*Detail, error) {
+	if err := validateSignature(req, false); err != nil {
+		return nil, err
+	}
+	existing, err := s.get(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(auth.EditContract, user, resource(existing)); err != nil {
+		return nil, err
+	}
+	status := Status(existing.Status)
+	if existing.AgencySignedAt.Valid || !status.AgencyCanSign() {
+		return nil, pkg.BadRequestError{
+			Message: "This contract cannot be signed by the agency",
+			Err:     fmt.Errorf("contract %s has status %s", existing.ID, existing.Status),
+		}
+	}
+	d, err := s.sign(ctx, existing, PartyAgency, user.ID, req, origin)
+	if err != nil {
+		return nil, err
+	}
+	s.logActivity(ctx, &d.Contract, user.ID, "contract.agency_signed", nil, map[string]any{
+		"signatoryName":  req.SignatoryName,
+		"signatoryTitle": req.SignatoryTitle,
+	}, map[string]any{"ipAddress": origin.IPAddress, "contentHash": d.Contract.ContentHash.String})
+	return d, nil
+}
+
+// SignAsClient records the client's signature from the public contract
+// page. It requires no authentication; the slug grants access.
+func (s *Service) SignAsClient(ctx context.Context, slug string, req SignRequest, origin Origin) (*Detail, error) {
+	existing, err := s.public(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	var requireTitle bool
+	if existing.TemplateID.Valid {
+		tmpl, err := s.store.SelectContractTemplate(ctx, existing.TemplateID.UUID)
+		if err == nil {
+			requireTitle = decodeConfig[SignatureConfig](tmpl.SignatureConfig).RequireClientTitle
+		}
+	}
+	if err := validateSignature(req, requireTitle); err != nil {
+		return nil, err
+	}
+
+	status := Status(existing.Status)
+	if existing.ClientSignedAt.Valid || (status != StatusSent && status != StatusViewed) {
+		return nil, pkg.BadRequestError{
+			Message: "This contract is no longer available for signing",
+			Err:     fmt.Errorf("contract %s has status %s", existing.ID, existing.Status),
+		}
+	}
+	if existing.ValidUntil.Valid && time.Now().After(existing.ValidUntil.Time) {
+		return nil, pkg.BadRequestError{
+			Message: "This contract has expired",
+			Err:     fmt.Errorf("contract %s expired at %s", existing.ID, existing.ValidUntil.Time),
+		}
+	}
+	d, err := s.sign(ctx, existing, PartyClient, uuid.Nil, req, origin)
+	if err != nil {
+		return nil, err
+	}
+	s.logActivity(ctx, &d.Contract, uuid.Nil, "contract.signed",
+		map[string]any{"status": existing.Status},
+		map[string]any{"status": d.Contract.Status, "signatoryName": req.SignatoryName},
+		map[string]any{"ipAddress": origin.IPAddress, "contentHash": d.Contract.ContentHash.String},
+	)
+	return d, nil
+}
+
+// RecordView counts a view of a contract's public page and moves a sent
+// contract to viewed. Drafts are not visible. It requires no authentication.
+func (s *Service) RecordView(ctx context.Context, slug string) (*query.Contract, error) {
+	existing, err := s.public(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.store.RecordContractView(ctx, existing.ID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error recording contract view", Err: err}
+	}
+	if existing.Status != c.Status {
+		s.logActivity(ctx, &c, uuid.Nil, "contract.viewed",
+			map[string]any{"status": existing.Status},
+			map[string]any{"status": c.Status},
+			nil,
+		)
+	}
+	return &c, nil
+}
+
+// VerifyContract recomputes a contract's content hash and signature chain
+// and compares them with the stored values
+func (s *Service) VerifyContract(ctx context.Context, agencyID, id uuid.UUID) (*Verification, error) {
+	c, err := s.get(ctx, agencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	signatures, err := s.signatures(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	return verify(*c, signatures, time.Now()), nil
+}
+
+// DeleteContract removes an unsigned draft contract
+func (s *Service) DeleteContract(ctx context.Context, user auth.UserAttr, id uuid.UUID) error {
+	existing, err := s.get(ctx, user.AgencyID, id)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(auth.RemoveContract, user, resource(existing)); err != nil {
+		return err
+	}
+	if Status(existing.Status) != StatusDraft || existing.ContentHash.Valid {
+		return pkg.BadRequestError{
+			Message: "Only unsigned draft contracts can be deleted, terminate the contract instead",
+			Err:     errors.New("contract is not an unsigned draft"),
+		}
+	}
+	if err := s.store.DeleteContract(ctx, id); err != nil {
+		return pkg.InternalError{Message: "Error deleting contract", Err: err}
+	}
+	s.logActivity(ctx, existing, user.ID, "contract.deleted", map[string]any{
+		"contractNumber": existing.ContractNumber,
+		"totalPrice":     existing.TotalPrice,
+	}, nil, nil)
+	return nil
+}
+
+// sign records a party's signature. The signature columns, content hash
+// and the chained signature record are written in one transaction; the
+// guarded update fails if the party has already signed, the contract has
+// changed since it was read, or the content no longer matches the hash of
+// an earlier signature.
+func (s *Service) sign(
+	ctx context.Context,
+	c *query.Contract,
+	party Party,
+	userID uuid.UUID,
+	req SignRequest,
+	origin Origin,
+) (*Detail, error) {
+	hash := ContentHash(*c)
+	if c.ContentHash.Valid && c.ContentHash.String != hash {
+		return nil, pkg.InternalError{
+			Message: "Contract content does not match its signed hash",
+			Err:     fmt.Errorf("contract %s content hash mismatch", c.ID),
+		}
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating signature ID", Err: err}
+	}
+	signedAt := time.Now().UTC().Truncate(time.Microsecond)
+	ip := truncate(origin.IPAddress, 50)
+	userAgent := truncate(origin.UserAgent, 500)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error starting contract signing", Err: err}
+	}
+	defer tx.Rollback()
+	q := query.New(tx)
+
+	var signed query.Contract
+	switch party {
+	case PartyAgency:
+		signed, err = q.SignContractAsAgency(ctx, query.SignContractAsAgencyParams{
+			ID:             c.ID,
+			SignatoryName:  nullString(req.SignatoryName),
+			SignatoryTitle: nullString(req.SignatoryTitle),
+			SignedAt:       sql.NullTime{Time: signedAt, Valid: true},
+			IpAddress:      nullString(ip),
+			UserAgent:      nullString(userAgent),
+			ContentHash:    nullString(hash),
+			Version:        c.Version,
+		})
+	case PartyClient:
+		signed, err = q.SignContractAsClient(ctx, query.SignContractAsClientParams{
+			ID:             c.ID,
+			SignatoryName:  nullString(req.SignatoryName),
+			SignatoryTitle: nullString(req.SignatoryTitle),
+			SignedAt:       sql.NullTime{Time: signedAt, Valid: true},
+			IpAddress:      nullString(ip),
+			UserAgent:      nullString(userAgent),
+			ContentHash:    nullString(hash),
+			Version:        c.Version,
+		})
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.ConflictError{Message: "Contract changed or was already signed, please reload and try again", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error signing contract", Err: err}
+	}
+
+	signatures, err := q.SelectContractSignatures(ctx, c.ID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting contract signatures", Err: err}
+	}
+	record := query.ContractSignature{
+		ID:             id,
+		ContractID:     c.ID,
+		AgencyID:       c.AgencyID,
+		Party:          string(party),
+		SignatoryName:  req.SignatoryName,
+		SignatoryTitle: req.SignatoryTitle,
+		SignedAt:       signedAt,
+		IpAddress:      ip,
+		UserAgent:      userAgent,
+		UserID:         uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+		ContentHash:    hash,
+	}
+	if n := len(signatures); n > 0 {
+		record.PreviousHash = signatures[n-1].SignatureHash
+	}
+	record.SignatureHash = SignatureHash(record)
+
+	inserted, err := q.InsertContractSignature(ctx, query.InsertContractSignatureParams{
+		ID:             record.ID,
+		ContractID:     record.ContractID,
+		AgencyID:       record.AgencyID,
+		Party:          record.Party,
+		SignatoryName:  record.SignatoryName,
+		SignatoryTitle: record.SignatoryTitle,
+		SignedAt:       record.SignedAt,
+		IpAddress:      record.IpAddress,
+		UserAgent:      record.UserAgent,
+		UserID:         record.UserID,
+		ContentHash:    record.ContentHash,
+		PreviousHash:   record.PreviousHash,
+		SignatureHash:  record.SignatureHash,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error inserting contract signature", Err: err}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, pkg.InternalError{Message: "Error committing contract signature", Err: err}
+	}
+	return &Detail{Contract: signed, Signatures: append(signatures, inserted)}, nil
+}
+
+// render fills the contract's cover, terms and schedule HTML from the
+// template and records which schedules were included
+func (s *Service) render(
+	ctx context.Context,
+	c *query.Contract,
+	tmpl *query.ContractTemplate,
+	p *query.Proposal,
+	pricing *proposal.PricingBreakdown,
+	ids []uuid.UUID,
+) error {
+	agency, err := s.store.SelectAgency(ctx, c.AgencyID)
+	if err != nil {
+		return pkg.InternalError{Message: "Error selecting agency", Err: err}
+	}
+	profile, err := s.store.SelectAgencyProfile(ctx, c.AgencyID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return pkg.InternalError{Message: "Error selecting agency profile", Err: err}
+	}
+
+	data := MergeData{
+		"agency":   agencyFields(agency, profile),
+		"client":   clientFields(*c, p),
+		"proposal": proposalFields(p, pricing),
+		"contract": contractFields(*c, pricing.MinimumTermMonths),
+		"computed": computedFields(time.Now(), *c),
+	}
+
+	var cover CoverPageConfig
+	var schedules []query.ContractSchedule
+	terms := ""
+	if tmpl != nil {
+		cover = decodeConfig[CoverPageConfig](tmpl.CoverPageConfig)
+		terms = Resolve(tmpl.TermsContent, data)
+		all, err := s.store.SelectContractSchedules(ctx, tmpl.ID)
+		if err != nil {
+			return pkg.InternalError{Message: "Error selecting contract schedules", Err: err}
+		}
+		schedules = matchSchedules(all, p.SelectedPackageID, ids)
+	}
+
+	c.GeneratedCoverHtml = nullString(renderCover(cover, *c, data))
+	c.GeneratedTermsHtml = nullString(terms)
+	c.GeneratedScheduleHtml = nullString(renderSchedules(schedules, data))
+	c.IncludedScheduleIds = scheduleIDs(schedules)
+	return nil
+}
+
+// template returns the requested template, or the agency's default when
+// none is given. A contract may be generated without a template.
+func (s *Service) template(ctx context.Context, agencyID uuid.UUID, id *uuid.UUID) (*query.ContractTemplate, error) {
+	if id == nil {
+		tmpl, err := s.store.SelectDefaultContractTemplate(ctx, agencyID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, nil
+			}
+			return nil, pkg.InternalError{Message: "Error selecting default contract template", Err: err}
+		}
+		return &tmpl, nil
+	}
+	tmpl, err := s.store.SelectContractTemplate(ctx, *id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.InternalError{Message: "Error selecting contract template", Err: err}
+	}
+	if err != nil || tmpl.AgencyID != agencyID || !tmpl.IsActive {
+		return nil, pkg.NotFoundError{Message: "Contract template not found", Err: err}
+	}
+	return &tmpl, nil
+}
+
+func (s *Service) get(ctx context.Context, agencyID, id uuid.UUID) (*query.Contract, error) {
+	c, err := s.store.SelectContract(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Contract not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting contract", Err: err}
+	}
+	// Contracts from other agencies are reported as missing rather than forbidden
+	if c.AgencyID != agencyID {
+		return nil, pkg.NotFoundError{Message: "Contract not found", Err: fmt.Errorf("contract %s belongs to another agency", id)}
+	}
+	return &c, nil
+}
+
+// resource returns the attributes a contract is authorized against
+func resource(c *query.Contract) auth.Resource {
+	return auth.Resource{OwnerID: c.CreatedBy.UUID, AgencyID: c.AgencyID}
+}
+
+// public returns a contract by its public slug. Drafts have not been sent
+// and are reported as missing.
+func (s *Service) public(ctx context.Context, slug string) (*query.Contract, error) {
+	c, err := s.store.SelectContractBySlug(ctx, slug)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.InternalError{Message: "Error selecting contract", Err: err}
+	}
+	if err != nil || Status(c.Status) == StatusDraft {
+		return nil, pkg.NotFoundError{Message: "Contract not found", Err: err}
+	}
+	return &c, nil
+}
+
+func (s *Service) signatures(ctx context.Context, contractID uuid.UUID) ([]query.ContractSignature, error) {
+	signatures, err := s.store.SelectContractSignatures(ctx, contractID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting contract signatures", Err: err}
+	}
+	if signatures == nil {
+		signatures = []query.ContractSignature{}
+	}
+	return signatures, nil
+}
+
+// logActivity records a change to a contract in the agency activity log
+func (s *Service) logActivity(
+	ctx context.Context,
+	c *query.Contract,
+	userID uuid.UUID,
+	action string,
+	oldValues any,
+	newValues any,
+	metadata any,
+) {
+	activity.Log(ctx, s.store, activity.Entry{
+		AgencyID:   c.AgencyID,
+		UserID:     userID,
+		Action:     action,
+		EntityType: "contract",
+		EntityID:   c.ID,
+		OldValues:  oldValues,
+		NewValues:  newValues,
+		Metadata:   metadata,
+	})
+}
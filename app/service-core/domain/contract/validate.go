@@ -0,0 +1,79 @@
+package contract
+
+import (
+	"app/pkg"
+	"net/mail"
+	"service-core/storage/query"
+)
+
+func validate(c query.Contract) error {
+	var errors pkg.ValidationErrors
+	if c.ClientBusinessName == "" {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "clientBusinessName",
+			Tag:     "required",
+			Message: "Client business name is required",
+		})
+	}
+	if _, err := mail.ParseAddress(c.ClientEmail); err != nil {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "clientEmail",
+			Tag:     "email",
+			Message: "Client email must be a valid email address",
+		})
+	}
+	if c.CommencementDate.Valid && c.CompletionDate.Valid && c.CompletionDate.Time.Before(c.CommencementDate.Time) {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "completionDate",
+			Tag:     "gtefield",
+			Message: "Completion date cannot be before the commencement date",
+		})
+	}
+	if c.TotalPrice.IsNegative() {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "totalPrice",
+			Tag:     "min",
+			Message: "Total price cannot be negative",
+		})
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+func validateSignature(req SignRequest, requireTitle bool) error {
+	var errors pkg.ValidationErrors
+	if req.SignatoryName == "" {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "signatoryName",
+			Tag:     "required",
+			Message: "Signatory name is required",
+		})
+	}
+	if len(req.SignatoryName) > 255 {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "signatoryName",
+			Tag:     "max",
+			Message: "Signatory name must be at most 255 characters",
+		})
+	}
+	if requireTitle && req.SignatoryTitle == "" {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "signatoryTitle",
+			Tag:     "required",
+			Message: "Signatory title is required",
+		})
+	}
+	if len(req.SignatoryTitle) > 100 {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "signatoryTitle",
+			Tag:     "max",
+			Message: "Signatory title must be at most 100 characters",
+		})
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
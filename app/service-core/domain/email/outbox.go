@@ -0,0 +1,235 @@
+package email
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"service-core/storage/query"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of outbox emails, which follow those of email logs
+const (
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+)
+
+// MaxAttempts is how many times an email is tried before it is left as
+// failed for a manual resend
+const MaxAttempts = 10
+
+const (
+	// retryBase is the wait after the first failed attempt, which doubles
+	// with each further failure up to retryMax
+	retryBase = 30 * time.Second
+	retryMax  = time.Hour
+	// leaseDuration is how long a worker has to send an email it claimed
+	// before another worker may take it over
+	leaseDuration = 5 * time.Minute
+)
+
+// Backoff returns how long to wait before the next attempt at an email
+// that has failed the given number of times
+func Backoff(failures int32) time.Duration {
+	wait := retryBase
+	for i := int32(1); i < failures && wait < retryMax; i++ {
+		wait *= 2
+	}
+	return min(wait, retryMax)
+}
+
+// Run sends queued emails with cfg.EmailWorkers workers until the context
+// is cancelled, then waits for sends in progress to finish. Workers poll
+// the outbox every cfg.EmailPollInterval, and are woken when an email is
+// queued.
+func (s *Service) Run(ctx context.Context) {
+	slog.Info("Email workers started", "workers", s.cfg.EmailWorkers)
+	var wg sync.WaitGroup
+	for range s.cfg.EmailWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx)
+		}()
+	}
+	wg.Wait()
+	slog.Info("Email workers stopped")
+}
+
+func (s *Service) work(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.EmailPollInterval)
+	defer ticker.Stop()
+	for {
+		for s.sendNext(ctx) {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// notify wakes an idle worker, if there is one
+func (s *Service) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// sendNext claims the next email that is due and sends it, reporting
+// whether there was one
+func (s *Service) sendNext(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	emails, err := s.store.ClaimEmails(ctx, query.ClaimEmailsParams{
+		LockedUntil: sql.NullTime{Time: time.Now().Add(leaseDuration), Valid: true},
+		RowLimit:    1,
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("Error claiming emails", "error", err)
+		}
+		return false
+	}
+	if len(emails) == 0 {
+		return false
+	}
+	// A send in progress is finished even when the workers are stopping
+	s.deliver(context.WithoutCancel(ctx), emails[0])
+	return true
+}
+
+// deliver sends a claimed email and records the outcome. A failed email is
+// retried with exponential backoff until MaxAttempts, and then left failed.
+// The outcome is only recorded under the lease the email was claimed with;
+// if the lease ran out and another worker took the email over, that
+// worker's outcome stands.
+func (s *Service) deliver(ctx context.Context, e query.Email) {
+	sendCtx, cancel := context.WithTimeout(ctx, s.cfg.ContextTimeout)
+	messageID, err := s.send(sendCtx, e)
+	cancel()
+
+	ctx, cancel = context.WithTimeout(ctx, s.cfg.ContextTimeout)
+	defer cancel()
+	if err == nil {
+		sent, err := s.store.UpdateEmailSent(ctx, query.UpdateEmailSentParams{
+			ID:                e.ID,
+			ProviderMessageID: messageID,
+			ClaimedUntil:      e.LockedUntil,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("Email lease lost before recording it sent", "email_id", e.ID, "provider_message_id", messageID)
+			return
+		}
+		if err != nil {
+			slog.Error("Error recording sent email", "error", err, "email_id", e.ID)
+			return
+		}
+		s.updateLog(ctx, sent)
+		return
+	}
+
+	failures := e.RetryCount + 1
+	status := StatusPending
+	if failures >= MaxAttempts {
+		status = StatusFailed
+		slog.Error("Email failed, giving up", "error", err, "email_id", e.ID, "attempts", failures)
+	} else {
+		slog.Warn("Email failed, retrying", "error", err, "email_id", e.ID, "attempts", failures)
+	}
+	failed, uerr := s.store.UpdateEmailFailed(ctx, query.UpdateEmailFailedParams{
+		Status:        status,
+		ErrorMessage:  err.Error(),
+		NextAttemptAt: time.Now().Add(Backoff(failures)),
+		ID:            e.ID,
+		ClaimedUntil:  e.LockedUntil,
+	})
+	if errors.Is(uerr, sql.ErrNoRows) {
+		slog.Warn("Email lease lost before recording its failure", "email_id", e.ID)
+		return
+	}
+	if uerr != nil {
+		slog.Error("Error recording failed email", "error", uerr, "email_id", e.ID)
+		return
+	}
+	s.updateLog(ctx, failed)
+}
+
+// send sends an email with its attachments, which are fetched as the user
+// who queued it, and returns the provider's message ID
+func (s *Service) send(ctx context.Context, e query.Email) (string, error) {
+	rows, err := s.store.SelectEmailAttachments(ctx, e.ID)
+	if err != nil {
+		return "", fmt.Errorf("error selecting attachments: %w", err)
+	}
+	attachments := make([]Attachment, 0, len(rows))
+	for _, row := range rows {
+		if !row.FileID.Valid {
+			return "", fmt.Errorf("attachment %s has no file", row.ID)
+		}
+		_, data, err := s.fileService.DownloadFile(ctx, auth.UserAttr{ID: e.UserID}, row.FileID.UUID)
+		if err != nil {
+			return "", fmt.Errorf("error downloading attachment %s: %w", row.FileID.UUID, err)
+		}
+		attachments = append(attachments, Attachment{
+			Filename:    row.FileName,
+			ContentType: row.ContentType,
+			Content:     data,
+		})
+	}
+	email := Email{
+		EmailTo:          e.EmailTo,
+		EmailSubject:     e.EmailSubject,
+		EmailBody:        e.EmailBody,
+		EmailText:        htmlText(e.EmailBody),
+		EmailAttachments: attachments,
+	}
+	if e.UnsubscribeToken.Valid {
+		email.Headers = s.unsubscribeHeaders(e.UnsubscribeToken.String)
+	}
+	return s.provider.Send(ctx, email)
+}
+
+// updateLog mirrors an email's delivery onto the email log it was sent for
+func (s *Service) updateLog(ctx context.Context, e query.Email) {
+	if !e.EmailLogID.Valid {
+		return
+	}
+	err := s.store.UpdateEmailLogDelivery(ctx, query.UpdateEmailLogDeliveryParams{
+		Status:            e.Status,
+		RetryCount:        e.RetryCount,
+		ErrorMessage:      sql.NullString{String: e.ErrorMessage, Valid: e.ErrorMessage != ""},
+		ProviderMessageID: sql.NullString{String: e.ProviderMessageID, Valid: e.ProviderMessageID != ""},
+		SentAt:            e.SentAt,
+		ID:                e.EmailLogID.UUID,
+	})
+	if err != nil {
+		slog.Error("Error updating email log", "error", err, "email_id", e.ID, "email_log_id", e.EmailLogID.UUID)
+	}
+}
+
+// Resend queues one of a user's failed emails to be sent again
+func (s *Service) Resend(ctx context.Context, userID, id uuid.UUID) (*query.Email, error) {
+	e, err := s.store.RetryEmail(ctx, query.RetryEmailParams{ID: id, UserID: userID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.NotFoundError{Message: "Failed email not found", Err: err}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error queueing email", Err: err}
+	}
+	s.updateLog(ctx, e)
+	s.notify()
+	return &e, nil
+}
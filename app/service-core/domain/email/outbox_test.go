@@ -0,0 +1,262 @@
+package email_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"service-core/config"
+	"service-core/domain/email"
+	"service-core/storage/query"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		failures int32
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{email.MaxAttempts, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := email.Backoff(tt.failures); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	t.Parallel()
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	agencyID := uuid.MustParse("00000000-0000-0000-0000-000000000100")
+	logID := uuid.MustParse("00000000-0000-0000-0000-000000000020")
+
+	tests := []struct {
+		name        string
+		sendErr     error
+		retryCount  int32
+		wantStatus  string
+		wantRetries int32
+	}{
+		{"sent", nil, 0, email.StatusSent, 0},
+		{"failure is retried", errors.New("provider down"), 0, email.StatusPending, 1},
+		{"last attempt fails", errors.New("provider down"), email.MaxAttempts - 1, email.StatusFailed, email.MaxAttempts},
+	}
+	cfg := &config.Config{
+		ContextTimeout:    time.Second,
+		EmailWorkers:      2,
+		EmailPollInterval: 10 * time.Millisecond,
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newMockStore()
+			store.logAgencies[logID] = agencyID
+			provider := &mockProvider{err: tt.sendErr}
+			s := email.NewService(cfg, store, store, provider, &mockFileService{})
+
+			queued, err := s.Queue(context.Background(), email.Message{
+				UserID:     userID,
+				AgencyID:   agencyID,
+				To:         "client@example.com",
+				Subject:    "Subject",
+				Body:       "Body",
+				EmailLogID: uuid.NullUUID{UUID: logID, Valid: true},
+			})
+			if err != nil {
+				t.Fatalf("Queue() = %v", err)
+			}
+			store.mu.Lock()
+			e := store.emails[queued.ID]
+			e.RetryCount = tt.retryCount
+			store.emails[queued.ID] = e
+			store.mu.Unlock()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				s.Run(ctx)
+				close(done)
+			}()
+			deadline := time.Now().Add(2 * time.Second)
+			for store.email(queued.ID).RetryCount == tt.retryCount && store.email(queued.ID).Status == email.StatusPending {
+				if time.Now().After(deadline) {
+					t.Fatal("email was not delivered")
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			cancel()
+			<-done
+
+			got := store.email(queued.ID)
+			if got.Status != tt.wantStatus || got.RetryCount != tt.wantRetries {
+				t.Fatalf("email is %s after %d failures, want %s after %d", got.Status, got.RetryCount, tt.wantStatus, tt.wantRetries)
+			}
+			if tt.wantStatus == email.StatusSent && got.ProviderMessageID != "message-id" {
+				t.Errorf("ProviderMessageID = %q, want the provider's message ID", got.ProviderMessageID)
+			}
+			if tt.sendErr != nil && got.ErrorMessage != tt.sendErr.Error() {
+				t.Errorf("ErrorMessage = %q, want %q", got.ErrorMessage, tt.sendErr.Error())
+			}
+			if tt.wantStatus == email.StatusPending && !got.NextAttemptAt.After(time.Now()) {
+				t.Error("retry was not scheduled in the future")
+			}
+			store.mu.Lock()
+			log := store.logs[logID]
+			store.mu.Unlock()
+			if log.Status != tt.wantStatus {
+				t.Errorf("email log status = %q, want %q", log.Status, tt.wantStatus)
+			}
+			if log.ProviderMessageID.String != got.ProviderMessageID {
+				t.Errorf("email log message ID = %q, want %q", log.ProviderMessageID.String, got.ProviderMessageID)
+			}
+		})
+	}
+}
+
+func TestResend(t *testing.T) {
+	t.Parallel()
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	other := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	cfg := &config.Config{ContextTimeout: time.Second}
+	store := newMockStore()
+	s := email.NewService(cfg, store, store, &mockProvider{}, &mockFileService{})
+
+	failedID := uuid.MustParse("00000000-0000-0000-0000-000000000030")
+	sentID := uuid.MustParse("00000000-0000-0000-0000-000000000031")
+	store.emails[failedID] = query.Email{ID: failedID, UserID: userID, Status: email.StatusFailed, RetryCount: email.MaxAttempts}
+	store.emails[sentID] = query.Email{ID: sentID, UserID: userID, Status: email.StatusSent}
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		id      uuid.UUID
+		wantErr bool
+	}{
+		{"other user's email", other, failedID, true},
+		{"sent email", userID, sentID, true},
+		{"failed email", userID, failedID, false},
+	}
+	for _, tt := range tests {
+		e, err := s.Resend(context.Background(), tt.userID, tt.id)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: Resend() = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil && (e.Status != email.StatusPending || e.RetryCount != 0) {
+			t.Fatalf("%s: resent email is %s after %d failures", tt.name, e.Status, e.RetryCount)
+		}
+	}
+}
+
+func TestQueueAttachments(t *testing.T) {
+	t.Parallel()
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	fileID := uuid.MustParse("00000000-0000-0000-0000-000000000030")
+	files := &mockFileService{files: map[uuid.UUID]query.File{
+		fileID: {ID: fileID, UserID: userID, FileName: "proposal.pdf"},
+	}}
+	cfg := &config.Config{ContextTimeout: time.Second}
+
+	tests := []struct {
+		name      string
+		insertErr error
+		want      int
+	}{
+		{name: "queued with the email", want: 1},
+		{name: "rolled back with the email", insertErr: errors.New("insert failed"), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newMockStore()
+			store.insertErr = tt.insertErr
+			s := email.NewService(cfg, store, store, &mockProvider{}, files)
+			_, err := s.Queue(context.Background(), email.Message{
+				UserID:        userID,
+				To:            "client@example.com",
+				Subject:       "Subject",
+				Body:          "Body",
+				AttachmentIDs: []uuid.UUID{fileID},
+			})
+			if (err != nil) != (tt.insertErr != nil) {
+				t.Fatalf("Queue() = %v, want error %v", err, tt.insertErr)
+			}
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			if len(store.attachments) != tt.want || len(store.emails) != tt.want {
+				t.Errorf("%d emails and %d attachments stored, want %d of each", len(store.emails), len(store.attachments), tt.want)
+			}
+		})
+	}
+}
+
+// blockingProvider holds a send until it is released
+type blockingProvider struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingProvider) Send(ctx context.Context, e email.Email) (string, error) {
+	close(p.started)
+	<-p.release
+	return "late-message-id", nil
+}
+
+func TestOutboxLeaseLost(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Config{
+		ContextTimeout:    time.Second,
+		EmailWorkers:      1,
+		EmailPollInterval: 10 * time.Millisecond,
+	}
+	store := newMockStore()
+	provider := &blockingProvider{started: make(chan struct{}), release: make(chan struct{})}
+	s := email.NewService(cfg, store, store, provider, &mockFileService{})
+	queued, err := s.Queue(context.Background(), email.Message{
+		UserID:  uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		To:      "client@example.com",
+		Subject: "Subject",
+		Body:    "Body",
+	})
+	if err != nil {
+		t.Fatalf("Queue() = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	<-provider.started
+
+	// The lease runs out mid-send, and another worker claims the email and
+	// sends it
+	store.mu.Lock()
+	e := store.emails[queued.ID]
+	e.LockedUntil = sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true}
+	store.emails[queued.ID] = e
+	store.mu.Unlock()
+	if _, err := store.UpdateEmailSent(context.Background(), query.UpdateEmailSentParams{
+		ID:                queued.ID,
+		ProviderMessageID: "other-message-id",
+		ClaimedUntil:      e.LockedUntil,
+	}); err != nil {
+		t.Fatalf("UpdateEmailSent() by the other worker = %v", err)
+	}
+
+	close(provider.release)
+	cancel()
+	<-done
+	if got := store.email(queued.ID); got.Status != email.StatusSent || got.ProviderMessageID != "other-message-id" {
+		t.Errorf("email is %s with message ID %q, want the other worker's outcome", got.Status, got.ProviderMessageID)
+	}
+}
@@ -17,10 +17,14 @@ type Attachment struct {
 }
 
 type Email struct {
-	EmailTo          string
-	EmailSubject     string
-	EmailBody        string
+	EmailTo      string
+	EmailSubject string
+	EmailBody    string
+	// EmailText is the plain text alternative of the HTML body
+	EmailText        string
 	EmailAttachments []Attachment
+	// Headers are extra headers, such as List-Unsubscribe
+	Headers map[string]string
 }
 
 //nolint:ireturn
@@ -36,6 +40,8 @@ func NewProvider(cfg *config.Config) provider {
 		return &sesProvider{cfg: cfg}
 	case "smtp":
 		return &smtpProvider{cfg: cfg}
+	case "mailbox":
+		return &mailboxProvider{cfg: cfg}
 	case "local":
 		return &localProvider{cfg: cfg}
 	default:
@@ -43,7 +49,9 @@ func NewProvider(cfg *config.Config) provider {
 	}
 }
 
-func sendEmail(ctx context.Context, content any, url string, headers map[string]string) error {
+// sendEmail posts an email to a provider's API, returning the response
+// body and headers, which carry the provider's message ID
+func sendEmail(ctx context.Context, content any, url string, headers map[string]string) ([]byte, http.Header, error) {
 	var client = &http.Client{}
 	var payload []byte
 	var errJSON error
@@ -53,24 +61,24 @@ func sendEmail(ctx context.Context, content any, url string, headers map[string]
 	} else {
 		payload, errJSON = json.Marshal(content)
 		if errJSON != nil {
-			return fmt.Errorf("error marshalling email body: %w", errJSON)
+			return nil, nil, fmt.Errorf("error marshalling email body: %w", errJSON)
 		}
 	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(payload))
 	if err != nil {
-		return fmt.Errorf("error creating email request: %w", err)
+		return nil, nil, fmt.Errorf("error creating email request: %w", err)
 	}
 	for key, value := range headers {
 		req.Header.Add(key, value)
 	}
 	res, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("error sending email request: %w", err)
+		return nil, nil, fmt.Errorf("error sending email request: %w", err)
 	}
 	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
 	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusAccepted {
-		body, _ := io.ReadAll(res.Body)
-		return fmt.Errorf("error sending email: %w", fmt.Errorf("status: %d, Body: %s", res.StatusCode, body))
+		return nil, nil, fmt.Errorf("error sending email: %w", fmt.Errorf("status: %d, Body: %s", res.StatusCode, body))
 	}
-	return nil
+	return body, res.Header, nil
 }
@@ -0,0 +1,47 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/mail"
+	"net/smtp"
+	"service-core/config"
+	"time"
+)
+
+// mailboxProvider sends emails to the development mailbox of the admin
+// service, which keeps them so they can be inspected instead of delivered
+type mailboxProvider struct {
+	cfg *config.Config
+}
+
+// Send sends an email as a MIME message, with its attachments, to the
+// mailbox's SMTP server. The message ID is the Message-ID header the email
+// is sent with.
+func (p *mailboxProvider) Send(_ context.Context, email Email) (string, error) {
+	messageID := newMessageID(p.cfg.EmailFrom)
+	email.Headers = maps.Clone(email.Headers)
+	if email.Headers == nil {
+		email.Headers = map[string]string{}
+	}
+	email.Headers["Message-ID"] = "<" + messageID + ">"
+	email.Headers["Date"] = time.Now().Format(time.RFC1123Z)
+
+	msg, err := createMIMEMessage(email, p.cfg.EmailFrom)
+	if err != nil {
+		return "", fmt.Errorf("error creating MIME message: %w", err)
+	}
+	from, err := mail.ParseAddress(p.cfg.EmailFrom)
+	if err != nil {
+		return "", fmt.Errorf("invalid sender address: %w", err)
+	}
+	to, err := mail.ParseAddress(email.EmailTo)
+	if err != nil {
+		return "", fmt.Errorf("invalid recipient address: %w", err)
+	}
+	if err := smtp.SendMail(p.cfg.MailboxSMTPAddr, nil, from.Address, []string{to.Address}, []byte(msg)); err != nil {
+		return "", fmt.Errorf("error sending email to the mailbox: %w", err)
+	}
+	return messageID, nil
+}
@@ -0,0 +1,103 @@
+package email_test
+
+import (
+	"app/pkg/mailbox"
+	"context"
+	"net"
+	"service-core/config"
+	"service-core/domain/email"
+	"service-core/storage/query"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMailboxProvider(t *testing.T) {
+	t.Parallel()
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	agencyID := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	fileID := uuid.MustParse("00000000-0000-0000-0000-000000000010")
+
+	box := mailbox.New(10)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &mailbox.Server{Mailbox: box}
+	go server.Serve(l)
+	t.Cleanup(func() { server.Close() })
+
+	cfg := &config.Config{
+		ContextTimeout:    time.Second,
+		CoreURL:           "https://core.example.com",
+		EmailProvider:     "mailbox",
+		EmailFrom:         "Acme <noreply@example.com>",
+		MailboxSMTPAddr:   l.Addr().String(),
+		EmailWorkers:      1,
+		EmailPollInterval: 10 * time.Millisecond,
+	}
+	store := newMockStore()
+	store.agencies[agencyID] = query.Agency{ID: agencyID, Name: "Acme Studio"}
+	files := &mockFileService{files: map[uuid.UUID]query.File{
+		fileID: {ID: fileID, UserID: userID, FileName: "proposal.pdf"},
+	}}
+	s := email.NewService(cfg, store, store, email.NewProvider(cfg), files)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	loginURL := "https://app.example.com/login/callback?token=abc&email=jane%40example.com"
+	if _, err := s.SendTemplateEmail(ctx, email.TemplateMessage{
+		To:       "jane@example.com",
+		Template: email.TemplateLogin,
+		Data:     map[string]any{"LoginURL": loginURL},
+	}); err != nil {
+		t.Fatalf("SendTemplateEmail() = %v", err)
+	}
+	proposal, err := s.Queue(ctx, email.Message{
+		UserID:        userID,
+		AgencyID:      agencyID,
+		To:            "Sam <sam@example.com>",
+		Subject:       "Your proposal",
+		Body:          "<p>Your proposal is attached</p>",
+		AttachmentIDs: []uuid.UUID{fileID},
+		Bulk:          true,
+	})
+	if err != nil {
+		t.Fatalf("Queue() = %v", err)
+	}
+
+	// The sign in link can be followed from the HTML of the login email
+	login, err := box.Wait("jane@example.com", 2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(login.Links, loginURL) {
+		t.Errorf("Links = %v, want %s", login.Links, loginURL)
+	}
+	if !strings.Contains(login.Text, loginURL) {
+		t.Errorf("Text = %q, want the sign in link", login.Text)
+	}
+	if got := login.Headers["Message-Id"]; len(got) != 1 || !strings.HasSuffix(got[0], "@example.com>") {
+		t.Errorf("Message-ID = %v", got)
+	}
+
+	// The proposal arrives with its attachment and unsubscribe headers
+	sent, err := box.Wait("sam@example.com", 2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.Subject != "Your proposal" || sent.From != "noreply@example.com" {
+		t.Errorf("email = %q from %q", sent.Subject, sent.From)
+	}
+	if len(sent.Attachments) != 1 || sent.Attachments[0].Filename != "proposal.pdf" || string(sent.Attachments[0].Content) != "content" {
+		t.Errorf("Attachments = %+v, want proposal.pdf", sent.Attachments)
+	}
+	want := "<https://core.example.com/api/v1/email-unsubscribe/" + proposal.UnsubscribeToken.String + ">"
+	if got := sent.Headers["List-Unsubscribe"]; len(got) != 1 || got[0] != want {
+		t.Errorf("List-Unsubscribe = %v, want %s", got, want)
+	}
+}
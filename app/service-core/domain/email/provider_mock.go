@@ -8,6 +8,6 @@ func NewMockProvider() *MockProvider {
     return &MockProvider{}
 }
 
-func (p *MockProvider) Send(_ context.Context, _ Email) error {
-    return nil
+func (p *MockProvider) Send(_ context.Context, _ Email) (string, error) {
+    return "", nil
 }
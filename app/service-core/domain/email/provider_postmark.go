@@ -1,8 +1,14 @@
 package email
 
 import (
+	"app/pkg"
 	"context"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 	"service-core/config"
 )
 
@@ -12,27 +18,32 @@ type postmarkAttachment struct {
 	ContentType string `json:"ContentType"`
 }
 
+type postmarkHeader struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
 type postmarkEmail struct {
 	From          string               `json:"From"`
 	To            string               `json:"To"`
 	Subject       string               `json:"Subject"`
 	HTMLBody      string               `json:"HtmlBody"`
+	TextBody      string               `json:"TextBody,omitempty"`
 	MessageStream string               `json:"MessageStream"`
+	TrackOpens    bool                 `json:"TrackOpens"`
 	Attachments   []postmarkAttachment `json:"Attachments"`
+	Headers       []postmarkHeader     `json:"Headers,omitempty"`
 }
 
-type postmarkTemplate struct {
-	From          string `json:"From"`
-	To            string `json:"To"`
-	TemplateID    string `json:"TemplateId"`
-	TemplateModel any    `json:"TemplateModel"`
+type postmarkResponse struct {
+	MessageID string `json:"MessageID"`
 }
 
 type postmarkProvider struct {
 	cfg *config.Config
 }
 
-func (p *postmarkProvider) Send(ctx context.Context, email Email) error {
+func (p *postmarkProvider) Send(ctx context.Context, email Email) (string, error) {
 	var postmarkURL = "https://api.postmarkapp.com/email"
 
 	content := postmarkEmail{
@@ -40,7 +51,9 @@ func (p *postmarkProvider) Send(ctx context.Context, email Email) error {
 		To:            email.EmailTo,
 		Subject:       email.EmailSubject,
 		HTMLBody:      email.EmailBody,
+		TextBody:      email.EmailText,
 		MessageStream: "outbound",
+		TrackOpens:    true,
 		Attachments:   make([]postmarkAttachment, 0, len(email.EmailAttachments)),
 	}
 
@@ -51,29 +64,75 @@ func (p *postmarkProvider) Send(ctx context.Context, email Email) error {
 			ContentType: attachment.ContentType,
 		})
 	}
+	for name, value := range email.Headers {
+		content.Headers = append(content.Headers, postmarkHeader{Name: name, Value: value})
+	}
 
 	headers := map[string]string{
 		"Accept":                  "application/json",
 		"Content-Type":            "application/json",
 		"X-Postmark-Server-Token": p.cfg.PostmarkAPIKey,
 	}
-	return sendEmail(ctx, content, postmarkURL, headers)
+	body, _, err := sendEmail(ctx, content, postmarkURL, headers)
+	if err != nil {
+		return "", err
+	}
+	var res postmarkResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return "", fmt.Errorf("error decoding Postmark response: %w", err)
+	}
+	return res.MessageID, nil
 }
 
-func (p *postmarkProvider) SendTemplate(ctx context.Context, templateID string, to string, data map[string]any) error {
-	var postmarkURL = "https://api.postmarkapp.com/email/withTemplate"
+// postmarkEvent is a Postmark webhook payload. The time of the event is in
+// the field named for its record type.
+type postmarkEvent struct {
+	RecordType  string `json:"RecordType"`
+	MessageID   string `json:"MessageID"`
+	Type        string `json:"Type"`
+	Description string `json:"Description"`
+	DeliveredAt string `json:"DeliveredAt"`
+	BouncedAt   string `json:"BouncedAt"`
+	ReceivedAt  string `json:"ReceivedAt"`
+}
 
-	content := postmarkTemplate{
-		From:          p.cfg.EmailFrom,
-		To:            to,
-		TemplateID:    templateID,
-		TemplateModel: data,
+// postmarkEvents verifies a Postmark webhook request, which Postmark signs
+// with nothing but the basic auth credentials in the webhook's URL
+func (s *Service) postmarkEvents(header http.Header, body []byte) ([]Event, error) {
+	if s.cfg.PostmarkWebhookPassword == "" {
+		return nil, webhookNotConfigured("postmark")
 	}
-
-	headers := map[string]string{
-		"Accept":                  "application/json",
-		"Content-Type":            "application/json",
-		"X-Postmark-Server-Token": p.cfg.PostmarkAPIKey,
+	username, password, ok := (&http.Request{Header: header}).BasicAuth()
+	validUsername := subtle.ConstantTimeCompare([]byte(username), []byte(s.cfg.PostmarkWebhookUsername)) == 1
+	validPassword := subtle.ConstantTimeCompare([]byte(password), []byte(s.cfg.PostmarkWebhookPassword)) == 1
+	if !ok || !validUsername || !validPassword {
+		return nil, pkg.UnauthorizedError{Err: errors.New("invalid Postmark webhook credentials")}
+	}
+	var e postmarkEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid webhook payload", Err: err}
+	}
+	switch e.RecordType {
+	case "Delivery":
+		return []Event{{MessageID: e.MessageID, Type: EventDelivered, OccurredAt: parseEventTime(e.DeliveredAt)}}, nil
+	case "Open":
+		return []Event{{MessageID: e.MessageID, Type: EventOpened, OccurredAt: parseEventTime(e.ReceivedAt)}}, nil
+	case "Bounce":
+		// Transient bounces are delays, and auto responders replies to an
+		// email that was delivered
+		if e.Type == "Transient" || e.Type == "AutoResponder" {
+			return nil, nil
+		}
+		return []Event{{
+			MessageID:  e.MessageID,
+			Type:       EventBounced,
+			OccurredAt: parseEventTime(e.BouncedAt),
+			Reason:     e.Description,
+			Hard:       e.Type == "HardBounce" || e.Type == "BadEmailAddress",
+		}}, nil
+	case "SpamComplaint":
+		return []Event{{MessageID: e.MessageID, Type: EventComplained, OccurredAt: parseEventTime(e.BouncedAt)}}, nil
+	default:
+		return nil, nil
 	}
-	return sendEmail(ctx, content, postmarkURL, headers)
 }
@@ -1,9 +1,19 @@
 package email
 
 import (
+	"app/pkg"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"service-core/config"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type resendAttachment struct {
@@ -16,14 +26,20 @@ type resendEmail struct {
 	To          []string           `json:"to"`
 	Subject     string             `json:"subject"`
 	HTML        string             `json:"html"`
+	Text        string             `json:"text,omitempty"`
 	Attachments []resendAttachment `json:"attachments"`
+	Headers     map[string]string  `json:"headers,omitempty"`
+}
+
+type resendResponse struct {
+	ID string `json:"id"`
 }
 
 type resendProvider struct {
 	cfg *config.Config
 }
 
-func (p *resendProvider) Send(ctx context.Context, email Email) error {
+func (p *resendProvider) Send(ctx context.Context, email Email) (string, error) {
 	var resendURL = "https://api.resend.com/emails"
 
 	content := resendEmail{
@@ -31,7 +47,9 @@ func (p *resendProvider) Send(ctx context.Context, email Email) error {
 		To:          []string{email.EmailTo},
 		Subject:     email.EmailSubject,
 		HTML:        email.EmailBody,
+		Text:        email.EmailText,
 		Attachments: make([]resendAttachment, 0, len(email.EmailAttachments)),
+		Headers:     email.Headers,
 	}
 	if len(email.EmailAttachments) > 0 {
 		for _, attachment := range email.EmailAttachments {
@@ -46,9 +64,86 @@ func (p *resendProvider) Send(ctx context.Context, email Email) error {
 		"Content-Type":  "application/json",
 		"Authorization": "Bearer " + p.cfg.ResendAPIKey,
 	}
-	return sendEmail(ctx, content, resendURL, headers)
+	body, _, err := sendEmail(ctx, content, resendURL, headers)
+	if err != nil {
+		return "", err
+	}
+	var res resendResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return "", fmt.Errorf("error decoding Resend response: %w", err)
+	}
+	return res.ID, nil
 }
 
-func (p *resendProvider) SendTemplate(_ context.Context, _ string, _ string, _ map[string]any) error {
-	return errors.New("not implemented")
+// resendEvent is a Resend webhook payload
+type resendEvent struct {
+	Type      string `json:"type"`
+	CreatedAt string `json:"created_at"`
+	Data      struct {
+		EmailID string `json:"email_id"`
+		Bounce  struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+		} `json:"bounce"`
+	} `json:"data"`
+}
+
+// resendEvents verifies a Resend webhook request. Resend signs requests the
+// Svix way: an HMAC of the message ID, timestamp and body, keyed with the
+// base64 secret after its "whsec_" prefix.
+func (s *Service) resendEvents(header http.Header, body []byte) ([]Event, error) {
+	if s.cfg.ResendWebhookSecret == "" {
+		return nil, webhookNotConfigured("resend")
+	}
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s.cfg.ResendWebhookSecret, "whsec_"))
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Invalid Resend webhook secret", Err: err}
+	}
+	id := header.Get("svix-id")
+	timestamp := header.Get("svix-timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("invalid Resend webhook timestamp: %w", err)}
+	}
+	if err := checkWebhookTime(time.Unix(seconds, 0)); err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(id + "." + timestamp + "."))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+	valid := false
+	// The header holds a space separated list of versioned signatures
+	for _, versioned := range strings.Fields(header.Get("svix-signature")) {
+		version, signature, _ := strings.Cut(versioned, ",")
+		decoded, err := base64.StdEncoding.DecodeString(signature)
+		if version == "v1" && err == nil && hmac.Equal(decoded, expected) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, pkg.UnauthorizedError{Err: errors.New("invalid Resend webhook signature")}
+	}
+
+	var e resendEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid webhook payload", Err: err}
+	}
+	event := Event{MessageID: e.Data.EmailID, OccurredAt: parseEventTime(e.CreatedAt)}
+	switch e.Type {
+	case "email.delivered":
+		event.Type = EventDelivered
+	case "email.opened":
+		event.Type = EventOpened
+	case "email.bounced":
+		event.Type = EventBounced
+		event.Reason = e.Data.Bounce.Message
+		event.Hard = e.Data.Bounce.Type == "Permanent"
+	case "email.complained":
+		event.Type = EventComplained
+	default:
+		return nil, nil
+	}
+	return []Event{event}, nil
 }
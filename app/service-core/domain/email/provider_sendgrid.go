@@ -1,10 +1,20 @@
 package email
 
 import (
+	"app/pkg"
 	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"service-core/config"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type sendgridAttachments struct {
@@ -28,13 +38,14 @@ type sendgridEmail struct {
 		Value string `json:"value"`
 	} `json:"content"`
 	Attachments []sendgridAttachments `json:"attachments"`
+	Headers     map[string]string     `json:"headers,omitempty"`
 }
 
 type sendgridProvider struct {
 	cfg *config.Config
 }
 
-func (p *sendgridProvider) Send(ctx context.Context, email Email) error {
+func (p *sendgridProvider) Send(ctx context.Context, email Email) (string, error) {
 	var sendgridURL = "https://api.sendgrid.com/v3/mail/send"
 
 	content := sendgridEmail{
@@ -69,6 +80,14 @@ func (p *sendgridProvider) Send(ctx context.Context, email Email) error {
 			},
 		},
 		Attachments: make([]sendgridAttachments, 0, len(email.EmailAttachments)),
+		Headers:     email.Headers,
+	}
+	if email.EmailText != "" {
+		// SendGrid requires the plain text content to come first
+		content.Content = append([]struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		}{{Type: "text/plain", Value: email.EmailText}}, content.Content...)
 	}
 
 	if len(email.EmailAttachments) > 0 {
@@ -86,9 +105,87 @@ func (p *sendgridProvider) Send(ctx context.Context, email Email) error {
 		"Content-Type":  "application/json",
 		"Authorization": "Bearer " + p.cfg.SendgridAPIKey,
 	}
-	return sendEmail(ctx, content, sendgridURL, headers)
+	_, header, err := sendEmail(ctx, content, sendgridURL, headers)
+	if err != nil {
+		return "", err
+	}
+	return header.Get("X-Message-Id"), nil
+}
+
+// sendgridEvent is one of the events in a SendGrid webhook payload.
+// sg_message_id is the X-Message-Id the email was sent with, followed by
+// a suffix naming the mail server.
+type sendgridEvent struct {
+	Event     string `json:"event"`
+	MessageID string `json:"sg_message_id"`
+	Timestamp int64  `json:"timestamp"`
+	Reason    string `json:"reason"`
+	// Type tells bounces from blocked emails, which the receiving server
+	// refused for now
+	Type string `json:"type"`
 }
 
-func (p *sendgridProvider) SendTemplate(_ context.Context, _ string, _ string, _ map[string]any) error {
-	return errors.New("not implemented")
+// sendgridEvents verifies a signed SendGrid event webhook request, which is
+// signed with ECDSA over its timestamp and body. Requests signed too long
+// ago are refused as replays.
+func (s *Service) sendgridEvents(header http.Header, body []byte) ([]Event, error) {
+	if s.cfg.SendgridWebhookPublicKey == "" {
+		return nil, webhookNotConfigured("sendgrid")
+	}
+	der, err := base64.StdEncoding.DecodeString(s.cfg.SendgridWebhookPublicKey)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Invalid SendGrid webhook public key", Err: err}
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Invalid SendGrid webhook public key", Err: err}
+	}
+	publicKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, pkg.InternalError{Message: "Invalid SendGrid webhook public key", Err: errors.New("public key is not an ECDSA key")}
+	}
+	signature, err := base64.StdEncoding.DecodeString(header.Get("X-Twilio-Email-Event-Webhook-Signature"))
+	if err != nil {
+		return nil, pkg.UnauthorizedError{Err: err}
+	}
+	timestamp := header.Get("X-Twilio-Email-Event-Webhook-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("invalid SendGrid webhook timestamp: %w", err)}
+	}
+	if err := checkWebhookTime(time.Unix(seconds, 0)); err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(append([]byte(timestamp), body...))
+	if !ecdsa.VerifyASN1(publicKey, digest[:], signature) {
+		return nil, pkg.UnauthorizedError{Err: errors.New("invalid SendGrid webhook signature")}
+	}
+
+	var payload []sendgridEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid webhook payload", Err: err}
+	}
+	events := make([]Event, 0, len(payload))
+	for _, e := range payload {
+		event := Event{
+			MessageID:  strings.SplitN(e.MessageID, ".", 2)[0],
+			OccurredAt: time.Unix(e.Timestamp, 0),
+		}
+		switch e.Event {
+		case "delivered":
+			event.Type = EventDelivered
+		case "open":
+			event.Type = EventOpened
+		case "bounce", "dropped":
+			event.Type = EventBounced
+			event.Reason = e.Reason
+			event.Hard = e.Event == "bounce" && e.Type != "blocked"
+		case "spamreport":
+			event.Type = EventComplained
+		default:
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
 }
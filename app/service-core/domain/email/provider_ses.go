@@ -1,18 +1,22 @@
 package email
 
 import (
+	"app/pkg"
 	"bytes"
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
-	"errors"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"maps"
 	"mime/multipart"
 	"net/textproto"
 	"net/url"
 	"service-core/config"
+	"strings"
 	"time"
 )
 
@@ -20,7 +24,13 @@ type sesProvider struct {
 	cfg *config.Config
 }
 
-func (p *sesProvider) Send(ctx context.Context, email Email) error {
+// sesResponse is the response to SendEmail or SendRawEmail
+type sesResponse struct {
+	MessageID    string `xml:"SendEmailResult>MessageId"`
+	RawMessageID string `xml:"SendRawEmailResult>MessageId"`
+}
+
+func (p *sesProvider) Send(ctx context.Context, email Email) (string, error) {
 	var sesURL = "https://email.%s.amazonaws.com"
 
 	region := p.cfg.SesRegion
@@ -32,7 +42,7 @@ func (p *sesProvider) Send(ctx context.Context, email Email) error {
 	// Parse endpoint to get the hostname
 	parsedURL, err := url.Parse(endpoint)
 	if err != nil {
-		return fmt.Errorf("error parsing endpoint: %w", err)
+		return "", fmt.Errorf("error parsing endpoint: %w", err)
 	}
 	hostname := parsedURL.Hostname()
 
@@ -42,14 +52,20 @@ func (p *sesProvider) Send(ctx context.Context, email Email) error {
 	dateStamp := now.Format("20060102")
 
 	// Prepare the request payload
-	content := createPayload(p.cfg.EmailFrom, email.EmailTo, email.EmailSubject, email.EmailBody)
-	if len(email.EmailAttachments) > 0 {
+	content := createPayload(p.cfg.EmailFrom, email.EmailTo, email.EmailSubject, email.EmailBody, email.EmailText)
+	// SendEmail takes neither attachments nor extra headers, so those
+	// emails are sent as raw MIME messages
+	if len(email.EmailAttachments) > 0 || len(email.Headers) > 0 {
 		mimeMessage, err := createMIMEMessage(email, p.cfg.EmailFrom)
 		if err != nil {
-			return fmt.Errorf("error creating MIME message: %w", err)
+			return "", fmt.Errorf("error creating MIME message: %w", err)
 		}
 		content = createRawPayload(mimeMessage)
 	}
+	if p.cfg.SesConfigurationSet != "" {
+		// Events are published through the configuration set
+		content += "&" + url.Values{"ConfigurationSetName": {p.cfg.SesConfigurationSet}}.Encode()
+	}
 
 	// Prepare the canonical request and string to sign
 	canonicalRequest := "POST\n/\n\ncontent-type:application/x-www-form-urlencoded\nhost:" + hostname +
@@ -70,11 +86,94 @@ func (p *sesProvider) Send(ctx context.Context, email Email) error {
 		"Authorization": authorization,
 	}
 
-	return sendEmail(ctx, content, endpoint, headers)
+	body, _, err := sendEmail(ctx, content, endpoint, headers)
+	if err != nil {
+		return "", err
+	}
+	var res sesResponse
+	if err := xml.Unmarshal(body, &res); err != nil {
+		return "", fmt.Errorf("error decoding SES response: %w", err)
+	}
+	if res.MessageID != "" {
+		return res.MessageID, nil
+	}
+	return res.RawMessageID, nil
 }
 
-func (p *sesProvider) SendTemplate(_ context.Context, _ string, _ string, _ map[string]any) error {
-	return errors.New("not implemented")
+// sesEvent is an SES event, published through a configuration set, or an
+// SES notification, which names its type notificationType instead
+type sesEvent struct {
+	EventType        string `json:"eventType"`
+	NotificationType string `json:"notificationType"`
+	Mail             struct {
+		MessageID string `json:"messageId"`
+	} `json:"mail"`
+	Delivery struct {
+		Timestamp string `json:"timestamp"`
+	} `json:"delivery"`
+	Open struct {
+		Timestamp string `json:"timestamp"`
+	} `json:"open"`
+	Bounce struct {
+		BounceType        string `json:"bounceType"`
+		BounceSubType     string `json:"bounceSubType"`
+		Timestamp         string `json:"timestamp"`
+		BouncedRecipients []struct {
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		Timestamp string `json:"timestamp"`
+	} `json:"complaint"`
+}
+
+// sesEvents verifies an SNS message from the topic SES publishes events
+// to, and returns the event it carries
+func (s *Service) sesEvents(ctx context.Context, body []byte) ([]Event, error) {
+	if s.cfg.SesWebhookTopicArn == "" {
+		return nil, webhookNotConfigured("ses")
+	}
+	m, err := s.snsNotification(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	if m.Type != "Notification" {
+		return nil, nil
+	}
+	var e sesEvent
+	if err := json.Unmarshal([]byte(m.Message), &e); err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid webhook payload", Err: err}
+	}
+	eventType := e.EventType
+	if eventType == "" {
+		eventType = e.NotificationType
+	}
+	event := Event{MessageID: e.Mail.MessageID}
+	switch eventType {
+	case "Delivery":
+		event.Type = EventDelivered
+		event.OccurredAt = parseEventTime(e.Delivery.Timestamp)
+	case "Open":
+		event.Type = EventOpened
+		event.OccurredAt = parseEventTime(e.Open.Timestamp)
+	case "Bounce":
+		event.Type = EventBounced
+		event.OccurredAt = parseEventTime(e.Bounce.Timestamp)
+		reasons := []string{e.Bounce.BounceType + " bounce (" + e.Bounce.BounceSubType + ")"}
+		for _, r := range e.Bounce.BouncedRecipients {
+			if r.DiagnosticCode != "" {
+				reasons = append(reasons, r.DiagnosticCode)
+			}
+		}
+		event.Reason = strings.Join(reasons, ": ")
+		event.Hard = e.Bounce.BounceType == "Permanent"
+	case "Complaint":
+		event.Type = EventComplained
+		event.OccurredAt = parseEventTime(e.Complaint.Timestamp)
+	default:
+		return nil, nil
+	}
+	return []Event{event}, nil
 }
 
 func createMIMEMessage(email Email, emailFrom string) (string, error) {
@@ -90,6 +189,7 @@ func createMIMEMessage(email Email, emailFrom string) (string, error) {
 		"Subject":      email.EmailSubject,
 		"MIME-Version": "1.0",
 	}
+	maps.Copy(headers, email.Headers)
 
 	for key, value := range headers {
 		buf.WriteString(fmt.Sprintf("%s: %s\r\n", key, value))
@@ -97,12 +197,31 @@ func createMIMEMessage(email Email, emailFrom string) (string, error) {
 
 	buf.WriteString("\r\n")
 
-	// Write body part
+	// Write body part, with the plain text and HTML as alternatives
+	var alternatives bytes.Buffer
+	alternativeWriter := multipart.NewWriter(&alternatives)
+	if email.EmailText != "" {
+		textWriter, _ := alternativeWriter.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {"text/plain; charset=UTF-8"},
+		})
+		if _, err := textWriter.Write([]byte(email.EmailText)); err != nil {
+			return "", fmt.Errorf("error writing body: %w", err)
+		}
+	}
+	htmlWriter, _ := alternativeWriter.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"text/html; charset=UTF-8"},
+	})
+	if _, err := htmlWriter.Write([]byte(email.EmailBody)); err != nil {
+		return "", fmt.Errorf("error writing body: %w", err)
+	}
+	if err := alternativeWriter.Close(); err != nil {
+		return "", fmt.Errorf("error writing body: %w", err)
+	}
 	bodyWriter, _ := writer.CreatePart(textproto.MIMEHeader{
-		"Content-Type": {"text/plain; charset=UTF-8"},
+		"Content-Type": {fmt.Sprintf("multipart/alternative; boundary=\"%s\"", alternativeWriter.Boundary())},
 	})
 
-	_, err := bodyWriter.Write([]byte(email.EmailBody))
+	_, err := bodyWriter.Write(alternatives.Bytes())
 	if err != nil {
 		return "", fmt.Errorf("error writing body: %w", err)
 	}
@@ -121,6 +240,9 @@ func createMIMEMessage(email Email, emailFrom string) (string, error) {
 			return "", fmt.Errorf("error writing attachment: %w", err)
 		}
 	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("error closing MIME message: %w", err)
+	}
 
 	return buf.String(), nil
 }
@@ -142,13 +264,16 @@ func getSignatureKey(secretKey, dateStamp, region, service string) []byte {
 }
 
 // Create the canonical request payload for Ses SendEmail
-func createPayload(from, to, subject, body string) string {
+func createPayload(from, to, subject, body, text string) string {
 	form := url.Values{}
 	form.Set("Action", "SendEmail")
 	form.Set("Source", from)
 	form.Set("Destination.ToAddresses.member.1", to)
 	form.Set("Message.Subject.Data", subject)
-	form.Set("Message.Body.Text.Data", body)
+	form.Set("Message.Body.Html.Data", body)
+	if text != "" {
+		form.Set("Message.Body.Text.Data", text)
+	}
 
 	return form.Encode()
 }
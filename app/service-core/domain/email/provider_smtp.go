@@ -8,21 +8,26 @@ import (
 	"service-core/config"
 	"strconv"
 	"strings"
+
+	"github.com/google/uuid"
 )
 
 type smtpProvider struct {
 	cfg *config.Config
 }
 
-func (p *smtpProvider) Send(_ context.Context, email Email) error {
+// Send sends an email over SMTP. SMTP servers do not return an ID, so the
+// message ID is the Message-ID header the email is sent with.
+func (p *smtpProvider) Send(_ context.Context, email Email) (string, error) {
 	to := []string{email.EmailTo}
-	msg := p.buildMessage(email)
+	messageID := newMessageID(p.cfg.EmailFrom)
+	msg := p.buildMessage(email, messageID)
 
 	addr := fmt.Sprintf("%s:%s", p.cfg.SMTPHost, p.cfg.SMTPPort)
 
 	port, err := strconv.Atoi(p.cfg.SMTPPort)
 	if err != nil {
-		return fmt.Errorf("invalid SMTP port: %w", err)
+		return "", fmt.Errorf("invalid SMTP port: %w", err)
 	}
 
 	// Only use authentication if credentials are provided and not empty
@@ -34,37 +39,56 @@ func (p *smtpProvider) Send(_ context.Context, email Email) error {
 	if port == 465 {
 		err = p.sendMailTLS(addr, auth, p.cfg.EmailFrom, to, msg)
 		if err != nil {
-			return fmt.Errorf("error sending email via SMTP TLS: %w", err)
+			return "", fmt.Errorf("error sending email via SMTP TLS: %w", err)
 		}
 	} else {
 		err = smtp.SendMail(addr, auth, p.cfg.EmailFrom, to, msg)
 		if err != nil {
-			return fmt.Errorf("error sending email via SMTP: %w", err)
+			return "", fmt.Errorf("error sending email via SMTP: %w", err)
 		}
 	}
 
-	return nil
+	return messageID, nil
 }
 
-func (p *smtpProvider) SendTemplate(_ context.Context, templateID string, to string, data map[string]any) error {
-	email := Email{
-		EmailTo:      to,
-		EmailSubject: fmt.Sprintf("Template: %s", templateID),
-		EmailBody:    fmt.Sprintf("Template ID: %s\nData: %+v", templateID, data),
+// newMessageID returns a new message ID in the domain of the sender
+func newMessageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = strings.TrimRight(from[i+1:], "> ")
 	}
-	return p.Send(context.Background(), email)
+	return fmt.Sprintf("%s@%s", uuid.NewString(), domain)
 }
 
-func (p *smtpProvider) buildMessage(email Email) []byte {
+func (p *smtpProvider) buildMessage(email Email, messageID string) []byte {
 	var msg strings.Builder
 
+	msg.WriteString(fmt.Sprintf("Message-ID: <%s>\r\n", messageID))
 	msg.WriteString(fmt.Sprintf("From: %s\r\n", p.cfg.EmailFrom))
 	msg.WriteString(fmt.Sprintf("To: %s\r\n", email.EmailTo))
 	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", email.EmailSubject))
+	for name, value := range email.Headers {
+		msg.WriteString(fmt.Sprintf("%s: %s\r\n", name, value))
+	}
 	msg.WriteString("MIME-Version: 1.0\r\n")
-	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	if email.EmailText == "" {
+		msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+		msg.WriteString("\r\n")
+		msg.WriteString(email.EmailBody)
+		return []byte(msg.String())
+	}
+
+	// Send the plain text and HTML as alternatives, preferring the HTML
+	boundary := uuid.NewString()
+	msg.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=\"%s\"\r\n", boundary))
 	msg.WriteString("\r\n")
+	msg.WriteString(fmt.Sprintf("--%s\r\n", boundary))
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(email.EmailText)
+	msg.WriteString(fmt.Sprintf("\r\n--%s\r\n", boundary))
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
 	msg.WriteString(email.EmailBody)
+	msg.WriteString(fmt.Sprintf("\r\n--%s--\r\n", boundary))
 
 	return []byte(msg.String())
 }
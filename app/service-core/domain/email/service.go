@@ -2,47 +2,85 @@ package email
 
 import (
 	"app/pkg"
+	"app/pkg/auth"
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"service-core/config"
 	"service-core/storage/query"
+	"sync"
 
 	"github.com/google/uuid"
 )
 
 type store interface {
 	SelectEmails(ctx context.Context, userID uuid.UUID) ([]query.Email, error)
+	SelectEmailsByStatus(ctx context.Context, arg query.SelectEmailsByStatusParams) ([]query.Email, error)
 	SelectEmailAttachments(ctx context.Context, emailID uuid.UUID) ([]query.EmailAttachment, error)
-	InsertEmail(ctx context.Context, params query.InsertEmailParams) (query.Email, error)
-	InsertEmailAttachment(ctx context.Context, params query.InsertEmailAttachmentParams) (query.EmailAttachment, error)
+	ClaimEmails(ctx context.Context, arg query.ClaimEmailsParams) ([]query.Email, error)
+	UpdateEmailSent(ctx context.Context, arg query.UpdateEmailSentParams) (query.Email, error)
+	UpdateEmailFailed(ctx context.Context, arg query.UpdateEmailFailedParams) (query.Email, error)
+	RetryEmail(ctx context.Context, arg query.RetryEmailParams) (query.Email, error)
+	SelectEmailLogAgencyID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	UpdateEmailLogDelivery(ctx context.Context, arg query.UpdateEmailLogDeliveryParams) error
+	UpdateEmailLogEvent(ctx context.Context, arg query.UpdateEmailLogEventParams) ([]query.UpdateEmailLogEventRow, error)
+	SelectAgency(ctx context.Context, id uuid.UUID) (query.Agency, error)
+	SelectAgencyProfile(ctx context.Context, agencyID uuid.UUID) (query.AgencyProfile, error)
+	SelectEmailTemplate(ctx context.Context, arg query.SelectEmailTemplateParams) (query.EmailTemplate, error)
+	SelectEmailTemplates(ctx context.Context, agencyID uuid.UUID) ([]query.EmailTemplate, error)
+	SelectEmailTemplateVersions(ctx context.Context, arg query.SelectEmailTemplateVersionsParams) ([]query.EmailTemplate, error)
+	InsertEmailTemplate(ctx context.Context, arg query.InsertEmailTemplateParams) (query.EmailTemplate, error)
+	DeleteEmailTemplates(ctx context.Context, arg query.DeleteEmailTemplatesParams) (int64, error)
+	SelectEmailSuppression(ctx context.Context, arg query.SelectEmailSuppressionParams) (query.EmailSuppression, error)
+	SelectEmailSuppressions(ctx context.Context, agencyID uuid.UUID) ([]query.EmailSuppression, error)
+	UpsertEmailSuppression(ctx context.Context, arg query.UpsertEmailSuppressionParams) (query.EmailSuppression, error)
+	DeleteEmailSuppression(ctx context.Context, arg query.DeleteEmailSuppressionParams) (int64, error)
+	SelectEmailByUnsubscribeToken(ctx context.Context, unsubscribeToken sql.NullString) (query.Email, error)
+}
+
+// transactor runs a function in a database transaction
+type transactor interface {
+	InTx(ctx context.Context, fn func(q query.Querier) error) error
 }
 
 type provider interface {
-	Send(ctx context.Context, email Email) error
-	SendTemplate(ctx context.Context, templateID string, to string, data map[string]any) error
+	// Send sends an email, returning the ID the provider gave it
+	Send(ctx context.Context, email Email) (string, error)
 }
 
 type fileService interface {
-	DownloadFile(ctx context.Context, fileID uuid.UUID) (*query.File, []byte, error)
+	DownloadFile(ctx context.Context, userAttr auth.UserAttr, fileID uuid.UUID) (*query.File, []byte, error)
 }
 
 type Service struct {
 	cfg         *config.Config
+	tx          transactor
 	store       store
 	provider    provider
 	fileService fileService
+	// wake tells an idle worker that an email has been queued
+	wake chan struct{}
+	// snsCerts caches the certificates SNS messages are signed with, by URL
+	snsCerts sync.Map
 }
 
+// NewService creates a new email service. The transactor queues an email
+// and its attachments together.
 func NewService(
 	cfg *config.Config,
+	tx transactor,
 	store store,
 	provider provider,
 	fileService fileService,
 ) *Service {
 	return &Service{
 		cfg:         cfg,
+		tx:          tx,
 		store:       store,
 		provider:    provider,
 		fileService: fileService,
+		wake:        make(chan struct{}, 1),
 	}
 }
 
@@ -51,13 +89,25 @@ type Response struct {
 	Attachments []query.EmailAttachment `json:"attachments"`
 }
 
+// GetEmails returns a user's emails, or only those with a status such as
+// StatusFailed when one is given
 func (s *Service) GetEmails(
 	ctx context.Context,
 	userID uuid.UUID,
+	status string,
 ) ([]Response, error) {
 	empty := make([]Response, 0)
 
-	emails, err := s.store.SelectEmails(ctx, userID)
+	var emails []query.Email
+	var err error
+	switch status {
+	case "":
+		emails, err = s.store.SelectEmails(ctx, userID)
+	case StatusPending, StatusSent, StatusFailed:
+		emails, err = s.store.SelectEmailsByStatus(ctx, query.SelectEmailsByStatusParams{UserID: userID, Status: status})
+	default:
+		return nil, pkg.BadRequestError{Message: "Invalid email status", Err: fmt.Errorf("unknown status %q", status)}
+	}
 	if err != nil {
 		return nil, pkg.InternalError{Message: "Error selecting emails", Err: err}
 	}
@@ -82,6 +132,22 @@ func (s *Service) GetEmails(
 	return emailResponses, nil
 }
 
+// Message is an email to queue. An email sent for an agency may name the
+// email log it is recorded in, which then follows its delivery.
+type Message struct {
+	UserID        uuid.UUID
+	AgencyID      uuid.UUID
+	To            string
+	Subject       string
+	Body          string
+	AttachmentIDs []uuid.UUID
+	EmailLogID    uuid.NullUUID
+	// Bulk is mail the recipient did not ask for, such as reminders. Bulk
+	// emails of an agency carry a one-click unsubscribe link.
+	Bulk bool
+}
+
+// SendEmail queues an email from a user, to be sent by the outbox workers
 func (s *Service) SendEmail(
 	ctx context.Context,
 	userID uuid.UUID,
@@ -90,6 +156,20 @@ func (s *Service) SendEmail(
 	emailBody string,
 	attachmentsIDs []uuid.UUID,
 ) (*query.Email, error) {
+	return s.Queue(ctx, Message{
+		UserID:        userID,
+		To:            emailTo,
+		Subject:       emailSubject,
+		Body:          emailBody,
+		AttachmentIDs: attachmentsIDs,
+	})
+}
+
+// Queue adds an email to the outbox. Attachments are checked now, and
+// fetched again when the email is sent. The email is sent by a worker,
+// with retries, so a failure of the provider does not lose it. An agency's
+// email to an address on its suppression list is refused.
+func (s *Service) Queue(ctx context.Context, msg Message) (*query.Email, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.cfg.ContextTimeout)
 	defer cancel()
 
@@ -98,109 +178,89 @@ func (s *Service) SendEmail(
 		return nil, pkg.InternalError{Message: "Error generating email ID", Err: err}
 	}
 
-	// If we habe attachments, we need to download them first
-	attachments := make([]Attachment, 0, len(attachmentsIDs))
-	if len(attachmentsIDs) > 0 {
-		for _, attachmentID := range attachmentsIDs {
-			file, data, err := s.fileService.DownloadFile(ctx, attachmentID)
-			if err != nil {
-				return nil, pkg.InternalError{Message: "Error downloading file", Err: err}
-			}
-			if file == nil {
-				return nil, pkg.InternalError{Message: "File not found", Err: nil}
-			}
-			if data == nil {
-				return nil, pkg.InternalError{Message: "File data is empty", Err: nil}
-			}
-			attachment := Attachment{
-				Filename:    file.FileName,
-				ContentType: file.ContentType,
-				Content:     data,
-			}
-			attachments = append(attachments, attachment)
-		}
-	}
-
 	params := query.InsertEmailParams{
 		ID:           id,
-		UserID:       userID,
-		EmailTo:      emailTo,
+		UserID:       msg.UserID,
+		EmailTo:      msg.To,
 		EmailFrom:    s.cfg.EmailFrom,
-		EmailSubject: emailSubject,
-		EmailBody:    emailBody,
+		EmailSubject: msg.Subject,
+		EmailBody:    msg.Body,
+		EmailLogID:   msg.EmailLogID,
+		AgencyID:     uuid.NullUUID{UUID: msg.AgencyID, Valid: msg.AgencyID != uuid.Nil},
 	}
 	err = validate(params)
 	if err != nil {
 		return nil, err
 	}
-
-	errorChan := make(chan error, 1)
-	go func() {
-		email := Email{
-			EmailTo:          params.EmailTo,
-			EmailSubject:     params.EmailSubject,
-			EmailBody:        params.EmailBody,
-			EmailAttachments: attachments,
+	if params.AgencyID.Valid {
+		if err := s.checkSuppressed(ctx, msg.AgencyID, msg.To); err != nil {
+			return nil, err
 		}
-		errorChan <- s.provider.Send(ctx, email)
-	}()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, pkg.InternalError{Message: "Timeout sending email", Err: ctx.Err()}
-		case err := <-errorChan:
-			if err != nil {
-				return nil, pkg.InternalError{Message: "Error sending email", Err: err}
-			}
-			email, err := s.store.InsertEmail(ctx, params)
+		if msg.Bulk {
+			token, err := newUnsubscribeToken()
 			if err != nil {
-				return nil, pkg.InternalError{Message: "Error inserting email", Err: err}
+				return nil, pkg.InternalError{Message: "Error generating unsubscribe token", Err: err}
 			}
-			for _, attachment := range attachments {
-				id, err := uuid.NewV7()
-				if err != nil {
-					return nil, pkg.InternalError{Message: "Error generating attachment ID", Err: err}
-				}
-				attachmentParams := query.InsertEmailAttachmentParams{
-					ID:          id,
-					EmailID:     email.ID,
-					FileName:    attachment.Filename,
-					ContentType: attachment.ContentType,
-				}
-				_, err = s.store.InsertEmailAttachment(ctx, attachmentParams)
-				if err != nil {
-					return nil, pkg.InternalError{Message: "Error inserting email attachment", Err: err}
-				}
-			}
-			return &email, nil
+			params.UnsubscribeToken = sql.NullString{String: token, Valid: true}
 		}
 	}
-}
 
-func (s *Service) SendTemplateEmail(
-	ctx context.Context,
-	templateID string,
-	to string,
-	data map[string]any,
-) error {
-	ctx, cancel := context.WithTimeout(ctx, s.cfg.ContextTimeout)
-	defer cancel()
+	// Email logs of other agencies are reported as missing
+	if msg.EmailLogID.Valid {
+		agencyID, err := s.store.SelectEmailLogAgencyID(ctx, msg.EmailLogID.UUID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.InternalError{Message: "Error selecting email log", Err: err}
+		}
+		if err != nil || agencyID != msg.AgencyID {
+			return nil, pkg.NotFoundError{
+				Message: "Email log not found",
+				Err:     fmt.Errorf("email log %s not found in agency %s", msg.EmailLogID.UUID, msg.AgencyID),
+			}
+		}
+	}
 
-	errorChan := make(chan error, 1)
-	go func() {
-		errorChan <- s.provider.SendTemplate(ctx, templateID, to, data)
-	}()
+	// Users may only attach their own files
+	attachments := make([]query.InsertEmailAttachmentParams, 0, len(msg.AttachmentIDs))
+	for _, attachmentID := range msg.AttachmentIDs {
+		file, _, err := s.fileService.DownloadFile(ctx, auth.UserAttr{ID: msg.UserID}, attachmentID)
+		if err != nil {
+			return nil, err
+		}
+		if file == nil {
+			return nil, pkg.InternalError{Message: "File not found", Err: fmt.Errorf("file %s not found", attachmentID)}
+		}
+		rowID, err := uuid.NewV7()
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error generating attachment ID", Err: err}
+		}
+		attachments = append(attachments, query.InsertEmailAttachmentParams{
+			ID:          rowID,
+			EmailID:     id,
+			FileName:    file.FileName,
+			ContentType: file.ContentType,
+			FileID:      uuid.NullUUID{UUID: file.ID, Valid: true},
+		})
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return pkg.InternalError{Message: "Timeout sending email", Err: ctx.Err()}
-		case err := <-errorChan:
-			if err != nil {
-				return pkg.InternalError{Message: "Error sending email", Err: err}
+	// The email and its attachments are inserted together, so a worker
+	// never sends the email without them and a failure leaves neither
+	var email query.Email
+	err = s.tx.InTx(ctx, func(q query.Querier) error {
+		var err error
+		email, err = q.InsertEmail(ctx, params)
+		if err != nil {
+			return pkg.InternalError{Message: "Error inserting email", Err: err}
+		}
+		for _, attachment := range attachments {
+			if _, err := q.InsertEmailAttachment(ctx, attachment); err != nil {
+				return pkg.InternalError{Message: "Error inserting email attachment", Err: err}
 			}
-			return nil
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	s.notify()
+	return &email, nil
 }
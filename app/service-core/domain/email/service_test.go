@@ -0,0 +1,371 @@
+package email_test
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"database/sql"
+	"maps"
+	"service-core/domain/email"
+	"service-core/storage/query"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type mockStore struct {
+	*query.Queries
+	mu          sync.Mutex
+	inserted    int
+	emails      map[uuid.UUID]query.Email
+	attachments []query.EmailAttachment
+	logs        map[uuid.UUID]query.UpdateEmailLogDeliveryParams
+	logAgencies map[uuid.UUID]uuid.UUID
+	events      []query.UpdateEmailLogEventParams
+	agencies    map[uuid.UUID]query.Agency
+	templates   []query.EmailTemplate
+	// recipients are the email logs of provider message IDs
+	recipients   map[string][]query.UpdateEmailLogEventRow
+	suppressions []query.EmailSuppression
+	// insertErr fails inserting an email
+	insertErr error
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{
+		emails:      map[uuid.UUID]query.Email{},
+		logs:        map[uuid.UUID]query.UpdateEmailLogDeliveryParams{},
+		logAgencies: map[uuid.UUID]uuid.UUID{},
+		agencies:    map[uuid.UUID]query.Agency{},
+		recipients:  map[string][]query.UpdateEmailLogEventRow{},
+	}
+}
+
+// InTx runs fn against the store, restoring the emails and attachments if
+// it fails, as rolling back would
+func (m *mockStore) InTx(ctx context.Context, fn func(q query.Querier) error) error {
+	m.mu.Lock()
+	emails, attachments := maps.Clone(m.emails), slices.Clone(m.attachments)
+	m.mu.Unlock()
+	if err := fn(m); err != nil {
+		m.mu.Lock()
+		m.emails, m.attachments = emails, attachments
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (m *mockStore) email(id uuid.UUID) query.Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.emails[id]
+}
+
+func (m *mockStore) SelectEmails(ctx context.Context, userID uuid.UUID) ([]query.Email, error) {
+	return nil, nil
+}
+
+func (m *mockStore) SelectEmailsByStatus(ctx context.Context, arg query.SelectEmailsByStatusParams) ([]query.Email, error) {
+	return nil, nil
+}
+
+func (m *mockStore) SelectEmailAttachments(ctx context.Context, emailID uuid.UUID) ([]query.EmailAttachment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var rows []query.EmailAttachment
+	for _, a := range m.attachments {
+		if a.EmailID == emailID {
+			rows = append(rows, a)
+		}
+	}
+	return rows, nil
+}
+
+func (m *mockStore) InsertEmail(ctx context.Context, params query.InsertEmailParams) (query.Email, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.insertErr != nil {
+		return query.Email{}, m.insertErr
+	}
+	m.inserted++
+	e := query.Email{
+		ID:               params.ID,
+		UserID:           params.UserID,
+		EmailTo:          params.EmailTo,
+		EmailSubject:     params.EmailSubject,
+		EmailBody:        params.EmailBody,
+		Status:           email.StatusPending,
+		NextAttemptAt:    time.Now(),
+		EmailLogID:       params.EmailLogID,
+		AgencyID:         params.AgencyID,
+		UnsubscribeToken: params.UnsubscribeToken,
+	}
+	m.emails[e.ID] = e
+	return e, nil
+}
+
+func (m *mockStore) InsertEmailAttachment(ctx context.Context, params query.InsertEmailAttachmentParams) (query.EmailAttachment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a := query.EmailAttachment{ID: params.ID, EmailID: params.EmailID, FileName: params.FileName, FileID: params.FileID}
+	m.attachments = append(m.attachments, a)
+	return a, nil
+}
+
+func (m *mockStore) ClaimEmails(ctx context.Context, arg query.ClaimEmailsParams) ([]query.Email, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for id, e := range m.emails {
+		if e.Status == email.StatusPending && !e.NextAttemptAt.After(now) && (!e.LockedUntil.Valid || e.LockedUntil.Time.Before(now)) {
+			e.LockedUntil = arg.LockedUntil
+			m.emails[id] = e
+			return []query.Email{e}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockStore) UpdateEmailSent(ctx context.Context, arg query.UpdateEmailSentParams) (query.Email, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.emails[arg.ID]
+	if !ok || e.Status != email.StatusPending || e.LockedUntil != arg.ClaimedUntil {
+		return query.Email{}, sql.ErrNoRows
+	}
+	e.Status = email.StatusSent
+	e.ProviderMessageID = arg.ProviderMessageID
+	e.SentAt = sql.NullTime{Time: time.Now(), Valid: true}
+	e.LockedUntil = sql.NullTime{}
+	m.emails[arg.ID] = e
+	return e, nil
+}
+
+func (m *mockStore) UpdateEmailFailed(ctx context.Context, arg query.UpdateEmailFailedParams) (query.Email, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.emails[arg.ID]
+	if !ok || e.Status != email.StatusPending || e.LockedUntil != arg.ClaimedUntil {
+		return query.Email{}, sql.ErrNoRows
+	}
+	e.Status = arg.Status
+	e.RetryCount++
+	e.ErrorMessage = arg.ErrorMessage
+	e.NextAttemptAt = arg.NextAttemptAt
+	e.LockedUntil = sql.NullTime{}
+	m.emails[arg.ID] = e
+	return e, nil
+}
+
+func (m *mockStore) RetryEmail(ctx context.Context, arg query.RetryEmailParams) (query.Email, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.emails[arg.ID]
+	if !ok || e.UserID != arg.UserID || e.Status != email.StatusFailed {
+		return query.Email{}, sql.ErrNoRows
+	}
+	e.Status = email.StatusPending
+	e.RetryCount = 0
+	e.NextAttemptAt = time.Now()
+	m.emails[arg.ID] = e
+	return e, nil
+}
+
+func (m *mockStore) SelectEmailLogAgencyID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	agencyID, ok := m.logAgencies[id]
+	if !ok {
+		return uuid.Nil, sql.ErrNoRows
+	}
+	return agencyID, nil
+}
+
+func (m *mockStore) UpdateEmailLogDelivery(ctx context.Context, arg query.UpdateEmailLogDeliveryParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logs[arg.ID] = arg
+	return nil
+}
+
+func (m *mockStore) UpdateEmailLogEvent(ctx context.Context, arg query.UpdateEmailLogEventParams) ([]query.UpdateEmailLogEventRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, arg)
+	return m.recipients[arg.ProviderMessageID], nil
+}
+
+func (m *mockStore) SelectAgency(ctx context.Context, id uuid.UUID) (query.Agency, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.agencies[id]
+	if !ok {
+		return query.Agency{}, sql.ErrNoRows
+	}
+	return a, nil
+}
+
+func (m *mockStore) SelectAgencyProfile(ctx context.Context, agencyID uuid.UUID) (query.AgencyProfile, error) {
+	return query.AgencyProfile{}, sql.ErrNoRows
+}
+
+func (m *mockStore) SelectEmailTemplate(ctx context.Context, arg query.SelectEmailTemplateParams) (query.EmailTemplate, error) {
+	versions, _ := m.SelectEmailTemplateVersions(ctx, query.SelectEmailTemplateVersionsParams(arg))
+	if len(versions) == 0 {
+		return query.EmailTemplate{}, sql.ErrNoRows
+	}
+	return versions[0], nil
+}
+
+func (m *mockStore) SelectEmailTemplates(ctx context.Context, agencyID uuid.UUID) ([]query.EmailTemplate, error) {
+	return nil, nil
+}
+
+func (m *mockStore) SelectEmailTemplateVersions(ctx context.Context, arg query.SelectEmailTemplateVersionsParams) ([]query.EmailTemplate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var rows []query.EmailTemplate
+	for _, t := range m.templates {
+		if t.AgencyID == arg.AgencyID && t.Name == arg.Name {
+			rows = append([]query.EmailTemplate{t}, rows...)
+		}
+	}
+	return rows, nil
+}
+
+func (m *mockStore) InsertEmailTemplate(ctx context.Context, arg query.InsertEmailTemplateParams) (query.EmailTemplate, error) {
+	versions, _ := m.SelectEmailTemplateVersions(ctx, query.SelectEmailTemplateVersionsParams{AgencyID: arg.AgencyID, Name: arg.Name})
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := query.EmailTemplate{
+		ID:        arg.ID,
+		CreatedAt: time.Now(),
+		AgencyID:  arg.AgencyID,
+		Name:      arg.Name,
+		Version:   int32(len(versions) + 1),
+		Subject:   arg.Subject,
+		Body:      arg.Body,
+		CreatedBy: arg.CreatedBy,
+	}
+	m.templates = append(m.templates, t)
+	return t, nil
+}
+
+func (m *mockStore) DeleteEmailTemplates(ctx context.Context, arg query.DeleteEmailTemplatesParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.templates[:0]
+	for _, t := range m.templates {
+		if t.AgencyID != arg.AgencyID || t.Name != arg.Name {
+			kept = append(kept, t)
+		}
+	}
+	n := int64(len(m.templates) - len(kept))
+	m.templates = kept
+	return n, nil
+}
+
+func (m *mockStore) SelectEmailSuppression(ctx context.Context, arg query.SelectEmailSuppressionParams) (query.EmailSuppression, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.suppressions {
+		if s.AgencyID == arg.AgencyID && s.Email == arg.Email {
+			return s, nil
+		}
+	}
+	return query.EmailSuppression{}, sql.ErrNoRows
+}
+
+func (m *mockStore) SelectEmailSuppressions(ctx context.Context, agencyID uuid.UUID) ([]query.EmailSuppression, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var rows []query.EmailSuppression
+	for _, s := range m.suppressions {
+		if s.AgencyID == agencyID {
+			rows = append(rows, s)
+		}
+	}
+	return rows, nil
+}
+
+func (m *mockStore) UpsertEmailSuppression(ctx context.Context, arg query.UpsertEmailSuppressionParams) (query.EmailSuppression, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, s := range m.suppressions {
+		if s.AgencyID == arg.AgencyID && s.Email == arg.Email {
+			m.suppressions[i].Reason, m.suppressions[i].Detail = arg.Reason, arg.Detail
+			return m.suppressions[i], nil
+		}
+	}
+	s := query.EmailSuppression{
+		ID:        arg.ID,
+		CreatedAt: time.Now(),
+		AgencyID:  arg.AgencyID,
+		Email:     arg.Email,
+		Reason:    arg.Reason,
+		Detail:    arg.Detail,
+	}
+	m.suppressions = append(m.suppressions, s)
+	return s, nil
+}
+
+func (m *mockStore) DeleteEmailSuppression(ctx context.Context, arg query.DeleteEmailSuppressionParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, s := range m.suppressions {
+		if s.ID == arg.ID && s.AgencyID == arg.AgencyID {
+			m.suppressions = append(m.suppressions[:i], m.suppressions[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *mockStore) SelectEmailByUnsubscribeToken(ctx context.Context, token sql.NullString) (query.Email, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.emails {
+		if e.UnsubscribeToken.Valid && e.UnsubscribeToken == token {
+			return e, nil
+		}
+	}
+	return query.Email{}, sql.ErrNoRows
+}
+
+type mockProvider struct {
+	mu   sync.Mutex
+	sent int
+	last email.Email
+	err  error
+}
+
+func (m *mockProvider) Send(ctx context.Context, e email.Email) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return "", m.err
+	}
+	m.sent++
+	m.last = e
+	return "message-id", nil
+}
+
+// mockFileService stands in for the file service, letting users download
+// the personal files they own
+type mockFileService struct {
+	files map[uuid.UUID]query.File
+}
+
+func (m *mockFileService) DownloadFile(ctx context.Context, userAttr auth.UserAttr, fileID uuid.UUID) (*query.File, []byte, error) {
+	f, ok := m.files[fileID]
+	if !ok {
+		return nil, nil, pkg.NotFoundError{Message: "Error selecting file by ID", Err: sql.ErrNoRows}
+	}
+	if err := auth.Authorize(auth.DownloadFile, userAttr, auth.Resource{OwnerID: f.UserID}); err != nil {
+		return nil, nil, err
+	}
+	return &f, []byte("content"), nil
+}
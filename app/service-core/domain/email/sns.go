@@ -0,0 +1,183 @@
+package email
+
+import (
+	"app/pkg"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // SNS signature version 1 is SHA1 with RSA
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// snsHost matches the hosts SNS signing certificates and subscription
+// confirmations are served from
+var snsHost = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// snsMessage is an SNS HTTP notification
+type snsMessage struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	SubscribeURL     string `json:"SubscribeURL"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+}
+
+// stringToSign returns the fields of a message that are signed, in the
+// order SNS signs them
+func (m *snsMessage) stringToSign() string {
+	fields := [][2]string{{"Message", m.Message}, {"MessageId", m.MessageID}}
+	if m.Type == "Notification" {
+		if m.Subject != "" {
+			fields = append(fields, [2]string{"Subject", m.Subject})
+		}
+		fields = append(fields, [2]string{"Timestamp", m.Timestamp}, [2]string{"TopicArn", m.TopicArn})
+	} else {
+		fields = append(fields,
+			[2]string{"SubscribeURL", m.SubscribeURL},
+			[2]string{"Timestamp", m.Timestamp},
+			[2]string{"Token", m.Token},
+			[2]string{"TopicArn", m.TopicArn},
+		)
+	}
+	fields = append(fields, [2]string{"Type", m.Type})
+	var b strings.Builder
+	for _, f := range fields {
+		b.WriteString(f[0] + "\n" + f[1] + "\n")
+	}
+	return b.String()
+}
+
+// snsNotification verifies an SNS message from the configured topic and
+// returns it. A subscription confirmation is confirmed, and returned like
+// any other message.
+func (s *Service) snsNotification(ctx context.Context, body []byte) (*snsMessage, error) {
+	var m snsMessage
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid webhook payload", Err: err}
+	}
+	if m.TopicArn != s.cfg.SesWebhookTopicArn {
+		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("SNS message from unexpected topic %q", m.TopicArn)}
+	}
+	// The timestamp is signed, so an old message is refused as a replay
+	// before its certificate is fetched
+	at, err := time.Parse(time.RFC3339, m.Timestamp)
+	if err != nil {
+		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("invalid SNS timestamp: %w", err)}
+	}
+	if err := checkWebhookTime(at); err != nil {
+		return nil, err
+	}
+	if err := s.verifySNS(ctx, &m); err != nil {
+		return nil, err
+	}
+	if m.Type == "SubscriptionConfirmation" {
+		if err := confirmSNSSubscription(ctx, m.SubscribeURL); err != nil {
+			return nil, pkg.InternalError{Message: "Error confirming SNS subscription", Err: err}
+		}
+	}
+	return &m, nil
+}
+
+// verifySNS checks an SNS message's signature with the certificate it
+// names, which must be served by SNS
+func (s *Service) verifySNS(ctx context.Context, m *snsMessage) error {
+	var hash crypto.Hash
+	var digest []byte
+	switch m.SignatureVersion {
+	case "1":
+		sum := sha1.Sum([]byte(m.stringToSign())) //nolint:gosec // Required by signature version 1
+		hash, digest = crypto.SHA1, sum[:]
+	case "2":
+		sum := sha256.Sum256([]byte(m.stringToSign()))
+		hash, digest = crypto.SHA256, sum[:]
+	default:
+		return pkg.UnauthorizedError{Err: fmt.Errorf("unsupported SNS signature version %q", m.SignatureVersion)}
+	}
+	signature, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return pkg.UnauthorizedError{Err: err}
+	}
+	cert, err := s.snsCertificate(ctx, m.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	publicKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return pkg.UnauthorizedError{Err: errors.New("SNS signing certificate does not have an RSA key")}
+	}
+	if err := rsa.VerifyPKCS1v15(publicKey, hash, digest, signature); err != nil {
+		return pkg.UnauthorizedError{Err: fmt.Errorf("invalid SNS signature: %w", err)}
+	}
+	return nil
+}
+
+// snsCertificate returns the signing certificate at a URL, fetching it
+// the first time it is used
+func (s *Service) snsCertificate(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	if cert, ok := s.snsCerts.Load(certURL); ok {
+		return cert.(*x509.Certificate), nil
+	}
+	u, err := url.Parse(certURL)
+	if err != nil || u.Scheme != "https" || !snsHost.MatchString(u.Host) || !strings.HasSuffix(u.Path, ".pem") {
+		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("SNS signing certificate URL %q is not served by SNS", certURL)}
+	}
+	body, err := snsGet(ctx, u.String())
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error fetching SNS signing certificate", Err: err}
+	}
+	block, _ := pem.Decode(body)
+	if block == nil {
+		return nil, pkg.InternalError{Message: "Error parsing SNS signing certificate", Err: errors.New("no PEM block")}
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error parsing SNS signing certificate", Err: err}
+	}
+	s.snsCerts.Store(certURL, cert)
+	return cert, nil
+}
+
+// confirmSNSSubscription visits the URL that confirms a subscription to a
+// topic, so SNS starts sending its notifications
+func confirmSNSSubscription(ctx context.Context, subscribeURL string) error {
+	u, err := url.Parse(subscribeURL)
+	if err != nil || u.Scheme != "https" || !snsHost.MatchString(u.Host) {
+		return fmt.Errorf("subscribe URL %q is not served by SNS", subscribeURL)
+	}
+	_, err = snsGet(ctx, u.String())
+	return err
+}
+
+func snsGet(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status: %d", res.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(res.Body, 1<<20))
+}
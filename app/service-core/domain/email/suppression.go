@@ -0,0 +1,211 @@
+package email
+
+import (
+	"app/pkg"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/mail"
+	"service-core/storage/query"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reasons an address is suppressed
+const (
+	SuppressionBounced      = "bounced"
+	SuppressionComplained   = "complained"
+	SuppressionUnsubscribed = "unsubscribed"
+	SuppressionManual       = "manual"
+)
+
+// suppressionMessages explain to the sender why an address is refused
+var suppressionMessages = map[string]string{
+	SuppressionBounced:      "Emails to this address bounced",
+	SuppressionComplained:   "The recipient marked an email as spam",
+	SuppressionUnsubscribed: "The recipient unsubscribed",
+	SuppressionManual:       "This address is on the suppression list",
+}
+
+// Suppression is an address an agency no longer sends to
+type Suppression struct {
+	ID        uuid.UUID `json:"id"`
+	Email     string    `json:"email"`
+	Reason    string    `json:"reason"`
+	Detail    string    `json:"detail"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SuppressionRequest adds an address to an agency's suppression list
+type SuppressionRequest struct {
+	Email  string `json:"email"`
+	Detail string `json:"detail"`
+}
+
+// ListSuppressions returns an agency's suppressed addresses, newest first
+func (s *Service) ListSuppressions(ctx context.Context, agencyID uuid.UUID) ([]Suppression, error) {
+	rows, err := s.store.SelectEmailSuppressions(ctx, agencyID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting email suppressions", Err: err}
+	}
+	suppressions := make([]Suppression, len(rows))
+	for i, row := range rows {
+		suppressions[i] = toSuppression(row)
+	}
+	return suppressions, nil
+}
+
+// AddSuppression suppresses an address for an agency
+func (s *Service) AddSuppression(ctx context.Context, agencyID uuid.UUID, req SuppressionRequest) (*Suppression, error) {
+	address, err := normalizeAddress(req.Email)
+	if err != nil {
+		return nil, pkg.ValidationErrors{{Field: "email", Tag: "email", Message: "Invalid email address"}}
+	}
+	row, err := s.suppress(ctx, agencyID, address, SuppressionManual, strings.TrimSpace(req.Detail))
+	if err != nil {
+		return nil, err
+	}
+	suppression := toSuppression(row)
+	return &suppression, nil
+}
+
+// RemoveSuppression lets an agency send to a suppressed address again
+func (s *Service) RemoveSuppression(ctx context.Context, agencyID, id uuid.UUID) error {
+	n, err := s.store.DeleteEmailSuppression(ctx, query.DeleteEmailSuppressionParams{ID: id, AgencyID: agencyID})
+	if err != nil {
+		return pkg.InternalError{Message: "Error deleting email suppression", Err: err}
+	}
+	if n == 0 {
+		return pkg.NotFoundError{Message: "Email suppression not found", Err: fmt.Errorf("suppression %s not found in agency %s", id, agencyID)}
+	}
+	return nil
+}
+
+// UnsubscribePage renders the page a recipient reaches from the
+// unsubscribe link of a bulk email. The page asks the recipient to
+// confirm, and once they have, unsubscribe suppresses their address for
+// the agency that sent the email.
+func (s *Service) UnsubscribePage(ctx context.Context, token string, unsubscribe bool) (string, error) {
+	e, err := s.store.SelectEmailByUnsubscribeToken(ctx, sql.NullString{String: token, Valid: token != ""})
+	if err != nil || !e.AgencyID.Valid {
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return "", pkg.InternalError{Message: "Error selecting email", Err: err}
+		}
+		return "", pkg.NotFoundError{Message: "Unsubscribe link not found", Err: errors.New("unknown unsubscribe token")}
+	}
+	address, err := normalizeAddress(e.EmailTo)
+	if err != nil {
+		return "", pkg.InternalError{Message: "Invalid recipient address", Err: err}
+	}
+	if unsubscribe {
+		if _, err := s.suppress(ctx, e.AgencyID.UUID, address, SuppressionUnsubscribed, ""); err != nil {
+			return "", err
+		}
+	}
+	brand, err := s.brand(ctx, e.AgencyID.UUID)
+	if err != nil {
+		return "", err
+	}
+	rendered, err := render("Unsubscribe", defaultBody("unsubscribe"), brand, map[string]any{
+		"Email":        address,
+		"Unsubscribed": unsubscribe,
+	})
+	if err != nil {
+		return "", pkg.InternalError{Message: "Error rendering unsubscribe page", Err: err}
+	}
+	return rendered.HTML, nil
+}
+
+// checkSuppressed refuses an address an agency no longer sends to
+func (s *Service) checkSuppressed(ctx context.Context, agencyID uuid.UUID, to string) error {
+	address, err := normalizeAddress(to)
+	if err != nil {
+		return pkg.ValidationErrors{{Field: "email_to", Tag: "email", Message: "Invalid email address"}}
+	}
+	row, err := s.store.SelectEmailSuppression(ctx, query.SelectEmailSuppressionParams{AgencyID: agencyID, Email: address})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return pkg.InternalError{Message: "Error selecting email suppression", Err: err}
+	}
+	return pkg.ValidationErrors{{
+		Field:   "email_to",
+		Tag:     "suppressed",
+		Message: suppressionMessages[row.Reason] + ", so it is no longer sent to. Remove it from the suppression list to send to it again.",
+	}}
+}
+
+// suppress adds an address to an agency's suppression list
+func (s *Service) suppress(ctx context.Context, agencyID uuid.UUID, address, reason, detail string) (query.EmailSuppression, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return query.EmailSuppression{}, pkg.InternalError{Message: "Error generating suppression ID", Err: err}
+	}
+	row, err := s.store.UpsertEmailSuppression(ctx, query.UpsertEmailSuppressionParams{
+		ID:       id,
+		AgencyID: agencyID,
+		Email:    address,
+		Reason:   reason,
+		Detail:   detail,
+	})
+	if err != nil {
+		return query.EmailSuppression{}, pkg.InternalError{Message: "Error inserting email suppression", Err: err}
+	}
+	return row, nil
+}
+
+// suppressionReason returns why an event suppresses the recipient of the
+// email, if it does. Soft bounces, such as a full mailbox, do not.
+func suppressionReason(e Event) string {
+	switch {
+	case e.Type == EventComplained:
+		return SuppressionComplained
+	case e.Type == EventBounced && e.Hard:
+		return SuppressionBounced
+	default:
+		return ""
+	}
+}
+
+// unsubscribeHeaders are the headers of a one-click unsubscribe link
+// (RFC 8058), which mail clients post to without opening the page
+func (s *Service) unsubscribeHeaders(token string) map[string]string {
+	return map[string]string{
+		"List-Unsubscribe":      "<" + s.cfg.CoreURL + "/api/v1/email-unsubscribe/" + token + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+}
+
+func newUnsubscribeToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// normalizeAddress returns the lower case address of a recipient, which
+// may include their name
+func normalizeAddress(to string) (string, error) {
+	addr, err := mail.ParseAddress(to)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(addr.Address), nil
+}
+
+func toSuppression(row query.EmailSuppression) Suppression {
+	return Suppression{
+		ID:        row.ID,
+		Email:     row.Email,
+		Reason:    row.Reason,
+		Detail:    row.Detail,
+		CreatedAt: row.CreatedAt,
+	}
+}
@@ -0,0 +1,210 @@
+package email_test
+
+import (
+	"app/pkg"
+	"context"
+	"errors"
+	"net/http"
+	"service-core/config"
+	"service-core/domain/email"
+	"service-core/storage/query"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestQueueSuppressed(t *testing.T) {
+	t.Parallel()
+	agencyID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	otherID := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+
+	tests := []struct {
+		name     string
+		agencyID uuid.UUID
+		to       string
+		want     bool
+	}{
+		{"suppressed address", agencyID, "jane@example.com", true},
+		{"suppressed address with name and capitals", agencyID, "Jane <JANE@Example.com>", true},
+		{"other address", agencyID, "sam@example.com", false},
+		{"other agency", otherID, "jane@example.com", false},
+		{"no agency", uuid.Nil, "jane@example.com", false},
+	}
+	cfg := &config.Config{ContextTimeout: time.Second}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newMockStore()
+			store.suppressions = []query.EmailSuppression{
+				{ID: uuid.New(), AgencyID: agencyID, Email: "jane@example.com", Reason: email.SuppressionBounced},
+			}
+			s := email.NewService(cfg, store, store, &mockProvider{}, &mockFileService{})
+
+			_, err := s.Queue(context.Background(), email.Message{
+				AgencyID: tt.agencyID,
+				To:       tt.to,
+				Subject:  "Subject",
+				Body:     "Body",
+			})
+			if !tt.want {
+				if err != nil {
+					t.Fatalf("Queue() = %v, want nil", err)
+				}
+				return
+			}
+			var v pkg.ValidationErrors
+			if !errors.As(err, &v) || len(v) != 1 || v[0].Field != "email_to" || v[0].Tag != "suppressed" {
+				t.Fatalf("Queue() = %v, want a suppressed email_to error", err)
+			}
+			if !strings.Contains(v[0].Message, "bounced") {
+				t.Errorf("Message = %q, want the reason", v[0].Message)
+			}
+			if store.inserted > 0 {
+				t.Fatal("Queue() queued an email to a suppressed address")
+			}
+		})
+	}
+}
+
+func TestWebhookSuppresses(t *testing.T) {
+	t.Parallel()
+	agencyID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	tests := []struct {
+		name       string
+		body       string
+		wantReason string
+	}{
+		{"hard bounce", `{"RecordType":"Bounce","MessageID":"pm_1","Type":"HardBounce","Description":"Mailbox does not exist"}`, email.SuppressionBounced},
+		{"soft bounce", `{"RecordType":"Bounce","MessageID":"pm_1","Type":"SoftBounce","Description":"Mailbox full"}`, ""},
+		{"complaint", `{"RecordType":"SpamComplaint","MessageID":"pm_1"}`, email.SuppressionComplained},
+		{"delivery", `{"RecordType":"Delivery","MessageID":"pm_1"}`, ""},
+	}
+	cfg := &config.Config{
+		ContextTimeout:          time.Second,
+		PostmarkWebhookUsername: "postmark",
+		PostmarkWebhookPassword: "password",
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newMockStore()
+			store.recipients["pm_1"] = []query.UpdateEmailLogEventRow{{AgencyID: agencyID, RecipientEmail: "Jane@Example.com"}}
+			s := email.NewService(cfg, store, store, &mockProvider{}, nil)
+
+			req := &http.Request{Header: http.Header{}}
+			req.SetBasicAuth("postmark", "password")
+			if err := s.HandleWebhook(context.Background(), "postmark", req.Header, []byte(tt.body)); err != nil {
+				t.Fatalf("HandleWebhook() = %v", err)
+			}
+			suppressions, err := s.ListSuppressions(context.Background(), agencyID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantReason == "" {
+				if len(suppressions) > 0 {
+					t.Fatalf("suppressions = %+v, want none", suppressions)
+				}
+				return
+			}
+			if len(suppressions) != 1 || suppressions[0].Email != "jane@example.com" || suppressions[0].Reason != tt.wantReason {
+				t.Fatalf("suppressions = %+v, want jane@example.com %s", suppressions, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	t.Parallel()
+	agencyID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	cfg := &config.Config{
+		ContextTimeout:    time.Second,
+		CoreURL:           "https://core.example.com",
+		EmailWorkers:      1,
+		EmailPollInterval: 10 * time.Millisecond,
+	}
+	store := newMockStore()
+	store.agencies[agencyID] = query.Agency{ID: agencyID, Name: "Acme Studio"}
+	provider := &mockProvider{}
+	s := email.NewService(cfg, store, store, provider, &mockFileService{})
+	ctx := context.Background()
+	msg := email.Message{AgencyID: agencyID, To: "jane@example.com", Subject: "Reminder", Body: "<p>Reminder</p>", Bulk: true}
+
+	queued, err := s.Queue(ctx, msg)
+	if err != nil {
+		t.Fatalf("Queue() = %v", err)
+	}
+	if !queued.UnsubscribeToken.Valid {
+		t.Fatal("bulk email has no unsubscribe token")
+	}
+	token := queued.UnsubscribeToken.String
+
+	// The worker sends the email with the one-click unsubscribe headers
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		s.Run(runCtx)
+		close(done)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for store.email(queued.ID).Status == email.StatusPending {
+		if time.Now().After(deadline) {
+			t.Fatal("email was not sent")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	provider.mu.Lock()
+	headers := provider.last.Headers
+	provider.mu.Unlock()
+	if want := "<https://core.example.com/api/v1/email-unsubscribe/" + token + ">"; headers["List-Unsubscribe"] != want {
+		t.Errorf("List-Unsubscribe = %q, want %q", headers["List-Unsubscribe"], want)
+	}
+	if headers["List-Unsubscribe-Post"] != "List-Unsubscribe=One-Click" {
+		t.Errorf("List-Unsubscribe-Post = %q", headers["List-Unsubscribe-Post"])
+	}
+
+	// Opening the link asks for confirmation without unsubscribing
+	page, err := s.UnsubscribePage(ctx, token, false)
+	if err != nil {
+		t.Fatalf("UnsubscribePage() = %v", err)
+	}
+	if !strings.Contains(page, "<form") || !strings.Contains(page, "Acme Studio") {
+		t.Errorf("confirmation page does not ask to unsubscribe from Acme Studio:\n%s", page)
+	}
+	if _, err := s.Queue(ctx, msg); err != nil {
+		t.Fatalf("Queue() before unsubscribing = %v", err)
+	}
+
+	page, err = s.UnsubscribePage(ctx, token, true)
+	if err != nil {
+		t.Fatalf("UnsubscribePage() = %v", err)
+	}
+	if !strings.Contains(page, "You have been unsubscribed") {
+		t.Errorf("page does not confirm the unsubscribe:\n%s", page)
+	}
+	var v pkg.ValidationErrors
+	if _, err := s.Queue(ctx, msg); !errors.As(err, &v) {
+		t.Fatalf("Queue() after unsubscribing = %v, want a validation error", err)
+	}
+
+	// Removing the address from the list lets the agency send to it again
+	suppressions, err := s.ListSuppressions(ctx, agencyID)
+	if err != nil || len(suppressions) != 1 || suppressions[0].Reason != email.SuppressionUnsubscribed {
+		t.Fatalf("ListSuppressions() = %+v, %v, want the unsubscribe", suppressions, err)
+	}
+	if err := s.RemoveSuppression(ctx, agencyID, suppressions[0].ID); err != nil {
+		t.Fatalf("RemoveSuppression() = %v", err)
+	}
+	if _, err := s.Queue(ctx, msg); err != nil {
+		t.Fatalf("Queue() after removing the suppression = %v", err)
+	}
+
+	var notFound pkg.NotFoundError
+	if _, err := s.UnsubscribePage(ctx, "unknown", true); !errors.As(err, &notFound) {
+		t.Errorf("UnsubscribePage() with an unknown token = %v, want NotFoundError", err)
+	}
+}
@@ -0,0 +1,493 @@
+package email
+
+import (
+	"app/pkg"
+	"bytes"
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"maps"
+	"service-core/storage/query"
+	"slices"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Built-in templates
+const (
+	TemplateLogin        = "login"
+	TemplateProposalSent = "proposal_sent"
+	TemplateInvoiceDue   = "invoice_due"
+)
+
+// maxTemplateSize caps the subject and body of a saved template
+const maxTemplateSize = 64 << 10
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+// definition is a built-in template. Its body is in templates/<name>.html.
+type definition struct {
+	description string
+	subject     string
+	// sample is the data previews are rendered with, and documents the
+	// variables the template is given
+	sample map[string]any
+}
+
+var definitions = map[string]definition{
+	TemplateLogin: {
+		description: "Magic link sent when a user signs in with their email",
+		subject:     "Sign in to {{.Brand.Name}}",
+		sample: map[string]any{
+			"LoginURL": "https://example.com/login-callback/email?state=preview",
+		},
+	},
+	TemplateProposalSent: {
+		description: "Sent to a client with a link to their proposal",
+		subject:     "Proposal {{.ProposalNumber}} from {{.Brand.Name}}",
+		sample: map[string]any{
+			"ClientName":     "Jane Citizen",
+			"ProposalNumber": "PROP-0001",
+			"ProposalURL":    "https://example.com/p/preview",
+			"ValidUntil":     "31 March 2026",
+			"Message":        "",
+		},
+	},
+	TemplateInvoiceDue: {
+		description: "Reminds a client that an invoice is due",
+		subject:     "Invoice {{.InvoiceNumber}} is due {{.DueDate}}",
+		sample: map[string]any{
+			"ClientName":    "Jane Citizen",
+			"InvoiceNumber": "INV-0001",
+			"Amount":        "$1,650.00",
+			"DueDate":       "14 March 2026",
+			"InvoiceURL":    "https://example.com/i/preview",
+			"Message":       "",
+		},
+	},
+}
+
+// platformBrand is the branding of emails that are not sent for an agency
+var platformBrand = Brand{
+	Name:         "Webkit",
+	PrimaryColor: "#6366f1",
+	AccentColor:  "#F59E0B",
+}
+
+var templateFuncs = template.FuncMap{
+	// dict builds the data of a partial from pairs of keys and values
+	"dict": func(pairs ...any) (map[string]any, error) {
+		if len(pairs)%2 != 0 {
+			return nil, errors.New("dict needs pairs of keys and values")
+		}
+		m := make(map[string]any, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			key, ok := pairs[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("dict key %v is not a string", pairs[i])
+			}
+			m[key] = pairs[i+1]
+		}
+		return m, nil
+	},
+	"year": func() int { return time.Now().Year() },
+}
+
+// layout is the shared layout and partials every template is rendered in
+var layout = template.Must(template.New("layout").Funcs(templateFuncs).ParseFS(templateFiles, "templates/layout.html"))
+
+// Brand is the agency identity templates are given as .Brand
+type Brand struct {
+	Name         string `json:"name"`
+	LogoURL      string `json:"logoUrl"`
+	PrimaryColor string `json:"primaryColor"`
+	AccentColor  string `json:"accentColor"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	Website      string `json:"website"`
+	Address      string `json:"address"`
+}
+
+// Template is an email template: an agency's latest version, or the
+// built-in template at version 0
+type Template struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Version     int32      `json:"version"`
+	Customized  bool       `json:"customized"`
+	Subject     string     `json:"subject"`
+	Body        string     `json:"body"`
+	Variables   []string   `json:"variables"`
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+}
+
+// TemplateRequest is a new version of a template
+type TemplateRequest struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// PreviewRequest renders a template with sample data. A subject or body
+// previews unsaved changes, and data replaces sample values.
+type PreviewRequest struct {
+	Subject *string        `json:"subject"`
+	Body    *string        `json:"body"`
+	Data    map[string]any `json:"data"`
+}
+
+// Rendered is a rendered email, with a plain text alternative of its HTML
+type Rendered struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// TemplateMessage is an email rendered from a template. A nil agency ID
+// renders the built-in template with the platform's branding.
+type TemplateMessage struct {
+	UserID     uuid.UUID
+	AgencyID   uuid.UUID
+	To         string
+	Template   string
+	Data       map[string]any
+	EmailLogID uuid.NullUUID
+	Bulk       bool
+}
+
+// SendTemplateEmail renders an agency's template and queues the email
+func (s *Service) SendTemplateEmail(ctx context.Context, msg TemplateMessage) (*query.Email, error) {
+	rendered, err := s.RenderTemplate(ctx, msg.AgencyID, msg.Template, msg.Data)
+	if err != nil {
+		return nil, err
+	}
+	return s.Queue(ctx, Message{
+		UserID:     msg.UserID,
+		AgencyID:   msg.AgencyID,
+		To:         msg.To,
+		Subject:    rendered.Subject,
+		Body:       rendered.HTML,
+		EmailLogID: msg.EmailLogID,
+		Bulk:       msg.Bulk,
+	})
+}
+
+// RenderTemplate renders an agency's latest version of a template in its
+// branding. An agency template that no longer renders, such as one using a
+// variable that has since been removed, falls back to the built-in one so
+// the email is still sent.
+func (s *Service) RenderTemplate(ctx context.Context, agencyID uuid.UUID, name string, data map[string]any) (*Rendered, error) {
+	t, err := s.template(ctx, agencyID, name)
+	if err != nil {
+		return nil, err
+	}
+	brand, err := s.brand(ctx, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := render(t.Subject, t.Body, brand, data)
+	if err != nil && t.Customized {
+		slog.Error("Error rendering agency email template, using the built-in template", "error", err, "agency_id", agencyID, "template", name, "version", t.Version)
+		d := definitions[name]
+		rendered, err = render(d.subject, defaultBody(name), brand, data)
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error rendering email template", Err: err}
+	}
+	return rendered, nil
+}
+
+// ListTemplates returns every template as the agency sends it
+func (s *Service) ListTemplates(ctx context.Context, agencyID uuid.UUID) ([]Template, error) {
+	rows, err := s.store.SelectEmailTemplates(ctx, agencyID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting email templates", Err: err}
+	}
+	custom := make(map[string]query.EmailTemplate, len(rows))
+	for _, row := range rows {
+		custom[row.Name] = row
+	}
+	templates := make([]Template, 0, len(definitions))
+	for _, name := range slices.Sorted(maps.Keys(definitions)) {
+		if row, ok := custom[name]; ok {
+			templates = append(templates, customTemplate(row))
+			continue
+		}
+		templates = append(templates, builtInTemplate(name))
+	}
+	return templates, nil
+}
+
+// GetTemplate returns a template as the agency sends it
+func (s *Service) GetTemplate(ctx context.Context, agencyID uuid.UUID, name string) (*Template, error) {
+	t, err := s.template(ctx, agencyID, name)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetTemplateVersions returns every version an agency has saved of a
+// template, newest first
+func (s *Service) GetTemplateVersions(ctx context.Context, agencyID uuid.UUID, name string) ([]Template, error) {
+	if _, ok := definitions[name]; !ok {
+		return nil, templateNotFound(name)
+	}
+	rows, err := s.store.SelectEmailTemplateVersions(ctx, query.SelectEmailTemplateVersionsParams{AgencyID: agencyID, Name: name})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting email template versions", Err: err}
+	}
+	versions := make([]Template, len(rows))
+	for i, row := range rows {
+		versions[i] = customTemplate(row)
+	}
+	return versions, nil
+}
+
+// SaveTemplate saves a new version of an agency's template, once it has
+// been checked to render with the template's variables
+func (s *Service) SaveTemplate(ctx context.Context, agencyID, userID uuid.UUID, name string, req TemplateRequest) (*Template, error) {
+	d, ok := definitions[name]
+	if !ok {
+		return nil, templateNotFound(name)
+	}
+	if err := validateTemplate(req, d); err != nil {
+		return nil, err
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating template ID", Err: err}
+	}
+	row, err := s.store.InsertEmailTemplate(ctx, query.InsertEmailTemplateParams{
+		ID:        id,
+		AgencyID:  agencyID,
+		Name:      name,
+		Subject:   req.Subject,
+		Body:      req.Body,
+		CreatedBy: uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error inserting email template", Err: err}
+	}
+	t := customTemplate(row)
+	return &t, nil
+}
+
+// RevertTemplate removes every version of an agency's template, so the
+// built-in template is sent again
+func (s *Service) RevertTemplate(ctx context.Context, agencyID uuid.UUID, name string) error {
+	if _, ok := definitions[name]; !ok {
+		return templateNotFound(name)
+	}
+	n, err := s.store.DeleteEmailTemplates(ctx, query.DeleteEmailTemplatesParams{AgencyID: agencyID, Name: name})
+	if err != nil {
+		return pkg.InternalError{Message: "Error deleting email template", Err: err}
+	}
+	if n == 0 {
+		return pkg.NotFoundError{Message: "Email template has not been customized", Err: fmt.Errorf("agency %s has no %s template", agencyID, name)}
+	}
+	return nil
+}
+
+// PreviewTemplate renders a template in the agency's branding with sample
+// data, without sending it
+func (s *Service) PreviewTemplate(ctx context.Context, agencyID uuid.UUID, name string, req PreviewRequest) (*Rendered, error) {
+	t, err := s.template(ctx, agencyID, name)
+	if err != nil {
+		return nil, err
+	}
+	if req.Subject != nil {
+		t.Subject = *req.Subject
+	}
+	if req.Body != nil {
+		t.Body = *req.Body
+	}
+	brand, err := s.brand(ctx, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	data := maps.Clone(definitions[name].sample)
+	maps.Copy(data, req.Data)
+	rendered, err := render(t.Subject, t.Body, brand, data)
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: "Error rendering email template: " + err.Error(), Err: err}
+	}
+	return rendered, nil
+}
+
+// template returns an agency's latest version of a template, or the
+// built-in one
+func (s *Service) template(ctx context.Context, agencyID uuid.UUID, name string) (Template, error) {
+	if _, ok := definitions[name]; !ok {
+		return Template{}, templateNotFound(name)
+	}
+	if agencyID == uuid.Nil {
+		return builtInTemplate(name), nil
+	}
+	row, err := s.store.SelectEmailTemplate(ctx, query.SelectEmailTemplateParams{AgencyID: agencyID, Name: name})
+	if errors.Is(err, sql.ErrNoRows) {
+		return builtInTemplate(name), nil
+	}
+	if err != nil {
+		return Template{}, pkg.InternalError{Message: "Error selecting email template", Err: err}
+	}
+	return customTemplate(row), nil
+}
+
+// brand returns the branding of an agency's emails, from its details and
+// business profile
+func (s *Service) brand(ctx context.Context, agencyID uuid.UUID) (Brand, error) {
+	if agencyID == uuid.Nil {
+		return platformBrand, nil
+	}
+	agency, err := s.store.SelectAgency(ctx, agencyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Brand{}, pkg.NotFoundError{Message: "Agency not found", Err: err}
+		}
+		return Brand{}, pkg.InternalError{Message: "Error selecting agency", Err: err}
+	}
+	profile, err := s.store.SelectAgencyProfile(ctx, agencyID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Brand{}, pkg.InternalError{Message: "Error selecting agency profile", Err: err}
+	}
+	brand := Brand{
+		Name:         agency.Name,
+		LogoURL:      agency.LogoUrl,
+		PrimaryColor: agency.PrimaryColor,
+		AccentColor:  agency.AccentColor,
+		Email:        agency.Email,
+		Phone:        agency.Phone,
+		Website:      agency.Website,
+	}
+	if profile.TradingName != "" {
+		brand.Name = profile.TradingName
+	}
+	var address []string
+	for _, part := range []string{profile.AddressLine1, profile.AddressLine2, profile.City, profile.State, profile.Postcode} {
+		if part != "" {
+			address = append(address, part)
+		}
+	}
+	brand.Address = strings.Join(address, ", ")
+	return brand, nil
+}
+
+// renderError is an error in the subject or body of a template
+type renderError struct {
+	field string
+	err   error
+}
+
+func (e renderError) Error() string {
+	return e.field + ": " + e.err.Error()
+}
+
+// render renders a subject and body with the layout, giving templates the
+// data and the brand as .Brand
+func render(subject, body string, brand Brand, data map[string]any) (*Rendered, error) {
+	values := make(map[string]any, len(data)+2)
+	maps.Copy(values, data)
+	values["Brand"] = brand
+
+	st, err := texttemplate.New("subject").Funcs(texttemplate.FuncMap(templateFuncs)).Parse(subject)
+	if err != nil {
+		return nil, renderError{"subject", err}
+	}
+	var subjectBuf bytes.Buffer
+	if err := st.Execute(&subjectBuf, values); err != nil {
+		return nil, renderError{"subject", err}
+	}
+	values["Subject"] = strings.TrimSpace(subjectBuf.String())
+
+	bt, err := layout.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := bt.New("content").Parse(body); err != nil {
+		return nil, renderError{"body", err}
+	}
+	var html bytes.Buffer
+	if err := bt.ExecuteTemplate(&html, "layout", values); err != nil {
+		return nil, renderError{"body", err}
+	}
+	return &Rendered{
+		Subject: values["Subject"].(string),
+		HTML:    html.String(),
+		Text:    htmlText(html.String()),
+	}, nil
+}
+
+// validateTemplate checks that a template renders with the sample data of
+// its definition
+func validateTemplate(req TemplateRequest, d definition) error {
+	v := pkg.ValidationErrors{}
+	if strings.TrimSpace(req.Subject) == "" {
+		v = append(v, pkg.ValidationError{Field: "subject", Tag: "required", Message: "Subject is required"})
+	} else if len(req.Subject) > maxTemplateSize {
+		v = append(v, pkg.ValidationError{Field: "subject", Tag: "max", Message: "Subject is too long"})
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		v = append(v, pkg.ValidationError{Field: "body", Tag: "required", Message: "Body is required"})
+	} else if len(req.Body) > maxTemplateSize {
+		v = append(v, pkg.ValidationError{Field: "body", Tag: "max", Message: "Body is too long"})
+	}
+	if len(v) > 0 {
+		return v
+	}
+	if _, err := render(req.Subject, req.Body, platformBrand, d.sample); err != nil {
+		var rerr renderError
+		if !errors.As(err, &rerr) {
+			return pkg.InternalError{Message: "Error rendering email template", Err: err}
+		}
+		return pkg.ValidationErrors{{Field: rerr.field, Tag: "template", Message: "Template does not render: " + rerr.err.Error()}}
+	}
+	return nil
+}
+
+func builtInTemplate(name string) Template {
+	d := definitions[name]
+	return Template{
+		Name:        name,
+		Description: d.description,
+		Subject:     d.subject,
+		Body:        defaultBody(name),
+		Variables:   variables(d),
+	}
+}
+
+func customTemplate(row query.EmailTemplate) Template {
+	t := builtInTemplate(row.Name)
+	t.Version = row.Version
+	t.Customized = true
+	t.Subject = row.Subject
+	t.Body = row.Body
+	t.UpdatedAt = &row.CreatedAt
+	return t
+}
+
+// defaultBody returns the body of a built-in template
+func defaultBody(name string) string {
+	b, err := templateFiles.ReadFile("templates/" + name + ".html")
+	if err != nil {
+		panic(fmt.Sprintf("missing built-in email template %s: %v", name, err))
+	}
+	return string(b)
+}
+
+// variables lists the variables a template is given
+func variables(d definition) []string {
+	names := slices.Sorted(maps.Keys(d.sample))
+	return append(names, "Brand")
+}
+
+func templateNotFound(name string) error {
+	return pkg.NotFoundError{Message: "Email template not found", Err: fmt.Errorf("unknown email template %q", name)}
+}
@@ -0,0 +1,218 @@
+package email_test
+
+import (
+	"app/pkg"
+	"context"
+	"errors"
+	"service-core/config"
+	"service-core/domain/email"
+	"service-core/storage/query"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	t.Parallel()
+	agencyID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	loginURL := "https://example.com/login-callback/email?state=abc&email=jane%40example.com"
+
+	tests := []struct {
+		name        string
+		agencyID    uuid.UUID
+		custom      *query.EmailTemplate
+		wantSubject string
+		wantHTML    []string
+		wantText    []string
+	}{
+		{
+			name:        "built-in with platform branding",
+			agencyID:    uuid.Nil,
+			wantSubject: "Sign in to Webkit",
+			wantHTML:    []string{"Webkit", "#6366f1", "state=abc&amp;email=jane%40example.com"},
+			wantText:    []string{"Sign in to your account", "Sign In (" + loginURL + ")"},
+		},
+		{
+			name:        "built-in with agency branding",
+			agencyID:    agencyID,
+			wantSubject: "Sign in to Acme Studio",
+			wantHTML:    []string{"Acme Studio", "#112233", "hello@acme.example"},
+			wantText:    []string{"securely sign in to Acme Studio"},
+		},
+		{
+			name:     "agency override",
+			agencyID: agencyID,
+			custom: &query.EmailTemplate{
+				Subject: "Your {{.Brand.Name}} link",
+				Body:    `{{template "paragraph" "Welcome back"}}{{template "button" (dict "URL" .LoginURL "Label" "Continue" "Color" .Brand.AccentColor)}}`,
+			},
+			wantSubject: "Your Acme Studio link",
+			wantHTML:    []string{"Welcome back", "#445566"},
+			wantText:    []string{"Continue (" + loginURL + ")"},
+		},
+		{
+			name:     "broken override falls back to built-in",
+			agencyID: agencyID,
+			custom: &query.EmailTemplate{
+				Subject: "Your link",
+				Body:    `{{template "missing"}}`,
+			},
+			wantSubject: "Sign in to Acme Studio",
+			wantText:    []string{"Sign in to your account"},
+		},
+	}
+	cfg := &config.Config{ContextTimeout: time.Second}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newMockStore()
+			store.agencies[agencyID] = query.Agency{
+				ID:           agencyID,
+				Name:         "Acme Studio",
+				PrimaryColor: "#112233",
+				AccentColor:  "#445566",
+				Email:        "hello@acme.example",
+			}
+			if tt.custom != nil {
+				custom := *tt.custom
+				custom.AgencyID, custom.Name, custom.Version = agencyID, email.TemplateLogin, 1
+				store.templates = append(store.templates, custom)
+			}
+			s := email.NewService(cfg, store, store, &mockProvider{}, nil)
+
+			rendered, err := s.RenderTemplate(context.Background(), tt.agencyID, email.TemplateLogin, map[string]any{"LoginURL": loginURL})
+			if err != nil {
+				t.Fatalf("RenderTemplate() = %v", err)
+			}
+			if rendered.Subject != tt.wantSubject {
+				t.Errorf("Subject = %q, want %q", rendered.Subject, tt.wantSubject)
+			}
+			for _, want := range tt.wantHTML {
+				if !strings.Contains(rendered.HTML, want) {
+					t.Errorf("HTML does not contain %q", want)
+				}
+			}
+			for _, want := range tt.wantText {
+				if !strings.Contains(rendered.Text, want) {
+					t.Errorf("Text does not contain %q:\n%s", want, rendered.Text)
+				}
+			}
+			if strings.Contains(rendered.Text, "<") {
+				t.Errorf("Text contains markup:\n%s", rendered.Text)
+			}
+		})
+	}
+}
+
+func TestSaveTemplate(t *testing.T) {
+	t.Parallel()
+	agencyID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+
+	tests := []struct {
+		name      string
+		template  string
+		req       email.TemplateRequest
+		wantField string
+		wantErr   error
+	}{
+		{"valid", email.TemplateInvoiceDue, email.TemplateRequest{Subject: "Invoice {{.InvoiceNumber}}", Body: "<p>{{.Amount}} is due {{.DueDate}}</p>"}, "", nil},
+		{"missing subject", email.TemplateInvoiceDue, email.TemplateRequest{Subject: " ", Body: "<p>Due</p>"}, "subject", nil},
+		{"subject does not parse", email.TemplateInvoiceDue, email.TemplateRequest{Subject: "Invoice {{.InvoiceNumber", Body: "<p>Due</p>"}, "subject", nil},
+		{"body does not parse", email.TemplateInvoiceDue, email.TemplateRequest{Subject: "Invoice", Body: "<p>{{if .Amount}}</p>"}, "body", nil},
+		{"body does not execute", email.TemplateInvoiceDue, email.TemplateRequest{Subject: "Invoice", Body: `{{template "missing"}}`}, "body", nil},
+		{"unknown template", "welcome", email.TemplateRequest{Subject: "Hi", Body: "<p>Hi</p>"}, "", pkg.NotFoundError{}},
+	}
+	cfg := &config.Config{ContextTimeout: time.Second}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newMockStore()
+			s := email.NewService(cfg, store, store, &mockProvider{}, nil)
+
+			saved, err := s.SaveTemplate(context.Background(), agencyID, userID, tt.template, tt.req)
+			switch {
+			case tt.wantField != "":
+				var v pkg.ValidationErrors
+				if !errors.As(err, &v) || len(v) == 0 || v[0].Field != tt.wantField {
+					t.Fatalf("SaveTemplate() = %v, want validation error on %s", err, tt.wantField)
+				}
+			case tt.wantErr != nil:
+				var notFound pkg.NotFoundError
+				if !errors.As(err, &notFound) {
+					t.Fatalf("SaveTemplate() = %v, want NotFoundError", err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("SaveTemplate() = %v", err)
+				}
+				if saved.Version != 1 || !saved.Customized {
+					t.Fatalf("SaveTemplate() = version %d, customized %t, want version 1, customized", saved.Version, saved.Customized)
+				}
+				again, err := s.SaveTemplate(context.Background(), agencyID, userID, tt.template, tt.req)
+				if err != nil || again.Version != 2 {
+					t.Fatalf("second SaveTemplate() = %v, %v, want version 2", again, err)
+				}
+				if err := s.RevertTemplate(context.Background(), agencyID, tt.template); err != nil {
+					t.Fatalf("RevertTemplate() = %v", err)
+				}
+				reverted, err := s.GetTemplate(context.Background(), agencyID, tt.template)
+				if err != nil || reverted.Customized || reverted.Version != 0 {
+					t.Fatalf("GetTemplate() after revert = %+v, %v, want the built-in template", reverted, err)
+				}
+				return
+			}
+			if len(store.templates) > 0 {
+				t.Fatal("SaveTemplate() saved a template it should have refused")
+			}
+		})
+	}
+}
+
+func TestPreviewTemplate(t *testing.T) {
+	t.Parallel()
+	agencyID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	body := "<p>{{.ClientName}} owes {{.Amount}}</p>"
+	broken := "<p>{{.ClientName</p>"
+
+	tests := []struct {
+		name     string
+		req      email.PreviewRequest
+		wantText string
+		wantErr  bool
+	}{
+		{"saved template with sample data", email.PreviewRequest{}, "Jane Citizen", false},
+		{"unsaved body", email.PreviewRequest{Body: &body}, "Jane Citizen owes $1,650.00", false},
+		{"data replaces samples", email.PreviewRequest{Body: &body, Data: map[string]any{"ClientName": "Sam"}}, "Sam owes $1,650.00", false},
+		{"unsaved body does not parse", email.PreviewRequest{Body: &broken}, "", true},
+	}
+	cfg := &config.Config{ContextTimeout: time.Second}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newMockStore()
+			store.agencies[agencyID] = query.Agency{ID: agencyID, Name: "Acme Studio"}
+			s := email.NewService(cfg, store, store, &mockProvider{}, nil)
+
+			rendered, err := s.PreviewTemplate(context.Background(), agencyID, email.TemplateInvoiceDue, tt.req)
+			if tt.wantErr {
+				var badRequest pkg.BadRequestError
+				if !errors.As(err, &badRequest) {
+					t.Fatalf("PreviewTemplate() = %v, want BadRequestError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PreviewTemplate() = %v", err)
+			}
+			if !strings.Contains(rendered.Text, tt.wantText) {
+				t.Errorf("Text does not contain %q:\n%s", tt.wantText, rendered.Text)
+			}
+			if rendered.Subject != "Invoice INV-0001 is due 14 March 2026" {
+				t.Errorf("Subject = %q", rendered.Subject)
+			}
+		})
+	}
+}
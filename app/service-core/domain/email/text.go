@@ -0,0 +1,106 @@
+package email
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// htmlText converts an HTML email to its plain text alternative. Blocks
+// become lines, list items are bulleted, and links keep their URL after
+// their text. Markup that is not content, such as styles, is dropped.
+func htmlText(src string) string {
+	var (
+		out   strings.Builder
+		line  strings.Builder
+		href  []string
+		skip  int
+		blank bool
+	)
+	flush := func() {
+		s := strings.Join(strings.Fields(line.String()), " ")
+		line.Reset()
+		if s == "" {
+			// Keep a single blank line between blocks
+			if out.Len() > 0 && !blank {
+				out.WriteString("\n")
+				blank = true
+			}
+			return
+		}
+		out.WriteString(s + "\n")
+		blank = false
+	}
+
+	z := html.NewTokenizer(strings.NewReader(src))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		name, hasAttr := z.TagName()
+		tag := string(name)
+		switch tt {
+		case html.TextToken:
+			if skip == 0 {
+				line.WriteString(string(z.Text()))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch tag {
+			case "script", "style", "head", "title":
+				if tt == html.StartTagToken {
+					skip++
+				}
+			case "br":
+				flush()
+			case "p", "div", "tr", "table", "h1", "h2", "h3", "h4", "ul", "ol", "blockquote":
+				flush()
+			case "li":
+				flush()
+				line.WriteString("- ")
+			case "td", "th":
+				line.WriteString(" ")
+			case "img":
+				for hasAttr {
+					var key, val []byte
+					key, val, hasAttr = z.TagAttr()
+					if string(key) == "alt" {
+						line.WriteString(string(val))
+					}
+				}
+			case "a":
+				url := ""
+				for hasAttr {
+					var key, val []byte
+					key, val, hasAttr = z.TagAttr()
+					if string(key) == "href" {
+						url = string(val)
+					}
+				}
+				href = append(href, url)
+				line.WriteString(" ")
+			}
+		case html.EndTagToken:
+			switch tag {
+			case "script", "style", "head", "title":
+				if skip > 0 {
+					skip--
+				}
+			case "p", "div", "tr", "table", "h1", "h2", "h3", "h4", "ul", "ol", "li", "blockquote":
+				flush()
+			case "a":
+				if n := len(href); n > 0 {
+					url := href[n-1]
+					href = href[:n-1]
+					// A link shown as its own URL is not repeated
+					text := strings.TrimSpace(line.String())
+					if url != "" && !strings.HasPrefix(url, "#") && !strings.HasSuffix(text, url) {
+						line.WriteString(" (" + url + ")")
+					}
+				}
+			}
+		}
+	}
+	flush()
+	return strings.TrimSpace(out.String())
+}
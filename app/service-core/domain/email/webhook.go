@@ -0,0 +1,119 @@
+package email
+
+import (
+	"app/pkg"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"service-core/storage/query"
+	"time"
+)
+
+// Events providers report about an email after accepting it
+const (
+	EventDelivered  = "delivered"
+	EventOpened     = "opened"
+	EventBounced    = "bounced"
+	EventComplained = "complained"
+)
+
+// webhookTolerance is how old a signed webhook request may be before it is
+// refused as a replay
+const webhookTolerance = 5 * time.Minute
+
+// checkWebhookTime refuses a signed webhook request whose signed timestamp
+// is outside webhookTolerance of now, so a captured request cannot be
+// replayed later
+func checkWebhookTime(at time.Time) error {
+	if age := time.Since(at); age > webhookTolerance || age < -webhookTolerance {
+		return pkg.UnauthorizedError{Err: fmt.Errorf("webhook timestamp is %s old", age)}
+	}
+	return nil
+}
+
+// Event is something a provider reported about an email it sent, which is
+// found by the message ID the provider returned for it
+type Event struct {
+	MessageID  string
+	Type       string
+	OccurredAt time.Time
+	// Reason is why the email bounced
+	Reason string
+	// Hard is whether a bounce is permanent, such as to an address that
+	// does not exist
+	Hard bool
+}
+
+// HandleWebhook verifies a provider's webhook request and records the
+// events it carries on the email logs of the emails they are about. Hard
+// bounces and complaints suppress the recipient for the agency that sent
+// the email. A provider's webhook is only accepted when its secret is
+// configured. Events for emails that have no log are ignored.
+func (s *Service) HandleWebhook(ctx context.Context, provider string, header http.Header, body []byte) error {
+	var events []Event
+	var err error
+	switch provider {
+	case "postmark":
+		events, err = s.postmarkEvents(header, body)
+	case "sendgrid":
+		events, err = s.sendgridEvents(header, body)
+	case "resend":
+		events, err = s.resendEvents(header, body)
+	case "ses":
+		events, err = s.sesEvents(ctx, body)
+	default:
+		return pkg.NotFoundError{Message: "Email webhook not found", Err: fmt.Errorf("unknown email provider %q", provider)}
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		if e.MessageID == "" {
+			continue
+		}
+		rows, err := s.store.UpdateEmailLogEvent(ctx, query.UpdateEmailLogEventParams{
+			Event:             e.Type,
+			OccurredAt:        e.OccurredAt,
+			Reason:            e.Reason,
+			ProviderMessageID: e.MessageID,
+		})
+		if err != nil {
+			return pkg.InternalError{Message: "Error recording email event", Err: err}
+		}
+		if len(rows) == 0 {
+			slog.Debug("Email event has no email log", "provider", provider, "message_id", e.MessageID, "event", e.Type)
+		}
+		reason := suppressionReason(e)
+		if reason == "" {
+			continue
+		}
+		for _, row := range rows {
+			address, err := normalizeAddress(row.RecipientEmail)
+			if err != nil {
+				slog.Warn("Email log has an invalid recipient", "error", err, "agency_id", row.AgencyID, "recipient", row.RecipientEmail)
+				continue
+			}
+			if _, err := s.suppress(ctx, row.AgencyID, address, reason, e.Reason); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// webhookNotConfigured is the error for a webhook whose secret is not set,
+// which is reported as missing
+func webhookNotConfigured(provider string) error {
+	return pkg.NotFoundError{Message: "Email webhook not found", Err: fmt.Errorf("%s webhook is not configured", provider)}
+}
+
+// parseEventTime parses the time of an event, falling back to now for
+// providers that leave it out
+func parseEventTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Now()
+	}
+	return t
+}
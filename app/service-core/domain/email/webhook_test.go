@@ -0,0 +1,241 @@
+package email_test
+
+import (
+	"app/pkg"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"service-core/config"
+	"service-core/domain/email"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// resendHeader signs a Resend webhook body the way Svix does
+func resendHeader(secret []byte, body string, at time.Time) http.Header {
+	id := "msg_1"
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(id + "." + timestamp + "." + body))
+	return http.Header{
+		"Svix-Id":        {id},
+		"Svix-Timestamp": {timestamp},
+		"Svix-Signature": {"v1,bm90IHRoZSBzaWduYXR1cmU= v1," + base64.StdEncoding.EncodeToString(mac.Sum(nil))},
+	}
+}
+
+// sendgridHeader signs a SendGrid webhook body with an ECDSA key
+func sendgridHeader(t *testing.T, key *ecdsa.PrivateKey, body string, at time.Time) http.Header {
+	t.Helper()
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	digest := sha256.Sum256([]byte(timestamp + body))
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return http.Header{
+		"X-Twilio-Email-Event-Webhook-Signature": {base64.StdEncoding.EncodeToString(signature)},
+		"X-Twilio-Email-Event-Webhook-Timestamp": {timestamp},
+	}
+}
+
+func TestHandleWebhook(t *testing.T) {
+	t.Parallel()
+	resendSecret := []byte("resend webhook secret")
+	sendgridKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sendgridPublicKey, err := x509.MarshalPKIXPublicKey(&sendgridKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		ContextTimeout:           time.Second,
+		PostmarkWebhookUsername:  "postmark",
+		PostmarkWebhookPassword:  "password",
+		SendgridWebhookPublicKey: base64.StdEncoding.EncodeToString(sendgridPublicKey),
+		ResendWebhookSecret:      "whsec_" + base64.StdEncoding.EncodeToString(resendSecret),
+		SesWebhookTopicArn:       "arn:aws:sns:us-east-1:123456789012:ses-events",
+	}
+
+	resendBounce := `{"type":"email.bounced","created_at":"2026-01-02T03:04:05.000Z","data":{"email_id":"re_1","bounce":{"message":"Mailbox does not exist"}}}`
+	sendgridBody := `[{"event":"delivered","sg_message_id":"sg_1.filter0001","timestamp":1767323045},{"event":"processed","sg_message_id":"sg_1.filter0001","timestamp":1767323040},{"event":"spamreport","sg_message_id":"sg_2.filter0002","timestamp":1767323050}]`
+	postmarkOpen := `{"RecordType":"Open","MessageID":"pm_1","ReceivedAt":"2026-01-02T03:04:05Z"}`
+	postmarkHeader := func(username, password string) http.Header {
+		r := &http.Request{Header: http.Header{}}
+		r.SetBasicAuth(username, password)
+		return r.Header
+	}
+	snsBody := func(at time.Time) string {
+		return `{"Type":"Notification","MessageId":"1","TopicArn":"arn:aws:sns:us-east-1:123456789012:ses-events","Message":"{}","Timestamp":"` +
+			at.UTC().Format("2006-01-02T15:04:05.000Z") +
+			`","SignatureVersion":"1","Signature":"c2lnbmF0dXJl","SigningCertURL":"https://sns.us-east-1.amazonaws.com/cert.pem"}`
+	}
+
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		provider string
+		header   http.Header
+		body     string
+		want     []email.Event
+		wantErr  error
+	}{
+		{
+			name:     "resend bounce",
+			provider: "resend",
+			header:   resendHeader(resendSecret, resendBounce, time.Now()),
+			body:     resendBounce,
+			want: []email.Event{{
+				MessageID:  "re_1",
+				Type:       email.EventBounced,
+				OccurredAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+				Reason:     "Mailbox does not exist",
+			}},
+		},
+		{
+			name:     "resend wrong secret",
+			provider: "resend",
+			header:   resendHeader([]byte("other secret"), resendBounce, time.Now()),
+			body:     resendBounce,
+			wantErr:  pkg.UnauthorizedError{},
+		},
+		{
+			name:     "resend replayed",
+			provider: "resend",
+			header:   resendHeader(resendSecret, resendBounce, time.Now().Add(-time.Hour)),
+			body:     resendBounce,
+			wantErr:  pkg.UnauthorizedError{},
+		},
+		{
+			name:     "resend not configured",
+			cfg:      &config.Config{ContextTimeout: time.Second},
+			provider: "resend",
+			header:   resendHeader(resendSecret, resendBounce, time.Now()),
+			body:     resendBounce,
+			wantErr:  pkg.NotFoundError{},
+		},
+		{
+			name:     "sendgrid batch",
+			provider: "sendgrid",
+			header:   sendgridHeader(t, sendgridKey, sendgridBody, time.Now()),
+			body:     sendgridBody,
+			want: []email.Event{
+				{MessageID: "sg_1", Type: email.EventDelivered, OccurredAt: time.Unix(1767323045, 0)},
+				{MessageID: "sg_2", Type: email.EventComplained, OccurredAt: time.Unix(1767323050, 0)},
+			},
+		},
+		{
+			name:     "sendgrid wrong key",
+			provider: "sendgrid",
+			header:   sendgridHeader(t, otherKey, sendgridBody, time.Now()),
+			body:     sendgridBody,
+			wantErr:  pkg.UnauthorizedError{},
+		},
+		{
+			name:     "sendgrid replayed",
+			provider: "sendgrid",
+			header:   sendgridHeader(t, sendgridKey, sendgridBody, time.Now().Add(-time.Hour)),
+			body:     sendgridBody,
+			wantErr:  pkg.UnauthorizedError{},
+		},
+		{
+			name:     "postmark open",
+			provider: "postmark",
+			header:   postmarkHeader("postmark", "password"),
+			body:     postmarkOpen,
+			want: []email.Event{{
+				MessageID:  "pm_1",
+				Type:       email.EventOpened,
+				OccurredAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			}},
+		},
+		{
+			name:     "postmark wrong password",
+			provider: "postmark",
+			header:   postmarkHeader("postmark", "wrong"),
+			body:     postmarkOpen,
+			wantErr:  pkg.UnauthorizedError{},
+		},
+		{
+			name:     "ses certificate not from sns",
+			provider: "ses",
+			header:   http.Header{},
+			body:     strings.Replace(snsBody(time.Now()), "sns.us-east-1.amazonaws.com", "sns.example.com", 1),
+			wantErr:  pkg.UnauthorizedError{},
+		},
+		{
+			name:     "ses replayed",
+			provider: "ses",
+			header:   http.Header{},
+			body:     snsBody(time.Now().Add(-time.Hour)),
+			wantErr:  pkg.UnauthorizedError{},
+		},
+		{
+			name:     "ses other topic",
+			provider: "ses",
+			header:   http.Header{},
+			body:     `{"Type":"Notification","TopicArn":"arn:aws:sns:us-east-1:123456789012:other"}`,
+			wantErr:  pkg.UnauthorizedError{},
+		},
+		{
+			name:     "unknown provider",
+			provider: "mailgun",
+			header:   http.Header{},
+			body:     `{}`,
+			wantErr:  pkg.NotFoundError{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := cfg
+			if tt.cfg != nil {
+				c = tt.cfg
+			}
+			store := newMockStore()
+			s := email.NewService(c, store, store, &mockProvider{}, &mockFileService{})
+			err := s.HandleWebhook(context.Background(), tt.provider, tt.header, []byte(tt.body))
+			switch tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("HandleWebhook() = %v, want nil", err)
+				}
+			case pkg.UnauthorizedError:
+				var unauthorized pkg.UnauthorizedError
+				if !errors.As(err, &unauthorized) {
+					t.Fatalf("HandleWebhook() = %v, want UnauthorizedError", err)
+				}
+			case pkg.NotFoundError:
+				var notFound pkg.NotFoundError
+				if !errors.As(err, &notFound) {
+					t.Fatalf("HandleWebhook() = %v, want NotFoundError", err)
+				}
+			}
+			if len(store.events) != len(tt.want) {
+				t.Fatalf("recorded %d events, want %d", len(store.events), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := store.events[i]
+				if got.ProviderMessageID != want.MessageID || got.Event != want.Type ||
+					!got.OccurredAt.Equal(want.OccurredAt) || got.Reason != want.Reason {
+					t.Errorf("event %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
@@ -2,8 +2,11 @@ package file
 
 import (
 	"app/pkg"
+	"app/pkg/auth"
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"service-core/config"
@@ -18,6 +21,7 @@ type store interface {
 	SelectFile(ctx context.Context, id uuid.UUID) (query.File, error)
 	InsertFile(ctx context.Context, params query.InsertFileParams) (query.File, error)
 	DeleteFile(ctx context.Context, id uuid.UUID) error
+	SelectAgencyMembership(ctx context.Context, arg query.SelectAgencyMembershipParams) (query.AgencyMembership, error)
 }
 
 type provider interface {
@@ -149,17 +153,59 @@ func (s *Service) UploadFiles(
 	}
 }
 
+// StoreFile uploads generated content, such as a rendered PDF, and records
+// it against the user and, for an agency's documents, the agency
+func (s *Service) StoreFile(
+	ctx context.Context,
+	userID uuid.UUID,
+	agencyID uuid.UUID,
+	fileName string,
+	contentType string,
+	data []byte,
+) (*query.File, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.ContextTimeout)
+	defer cancel()
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating UUID", Err: err}
+	}
+	fileKey := userID.String() + "/" + id.String()
+	params := query.InsertFileParams{
+		ID:          id,
+		UserID:      userID,
+		FileKey:     fileKey,
+		FileName:    fileName,
+		FileSize:    int64(len(data)),
+		ContentType: contentType,
+		AgencyID:    uuid.NullUUID{UUID: agencyID, Valid: agencyID != uuid.Nil},
+	}
+	if err := validate(params, data); err != nil {
+		return nil, err
+	}
+
+	err = s.provider.Upload(ctx, &File{Key: fileKey, ContentType: contentType, Data: data})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error uploading file to provider", Err: err}
+	}
+	file, err := s.store.InsertFile(ctx, params)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error inserting file", Err: err}
+	}
+	return &file, nil
+}
+
 func (s *Service) DownloadFile(
 	ctx context.Context,
+	userAttr auth.UserAttr,
 	fileID uuid.UUID,
 ) (*query.File, []byte, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.cfg.ContextTimeout)
 	defer cancel()
 
-	// Select the file by ID
-	file, err := s.store.SelectFile(ctx, fileID)
+	file, err := s.authorize(ctx, userAttr, auth.DownloadFile, fileID)
 	if err != nil {
-		return nil, nil, pkg.NotFoundError{Message: "Error selecting file by ID", Err: err}
+		return nil, nil, err
 	}
 
 	// Download the file from the provider
@@ -183,22 +229,22 @@ func (s *Service) DownloadFile(
 		if d == nil {
 			return nil, nil, pkg.InternalError{Message: "Error downloading file from provider", Err: nil}
 		}
-		return &file, d, nil
+		return file, d, nil
 	}
 }
 
 
 func (s *Service) RemoveFile(
 	ctx context.Context,
+	userAttr auth.UserAttr,
 	fileID uuid.UUID,
 ) error {
 	ctx, cancel := context.WithTimeout(ctx, s.cfg.ContextTimeout)
 	defer cancel()
 
-	// Select the file by ID
-	file, err := s.store.SelectFile(ctx, fileID)
+	file, err := s.authorize(ctx, userAttr, auth.RemoveFile, fileID)
 	if err != nil {
-		return pkg.NotFoundError{Message: "Error selecting file by ID", Err: err}
+		return err
 	}
 
 	// Remove the file from the provider
@@ -229,3 +275,39 @@ func (s *Service) RemoveFile(
 		}
 	}
 }
+
+// authorize returns the file if the user may act on it. Uploaded files are
+// personal, so only the user who stored them may. An agency's files are
+// authorized by the user's role in that agency, read from their membership
+// rather than the request so it holds wherever the file is used.
+func (s *Service) authorize(
+	ctx context.Context,
+	userAttr auth.UserAttr,
+	access int64,
+	fileID uuid.UUID,
+) (*query.File, error) {
+	file, err := s.store.SelectFile(ctx, fileID)
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: "Error selecting file by ID", Err: err}
+	}
+	resource := auth.Resource{OwnerID: file.UserID}
+	if file.AgencyID.Valid {
+		resource.AgencyID = file.AgencyID.UUID
+		m, err := s.store.SelectAgencyMembership(ctx, query.SelectAgencyMembershipParams{
+			UserID:   userAttr.ID,
+			AgencyID: resource.AgencyID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, pkg.ForbiddenError{Err: fmt.Errorf("user %s is not a member of agency %s", userAttr.ID, resource.AgencyID)}
+			}
+			return nil, pkg.InternalError{Message: "Error selecting agency membership", Err: err}
+		}
+		userAttr.AgencyID = m.AgencyID
+		userAttr.Role = auth.Role(m.Role)
+	}
+	if err := auth.Authorize(access, userAttr, resource); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
@@ -0,0 +1,133 @@
+package form
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// state is the visibility and requirement of each data field for one
+// submission
+type state struct {
+	hidden   map[string]bool
+	required map[string]bool
+}
+
+// evaluate applies the schema's conditional rules to the submission data.
+// A field with show rules is visible only while one of them matches, and a
+// matching hide rule hides it. Fields are evaluated in form order and a
+// hidden field's value is ignored by the rules of the fields after it, so
+// hiding a question also hides the questions that depend on it.
+func evaluate(s *Schema, data map[string]any) state {
+	st := state{hidden: map[string]bool{}, required: map[string]bool{}}
+	for _, f := range s.inputs() {
+		shows, shown := 0, false
+		required := f.Required
+		for _, r := range f.ConditionalLogic {
+			var value any
+			if !st.hidden[r.Field] {
+				value = data[r.Field]
+			}
+			matched := r.matches(value)
+			switch r.Action {
+			case ActionShow:
+				shows++
+				shown = shown || matched
+			case ActionHide:
+				if matched {
+					st.hidden[f.Name] = true
+				}
+			case ActionRequire:
+				required = required || matched
+			}
+		}
+		if shows > 0 && !shown {
+			st.hidden[f.Name] = true
+		}
+		st.required[f.Name] = required && !st.hidden[f.Name]
+	}
+	return st
+}
+
+// matches reports whether a field value satisfies the rule. Values are
+// compared as text so a rule on "yes" matches the string sent by a radio
+// and a rule on 3 matches a rating of 3.
+func (r Rule) matches(value any) bool {
+	switch r.Operator {
+	case OperatorEquals:
+		return equal(value, r.Value)
+	case OperatorNotEquals:
+		return !equal(value, r.Value)
+	case OperatorContains:
+		switch v := value.(type) {
+		case []any:
+			for _, item := range v {
+				if equal(item, r.Value) {
+					return true
+				}
+			}
+		case string:
+			return strings.Contains(strings.ToLower(v), strings.ToLower(text(r.Value)))
+		}
+	case OperatorGreaterThan, OperatorLessThan:
+		a, aok := number(value)
+		b, bok := number(r.Value)
+		if !aok || !bok {
+			return false
+		}
+		if r.Operator == OperatorGreaterThan {
+			return a > b
+		}
+		return a < b
+	}
+	return false
+}
+
+func equal(a, b any) bool {
+	if a == nil || b == nil {
+		return isEmpty(a) && isEmpty(b)
+	}
+	return text(a) == text(b)
+}
+
+// text returns a scalar value as a string and other values as JSON
+func text(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case nil:
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// number reads a JSON number or a numeric string
+func number(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// isEmpty reports whether a value leaves a required field unanswered:
+// missing, null, an empty string or an empty list
+func isEmpty(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(x) == ""
+	case []any:
+		return len(x) == 0
+	}
+	return false
+}
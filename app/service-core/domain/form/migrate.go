@@ -0,0 +1,207 @@
+package form
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ChangeKind classifies a field change between two versions of a form
+type ChangeKind string
+
+const (
+	ChangeAdded       ChangeKind = "added"
+	ChangeRemoved     ChangeKind = "removed"
+	ChangeRenamed     ChangeKind = "renamed"
+	ChangeTypeChanged ChangeKind = "typeChanged"
+)
+
+// Change is one field change between two versions of a form. Name is the
+// field's name in the new version, or its old name when it was removed.
+type Change struct {
+	Kind    ChangeKind `json:"kind"`
+	FieldID string     `json:"fieldId"`
+	Name    string     `json:"name"`
+	OldName string     `json:"oldName,omitempty"`
+	Type    FieldType  `json:"type,omitempty"`
+	OldType FieldType  `json:"oldType,omitempty"`
+}
+
+// match is a data field of the new version and the field it was in the old
+// version, if any
+type match struct {
+	field Field
+	old   *Field
+}
+
+// matchFields pairs the data fields of two versions. Fields keep their ID
+// when they are renamed or retyped in the form builder, so they are matched
+// by ID first and then by name. It returns the new version's fields in form
+// order and the old fields left unmatched.
+func matchFields(from, to *Schema) ([]match, []Field) {
+	old := from.inputs()
+	used := make([]bool, len(old))
+	find := func(same func(Field) bool) *Field {
+		for i, f := range old {
+			if !used[i] && same(f) {
+				used[i] = true
+				return &old[i]
+			}
+		}
+		return nil
+	}
+
+	fields := to.inputs()
+	matches := make([]match, len(fields))
+	for i, f := range fields {
+		matches[i].field = f
+		if f.ID != "" {
+			matches[i].old = find(func(o Field) bool { return o.ID == f.ID })
+		}
+	}
+	for i, f := range fields {
+		if matches[i].old == nil {
+			matches[i].old = find(func(o Field) bool { return o.Name == f.Name })
+		}
+	}
+
+	var removed []Field
+	for i, f := range old {
+		if !used[i] {
+			removed = append(removed, f)
+		}
+	}
+	return matches, removed
+}
+
+// Compare returns the field changes from one version of a form to the next:
+// added, renamed and retyped fields in the new form's order, then removed
+// fields. A field that is both renamed and retyped has a change for each.
+func Compare(from, to *Schema) []Change {
+	matches, removed := matchFields(from, to)
+	changes := []Change{}
+	for _, m := range matches {
+		f := m.field
+		if m.old == nil {
+			changes = append(changes, Change{Kind: ChangeAdded, FieldID: f.ID, Name: f.Name, Type: f.Type})
+			continue
+		}
+		if m.old.Name != f.Name {
+			changes = append(changes, Change{Kind: ChangeRenamed, FieldID: f.ID, Name: f.Name, OldName: m.old.Name})
+		}
+		if m.old.Type != f.Type {
+			changes = append(changes, Change{Kind: ChangeTypeChanged, FieldID: f.ID, Name: f.Name, Type: f.Type, OldType: m.old.Type})
+		}
+	}
+	for _, f := range removed {
+		changes = append(changes, Change{Kind: ChangeRemoved, FieldID: f.ID, Name: f.Name, Type: f.Type})
+	}
+	return changes
+}
+
+// Migration is submission data moved to a new version of its form.
+// Orphaned holds the answers that could not be carried over, keyed by
+// their old field name: answers to removed fields and answers that are not
+// valid for their field's new type or options.
+type Migration struct {
+	Data     map[string]any
+	Orphaned map[string]any
+}
+
+// Lossless reports whether every answer was carried over
+func (m Migration) Lossless() bool {
+	return len(m.Orphaned) == 0
+}
+
+// Migrate moves submission data from one version of a form to the next.
+// Answers follow renamed fields, and answers to retyped fields are
+// converted where the value allows, such as a number to text or a single
+// choice to a list. Keys that were not fields of the old version are kept
+// unless a field of the new version now uses the name.
+func Migrate(from, to *Schema, data map[string]any) Migration {
+	m := Migration{Data: map[string]any{}, Orphaned: map[string]any{}}
+	matches, removed := matchFields(from, to)
+
+	fields := map[string]bool{}
+	for _, f := range from.inputs() {
+		fields[f.Name] = true
+	}
+	for _, f := range to.inputs() {
+		fields[f.Name] = true
+	}
+	for k, v := range data {
+		if !fields[k] {
+			m.Data[k] = v
+		}
+	}
+
+	for _, f := range removed {
+		if v := data[f.Name]; !isEmpty(v) {
+			m.Orphaned[f.Name] = v
+		}
+	}
+	for _, pair := range matches {
+		if pair.old == nil {
+			continue
+		}
+		v, ok := data[pair.old.Name]
+		if !ok {
+			continue
+		}
+		if isEmpty(v) {
+			m.Data[pair.field.Name] = v
+			continue
+		}
+		if converted, ok := convert(pair.field, v); ok {
+			m.Data[pair.field.Name] = converted
+			continue
+		}
+		m.Orphaned[pair.old.Name] = v
+	}
+	return m
+}
+
+// convert returns a value valid for the field, converting it between types
+// where no information is lost
+func convert(f Field, v any) (any, bool) {
+	if f.check(v) == nil {
+		return v, true
+	}
+	var converted any
+	switch f.Type {
+	case TypeNumber, TypeSlider, TypeRating:
+		if s, ok := v.(string); ok {
+			if n, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				converted = n
+			}
+		}
+	case TypeMultiselect:
+		if s, ok := v.(string); ok {
+			converted = []any{s}
+		}
+	case TypeSelect, TypeRadio:
+		if items, ok := v.([]any); ok && len(items) == 1 {
+			converted = items[0]
+		}
+	case TypeCheckbox:
+		if s, ok := v.(string); ok {
+			if b, err := strconv.ParseBool(s); err == nil {
+				converted = b
+			}
+		}
+	default:
+		switch x := v.(type) {
+		case float64, bool:
+			converted = text(x)
+		case []any:
+			if len(x) == 1 {
+				if s, ok := x[0].(string); ok {
+					converted = s
+				}
+			}
+		}
+	}
+	if converted == nil || f.check(converted) != nil {
+		return nil, false
+	}
+	return converted, true
+}
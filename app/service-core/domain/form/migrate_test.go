@@ -0,0 +1,100 @@
+package form_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"service-core/domain/form"
+	"testing"
+)
+
+func mustParse(t *testing.T, raw string) *form.Schema {
+	t.Helper()
+	s, err := form.Parse(json.RawMessage(raw))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return s
+}
+
+const v1 = `{"steps":[{"id":"s","fields":[
+	{"id":"a","type":"text","name":"business"},
+	{"id":"b","type":"number","name":"pages"},
+	{"id":"c","type":"select","name":"budget","options":[{"value":"small","label":"Small"},{"value":"large","label":"Large"}]},
+	{"id":"d","type":"textarea","name":"notes"},
+	{"id":"e","type":"text","name":"colour"}
+]}]}`
+
+const v2 = `{"steps":[{"id":"s","fields":[
+	{"id":"a","type":"text","name":"business_name"},
+	{"id":"b","type":"text","name":"pages"},
+	{"id":"c","type":"multiselect","name":"budget","options":[{"value":"small","label":"Small"}]},
+	{"id":"x","type":"email","name":"notes"},
+	{"id":"f","type":"url","name":"website"}
+]}]}`
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+	got := form.Compare(mustParse(t, v1), mustParse(t, v2))
+	want := []form.Change{
+		{Kind: form.ChangeRenamed, FieldID: "a", Name: "business_name", OldName: "business"},
+		{Kind: form.ChangeTypeChanged, FieldID: "b", Name: "pages", Type: form.TypeText, OldType: form.TypeNumber},
+		{Kind: form.ChangeTypeChanged, FieldID: "c", Name: "budget", Type: form.TypeMultiselect, OldType: form.TypeSelect},
+		// Matched by name when the ID changed
+		{Kind: form.ChangeTypeChanged, FieldID: "x", Name: "notes", Type: form.TypeEmail, OldType: form.TypeTextarea},
+		{Kind: form.ChangeAdded, FieldID: "f", Name: "website", Type: form.TypeURL},
+		{Kind: form.ChangeRemoved, FieldID: "e", Name: "colour", Type: form.TypeText},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare() = %+v, want %+v", got, want)
+	}
+
+	if got := form.Compare(mustParse(t, v1), mustParse(t, v1)); len(got) != 0 {
+		t.Errorf("Compare(same) = %+v, want none", got)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+	from, to := mustParse(t, v1), mustParse(t, v2)
+	tests := []struct {
+		name         string
+		data         string
+		wantData     string
+		wantOrphaned string
+	}{
+		{
+			name:         "lossless",
+			data:         `{"business":"Harbour Cafe","pages":5,"budget":"small","notes":"","ref":"ad"}`,
+			wantData:     `{"business_name":"Harbour Cafe","pages":"5","budget":["small"],"notes":"","ref":"ad"}`,
+			wantOrphaned: `{}`,
+		},
+		{
+			name:         "lossy",
+			data:         `{"business":"Harbour Cafe","budget":"large","notes":"Call after 3pm","colour":"blue"}`,
+			wantData:     `{"business_name":"Harbour Cafe"}`,
+			wantOrphaned: `{"budget":"large","notes":"Call after 3pm","colour":"blue"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			data, err := form.DecodeData(json.RawMessage(tt.data))
+			if err != nil {
+				t.Fatalf("DecodeData() error = %v", err)
+			}
+			got := form.Migrate(from, to, data)
+			var wantData, wantOrphaned map[string]any
+			_ = json.Unmarshal([]byte(tt.wantData), &wantData)
+			_ = json.Unmarshal([]byte(tt.wantOrphaned), &wantOrphaned)
+			if !reflect.DeepEqual(got.Data, wantData) {
+				t.Errorf("Migrate() data = %v, want %v", got.Data, wantData)
+			}
+			if !reflect.DeepEqual(got.Orphaned, wantOrphaned) {
+				t.Errorf("Migrate() orphaned = %v, want %v", got.Orphaned, wantOrphaned)
+			}
+			if got.Lossless() != (len(wantOrphaned) == 0) {
+				t.Errorf("Lossless() = %v", got.Lossless())
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package form
+
+import (
+	"encoding/json"
+	"service-core/storage/query"
+)
+
+// EvaluateRequest is submission data to check against a form. Drafts are
+// checked as saved progress, so required fields may still be empty.
+// Version selects the form version a pinned submission is on; zero means
+// the current version.
+type EvaluateRequest struct {
+	Data    json.RawMessage `json:"data"`
+	Draft   bool            `json:"draft"`
+	Version int32           `json:"version"`
+}
+
+// Evaluation is the progress of valid submission data through a form
+type Evaluation struct {
+	CompletionPercentage int32 `json:"completionPercentage"`
+	CurrentStep          int32 `json:"currentStep"`
+}
+
+// DraftPolicy decides what happens to draft submissions when a new form
+// version is published
+type DraftPolicy string
+
+const (
+	// DraftsAuto migrates drafts whose answers all carry over and pins the rest
+	DraftsAuto DraftPolicy = "auto"
+	// DraftsMigrate migrates every draft, keeping answers that cannot carry
+	// over in the submission metadata
+	DraftsMigrate DraftPolicy = "migrate"
+	// DraftsPin leaves every draft on the version it was started on
+	DraftsPin DraftPolicy = "pin"
+)
+
+// IsValid reports whether p is a known draft policy
+func (p DraftPolicy) IsValid() bool {
+	switch p {
+	case DraftsAuto, DraftsMigrate, DraftsPin:
+		return true
+	}
+	return false
+}
+
+// PublishRequest is a new schema for a form. UiConfig is kept when omitted
+// and Drafts defaults to auto.
+type PublishRequest struct {
+	Schema   json.RawMessage `json:"schema"`
+	UiConfig json.RawMessage `json:"uiConfig"`
+	Drafts   DraftPolicy     `json:"drafts"`
+}
+
+// PublishResult is the form after publishing with the changes from the
+// previous version and what happened to its draft submissions
+type PublishResult struct {
+	Form     query.AgencyForm `json:"form"`
+	Changes  []Change         `json:"changes"`
+	Migrated int              `json:"migrated"`
+	Pinned   int              `json:"pinned"`
+}
+
+// Version is a published version of a form
+type Version struct {
+	query.AgencyFormVersion
+	Changes []Change `json:"changes"`
+}
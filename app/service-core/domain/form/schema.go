@@ -0,0 +1,257 @@
+package form
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"service-core/storage/query"
+)
+
+// FieldType is the input type of a form field, matching the form builder
+type FieldType string
+
+const (
+	TypeText        FieldType = "text"
+	TypeEmail       FieldType = "email"
+	TypePassword    FieldType = "password"
+	TypeTel         FieldType = "tel"
+	TypeURL         FieldType = "url"
+	TypeTextarea    FieldType = "textarea"
+	TypeNumber      FieldType = "number"
+	TypeDate        FieldType = "date"
+	TypeDatetime    FieldType = "datetime"
+	TypeSelect      FieldType = "select"
+	TypeMultiselect FieldType = "multiselect"
+	TypeRadio       FieldType = "radio"
+	TypeCheckbox    FieldType = "checkbox"
+	TypeFile        FieldType = "file"
+	TypeSignature   FieldType = "signature"
+	TypeRating      FieldType = "rating"
+	TypeSlider      FieldType = "slider"
+	TypeHeading     FieldType = "heading"
+	TypeParagraph   FieldType = "paragraph"
+	TypeDivider     FieldType = "divider"
+)
+
+var fieldTypes = map[FieldType]bool{
+	TypeText: true, TypeEmail: true, TypePassword: true, TypeTel: true,
+	TypeURL: true, TypeTextarea: true, TypeNumber: true, TypeDate: true,
+	TypeDatetime: true, TypeSelect: true, TypeMultiselect: true,
+	TypeRadio: true, TypeCheckbox: true, TypeFile: true, TypeSignature: true,
+	TypeRating: true, TypeSlider: true, TypeHeading: true,
+	TypeParagraph: true, TypeDivider: true,
+}
+
+// IsDisplay reports whether the field is a display element that collects
+// no data
+func (t FieldType) IsDisplay() bool {
+	return t == TypeHeading || t == TypeParagraph || t == TypeDivider
+}
+
+// hasOptions reports whether values of the field type are chosen from its
+// options
+func (t FieldType) hasOptions() bool {
+	return t == TypeSelect || t == TypeMultiselect || t == TypeRadio
+}
+
+// Operator compares a field's value in a conditional rule
+type Operator string
+
+const (
+	OperatorEquals      Operator = "equals"
+	OperatorNotEquals   Operator = "notEquals"
+	OperatorContains    Operator = "contains"
+	OperatorGreaterThan Operator = "greaterThan"
+	OperatorLessThan    Operator = "lessThan"
+)
+
+// Action is what a conditional rule does to its field when it matches
+type Action string
+
+const (
+	ActionShow    Action = "show"
+	ActionHide    Action = "hide"
+	ActionRequire Action = "require"
+)
+
+// Schema is a form builder schema as stored in agency_forms.schema
+type Schema struct {
+	Version string `json:"version"`
+	Steps   []Step `json:"steps"`
+}
+
+// Step is one page of a multi-step form
+type Step struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description,omitempty"`
+	Fields      []Field `json:"fields"`
+}
+
+// Field is a form input or display element. Name is the key of the field's
+// value in the submission data.
+type Field struct {
+	ID               string    `json:"id"`
+	Type             FieldType `json:"type"`
+	Name             string    `json:"name"`
+	Label            string    `json:"label"`
+	Required         bool      `json:"required"`
+	Validation       *Rules    `json:"validation,omitempty"`
+	Options          []Option  `json:"options,omitempty"`
+	OptionSetSlug    string    `json:"optionSetSlug,omitempty"`
+	ConditionalLogic []Rule    `json:"conditionalLogic,omitempty"`
+}
+
+// Rules are the validation constraints of a field
+type Rules struct {
+	Min            *float64 `json:"min,omitempty"`
+	Max            *float64 `json:"max,omitempty"`
+	MinLength      *int     `json:"minLength,omitempty"`
+	MaxLength      *int     `json:"maxLength,omitempty"`
+	Pattern        string   `json:"pattern,omitempty"`
+	PatternMessage string   `json:"patternMessage,omitempty"`
+	CustomMessage  string   `json:"customMessage,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Option is a choice of a select, multiselect or radio field
+type Option struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+// Rule shows, hides or requires a field depending on the value of another
+// field, named by Field
+type Rule struct {
+	Field    string   `json:"field"`
+	Operator Operator `json:"operator"`
+	Value    any      `json:"value"`
+	Action   Action   `json:"action"`
+}
+
+// Parse reads and checks a form builder schema. Structural problems, such
+// as duplicate field names or rules on unknown fields, are returned as
+// validation errors naming the offending step or field.
+func Parse(raw json.RawMessage) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid form schema", Err: err}
+	}
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// check validates the schema's structure and compiles its patterns
+func (s *Schema) check() error {
+	var errors pkg.ValidationErrors
+	add := func(field, tag, message string) {
+		errors = append(errors, pkg.ValidationError{Field: field, Tag: tag, Message: message})
+	}
+	if len(s.Steps) == 0 {
+		add("steps", "required", "Form must have at least one step")
+	}
+
+	names := map[string]bool{}
+	for i := range s.Steps {
+		for j := range s.Steps[i].Fields {
+			f := &s.Steps[i].Fields[j]
+			path := fmt.Sprintf("steps[%d].fields[%d]", i, j)
+			if !fieldTypes[f.Type] {
+				add(path+".type", "oneof", fmt.Sprintf("Unknown field type %q", f.Type))
+				continue
+			}
+			if f.Type.IsDisplay() {
+				continue
+			}
+			switch {
+			case f.Name == "":
+				add(path+".name", "required", "Field name is required")
+			case names[f.Name]:
+				add(path+".name", "unique", fmt.Sprintf("Field name %q is used more than once", f.Name))
+			}
+			names[f.Name] = true
+			if f.Validation != nil && f.Validation.Pattern != "" {
+				re, err := regexp.Compile(f.Validation.Pattern)
+				if err != nil {
+					add(path+".validation.pattern", "regexp", "Pattern is not a valid regular expression")
+				}
+				f.Validation.pattern = re
+			}
+		}
+	}
+
+	for i, step := range s.Steps {
+		for j, f := range step.Fields {
+			for k, r := range f.ConditionalLogic {
+				path := fmt.Sprintf("steps[%d].fields[%d].conditionalLogic[%d]", i, j, k)
+				if !names[r.Field] {
+					add(path+".field", "exists", fmt.Sprintf("Rule refers to unknown field %q", r.Field))
+				}
+				switch r.Operator {
+				case OperatorEquals, OperatorNotEquals, OperatorContains, OperatorGreaterThan, OperatorLessThan:
+				default:
+					add(path+".operator", "oneof", fmt.Sprintf("Unknown operator %q", r.Operator))
+				}
+				switch r.Action {
+				case ActionShow, ActionHide, ActionRequire:
+				default:
+					add(path+".action", "oneof", fmt.Sprintf("Unknown action %q", r.Action))
+				}
+			}
+		}
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+// ResolveOptions replaces the options of fields that use an option set with
+// the set's options. Sets are applied in order, so an agency's set replaces
+// a system set listed before it with the same slug. Fields whose set is
+// missing keep their inline options, as in the form renderer.
+func (s *Schema) ResolveOptions(sets []query.FieldOptionSet) error {
+	options := map[string][]Option{}
+	for _, set := range sets {
+		var opts []Option
+		if err := json.Unmarshal(set.Options, &opts); err != nil {
+			return fmt.Errorf("option set %s: %w", set.Slug, err)
+		}
+		options[set.Slug] = opts
+	}
+	for i := range s.Steps {
+		for j := range s.Steps[i].Fields {
+			f := &s.Steps[i].Fields[j]
+			if opts, ok := options[f.OptionSetSlug]; ok && f.OptionSetSlug != "" {
+				f.Options = opts
+			}
+		}
+	}
+	return nil
+}
+
+// inputs returns the schema's data fields in form order
+func (s *Schema) inputs() []Field {
+	var fields []Field
+	for _, step := range s.Steps {
+		for _, f := range step.Fields {
+			if !f.Type.IsDisplay() {
+				fields = append(fields, f)
+			}
+		}
+	}
+	return fields
+}
+
+// Names returns the names of the schema's data fields
+func (s *Schema) Names() map[string]bool {
+	names := map[string]bool{}
+	for _, f := range s.inputs() {
+		names[f.Name] = true
+	}
+	return names
+}
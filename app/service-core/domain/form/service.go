@@ -0,0 +1,419 @@
+package form
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+)
+
+// store defines the database interface for form operations
+type store interface {
+	SelectAgencyForm(ctx context.Context, id uuid.UUID) (query.AgencyForm, error)
+	SelectAgencyFormVersions(ctx context.Context, formID uuid.UUID) ([]query.AgencyFormVersion, error)
+	SelectAgencyFormVersion(ctx context.Context, arg query.SelectAgencyFormVersionParams) (query.AgencyFormVersion, error)
+	SelectFieldOptionSets(ctx context.Context, agencyID uuid.UUID) ([]query.FieldOptionSet, error)
+}
+
+// Service evaluates submissions against agency form schemas and publishes
+// new form versions
+type Service struct {
+	db    *sql.DB
+	store store
+}
+
+// NewService creates a new form service
+func NewService(db *sql.DB, store store) *Service {
+	return &Service{
+		db:    db,
+		store: store,
+	}
+}
+
+// Load returns a form with its parsed schema, with option set choices
+// resolved for the form's agency
+func (s *Service) Load(ctx context.Context, formID uuid.UUID) (*query.AgencyForm, *Schema, error) {
+	return s.load(ctx, s.store, formID, 0)
+}
+
+// LoadVersion returns a form with the parsed schema of one of its versions,
+// for submissions pinned to that version. Zero loads the current version.
+func (s *Service) LoadVersion(ctx context.Context, formID uuid.UUID, version int32) (*query.AgencyForm, *Schema, error) {
+	return s.load(ctx, s.store, formID, version)
+}
+
+// Evaluate validates submission data for a public form and returns its
+// progress. Inactive forms and forms that require sign in are reported as
+// missing.
+func (s *Service) Evaluate(ctx context.Context, formID uuid.UUID, req EvaluateRequest) (*Evaluation, error) {
+	f, schema, err := s.LoadVersion(ctx, formID, req.Version)
+	if err != nil {
+		return nil, err
+	}
+	if !f.IsActive || f.RequiresAuth {
+		return nil, pkg.NotFoundError{Message: "Form not found", Err: fmt.Errorf("form %s is not public", formID)}
+	}
+	data, err := DecodeData(req.Data)
+	if err != nil {
+		return nil, err
+	}
+	if err := schema.Validate(data, req.Draft); err != nil {
+		return nil, err
+	}
+	completion, step := schema.Progress(data)
+	return &Evaluation{
+		CompletionPercentage: completion,
+		CurrentStep:          step,
+	}, nil
+}
+
+// ListVersions returns the published versions of a form, newest first
+func (s *Service) ListVersions(ctx context.Context, agencyID, formID uuid.UUID) ([]Version, error) {
+	if _, err := s.get(ctx, s.store, agencyID, formID); err != nil {
+		return nil, err
+	}
+	rows, err := s.store.SelectAgencyFormVersions(ctx, formID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting form versions", Err: err}
+	}
+	versions := make([]Version, 0, len(rows))
+	for _, row := range rows {
+		versions = append(versions, versionOf(row))
+	}
+	return versions, nil
+}
+
+// GetVersion returns one published version of a form
+func (s *Service) GetVersion(ctx context.Context, agencyID, formID uuid.UUID, number int32) (*Version, error) {
+	if _, err := s.get(ctx, s.store, agencyID, formID); err != nil {
+		return nil, err
+	}
+	row, err := s.version(ctx, s.store, formID, number)
+	if err != nil {
+		return nil, err
+	}
+	v := versionOf(*row)
+	return &v, nil
+}
+
+// Publish stores a new schema as the next version of a form and reconciles
+// its draft submissions: depending on the draft policy each draft's answers
+// are migrated to the new version or the draft stays pinned to the version
+// it was started on. Completed submissions keep their version.
+func (s *Service) Publish(
+	ctx context.Context,
+	agencyID uuid.UUID,
+	userID uuid.UUID,
+	formID uuid.UUID,
+	req PublishRequest,
+) (*PublishResult, error) {
+	if req.Drafts == "" {
+		req.Drafts = DraftsAuto
+	}
+	if !req.Drafts.IsValid() {
+		return nil, pkg.ValidationErrors{{
+			Field:   "drafts",
+			Tag:     "oneof",
+			Message: "Drafts must be auto, migrate or pin",
+		}}
+	}
+	next, err := Parse(req.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error starting form publish", Err: err}
+	}
+	defer tx.Rollback()
+	q := query.New(tx)
+
+	existing, err := q.SelectAgencyFormForUpdate(ctx, formID)
+	if err != nil || existing.AgencyID != agencyID {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Form not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting form", Err: err}
+	}
+	current, err := Parse(existing.Schema)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error parsing form schema", Err: err}
+	}
+	sets, err := q.SelectFieldOptionSets(ctx, agencyID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting option sets", Err: err}
+	}
+	if err := next.ResolveOptions(sets); err != nil {
+		return nil, pkg.InternalError{Message: "Error reading option sets", Err: err}
+	}
+
+	// Forms created before versioning, or edited outside this service, may
+	// not have their current version recorded yet
+	if _, err := q.SelectAgencyFormVersion(ctx, query.SelectAgencyFormVersionParams{
+		FormID:  formID,
+		Version: existing.Version,
+	}); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.InternalError{Message: "Error selecting form version", Err: err}
+		}
+		if err := insertVersion(ctx, q, existing, existing.Schema, existing.UiConfig, []Change{}, uuid.NullUUID{}); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := Compare(current, next)
+	uiConfig := existing.UiConfig
+	if len(req.UiConfig) > 0 && string(req.UiConfig) != "null" {
+		uiConfig = req.UiConfig
+	}
+	form, err := q.UpdateAgencyFormSchema(ctx, query.UpdateAgencyFormSchemaParams{
+		ID:             formID,
+		Schema:         req.Schema,
+		UiConfig:       uiConfig,
+		PreviousSchema: pqtype.NullRawMessage{RawMessage: existing.Schema, Valid: true},
+		Version:        existing.Version + 1,
+		// A schema change stops template updates being pushed to the form
+		IsCustomized: existing.IsCustomized || existing.SourceTemplateID.Valid,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating form", Err: err}
+	}
+	createdBy := uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil}
+	if err := insertVersion(ctx, q, form, req.Schema, uiConfig, changes, createdBy); err != nil {
+		return nil, err
+	}
+
+	migrated, pinned, err := s.migrateDrafts(ctx, q, form, next, req.Drafts)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, pkg.InternalError{Message: "Error committing form publish", Err: err}
+	}
+	return &PublishResult{
+		Form:     form,
+		Changes:  changes,
+		Migrated: migrated,
+		Pinned:   pinned,
+	}, nil
+}
+
+// migrateDrafts moves the form's draft submissions to its new version or
+// pins them to their own, returning how many of each
+func (s *Service) migrateDrafts(
+	ctx context.Context,
+	q *query.Queries,
+	form query.AgencyForm,
+	next *Schema,
+	policy DraftPolicy,
+) (migrated int, pinned int, err error) {
+	drafts, err := q.SelectDraftFormSubmissions(ctx, form.ID)
+	if err != nil {
+		return 0, 0, pkg.InternalError{Message: "Error selecting draft submissions", Err: err}
+	}
+	if policy == DraftsPin {
+		return 0, len(drafts), nil
+	}
+
+	// Drafts may have been pinned by earlier publishes, so each is migrated
+	// from the version it is on
+	schemas := map[int32]*Schema{}
+	for _, d := range drafts {
+		if d.FormVersion == form.Version {
+			continue
+		}
+		from, ok := schemas[d.FormVersion]
+		if !ok {
+			_, from, err = s.load(ctx, q, form.ID, d.FormVersion)
+			if err != nil {
+				var notFound pkg.NotFoundError
+				if !errors.As(err, &notFound) {
+					return 0, 0, err
+				}
+				// Without its schema the draft cannot be migrated safely
+				from = nil
+			}
+			schemas[d.FormVersion] = from
+		}
+		if from == nil {
+			pinned++
+			continue
+		}
+
+		data, err := DecodeData(d.Data)
+		if err != nil {
+			pinned++
+			continue
+		}
+		m := Migrate(from, next, data)
+		if policy == DraftsAuto && !m.Lossless() {
+			pinned++
+			continue
+		}
+		if err := updateDraft(ctx, q, d, form.Version, next, m); err != nil {
+			return 0, 0, err
+		}
+		migrated++
+	}
+	return migrated, pinned, nil
+}
+
+// updateDraft stores a migrated draft on the new version. Answers that did
+// not carry over are kept in the metadata under orphanedData.
+func updateDraft(
+	ctx context.Context,
+	q *query.Queries,
+	d query.FormSubmission,
+	version int32,
+	schema *Schema,
+	m Migration,
+) error {
+	metadata := map[string]any{}
+	if len(d.Metadata) > 0 {
+		if err := json.Unmarshal(d.Metadata, &metadata); err != nil {
+			metadata = map[string]any{}
+		}
+	}
+	if !m.Lossless() {
+		orphaned, _ := metadata["orphanedData"].(map[string]any)
+		if orphaned == nil {
+			orphaned = map[string]any{}
+		}
+		for k, v := range m.Orphaned {
+			orphaned[k] = v
+		}
+		metadata["orphanedData"] = orphaned
+	}
+	metadata["migratedFromVersion"] = d.FormVersion
+
+	data, err := json.Marshal(m.Data)
+	if err != nil {
+		return pkg.InternalError{Message: "Error encoding submission data", Err: err}
+	}
+	meta, err := json.Marshal(metadata)
+	if err != nil {
+		return pkg.InternalError{Message: "Error encoding submission metadata", Err: err}
+	}
+	completion, step := schema.Progress(m.Data)
+	err = q.UpdateFormSubmissionVersion(ctx, query.UpdateFormSubmissionVersionParams{
+		ID:                   d.ID,
+		Data:                 data,
+		Metadata:             meta,
+		FormVersion:          version,
+		CurrentStep:          step,
+		CompletionPercentage: completion,
+	})
+	if err != nil {
+		return pkg.InternalError{Message: "Error updating submission", Err: err}
+	}
+	return nil
+}
+
+func insertVersion(
+	ctx context.Context,
+	q *query.Queries,
+	form query.AgencyForm,
+	schema json.RawMessage,
+	uiConfig json.RawMessage,
+	changes []Change,
+	createdBy uuid.NullUUID,
+) error {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return pkg.InternalError{Message: "Error generating form version ID", Err: err}
+	}
+	b, err := json.Marshal(changes)
+	if err != nil {
+		return pkg.InternalError{Message: "Error encoding form changes", Err: err}
+	}
+	_, err = q.InsertAgencyFormVersion(ctx, query.InsertAgencyFormVersionParams{
+		ID:        id,
+		FormID:    form.ID,
+		AgencyID:  form.AgencyID,
+		Version:   form.Version,
+		Schema:    schema,
+		UiConfig:  uiConfig,
+		Changes:   b,
+		CreatedBy: createdBy,
+	})
+	if err != nil {
+		return pkg.InternalError{Message: "Error inserting form version", Err: err}
+	}
+	return nil
+}
+
+// load returns a form with the parsed schema of the given version, or of
+// the current version when version is zero or current
+func (s *Service) load(ctx context.Context, r store, formID uuid.UUID, version int32) (*query.AgencyForm, *Schema, error) {
+	f, err := r.SelectAgencyForm(ctx, formID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, pkg.NotFoundError{Message: "Form not found", Err: err}
+		}
+		return nil, nil, pkg.InternalError{Message: "Error selecting form", Err: err}
+	}
+	raw := f.Schema
+	if version != 0 && version != f.Version {
+		v, err := s.version(ctx, r, formID, version)
+		if err != nil {
+			return nil, nil, err
+		}
+		raw = v.Schema
+	}
+	schema, err := Parse(raw)
+	if err != nil {
+		return nil, nil, pkg.InternalError{Message: "Error parsing form schema", Err: err}
+	}
+	sets, err := r.SelectFieldOptionSets(ctx, f.AgencyID)
+	if err != nil {
+		return nil, nil, pkg.InternalError{Message: "Error selecting option sets", Err: err}
+	}
+	if err := schema.ResolveOptions(sets); err != nil {
+		return nil, nil, pkg.InternalError{Message: "Error reading option sets", Err: err}
+	}
+	return &f, schema, nil
+}
+
+func (s *Service) get(ctx context.Context, r store, agencyID, formID uuid.UUID) (*query.AgencyForm, error) {
+	f, err := r.SelectAgencyForm(ctx, formID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Form not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting form", Err: err}
+	}
+	// Forms from other agencies are reported as missing rather than forbidden
+	if f.AgencyID != agencyID {
+		return nil, pkg.NotFoundError{Message: "Form not found", Err: fmt.Errorf("form %s belongs to another agency", formID)}
+	}
+	return &f, nil
+}
+
+func (s *Service) version(ctx context.Context, r store, formID uuid.UUID, number int32) (*query.AgencyFormVersion, error) {
+	v, err := r.SelectAgencyFormVersion(ctx, query.SelectAgencyFormVersionParams{
+		FormID:  formID,
+		Version: number,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Form version not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting form version", Err: err}
+	}
+	return &v, nil
+}
+
+func versionOf(row query.AgencyFormVersion) Version {
+	v := Version{AgencyFormVersion: row, Changes: []Change{}}
+	if err := json.Unmarshal(row.Changes, &v.Changes); err != nil || v.Changes == nil {
+		v.Changes = []Change{}
+	}
+	return v
+}
@@ -0,0 +1,205 @@
+package form
+
+import (
+	"app/pkg"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+// DecodeData reads submission data, which must be a JSON object keyed by
+// field name
+func DecodeData(raw json.RawMessage) (map[string]any, error) {
+	data := map[string]any{}
+	if len(bytes.TrimSpace(raw)) == 0 || string(raw) == "null" {
+		return data, nil
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, pkg.BadRequestError{Message: "Submission data must be an object", Err: err}
+	}
+	return data, nil
+}
+
+// Validate checks submission data against the schema, returning validation
+// errors keyed by field name. Hidden fields are not checked. A draft may
+// leave required fields empty; the values it does have must still be valid.
+// Keys that are not fields of the schema are ignored.
+func (s *Schema) Validate(data map[string]any, draft bool) error {
+	st := evaluate(s, data)
+	var errors pkg.ValidationErrors
+	for _, f := range s.inputs() {
+		if st.hidden[f.Name] {
+			continue
+		}
+		value := data[f.Name]
+		if isEmpty(value) {
+			if st.required[f.Name] && !draft {
+				errors = append(errors, *f.error("required", f.Label+" is required"))
+			}
+			continue
+		}
+		if err := f.check(value); err != nil {
+			errors = append(errors, *err)
+		}
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+// check validates a non-empty value against the field's type, options and
+// rules
+func (f Field) check(value any) *pkg.ValidationError {
+	rules := f.Validation
+	if rules == nil {
+		rules = &Rules{}
+	}
+	switch f.Type {
+	case TypeNumber, TypeSlider, TypeRating:
+		n, ok := value.(float64)
+		if !ok {
+			return f.error("number", f.Label+" must be a number")
+		}
+		if rules.Min != nil && n < *rules.Min {
+			return f.error("min", fmt.Sprintf("%s must be at least %s", f.Label, text(*rules.Min)))
+		}
+		if rules.Max != nil && n > *rules.Max {
+			return f.error("max", fmt.Sprintf("%s must be at most %s", f.Label, text(*rules.Max)))
+		}
+		if f.Type == TypeRating && n != math.Trunc(n) {
+			return f.error("number", f.Label+" must be a whole number")
+		}
+		return nil
+
+	case TypeCheckbox:
+		if _, ok := value.(bool); !ok {
+			return f.error("boolean", f.Label+" must be true or false")
+		}
+		return nil
+
+	case TypeMultiselect:
+		items, ok := value.([]any)
+		if !ok {
+			return f.error("array", f.Label+" must be a list")
+		}
+		for _, item := range items {
+			v, ok := item.(string)
+			if !ok || !f.allows(v) {
+				return f.error("oneof", fmt.Sprintf("%s has an invalid option %q", f.Label, text(item)))
+			}
+		}
+		return nil
+
+	case TypeFile:
+		// Uploads are stored and checked by the file service
+		return nil
+	}
+
+	v, ok := value.(string)
+	if !ok {
+		return f.error("string", f.Label+" must be text")
+	}
+	switch f.Type {
+	case TypeEmail:
+		if a, err := mail.ParseAddress(v); err != nil || a.Address != v {
+			return f.error("email", f.Label+" must be a valid email address")
+		}
+	case TypeURL:
+		if u, err := url.ParseRequestURI(v); err != nil || u.Host == "" {
+			return f.error("url", f.Label+" must be a valid URL")
+		}
+	case TypeDate:
+		if !parsesAs(v, time.DateOnly, time.RFC3339) {
+			return f.error("date", f.Label+" must be a valid date")
+		}
+	case TypeDatetime:
+		if !parsesAs(v, time.RFC3339, "2006-01-02T15:04", "2006-01-02T15:04:05") {
+			return f.error("datetime", f.Label+" must be a valid date and time")
+		}
+	case TypeSelect, TypeRadio:
+		if !f.allows(v) {
+			return f.error("oneof", fmt.Sprintf("%s has an invalid option %q", f.Label, v))
+		}
+	}
+
+	length := utf8.RuneCountInString(v)
+	if rules.MinLength != nil && length < *rules.MinLength {
+		return f.error("min", fmt.Sprintf("%s must be at least %d characters", f.Label, *rules.MinLength))
+	}
+	if rules.MaxLength != nil && length > *rules.MaxLength {
+		return f.error("max", fmt.Sprintf("%s must be at most %d characters", f.Label, *rules.MaxLength))
+	}
+	if rules.pattern != nil && !rules.pattern.MatchString(v) {
+		message := rules.PatternMessage
+		if message == "" {
+			message = f.Label + " is not in the expected format"
+		}
+		return f.error("pattern", message)
+	}
+	return nil
+}
+
+// allows reports whether a value is one of the field's options. A field
+// without options accepts any value.
+func (f Field) allows(v string) bool {
+	if len(f.Options) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(f.Options, func(o Option) bool { return o.Value == v })
+}
+
+// error returns a validation error for the field, using the field's custom
+// message when the form sets one
+func (f Field) error(tag, message string) *pkg.ValidationError {
+	if f.Validation != nil && f.Validation.CustomMessage != "" && tag != "pattern" {
+		message = f.Validation.CustomMessage
+	}
+	return &pkg.ValidationError{Field: f.Name, Tag: tag, Message: message}
+}
+
+func parsesAs(v string, layouts ...string) bool {
+	for _, layout := range layouts {
+		if _, err := time.Parse(layout, v); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Progress returns the percentage of steps complete and the index of the
+// step the client should resume on. A step is complete when all of its
+// visible required fields are answered; the current step is the first
+// incomplete step, or the last step once the form is complete.
+func (s *Schema) Progress(data map[string]any) (completion int32, currentStep int32) {
+	if len(s.Steps) == 0 {
+		return 0, 0
+	}
+	st := evaluate(s, data)
+	complete := 0
+	currentStep = -1
+	for i, step := range s.Steps {
+		done := true
+		for _, f := range step.Fields {
+			if !f.Type.IsDisplay() && st.required[f.Name] && isEmpty(data[f.Name]) {
+				done = false
+				break
+			}
+		}
+		if done {
+			complete++
+		} else if currentStep < 0 {
+			currentStep = int32(i)
+		}
+	}
+	if currentStep < 0 {
+		currentStep = int32(len(s.Steps) - 1)
+	}
+	return int32(math.Round(float64(complete) * 100 / float64(len(s.Steps)))), currentStep
+}
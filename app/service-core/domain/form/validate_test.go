@@ -0,0 +1,250 @@
+package form_test
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"service-core/domain/form"
+	"service-core/storage/query"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+const intake = `{
+	"version": "1.0",
+	"steps": [
+		{
+			"id": "contact",
+			"title": "Contact",
+			"fields": [
+				{"id": "f1", "type": "heading", "name": "intro", "label": "About you"},
+				{"id": "f2", "type": "text", "name": "business_name", "label": "Business Name", "required": true,
+					"validation": {"maxLength": 20}},
+				{"id": "f3", "type": "email", "name": "email", "label": "Email", "required": true},
+				{"id": "f4", "type": "url", "name": "website", "label": "Website"},
+				{"id": "f5", "type": "tel", "name": "phone", "label": "Phone",
+					"validation": {"pattern": "^[0-9 +]+$", "patternMessage": "Phone may only contain digits"}}
+			]
+		},
+		{
+			"id": "project",
+			"title": "Project",
+			"fields": [
+				{"id": "f6", "type": "radio", "name": "has_domain", "label": "Have a domain?", "required": true,
+					"options": [{"value": "yes", "label": "Yes"}, {"value": "no", "label": "No"}]},
+				{"id": "f7", "type": "text", "name": "domain_name", "label": "Domain Name",
+					"conditionalLogic": [{"field": "has_domain", "operator": "equals", "value": "yes", "action": "show"},
+						{"field": "has_domain", "operator": "equals", "value": "yes", "action": "require"}]},
+				{"id": "f8", "type": "multiselect", "name": "industry", "label": "Industry", "optionSetSlug": "industries"},
+				{"id": "f9", "type": "rating", "name": "urgency", "label": "Urgency",
+					"validation": {"min": 1, "max": 5}},
+				{"id": "f10", "type": "checkbox", "name": "terms", "label": "Terms", "required": true}
+			]
+		}
+	]
+}`
+
+func parse(t *testing.T) *form.Schema {
+	t.Helper()
+	s, err := form.Parse(json.RawMessage(intake))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	err = s.ResolveOptions([]query.FieldOptionSet{
+		{Slug: "industries", Options: json.RawMessage(`[{"value":"retail","label":"Retail"}]`)},
+		{
+			Slug:     "industries",
+			AgencyID: uuid.NullUUID{UUID: uuid.New(), Valid: true},
+			Options:  json.RawMessage(`[{"value":"cafe","label":"Cafe"},{"value":"trades","label":"Trades"}]`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("ResolveOptions() error = %v", err)
+	}
+	return s
+}
+
+func fields(err error) map[string]string {
+	var verrs pkg.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil
+	}
+	m := map[string]string{}
+	for _, e := range verrs {
+		m[e.Field] = e.Tag
+	}
+	return m
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		schema string
+		want   map[string]string
+	}{
+		{
+			name:   "no steps",
+			schema: `{"version":"1.0","steps":[]}`,
+			want:   map[string]string{"steps": "required"},
+		},
+		{
+			name: "duplicate name and unknown type",
+			schema: `{"steps":[{"id":"s","fields":[
+				{"type":"text","name":"a"},{"type":"email","name":"a"},{"type":"colour","name":"b"}]}]}`,
+			want: map[string]string{
+				"steps[0].fields[1].name": "unique",
+				"steps[0].fields[2].type": "oneof",
+			},
+		},
+		{
+			name: "bad rule and pattern",
+			schema: `{"steps":[{"id":"s","fields":[
+				{"type":"text","name":"a","validation":{"pattern":"("},
+					"conditionalLogic":[{"field":"missing","operator":"between","value":1,"action":"show"}]}]}]}`,
+			want: map[string]string{
+				"steps[0].fields[0].validation.pattern":           "regexp",
+				"steps[0].fields[0].conditionalLogic[0].field":    "exists",
+				"steps[0].fields[0].conditionalLogic[0].operator": "oneof",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := form.Parse(json.RawMessage(tt.schema))
+			if got := fields(err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() errors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if _, err := form.Parse(json.RawMessage(intake)); err != nil {
+		t.Errorf("Parse(intake) error = %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	schema := parse(t)
+	valid := `{"business_name":"Harbour Cafe","email":"hello@harbour.example","has_domain":"no","terms":true}`
+
+	tests := []struct {
+		name  string
+		data  string
+		draft bool
+		want  map[string]string
+	}{
+		{name: "valid", data: valid, want: map[string]string{}},
+		{
+			name: "missing required",
+			data: `{"business_name":" ","website":""}`,
+			want: map[string]string{
+				"business_name": "required",
+				"email":         "required",
+				"has_domain":    "required",
+				"terms":         "required",
+			},
+		},
+		{name: "draft skips required", data: `{"email":"hello@harbour.example"}`, draft: true, want: map[string]string{}},
+		{
+			name:  "draft checks values",
+			data:  `{"email":"not an email","website":"harbour","phone":"call me"}`,
+			draft: true,
+			want: map[string]string{
+				"email":   "email",
+				"website": "url",
+				"phone":   "pattern",
+			},
+		},
+		{
+			name: "conditional field required when shown",
+			data: `{"business_name":"Harbour Cafe","email":"hello@harbour.example","has_domain":"yes","terms":false}`,
+			want: map[string]string{"domain_name": "required"},
+		},
+		{
+			name: "hidden field not checked",
+			data: `{"business_name":"Harbour Cafe","email":"hello@harbour.example","has_domain":"no","domain_name":42,"terms":true}`,
+			want: map[string]string{},
+		},
+		{
+			name: "types and options",
+			data: `{"business_name":"Harbour Cafe and Bakery Pty Ltd","email":"hello@harbour.example","has_domain":"maybe",
+				"industry":["cafe","retail"],"urgency":7,"terms":"yes"}`,
+			want: map[string]string{
+				"business_name": "max",
+				"has_domain":    "oneof",
+				"industry":      "oneof",
+				"urgency":       "max",
+				"terms":         "boolean",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			data, err := form.DecodeData(json.RawMessage(tt.data))
+			if err != nil {
+				t.Fatalf("DecodeData() error = %v", err)
+			}
+			err = schema.Validate(data, tt.draft)
+			got := fields(err)
+			if err == nil {
+				got = map[string]string{}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() errors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := form.DecodeData(json.RawMessage(`["a"]`)); err == nil {
+		t.Error("DecodeData(list) error = nil, want error")
+	}
+}
+
+func TestProgress(t *testing.T) {
+	t.Parallel()
+	schema := parse(t)
+	tests := []struct {
+		name           string
+		data           string
+		wantCompletion int32
+		wantStep       int32
+	}{
+		{name: "empty", data: `{}`, wantCompletion: 0, wantStep: 0},
+		{name: "first step done", data: `{"business_name":"a","email":"a@b.example"}`, wantCompletion: 50, wantStep: 1},
+		{
+			name:           "shown field outstanding",
+			data:           `{"business_name":"a","email":"a@b.example","has_domain":"yes","terms":true}`,
+			wantCompletion: 50,
+			wantStep:       1,
+		},
+		{
+			name:           "complete",
+			data:           `{"business_name":"a","email":"a@b.example","has_domain":"no","terms":false}`,
+			wantCompletion: 100,
+			wantStep:       1,
+		},
+		{
+			name:           "later step done first",
+			data:           `{"email":"a@b.example","has_domain":"no","terms":true}`,
+			wantCompletion: 50,
+			wantStep:       0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			data, err := form.DecodeData(json.RawMessage(tt.data))
+			if err != nil {
+				t.Fatalf("DecodeData() error = %v", err)
+			}
+			completion, step := schema.Progress(data)
+			if completion != tt.wantCompletion || step != tt.wantStep {
+				t.Errorf("Progress() = %d, %d, want %d, %d", completion, step, tt.wantCompletion, tt.wantStep)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package invoice
+
+import (
+	"app/pkg/money"
+	"crypto/rand"
+	"database/sql"
+	agencypkg "service-core/domain/pkg"
+	"service-core/domain/proposal"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const slugAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// newSlug returns a random 12 character public URL slug
+func newSlug() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = slugAlphabet[int(b[i])%len(slugAlphabet)]
+	}
+	return string(b), nil
+}
+
+// newParams returns insert params for a draft invoice using the agency's GST
+// registration and default payment terms
+func newParams(agencyID, userID uuid.UUID, number, slug string, profile query.AgencyProfile, issued time.Time) query.InsertInvoiceParams {
+	terms := profile.DefaultPaymentTerms
+	if terms == "" {
+		terms = TermsNet14
+	}
+	return query.InsertInvoiceParams{
+		AgencyID:      agencyID,
+		InvoiceNumber: number,
+		Slug:          slug,
+		Status:        string(StatusDraft),
+		IssueDate:     issued,
+		DueDate:       DueDate(issued, terms),
+		GstRegistered: profile.GstRegistered,
+		GstRate:       profile.GstRate,
+		PaymentTerms:  terms,
+		CreatedBy:     uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+	}
+}
+
+// applyCreate copies the client details, dates, terms and notes of a blank
+// invoice request onto the insert params
+func applyCreate(params *query.InsertInvoiceParams, req CreateRequest) {
+	if req.ClientID != nil {
+		params.ClientID = uuid.NullUUID{UUID: *req.ClientID, Valid: true}
+	}
+	params.ClientBusinessName = req.ClientBusinessName
+	params.ClientContactName = req.ClientContactName
+	params.ClientEmail = req.ClientEmail
+	params.ClientPhone = req.ClientPhone
+	params.ClientAddress = req.ClientAddress
+	params.ClientAbn = req.ClientAbn
+	if req.PaymentTerms != "" {
+		params.PaymentTerms = req.PaymentTerms
+	}
+	params.PaymentTermsCustom = req.PaymentTermsCustom
+	if req.IssueDate != nil {
+		params.IssueDate = *req.IssueDate
+	}
+	params.DueDate = DueDate(params.IssueDate, params.PaymentTerms)
+	if req.DueDate != nil {
+		params.DueDate = *req.DueDate
+	}
+	params.Notes = req.Notes
+	params.PublicNotes = req.PublicNotes
+	params.DiscountAmount = req.DiscountAmount
+	params.DiscountDescription = req.DiscountDescription
+}
+
+// applyUpdate merges an update request into the existing invoice. Changing
+// the payment terms or issue date moves the due date unless one is given.
+func applyUpdate(existing query.Invoice, req UpdateRequest) query.UpdateInvoiceParams {
+	p := query.UpdateInvoiceParams{
+		ID:                   existing.ID,
+		ClientBusinessName:   existing.ClientBusinessName,
+		ClientContactName:    existing.ClientContactName,
+		ClientEmail:          existing.ClientEmail,
+		ClientPhone:          existing.ClientPhone,
+		ClientAddress:        existing.ClientAddress,
+		ClientAbn:            existing.ClientAbn,
+		IssueDate:            existing.IssueDate,
+		DueDate:              existing.DueDate,
+		DiscountAmount:       existing.DiscountAmount,
+		DiscountDescription:  existing.DiscountDescription,
+		PaymentTerms:         existing.PaymentTerms,
+		PaymentTermsCustom:   existing.PaymentTermsCustom,
+		Notes:                existing.Notes,
+		PublicNotes:          existing.PublicNotes,
+		OnlinePaymentEnabled: existing.OnlinePaymentEnabled,
+	}
+	setString(&p.ClientBusinessName, req.ClientBusinessName)
+	setString(&p.ClientContactName, req.ClientContactName)
+	setString(&p.ClientEmail, req.ClientEmail)
+	setString(&p.ClientPhone, req.ClientPhone)
+	setString(&p.ClientAddress, req.ClientAddress)
+	setString(&p.ClientAbn, req.ClientAbn)
+	setString(&p.PaymentTerms, req.PaymentTerms)
+	setString(&p.PaymentTermsCustom, req.PaymentTermsCustom)
+	setString(&p.Notes, req.Notes)
+	setString(&p.PublicNotes, req.PublicNotes)
+	setString(&p.DiscountDescription, req.DiscountDescription)
+	if req.IssueDate != nil {
+		p.IssueDate = *req.IssueDate
+	}
+	if req.IssueDate != nil || req.PaymentTerms != nil {
+		p.DueDate = DueDate(p.IssueDate, p.PaymentTerms)
+	}
+	if req.DueDate != nil {
+		p.DueDate = *req.DueDate
+	}
+	if req.DiscountAmount != nil {
+		p.DiscountAmount = *req.DiscountAmount
+	}
+	if req.OnlinePaymentEnabled != nil {
+		p.OnlinePaymentEnabled = *req.OnlinePaymentEnabled
+	}
+	return p
+}
+
+func setString(dst *string, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// lineItemParams builds the insert params for a requested line item,
+// computing its amount from the quantity and unit price
+func lineItemParams(invoiceID uuid.UUID, sortOrder int32, req LineItemRequest, mode money.Rounding) query.InsertInvoiceLineItemParams {
+	if req.SortOrder != nil {
+		sortOrder = *req.SortOrder
+	}
+	p := query.InsertInvoiceLineItemParams{
+		InvoiceID:   invoiceID,
+		Description: req.Description,
+		Quantity:    req.Quantity,
+		UnitPrice:   req.UnitPrice,
+		Amount:      req.UnitPrice.Mul(req.Quantity, mode),
+		IsTaxable:   req.IsTaxable == nil || *req.IsTaxable,
+		SortOrder:   sortOrder,
+		Category:    sql.NullString{String: req.Category, Valid: req.Category != ""},
+	}
+	if req.PackageID != nil {
+		p.PackageID = uuid.NullUUID{UUID: *req.PackageID, Valid: true}
+	}
+	if req.AddonID != nil {
+		p.AddonID = uuid.NullUUID{UUID: *req.AddonID, Valid: true}
+	}
+	return p
+}
+
+// proposalLineItems returns the one-time charges of a proposal's pricing as
+// line items. Monthly charges are billed separately by subscription.
+func proposalLineItems(pricing *proposal.PricingBreakdown) []LineItemRequest {
+	var items []LineItemRequest
+	for _, line := range pricing.LineItems {
+		if line.Frequency != agencypkg.FrequencyOneTime {
+			continue
+		}
+		items = append(items, LineItemRequest{
+			Description: line.Description,
+			Quantity:    money.NewDecimal(int64(line.Quantity)),
+			UnitPrice:   line.UnitPrice,
+			Category:    line.Category,
+			PackageID:   line.PackageID,
+			AddonID:     line.AddonID,
+		})
+	}
+	return items
+}
+
+// exclusive removes GST from a GST inclusive price at rate percent
+func exclusive(price money.Money, rate money.Decimal, mode money.Rounding) money.Money {
+	return price.Sub(price.IncludedPercent(rate, mode))
+}
@@ -0,0 +1,214 @@
+package invoice
+
+import (
+	"app/pkg/money"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is the lifecycle state of an invoice
+type Status string
+
+const (
+	StatusDraft         Status = "draft"
+	StatusSent          Status = "sent"
+	StatusViewed        Status = "viewed"
+	StatusPartiallyPaid Status = "partially_paid"
+	StatusPaid          Status = "paid"
+	StatusOverdue       Status = "overdue"
+	StatusVoid          Status = "void"
+)
+
+// transitions lists the statuses an invoice may be moved to explicitly.
+// Viewed is only reached by recording a view, and partially paid and paid
+// only by recording a payment.
+var transitions = map[Status][]Status{
+	StatusDraft:         {StatusSent, StatusVoid},
+	StatusSent:          {StatusOverdue, StatusVoid},
+	StatusViewed:        {StatusOverdue, StatusVoid},
+	StatusOverdue:       {StatusSent, StatusVoid},
+	StatusPartiallyPaid: {StatusOverdue},
+}
+
+// CanTransition reports whether an invoice may be moved from one status to
+// another
+func CanTransition(from, to Status) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEditable reports whether an invoice's details and line items may still
+// be changed. Once money has been received or the invoice is void it is frozen.
+func (s Status) IsEditable() bool {
+	return s == StatusDraft || s == StatusSent || s == StatusViewed || s == StatusOverdue
+}
+
+// AcceptsPayment reports whether a payment can be recorded against an
+// invoice with this status
+func (s Status) AcceptsPayment() bool {
+	return s == StatusSent || s == StatusViewed || s == StatusOverdue || s == StatusPartiallyPaid
+}
+
+// PaymentMethod is how a payment was made
+type PaymentMethod string
+
+const (
+	PaymentBankTransfer PaymentMethod = "bank_transfer"
+	PaymentCard         PaymentMethod = "card"
+	PaymentCash         PaymentMethod = "cash"
+	PaymentOther        PaymentMethod = "other"
+)
+
+// IsValid reports whether m is a known payment method
+func (m PaymentMethod) IsValid() bool {
+	switch m {
+	case PaymentBankTransfer, PaymentCard, PaymentCash, PaymentOther:
+		return true
+	}
+	return false
+}
+
+// Payment terms codes
+const (
+	TermsDueOnReceipt = "DUE_ON_RECEIPT"
+	TermsNet7         = "NET_7"
+	TermsNet14        = "NET_14"
+	TermsNet30        = "NET_30"
+	TermsCustom       = "CUSTOM"
+)
+
+// DueDate returns the due date for an invoice issued on issued with the
+// given payment terms. Custom and unknown terms fall back to 14 days.
+func DueDate(issued time.Time, terms string) time.Time {
+	switch terms {
+	case TermsDueOnReceipt:
+		return issued
+	case TermsNet7:
+		return issued.AddDate(0, 0, 7)
+	case TermsNet30:
+		return issued.AddDate(0, 0, 30)
+	}
+	return issued.AddDate(0, 0, 14)
+}
+
+// Totals are the computed amounts of an invoice
+type Totals struct {
+	Subtotal money.Money `json:"subtotal"`
+	GST      money.Money `json:"gst"`
+	Total    money.Money `json:"total"`
+}
+
+// CalculateTotals sums the line items and applies the discount and GST. The
+// discount is shared across taxable and non-taxable items in proportion to
+// their amounts, so GST is only charged on the discounted taxable amount.
+// Amounts in different currencies return money.ErrCurrencyMismatch.
+func CalculateTotals(items []query.InvoiceLineItem, discount money.Money, gstRegistered bool, gstRate money.Decimal, mode money.Rounding) (Totals, error) {
+	amounts := []money.Money{discount}
+	for _, item := range items {
+		amounts = append(amounts, item.Amount)
+	}
+	if err := money.SameCurrency(amounts...); err != nil {
+		return Totals{}, err
+	}
+
+	var subtotal, taxable money.Money
+	for _, item := range items {
+		subtotal = subtotal.Add(item.Amount)
+		if item.IsTaxable {
+			taxable = taxable.Add(item.Amount)
+		}
+	}
+	t := Totals{Subtotal: subtotal, GST: money.Zero(subtotal.Currency())}
+	if gstRegistered {
+		taxable = taxable.Sub(discount.Prorate(taxable, subtotal, mode))
+		t.GST = taxable.Percent(gstRate, mode)
+	}
+	t.Total = subtotal.Sub(discount).Add(t.GST)
+	return t, nil
+}
+
+// LineItemRequest is the input for adding or editing a line item. The amount
+// is always computed from the quantity and unit price.
+type LineItemRequest struct {
+	Description string        `json:"description"`
+	Quantity    money.Decimal `json:"quantity"`
+	UnitPrice   money.Money   `json:"unitPrice"`
+	IsTaxable   *bool         `json:"isTaxable"`
+	SortOrder   *int32        `json:"sortOrder"`
+	Category    string        `json:"category"`
+	PackageID   *uuid.UUID    `json:"packageId"`
+	AddonID     *uuid.UUID    `json:"addonId"`
+}
+
+// CreateRequest is the input for creating a blank invoice
+type CreateRequest struct {
+	ClientID            *uuid.UUID        `json:"clientId"`
+	ClientBusinessName  string            `json:"clientBusinessName"`
+	ClientContactName   string            `json:"clientContactName"`
+	ClientEmail         string            `json:"clientEmail"`
+	ClientPhone         string            `json:"clientPhone"`
+	ClientAddress       string            `json:"clientAddress"`
+	ClientAbn           string            `json:"clientAbn"`
+	IssueDate           *time.Time        `json:"issueDate"`
+	DueDate             *time.Time        `json:"dueDate"`
+	PaymentTerms        string            `json:"paymentTerms"`
+	PaymentTermsCustom  string            `json:"paymentTermsCustom"`
+	Notes               string            `json:"notes"`
+	PublicNotes         string            `json:"publicNotes"`
+	DiscountAmount      money.Money       `json:"discountAmount"`
+	DiscountDescription string            `json:"discountDescription"`
+	LineItems           []LineItemRequest `json:"lineItems"`
+}
+
+// UpdateRequest is the input for editing an invoice's details. Nil fields are
+// left unchanged.
+type UpdateRequest struct {
+	ClientBusinessName   *string      `json:"clientBusinessName"`
+	ClientContactName    *string      `json:"clientContactName"`
+	ClientEmail          *string      `json:"clientEmail"`
+	ClientPhone          *string      `json:"clientPhone"`
+	ClientAddress        *string      `json:"clientAddress"`
+	ClientAbn            *string      `json:"clientAbn"`
+	IssueDate            *time.Time   `json:"issueDate"`
+	DueDate              *time.Time   `json:"dueDate"`
+	PaymentTerms         *string      `json:"paymentTerms"`
+	PaymentTermsCustom   *string      `json:"paymentTermsCustom"`
+	Notes                *string      `json:"notes"`
+	PublicNotes          *string      `json:"publicNotes"`
+	DiscountAmount       *money.Money `json:"discountAmount"`
+	DiscountDescription  *string      `json:"discountDescription"`
+	OnlinePaymentEnabled *bool        `json:"onlinePaymentEnabled"`
+}
+
+// TransitionRequest is the input for moving an invoice to a new status
+type TransitionRequest struct {
+	Status Status `json:"status"`
+}
+
+// PaymentRequest records a payment against an invoice. A nil amount pays
+// the outstanding balance in full.
+type PaymentRequest struct {
+	Amount    *money.Money  `json:"amount"`
+	Method    PaymentMethod `json:"method"`
+	Reference string        `json:"reference"`
+	Notes     string        `json:"notes"`
+	PaidAt    *time.Time    `json:"paidAt"`
+}
+
+// Detail is an invoice with its line items
+type Detail struct {
+	Invoice   query.Invoice           `json:"invoice"`
+	LineItems []query.InvoiceLineItem `json:"lineItems"`
+}
+
+// ListResponse is a page of invoices for an agency
+type ListResponse struct {
+	Count    int64           `json:"count"`
+	Invoices []query.Invoice `json:"invoices"`
+}
@@ -0,0 +1,91 @@
+package invoice_test
+
+import (
+	"app/pkg/money"
+	"errors"
+	"service-core/domain/invoice"
+	"service-core/storage/query"
+	"testing"
+	"time"
+)
+
+func TestCanTransition(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		from invoice.Status
+		to   invoice.Status
+		want bool
+	}{
+		{invoice.StatusDraft, invoice.StatusSent, true},
+		{invoice.StatusDraft, invoice.StatusVoid, true},
+		{invoice.StatusSent, invoice.StatusOverdue, true},
+		{invoice.StatusViewed, invoice.StatusVoid, true},
+		{invoice.StatusOverdue, invoice.StatusSent, true},
+		{invoice.StatusPartiallyPaid, invoice.StatusOverdue, true},
+		{invoice.StatusDraft, invoice.StatusPaid, false},
+		{invoice.StatusSent, invoice.StatusViewed, false},
+		{invoice.StatusSent, invoice.StatusPaid, false},
+		{invoice.StatusPartiallyPaid, invoice.StatusVoid, false},
+		{invoice.StatusPaid, invoice.StatusVoid, false},
+		{invoice.StatusVoid, invoice.StatusDraft, false},
+	}
+	for _, tt := range tests {
+		if got := invoice.CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateTotals(t *testing.T) {
+	t.Parallel()
+	items := []query.InvoiceLineItem{
+		{Amount: money.MustParse("900.00", money.AUD), IsTaxable: true},
+		{Amount: money.MustParse("100.00", money.AUD), IsTaxable: false},
+	}
+	rate := money.MustParseDecimal("10")
+	tests := []struct {
+		name       string
+		discount   string
+		registered bool
+		subtotal   string
+		gst        string
+		total      string
+	}{
+		{"no discount", "0", true, "1000.00", "90.00", "1090.00"},
+		// 90% of the discount falls on the taxable item
+		{"discount", "100.00", true, "1000.00", "81.00", "981.00"},
+		{"not registered", "100.00", false, "1000.00", "0.00", "900.00"},
+	}
+	for _, tt := range tests {
+		got, err := invoice.CalculateTotals(items, money.MustParse(tt.discount, money.AUD), tt.registered, rate, money.HalfEven)
+		if err != nil {
+			t.Fatalf("%s: CalculateTotals() error = %v", tt.name, err)
+		}
+		if got.Subtotal.String() != tt.subtotal || got.GST.String() != tt.gst || got.Total.String() != tt.total {
+			t.Errorf("%s: totals = %s/%s/%s, want %s/%s/%s", tt.name,
+				got.Subtotal, got.GST, got.Total, tt.subtotal, tt.gst, tt.total)
+		}
+	}
+
+	_, err := invoice.CalculateTotals(items, money.MustParse("100.00", money.USD), true, rate, money.HalfEven)
+	if !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("CalculateTotals() with a USD discount error = %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestDueDate(t *testing.T) {
+	t.Parallel()
+	issued := time.Date(2026, 1, 28, 0, 0, 0, 0, time.UTC)
+	tests := map[string]string{
+		invoice.TermsDueOnReceipt: "2026-01-28",
+		invoice.TermsNet7:         "2026-02-04",
+		invoice.TermsNet14:        "2026-02-11",
+		invoice.TermsNet30:        "2026-02-27",
+		invoice.TermsCustom:       "2026-02-11",
+	}
+	for terms, want := range tests {
+		if got := invoice.DueDate(issued, terms).Format("2006-01-02"); got != want {
+			t.Errorf("DueDate(%s) = %s, want %s", terms, got, want)
+		}
+	}
+}
@@ -0,0 +1,868 @@
+package invoice
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"app/pkg/money"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"service-core/config"
+	"service-core/domain/activity"
+	"service-core/domain/numbering"
+	"service-core/domain/proposal"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// store defines the database interface for invoice operations
+type store interface {
+	CountInvoices(ctx context.Context, arg query.CountInvoicesParams) (int64, error)
+	SelectInvoices(ctx context.Context, arg query.SelectInvoicesParams) ([]query.Invoice, error)
+	SelectInvoice(ctx context.Context, id uuid.UUID) (query.Invoice, error)
+	SelectInvoiceBySlug(ctx context.Context, slug string) (query.Invoice, error)
+	InsertInvoice(ctx context.Context, arg query.InsertInvoiceParams) (query.Invoice, error)
+	UpdateInvoice(ctx context.Context, arg query.UpdateInvoiceParams) (query.Invoice, error)
+	UpdateInvoiceTotals(ctx context.Context, arg query.UpdateInvoiceTotalsParams) (query.Invoice, error)
+	UpdateInvoiceStatus(ctx context.Context, arg query.UpdateInvoiceStatusParams) (query.Invoice, error)
+	RecordInvoicePayment(ctx context.Context, arg query.RecordInvoicePaymentParams) (query.Invoice, error)
+	RecordInvoiceView(ctx context.Context, id uuid.UUID) (query.Invoice, error)
+	DeleteInvoice(ctx context.Context, id uuid.UUID) error
+
+	SelectInvoiceLineItems(ctx context.Context, invoiceID uuid.UUID) ([]query.InvoiceLineItem, error)
+	SelectInvoiceLineItem(ctx context.Context, id uuid.UUID) (query.InvoiceLineItem, error)
+	InsertInvoiceLineItem(ctx context.Context, arg query.InsertInvoiceLineItemParams) (query.InvoiceLineItem, error)
+	UpdateInvoiceLineItem(ctx context.Context, arg query.UpdateInvoiceLineItemParams) (query.InvoiceLineItem, error)
+	DeleteInvoiceLineItem(ctx context.Context, id uuid.UUID) error
+
+	SelectAgencyProfile(ctx context.Context, agencyID uuid.UUID) (query.AgencyProfile, error)
+	SelectContract(ctx context.Context, id uuid.UUID) (query.Contract, error)
+	SelectQuotation(ctx context.Context, id uuid.UUID) (query.Quotation, error)
+	SelectQuotationScopeSections(ctx context.Context, quotationID uuid.UUID) ([]query.QuotationScopeSection, error)
+	InsertActivityLog(ctx context.Context, arg query.InsertActivityLogParams) error
+}
+
+// proposalService loads accepted proposals and their pricing
+type proposalService interface {
+	GetProposal(ctx context.Context, agencyID, id uuid.UUID) (*query.Proposal, error)
+	Price(ctx context.Context, p *query.Proposal) (*proposal.PricingBreakdown, error)
+}
+
+// numberer allocates agency document numbers
+type numberer interface {
+	Allocate(ctx context.Context, agencyID uuid.UUID, docType numbering.DocumentType) (string, error)
+}
+
+// Service handles agency invoice operations
+type Service struct {
+	cfg             *config.Config
+	store           store
+	proposalService proposalService
+	numberer        numberer
+}
+
+// NewService creates a new invoice service
+func NewService(cfg *config.Config, store store, proposalService proposalService, numberer numberer) *Service {
+	return &Service{
+		cfg:             cfg,
+		store:           store,
+		proposalService: proposalService,
+		numberer:        numberer,
+	}
+}
+
+// ListInvoices returns a page of an agency's invoices, optionally filtered by status
+func (s *Service) ListInvoices(
+	ctx context.Context,
+	agencyID uuid.UUID,
+	status string,
+	page int32,
+	limit int32,
+) (*ListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+	count, err := s.store.CountInvoices(ctx, query.CountInvoicesParams{
+		AgencyID: agencyID,
+		Status:   status,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error counting invoices", Err: err}
+	}
+	invoices, err := s.store.SelectInvoices(ctx, query.SelectInvoicesParams{
+		AgencyID:  agencyID,
+		Status:    status,
+		RowLimit:  limit,
+		RowOffset: (page - 1) * limit,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting invoices", Err: err}
+	}
+	if invoices == nil {
+		invoices = []query.Invoice{}
+	}
+	return &ListResponse{
+		Count:    count,
+		Invoices: invoices,
+	}, nil
+}
+
+// GetInvoice returns an invoice belonging to the agency with its line items
+func (s *Service) GetInvoice(ctx context.Context, agencyID, id uuid.UUID) (*Detail, error) {
+	inv, err := s.get(ctx, agencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.lineItems(ctx, inv.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Detail{Invoice: *inv, LineItems: items}, nil
+}
+
+// CreateInvoice creates a draft invoice from scratch
+func (s *Service) CreateInvoice(
+	ctx context.Context,
+	user auth.UserAttr,
+	req CreateRequest,
+) (*Detail, error) {
+	if err := auth.Authorize(auth.CreateInvoice, user, auth.Resource{OwnerID: user.ID, AgencyID: user.AgencyID}); err != nil {
+		return nil, err
+	}
+	profile, err := s.profile(ctx, user.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+	params := newParams(user.AgencyID, user.ID, "", "", profile, today())
+	applyCreate(&params, req)
+
+	err = validate(&schema{
+		clientBusinessName: params.ClientBusinessName,
+		clientEmail:        params.ClientEmail,
+		paymentTerms:       params.PaymentTerms,
+		discountAmount:     params.DiscountAmount,
+		lineItems:          req.LineItems,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := s.create(ctx, params, req.LineItems)
+	if err != nil {
+		return nil, err
+	}
+	s.logActivity(ctx, &d.Invoice, user.ID, "invoice.created", nil, map[string]any{
+		"invoiceNumber": d.Invoice.InvoiceNumber,
+		"total":         d.Invoice.Total,
+	}, nil)
+	return d, nil
+}
+
+// CreateFromProposal creates a draft invoice for the one-time charges of an
+// accepted proposal, carrying over its client, discount and GST settings
+func (s *Service) CreateFromProposal(
+	ctx context.Context,
+	user auth.UserAttr,
+	proposalID uuid.UUID,
+) (*Detail, error) {
+	if err := auth.Authorize(auth.CreateInvoice, user, auth.Resource{OwnerID: user.ID, AgencyID: user.AgencyID}); err != nil {
+		return nil, err
+	}
+	p, err := s.proposalService.GetProposal(ctx, user.AgencyID, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status(p.Status) != proposal.StatusAccepted {
+		return nil, pkg.BadRequestError{
+			Message: "Only accepted proposals can be invoiced",
+			Err:     fmt.Errorf("proposal %s has status %s", p.ID, p.Status),
+		}
+	}
+	pricing, err := s.proposalService.Price(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	items := proposalLineItems(pricing)
+	if len(items) == 0 {
+		return nil, pkg.BadRequestError{
+			Message: "Proposal has no one-time charges to invoice",
+			Err:     errors.New("no one-time line items"),
+		}
+	}
+	profile, err := s.profile(ctx, user.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := newParams(user.AgencyID, user.ID, "", "", profile, today())
+	params.ProposalID = uuid.NullUUID{UUID: p.ID, Valid: true}
+	params.ClientID = p.ClientID
+	params.ClientBusinessName = p.ClientBusinessName
+	params.ClientContactName = p.ClientContactName
+	params.ClientEmail = p.ClientEmail
+	params.ClientPhone = p.ClientPhone
+	params.GstRegistered = pricing.GSTRegistered
+	params.GstRate = pricing.GSTRate
+	params.DiscountAmount = pricing.OneTime.Discount
+	params.DiscountDescription = pricing.DiscountNote
+	if params.DiscountDescription == "" && !pricing.DiscountPercent.IsZero() {
+		params.DiscountDescription = fmt.Sprintf("%s%% discount", pricing.DiscountPercent)
+	}
+
+	d, err := s.create(ctx, params, items)
+	if err != nil {
+		return nil, err
+	}
+	s.logActivity(ctx, &d.Invoice, user.ID, "invoice.created_from_proposal", nil, map[string]any{
+		"invoiceNumber": d.Invoice.InvoiceNumber,
+		"total":         d.Invoice.Total,
+	}, map[string]any{"proposalId": p.ID, "proposalNumber": p.ProposalNumber})
+	return d, nil
+}
+
+// CreateFromContract creates a draft invoice for the total price of a
+// signed contract. A GST inclusive price is split back into its GST
+// exclusive amount so the invoice total matches the contract.
+func (s *Service) CreateFromContract(
+	ctx context.Context,
+	user auth.UserAttr,
+	contractID uuid.UUID,
+) (*Detail, error) {
+	if err := auth.Authorize(auth.CreateInvoice, user, auth.Resource{OwnerID: user.ID, AgencyID: user.AgencyID}); err != nil {
+		return nil, err
+	}
+	c, err := s.store.SelectContract(ctx, contractID)
+	if err != nil || c.AgencyID != user.AgencyID {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Contract not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting contract", Err: err}
+	}
+	if c.Status != "signed" && c.Status != "completed" {
+		return nil, pkg.BadRequestError{
+			Message: "Only signed or completed contracts can be invoiced",
+			Err:     fmt.Errorf("contract %s has status %s", c.ID, c.Status),
+		}
+	}
+	profile, err := s.profile(ctx, user.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := newParams(user.AgencyID, user.ID, "", "", profile, today())
+	params.ContractID = uuid.NullUUID{UUID: c.ID, Valid: true}
+	params.ProposalID = uuid.NullUUID{UUID: c.ProposalID, Valid: c.ProposalID != uuid.Nil}
+	params.ClientID = c.ClientID
+	params.ClientBusinessName = c.ClientBusinessName
+	params.ClientContactName = c.ClientContactName
+	params.ClientEmail = c.ClientEmail
+	params.ClientPhone = c.ClientPhone
+	params.ClientAddress = c.ClientAddress
+
+	price := c.TotalPrice
+	if c.PriceIncludesGst && params.GstRegistered {
+		price = exclusive(price, params.GstRate, s.cfg.Rounding)
+	}
+	description := c.ServicesDescription
+	if description == "" {
+		description = "Services as per contract"
+	}
+	items := []LineItemRequest{{
+		Description: description,
+		Quantity:    money.NewDecimal(1),
+		UnitPrice:   price,
+	}}
+
+	d, err := s.create(ctx, params, items)
+	if err != nil {
+		return nil, err
+	}
+	s.logActivity(ctx, &d.Invoice, user.ID, "invoice.created_from_contract", nil, map[string]any{
+		"invoiceNumber": d.Invoice.InvoiceNumber,
+		"total":         d.Invoice.Total,
+	}, map[string]any{"contractId": c.ID, "contractNumber": c.ContractNumber})
+	return d, nil
+}
+
+// CreateFromQuotation creates a draft invoice for an accepted quotation with
+// one line item per scope section, carrying over its client, discount and
+// GST settings
+func (s *Service) CreateFromQuotation(
+	ctx context.Context,
+	user auth.UserAttr,
+	quotationID uuid.UUID,
+) (*Detail, error) {
+	if err := auth.Authorize(auth.CreateInvoice, user, auth.Resource{OwnerID: user.ID, AgencyID: user.AgencyID}); err != nil {
+		return nil, err
+	}
+	q, err := s.store.SelectQuotation(ctx, quotationID)
+	if err != nil || q.AgencyID != user.AgencyID {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Quotation not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting quotation", Err: err}
+	}
+	if q.Status != "accepted" {
+		return nil, pkg.BadRequestError{
+			Message: "Only accepted quotations can be invoiced",
+			Err:     fmt.Errorf("quotation %s has status %s", q.ID, q.Status),
+		}
+	}
+	sections, err := s.store.SelectQuotationScopeSections(ctx, q.ID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting quotation sections", Err: err}
+	}
+	if len(sections) == 0 {
+		return nil, pkg.BadRequestError{
+			Message: "Quotation has no priced sections to invoice",
+			Err:     errors.New("no quotation sections"),
+		}
+	}
+	profile, err := s.profile(ctx, user.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := newParams(user.AgencyID, user.ID, "", "", profile, today())
+	params.QuotationID = uuid.NullUUID{UUID: q.ID, Valid: true}
+	params.ClientID = q.ClientID
+	params.ClientBusinessName = q.ClientBusinessName
+	params.ClientContactName = q.ClientContactName
+	params.ClientEmail = q.ClientEmail
+	params.ClientPhone = q.ClientPhone
+	params.ClientAddress = q.ClientAddress
+	params.GstRegistered = q.GstRegistered
+	params.GstRate = q.GstRate
+	params.DiscountAmount = q.DiscountAmount
+	params.DiscountDescription = q.DiscountDescription
+
+	taxable := true
+	items := make([]LineItemRequest, 0, len(sections))
+	for _, section := range sections {
+		sortOrder := section.SortOrder
+		items = append(items, LineItemRequest{
+			Description: section.Title,
+			Quantity:    money.NewDecimal(1),
+			UnitPrice:   section.SectionPrice,
+			IsTaxable:   &taxable,
+			SortOrder:   &sortOrder,
+		})
+	}
+
+	d, err := s.create(ctx, params, items)
+	if err != nil {
+		return nil, err
+	}
+	s.logActivity(ctx, &d.Invoice, user.ID, "invoice.created_from_quotation", nil, map[string]any{
+		"invoiceNumber": d.Invoice.InvoiceNumber,
+		"total":         d.Invoice.Total,
+	}, map[string]any{"quotationId": q.ID, "quotationNumber": q.QuotationNumber})
+	return d, nil
+}
+
+// UpdateInvoice edits an invoice's client details, dates, terms, notes and
+// discount, recomputing its totals
+func (s *Service) UpdateInvoice(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	req UpdateRequest,
+) (*Detail, error) {
+	existing, err := s.editable(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+	params := applyUpdate(*existing, req)
+	err = validate(&schema{
+		clientBusinessName: params.ClientBusinessName,
+		clientEmail:        params.ClientEmail,
+		paymentTerms:       params.PaymentTerms,
+		discountAmount:     params.DiscountAmount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := s.store.UpdateInvoice(ctx, params)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating invoice", Err: err}
+	}
+	d, err := s.recalculate(ctx, inv)
+	if err != nil {
+		return nil, err
+	}
+	s.logActivity(ctx, &d.Invoice, user.ID, "invoice.updated", map[string]any{"total": existing.Total}, req, nil)
+	return d, nil
+}
+
+// AddLineItem appends a line item to an invoice and recomputes its totals
+func (s *Service) AddLineItem(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	req LineItemRequest,
+) (*Detail, error) {
+	if err := validateLineItem(req); err != nil {
+		return nil, err
+	}
+	inv, err := s.editable(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.lineItems(ctx, inv.ID)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.insertLineItem(ctx, lineItemParams(inv.ID, nextSortOrder(existing), req, s.cfg.Rounding))
+	if err != nil {
+		return nil, err
+	}
+	d, err := s.recalculate(ctx, *inv)
+	if err != nil {
+		return nil, err
+	}
+	s.logActivity(ctx, &d.Invoice, user.ID, "invoice.line_item_added", nil, item, nil)
+	return d, nil
+}
+
+// UpdateLineItem replaces a line item's details and recomputes the totals
+func (s *Service) UpdateLineItem(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	itemID uuid.UUID,
+	req LineItemRequest,
+) (*Detail, error) {
+	if err := validateLineItem(req); err != nil {
+		return nil, err
+	}
+	inv, err := s.editable(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.lineItem(ctx, inv.ID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	p := lineItemParams(inv.ID, existing.SortOrder, req, s.cfg.Rounding)
+	item, err := s.store.UpdateInvoiceLineItem(ctx, query.UpdateInvoiceLineItemParams{
+		ID:          existing.ID,
+		Description: p.Description,
+		Quantity:    p.Quantity,
+		UnitPrice:   p.UnitPrice,
+		Amount:      p.Amount,
+		IsTaxable:   p.IsTaxable,
+		SortOrder:   p.SortOrder,
+		Category:    p.Category,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating invoice line item", Err: err}
+	}
+	d, err := s.recalculate(ctx, *inv)
+	if err != nil {
+		return nil, err
+	}
+	s.logActivity(ctx, &d.Invoice, user.ID, "invoice.line_item_updated", existing, item, nil)
+	return d, nil
+}
+
+// RemoveLineItem deletes a line item and recomputes the totals
+func (s *Service) RemoveLineItem(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	itemID uuid.UUID,
+) (*Detail, error) {
+	inv, err := s.editable(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.lineItem(ctx, inv.ID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.DeleteInvoiceLineItem(ctx, existing.ID); err != nil {
+		return nil, pkg.InternalError{Message: "Error deleting invoice line item", Err: err}
+	}
+	d, err := s.recalculate(ctx, *inv)
+	if err != nil {
+		return nil, err
+	}
+	s.logActivity(ctx, &d.Invoice, user.ID, "invoice.line_item_removed", existing, nil, nil)
+	return d, nil
+}
+
+// TransitionInvoice moves an invoice to a new status. The update only
+// applies if the status has not changed since it was read.
+func (s *Service) TransitionInvoice(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	req TransitionRequest,
+) (*query.Invoice, error) {
+	existing, err := s.get(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(auth.EditInvoice, user, resource(existing)); err != nil {
+		return nil, err
+	}
+	from := Status(existing.Status)
+	if !CanTransition(from, req.Status) {
+		message := fmt.Sprintf("Cannot change invoice status from %s to %s", from, req.Status)
+		if req.Status == StatusPaid || req.Status == StatusPartiallyPaid {
+			message = "Record a payment to mark an invoice as paid"
+		}
+		return nil, pkg.BadRequestError{Message: message, Err: errors.New("invalid status transition")}
+	}
+
+	params := query.UpdateInvoiceStatusParams{
+		ID:         existing.ID,
+		Status:     string(req.Status),
+		FromStatus: existing.Status,
+	}
+	if req.Status == StatusSent {
+		params.SentAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	inv, err := s.store.UpdateInvoiceStatus(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.BadRequestError{Message: "Invoice status changed, please reload and try again", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error updating invoice status", Err: err}
+	}
+	s.logActivity(ctx, &inv, user.ID, "invoice."+string(req.Status),
+		map[string]any{"status": from},
+		map[string]any{"status": req.Status},
+		nil,
+	)
+	return &inv, nil
+}
+
+// RecordPayment records a payment against an invoice. The invoice becomes
+// paid once the payments cover its total and partially paid until then.
+func (s *Service) RecordPayment(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	req PaymentRequest,
+) (*query.Invoice, error) {
+	if err := validatePayment(req); err != nil {
+		return nil, err
+	}
+	existing, err := s.get(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(auth.EditInvoice, user, resource(existing)); err != nil {
+		return nil, err
+	}
+	from := Status(existing.Status)
+	if !from.AcceptsPayment() {
+		return nil, pkg.BadRequestError{
+			Message: fmt.Sprintf("Cannot record a payment on a %s invoice", from),
+			Err:     errors.New("invoice does not accept payments"),
+		}
+	}
+
+	amount := existing.Total.Sub(existing.AmountPaid)
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+	if err := money.SameCurrency(amount, existing.Total, existing.AmountPaid); err != nil {
+		return nil, pkg.BadRequestError{Message: "Payment must be in the invoice's currency", Err: err}
+	}
+	outstanding := existing.Total.Sub(existing.AmountPaid)
+	if amount.Cmp(outstanding) > 0 {
+		return nil, pkg.ValidationErrors{{
+			Field:   "amount",
+			Tag:     "max",
+			Message: "Payment exceeds the outstanding balance of " + outstanding.Format(),
+		}}
+	}
+
+	paidAt := time.Now()
+	if req.PaidAt != nil {
+		paidAt = *req.PaidAt
+	}
+	paid := existing.AmountPaid.Add(amount)
+	params := query.RecordInvoicePaymentParams{
+		ID:               existing.ID,
+		FromStatus:       existing.Status,
+		Status:           string(StatusPartiallyPaid),
+		AmountPaid:       paid,
+		PaymentMethod:    sql.NullString{String: string(req.Method), Valid: true},
+		PaymentReference: sql.NullString{String: req.Reference, Valid: req.Reference != ""},
+		PaymentNotes:     sql.NullString{String: req.Notes, Valid: req.Notes != ""},
+	}
+	if paid.Cmp(existing.Total) >= 0 {
+		params.Status = string(StatusPaid)
+		params.PaidAt = sql.NullTime{Time: paidAt, Valid: true}
+	}
+
+	inv, err := s.store.RecordInvoicePayment(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.BadRequestError{Message: "Invoice status changed, please reload and try again", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error recording invoice payment", Err: err}
+	}
+	s.logActivity(ctx, &inv, user.ID, "invoice.payment_recorded",
+		map[string]any{"status": from, "amountPaid": existing.AmountPaid},
+		map[string]any{"status": inv.Status, "amountPaid": inv.AmountPaid},
+		map[string]any{"amount": amount, "method": req.Method, "reference": req.Reference, "paidAt": paidAt},
+	)
+	return &inv, nil
+}
+
+// RecordView counts a view of an invoice's public page and moves a sent
+// invoice to viewed. It requires no authentication.
+func (s *Service) RecordView(ctx context.Context, slug string) (*query.Invoice, error) {
+	existing, err := s.store.SelectInvoiceBySlug(ctx, slug)
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: "Invoice not found", Err: err}
+	}
+	inv, err := s.store.RecordInvoiceView(ctx, existing.ID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error recording invoice view", Err: err}
+	}
+	if existing.Status != inv.Status {
+		s.logActivity(ctx, &inv, uuid.Nil, "invoice.viewed",
+			map[string]any{"status": existing.Status},
+			map[string]any{"status": inv.Status},
+			nil,
+		)
+	}
+	return &inv, nil
+}
+
+// DeleteInvoice removes a draft invoice. Invoices that have been sent must
+// be voided instead so the numbering has no silent gaps.
+func (s *Service) DeleteInvoice(ctx context.Context, user auth.UserAttr, id uuid.UUID) error {
+	existing, err := s.get(ctx, user.AgencyID, id)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(auth.RemoveInvoice, user, resource(existing)); err != nil {
+		return err
+	}
+	if Status(existing.Status) != StatusDraft {
+		return pkg.BadRequestError{
+			Message: "Only draft invoices can be deleted, void the invoice instead",
+			Err:     errors.New("invoice is not a draft"),
+		}
+	}
+	if err := s.store.DeleteInvoice(ctx, id); err != nil {
+		return pkg.InternalError{Message: "Error deleting invoice", Err: err}
+	}
+	s.logActivity(ctx, existing, user.ID, "invoice.deleted", map[string]any{
+		"invoiceNumber": existing.InvoiceNumber,
+		"total":         existing.Total,
+	}, nil, nil)
+	return nil
+}
+
+func (s *Service) get(ctx context.Context, agencyID, id uuid.UUID) (*query.Invoice, error) {
+	inv, err := s.store.SelectInvoice(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Invoice not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting invoice", Err: err}
+	}
+	// Invoices from other agencies are reported as missing rather than forbidden
+	if inv.AgencyID != agencyID {
+		return nil, pkg.NotFoundError{Message: "Invoice not found", Err: fmt.Errorf("invoice %s belongs to another agency", id)}
+	}
+	return &inv, nil
+}
+
+// editable returns the invoice if the user may edit it and its details may
+// still be changed
+func (s *Service) editable(ctx context.Context, user auth.UserAttr, id uuid.UUID) (*query.Invoice, error) {
+	inv, err := s.get(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(auth.EditInvoice, user, resource(inv)); err != nil {
+		return nil, err
+	}
+	if !Status(inv.Status).IsEditable() {
+		return nil, pkg.BadRequestError{
+			Message: fmt.Sprintf("Invoices with status %s can no longer be edited", inv.Status),
+			Err:     errors.New("invoice is not editable"),
+		}
+	}
+	return inv, nil
+}
+
+// resource returns the attributes an invoice is authorized against
+func resource(inv *query.Invoice) auth.Resource {
+	return auth.Resource{OwnerID: inv.CreatedBy.UUID, AgencyID: inv.AgencyID}
+}
+
+func (s *Service) profile(ctx context.Context, agencyID uuid.UUID) (query.AgencyProfile, error) {
+	profile, err := s.store.SelectAgencyProfile(ctx, agencyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return profile, pkg.NotFoundError{Message: "Agency profile not found", Err: err}
+		}
+		return profile, pkg.InternalError{Message: "Error selecting agency profile", Err: err}
+	}
+	return profile, nil
+}
+
+func (s *Service) lineItems(ctx context.Context, invoiceID uuid.UUID) ([]query.InvoiceLineItem, error) {
+	items, err := s.store.SelectInvoiceLineItems(ctx, invoiceID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting invoice line items", Err: err}
+	}
+	if items == nil {
+		items = []query.InvoiceLineItem{}
+	}
+	return items, nil
+}
+
+func (s *Service) lineItem(ctx context.Context, invoiceID, id uuid.UUID) (*query.InvoiceLineItem, error) {
+	item, err := s.store.SelectInvoiceLineItem(ctx, id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.InternalError{Message: "Error selecting invoice line item", Err: err}
+	}
+	if err != nil || item.InvoiceID != invoiceID {
+		return nil, pkg.NotFoundError{Message: "Line item not found", Err: err}
+	}
+	return &item, nil
+}
+
+func (s *Service) insertLineItem(ctx context.Context, params query.InsertInvoiceLineItemParams) (*query.InvoiceLineItem, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating line item ID", Err: err}
+	}
+	params.ID = id
+	item, err := s.store.InsertInvoiceLineItem(ctx, params)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error inserting invoice line item", Err: err}
+	}
+	return &item, nil
+}
+
+// create numbers and inserts an invoice with its line items. The totals are
+// computed before the insert so the invoice is never stored without them.
+func (s *Service) create(ctx context.Context, params query.InsertInvoiceParams, reqs []LineItemRequest) (*Detail, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating invoice ID", Err: err}
+	}
+	number, slug, err := s.allocate(ctx, params.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+	params.ID = id
+	params.InvoiceNumber = number
+	params.Slug = slug
+
+	items := make([]query.InsertInvoiceLineItemParams, len(reqs))
+	computed := make([]query.InvoiceLineItem, len(reqs))
+	for i, req := range reqs {
+		items[i] = lineItemParams(id, int32(i), req, s.cfg.Rounding)
+		computed[i] = query.InvoiceLineItem{Amount: items[i].Amount, IsTaxable: items[i].IsTaxable}
+	}
+	t, err := CalculateTotals(computed, params.DiscountAmount, params.GstRegistered, params.GstRate, s.cfg.Rounding)
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: "Invoice amounts must share a currency", Err: err}
+	}
+	params.Subtotal, params.GstAmount, params.Total = t.Subtotal, t.GST, t.Total
+
+	inv, err := s.store.InsertInvoice(ctx, params)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error inserting invoice", Err: err}
+	}
+	d := &Detail{Invoice: inv, LineItems: make([]query.InvoiceLineItem, 0, len(items))}
+	for _, p := range items {
+		item, err := s.insertLineItem(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		d.LineItems = append(d.LineItems, *item)
+	}
+	return d, nil
+}
+
+// recalculate recomputes and stores an invoice's totals from its line items
+func (s *Service) recalculate(ctx context.Context, inv query.Invoice) (*Detail, error) {
+	items, err := s.lineItems(ctx, inv.ID)
+	if err != nil {
+		return nil, err
+	}
+	t, err := CalculateTotals(items, inv.DiscountAmount, inv.GstRegistered, inv.GstRate, s.cfg.Rounding)
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: "Invoice amounts must share a currency", Err: err}
+	}
+	inv, err = s.store.UpdateInvoiceTotals(ctx, query.UpdateInvoiceTotalsParams{
+		ID:        inv.ID,
+		Subtotal:  t.Subtotal,
+		GstAmount: t.GST,
+		Total:     t.Total,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating invoice totals", Err: err}
+	}
+	return &Detail{Invoice: inv, LineItems: items}, nil
+}
+
+// allocate reserves the next invoice number for the agency and a public slug
+func (s *Service) allocate(ctx context.Context, agencyID uuid.UUID) (string, string, error) {
+	number, err := s.numberer.Allocate(ctx, agencyID, numbering.Invoice)
+	if err != nil {
+		return "", "", err
+	}
+	slug, err := newSlug()
+	if err != nil {
+		return "", "", pkg.InternalError{Message: "Error generating invoice slug", Err: err}
+	}
+	return number, slug, nil
+}
+
+// logActivity records a change to an invoice in the agency activity log
+func (s *Service) logActivity(
+	ctx context.Context,
+	inv *query.Invoice,
+	userID uuid.UUID,
+	action string,
+	oldValues any,
+	newValues any,
+	metadata any,
+) {
+	activity.Log(ctx, s.store, activity.Entry{
+		AgencyID:   inv.AgencyID,
+		UserID:     userID,
+		Action:     action,
+		EntityType: "invoice",
+		EntityID:   inv.ID,
+		OldValues:  oldValues,
+		NewValues:  newValues,
+		Metadata:   metadata,
+	})
+}
+
+func nextSortOrder(items []query.InvoiceLineItem) int32 {
+	var next int32
+	for _, item := range items {
+		if item.SortOrder >= next {
+			next = item.SortOrder + 1
+		}
+	}
+	return next
+}
+
+// today returns the start of the current day in UTC
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
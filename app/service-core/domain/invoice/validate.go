@@ -0,0 +1,112 @@
+package invoice
+
+import (
+	"app/pkg"
+	"app/pkg/money"
+	"fmt"
+	"net/mail"
+)
+
+type schema struct {
+	clientBusinessName string
+	clientEmail        string
+	paymentTerms       string
+	discountAmount     money.Money
+	lineItems          []LineItemRequest
+}
+
+func validate(s *schema) error {
+	var errors pkg.ValidationErrors
+	if s.clientBusinessName == "" {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "clientBusinessName",
+			Tag:     "required",
+			Message: "Client business name is required",
+		})
+	}
+	if _, err := mail.ParseAddress(s.clientEmail); err != nil {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "clientEmail",
+			Tag:     "email",
+			Message: "Client email must be a valid email address",
+		})
+	}
+	switch s.paymentTerms {
+	case TermsDueOnReceipt, TermsNet7, TermsNet14, TermsNet30, TermsCustom:
+	default:
+		errors = append(errors, pkg.ValidationError{
+			Field:   "paymentTerms",
+			Tag:     "oneof",
+			Message: "Payment terms must be one of DUE_ON_RECEIPT, NET_7, NET_14, NET_30 or CUSTOM",
+		})
+	}
+	if s.discountAmount.IsNegative() {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "discountAmount",
+			Tag:     "min",
+			Message: "Discount cannot be negative",
+		})
+	}
+	errors = append(errors, validateLineItems(s.lineItems, "lineItems")...)
+
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+// validateLineItems checks requested line items, naming fields by their
+// position in the request
+func validateLineItems(items []LineItemRequest, prefix string) pkg.ValidationErrors {
+	var errors pkg.ValidationErrors
+	for i, item := range items {
+		field := ""
+		if prefix != "" {
+			field = fmt.Sprintf("%s[%d].", prefix, i)
+		}
+		if item.Description == "" {
+			errors = append(errors, pkg.ValidationError{
+				Field:   field + "description",
+				Tag:     "required",
+				Message: "Description is required",
+			})
+		}
+		if item.Quantity.Cmp(money.Decimal{}) <= 0 {
+			errors = append(errors, pkg.ValidationError{
+				Field:   field + "quantity",
+				Tag:     "gt0",
+				Message: "Quantity must be greater than zero",
+			})
+		}
+	}
+	return errors
+}
+
+func validateLineItem(item LineItemRequest) error {
+	if errors := validateLineItems([]LineItemRequest{item}, ""); len(errors) > 0 {
+		return errors
+	}
+	return nil
+}
+
+func validatePayment(req PaymentRequest) error {
+	var errors pkg.ValidationErrors
+	if !req.Method.IsValid() {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "method",
+			Tag:     "oneof",
+			Message: "Payment method must be one of bank_transfer, card, cash or other",
+		})
+	}
+	if req.Amount != nil && (req.Amount.IsNegative() || req.Amount.IsZero()) {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "amount",
+			Tag:     "gt0",
+			Message: "Payment amount must be greater than zero",
+		})
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
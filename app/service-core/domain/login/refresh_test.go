@@ -0,0 +1,313 @@
+package login_test
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"database/sql"
+	"errors"
+	"service-core/config"
+	"service-core/domain/login"
+	"service-core/domain/session"
+	"service-core/storage/query"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// mockStore keeps tokens in memory. Refresh tokens are their own IDs, so
+// tests can present them directly.
+type mockStore struct {
+	mu     sync.Mutex
+	tokens map[string]query.Token
+	used   map[uuid.UUID]bool
+	user   query.User
+}
+
+func (m *mockStore) SelectToken(ctx context.Context, id string) (query.Token, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tokens[id]
+	if !ok {
+		return query.Token{}, sql.ErrNoRows
+	}
+	return t, nil
+}
+
+func (m *mockStore) InsertToken(ctx context.Context, arg query.InsertTokenParams) (query.Token, error) {
+	return query.Token{}, errors.New("not implemented")
+}
+
+func (m *mockStore) UpdateToken(ctx context.Context, arg query.UpdateTokenParams) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockStore) InsertSessionToken(ctx context.Context, arg query.InsertSessionTokenParams) (query.Token, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := query.Token{
+		ID:        arg.ID,
+		Expires:   arg.Expires,
+		Target:    arg.Target,
+		Family:    arg.Family,
+		Created:   time.Now(),
+		UserAgent: arg.UserAgent,
+		Ip:        arg.Ip,
+	}
+	m.tokens[t.ID] = t
+	return t, nil
+}
+
+func (m *mockStore) RotateToken(ctx context.Context, id string) (query.Token, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tokens[id]
+	if !ok || t.Rotated.Valid || t.Target == "" {
+		return query.Token{}, sql.ErrNoRows
+	}
+	t.Rotated = sql.NullTime{Time: time.Now(), Valid: true}
+	m.tokens[id] = t
+	return t, nil
+}
+
+func (m *mockStore) RevokeTokenFamily(ctx context.Context, family string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, t := range m.tokens {
+		if t.Family == family {
+			t.Target = ""
+			m.tokens[id] = t
+		}
+	}
+	return nil
+}
+
+func (m *mockStore) SelectSessionActive(ctx context.Context, family string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.tokens {
+		if t.Family == family && t.Target != "" && t.Expires.After(time.Now()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockStore) SelectUser(ctx context.Context, id uuid.UUID) (query.User, error) {
+	return m.user, nil
+}
+
+func (m *mockStore) SelectUserByEmail(ctx context.Context, email string) (query.User, error) {
+	return query.User{}, sql.ErrNoRows
+}
+
+func (m *mockStore) SelectUserByEmailAndSub(ctx context.Context, arg query.SelectUserByEmailAndSubParams) (query.User, error) {
+	return query.User{}, sql.ErrNoRows
+}
+
+func (m *mockStore) InsertUser(ctx context.Context, arg query.InsertUserParams) (query.User, error) {
+	return query.User{}, errors.New("not implemented")
+}
+
+func (m *mockStore) UpdateUserActivity(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (m *mockStore) UpdateUserAccess(ctx context.Context, arg query.UpdateUserAccessParams) (query.User, error) {
+	return query.User{}, errors.New("not implemented")
+}
+
+func (m *mockStore) UpdateUserPhone(ctx context.Context, arg query.UpdateUserPhoneParams) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockStore) UpdateUserSub(ctx context.Context, arg query.UpdateUserSubParams) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockStore) AcceptPendingMemberships(ctx context.Context, userID uuid.UUID) error {
+	return nil
+}
+
+func (m *mockStore) SelectDefaultAgencyMembership(ctx context.Context, userID uuid.UUID) (query.AgencyMembership, error) {
+	return query.AgencyMembership{}, sql.ErrNoRows
+}
+
+func (m *mockStore) InsertUsedSessionToken(ctx context.Context, params query.InsertUsedSessionTokenParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.used[params.ID] {
+		return 0, nil
+	}
+	m.used[params.ID] = true
+	return 1, nil
+}
+
+func (m *mockStore) DeleteExpiredUsedSessionTokens(ctx context.Context) error {
+	return nil
+}
+
+// mockAuthService issues tokens that are the IDs they stand for: access
+// tokens are session IDs and refresh tokens are token IDs
+type mockAuthService struct {
+	userID uuid.UUID
+}
+
+func (m *mockAuthService) GenerateSessionToken(userID string, phone string, method string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (m *mockAuthService) ValidateSessionToken(token string) (*auth.SessionTokenClaims, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockAuthService) GenerateTokens(refreshTokenID, sessionID, userID string, access int64, avatar, email string, subscriptionActive bool, agencyID string, agencyRole auth.Role) (string, string, error) {
+	return sessionID, refreshTokenID, nil
+}
+
+func (m *mockAuthService) ValidateAccessToken(token string) (*auth.AccessTokenClaims, error) {
+	sessionID, err := uuid.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	return &auth.AccessTokenClaims{ID: m.userID, SessionID: sessionID}, nil
+}
+
+func (m *mockAuthService) ValidateRefreshToken(token string) (*auth.RefreshTokenClaims, error) {
+	id, err := uuid.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	return &auth.RefreshTokenClaims{ID: id, UserID: m.userID}, nil
+}
+
+// token returns a stored refresh token of the user in the given family,
+// rotated the given time ago, or not rotated when rotated is zero
+func token(userID uuid.UUID, family string, rotated time.Duration) query.Token {
+	t := query.Token{
+		ID:      uuid.NewString(),
+		Expires: time.Now().Add(time.Hour),
+		Target:  userID.String(),
+		Family:  family,
+		Created: time.Now().Add(-rotated),
+	}
+	if rotated > 0 {
+		t.Rotated = sql.NullTime{Time: time.Now().Add(-rotated), Valid: true}
+	}
+	return t
+}
+
+func TestRefresh(t *testing.T) {
+	t.Parallel()
+	userID := uuid.New()
+	cfg := &config.Config{ContextTimeout: time.Second, RefreshTokenExp: time.Hour}
+
+	newService := func(tokens ...query.Token) (*login.Service, *mockStore) {
+		store := &mockStore{
+			tokens: map[string]query.Token{},
+			user:   query.User{ID: userID, Email: "user@example.com", Access: auth.NewUserAccess},
+		}
+		for _, t := range tokens {
+			store.tokens[t.ID] = t
+		}
+		return login.NewService(cfg, store, &mockAuthService{userID: userID}, nil, nil, nil), store
+	}
+	client := session.Client{UserAgent: "test", IP: "192.0.2.1"}
+
+	t.Run("rotates within the family", func(t *testing.T) {
+		t.Parallel()
+		family := uuid.NewString()
+		current := token(userID, family, 0)
+		s, store := newService(current)
+
+		response, err := s.Refresh(context.Background(), "", current.ID, client)
+		if err != nil {
+			t.Fatalf("Refresh() error = %v", err)
+		}
+		next, err := store.SelectToken(context.Background(), response.RefreshToken)
+		if err != nil {
+			t.Fatalf("new refresh token not stored: %v", err)
+		}
+		if next.Family != family || next.Target != userID.String() || next.UserAgent != "test" {
+			t.Errorf("new token = %+v, want family %s of user %s", next, family, userID)
+		}
+		if response.AccessToken != family {
+			t.Errorf("access token session = %s, want %s", response.AccessToken, family)
+		}
+		old, _ := store.SelectToken(context.Background(), current.ID)
+		if !old.Rotated.Valid {
+			t.Error("presented token was not rotated")
+		}
+	})
+
+	t.Run("refusing a token already rotated", func(t *testing.T) {
+		t.Parallel()
+		family := uuid.NewString()
+		current := token(userID, family, 0)
+		s, _ := newService(current)
+
+		if _, err := s.Refresh(context.Background(), "", current.ID, client); err != nil {
+			t.Fatalf("first Refresh() error = %v", err)
+		}
+		_, err := s.Refresh(context.Background(), "", current.ID, client)
+		var unauthorized pkg.UnauthorizedError
+		if !errors.As(err, &unauthorized) {
+			t.Fatalf("second Refresh() error = %v, want UnauthorizedError", err)
+		}
+	})
+
+	tests := []struct {
+		name        string
+		rotated     time.Duration
+		wantRevoked bool
+	}{
+		{name: "reuse within the grace period keeps the session", rotated: 5 * time.Second, wantRevoked: false},
+		{name: "reuse after the grace period revokes the session", rotated: time.Minute, wantRevoked: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			family := uuid.NewString()
+			reused := token(userID, family, tt.rotated)
+			current := token(userID, family, 0)
+			other := token(userID, uuid.NewString(), 0)
+			s, store := newService(reused, current, other)
+
+			_, err := s.Refresh(context.Background(), "", reused.ID, client)
+			var unauthorized pkg.UnauthorizedError
+			if !errors.As(err, &unauthorized) {
+				t.Fatalf("Refresh() error = %v, want UnauthorizedError", err)
+			}
+			active, _ := store.SelectSessionActive(context.Background(), family)
+			if active == tt.wantRevoked {
+				t.Errorf("session active = %v, want %v", active, !tt.wantRevoked)
+			}
+			if active, _ := store.SelectSessionActive(context.Background(), other.Family); !active {
+				t.Error("another session was revoked")
+			}
+			_, err = s.Refresh(context.Background(), "", current.ID, client)
+			if tt.wantRevoked && err == nil {
+				t.Error("current token of the revoked session still refreshes")
+			}
+			if !tt.wantRevoked && err != nil {
+				t.Errorf("current token of the session does not refresh: %v", err)
+			}
+		})
+	}
+
+	t.Run("access token of a revoked session", func(t *testing.T) {
+		t.Parallel()
+		family := uuid.NewString()
+		current := token(userID, family, 0)
+		current.Target = ""
+		s, _ := newService(current)
+
+		_, err := s.Refresh(context.Background(), family, current.ID, client)
+		var unauthorized pkg.UnauthorizedError
+		if !errors.As(err, &unauthorized) {
+			t.Fatalf("Refresh() error = %v, want UnauthorizedError", err)
+		}
+	})
+}
@@ -5,12 +5,16 @@ import (
 	"app/pkg/auth"
 	"app/pkg/str"
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/mail"
 	"net/url"
 	"service-core/config"
+	"service-core/domain/email"
+	"service-core/domain/passkey"
+	"service-core/domain/session"
 	"service-core/storage/query"
 	"strings"
 	"time"
@@ -21,10 +25,19 @@ import (
 	"golang.org/x/oauth2"
 )
 
+// reuseGrace is how long after a refresh token is rotated it may be
+// presented again without revoking its session. Pages that refresh at the
+// same time present the same token, and only one of them can rotate it.
+const reuseGrace = 30 * time.Second
+
 type store interface {
 	SelectToken(ctx context.Context, ID string) (query.Token, error)
 	InsertToken(ctx context.Context, params query.InsertTokenParams) (query.Token, error)
 	UpdateToken(ctx context.Context, params query.UpdateTokenParams) error
+	InsertSessionToken(ctx context.Context, params query.InsertSessionTokenParams) (query.Token, error)
+	RotateToken(ctx context.Context, ID string) (query.Token, error)
+	RevokeTokenFamily(ctx context.Context, family string) error
+	SelectSessionActive(ctx context.Context, family string) (bool, error)
 	SelectUser(ctx context.Context, ID uuid.UUID) (query.User, error)
 	SelectUserByEmail(ctx context.Context, email string) (query.User, error)
 	SelectUserByEmailAndSub(ctx context.Context, params query.SelectUserByEmailAndSubParams) (query.User, error)
@@ -34,6 +47,9 @@ type store interface {
 	UpdateUserPhone(ctx context.Context, params query.UpdateUserPhoneParams) error
 	UpdateUserSub(ctx context.Context, params query.UpdateUserSubParams) error
 	AcceptPendingMemberships(ctx context.Context, userID uuid.UUID) error
+	SelectDefaultAgencyMembership(ctx context.Context, userID uuid.UUID) (query.AgencyMembership, error)
+	InsertUsedSessionToken(ctx context.Context, params query.InsertUsedSessionTokenParams) (int64, error)
+	DeleteExpiredUsedSessionTokens(ctx context.Context) error
 }
 
 type provider interface {
@@ -42,30 +58,37 @@ type provider interface {
 }
 
 type authService interface {
-	GenerateSessionToken(userID string, phone string) (string, error)
+	GenerateSessionToken(userID string, phone string, method string) (string, error)
 	ValidateSessionToken(token string) (*auth.SessionTokenClaims, error)
 
-	GenerateTokens(refreshTokenID string, userID string, access int64, avatar string, email string, subscriptionActive bool) (string, string, error)
+	GenerateTokens(refreshTokenID string, sessionID string, userID string, access int64, avatar string, email string, subscriptionActive bool, agencyID string, agencyRole auth.Role) (string, string, error)
 	ValidateAccessToken(token string) (*auth.AccessTokenClaims, error)
 	ValidateRefreshToken(token string) (*auth.RefreshTokenClaims, error)
 }
 
 type emailService interface {
-	SendEmail(
-		ctx context.Context,
-		userID uuid.UUID,
-		emailTo string,
-		emailSubject string,
-		emailBody string,
-		attachmentIDs []uuid.UUID,
-	) (*query.Email, error)
+	SendTemplateEmail(ctx context.Context, msg email.TemplateMessage) (*query.Email, error)
+}
+
+type twoFactorService interface {
+	Enabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	Verify(ctx context.Context, userID uuid.UUID, code string) error
+}
+
+type passkeyService interface {
+	Enabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	LoginOptions(ctx context.Context, returnURL string) (*passkey.RequestOptions, error)
+	Login(ctx context.Context, cred passkey.AssertionCredential) (*passkey.Assertion, error)
+	Verify(ctx context.Context, userID uuid.UUID, cred passkey.AssertionCredential) error
 }
 
 type Service struct {
-	cfg          *config.Config
-	store        store
-	authService  authService
-	emailService emailService
+	cfg              *config.Config
+	store            store
+	authService      authService
+	emailService     emailService
+	twoFactorService twoFactorService
+	passkeyService   passkeyService
 }
 
 func NewService(
@@ -73,12 +96,16 @@ func NewService(
 	store store,
 	authService authService,
 	emailService emailService,
+	twoFactorService twoFactorService,
+	passkeyService passkeyService,
 ) *Service {
 	return &Service{
-		cfg:          cfg,
-		store:        store,
-		authService:  authService,
-		emailService: emailService,
+		cfg:              cfg,
+		store:            store,
+		authService:      authService,
+		emailService:     emailService,
+		twoFactorService: twoFactorService,
+		passkeyService:   passkeyService,
 	}
 }
 
@@ -96,19 +123,35 @@ type AuthResponse struct {
 	ReturnURL    string    `json:"return_url"`
 	User         *AuthUser `json:"user"`
 	// used for 2FA
-	SessionToken string `json:"session_token"`
-	HasPhone     bool   `json:"has_phone"`
+	SessionToken    string `json:"session_token"`
+	HasPhone        bool   `json:"has_phone"`
+	TwoFactorMethod string `json:"two_factor_method"`
 }
 
 type URLResponse struct {
 	URL string `json:"url"`
 }
 
-func (s *Service) Refresh(ctx context.Context, accessToken string, refreshToken string) (*AuthResponse, error) {
+// Refresh exchanges a refresh token for new tokens. Each refresh token can
+// be exchanged once: the next one is issued in the same family, and a
+// rotated token presented again after reuseGrace is taken as stolen and
+// revokes the whole family. The client describes the device refreshing;
+// when it is empty, the session keeps the device it was signed in with.
+func (s *Service) Refresh(ctx context.Context, accessToken string, refreshToken string, client session.Client) (*AuthResponse, error) {
 	// Validate token
 	claims, err := s.authService.ValidateAccessToken(accessToken)
 
 	if err == nil {
+		// The access token is only reused while its session is active
+		if claims.SessionID != uuid.Nil {
+			active, err := s.store.SelectSessionActive(ctx, claims.SessionID.String())
+			if err != nil {
+				return nil, pkg.InternalError{Message: "Error selecting session", Err: err}
+			}
+			if !active {
+				return nil, pkg.UnauthorizedError{Err: errors.New("session has been revoked")}
+			}
+		}
 		// Get user from database
 		user, err := s.store.SelectUser(ctx, claims.ID)
 		if err != nil {
@@ -152,23 +195,38 @@ func (s *Service) Refresh(ctx context.Context, accessToken string, refreshToken
 	if time.Now().After(refreshTokenStore.Expires) {
 		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("token expired: %w", err)}
 	}
+	// Check if token has already been exchanged
+	if refreshTokenStore.Rotated.Valid {
+		return nil, s.reused(ctx, refreshTokenStore)
+	}
+	// Rotate the token. Only one exchange of a token succeeds, so a
+	// concurrent refresh with the same token fails here.
+	refreshTokenStore, err = s.store.RotateToken(ctx, refreshTokenStore.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.UnauthorizedError{Err: errors.New("token has already been refreshed")}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error rotating refresh token", Err: err}
+	}
 	// Get user from database
 	user, err := s.store.SelectUser(ctx, refreshTokenClaims.UserID)
 	if err != nil {
 		return nil, pkg.NotFoundError{Message: "Error selecting user by ID", Err: err}
 	}
-	// Create refresh token (valid for 30 days)
-	id, err := uuid.NewV7()
-	if err != nil {
-		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("error generating UUID: %w", err)}
+	// Tokens issued before families were added start one
+	family := refreshTokenStore.Family
+	if family == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, pkg.UnauthorizedError{Err: fmt.Errorf("error generating UUID: %w", err)}
+		}
+		family = id.String()
 	}
-	params := query.InsertTokenParams{
-		ID:       id.String(),
-		Expires:  time.Now().Add(s.cfg.RefreshTokenExp),
-		Target:   refreshTokenClaims.UserID.String(),
-		Callback: "",
+	if client.UserAgent == "" && client.IP == "" {
+		client = session.Client{UserAgent: refreshTokenStore.UserAgent, IP: refreshTokenStore.Ip}
 	}
-	refreshTokenStore, err = s.store.InsertToken(ctx, params)
+	// Create the next refresh token in the family
+	refreshTokenStore, err = s.insertSessionToken(ctx, refreshTokenClaims.UserID, family, client)
 	if err != nil {
 		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("error inserting refresh token: %w", err)}
 	}
@@ -178,34 +236,33 @@ func (s *Service) Refresh(ctx context.Context, accessToken string, refreshToken
 		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("error checking user access: %w", err)}
 	}
 
+	agencyID, agencyRole, err := s.defaultAgency(ctx, user.ID)
+	if err != nil {
+		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("error selecting agency membership: %w", err)}
+	}
+
 	// Generate JWT tokens
 	newAccessToken, newRefreshToken, err := s.authService.GenerateTokens(
 		refreshTokenStore.ID,
+		refreshTokenStore.Family,
 		user.ID.String(),
 		access,
 		user.Avatar,
 		user.Email,
 		subscriptionActive,
+		agencyID,
+		agencyRole,
 	)
 	if err != nil {
 		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("error generating JWT token: %w", err)}
 	}
 
-	// Update user activity and update old refresh token expiration to 1 minute
-	// This is to prevent the refresh token from being used again
+	// Update user activity
 	//nolint: contextcheck
 	go func() {
 		newCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ContextTimeout)
 		defer cancel()
-		params := query.UpdateTokenParams{
-			ID:      refreshTokenClaims.ID.String(),
-			Expires: time.Now().Add(time.Minute),
-		}
-		err = s.store.UpdateToken(newCtx, params)
-		if err != nil {
-			slog.Error("Error updating token", "error", err)
-		}
-		err = s.store.UpdateUserActivity(newCtx, user.ID)
+		err := s.store.UpdateUserActivity(newCtx, user.ID)
 		if err != nil {
 			slog.Error("Error updating user", "error", err)
 		}
@@ -227,6 +284,48 @@ func (s *Service) Refresh(ctx context.Context, accessToken string, refreshToken
 	}, nil
 }
 
+// reused handles a refresh token presented after it was exchanged. Within
+// reuseGrace it is refused; after that, it can only be a copy of the token,
+// so the session it belongs to is revoked.
+func (s *Service) reused(ctx context.Context, token query.Token) error {
+	if time.Since(token.Rotated.Time) < reuseGrace {
+		return pkg.UnauthorizedError{Err: errors.New("token has already been refreshed")}
+	}
+	slog.Warn("Refresh token reused, revoking session", "token_id", token.ID, "session_id", token.Family, "user_id", token.Target)
+	if token.Family == "" {
+		return pkg.UnauthorizedError{Err: errors.New("token has already been refreshed")}
+	}
+	if err := s.store.RevokeTokenFamily(ctx, token.Family); err != nil {
+		return pkg.InternalError{Message: "Error revoking session", Err: err}
+	}
+	return pkg.UnauthorizedError{Err: errors.New("refresh token reused, session revoked")}
+}
+
+// Logout revokes the session a refresh token belongs to
+func (s *Service) Logout(ctx context.Context, refreshToken string) error {
+	claims, err := s.authService.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return pkg.UnauthorizedError{Err: fmt.Errorf("error validating refresh token: %w", err)}
+	}
+	token, err := s.store.SelectToken(ctx, claims.ID.String())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return pkg.InternalError{Message: "Error selecting token by ID", Err: err}
+	}
+	// Tokens issued before families were added are expired on their own
+	if token.Family == "" {
+		err = s.store.UpdateToken(ctx, query.UpdateTokenParams{ID: token.ID, Expires: time.Now()})
+	} else {
+		err = s.store.RevokeTokenFamily(ctx, token.Family)
+	}
+	if err != nil {
+		return pkg.InternalError{Message: "Error revoking session", Err: err}
+	}
+	return nil
+}
+
 func (s *Service) Login(
 	ctx context.Context,
 	userEmail string,
@@ -272,84 +371,13 @@ func (s *Service) Login(
 			v = append(v, validationError)
 			return nil, v
 		}
-		subject := "Sign in to Webkit"
 		loginURL := s.cfg.CoreURL + `/login-callback/email?state=` + url.QueryEscape(state) + `&email=` + url.QueryEscape(userEmail)
-		body := `<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="utf-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Sign in to Webkit</title>
-</head>
-<body style="margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; background-color: #f4f4f5;">
-    <table role="presentation" width="100%" cellspacing="0" cellpadding="0" style="max-width: 600px; margin: 0 auto; padding: 40px 20px;">
-        <tr>
-            <td style="background-color: #ffffff; border-radius: 12px; padding: 40px; box-shadow: 0 1px 3px rgba(0,0,0,0.1);">
-                <!-- Logo/Brand -->
-                <table role="presentation" width="100%" cellspacing="0" cellpadding="0">
-                    <tr>
-                        <td style="padding-bottom: 24px; text-align: center;">
-                            <span style="font-size: 28px; font-weight: 700; color: #6366f1;">Webkit</span>
-                        </td>
-                    </tr>
-                </table>
-
-                <!-- Main Content -->
-                <table role="presentation" width="100%" cellspacing="0" cellpadding="0">
-                    <tr>
-                        <td style="padding-bottom: 16px;">
-                            <h1 style="margin: 0; font-size: 24px; font-weight: 600; color: #18181b; text-align: center;">Sign in to your account</h1>
-                        </td>
-                    </tr>
-                    <tr>
-                        <td style="padding-bottom: 24px;">
-                            <p style="margin: 0; font-size: 16px; line-height: 24px; color: #52525b; text-align: center;">
-                                Click the button below to securely sign in to Webkit. This link will expire in 15 minutes.
-                            </p>
-                        </td>
-                    </tr>
-                    <tr>
-                        <td style="padding-bottom: 24px; text-align: center;">
-                            <a href="` + loginURL + `" style="display: inline-block; padding: 14px 32px; background-color: #6366f1; color: #ffffff; text-decoration: none; font-size: 16px; font-weight: 600; border-radius: 8px;">Sign In</a>
-                        </td>
-                    </tr>
-                    <tr>
-                        <td style="padding-bottom: 24px;">
-                            <p style="margin: 0; font-size: 14px; line-height: 20px; color: #71717a; text-align: center;">
-                                If the button doesn't work, copy and paste this link into your browser:
-                            </p>
-                            <p style="margin: 8px 0 0 0; font-size: 12px; line-height: 18px; color: #a1a1aa; text-align: center; word-break: break-all;">
-                                ` + loginURL + `
-                            </p>
-                        </td>
-                    </tr>
-                </table>
-
-                <!-- Security Notice -->
-                <table role="presentation" width="100%" cellspacing="0" cellpadding="0">
-                    <tr>
-                        <td style="border-top: 1px solid #e4e4e7; padding-top: 24px;">
-                            <p style="margin: 0; font-size: 13px; line-height: 20px; color: #a1a1aa; text-align: center;">
-                                If you didn't request this email, you can safely ignore it. Never share this link with anyone.
-                            </p>
-                        </td>
-                    </tr>
-                </table>
-            </td>
-        </tr>
-
-        <!-- Footer -->
-        <tr>
-            <td style="padding-top: 24px; text-align: center;">
-                <p style="margin: 0; font-size: 12px; color: #a1a1aa;">
-                    © 2025 Webkit. All rights reserved.
-                </p>
-            </td>
-        </tr>
-    </table>
-</body>
-</html>`
-		_, err = s.emailService.SendEmail(ctx, uuid.Nil, userEmail, subject, body, nil)
+		_, err = s.emailService.SendTemplateEmail(ctx, email.TemplateMessage{
+			UserID:   uuid.Nil,
+			To:       userEmail,
+			Template: email.TemplateLogin,
+			Data:     map[string]any{"LoginURL": loginURL},
+		})
 		if err != nil {
 			return nil, pkg.UnauthorizedError{Err: fmt.Errorf("error sending email: %w", err)}
 		}
@@ -367,6 +395,7 @@ func (s *Service) LoginCallback(
 	code string,
 	email string,
 	provider Provider,
+	client session.Client,
 ) (*AuthResponse, error) {
 	// Get verifier from state
 	token, err := s.store.SelectToken(ctx, state)
@@ -458,9 +487,43 @@ func (s *Service) LoginCallback(
 		}
 	}
 
+	// Users with passkeys complete sign-in with one of them, and users
+	// enrolled in TOTP with their authenticator app, whether or not SMS is
+	// configured
+	passkeysEnabled, err := s.passkeyService.Enabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if passkeysEnabled {
+		sessionToken, err := s.authService.GenerateSessionToken(user.ID.String(), "", auth.TwoFactorPasskey)
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error generating session token", Err: fmt.Errorf("error generating session token: %w", err)}
+		}
+		return &AuthResponse{
+			ReturnURL:       token.Callback,
+			SessionToken:    sessionToken,
+			TwoFactorMethod: auth.TwoFactorPasskey,
+		}, nil
+	}
+	totpEnabled, err := s.twoFactorService.Enabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if totpEnabled {
+		sessionToken, err := s.authService.GenerateSessionToken(user.ID.String(), "", auth.TwoFactorTOTP)
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error generating session token", Err: fmt.Errorf("error generating session token: %w", err)}
+		}
+		return &AuthResponse{
+			ReturnURL:       token.Callback,
+			SessionToken:    sessionToken,
+			TwoFactorMethod: auth.TwoFactorTOTP,
+		}, nil
+	}
+
 	// If Twilio is not configured, skip 2FA
 	if s.cfg.TwilioServiceSID == "" {
-		authResponse, err := s.createAuthTokens(ctx, user)
+		authResponse, err := s.createAuthTokens(ctx, user, client)
 		if err != nil {
 			return nil, pkg.InternalError{Message: "Error creating auth tokens", Err: fmt.Errorf("error creating auth tokens: %w", err)}
 		}
@@ -475,26 +538,28 @@ func (s *Service) LoginCallback(
 			return nil, pkg.InternalError{Message: "Error sending SMS", Err: fmt.Errorf("error sending SMS: %w", err)}
 		}
 		return &AuthResponse{
-			ReturnURL:    token.Callback,
-			SessionToken: sessionToken,
-			HasPhone:     true,
-			AccessToken:  "",
-			RefreshToken: "",
-			User:         nil,
+			ReturnURL:       token.Callback,
+			SessionToken:    sessionToken,
+			HasPhone:        true,
+			TwoFactorMethod: auth.TwoFactorSMS,
+			AccessToken:     "",
+			RefreshToken:    "",
+			User:            nil,
 		}, nil
 	}
 	// Generate session token
-	sessionToken, err := s.authService.GenerateSessionToken(user.ID.String(), "")
+	sessionToken, err := s.authService.GenerateSessionToken(user.ID.String(), "", auth.TwoFactorSMS)
 	if err != nil {
 		return nil, pkg.InternalError{Message: "Error generating session token", Err: fmt.Errorf("error generating session token: %w", err)}
 	}
 	return &AuthResponse{
-		ReturnURL:    token.Callback,
-		SessionToken: sessionToken,
-		HasPhone:     false,
-		AccessToken:  "",
-		RefreshToken: "",
-		User:         nil,
+		ReturnURL:       token.Callback,
+		SessionToken:    sessionToken,
+		HasPhone:        false,
+		TwoFactorMethod: auth.TwoFactorSMS,
+		AccessToken:     "",
+		RefreshToken:    "",
+		User:            nil,
 	}, nil
 }
 
@@ -516,7 +581,7 @@ func (s *Service) LoginPhone(
 		return "", pkg.UnauthorizedError{Err: fmt.Errorf("error sending SMS: %w", err)}
 	}
 	// Generate session token
-	sessionToken, err := s.authService.GenerateSessionToken(userID.String(), phone)
+	sessionToken, err := s.authService.GenerateSessionToken(userID.String(), phone, auth.TwoFactorSMS)
 	if err != nil {
 		return "", pkg.UnauthorizedError{Err: fmt.Errorf("error generating session token: %w", err)}
 	}
@@ -528,18 +593,19 @@ func (s *Service) LoginVerify(
 	userID uuid.UUID,
 	phone string,
 	code string,
+	client session.Client,
 ) (*AuthResponse, error) {
 	user, err := s.store.SelectUser(ctx, userID)
 	if err != nil {
 		return nil, pkg.NotFoundError{Message: "Error selecting user by ID", Err: fmt.Errorf("error selecting user by ID: %w", err)}
 	}
 
-	client := twilio.NewRestClient()
+	twilioClient := twilio.NewRestClient()
 	params := &verify.CreateVerificationCheckParams{}
 	params.SetTo(phone)
 	params.SetCode(code)
 
-	r, err := client.VerifyV2.CreateVerificationCheck(s.cfg.TwilioServiceSID, params)
+	r, err := twilioClient.VerifyV2.CreateVerificationCheck(s.cfg.TwilioServiceSID, params)
 	if err != nil {
 		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("error verifying code: %w", err)}
 	}
@@ -556,7 +622,126 @@ func (s *Service) LoginVerify(
 		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("error updating user phone: %w", err)}
 	}
 
-	authResponse, err := s.createAuthTokens(ctx, user)
+	authResponse, err := s.createAuthTokens(ctx, user, client)
+	if err != nil {
+		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("error creating auth tokens: %w", err)}
+	}
+	return authResponse, nil
+}
+
+// LoginTOTP completes sign-in with a code from the user's authenticator app
+// or one of their recovery codes. The session token is used up by the
+// attempt, so each takes a new sign-in link.
+func (s *Service) LoginTOTP(
+	ctx context.Context,
+	sessionToken *auth.SessionTokenClaims,
+	code string,
+	client session.Client,
+) (*AuthResponse, error) {
+	if err := s.useSessionToken(ctx, sessionToken); err != nil {
+		return nil, err
+	}
+	user, err := s.store.SelectUser(ctx, sessionToken.ID)
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: "Error selecting user by ID", Err: fmt.Errorf("error selecting user by ID: %w", err)}
+	}
+	if err := s.twoFactorService.Verify(ctx, sessionToken.ID, code); err != nil {
+		return nil, err
+	}
+	authResponse, err := s.createAuthTokens(ctx, user, client)
+	if err != nil {
+		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("error creating auth tokens: %w", err)}
+	}
+	return authResponse, nil
+}
+
+// useSessionToken records a session token as used, refusing one used before
+func (s *Service) useSessionToken(ctx context.Context, sessionToken *auth.SessionTokenClaims) error {
+	if sessionToken.TokenID == uuid.Nil {
+		return pkg.UnauthorizedError{Err: errors.New("session token has no ID")}
+	}
+	if err := s.store.DeleteExpiredUsedSessionTokens(ctx); err != nil {
+		slog.Error("Error deleting expired session tokens", "error", err)
+	}
+	n, err := s.store.InsertUsedSessionToken(ctx, query.InsertUsedSessionTokenParams{
+		ID:        sessionToken.TokenID,
+		ExpiresAt: sessionToken.ExpiresAt,
+	})
+	if err != nil {
+		return pkg.InternalError{Message: "Error recording session token", Err: err}
+	}
+	if n == 0 {
+		return pkg.UnauthorizedError{Err: fmt.Errorf("session token %s already used", sessionToken.TokenID)}
+	}
+	return nil
+}
+
+// LoginPasskeyOptions starts a sign-in with a passkey in place of a magic
+// link
+func (s *Service) LoginPasskeyOptions(ctx context.Context, returnURL string) (*passkey.RequestOptions, error) {
+	if !s.allowedReturnURL(returnURL) {
+		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("invalid return URL %q", returnURL)}
+	}
+	return s.passkeyService.LoginOptions(ctx, returnURL)
+}
+
+// allowedReturnURL reports whether a URL is on the admin or client app. The
+// scheme and host are compared exactly, as a prefix of the app's URL would
+// also match hosts such as localhost:3000.evil.example.
+func (s *Service) allowedReturnURL(returnURL string) bool {
+	u, err := url.Parse(returnURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, appURL := range []string{s.cfg.AdminURL, s.cfg.ClientURL} {
+		app, err := url.Parse(appURL)
+		if err == nil && app.Host != "" && u.Scheme == app.Scheme && u.Host == app.Host {
+			return true
+		}
+	}
+	return false
+}
+
+// LoginPasskey signs in with a passkey. The authenticator has verified the
+// user, so the passkey stands in for both the magic link and the second
+// factor.
+func (s *Service) LoginPasskey(
+	ctx context.Context,
+	cred passkey.AssertionCredential,
+	client session.Client,
+) (*AuthResponse, error) {
+	assertion, err := s.passkeyService.Login(ctx, cred)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.store.SelectUser(ctx, assertion.UserID)
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: "Error selecting user by ID", Err: fmt.Errorf("error selecting user by ID: %w", err)}
+	}
+	authResponse, err := s.createAuthTokens(ctx, user, client)
+	if err != nil {
+		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("error creating auth tokens: %w", err)}
+	}
+	authResponse.ReturnURL = assertion.ReturnURL
+	return authResponse, nil
+}
+
+// LoginPasskeyVerify completes sign-in with one of the user's passkeys
+// after the magic link
+func (s *Service) LoginPasskeyVerify(
+	ctx context.Context,
+	userID uuid.UUID,
+	cred passkey.AssertionCredential,
+	client session.Client,
+) (*AuthResponse, error) {
+	user, err := s.store.SelectUser(ctx, userID)
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: "Error selecting user by ID", Err: fmt.Errorf("error selecting user by ID: %w", err)}
+	}
+	if err := s.passkeyService.Verify(ctx, userID, cred); err != nil {
+		return nil, err
+	}
+	authResponse, err := s.createAuthTokens(ctx, user, client)
 	if err != nil {
 		return nil, pkg.UnauthorizedError{Err: fmt.Errorf("error creating auth tokens: %w", err)}
 	}
@@ -569,37 +754,70 @@ func (s *Service) checkUserAccess(_ context.Context, user query.User) (bool, int
 	return subscriptionActive, user.Access, nil
 }
 
-func (s *Service) createAuthTokens(ctx context.Context, user query.User) (*AuthResponse, error) {
+// defaultAgency returns the agency a user's tokens are issued for and
+// their role there, or nothing when the user belongs to no agency
+func (s *Service) defaultAgency(ctx context.Context, userID uuid.UUID) (string, auth.Role, error) {
+	m, err := s.store.SelectDefaultAgencyMembership(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return m.AgencyID.String(), auth.Role(m.Role), nil
+}
+
+// insertSessionToken creates a refresh token (valid for 30 days) in a
+// session's family
+func (s *Service) insertSessionToken(ctx context.Context, userID uuid.UUID, family string, client session.Client) (query.Token, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return query.Token{}, fmt.Errorf("error generating UUID: %w", err)
+	}
+	return s.store.InsertSessionToken(ctx, query.InsertSessionTokenParams{
+		ID:        id.String(),
+		Expires:   time.Now().Add(s.cfg.RefreshTokenExp),
+		Target:    userID.String(),
+		Family:    family,
+		UserAgent: client.UserAgent,
+		Ip:        client.IP,
+	})
+}
+
+// createAuthTokens signs a user in, starting a new session
+func (s *Service) createAuthTokens(ctx context.Context, user query.User, client session.Client) (*AuthResponse, error) {
 	// Check if user has an active subscription
 	subscriptionActive, access, err := s.checkUserAccess(ctx, user)
 	if err != nil {
 		return nil, fmt.Errorf("error checking user access: %w", err)
 	}
 
-	// Create refresh token (valid for 30 days)
-	id, err := uuid.NewV7()
+	// Start the session's family of refresh tokens
+	family, err := uuid.NewV7()
 	if err != nil {
 		return nil, fmt.Errorf("error generating UUID: %w", err)
 	}
-	params2 := query.InsertTokenParams{
-		ID:       id.String(),
-		Expires:  time.Now().Add(s.cfg.RefreshTokenExp),
-		Target:   user.ID.String(),
-		Callback: "",
-	}
-	refreshToken, err := s.store.InsertToken(ctx, params2)
+	refreshToken, err := s.insertSessionToken(ctx, user.ID, family.String(), client)
 	if err != nil {
 		return nil, fmt.Errorf("error inserting refresh token: %w", err)
 	}
 
+	agencyID, agencyRole, err := s.defaultAgency(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error selecting agency membership: %w", err)
+	}
+
 	// Generate JWT tokens
 	jwtToken, jwtRefreshToken, err := s.authService.GenerateTokens(
 		refreshToken.ID,
+		refreshToken.Family,
 		user.ID.String(),
 		access,
 		user.Avatar,
 		user.Email,
 		subscriptionActive,
+		agencyID,
+		agencyRole,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error generating JWT token: %w", err)
@@ -0,0 +1,84 @@
+package login_test
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"errors"
+	"service-core/config"
+	"service-core/domain/login"
+	"service-core/domain/session"
+	"service-core/storage/query"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// mockTwoFactorService accepts a single code
+type mockTwoFactorService struct {
+	code string
+}
+
+func (m *mockTwoFactorService) Enabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return true, nil
+}
+
+func (m *mockTwoFactorService) Verify(ctx context.Context, userID uuid.UUID, code string) error {
+	if code != m.code {
+		return pkg.UnauthorizedError{Err: errors.New("invalid two-factor code")}
+	}
+	return nil
+}
+
+func TestLoginTOTP(t *testing.T) {
+	t.Parallel()
+	userID := uuid.New()
+	cfg := &config.Config{ContextTimeout: time.Second, RefreshTokenExp: time.Hour}
+	client := session.Client{UserAgent: "test", IP: "192.0.2.1"}
+	sessionToken := func() *auth.SessionTokenClaims {
+		return &auth.SessionTokenClaims{
+			ID:        userID,
+			Method:    auth.TwoFactorTOTP,
+			TokenID:   uuid.New(),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+	}
+
+	// The cases run in order against one store, as session tokens are used
+	// up
+	first, second := sessionToken(), sessionToken()
+	legacy := sessionToken()
+	legacy.TokenID = uuid.Nil
+	tests := []struct {
+		name         string
+		sessionToken *auth.SessionTokenClaims
+		code         string
+		wantErr      bool
+	}{
+		{name: "valid code", sessionToken: first, code: "123456"},
+		{name: "session token replayed", sessionToken: first, code: "123456", wantErr: true},
+		{name: "wrong code", sessionToken: second, code: "654321", wantErr: true},
+		{name: "session token retried after a wrong code", sessionToken: second, code: "123456", wantErr: true},
+		{name: "session token without an ID", sessionToken: legacy, code: "123456", wantErr: true},
+	}
+	store := &mockStore{
+		tokens: map[string]query.Token{},
+		used:   map[uuid.UUID]bool{},
+		user:   query.User{ID: userID, Email: "user@example.com", Access: auth.NewUserAccess},
+	}
+	s := login.NewService(cfg, store, &mockAuthService{userID: userID}, nil, &mockTwoFactorService{code: "123456"}, nil)
+	for _, tt := range tests {
+		response, err := s.LoginTOTP(context.Background(), tt.sessionToken, tt.code, client)
+		if !tt.wantErr {
+			if err != nil || response.RefreshToken == "" {
+				t.Errorf("%s: LoginTOTP() = %v, %v, want tokens", tt.name, response, err)
+			}
+			continue
+		}
+		var unauthorized pkg.UnauthorizedError
+		if !errors.As(err, &unauthorized) {
+			t.Errorf("%s: LoginTOTP() error = %v, want UnauthorizedError", tt.name, err)
+		}
+	}
+}
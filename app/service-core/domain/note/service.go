@@ -2,6 +2,7 @@ package note
 
 import (
 	"app/pkg"
+	"app/pkg/auth"
 	"context"
 	"service-core/storage/query"
 
@@ -98,18 +99,19 @@ func (s *Service) GetNotesByUserID(
 
 func (s *Service) GetNoteByID(
 	ctx context.Context,
+	userAttr auth.UserAttr,
 	id uuid.UUID,
 ) (*Note, error) {
-	note, err := s.noteStore.SelectNote(ctx, id)
+	note, err := s.authorize(ctx, userAttr, auth.GetNotes, id)
 	if err != nil {
-		return nil, pkg.NotFoundError{Message: "Error selecting note by ID", Err: err}
+		return nil, err
 	}
 	user, err := s.noteStore.SelectUser(ctx, note.UserID)
 	if err != nil {
 		return nil, pkg.NotFoundError{Message: "Error selecting user", Err: err}
 	}
 	return &Note{
-		Note: note,
+		Note: *note,
 		User: user,
 	}, nil
 }
@@ -150,11 +152,15 @@ func (s *Service) CreateNote(
 
 func (s *Service) EditNote(
 	ctx context.Context,
+	userAttr auth.UserAttr,
 	id uuid.UUID,
     title string,
     category string,
     content string,
 ) (*query.Note, error) {
+	if _, err := s.authorize(ctx, userAttr, auth.EditNote, id); err != nil {
+		return nil, err
+	}
 	params := query.UpdateNoteParams{
 		ID:       id,
 		Title:    title,
@@ -179,11 +185,33 @@ func (s *Service) EditNote(
 
 func (s *Service) RemoveNote(
 	ctx context.Context,
+	userAttr auth.UserAttr,
 	id uuid.UUID,
 ) error {
+	if _, err := s.authorize(ctx, userAttr, auth.RemoveNote, id); err != nil {
+		return err
+	}
     err := s.noteStore.DeleteNote(ctx, id)
 	if err != nil {
 		return pkg.InternalError{Message: "Error deleting note", Err: err}
 	}
 	return nil
 }
+
+// authorize returns the note if the user may act on it. Notes are personal,
+// so only their owner may.
+func (s *Service) authorize(
+	ctx context.Context,
+	userAttr auth.UserAttr,
+	access int64,
+	id uuid.UUID,
+) (*query.Note, error) {
+	note, err := s.noteStore.SelectNote(ctx, id)
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: "Error selecting note by ID", Err: err}
+	}
+	if err := auth.Authorize(access, userAttr, auth.Resource{OwnerID: note.UserID}); err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
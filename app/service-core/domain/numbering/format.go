@@ -0,0 +1,188 @@
+package numbering
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format templates are literal text with these placeholders:
+//
+//	{prefix}   the agency's prefix for the document type
+//	{yyyy}     four digit year
+//	{yy}       two digit year
+//	{mm}       two digit month
+//	{seq}      sequence number, unpadded
+//	{seq:05}   sequence number zero padded to 5 digits
+//
+// Every template must contain exactly one sequence placeholder.
+
+type tokenKind int
+
+const (
+	tokenLiteral tokenKind = iota
+	tokenPrefix
+	tokenYear
+	tokenShortYear
+	tokenMonth
+	tokenSeq
+)
+
+type token struct {
+	kind    tokenKind
+	literal string
+	width   int
+}
+
+type template []token
+
+func parseTemplate(format string) (template, error) {
+	var tmpl template
+	seqs := 0
+	rest := format
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			tmpl = append(tmpl, token{kind: tokenLiteral, literal: rest})
+			break
+		}
+		if open > 0 {
+			tmpl = append(tmpl, token{kind: tokenLiteral, literal: rest[:open]})
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, errors.New("unclosed placeholder")
+		}
+		name := rest[open+1 : open+end]
+		rest = rest[open+end+1:]
+
+		switch {
+		case name == "prefix":
+			tmpl = append(tmpl, token{kind: tokenPrefix})
+		case name == "yyyy":
+			tmpl = append(tmpl, token{kind: tokenYear})
+		case name == "yy":
+			tmpl = append(tmpl, token{kind: tokenShortYear})
+		case name == "mm":
+			tmpl = append(tmpl, token{kind: tokenMonth})
+		case name == "seq":
+			tmpl = append(tmpl, token{kind: tokenSeq})
+			seqs++
+		case strings.HasPrefix(name, "seq:"):
+			width, err := strconv.Atoi(name[len("seq:"):])
+			if err != nil || width < 1 || width > 12 {
+				return nil, fmt.Errorf("invalid sequence width in {%s}", name)
+			}
+			tmpl = append(tmpl, token{kind: tokenSeq, width: width})
+			seqs++
+		default:
+			return nil, fmt.Errorf("unknown placeholder {%s}", name)
+		}
+	}
+	if seqs != 1 {
+		return nil, errors.New("format must contain exactly one {seq} placeholder")
+	}
+	return tmpl, nil
+}
+
+// hasYear reports whether numbers rendered from the template include the year
+func (t template) hasYear() bool {
+	for _, tok := range t {
+		if tok.kind == tokenYear || tok.kind == tokenShortYear {
+			return true
+		}
+	}
+	return false
+}
+
+func (t template) render(prefix string, seq int32, at time.Time) string {
+	var b strings.Builder
+	for _, tok := range t {
+		switch tok.kind {
+		case tokenLiteral:
+			b.WriteString(tok.literal)
+		case tokenPrefix:
+			b.WriteString(prefix)
+		case tokenYear:
+			fmt.Fprintf(&b, "%04d", at.Year())
+		case tokenShortYear:
+			fmt.Fprintf(&b, "%02d", at.Year()%100)
+		case tokenMonth:
+			fmt.Fprintf(&b, "%02d", int(at.Month()))
+		case tokenSeq:
+			fmt.Fprintf(&b, "%0*d", tok.width, seq)
+		}
+	}
+	return b.String()
+}
+
+// matcher returns a pattern matching numbers rendered from the template
+// with the given prefix, capturing the year and sequence
+func (t template) matcher(prefix string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, tok := range t {
+		switch tok.kind {
+		case tokenLiteral:
+			b.WriteString(regexp.QuoteMeta(tok.literal))
+		case tokenPrefix:
+			b.WriteString(regexp.QuoteMeta(prefix))
+		case tokenYear:
+			b.WriteString(`(?P<year>\d{4})`)
+		case tokenShortYear:
+			b.WriteString(`(?P<yy>\d{2})`)
+		case tokenMonth:
+			b.WriteString(`\d{2}`)
+		case tokenSeq:
+			fmt.Fprintf(&b, `(?P<seq>\d{%d,})`, max(tok.width, 1))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// Format renders a document number from a format template
+func Format(format, prefix string, seq int32, at time.Time) (string, error) {
+	tmpl, err := parseTemplate(format)
+	if err != nil {
+		return "", err
+	}
+	return tmpl.render(prefix, seq, at), nil
+}
+
+// Parse extracts the year and sequence from a number rendered with the
+// format and prefix. Year is zero when the format has no year placeholder;
+// two digit years are read as 20yy.
+func Parse(format, prefix, number string) (year int, seq int32, ok bool) {
+	tmpl, err := parseTemplate(format)
+	if err != nil {
+		return 0, 0, false
+	}
+	return tmpl.parse(tmpl.matcher(prefix), number)
+}
+
+func (t template) parse(re *regexp.Regexp, number string) (year int, seq int32, ok bool) {
+	match := re.FindStringSubmatch(number)
+	if match == nil {
+		return 0, 0, false
+	}
+	for i, name := range re.SubexpNames() {
+		switch name {
+		case "year":
+			year, _ = strconv.Atoi(match[i])
+		case "yy":
+			yy, _ := strconv.Atoi(match[i])
+			year = 2000 + yy
+		case "seq":
+			n, err := strconv.ParseInt(match[i], 10, 32)
+			if err != nil {
+				return 0, 0, false
+			}
+			seq = int32(n)
+		}
+	}
+	return year, seq, true
+}
@@ -0,0 +1,62 @@
+package numbering_test
+
+import (
+	"service-core/domain/numbering"
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+	at := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{numbering.DefaultFormat, "INV-2026-0042", false},
+		{"{prefix}-{yyyy}-{seq:05}", "INV-2026-00042", false},
+		{"{prefix}/{yy}{mm}/{seq}", "INV/2603/42", false},
+		{"{seq:02}", "42", false},
+		{"{prefix}-{yyyy}", "", true},
+		{"{prefix}-{seq}-{seq}", "", true},
+		{"{prefix}-{seq:0}", "", true},
+		{"{prefix}-{day}-{seq}", "", true},
+		{"{prefix}-{seq", "", true},
+	}
+	for _, tt := range tests {
+		got, err := numbering.Format(tt.format, "INV", 42, at)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Format(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Format(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		format string
+		number string
+		year   int
+		seq    int32
+		ok     bool
+	}{
+		{numbering.DefaultFormat, "INV-2026-0042", 2026, 42, true},
+		{numbering.DefaultFormat, "INV-2026-12345", 2026, 12345, true},
+		{numbering.DefaultFormat, "QUO-2026-0042", 0, 0, false},
+		{numbering.DefaultFormat, "INV-2026-42", 0, 0, false},
+		{"{prefix}.{yy}.{seq}", "INV.26.7", 2026, 7, true},
+		{"{prefix}{seq:03}", "INV007", 0, 7, true},
+	}
+	for _, tt := range tests {
+		year, seq, ok := numbering.Parse(tt.format, "INV", tt.number)
+		if ok != tt.ok || year != tt.year || seq != tt.seq {
+			t.Errorf("Parse(%q, %q) = %d, %d, %v, want %d, %d, %v",
+				tt.format, tt.number, year, seq, ok, tt.year, tt.seq, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package numbering
+
+// DocumentType identifies a numbered agency document
+type DocumentType string
+
+const (
+	Proposal  DocumentType = "proposal"
+	Contract  DocumentType = "contract"
+	Invoice   DocumentType = "invoice"
+	Quotation DocumentType = "quotation"
+)
+
+// DocumentTypes lists every numbered document type in display order
+var DocumentTypes = []DocumentType{Proposal, Contract, Invoice, Quotation}
+
+// IsValid reports whether t is a known document type
+func (t DocumentType) IsValid() bool {
+	switch t {
+	case Proposal, Contract, Invoice, Quotation:
+		return true
+	}
+	return false
+}
+
+// defaultPrefix is used when the agency profile prefix is blank
+func (t DocumentType) defaultPrefix() string {
+	switch t {
+	case Proposal:
+		return "PROP"
+	case Contract:
+		return "CON"
+	case Invoice:
+		return "INV"
+	default:
+		return "QUO"
+	}
+}
+
+// DefaultFormat renders PREFIX-YYYY-NNNN, the format used before formats
+// were configurable
+const DefaultFormat = "{prefix}-{yyyy}-{seq:04}"
+
+// maxGapEntries caps the missing sequence numbers listed in a gap report
+const maxGapEntries = 500
+
+// Settings is the numbering configuration for one document type
+type Settings struct {
+	DocumentType DocumentType `json:"documentType"`
+	Prefix       string       `json:"prefix"`
+	NextNumber   int32        `json:"nextNumber"`
+	Format       string       `json:"format"`
+	ResetYearly  bool         `json:"resetYearly"`
+	Preview      string       `json:"preview"`
+}
+
+// UpdateRequest changes the format template and yearly reset for a
+// document type. Prefixes and counters are edited on the agency profile.
+type UpdateRequest struct {
+	Format      string `json:"format"`
+	ResetYearly bool   `json:"resetYearly"`
+}
+
+// GapReport lists sequence numbers that were skipped or whose documents
+// were deleted. First and Last bound the checked range; with yearly resets
+// the range covers a single year.
+type GapReport struct {
+	DocumentType DocumentType `json:"documentType"`
+	Format       string       `json:"format"`
+	Year         int          `json:"year,omitempty"`
+	First        int32        `json:"first"`
+	Last         int32        `json:"last"`
+	Issued       int          `json:"issued"`
+	MissingCount int          `json:"missingCount"`
+	Missing      []int32      `json:"missing"`
+	// Unparsed holds numbers that do not match the current format, such
+	// as documents numbered before the format was changed
+	Unparsed []string `json:"unparsed"`
+}
@@ -0,0 +1,411 @@
+package numbering
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"errors"
+	"service-core/domain/activity"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxSkips bounds how many numbers an allocation passes over when they are
+// already taken, e.g. by documents numbered by hand
+const maxSkips = 1000
+
+// store defines the database interface for numbering reads. Allocation
+// runs its own transaction.
+type store interface {
+	SelectAgencyNumbering(ctx context.Context, agencyID uuid.UUID) (query.SelectAgencyNumberingRow, error)
+	SelectDocumentNumberings(ctx context.Context, agencyID uuid.UUID) ([]query.AgencyDocumentNumbering, error)
+	SelectDocumentNumbering(ctx context.Context, arg query.SelectDocumentNumberingParams) (query.AgencyDocumentNumbering, error)
+	UpsertDocumentNumbering(ctx context.Context, arg query.UpsertDocumentNumberingParams) (query.AgencyDocumentNumbering, error)
+	SelectDocumentNumbers(ctx context.Context, arg query.SelectDocumentNumbersParams) ([]string, error)
+	InsertActivityLog(ctx context.Context, arg query.InsertActivityLogParams) error
+}
+
+// settingsReader is satisfied by both the store and a transaction's queries
+type settingsReader interface {
+	SelectDocumentNumbering(ctx context.Context, arg query.SelectDocumentNumberingParams) (query.AgencyDocumentNumbering, error)
+}
+
+// transactor runs a function in a database transaction
+type transactor interface {
+	InTx(ctx context.Context, fn func(q query.Querier) error) error
+}
+
+// Service allocates per-agency document numbers. It is the single
+// authority for proposal, contract, invoice and quotation numbers.
+type Service struct {
+	tx    transactor
+	store store
+}
+
+// NewService creates a new numbering service
+func NewService(tx transactor, store store) *Service {
+	return &Service{
+		tx:    tx,
+		store: store,
+	}
+}
+
+// Allocate reserves the next number for a document type. The agency
+// profile row is locked for the duration of the transaction so concurrent
+// allocations are serialised, and numbers already used by an existing
+// document are skipped.
+func (s *Service) Allocate(ctx context.Context, agencyID uuid.UUID, docType DocumentType) (string, error) {
+	if !docType.IsValid() {
+		return "", pkg.BadRequestError{Message: "Invalid document type"}
+	}
+	var number string
+	err := s.tx.InTx(ctx, func(q query.Querier) error {
+		var err error
+		number, err = s.allocate(ctx, q, agencyID, docType)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return number, nil
+}
+
+// allocate reserves a number within the allocation's transaction
+func (s *Service) allocate(ctx context.Context, q query.Querier, agencyID uuid.UUID, docType DocumentType) (string, error) {
+	row, err := q.LockAgencyNumbering(ctx, agencyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", pkg.NotFoundError{Message: "Agency profile not found", Err: err}
+		}
+		return "", pkg.InternalError{Message: "Error locking agency profile", Err: err}
+	}
+	prefix, next := counter(row, docType)
+
+	settings, found, err := s.settings(ctx, q, agencyID, docType)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := parseTemplate(settings.Format)
+	if err != nil {
+		return "", pkg.InternalError{Message: "Invalid stored number format", Err: err}
+	}
+
+	now := time.Now()
+	if settings.ResetYearly && settings.SequenceYear != 0 && int(settings.SequenceYear) < now.Year() {
+		next = 1
+	}
+
+	var number string
+	for skipped := 0; ; skipped++ {
+		if skipped == maxSkips {
+			return "", pkg.InternalError{Message: "No free document number found"}
+		}
+		number = tmpl.render(prefix, next, now)
+		exists, err := q.DocumentNumberExists(ctx, query.DocumentNumberExistsParams{
+			DocumentType: string(docType),
+			AgencyID:     agencyID,
+			Number:       number,
+		})
+		if err != nil {
+			return "", pkg.InternalError{Message: "Error checking document number", Err: err}
+		}
+		if !exists {
+			break
+		}
+		next++
+	}
+
+	err = q.SetNextDocumentNumber(ctx, query.SetNextDocumentNumberParams{
+		DocumentType: string(docType),
+		NextNumber:   next + 1,
+		AgencyID:     agencyID,
+	})
+	if err != nil {
+		return "", pkg.InternalError{Message: "Error updating document counter", Err: err}
+	}
+	// The settings row records the year of the sequence, so it is created
+	// on the first allocation; otherwise turning on yearly resets later
+	// would never reset a counter started in an earlier year
+	if !found {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return "", pkg.InternalError{Message: "Error generating numbering ID", Err: err}
+		}
+		err = q.InsertDocumentNumbering(ctx, query.InsertDocumentNumberingParams{
+			ID:           id,
+			AgencyID:     agencyID,
+			DocumentType: string(docType),
+			Format:       settings.Format,
+			ResetYearly:  settings.ResetYearly,
+			SequenceYear: int32(now.Year()),
+		})
+		if err != nil {
+			return "", pkg.InternalError{Message: "Error creating numbering settings", Err: err}
+		}
+	} else if int(settings.SequenceYear) != now.Year() {
+		err = q.UpdateDocumentSequenceYear(ctx, query.UpdateDocumentSequenceYearParams{
+			AgencyID:     agencyID,
+			DocumentType: string(docType),
+			SequenceYear: int32(now.Year()),
+		})
+		if err != nil {
+			return "", pkg.InternalError{Message: "Error updating sequence year", Err: err}
+		}
+	}
+	return number, nil
+}
+
+// settings returns the stored numbering settings for a document type, or
+// the defaults when the agency has not configured any
+func (s *Service) settings(
+	ctx context.Context,
+	q settingsReader,
+	agencyID uuid.UUID,
+	docType DocumentType,
+) (query.AgencyDocumentNumbering, bool, error) {
+	settings, err := q.SelectDocumentNumbering(ctx, query.SelectDocumentNumberingParams{
+		AgencyID:     agencyID,
+		DocumentType: string(docType),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return query.AgencyDocumentNumbering{
+				AgencyID:     agencyID,
+				DocumentType: string(docType),
+				Format:       DefaultFormat,
+			}, false, nil
+		}
+		return settings, false, pkg.InternalError{Message: "Error getting numbering settings", Err: err}
+	}
+	return settings, true, nil
+}
+
+// ListSettings returns the numbering settings for every document type with
+// a preview of the next number
+func (s *Service) ListSettings(ctx context.Context, agencyID uuid.UUID) ([]Settings, error) {
+	row, err := s.store.SelectAgencyNumbering(ctx, agencyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Agency profile not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error getting agency profile", Err: err}
+	}
+	stored, err := s.store.SelectDocumentNumberings(ctx, agencyID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error getting numbering settings", Err: err}
+	}
+	byType := make(map[DocumentType]query.AgencyDocumentNumbering, len(stored))
+	for _, n := range stored {
+		byType[DocumentType(n.DocumentType)] = n
+	}
+
+	result := make([]Settings, 0, len(DocumentTypes))
+	for _, docType := range DocumentTypes {
+		n, ok := byType[docType]
+		if !ok {
+			n = query.AgencyDocumentNumbering{Format: DefaultFormat}
+		}
+		result = append(result, newSettings(query.LockAgencyNumberingRow(row), docType, n))
+	}
+	return result, nil
+}
+
+// UpdateSettings stores the format template and yearly reset for a
+// document type
+func (s *Service) UpdateSettings(
+	ctx context.Context,
+	agencyID uuid.UUID,
+	userID uuid.UUID,
+	docType DocumentType,
+	req UpdateRequest,
+) (*Settings, error) {
+	if err := validate(docType, req); err != nil {
+		return nil, err
+	}
+	row, err := s.store.SelectAgencyNumbering(ctx, agencyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Agency profile not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error getting agency profile", Err: err}
+	}
+	old, found, err := s.settings(ctx, s.store, agencyID, docType)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating numbering ID", Err: err}
+	}
+	// SequenceYear only applies to a new row, whose counter the allocator
+	// has not recorded a year for yet
+	n, err := s.store.UpsertDocumentNumbering(ctx, query.UpsertDocumentNumberingParams{
+		ID:           id,
+		AgencyID:     agencyID,
+		DocumentType: string(docType),
+		Format:       req.Format,
+		ResetYearly:  req.ResetYearly,
+		SequenceYear: int32(time.Now().Year()),
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating numbering settings", Err: err}
+	}
+
+	var oldValues any
+	if found {
+		oldValues = UpdateRequest{Format: old.Format, ResetYearly: old.ResetYearly}
+	}
+	s.logActivity(ctx, agencyID, userID, "numbering.updated", oldValues, req, map[string]string{
+		"documentType": string(docType),
+	})
+
+	settings := newSettings(query.LockAgencyNumberingRow(row), docType, n)
+	return &settings, nil
+}
+
+// Gaps reports sequence numbers missing from an agency's documents. With
+// yearly resets the report covers the given year, defaulting to the
+// current one; otherwise it covers the whole sequence and year is ignored.
+func (s *Service) Gaps(ctx context.Context, agencyID uuid.UUID, docType DocumentType, year int) (*GapReport, error) {
+	if !docType.IsValid() {
+		return nil, pkg.BadRequestError{Message: "Invalid document type"}
+	}
+	row, err := s.store.SelectAgencyNumbering(ctx, agencyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Agency profile not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error getting agency profile", Err: err}
+	}
+	prefix, next := counter(query.LockAgencyNumberingRow(row), docType)
+	settings, _, err := s.settings(ctx, s.store, agencyID, docType)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := parseTemplate(settings.Format)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Invalid stored number format", Err: err}
+	}
+	numbers, err := s.store.SelectDocumentNumbers(ctx, query.SelectDocumentNumbersParams{
+		DocumentType: string(docType),
+		AgencyID:     agencyID,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error listing document numbers", Err: err}
+	}
+
+	byYear := settings.ResetYearly && tmpl.hasYear()
+	if !byYear {
+		year = 0
+	} else if year == 0 {
+		year = time.Now().Year()
+	}
+	report := &GapReport{
+		DocumentType: docType,
+		Format:       settings.Format,
+		Year:         year,
+		Missing:      []int32{},
+		Unparsed:     []string{},
+	}
+
+	re := tmpl.matcher(prefix)
+	issued := make(map[int32]bool)
+	for _, number := range numbers {
+		y, seq, ok := tmpl.parse(re, number)
+		if !ok {
+			report.Unparsed = append(report.Unparsed, number)
+			continue
+		}
+		if byYear && y != year {
+			continue
+		}
+		issued[seq] = true
+		if report.First == 0 || seq < report.First {
+			report.First = seq
+		}
+		if seq > report.Last {
+			report.Last = seq
+		}
+	}
+	report.Issued = len(issued)
+
+	// Numbers handed out by the counter but never used are gaps too, as
+	// long as the counter still belongs to the reported period
+	if !byYear || int(settings.SequenceYear) == year || settings.SequenceYear == 0 && year == time.Now().Year() {
+		if next-1 > report.Last && report.First != 0 {
+			report.Last = next - 1
+		}
+	}
+	for seq := report.First; report.First != 0 && seq <= report.Last; seq++ {
+		if issued[seq] {
+			continue
+		}
+		report.MissingCount++
+		if len(report.Missing) < maxGapEntries {
+			report.Missing = append(report.Missing, seq)
+		}
+	}
+	return report, nil
+}
+
+// counter returns the prefix and next sequence number for a document type
+func counter(row query.LockAgencyNumberingRow, docType DocumentType) (string, int32) {
+	var prefix string
+	var next int32
+	switch docType {
+	case Proposal:
+		prefix, next = row.ProposalPrefix, row.NextProposalNumber
+	case Contract:
+		prefix, next = row.ContractPrefix, row.NextContractNumber
+	case Invoice:
+		prefix, next = row.InvoicePrefix, row.NextInvoiceNumber
+	case Quotation:
+		prefix, next = row.QuotationPrefix, row.NextQuotationNumber
+	}
+	if prefix == "" {
+		prefix = docType.defaultPrefix()
+	}
+	return prefix, max(next, 1)
+}
+
+func newSettings(row query.LockAgencyNumberingRow, docType DocumentType, n query.AgencyDocumentNumbering) Settings {
+	prefix, next := counter(row, docType)
+	now := time.Now()
+	if n.ResetYearly && n.SequenceYear != 0 && int(n.SequenceYear) < now.Year() {
+		next = 1
+	}
+	preview, _ := Format(n.Format, prefix, next, now)
+	return Settings{
+		DocumentType: docType,
+		Prefix:       prefix,
+		NextNumber:   next,
+		Format:       n.Format,
+		ResetYearly:  n.ResetYearly,
+		Preview:      preview,
+	}
+}
+
+// logActivity records a numbering change in the agency activity log
+func (s *Service) logActivity(
+	ctx context.Context,
+	agencyID uuid.UUID,
+	userID uuid.UUID,
+	action string,
+	oldValues any,
+	newValues any,
+	metadata any,
+) {
+	activity.Log(ctx, s.store, activity.Entry{
+		AgencyID:   agencyID,
+		UserID:     userID,
+		Action:     action,
+		EntityType: "agency",
+		EntityID:   agencyID,
+		OldValues:  oldValues,
+		NewValues:  newValues,
+		Metadata:   metadata,
+	})
+}
@@ -0,0 +1,253 @@
+package numbering_test
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"service-core/domain/numbering"
+	"service-core/storage/query"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type mockStore struct {
+	*query.Queries
+	mu sync.Mutex
+	// profiles are the agencies' prefixes and counters
+	profiles map[uuid.UUID]query.LockAgencyNumberingRow
+	settings map[string]query.AgencyDocumentNumbering
+	// numbers are those already used by documents
+	numbers map[string]bool
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{
+		profiles: map[uuid.UUID]query.LockAgencyNumberingRow{},
+		settings: map[string]query.AgencyDocumentNumbering{},
+		numbers:  map[string]bool{},
+	}
+}
+
+func settingsKey(agencyID uuid.UUID, docType string) string {
+	return agencyID.String() + "/" + docType
+}
+
+// InTx runs fn against the store, restoring it if fn fails, as rolling back
+// would
+func (m *mockStore) InTx(ctx context.Context, fn func(q query.Querier) error) error {
+	m.mu.Lock()
+	profiles, settings := maps.Clone(m.profiles), maps.Clone(m.settings)
+	m.mu.Unlock()
+	if err := fn(m); err != nil {
+		m.mu.Lock()
+		m.profiles, m.settings = profiles, settings
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (m *mockStore) LockAgencyNumbering(ctx context.Context, agencyID uuid.UUID) (query.LockAgencyNumberingRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	row, ok := m.profiles[agencyID]
+	if !ok {
+		return query.LockAgencyNumberingRow{}, sql.ErrNoRows
+	}
+	return row, nil
+}
+
+func (m *mockStore) SelectDocumentNumbering(ctx context.Context, arg query.SelectDocumentNumberingParams) (query.AgencyDocumentNumbering, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.settings[settingsKey(arg.AgencyID, arg.DocumentType)]
+	if !ok {
+		return query.AgencyDocumentNumbering{}, sql.ErrNoRows
+	}
+	return s, nil
+}
+
+func (m *mockStore) DocumentNumberExists(ctx context.Context, arg query.DocumentNumberExistsParams) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.numbers[settingsKey(arg.AgencyID, arg.DocumentType)+"/"+arg.Number], nil
+}
+
+func (m *mockStore) SetNextDocumentNumber(ctx context.Context, arg query.SetNextDocumentNumberParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	row := m.profiles[arg.AgencyID]
+	switch numbering.DocumentType(arg.DocumentType) {
+	case numbering.Proposal:
+		row.NextProposalNumber = arg.NextNumber
+	case numbering.Contract:
+		row.NextContractNumber = arg.NextNumber
+	case numbering.Invoice:
+		row.NextInvoiceNumber = arg.NextNumber
+	case numbering.Quotation:
+		row.NextQuotationNumber = arg.NextNumber
+	}
+	m.profiles[arg.AgencyID] = row
+	return nil
+}
+
+func (m *mockStore) InsertDocumentNumbering(ctx context.Context, arg query.InsertDocumentNumberingParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.settings[settingsKey(arg.AgencyID, arg.DocumentType)] = query.AgencyDocumentNumbering{
+		ID:           arg.ID,
+		AgencyID:     arg.AgencyID,
+		DocumentType: arg.DocumentType,
+		Format:       arg.Format,
+		ResetYearly:  arg.ResetYearly,
+		SequenceYear: arg.SequenceYear,
+	}
+	return nil
+}
+
+func (m *mockStore) UpdateDocumentSequenceYear(ctx context.Context, arg query.UpdateDocumentSequenceYearParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := settingsKey(arg.AgencyID, arg.DocumentType)
+	s := m.settings[key]
+	s.SequenceYear = arg.SequenceYear
+	m.settings[key] = s
+	return nil
+}
+
+func TestAllocate(t *testing.T) {
+	t.Parallel()
+	agencyID := uuid.MustParse("00000000-0000-0000-0000-000000000100")
+	otherID := uuid.MustParse("00000000-0000-0000-0000-000000000200")
+	year := time.Now().Year()
+	invoice := func(n int) string { return fmt.Sprintf("INV-%d-%04d", year, n) }
+
+	type allocation struct {
+		agencyID uuid.UUID
+		docType  numbering.DocumentType
+		want     string
+	}
+	tests := []struct {
+		name        string
+		setup       func(m *mockStore)
+		allocations []allocation
+		wantErr     error
+	}{
+		{
+			name: "sequence increments",
+			allocations: []allocation{
+				{agencyID, numbering.Invoice, invoice(1)},
+				{agencyID, numbering.Invoice, invoice(2)},
+				{agencyID, numbering.Invoice, invoice(3)},
+			},
+		},
+		{
+			name: "counters continue from the profile",
+			setup: func(m *mockStore) {
+				m.profiles[agencyID] = query.LockAgencyNumberingRow{InvoicePrefix: "ACME", NextInvoiceNumber: 42}
+			},
+			allocations: []allocation{
+				{agencyID, numbering.Invoice, fmt.Sprintf("ACME-%d-0042", year)},
+				{agencyID, numbering.Invoice, fmt.Sprintf("ACME-%d-0043", year)},
+			},
+		},
+		{
+			name: "document types have their own sequences",
+			allocations: []allocation{
+				{agencyID, numbering.Invoice, invoice(1)},
+				{agencyID, numbering.Proposal, fmt.Sprintf("PROP-%d-0001", year)},
+				{agencyID, numbering.Invoice, invoice(2)},
+			},
+		},
+		{
+			name: "agencies have their own sequences",
+			setup: func(m *mockStore) {
+				m.profiles[otherID] = query.LockAgencyNumberingRow{}
+			},
+			allocations: []allocation{
+				{agencyID, numbering.Invoice, invoice(1)},
+				{agencyID, numbering.Invoice, invoice(2)},
+				{otherID, numbering.Invoice, invoice(1)},
+				{agencyID, numbering.Invoice, invoice(3)},
+			},
+		},
+		{
+			name: "taken numbers are skipped",
+			setup: func(m *mockStore) {
+				m.numbers[settingsKey(agencyID, "invoice")+"/"+invoice(1)] = true
+				m.numbers[settingsKey(agencyID, "invoice")+"/"+invoice(2)] = true
+			},
+			allocations: []allocation{
+				{agencyID, numbering.Invoice, invoice(3)},
+				{agencyID, numbering.Invoice, invoice(4)},
+			},
+		},
+		{
+			name: "yearly sequences reset in a new year",
+			setup: func(m *mockStore) {
+				m.profiles[agencyID] = query.LockAgencyNumberingRow{NextInvoiceNumber: 57}
+				m.settings[settingsKey(agencyID, "invoice")] = query.AgencyDocumentNumbering{
+					AgencyID:     agencyID,
+					DocumentType: "invoice",
+					Format:       numbering.DefaultFormat,
+					ResetYearly:  true,
+					SequenceYear: int32(year - 1),
+				}
+			},
+			allocations: []allocation{
+				{agencyID, numbering.Invoice, invoice(1)},
+				{agencyID, numbering.Invoice, invoice(2)},
+			},
+		},
+		{
+			name:        "agency without a profile",
+			allocations: []allocation{{otherID, numbering.Invoice, ""}},
+			wantErr:     pkg.NotFoundError{},
+		},
+		{
+			name:        "unknown document type",
+			allocations: []allocation{{agencyID, numbering.DocumentType("receipt"), ""}},
+			wantErr:     pkg.BadRequestError{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newMockStore()
+			store.profiles[agencyID] = query.LockAgencyNumberingRow{}
+			if tt.setup != nil {
+				tt.setup(store)
+			}
+			s := numbering.NewService(store, store)
+			for _, a := range tt.allocations {
+				got, err := s.Allocate(context.Background(), a.agencyID, a.docType)
+				switch tt.wantErr.(type) {
+				case nil:
+					if err != nil || got != a.want {
+						t.Fatalf("Allocate(%s) = %q, %v, want %q", a.docType, got, err, a.want)
+					}
+				case pkg.NotFoundError:
+					var notFound pkg.NotFoundError
+					if !errors.As(err, &notFound) {
+						t.Fatalf("Allocate() error = %v, want not found", err)
+					}
+				case pkg.BadRequestError:
+					var badRequest pkg.BadRequestError
+					if !errors.As(err, &badRequest) {
+						t.Fatalf("Allocate() error = %v, want bad request", err)
+					}
+				}
+			}
+			// A failed allocation leaves nothing behind
+			if tt.wantErr != nil && len(store.settings) != 0 {
+				t.Errorf("failed allocation stored settings %v", store.settings)
+			}
+		})
+	}
+}
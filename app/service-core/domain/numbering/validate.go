@@ -0,0 +1,42 @@
+package numbering
+
+import (
+	"app/pkg"
+)
+
+func validate(docType DocumentType, req UpdateRequest) error {
+	var errors pkg.ValidationErrors
+	if !docType.IsValid() {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "documentType",
+			Tag:     "oneof",
+			Message: "Document type must be one of proposal, contract, invoice or quotation",
+		})
+	}
+	tmpl, err := parseTemplate(req.Format)
+	switch {
+	case err != nil:
+		errors = append(errors, pkg.ValidationError{
+			Field:   "format",
+			Tag:     "format",
+			Message: "Invalid number format: " + err.Error(),
+		})
+	case len(req.Format) > 100:
+		errors = append(errors, pkg.ValidationError{
+			Field:   "format",
+			Tag:     "max",
+			Message: "Number format must be at most 100 characters",
+		})
+	case req.ResetYearly && !tmpl.hasYear():
+		// Without the year, a reset sequence would repeat last year's numbers
+		errors = append(errors, pkg.ValidationError{
+			Field:   "resetYearly",
+			Tag:     "format",
+			Message: "Yearly reset requires a {yyyy} or {yy} placeholder in the format",
+		})
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
@@ -0,0 +1,136 @@
+package passkey
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// maxCBORDepth bounds how deeply CBOR arrays and maps may nest
+const maxCBORDepth = 16
+
+// decodeCBOR decodes the CBOR (RFC 8949) data item at the start of b and
+// returns it with the bytes that follow it. Only the definite-length items
+// authenticators encode attestation objects and COSE keys with are
+// supported: integers decode to int64, byte strings to []byte, text to
+// string, arrays to []any and maps to map[any]any with int64 or string
+// keys.
+func decodeCBOR(b []byte) (any, []byte, error) {
+	return decodeCBORItem(b, 0)
+}
+
+func decodeCBORItem(b []byte, depth int) (any, []byte, error) {
+	if depth > maxCBORDepth {
+		return nil, nil, errors.New("CBOR is nested too deeply")
+	}
+	if len(b) == 0 {
+		return nil, nil, errors.New("CBOR is truncated")
+	}
+	major, info := b[0]>>5, b[0]&0x1f
+	b = b[1:]
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, b, nil
+		case 21:
+			return true, b, nil
+		case 22:
+			return nil, b, nil
+		}
+		return nil, nil, fmt.Errorf("unsupported CBOR simple value %d", info)
+	}
+	n, b, err := cborArgument(info, b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case 0, 1:
+		if n > math.MaxInt64 {
+			return nil, nil, errors.New("CBOR integer overflows int64")
+		}
+		if major == 1 {
+			return -1 - int64(n), b, nil
+		}
+		return int64(n), b, nil
+	case 2, 3:
+		if n > uint64(len(b)) {
+			return nil, nil, errors.New("CBOR string is truncated")
+		}
+		if major == 2 {
+			return slices.Clone(b[:n]), b[n:], nil
+		}
+		return string(b[:n]), b[n:], nil
+	case 4:
+		// Every item takes at least a byte
+		if n > uint64(len(b)) {
+			return nil, nil, errors.New("CBOR array is truncated")
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], b, err = decodeCBORItem(b, depth+1); err != nil {
+				return nil, nil, err
+			}
+		}
+		return items, b, nil
+	case 5:
+		if n > uint64(len(b)) {
+			return nil, nil, errors.New("CBOR map is truncated")
+		}
+		m := make(map[any]any, n)
+		for range n {
+			var key, value any
+			if key, b, err = decodeCBORItem(b, depth+1); err != nil {
+				return nil, nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, fmt.Errorf("unsupported CBOR map key %T", key)
+			}
+			if value, b, err = decodeCBORItem(b, depth+1); err != nil {
+				return nil, nil, err
+			}
+			m[key] = value
+		}
+		return m, b, nil
+	}
+	return nil, nil, fmt.Errorf("unsupported CBOR major type %d", major)
+}
+
+// cborArgument reads the argument of a data item: its value, length or
+// number of entries
+func cborArgument(info byte, b []byte) (uint64, []byte, error) {
+	size := 0
+	switch {
+	case info < 24:
+		return uint64(info), b, nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, nil, errors.New("indefinite-length CBOR is not supported")
+	}
+	if len(b) < size {
+		return 0, nil, errors.New("CBOR is truncated")
+	}
+	var n uint64
+	switch size {
+	case 1:
+		n = uint64(b[0])
+	case 2:
+		n = uint64(binary.BigEndian.Uint16(b))
+	case 4:
+		n = uint64(binary.BigEndian.Uint32(b))
+	case 8:
+		n = binary.BigEndian.Uint64(b)
+	}
+	return n, b[size:], nil
+}
@@ -0,0 +1,122 @@
+package passkey
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RPName names the app to users when they create a passkey
+const RPName = "Webkit"
+
+// MaxPasskeys is how many passkeys a user may register
+const MaxPasskeys = 10
+
+// ChallengeTTL is how long a ceremony has to complete after its options
+// are issued
+const ChallengeTTL = 5 * time.Minute
+
+// CreationOptions are the options of a registration ceremony, in the JSON
+// form browsers accept with PublicKeyCredential.parseCreationOptionsFromJSON
+type CreationOptions struct {
+	Challenge              string                 `json:"challenge"`
+	RP                     RelyingParty           `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions are the options of an authentication ceremony, in the JSON
+// form browsers accept with PublicKeyCredential.parseRequestOptionsFromJSON.
+// Sign-in leaves the credentials open, so the user picks a passkey; the
+// second factor allows only the user's own.
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	RPID             string                 `json:"rpId"`
+	Timeout          int64                  `json:"timeout"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+type RelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity identifies the user a passkey is created for. The ID is the
+// base64url user ID, which authenticators return as the user handle.
+type UserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// RegistrationRequest carries a new passkey, as returned by
+// PublicKeyCredential.toJSON after navigator.credentials.create
+type RegistrationRequest struct {
+	Name       string                 `json:"name"`
+	Credential RegistrationCredential `json:"credential"`
+}
+
+type RegistrationCredential struct {
+	ID       string              `json:"id"`
+	Type     string              `json:"type"`
+	Response AttestationResponse `json:"response"`
+}
+
+// AttestationResponse is the authenticator's response to a registration.
+// The credential and its COSE public key are read from the authenticator
+// data in the attestation object, which the browser cannot alter.
+type AttestationResponse struct {
+	ClientDataJSON    string   `json:"clientDataJSON"`
+	AttestationObject string   `json:"attestationObject"`
+	Transports        []string `json:"transports"`
+}
+
+// AssertionCredential is a signed challenge, as returned by
+// PublicKeyCredential.toJSON after navigator.credentials.get
+type AssertionCredential struct {
+	ID       string            `json:"id"`
+	Type     string            `json:"type"`
+	Response AssertionResponse `json:"response"`
+}
+
+type AssertionResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AuthenticatorData string `json:"authenticatorData"`
+	Signature         string `json:"signature"`
+	UserHandle        string `json:"userHandle"`
+}
+
+// Assertion is the outcome of a passkey sign-in
+type Assertion struct {
+	UserID    uuid.UUID
+	ReturnURL string
+}
+
+// Passkey describes one of a user's passkeys
+type Passkey struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+}
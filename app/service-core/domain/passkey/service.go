@@ -0,0 +1,435 @@
+package passkey
+
+import (
+	"app/pkg"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"service-core/config"
+	"service-core/storage/query"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Purposes of ceremony challenges
+const (
+	purposeRegister = "register"
+	purposeLogin    = "login"
+	purposeVerify   = "verify"
+)
+
+// store defines the database interface for passkey operations
+type store interface {
+	InsertPasskeyChallenge(ctx context.Context, arg query.InsertPasskeyChallengeParams) error
+	ConsumePasskeyChallenge(ctx context.Context, id string) (query.PasskeyChallenge, error)
+	DeleteExpiredPasskeyChallenges(ctx context.Context) error
+	InsertPasskey(ctx context.Context, arg query.InsertPasskeyParams) (query.Passkey, error)
+	SelectUserPasskeys(ctx context.Context, userID uuid.UUID) ([]query.Passkey, error)
+	SelectPasskeyByCredentialID(ctx context.Context, credentialID string) (query.Passkey, error)
+	CountUserPasskeys(ctx context.Context, userID uuid.UUID) (int64, error)
+	UpdatePasskeyUsed(ctx context.Context, arg query.UpdatePasskeyUsedParams) (int64, error)
+	DeleteUserPasskey(ctx context.Context, arg query.DeleteUserPasskeyParams) (int64, error)
+}
+
+// Service registers users' passkeys and runs the WebAuthn ceremonies that
+// sign in with them, either in place of a magic link or as a second factor
+type Service struct {
+	cfg     *config.Config
+	store   store
+	rpID    string
+	origins []string
+}
+
+// NewService creates a new passkey service
+func NewService(cfg *config.Config, store store) *Service {
+	rpID := cfg.WebAuthnRPID
+	if rpID == "" {
+		if u, err := url.Parse(cfg.ClientURL); err == nil {
+			rpID = u.Hostname()
+		}
+	}
+	var origins []string
+	for _, raw := range []string{cfg.ClientURL, cfg.AdminURL} {
+		if u, err := url.Parse(raw); err == nil && u.Host != "" {
+			origins = append(origins, u.Scheme+"://"+u.Host)
+		}
+	}
+	return &Service{cfg: cfg, store: store, rpID: rpID, origins: origins}
+}
+
+// Enabled reports whether a user has passkeys, and so can complete sign-in
+// with one
+func (s *Service) Enabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	n, err := s.store.CountUserPasskeys(ctx, userID)
+	if err != nil {
+		return false, pkg.InternalError{Message: "Error counting passkeys", Err: err}
+	}
+	return n > 0, nil
+}
+
+// List returns a user's passkeys
+func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]Passkey, error) {
+	rows, err := s.store.SelectUserPasskeys(ctx, userID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting passkeys", Err: err}
+	}
+	passkeys := make([]Passkey, len(rows))
+	for i, row := range rows {
+		passkeys[i] = toPasskey(row)
+	}
+	return passkeys, nil
+}
+
+// Remove deletes one of a user's passkeys
+func (s *Service) Remove(ctx context.Context, userID, id uuid.UUID) error {
+	n, err := s.store.DeleteUserPasskey(ctx, query.DeleteUserPasskeyParams{ID: id, UserID: userID})
+	if err != nil {
+		return pkg.InternalError{Message: "Error deleting passkey", Err: err}
+	}
+	if n == 0 {
+		return pkg.NotFoundError{Message: "Passkey not found", Err: fmt.Errorf("passkey %s of user %s not found", id, userID)}
+	}
+	return nil
+}
+
+// RegistrationOptions starts registering a passkey for a user. The user's
+// existing passkeys are excluded so an authenticator is not registered
+// twice.
+func (s *Service) RegistrationOptions(ctx context.Context, userID uuid.UUID, email string) (*CreationOptions, error) {
+	rows, err := s.store.SelectUserPasskeys(ctx, userID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting passkeys", Err: err}
+	}
+	if len(rows) >= MaxPasskeys {
+		return nil, pkg.BadRequestError{
+			Message: fmt.Sprintf("You can register at most %d passkeys", MaxPasskeys),
+			Err:     fmt.Errorf("user %s has %d passkeys", userID, len(rows)),
+		}
+	}
+	challenge, err := s.challenge(ctx, purposeRegister, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	params := make([]CredentialParameter, len(algorithms))
+	for i, alg := range algorithms {
+		params[i] = CredentialParameter{Type: "public-key", Alg: alg}
+	}
+	return &CreationOptions{
+		Challenge: challenge,
+		RP:        RelyingParty{ID: s.rpID, Name: RPName},
+		User: UserEntity{
+			ID:          encoding.EncodeToString(userID[:]),
+			Name:        email,
+			DisplayName: email,
+		},
+		PubKeyCredParams:   params,
+		Timeout:            ChallengeTTL.Milliseconds(),
+		ExcludeCredentials: descriptors(rows),
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      "required",
+			UserVerification: "required",
+		},
+		Attestation: "none",
+	}, nil
+}
+
+// Register verifies a new passkey against the challenge issued for the user
+// and stores it
+func (s *Service) Register(ctx context.Context, userID uuid.UUID, req RegistrationRequest) (*Passkey, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Passkey"
+	}
+	if utf8.RuneCountInString(name) > 100 {
+		return nil, pkg.ValidationErrors{{
+			Field:   "name",
+			Tag:     "max",
+			Message: "Name must be at most 100 characters",
+		}}
+	}
+
+	cred := req.Credential
+	invalid := func(err error) error {
+		return pkg.BadRequestError{Message: "Invalid passkey registration", Err: err}
+	}
+	clientDataJSON, err := decode(cred.Response.ClientDataJSON)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	client, err := parseClientData(clientDataJSON, "webauthn.create", s.origins)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	if _, err := s.consume(ctx, client.Challenge, purposeRegister, userID); err != nil {
+		return nil, invalid(err)
+	}
+
+	attestationObject, err := decode(cred.Response.AttestationObject)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	rawAuthData, err := parseAttestationObject(attestationObject)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	if err := authData.verify(s.rpID, true); err != nil {
+		return nil, invalid(err)
+	}
+	credentialID := encoding.EncodeToString(authData.CredentialID)
+	if authData.CredentialID == nil || credentialID != trimPadding(cred.ID) {
+		return nil, invalid(errors.New("credential ID does not match authenticator data"))
+	}
+	_, alg, err := parseCOSEKey(authData.PublicKey)
+	if err != nil {
+		return nil, invalid(err)
+	}
+
+	_, err = s.store.SelectPasskeyByCredentialID(ctx, credentialID)
+	if err == nil {
+		return nil, pkg.BadRequestError{Message: "Passkey is already registered", Err: fmt.Errorf("credential %s exists", credentialID)}
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.InternalError{Message: "Error selecting passkey", Err: err}
+	}
+	n, err := s.store.CountUserPasskeys(ctx, userID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error counting passkeys", Err: err}
+	}
+	if n >= MaxPasskeys {
+		return nil, pkg.BadRequestError{
+			Message: fmt.Sprintf("You can register at most %d passkeys", MaxPasskeys),
+			Err:     fmt.Errorf("user %s has %d passkeys", userID, n),
+		}
+	}
+
+	transports := cred.Response.Transports
+	if transports == nil {
+		transports = []string{}
+	}
+	b, err := json.Marshal(transports)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error encoding transports", Err: err}
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating UUID", Err: err}
+	}
+	row, err := s.store.InsertPasskey(ctx, query.InsertPasskeyParams{
+		ID:           id,
+		UserID:       userID,
+		Name:         name,
+		CredentialID: credentialID,
+		PublicKey:    authData.PublicKey,
+		Algorithm:    int32(alg),
+		SignCount:    int64(authData.SignCount),
+		Transports:   b,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error inserting passkey", Err: err}
+	}
+	passkey := toPasskey(row)
+	return &passkey, nil
+}
+
+// LoginOptions starts a passkey sign-in. The return URL is kept with the
+// challenge and handed back when the sign-in completes.
+func (s *Service) LoginOptions(ctx context.Context, returnURL string) (*RequestOptions, error) {
+	challenge, err := s.challenge(ctx, purposeLogin, uuid.Nil, returnURL)
+	if err != nil {
+		return nil, err
+	}
+	return &RequestOptions{
+		Challenge:        challenge,
+		RPID:             s.rpID,
+		Timeout:          ChallengeTTL.Milliseconds(),
+		AllowCredentials: []CredentialDescriptor{},
+		UserVerification: "required",
+	}, nil
+}
+
+// Login completes a passkey sign-in and returns the user it is for. The
+// user must have been verified by the authenticator, as the passkey stands
+// in for both factors.
+func (s *Service) Login(ctx context.Context, cred AssertionCredential) (*Assertion, error) {
+	passkey, challenge, err := s.assert(ctx, purposeLogin, uuid.Nil, cred)
+	if err != nil {
+		return nil, pkg.UnauthorizedError{Err: err}
+	}
+	return &Assertion{UserID: passkey.UserID, ReturnURL: challenge.ReturnUrl}, nil
+}
+
+// VerifyOptions starts the second factor of a sign-in, allowing only the
+// user's own passkeys
+func (s *Service) VerifyOptions(ctx context.Context, userID uuid.UUID) (*RequestOptions, error) {
+	rows, err := s.store.SelectUserPasskeys(ctx, userID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting passkeys", Err: err}
+	}
+	if len(rows) == 0 {
+		return nil, pkg.BadRequestError{Message: "You have no passkeys", Err: fmt.Errorf("user %s has no passkeys", userID)}
+	}
+	challenge, err := s.challenge(ctx, purposeVerify, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	return &RequestOptions{
+		Challenge:        challenge,
+		RPID:             s.rpID,
+		Timeout:          ChallengeTTL.Milliseconds(),
+		AllowCredentials: descriptors(rows),
+		UserVerification: "preferred",
+	}, nil
+}
+
+// Verify checks the passkey a user completes sign-in with, after the magic
+// link has proved their email address
+func (s *Service) Verify(ctx context.Context, userID uuid.UUID, cred AssertionCredential) error {
+	if _, _, err := s.assert(ctx, purposeVerify, userID, cred); err != nil {
+		return pkg.UnauthorizedError{Err: err}
+	}
+	return nil
+}
+
+// assert verifies an assertion against the challenge issued for the
+// purpose, and records the passkey's use. Sign-in requires user
+// verification; the second factor only presence.
+func (s *Service) assert(ctx context.Context, purpose string, userID uuid.UUID, cred AssertionCredential) (*query.Passkey, *query.PasskeyChallenge, error) {
+	clientDataJSON, err := decode(cred.Response.ClientDataJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := parseClientData(clientDataJSON, "webauthn.get", s.origins)
+	if err != nil {
+		return nil, nil, err
+	}
+	challenge, err := s.consume(ctx, client.Challenge, purpose, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	passkey, err := s.store.SelectPasskeyByCredentialID(ctx, trimPadding(cred.ID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error selecting passkey: %w", err)
+	}
+	if purpose == purposeVerify && passkey.UserID != userID {
+		return nil, nil, fmt.Errorf("passkey %s belongs to another user", passkey.ID)
+	}
+	if cred.Response.UserHandle != "" {
+		handle, err := decode(cred.Response.UserHandle)
+		if err != nil || string(handle) != string(passkey.UserID[:]) {
+			return nil, nil, errors.New("user handle does not match passkey")
+		}
+	}
+
+	rawAuthData, err := decode(cred.Response.AuthenticatorData)
+	if err != nil {
+		return nil, nil, err
+	}
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := authData.verify(s.rpID, purpose == purposeLogin); err != nil {
+		return nil, nil, err
+	}
+	signature, err := decode(cred.Response.Signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	alg := int64(passkey.Algorithm)
+	key, err := parsePublicKey(passkey.PublicKey, alg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := verifySignature(key, alg, rawAuthData, clientDataJSON, signature); err != nil {
+		return nil, nil, err
+	}
+
+	// Authenticators that count signatures must count up; one that does
+	// not may have been cloned. Synced passkeys always report zero. The
+	// count is only raised if it is still below this one, so of two
+	// assertions with the same count racing each other, one fails.
+	count := int64(authData.SignCount)
+	n, err := s.store.UpdatePasskeyUsed(ctx, query.UpdatePasskeyUsedParams{SignCount: count, ID: passkey.ID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error updating passkey: %w", err)
+	}
+	if n == 0 {
+		slog.Warn("Passkey sign count did not increase", "passkey_id", passkey.ID, "user_id", passkey.UserID)
+		return nil, nil, fmt.Errorf("sign count %d is not above the stored count", count)
+	}
+	return &passkey, challenge, nil
+}
+
+// challenge issues a random challenge for a ceremony, which expires with
+// the ceremony. Challenges of abandoned ceremonies are cleared out as new
+// ones are issued.
+func (s *Service) challenge(ctx context.Context, purpose string, userID uuid.UUID, returnURL string) (string, error) {
+	if err := s.store.DeleteExpiredPasskeyChallenges(ctx); err != nil {
+		slog.Warn("Error deleting expired passkey challenges", "error", err)
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", pkg.InternalError{Message: "Error generating challenge", Err: err}
+	}
+	challenge := encoding.EncodeToString(b)
+	err := s.store.InsertPasskeyChallenge(ctx, query.InsertPasskeyChallengeParams{
+		ID:        challenge,
+		ExpiresAt: time.Now().Add(ChallengeTTL),
+		Purpose:   purpose,
+		UserID:    uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+		ReturnUrl: returnURL,
+	})
+	if err != nil {
+		return "", pkg.InternalError{Message: "Error inserting challenge", Err: err}
+	}
+	return challenge, nil
+}
+
+// consume uses up a challenge, which must not have expired and must have
+// been issued for the purpose and user
+func (s *Service) consume(ctx context.Context, id, purpose string, userID uuid.UUID) (*query.PasskeyChallenge, error) {
+	challenge, err := s.store.ConsumePasskeyChallenge(ctx, trimPadding(id))
+	if err != nil {
+		return nil, fmt.Errorf("error consuming challenge: %w", err)
+	}
+	if time.Now().After(challenge.ExpiresAt) {
+		return nil, errors.New("challenge has expired")
+	}
+	if challenge.Purpose != purpose || challenge.UserID.UUID != userID {
+		return nil, fmt.Errorf("challenge was issued to %s for %s", challenge.UserID.UUID, challenge.Purpose)
+	}
+	return &challenge, nil
+}
+
+func descriptors(rows []query.Passkey) []CredentialDescriptor {
+	creds := make([]CredentialDescriptor, len(rows))
+	for i, row := range rows {
+		var transports []string
+		if err := json.Unmarshal(row.Transports, &transports); err != nil {
+			slog.Warn("Error decoding passkey transports", "error", err, "passkey_id", row.ID)
+		}
+		creds[i] = CredentialDescriptor{Type: "public-key", ID: row.CredentialID, Transports: transports}
+	}
+	return creds
+}
+
+func toPasskey(row query.Passkey) Passkey {
+	p := Passkey{ID: row.ID, Name: row.Name, CreatedAt: row.CreatedAt}
+	if row.LastUsedAt.Valid {
+		p.LastUsedAt = &row.LastUsedAt.Time
+	}
+	return p
+}
@@ -0,0 +1,438 @@
+package passkey_test
+
+import (
+	"app/pkg"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"service-core/config"
+	"service-core/domain/passkey"
+	"service-core/storage/query"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type mockStore struct {
+	mu         sync.Mutex
+	challenges map[string]query.PasskeyChallenge
+	passkeys   map[string]query.Passkey
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{challenges: map[string]query.PasskeyChallenge{}, passkeys: map[string]query.Passkey{}}
+}
+
+func (m *mockStore) InsertPasskeyChallenge(ctx context.Context, arg query.InsertPasskeyChallengeParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.challenges[arg.ID] = query.PasskeyChallenge{
+		ID:        arg.ID,
+		ExpiresAt: arg.ExpiresAt,
+		Purpose:   arg.Purpose,
+		UserID:    arg.UserID,
+		ReturnUrl: arg.ReturnUrl,
+	}
+	return nil
+}
+
+func (m *mockStore) ConsumePasskeyChallenge(ctx context.Context, id string) (query.PasskeyChallenge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.challenges[id]
+	if !ok {
+		return query.PasskeyChallenge{}, sql.ErrNoRows
+	}
+	delete(m.challenges, id)
+	return c, nil
+}
+
+func (m *mockStore) DeleteExpiredPasskeyChallenges(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockStore) InsertPasskey(ctx context.Context, arg query.InsertPasskeyParams) (query.Passkey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := query.Passkey{
+		ID:           arg.ID,
+		UserID:       arg.UserID,
+		Name:         arg.Name,
+		CredentialID: arg.CredentialID,
+		PublicKey:    arg.PublicKey,
+		Algorithm:    arg.Algorithm,
+		SignCount:    arg.SignCount,
+		Transports:   arg.Transports,
+	}
+	m.passkeys[arg.CredentialID] = p
+	return p, nil
+}
+
+func (m *mockStore) SelectUserPasskeys(ctx context.Context, userID uuid.UUID) ([]query.Passkey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var rows []query.Passkey
+	for _, p := range m.passkeys {
+		if p.UserID == userID {
+			rows = append(rows, p)
+		}
+	}
+	return rows, nil
+}
+
+func (m *mockStore) SelectPasskeyByCredentialID(ctx context.Context, credentialID string) (query.Passkey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.passkeys[credentialID]
+	if !ok {
+		return query.Passkey{}, sql.ErrNoRows
+	}
+	return p, nil
+}
+
+func (m *mockStore) CountUserPasskeys(ctx context.Context, userID uuid.UUID) (int64, error) {
+	rows, err := m.SelectUserPasskeys(ctx, userID)
+	return int64(len(rows)), err
+}
+
+func (m *mockStore) UpdatePasskeyUsed(ctx context.Context, arg query.UpdatePasskeyUsedParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, p := range m.passkeys {
+		if p.ID == arg.ID && (p.SignCount < arg.SignCount || p.SignCount == 0 && arg.SignCount == 0) {
+			p.SignCount = arg.SignCount
+			m.passkeys[id] = p
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *mockStore) DeleteUserPasskey(ctx context.Context, arg query.DeleteUserPasskeyParams) (int64, error) {
+	return 0, nil
+}
+
+var b64 = base64.RawURLEncoding
+
+// authenticator is a software authenticator holding one P-256 passkey
+type authenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	origin       string
+	rpID         string
+	count        uint32
+}
+
+func newAuthenticator(t *testing.T) *authenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	return &authenticator{key: key, credentialID: id, origin: "http://localhost:3000", rpID: "localhost", count: 1}
+}
+
+func (a *authenticator) clientData(t *testing.T, ceremony, challenge string) []byte {
+	t.Helper()
+	b, err := json.Marshal(map[string]any{"type": ceremony, "challenge": challenge, "origin": a.origin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func (a *authenticator) authData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	b := append([]byte{}, rpIDHash[:]...)
+	flags := byte(0x01 | 0x04)
+	if attested {
+		flags |= 0x40
+	}
+	b = append(b, flags)
+	b = binary.BigEndian.AppendUint32(b, a.count)
+	if attested {
+		b = append(b, make([]byte, 16)...)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(a.credentialID)))
+		b = append(b, a.credentialID...)
+		b = append(b, a.coseKey()...)
+	}
+	return b
+}
+
+// coseKey encodes the public key as an EC2 COSE key:
+// {1: 2, 3: -7, -1: 1, -2: x, -3: y}
+func (a *authenticator) coseKey() []byte {
+	x := a.key.PublicKey.X.FillBytes(make([]byte, 32))
+	y := a.key.PublicKey.Y.FillBytes(make([]byte, 32))
+	b := []byte{0xa5, 0x01, 0x02, 0x03, 0x26, 0x20, 0x01, 0x21}
+	b = append(cborBytes(b, x), 0x22)
+	return cborBytes(b, y)
+}
+
+// attestationObject encodes authenticator data in an attestation object
+// without an attestation statement:
+// {"fmt": "none", "attStmt": {}, "authData": authData}
+func attestationObject(authData []byte) []byte {
+	b := []byte{0xa3, 0x63, 'f', 'm', 't', 0x64, 'n', 'o', 'n', 'e'}
+	b = append(b, 0x67, 'a', 't', 't', 'S', 't', 'm', 't', 0xa0)
+	b = append(b, 0x68, 'a', 'u', 't', 'h', 'D', 'a', 't', 'a')
+	return cborBytes(b, authData)
+}
+
+// cborBytes appends a CBOR byte string of up to 65535 bytes
+func cborBytes(b, s []byte) []byte {
+	if len(s) < 256 {
+		b = append(b, 0x58, byte(len(s)))
+	} else {
+		b = binary.BigEndian.AppendUint16(append(b, 0x59), uint16(len(s)))
+	}
+	return append(b, s...)
+}
+
+func (a *authenticator) create(t *testing.T, challenge string) passkey.RegistrationRequest {
+	t.Helper()
+	return passkey.RegistrationRequest{
+		Name: "Laptop",
+		Credential: passkey.RegistrationCredential{
+			ID:   b64.EncodeToString(a.credentialID),
+			Type: "public-key",
+			Response: passkey.AttestationResponse{
+				ClientDataJSON:    b64.EncodeToString(a.clientData(t, "webauthn.create", challenge)),
+				AttestationObject: b64.EncodeToString(attestationObject(a.authData(true))),
+				Transports:        []string{"internal"},
+			},
+		},
+	}
+}
+
+func (a *authenticator) get(t *testing.T, challenge string) passkey.AssertionCredential {
+	t.Helper()
+	clientData := a.clientData(t, "webauthn.get", challenge)
+	authData := a.authData(false)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return passkey.AssertionCredential{
+		ID:   b64.EncodeToString(a.credentialID),
+		Type: "public-key",
+		Response: passkey.AssertionResponse{
+			ClientDataJSON:    b64.EncodeToString(clientData),
+			AuthenticatorData: b64.EncodeToString(authData),
+			Signature:         b64.EncodeToString(sig),
+		},
+	}
+}
+
+func setup(t *testing.T) (*passkey.Service, *authenticator, uuid.UUID) {
+	t.Helper()
+	ctx := context.Background()
+	service := passkey.NewService(config.LoadTestConfig(), newMockStore())
+	userID := uuid.New()
+	a := newAuthenticator(t)
+	opts, err := service.RegistrationOptions(ctx, userID, "user@example.com")
+	if err != nil {
+		t.Fatalf("RegistrationOptions() error = %v", err)
+	}
+	if opts.RP.ID != "localhost" {
+		t.Fatalf("RP ID = %q, want localhost", opts.RP.ID)
+	}
+	if _, err := service.Register(ctx, userID, a.create(t, opts.Challenge)); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	return service, a, userID
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	service, a, userID := setup(t)
+
+	passkeys, err := service.List(ctx, userID)
+	if err != nil || len(passkeys) != 1 || passkeys[0].Name != "Laptop" {
+		t.Fatalf("List() = %v, %v, want the registered passkey", passkeys, err)
+	}
+
+	// The challenge is used up by the first registration
+	opts, err := service.RegistrationOptions(ctx, userID, "user@example.com")
+	if err != nil {
+		t.Fatalf("RegistrationOptions() error = %v", err)
+	}
+	if len(opts.ExcludeCredentials) != 1 {
+		t.Errorf("ExcludeCredentials = %v, want the registered passkey", opts.ExcludeCredentials)
+	}
+	var badRequest pkg.BadRequestError
+	if _, err := service.Register(ctx, userID, a.create(t, opts.Challenge)); !errors.As(err, &badRequest) {
+		t.Errorf("Register() of a registered passkey error = %v, want bad request", err)
+	}
+	other := newAuthenticator(t)
+	if _, err := service.Register(ctx, userID, other.create(t, opts.Challenge)); !errors.As(err, &badRequest) {
+		t.Errorf("Register() with a used challenge error = %v, want bad request", err)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	var unauthorized pkg.UnauthorizedError
+
+	tests := []struct {
+		name   string
+		modify func(a *authenticator)
+		want   bool
+	}{
+		{name: "valid", modify: func(a *authenticator) { a.count = 2 }, want: true},
+		{name: "other origin", modify: func(a *authenticator) { a.count, a.origin = 2, "https://evil.example" }},
+		{name: "other relying party", modify: func(a *authenticator) { a.count, a.rpID = 2, "evil.example" }},
+		{name: "sign count did not increase", modify: func(a *authenticator) {}},
+		{name: "other key", modify: func(a *authenticator) {
+			a.count = 2
+			a.key, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			service, a, userID := setup(t)
+			opts, err := service.LoginOptions(ctx, "/dashboard")
+			if err != nil {
+				t.Fatalf("LoginOptions() error = %v", err)
+			}
+			tt.modify(a)
+			assertion, err := service.Login(ctx, a.get(t, opts.Challenge))
+			if !tt.want {
+				if !errors.As(err, &unauthorized) {
+					t.Errorf("Login() error = %v, want unauthorized", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Login() error = %v", err)
+			}
+			if assertion.UserID != userID || assertion.ReturnURL != "/dashboard" {
+				t.Errorf("Login() = %+v, want user %s returning to /dashboard", assertion, userID)
+			}
+			// A challenge is only accepted once
+			a.count++
+			if _, err := service.Login(ctx, a.get(t, opts.Challenge)); !errors.As(err, &unauthorized) {
+				t.Errorf("Login() with a used challenge error = %v, want unauthorized", err)
+			}
+		})
+	}
+}
+
+func TestLoginSignCountRace(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	service, a, _ := setup(t)
+
+	// Two assertions with the same sign count, as from a cloned
+	// authenticator, both pass their checks before either is recorded
+	a.count = 2
+	assertions := make([]passkey.AssertionCredential, 2)
+	for i := range assertions {
+		opts, err := service.LoginOptions(ctx, "/dashboard")
+		if err != nil {
+			t.Fatalf("LoginOptions() error = %v", err)
+		}
+		assertions[i] = a.get(t, opts.Challenge)
+	}
+	errs := make([]error, len(assertions))
+	var wg sync.WaitGroup
+	for i, assertion := range assertions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = service.Login(ctx, assertion)
+		}()
+	}
+	wg.Wait()
+
+	var unauthorized pkg.UnauthorizedError
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else if !errors.As(err, &unauthorized) {
+			t.Errorf("Login() error = %v, want unauthorized", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d of 2 logins with the same sign count succeeded, want 1", succeeded)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	service, a, userID := setup(t)
+
+	a.count = 2
+	opts, err := service.VerifyOptions(ctx, userID)
+	if err != nil {
+		t.Fatalf("VerifyOptions() error = %v", err)
+	}
+	if len(opts.AllowCredentials) != 1 {
+		t.Fatalf("AllowCredentials = %v, want the user's passkey", opts.AllowCredentials)
+	}
+	// A challenge issued for another user is refused
+	var unauthorized pkg.UnauthorizedError
+	if err := service.Verify(ctx, uuid.New(), a.get(t, opts.Challenge)); !errors.As(err, &unauthorized) {
+		t.Errorf("Verify() for another user error = %v, want unauthorized", err)
+	}
+	opts, err = service.VerifyOptions(ctx, userID)
+	if err != nil {
+		t.Fatalf("VerifyOptions() error = %v", err)
+	}
+	if err := service.Verify(ctx, userID, a.get(t, opts.Challenge)); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
+
+func TestLoginLegacyKey(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := newMockStore()
+	service := passkey.NewService(config.LoadTestConfig(), store)
+	a := newAuthenticator(t)
+
+	// Passkeys registered before COSE keys were stored hold the browser's
+	// SubjectPublicKeyInfo encoding
+	der, err := x509.MarshalPKIXPublicKey(&a.key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := uuid.New()
+	if _, err := store.InsertPasskey(ctx, query.InsertPasskeyParams{
+		ID:           uuid.New(),
+		UserID:       userID,
+		CredentialID: b64.EncodeToString(a.credentialID),
+		PublicKey:    der,
+		Algorithm:    int32(passkey.AlgES256),
+		SignCount:    1,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	opts, err := service.LoginOptions(ctx, "/dashboard")
+	if err != nil {
+		t.Fatalf("LoginOptions() error = %v", err)
+	}
+	a.count = 2
+	assertion, err := service.Login(ctx, a.get(t, opts.Challenge))
+	if err != nil || assertion.UserID != userID {
+		t.Errorf("Login() = %+v, %v, want user %s", assertion, err, userID)
+	}
+}
@@ -0,0 +1,291 @@
+package passkey
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// COSE algorithms of the keys passkeys may have, in order of preference
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+var algorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+// Authenticator data flags (WebAuthn section 6.1)
+const (
+	flagUserPresent      = 0x01
+	flagUserVerified     = 0x04
+	flagAttestedCredData = 0x40
+)
+
+var encoding = base64.RawURLEncoding
+
+// decode decodes a base64url value, with or without padding
+func decode(s string) ([]byte, error) {
+	return encoding.DecodeString(trimPadding(s))
+}
+
+func trimPadding(s string) string {
+	for len(s) > 0 && s[len(s)-1] == '=' {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// clientData is the client data a browser signs over (WebAuthn section 5.8.1)
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// parseClientData decodes client data and checks it is for a ceremony of
+// the given type from one of the allowed origins. The challenge is returned
+// for the caller to check.
+func parseClientData(raw []byte, ceremony string, origins []string) (*clientData, error) {
+	var c clientData
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, fmt.Errorf("error decoding client data: %w", err)
+	}
+	if c.Type != ceremony {
+		return nil, fmt.Errorf("client data is for %q, want %q", c.Type, ceremony)
+	}
+	if !slices.Contains(origins, c.Origin) {
+		return nil, fmt.Errorf("origin %q is not allowed", c.Origin)
+	}
+	if c.CrossOrigin {
+		return nil, errors.New("cross-origin ceremonies are not allowed")
+	}
+	return &c, nil
+}
+
+// authenticatorData is the data an authenticator signs over (WebAuthn
+// section 6.1). The credential ID and its COSE public key are only present
+// at registration.
+type authenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+func parseAuthenticatorData(b []byte) (*authenticatorData, error) {
+	if len(b) < 37 {
+		return nil, errors.New("authenticator data is too short")
+	}
+	d := &authenticatorData{
+		RPIDHash:  b[:32],
+		Flags:     b[32],
+		SignCount: binary.BigEndian.Uint32(b[33:37]),
+	}
+	if d.Flags&flagAttestedCredData != 0 {
+		// AAGUID (16 bytes), then the credential ID's length and the ID
+		rest := b[37:]
+		if len(rest) < 18 {
+			return nil, errors.New("attested credential data is too short")
+		}
+		n := int(binary.BigEndian.Uint16(rest[16:18]))
+		if len(rest) < 18+n {
+			return nil, errors.New("credential ID is truncated")
+		}
+		d.CredentialID = rest[18 : 18+n]
+
+		// The COSE key follows the ID, and extensions may follow the key
+		key := rest[18+n:]
+		_, after, err := decodeCBOR(key)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding credential public key: %w", err)
+		}
+		d.PublicKey = key[:len(key)-len(after)]
+	}
+	return d, nil
+}
+
+// parseAttestationObject returns the authenticator data of an attestation
+// object (WebAuthn section 6.5). The attestation statement is not checked,
+// as none is requested.
+func parseAttestationObject(b []byte) ([]byte, error) {
+	v, rest, err := decodeCBOR(b)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding attestation object: %w", err)
+	}
+	m, ok := v.(map[any]any)
+	if !ok || len(rest) != 0 {
+		return nil, errors.New("attestation object is not a map")
+	}
+	authData, ok := m["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("attestation object has no authenticator data")
+	}
+	return authData, nil
+}
+
+// verify checks authenticator data is for the relying party and that the
+// user was present, and verified when required
+func (d *authenticatorData) verify(rpID string, requireVerification bool) error {
+	want := sha256.Sum256([]byte(rpID))
+	if !bytes.Equal(d.RPIDHash, want[:]) {
+		return errors.New("authenticator data is for another relying party")
+	}
+	if d.Flags&flagUserPresent == 0 {
+		return errors.New("user was not present")
+	}
+	if requireVerification && d.Flags&flagUserVerified == 0 {
+		return errors.New("user was not verified")
+	}
+	return nil
+}
+
+// COSE key parameters and values (RFC 9052 and RFC 9053). The labels of
+// the key's own parameters depend on its type: an EC2 key's curve and
+// coordinates, an OKP key's curve and x, or an RSA key's modulus and
+// exponent.
+const (
+	coseKty = 1
+	coseAlg = 3
+	coseP1  = -1
+	coseP2  = -2
+	coseP3  = -3
+
+	coseKtyOKP = 1
+	coseKtyEC2 = 2
+	coseKtyRSA = 3
+
+	coseCrvP256    = 1
+	coseCrvEd25519 = 6
+)
+
+// parseCOSEKey parses a credential's public key, as the authenticator
+// attested it, and returns it with its algorithm
+func parseCOSEKey(b []byte) (crypto.PublicKey, int64, error) {
+	v, rest, err := decodeCBOR(b)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error decoding public key: %w", err)
+	}
+	m, ok := v.(map[any]any)
+	if !ok || len(rest) != 0 {
+		return nil, 0, errors.New("public key is not a COSE key")
+	}
+	kty, _ := m[int64(coseKty)].(int64)
+	alg, _ := m[int64(coseAlg)].(int64)
+	p1 := m[int64(coseP1)]
+	p2, _ := m[int64(coseP2)].([]byte)
+	p3, _ := m[int64(coseP3)].([]byte)
+
+	var key crypto.PublicKey
+	switch kty {
+	case coseKtyEC2:
+		if crv, _ := p1.(int64); crv != coseCrvP256 || len(p2) != 32 || len(p3) != 32 {
+			return nil, 0, errors.New("EC2 key is not on P-256")
+		}
+		// Checks the point is on the curve
+		point := append(append([]byte{0x04}, p2...), p3...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, 0, fmt.Errorf("error parsing EC2 key: %w", err)
+		}
+		key = &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(p2), Y: new(big.Int).SetBytes(p3)}
+	case coseKtyOKP:
+		if crv, _ := p1.(int64); crv != coseCrvEd25519 || len(p2) != ed25519.PublicKeySize {
+			return nil, 0, errors.New("OKP key is not Ed25519")
+		}
+		key = ed25519.PublicKey(p2)
+	case coseKtyRSA:
+		n, _ := p1.([]byte)
+		if len(n) == 0 || len(p2) == 0 || len(p2) > 4 {
+			return nil, 0, errors.New("RSA key is malformed")
+		}
+		e := new(big.Int).SetBytes(p2)
+		key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(e.Int64())}
+	default:
+		return nil, 0, fmt.Errorf("unsupported COSE key type %d", kty)
+	}
+	if err := checkKey(key, alg); err != nil {
+		return nil, 0, err
+	}
+	return key, alg, nil
+}
+
+// parsePublicKey parses a stored credential public key and checks it suits
+// the passkey's algorithm. Keys are stored as the COSE keys authenticators
+// attest; passkeys registered before then have the SubjectPublicKeyInfo
+// encoding browsers return from getPublicKey(), a DER sequence.
+func parsePublicKey(stored []byte, alg int64) (crypto.PublicKey, error) {
+	if len(stored) > 0 && stored[0] == 0x30 {
+		key, err := x509.ParsePKIXPublicKey(stored)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing public key: %w", err)
+		}
+		return key, checkKey(key, alg)
+	}
+	key, keyAlg, err := parseCOSEKey(stored)
+	if err != nil {
+		return nil, err
+	}
+	if keyAlg != alg {
+		return nil, fmt.Errorf("public key is for algorithm %d, not %d", keyAlg, alg)
+	}
+	return key, nil
+}
+
+// checkKey checks a public key suits an algorithm passkeys may have
+func checkKey(key crypto.PublicKey, alg int64) error {
+	ok := false
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		ok = alg == AlgES256 && k.Curve.Params().Name == "P-256"
+	case ed25519.PublicKey:
+		ok = alg == AlgEdDSA
+	case *rsa.PublicKey:
+		ok = alg == AlgRS256 && k.N.BitLen() >= 2048
+	}
+	if !ok {
+		return fmt.Errorf("public key does not suit algorithm %d", alg)
+	}
+	return nil
+}
+
+// verifySignature checks an assertion signature, which is over the
+// authenticator data followed by the hash of the client data
+func verifySignature(key crypto.PublicKey, alg int64, authData, clientDataJSON, signature []byte) error {
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(slices.Clone(authData), clientDataHash[:]...)
+	switch alg {
+	case AlgES256:
+		k, ok := key.(*ecdsa.PublicKey)
+		digest := sha256.Sum256(signed)
+		if ok && ecdsa.VerifyASN1(k, digest[:], signature) {
+			return nil
+		}
+	case AlgEdDSA:
+		k, ok := key.(ed25519.PublicKey)
+		if ok && ed25519.Verify(k, signed, signature) {
+			return nil
+		}
+	case AlgRS256:
+		k, ok := key.(*rsa.PublicKey)
+		digest := sha256.Sum256(signed)
+		if ok && rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil {
+			return nil
+		}
+	}
+	return errors.New("invalid signature")
+}
@@ -0,0 +1,299 @@
+package pdf
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"service-core/domain/proposal"
+	"service-core/storage/query"
+	"strings"
+	"time"
+)
+
+const dateFormat = "2 Jan 2006"
+
+// InvoiceDocument lays out an invoice with its line items, totals and the
+// agency's bank details
+func InvoiceDocument(inv query.Invoice, items []query.InvoiceLineItem, profile query.AgencyProfile, brand Branding) Document {
+	title := "Invoice"
+	if inv.GstRegistered {
+		title = "Tax Invoice"
+	}
+	meta := []Field{
+		{"Issue Date", inv.IssueDate.Format(dateFormat)},
+		{"Due Date", inv.DueDate.Format(dateFormat)},
+	}
+	if terms := paymentTerms(inv); terms != "" {
+		meta = append(meta, Field{"Terms", terms})
+	}
+	if inv.PaidAt.Valid {
+		meta = append(meta, Field{"Paid", inv.PaidAt.Time.Format(dateFormat)})
+	}
+
+	table := Table{Columns: []Column{
+		{Title: "Description", Width: 0.52},
+		{Title: "Qty", Width: 0.12, Align: AlignRight},
+		{Title: "Unit Price", Width: 0.18, Align: AlignRight},
+		{Title: "Amount", Width: 0.18, Align: AlignRight},
+	}}
+	for _, item := range items {
+		table.Rows = append(table.Rows, []string{
+			item.Description,
+			strings.TrimSuffix(strings.TrimSuffix(item.Quantity.String(), ".00"), ".0"),
+			item.UnitPrice.Format(),
+			item.Amount.Format(),
+		})
+	}
+
+	totals := Totals{{"Subtotal", inv.Subtotal.Format()}}
+	if !inv.DiscountAmount.IsZero() {
+		label := "Discount"
+		if inv.DiscountDescription != "" {
+			label += " (" + inv.DiscountDescription + ")"
+		}
+		totals = append(totals, Field{label, "-" + inv.DiscountAmount.Format()})
+	}
+	if inv.GstRegistered {
+		totals = append(totals, Field{"GST (" + inv.GstRate.String() + "%)", inv.GstAmount.Format()})
+	}
+	totals = append(totals, Field{"Total " + string(inv.Total.Currency()), inv.Total.Format()})
+	if !inv.AmountPaid.IsZero() && inv.Status != "paid" {
+		totals = append(totals,
+			Field{"Amount Paid", "-" + inv.AmountPaid.Format()},
+			Field{"Balance Due", inv.Total.Sub(inv.AmountPaid).Format()},
+		)
+	}
+
+	blocks := []Block{table, totals}
+	if inv.PublicNotes != "" {
+		blocks = append(blocks, Heading("Notes"), Paragraph(inv.PublicNotes))
+	}
+	if inv.Status != "paid" && inv.Status != "void" && profile.AccountNumber != "" {
+		blocks = append(blocks, Callout{
+			Title: "Payment Details",
+			Lines: nonEmpty(
+				labelled("Account Name", profile.AccountName),
+				labelled("Bank", profile.BankName),
+				labelled("BSB", profile.Bsb),
+				labelled("Account Number", profile.AccountNumber),
+				labelled("Reference", inv.InvoiceNumber),
+			),
+		})
+	}
+
+	return Document{
+		Title:  title,
+		Number: inv.InvoiceNumber,
+		Brand:  brand,
+		Parties: []Party{{
+			Label: "Bill To",
+			Name:  inv.ClientBusinessName,
+			Lines: nonEmpty(inv.ClientContactName, inv.ClientAddress, inv.ClientEmail, labelled("ABN", inv.ClientAbn)),
+		}},
+		Meta:   meta,
+		Blocks: blocks,
+	}
+}
+
+// ContractDocument lays out a contract's generated cover, terms and
+// schedules followed by the signing blocks
+func ContractDocument(c query.Contract, brand Branding) Document {
+	meta := []Field{
+		{"Date", c.CreatedAt.Format(dateFormat)},
+		{"Version", fmt.Sprintf("%d", c.Version)},
+	}
+	if c.CommencementDate.Valid {
+		meta = append(meta, Field{"Commencement", c.CommencementDate.Time.Format(dateFormat)})
+	}
+	if c.CompletionDate.Valid {
+		meta = append(meta, Field{"Completion", c.CompletionDate.Time.Format(dateFormat)})
+	}
+	price := c.TotalPrice.Format()
+	if c.PriceIncludesGst {
+		price += " inc. GST"
+	}
+	meta = append(meta, Field{"Total Price", price})
+
+	var blocks []Block
+	blocks = append(blocks, htmlBlocks(c.GeneratedCoverHtml.String)...)
+	if c.ServicesDescription != "" {
+		blocks = append(blocks, Heading("Services"), Paragraph(c.ServicesDescription))
+	}
+	if c.PaymentTerms != "" {
+		blocks = append(blocks, Heading("Payment Terms"), Paragraph(c.PaymentTerms))
+	}
+	blocks = append(blocks, htmlBlocks(c.GeneratedTermsHtml.String)...)
+	blocks = append(blocks, htmlBlocks(c.GeneratedScheduleHtml.String)...)
+	if c.SpecialConditions != "" {
+		blocks = append(blocks, Heading("Special Conditions"), Paragraph(c.SpecialConditions))
+	}
+	blocks = append(blocks, Heading("Signatures"), Signatures{
+		{
+			Label:    "Signed for " + brand.Name,
+			Name:     c.AgencySignatoryName.String,
+			Title:    c.AgencySignatoryTitle.String,
+			SignedAt: formatNullTime(c.AgencySignedAt),
+		},
+		{
+			Label:    "Signed for " + c.ClientBusinessName,
+			Name:     c.ClientSignatoryName.String,
+			Title:    c.ClientSignatoryTitle.String,
+			SignedAt: formatNullTime(c.ClientSignedAt),
+		},
+	})
+
+	return Document{
+		Title:  "Service Agreement",
+		Number: c.ContractNumber,
+		Brand:  brand,
+		Parties: []Party{{
+			Label: "Client",
+			Name:  c.ClientBusinessName,
+			Lines: nonEmpty(c.ClientContactName, c.ClientAddress, c.ClientEmail, c.ClientPhone),
+		}},
+		Meta:   meta,
+		Blocks: blocks,
+	}
+}
+
+// ProposalDocument lays out a proposal's sections and its pricing
+func ProposalDocument(p query.Proposal, pricing *proposal.PricingBreakdown, brand Branding) Document {
+	meta := []Field{{"Date", p.CreatedAt.Format(dateFormat)}}
+	if p.ValidUntil.Valid {
+		meta = append(meta, Field{"Valid Until", p.ValidUntil.Time.Format(dateFormat)})
+	}
+
+	blocks := []Block{Heading(p.Title)}
+	if p.ExecutiveSummary != "" {
+		blocks = append(blocks, Heading("Executive Summary"), Paragraph(p.ExecutiveSummary))
+	}
+	if p.OpportunityContent != "" {
+		blocks = append(blocks, Heading("The Opportunity"))
+		blocks = append(blocks, htmlBlocks(p.OpportunityContent)...)
+	}
+
+	var pages []proposal.ProposedPage
+	if json.Unmarshal(p.ProposedPages, &pages) == nil && len(pages) > 0 {
+		items := make(Bullets, len(pages))
+		for i, pg := range pages {
+			items[i] = pg.Name
+			if pg.Description != "" {
+				items[i] += " – " + pg.Description
+			}
+		}
+		blocks = append(blocks, Heading("Proposed Pages"), items)
+	}
+
+	var timeline []proposal.TimelinePhase
+	if json.Unmarshal(p.Timeline, &timeline) == nil && len(timeline) > 0 {
+		table := Table{Columns: []Column{
+			{Title: "Week", Width: 0.15},
+			{Title: "Phase", Width: 0.3},
+			{Title: "Description", Width: 0.55},
+		}}
+		for _, t := range timeline {
+			table.Rows = append(table.Rows, []string{t.Week, t.Title, t.Description})
+		}
+		blocks = append(blocks, Heading("Timeline"), table)
+	}
+
+	if pricing != nil && len(pricing.LineItems) > 0 {
+		table := Table{Columns: []Column{
+			{Title: "Item", Width: 0.5},
+			{Title: "Billing", Width: 0.2},
+			{Title: "Qty", Width: 0.1, Align: AlignRight},
+			{Title: "Amount", Width: 0.2, Align: AlignRight},
+		}}
+		for _, line := range pricing.LineItems {
+			billing := "One-time"
+			if line.Frequency == "monthly" {
+				billing = "Monthly"
+			}
+			table.Rows = append(table.Rows, []string{line.Description, billing, fmt.Sprintf("%d", line.Quantity), line.Amount.Format()})
+		}
+		totals := Totals{}
+		if !pricing.OneTime.Discount.IsZero() {
+			totals = append(totals, Field{"Discount", "-" + pricing.OneTime.Discount.Format()})
+		}
+		if !pricing.OneTime.Total.IsZero() {
+			totals = append(totals, Field{"One-time" + gstSuffix(pricing), pricing.OneTime.Total.Format()})
+		}
+		if !pricing.Monthly.Total.IsZero() {
+			totals = append(totals, Field{"Monthly" + gstSuffix(pricing), pricing.Monthly.Total.Format()})
+		}
+		totals = append(totals, Field{"First Year Investment", pricing.FirstYearCost.Format()})
+		blocks = append(blocks, Heading("Investment"), table, totals)
+		if pricing.DiscountNote != "" {
+			blocks = append(blocks, Paragraph(pricing.DiscountNote))
+		}
+	}
+
+	var steps []proposal.NextStep
+	if json.Unmarshal(p.NextSteps, &steps) == nil && len(steps) > 0 {
+		items := make(Bullets, len(steps))
+		for i, s := range steps {
+			items[i] = s.Text
+		}
+		blocks = append(blocks, Heading("Next Steps"), items)
+	}
+	if p.ClosingContent != "" {
+		blocks = append(blocks, htmlBlocks(p.ClosingContent)...)
+	}
+
+	return Document{
+		Title:  "Proposal",
+		Number: p.ProposalNumber,
+		Brand:  brand,
+		Parties: []Party{{
+			Label: "Prepared For",
+			Name:  p.ClientBusinessName,
+			Lines: nonEmpty(p.ClientContactName, p.ClientEmail, p.ClientPhone, p.ClientWebsite),
+		}},
+		Meta:   meta,
+		Blocks: blocks,
+	}
+}
+
+func gstSuffix(pricing *proposal.PricingBreakdown) string {
+	if pricing.GSTRegistered {
+		return " (inc. GST)"
+	}
+	return ""
+}
+
+// paymentTerms renders an invoice payment terms code such as NET_14
+func paymentTerms(inv query.Invoice) string {
+	switch {
+	case inv.PaymentTerms == "CUSTOM":
+		return inv.PaymentTermsCustom
+	case inv.PaymentTerms == "DUE_ON_RECEIPT":
+		return "Due on receipt"
+	case strings.HasPrefix(inv.PaymentTerms, "NET_"):
+		return "Net " + strings.TrimPrefix(inv.PaymentTerms, "NET_") + " days"
+	}
+	return inv.PaymentTerms
+}
+
+func formatNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.In(time.UTC).Format(dateFormat)
+}
+
+func labelled(label, value string) string {
+	if value == "" {
+		return ""
+	}
+	return label + ": " + value
+}
+
+func nonEmpty(values ...string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+package pdf
+
+// font is one of the two standard Type 1 fonts every PDF reader provides,
+// so nothing has to be embedded
+type font int
+
+const (
+	fontRegular font = iota
+	fontBold
+)
+
+func (f font) resource() string {
+	if f == fontBold {
+		return "F2"
+	}
+	return "F1"
+}
+
+func (f font) baseFont() string {
+	if f == fontBold {
+		return "Helvetica-Bold"
+	}
+	return "Helvetica"
+}
+
+// Glyph widths in thousandths of the font size for WinAnsi codes 32-126,
+// taken from the Adobe Helvetica and Helvetica-Bold AFM files
+var asciiWidths = [2][95]int{
+	{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// extendedWidths covers the punctuation above 127 that documents commonly
+// use. Other Latin-1 letters are close enough to 556.
+var extendedWidths = map[byte][2]int{
+	0x85: {1000, 1000}, // ellipsis
+	0x91: {222, 278},   // left single quote
+	0x92: {222, 278},   // right single quote
+	0x93: {333, 500},   // left double quote
+	0x94: {333, 500},   // right double quote
+	0x95: {350, 350},   // bullet
+	0x96: {556, 556},   // en dash
+	0x97: {1000, 1000}, // em dash
+	0x99: {1000, 1000}, // trademark
+	0xA0: {278, 278},   // no-break space
+	0xA9: {737, 737},   // copyright
+	0xB0: {400, 400},   // degree
+	0xD7: {584, 584},   // multiplication sign
+}
+
+// winAnsiSpecials maps the non Latin-1 characters of WinAnsiEncoding
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91,
+	'’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98,
+	'™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// winAnsi encodes text for the standard fonts. Characters outside the
+// encoding become a question mark.
+func winAnsi(s string) []byte {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			b = append(b, ' ')
+		case r >= 32 && r <= 126, r >= 0xA0 && r <= 0xFF:
+			b = append(b, byte(r))
+		default:
+			if c, ok := winAnsiSpecials[r]; ok {
+				b = append(b, c)
+			} else {
+				b = append(b, '?')
+			}
+		}
+	}
+	return b
+}
+
+// textWidth returns the width of s in points
+func textWidth(s string, f font, size float64) float64 {
+	total := 0
+	for _, c := range winAnsi(s) {
+		switch {
+		case c >= 32 && c <= 126:
+			total += asciiWidths[f][c-32]
+		default:
+			if w, ok := extendedWidths[c]; ok {
+				total += w[f]
+			} else {
+				total += 556
+			}
+		}
+	}
+	return float64(total) * size / 1000
+}
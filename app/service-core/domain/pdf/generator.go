@@ -1 +1,426 @@
 package pdf
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	margin       = 50.0
+	contentWidth = pageWidth - 2*margin
+	// bottomLimit leaves room for the footer
+	bottomLimit = pageHeight - 70
+)
+
+// Branding is the agency identity printed on every document
+type Branding struct {
+	Name    string
+	Logo    []byte
+	Primary string
+	Accent  string
+	Details []string
+	Footer  string
+}
+
+// Party is a labelled block of name and address lines, such as "Bill To"
+type Party struct {
+	Label string
+	Name  string
+	Lines []string
+}
+
+// Field is a label and value pair
+type Field struct {
+	Label string
+	Value string
+}
+
+// Document is the content of a PDF, independent of the record it was
+// built from. Invoices, contracts, proposals and quotations are all mapped
+// onto this before rendering.
+type Document struct {
+	Title   string
+	Number  string
+	Brand   Branding
+	Parties []Party
+	Meta    []Field
+	Blocks  []Block
+}
+
+// Block is a piece of body content
+type Block interface {
+	render(l *layout)
+}
+
+// Heading is a section title
+type Heading string
+
+// Paragraph is wrapped body text. Newlines start a new line.
+type Paragraph string
+
+// Bullets is an unordered list
+type Bullets []string
+
+// Align is the horizontal alignment of a table column
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignRight
+)
+
+// Column describes a table column. Width is a fraction of the content width.
+type Column struct {
+	Title string
+	Width float64
+	Align Align
+}
+
+// Table is a grid of text with a branded header row
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+}
+
+// Totals are right aligned summary rows. The last row is emphasised.
+type Totals []Field
+
+// Callout is a shaded box, used for payment details
+type Callout struct {
+	Title string
+	Lines []string
+}
+
+// Signature is one signing block
+type Signature struct {
+	Label    string
+	Name     string
+	Title    string
+	SignedAt string
+}
+
+// Signatures are signing blocks laid out side by side
+type Signatures []Signature
+
+// Render lays the document out on A4 pages and returns the PDF
+func Render(doc Document) ([]byte, error) {
+	l := &layout{
+		w:       &writer{title: strings.TrimSpace(doc.Title + " " + doc.Number)},
+		doc:     &doc,
+		primary: parseColor(doc.Brand.Primary, rgb{0.31, 0.27, 0.90}),
+		accent:  parseColor(doc.Brand.Accent, rgb{0.96, 0.62, 0.04}),
+	}
+	if len(doc.Brand.Logo) > 0 {
+		// A logo that cannot be decoded is left off rather than failing
+		// the whole document
+		if img, err := l.w.addImage(doc.Brand.Logo); err == nil {
+			l.logo = img
+		}
+	}
+	l.firstPage()
+	for _, b := range doc.Blocks {
+		b.render(l)
+	}
+	l.footers()
+	return l.w.bytes()
+}
+
+// layout tracks the cursor while flowing content down the pages
+type layout struct {
+	w       *writer
+	doc     *Document
+	page    *page
+	y       float64
+	primary rgb
+	accent  rgb
+	logo    *pdfImage
+}
+
+func (l *layout) firstPage() {
+	l.page = l.w.addPage()
+	l.page.rect(0, 0, pageWidth, 6, l.primary)
+
+	titleY := 62.0
+	if l.logo != nil {
+		h := 50.0
+		w := h * float64(l.logo.width) / float64(l.logo.height)
+		if w > 170 {
+			w = 170
+			h = w * float64(l.logo.height) / float64(l.logo.width)
+		}
+		l.page.image(l.logo, margin, 30, w, h)
+		l.y = 30 + h + 16
+	} else {
+		l.page.text(margin, titleY, fontBold, 18, l.primary, l.doc.Brand.Name)
+		l.y = titleY + 22
+	}
+	right := pageWidth - margin
+	title := strings.ToUpper(l.doc.Title)
+	l.page.text(right-textWidth(title, fontBold, 20), titleY, fontBold, 20, l.primary, title)
+	if l.doc.Number != "" {
+		l.page.text(right-textWidth(l.doc.Number, fontRegular, 10), titleY+16, fontRegular, 10, grey, l.doc.Number)
+	}
+
+	if l.logo != nil && l.doc.Brand.Name != "" {
+		l.page.text(margin, l.y, fontBold, 10, black, l.doc.Brand.Name)
+		l.y += 13
+	}
+	for _, d := range l.doc.Brand.Details {
+		l.page.text(margin, l.y, fontRegular, 9, grey, d)
+		l.y += 12
+	}
+	l.y += 14
+
+	// Parties flow left to right; meta fields sit in a column on the right
+	top := l.y
+	x := margin
+	partyWidth := contentWidth * 0.3
+	bottom := top
+	for _, p := range l.doc.Parties {
+		y := top
+		l.page.text(x, y, fontBold, 8, grey, strings.ToUpper(p.Label))
+		y += 14
+		if p.Name != "" {
+			l.page.text(x, y, fontBold, 10, black, p.Name)
+			y += 13
+		}
+		for _, line := range p.Lines {
+			for _, wrapped := range wrap(line, fontRegular, 9, partyWidth-10) {
+				l.page.text(x, y, fontRegular, 9, black, wrapped)
+				y += 12
+			}
+		}
+		bottom = max(bottom, y)
+		x += partyWidth
+	}
+	y := top
+	for _, m := range l.doc.Meta {
+		l.page.text(right-170, y, fontRegular, 9, grey, m.Label)
+		l.page.text(right-textWidth(m.Value, fontBold, 9), y, fontBold, 9, black, m.Value)
+		y += 14
+	}
+	l.y = max(bottom, y) + 10
+	l.page.line(margin, l.y, right, l.y, 0.75, lightGrey)
+	l.y += 20
+}
+
+// ensure starts a new page when h points of content will not fit
+func (l *layout) ensure(h float64) {
+	if l.y+h <= bottomLimit {
+		return
+	}
+	l.page = l.w.addPage()
+	l.page.rect(0, 0, pageWidth, 6, l.primary)
+	label := strings.TrimSpace(l.doc.Title + " " + l.doc.Number)
+	l.page.text(pageWidth-margin-textWidth(label, fontRegular, 8), 30, fontRegular, 8, grey, label)
+	l.y = 56
+}
+
+// footers stamps the footer text and page numbers once the page count is known
+func (l *layout) footers() {
+	footer := wrap(l.doc.Brand.Footer, fontRegular, 8, contentWidth-80)
+	if len(footer) > 2 {
+		footer = footer[:2]
+	}
+	for i, p := range l.w.pages {
+		y := pageHeight - 50
+		p.line(margin, y, pageWidth-margin, y, 0.5, lightGrey)
+		y += 14
+		for _, line := range footer {
+			p.text(margin, y, fontRegular, 8, grey, line)
+			y += 10
+		}
+		num := "Page " + strconv.Itoa(i+1) + " of " + strconv.Itoa(len(l.w.pages))
+		p.text(pageWidth-margin-textWidth(num, fontRegular, 8), pageHeight-36, fontRegular, 8, grey, num)
+	}
+}
+
+func (h Heading) render(l *layout) {
+	l.ensure(40)
+	l.y += 8
+	l.page.text(margin, l.y, fontBold, 13, l.primary, string(h))
+	l.y += 8
+	l.page.rect(margin, l.y, 28, 2, l.accent)
+	l.y += 16
+}
+
+func (p Paragraph) render(l *layout) {
+	for _, line := range wrap(string(p), fontRegular, 10, contentWidth) {
+		l.ensure(14)
+		l.page.text(margin, l.y, fontRegular, 10, black, line)
+		l.y += 14
+	}
+	l.y += 6
+}
+
+func (b Bullets) render(l *layout) {
+	for _, item := range b {
+		lines := wrap(item, fontRegular, 10, contentWidth-14)
+		for i, line := range lines {
+			l.ensure(14)
+			if i == 0 {
+				l.page.text(margin+2, l.y, fontRegular, 10, l.primary, "•")
+			}
+			l.page.text(margin+14, l.y, fontRegular, 10, black, line)
+			l.y += 14
+		}
+	}
+	l.y += 6
+}
+
+func (t Table) render(l *layout) {
+	const size, leading, pad = 9.0, 12.0, 6.0
+	widths := make([]float64, len(t.Columns))
+	for i, c := range t.Columns {
+		widths[i] = c.Width * contentWidth
+	}
+	header := func() {
+		l.ensure(22 + leading + 2*pad)
+		l.page.rect(margin, l.y, contentWidth, 20, l.primary)
+		x := margin
+		for i, c := range t.Columns {
+			cellText(l.page, c.Title, x, l.y+13.5, widths[i], c.Align, fontBold, size, white)
+			x += widths[i]
+		}
+		l.y += 20
+	}
+	header()
+	for r, row := range t.Rows {
+		cells := make([][]string, len(t.Columns))
+		lines := 1
+		for i := range t.Columns {
+			if i < len(row) {
+				cells[i] = wrap(row[i], fontRegular, size, widths[i]-2*pad)
+			}
+			lines = max(lines, len(cells[i]))
+		}
+		h := float64(lines)*leading + 2*pad
+		if l.y+h > bottomLimit {
+			l.ensure(h)
+			header()
+		}
+		if r%2 == 1 {
+			l.page.rect(margin, l.y, contentWidth, h, l.primary.tint(0.95))
+		}
+		x := margin
+		for i, c := range t.Columns {
+			for j, line := range cells[i] {
+				cellText(l.page, line, x, l.y+pad+9+float64(j)*leading, widths[i], c.Align, fontRegular, size, black)
+			}
+			x += widths[i]
+		}
+		l.y += h
+		l.page.line(margin, l.y, margin+contentWidth, l.y, 0.5, lightGrey)
+	}
+	l.y += 12
+}
+
+func cellText(p *page, s string, x, y, width float64, align Align, f font, size float64, c rgb) {
+	const pad = 6.0
+	if align == AlignRight {
+		x += width - pad - textWidth(s, f, size)
+	} else {
+		x += pad
+	}
+	p.text(x, y, f, size, c, s)
+}
+
+func (t Totals) render(l *layout) {
+	l.ensure(float64(len(t))*18 + 10)
+	right := pageWidth - margin
+	left := right - 230
+	for i, f := range t {
+		fnt, size, c := fontRegular, 10.0, black
+		if i == len(t)-1 {
+			l.page.line(left, l.y-4, right, l.y-4, 1, l.primary)
+			l.y += 6
+			fnt, size, c = fontBold, 12, l.primary
+		}
+		l.page.text(left, l.y+4, fnt, size, c, f.Label)
+		l.page.text(right-textWidth(f.Value, fnt, size), l.y+4, fnt, size, c, f.Value)
+		l.y += 18
+	}
+	l.y += 12
+}
+
+func (c Callout) render(l *layout) {
+	h := 20 + float64(len(c.Lines))*13 + 10
+	l.ensure(h + 10)
+	l.page.rect(margin, l.y, contentWidth, h, l.primary.tint(0.92))
+	l.page.rect(margin, l.y, 3, h, l.primary)
+	l.page.text(margin+14, l.y+18, fontBold, 10, l.primary, c.Title)
+	y := l.y + 33
+	for _, line := range c.Lines {
+		l.page.text(margin+14, y, fontRegular, 9, black, line)
+		y += 13
+	}
+	l.y += h + 16
+}
+
+func (s Signatures) render(l *layout) {
+	if len(s) == 0 {
+		return
+	}
+	l.ensure(120)
+	l.y += 10
+	width := contentWidth / float64(len(s))
+	for i, sig := range s {
+		x := margin + float64(i)*width
+		l.page.text(x, l.y, fontBold, 10, l.primary, sig.Label)
+		lineY := l.y + 46
+		if sig.SignedAt != "" {
+			l.page.text(x, lineY-8, fontBold, 14, black, sig.Name)
+		}
+		l.page.line(x, lineY, x+width-30, lineY, 0.75, grey)
+		y := lineY + 14
+		for _, f := range []Field{{"Name", sig.Name}, {"Title", sig.Title}, {"Date", sig.SignedAt}} {
+			l.page.text(x, y, fontRegular, 9, grey, f.Label+":")
+			l.page.text(x+40, y, fontRegular, 9, black, f.Value)
+			y += 13
+		}
+	}
+	l.y += 110
+}
+
+// wrap splits text into lines no wider than width, breaking on spaces and
+// splitting words that are too long on their own
+func wrap(s string, f font, size, width float64) []string {
+	var lines []string
+	for _, para := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		words := strings.Fields(para)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		line := ""
+		for _, word := range words {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if textWidth(candidate, f, size) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			line = word
+			for textWidth(line, f, size) > width && len([]rune(line)) > 1 {
+				runes := []rune(line)
+				n := len(runes) - 1
+				for n > 1 && textWidth(string(runes[:n]), f, size) > width {
+					n--
+				}
+				lines = append(lines, string(runes[:n]))
+				line = string(runes[n:])
+			}
+		}
+		lines = append(lines, line)
+	}
+	// Drop trailing blank lines
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
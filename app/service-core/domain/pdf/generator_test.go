@@ -0,0 +1,124 @@
+package pdf_test
+
+import (
+	"app/pkg/money"
+	"bytes"
+	"database/sql"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"regexp"
+	"service-core/domain/pdf"
+	"service-core/storage/query"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderInvoice(t *testing.T) {
+	t.Parallel()
+	logo := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for x := 0; x < 40; x++ {
+		logo.Set(x, 10, color.NRGBA{R: 200, A: 128})
+	}
+	var logoPNG bytes.Buffer
+	if err := png.Encode(&logoPNG, logo); err != nil {
+		t.Fatal(err)
+	}
+
+	inv := query.Invoice{
+		InvoiceNumber:      "INV-2026-0001",
+		ClientBusinessName: "Acme (Pty) Ltd",
+		IssueDate:          time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		DueDate:            time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC),
+		Subtotal:           money.MustParse("4000.00", money.AUD),
+		GstAmount:          money.MustParse("400.00", money.AUD),
+		Total:              money.MustParse("4400.00", money.AUD),
+		GstRegistered:      true,
+		GstRate:            money.MustParseDecimal("10"),
+		PaymentTerms:       "NET_14",
+		Status:             "sent",
+	}
+	var items []query.InvoiceLineItem
+	for i := 0; i < 80; i++ {
+		items = append(items, query.InvoiceLineItem{
+			Description: fmt.Sprintf("Line item %d – design & build", i),
+			Quantity:    money.NewDecimal(1),
+			UnitPrice:   money.MustParse("50.00", money.AUD),
+			Amount:      money.MustParse("50.00", money.AUD),
+		})
+	}
+	profile := query.AgencyProfile{AccountName: "Studio", Bsb: "062-000", AccountNumber: "12345678"}
+	brand := pdf.Branding{Name: "Studio", Logo: logoPNG.Bytes(), Primary: "#0f766e", Footer: "Thanks for your business"}
+
+	data, err := pdf.Render(pdf.InvoiceDocument(inv, items, profile, brand))
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("output is not framed as a PDF")
+	}
+	if n := bytes.Count(data, []byte("/Type /Page ")); n < 2 {
+		t.Errorf("page count = %d, want the line items to overflow onto a second page", n)
+	}
+
+	// Every xref entry must point at the start of its object
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	entries := strings.Split(string(data[xref:]), "\n")[3:]
+	for i, e := range entries {
+		if !strings.HasSuffix(e, " n ") {
+			break
+		}
+		off, _ := strconv.Atoi(e[:10])
+		if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(data[off:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, data[off:off+10])
+		}
+	}
+}
+
+func TestContractDocumentHTML(t *testing.T) {
+	t.Parallel()
+	c := query.Contract{
+		ContractNumber: "CON-2026-0001",
+		TotalPrice:     money.MustParse("1200", money.AUD),
+		GeneratedTermsHtml: sql.NullString{Valid: true, String: `
+			<h2>1. Terms</h2>
+			<p>The agency will
+			deliver <strong>the website</strong>.<br>On time.</p>
+			<ol><li>First</li><li>Second</li></ol>`},
+	}
+	doc := pdf.ContractDocument(c, pdf.Branding{Name: "Studio"})
+
+	var got []string
+	for _, b := range doc.Blocks {
+		switch v := b.(type) {
+		case pdf.Heading:
+			got = append(got, "H:"+string(v))
+		case pdf.Paragraph:
+			got = append(got, "P:"+string(v))
+		case pdf.Bullets:
+			got = append(got, "L:"+strings.Join(v, "|"))
+		}
+	}
+	want := []string{
+		"H:1. Terms",
+		"P:The agency will deliver the website.\nOn time.",
+		"L:1. First|2. Second",
+		"H:Signatures",
+	}
+	if strings.Join(got, "\n--\n") != strings.Join(want, "\n--\n") {
+		t.Errorf("blocks = %q, want %q", got, want)
+	}
+	if _, err := pdf.Render(doc); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package pdf
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// htmlBlocks converts the simple HTML produced by the contract and proposal
+// editors into blocks. Headings, paragraphs and list items are kept; inline
+// formatting is dropped.
+func htmlBlocks(src string) []Block {
+	var (
+		blocks  []Block
+		text    strings.Builder
+		bullets Bullets
+		ordered []int
+		inItem  bool
+		heading bool
+		skip    int
+	)
+	flush := func() {
+		s := strings.TrimSpace(collapse(text.String()))
+		text.Reset()
+		if s == "" {
+			return
+		}
+		switch {
+		case inItem:
+			if n := len(ordered); n > 0 && ordered[n-1] > 0 {
+				s = strconv.Itoa(ordered[n-1]) + ". " + s
+				ordered[n-1]++
+			}
+			bullets = append(bullets, s)
+		case heading:
+			blocks = append(blocks, Heading(s))
+		default:
+			blocks = append(blocks, Paragraph(s))
+		}
+	}
+	flushList := func() {
+		if len(bullets) > 0 {
+			blocks = append(blocks, bullets)
+			bullets = nil
+		}
+	}
+
+	z := html.NewTokenizer(strings.NewReader(src))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		name, _ := z.TagName()
+		tag := string(name)
+		switch tt {
+		case html.TextToken:
+			if skip == 0 {
+				// Source newlines are just whitespace; only <br> breaks a line
+				text.WriteString(strings.ReplaceAll(string(z.Text()), "\n", " "))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch tag {
+			case "script", "style", "head":
+				skip++
+			case "br":
+				text.WriteString("\n")
+			case "h1", "h2", "h3", "h4":
+				flush()
+				flushList()
+				heading = true
+			case "p", "div", "tr", "table", "section", "blockquote":
+				flush()
+			case "td", "th":
+				text.WriteString(" ")
+			case "ul":
+				flush()
+				ordered = append(ordered, 0)
+			case "ol":
+				flush()
+				ordered = append(ordered, 1)
+			case "li":
+				flush()
+				inItem = true
+			}
+		case html.EndTagToken:
+			switch tag {
+			case "script", "style", "head":
+				if skip > 0 {
+					skip--
+				}
+			case "h1", "h2", "h3", "h4":
+				flush()
+				heading = false
+			case "p", "div", "tr", "table", "section", "blockquote":
+				flush()
+			case "li":
+				flush()
+				inItem = false
+			case "ul", "ol":
+				flush()
+				if len(ordered) > 0 {
+					ordered = ordered[:len(ordered)-1]
+				}
+				if len(ordered) == 0 {
+					flushList()
+				}
+			}
+		}
+	}
+	flush()
+	flushList()
+	return blocks
+}
+
+// collapse squeezes runs of whitespace to single spaces while keeping the
+// line breaks written for <br>
+func collapse(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1 +1,327 @@
 package pdf
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"service-core/config"
+	"service-core/domain/proposal"
+	"service-core/storage/query"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxLogoSize caps the logo download
+const maxLogoSize = 2 << 20
+
+type store interface {
+	SelectAgency(ctx context.Context, id uuid.UUID) (query.Agency, error)
+	SelectAgencyProfile(ctx context.Context, agencyID uuid.UUID) (query.AgencyProfile, error)
+	SelectAgencyDocumentBranding(ctx context.Context, arg query.SelectAgencyDocumentBrandingParams) (query.AgencyDocumentBranding, error)
+	SelectInvoice(ctx context.Context, id uuid.UUID) (query.Invoice, error)
+	SelectInvoiceLineItems(ctx context.Context, invoiceID uuid.UUID) ([]query.InvoiceLineItem, error)
+	UpdateInvoicePdf(ctx context.Context, arg query.UpdateInvoicePdfParams) error
+	SelectContract(ctx context.Context, id uuid.UUID) (query.Contract, error)
+	UpdateContractPdf(ctx context.Context, arg query.UpdateContractPdfParams) error
+	SelectProposal(ctx context.Context, id uuid.UUID) (query.Proposal, error)
+}
+
+type fileService interface {
+	StoreFile(ctx context.Context, userID uuid.UUID, agencyID uuid.UUID, fileName string, contentType string, data []byte) (*query.File, error)
+}
+
+type pricer interface {
+	Price(ctx context.Context, p *query.Proposal) (*proposal.PricingBreakdown, error)
+}
+
+type Service struct {
+	cfg         *config.Config
+	store       store
+	fileService fileService
+	pricer      pricer
+	client      *http.Client
+}
+
+func NewService(
+	cfg *config.Config,
+	store store,
+	fileService fileService,
+	pricer pricer,
+) *Service {
+	return &Service{
+		cfg:         cfg,
+		store:       store,
+		fileService: fileService,
+		pricer:      pricer,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Result is a rendered PDF that has been stored
+type Result struct {
+	FileID uuid.UUID `json:"fileId"`
+	URL    string    `json:"url"`
+}
+
+// Publish renders an agency's document, stores it against the agency and the
+// user and returns its URL
+func (s *Service) Publish(ctx context.Context, agencyID, userID uuid.UUID, fileName string, doc Document) (*Result, error) {
+	data, err := Render(doc)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error rendering PDF", Err: err}
+	}
+	file, err := s.fileService.StoreFile(ctx, userID, agencyID, fileName, "application/pdf", data)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		FileID: file.ID,
+		URL:    s.cfg.CoreURL + "/api/v1/files/" + file.ID.String(),
+	}, nil
+}
+
+// RenderInvoice returns the PDF for an invoice without storing it
+func (s *Service) RenderInvoice(ctx context.Context, agencyID, invoiceID uuid.UUID) ([]byte, error) {
+	doc, _, err := s.invoiceDocument(ctx, agencyID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := Render(doc)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error rendering PDF", Err: err}
+	}
+	return data, nil
+}
+
+// GenerateInvoice renders and stores an invoice PDF and records its URL on
+// the invoice
+func (s *Service) GenerateInvoice(ctx context.Context, agencyID, userID, invoiceID uuid.UUID) (*Result, error) {
+	doc, inv, err := s.invoiceDocument(ctx, agencyID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.Publish(ctx, agencyID, userID, inv.InvoiceNumber+".pdf", doc)
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.UpdateInvoicePdf(ctx, query.UpdateInvoicePdfParams{
+		ID:     inv.ID,
+		PdfUrl: sql.NullString{String: res.URL, Valid: true},
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating invoice PDF", Err: err}
+	}
+	return res, nil
+}
+
+// RenderContract returns the PDF for a contract without storing it
+func (s *Service) RenderContract(ctx context.Context, agencyID, contractID uuid.UUID) ([]byte, error) {
+	doc, _, err := s.contractDocument(ctx, agencyID, contractID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := Render(doc)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error rendering PDF", Err: err}
+	}
+	return data, nil
+}
+
+// GenerateContract renders and stores a contract PDF and records its URL on
+// the contract
+func (s *Service) GenerateContract(ctx context.Context, agencyID, userID, contractID uuid.UUID) (*Result, error) {
+	doc, c, err := s.contractDocument(ctx, agencyID, contractID)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.Publish(ctx, agencyID, userID, c.ContractNumber+".pdf", doc)
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.UpdateContractPdf(ctx, query.UpdateContractPdfParams{
+		ID:           c.ID,
+		SignedPdfUrl: sql.NullString{String: res.URL, Valid: true},
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating contract PDF", Err: err}
+	}
+	return res, nil
+}
+
+// RenderProposal returns the PDF for a proposal without storing it
+func (s *Service) RenderProposal(ctx context.Context, agencyID, proposalID uuid.UUID) ([]byte, error) {
+	doc, _, err := s.proposalDocument(ctx, agencyID, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := Render(doc)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error rendering PDF", Err: err}
+	}
+	return data, nil
+}
+
+// GenerateProposal renders and stores a proposal PDF. Proposals have no PDF
+// column, so the URL is only returned.
+func (s *Service) GenerateProposal(ctx context.Context, agencyID, userID, proposalID uuid.UUID) (*Result, error) {
+	doc, p, err := s.proposalDocument(ctx, agencyID, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Publish(ctx, agencyID, userID, p.ProposalNumber+".pdf", doc)
+}
+
+func (s *Service) invoiceDocument(ctx context.Context, agencyID, id uuid.UUID) (Document, query.Invoice, error) {
+	inv, err := s.store.SelectInvoice(ctx, id)
+	if err != nil || inv.AgencyID != agencyID {
+		return Document{}, inv, notFound("Invoice", err)
+	}
+	items, err := s.store.SelectInvoiceLineItems(ctx, inv.ID)
+	if err != nil {
+		return Document{}, inv, pkg.InternalError{Message: "Error selecting invoice line items", Err: err}
+	}
+	brand, profile, err := s.Branding(ctx, agencyID, "invoice")
+	if err != nil {
+		return Document{}, inv, err
+	}
+	return InvoiceDocument(inv, items, profile, brand), inv, nil
+}
+
+func (s *Service) contractDocument(ctx context.Context, agencyID, id uuid.UUID) (Document, query.Contract, error) {
+	c, err := s.store.SelectContract(ctx, id)
+	if err != nil || c.AgencyID != agencyID {
+		return Document{}, c, notFound("Contract", err)
+	}
+	brand, _, err := s.Branding(ctx, agencyID, "contract")
+	if err != nil {
+		return Document{}, c, err
+	}
+	return ContractDocument(c, brand), c, nil
+}
+
+func (s *Service) proposalDocument(ctx context.Context, agencyID, id uuid.UUID) (Document, query.Proposal, error) {
+	p, err := s.store.SelectProposal(ctx, id)
+	if err != nil || p.AgencyID != agencyID {
+		return Document{}, p, notFound("Proposal", err)
+	}
+	pricing, err := s.pricer.Price(ctx, &p)
+	if err != nil {
+		return Document{}, p, err
+	}
+	brand, _, err := s.Branding(ctx, agencyID, "proposal")
+	if err != nil {
+		return Document{}, p, err
+	}
+	return ProposalDocument(p, pricing, brand), p, nil
+}
+
+// Branding resolves the agency's colours, logo, contact details and footer
+// for a document type. Per-document branding overrides the agency defaults
+// when it is enabled.
+func (s *Service) Branding(ctx context.Context, agencyID uuid.UUID, documentType string) (Branding, query.AgencyProfile, error) {
+	agency, err := s.store.SelectAgency(ctx, agencyID)
+	if err != nil {
+		return Branding{}, query.AgencyProfile{}, notFound("Agency", err)
+	}
+	profile, err := s.store.SelectAgencyProfile(ctx, agencyID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Branding{}, profile, pkg.InternalError{Message: "Error selecting agency profile", Err: err}
+	}
+
+	brand := Branding{
+		Name:    agency.Name,
+		Primary: agency.PrimaryColor,
+		Accent:  agency.AccentColor,
+	}
+	if profile.TradingName != "" {
+		brand.Name = profile.TradingName
+	}
+	logoURL := agency.LogoUrl
+
+	custom, err := s.store.SelectAgencyDocumentBranding(ctx, query.SelectAgencyDocumentBrandingParams{
+		AgencyID:     agencyID,
+		DocumentType: documentType,
+	})
+	switch {
+	case err == nil && custom.UseCustomBranding:
+		if custom.LogoUrl.String != "" {
+			logoURL = custom.LogoUrl.String
+		}
+		if custom.PrimaryColor.String != "" {
+			brand.Primary = custom.PrimaryColor.String
+		}
+		if custom.AccentColor.String != "" {
+			brand.Accent = custom.AccentColor.String
+		}
+	case err != nil && !errors.Is(err, sql.ErrNoRows):
+		return Branding{}, profile, pkg.InternalError{Message: "Error selecting document branding", Err: err}
+	}
+
+	locality := strings.TrimSpace(strings.Join(nonEmpty(profile.City, profile.State, profile.Postcode), " "))
+	brand.Details = nonEmpty(
+		strings.Join(nonEmpty(profile.AddressLine1, profile.AddressLine2), ", "),
+		locality,
+		labelled("ABN", profile.Abn),
+		strings.Join(nonEmpty(agency.Email, agency.Phone), "  |  "),
+		agency.Website,
+	)
+
+	switch documentType {
+	case "invoice":
+		brand.Footer = profile.InvoiceFooter
+	case "contract":
+		brand.Footer = profile.ContractFooter
+	}
+	if brand.Footer == "" {
+		brand.Footer = strings.Join(nonEmpty(profile.LegalEntityName, labelled("ABN", profile.Abn)), "  ·  ")
+	}
+
+	if logoURL != "" {
+		logo, err := s.fetchLogo(ctx, logoURL)
+		if err != nil {
+			// Documents are still useful without a logo
+			slog.Warn("Error fetching logo for PDF", "agency_id", agencyID, "error", err)
+		}
+		brand.Logo = logo
+	}
+	return brand, profile, nil
+}
+
+// fetchLogo downloads a logo over HTTP or decodes a base64 data URL
+func (s *Service) fetchLogo(ctx context.Context, url string) ([]byte, error) {
+	if strings.HasPrefix(url, "data:") {
+		_, data, ok := strings.Cut(url, ",")
+		if !ok {
+			return nil, errors.New("malformed data URL")
+		}
+		return base64.StdEncoding.DecodeString(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("logo request returned %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxLogoSize))
+}
+
+func notFound(what string, err error) error {
+	if err == nil || errors.Is(err, sql.ErrNoRows) {
+		return pkg.NotFoundError{Message: what + " not found", Err: err}
+	}
+	return pkg.InternalError{Message: "Error selecting " + strings.ToLower(what), Err: err}
+}
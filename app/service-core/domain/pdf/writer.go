@@ -0,0 +1,255 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	_ "image/gif"  // register decoder for logos
+	_ "image/jpeg" // register decoder for logos
+	_ "image/png"  // register decoder for logos
+	"strconv"
+	"strings"
+)
+
+// A4 in points
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+)
+
+// maxImageSide bounds decoded logos so a huge upload cannot exhaust memory
+const maxImageSide = 4000
+
+type rgb struct {
+	r, g, b float64
+}
+
+var (
+	black     = rgb{0.07, 0.09, 0.15}
+	grey      = rgb{0.42, 0.45, 0.50}
+	lightGrey = rgb{0.90, 0.91, 0.92}
+	white     = rgb{1, 1, 1}
+)
+
+// parseColor reads a #RGB or #RRGGBB colour
+func parseColor(s string, fallback rgb) rgb {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return fallback
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return fallback
+	}
+	return rgb{float64(v>>16&0xff) / 255, float64(v>>8&0xff) / 255, float64(v&0xff) / 255}
+}
+
+// tint mixes a colour towards white by amount (0-1)
+func (c rgb) tint(amount float64) rgb {
+	return rgb{c.r + (1-c.r)*amount, c.g + (1-c.g)*amount, c.b + (1-c.b)*amount}
+}
+
+type pdfImage struct {
+	name          string
+	width, height int
+	rgb           []byte
+	alpha         []byte
+}
+
+// page holds the content stream of one page. Coordinates passed to its
+// methods are measured from the top left corner.
+type page struct {
+	content bytes.Buffer
+}
+
+func (p *page) text(x, y float64, f font, size float64, c rgb, s string) {
+	fmt.Fprintf(&p.content, "BT /%s %.2f Tf %.3f %.3f %.3f rg %.2f %.2f Td (%s) Tj ET\n",
+		f.resource(), size, c.r, c.g, c.b, x, pageHeight-y, escape(winAnsi(s)))
+}
+
+func (p *page) rect(x, y, w, h float64, c rgb) {
+	fmt.Fprintf(&p.content, "%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f\n",
+		c.r, c.g, c.b, x, pageHeight-y-h, w, h)
+}
+
+func (p *page) line(x1, y1, x2, y2, width float64, c rgb) {
+	fmt.Fprintf(&p.content, "%.3f %.3f %.3f RG %.2f w %.2f %.2f m %.2f %.2f l S\n",
+		c.r, c.g, c.b, width, x1, pageHeight-y1, x2, pageHeight-y2)
+}
+
+func (p *page) image(img *pdfImage, x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "q %.2f 0 0 %.2f %.2f %.2f cm /%s Do Q\n", w, h, x, pageHeight-y-h, img.name)
+}
+
+// escape writes a PDF literal string body, keeping the file ASCII
+func escape(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c < 32 || c > 126:
+			fmt.Fprintf(&sb, "\\%03o", c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// writer collects pages and images and serialises them as a PDF 1.4 file
+type writer struct {
+	title  string
+	pages  []*page
+	images []*pdfImage
+}
+
+func (w *writer) addPage() *page {
+	p := &page{}
+	w.pages = append(w.pages, p)
+	return p
+}
+
+// addImage decodes a PNG, JPEG or GIF into raw RGB with a separate alpha
+// mask when the image has transparency
+func (w *writer) addImage(data []byte) (*pdfImage, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width > maxImageSide || cfg.Height > maxImageSide {
+		return nil, fmt.Errorf("image is %dx%d, larger than %d pixels", cfg.Width, cfg.Height, maxImageSide)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	bounds := src.Bounds()
+	img := &pdfImage{
+		name:   fmt.Sprintf("Im%d", len(w.images)+1),
+		width:  bounds.Dx(),
+		height: bounds.Dy(),
+		rgb:    make([]byte, 0, bounds.Dx()*bounds.Dy()*3),
+	}
+	alpha := make([]byte, 0, bounds.Dx()*bounds.Dy())
+	opaque := true
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := src.At(x, y).RGBA()
+			if a != 0xffff {
+				opaque = false
+			}
+			if a > 0 && a < 0xffff {
+				// Colours are premultiplied by alpha
+				r, g, b = r*0xffff/a, g*0xffff/a, b*0xffff/a
+			}
+			img.rgb = append(img.rgb, byte(r>>8), byte(g>>8), byte(b>>8))
+			alpha = append(alpha, byte(a>>8))
+		}
+	}
+	if !opaque {
+		img.alpha = alpha
+	}
+	w.images = append(w.images, img)
+	return img, nil
+}
+
+// bytes serialises the document
+func (w *writer) bytes() ([]byte, error) {
+	var objects [][]byte
+	reserve := func() int {
+		objects = append(objects, nil)
+		return len(objects)
+	}
+	set := func(n int, body string) {
+		objects[n-1] = []byte(body)
+	}
+
+	catalog, pages, regular, bold, info := reserve(), reserve(), reserve(), reserve(), reserve()
+	set(regular, fontObject(fontRegular))
+	set(bold, fontObject(fontBold))
+	set(info, fmt.Sprintf("<< /Producer (webkit) /Title (%s) >>", escape(winAnsi(w.title))))
+
+	var xobjects strings.Builder
+	for _, img := range w.images {
+		smask := ""
+		if img.alpha != nil {
+			n := reserve()
+			body, err := stream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8",
+				img.width, img.height), img.alpha)
+			if err != nil {
+				return nil, err
+			}
+			set(n, body)
+			smask = fmt.Sprintf(" /SMask %d 0 R", n)
+		}
+		n := reserve()
+		body, err := stream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8%s",
+			img.width, img.height, smask), img.rgb)
+		if err != nil {
+			return nil, err
+		}
+		set(n, body)
+		fmt.Fprintf(&xobjects, " /%s %d 0 R", img.name, n)
+	}
+	resources := fmt.Sprintf("<< /Font << /F1 %d 0 R /F2 %d 0 R >> /XObject <<%s >> >>", regular, bold, xobjects.String())
+
+	kids := make([]string, 0, len(w.pages))
+	for _, p := range w.pages {
+		content := reserve()
+		body, err := stream("", p.content.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		set(content, body)
+		n := reserve()
+		set(n, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources %s /Contents %d 0 R >>",
+			pages, pageWidth, pageHeight, resources, content))
+		kids = append(kids, fmt.Sprintf("%d 0 R", n))
+	}
+	set(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	set(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, body := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		buf.Write(body)
+		buf.WriteString("\nendobj\n")
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(objects)+1, catalog, info, xref)
+	return buf.Bytes(), nil
+}
+
+func fontObject(f font) string {
+	return fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.baseFont())
+}
+
+// stream returns a Flate compressed stream object with extra dictionary entries
+func stream(dict string, data []byte) (string, error) {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	if _, err := zw.Write(data); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	if dict != "" {
+		dict += " "
+	}
+	return fmt.Sprintf("<< %s/Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", dict, z.Len(), z.String()), nil
+}
@@ -1 +1,56 @@
 package pkg
+
+import "app/pkg/money"
+
+// PricingModel is how an agency package is billed
+type PricingModel string
+
+const (
+	PricingModelSubscription PricingModel = "subscription"
+	PricingModelLumpSum      PricingModel = "lump_sum"
+	PricingModelHybrid       PricingModel = "hybrid"
+)
+
+// PricingType is how an agency addon is billed
+type PricingType string
+
+const (
+	PricingTypeOneTime PricingType = "one_time"
+	PricingTypeMonthly PricingType = "monthly"
+	PricingTypePerUnit PricingType = "per_unit"
+)
+
+// Frequency is how often a charge recurs
+type Frequency string
+
+const (
+	FrequencyOneTime Frequency = "one_time"
+	FrequencyMonthly Frequency = "monthly"
+)
+
+// Overrides replaces individual package prices. Empty fields keep the
+// package price.
+type Overrides struct {
+	SetupFee     string
+	MonthlyPrice string
+	OneTimePrice string
+	HostingFee   string
+}
+
+// Pricing is the resolved price of a package
+type Pricing struct {
+	PricingModel      PricingModel
+	SetupFee          money.Money
+	OneTimePrice      money.Money
+	MonthlyPrice      money.Money
+	HostingFee        money.Money
+	MinimumTermMonths int32
+}
+
+// Charge is a single priced item
+type Charge struct {
+	Quantity  int32
+	UnitPrice money.Money
+	Amount    money.Money
+	Frequency Frequency
+}
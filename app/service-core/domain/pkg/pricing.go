@@ -1 +1,74 @@
 package pkg
+
+import (
+	"app/pkg/money"
+	"encoding/json"
+	"fmt"
+	"service-core/storage/query"
+)
+
+// Resolve returns the package prices with any overrides applied
+func Resolve(p query.AgencyPackage, o Overrides) (Pricing, error) {
+	pricing := Pricing{
+		PricingModel:      PricingModel(p.PricingModel),
+		SetupFee:          p.SetupFee,
+		OneTimePrice:      p.OneTimePrice,
+		MonthlyPrice:      p.MonthlyPrice,
+		HostingFee:        p.HostingFee,
+		MinimumTermMonths: p.MinimumTermMonths,
+	}
+	fields := []struct {
+		name     string
+		override string
+		dst      *money.Money
+	}{
+		{"setupFee", o.SetupFee, &pricing.SetupFee},
+		{"oneTimePrice", o.OneTimePrice, &pricing.OneTimePrice},
+		{"monthlyPrice", o.MonthlyPrice, &pricing.MonthlyPrice},
+		{"hostingFee", o.HostingFee, &pricing.HostingFee},
+	}
+	for _, f := range fields {
+		if f.override == "" {
+			continue
+		}
+		m, err := money.Parse(f.override, f.dst.Currency())
+		if err != nil {
+			return pricing, fmt.Errorf("%s: %w", f.name, err)
+		}
+		*f.dst = m
+	}
+	return pricing, nil
+}
+
+// AddonCharge prices an addon. Per unit addons are a one-time charge of
+// price × quantity; other addons ignore quantity.
+func AddonCharge(a query.AgencyAddon, quantity int32) (Charge, error) {
+	switch PricingType(a.PricingType) {
+	case PricingTypePerUnit:
+		if quantity < 1 {
+			quantity = 1
+		}
+		return Charge{Quantity: quantity, UnitPrice: a.Price, Amount: a.Price.MulInt(int64(quantity)), Frequency: FrequencyOneTime}, nil
+	case PricingTypeMonthly:
+		return Charge{Quantity: 1, UnitPrice: a.Price, Amount: a.Price, Frequency: FrequencyMonthly}, nil
+	case PricingTypeOneTime:
+		return Charge{Quantity: 1, UnitPrice: a.Price, Amount: a.Price, Frequency: FrequencyOneTime}, nil
+	default:
+		return Charge{}, fmt.Errorf("addon %s has unknown pricing type %q", a.Slug, a.PricingType)
+	}
+}
+
+// AddonAvailable reports whether an addon may be added to the package with
+// the given slug. An empty availability list means every package.
+func AddonAvailable(a query.AgencyAddon, packageSlug string) bool {
+	var slugs []string
+	if err := json.Unmarshal(a.AvailablePackages, &slugs); err != nil || len(slugs) == 0 {
+		return true
+	}
+	for _, s := range slugs {
+		if s == packageSlug {
+			return true
+		}
+	}
+	return false
+}
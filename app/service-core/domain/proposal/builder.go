@@ -1 +1,230 @@
 package proposal
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const slugAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// newSlug returns a random 12 character public URL slug
+func newSlug() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = slugAlphabet[int(b[i])%len(slugAlphabet)]
+	}
+	return string(b), nil
+}
+
+// newParams returns insert params for a blank draft proposal with the
+// default section content
+func newParams(agencyID, userID uuid.UUID, number, slug string) query.InsertProposalParams {
+	return query.InsertProposalParams{
+		AgencyID:               agencyID,
+		ProposalNumber:         number,
+		Slug:                   slug,
+		Status:                 string(StatusDraft),
+		Title:                  defaultTitle,
+		PerformanceData:        json.RawMessage(`{}`),
+		CurrentIssues:          json.RawMessage(`[]`),
+		ComplianceIssues:       json.RawMessage(`[]`),
+		RoiAnalysis:            json.RawMessage(`{}`),
+		PerformanceStandards:   mustJSON(defaultPerformanceStandards),
+		ProposedPages:          json.RawMessage(`[]`),
+		Timeline:               mustJSON(defaultTimeline),
+		SelectedAddons:         json.RawMessage(`[]`),
+		NextSteps:              mustJSON(defaultNextSteps),
+		ConsultationPainPoints: json.RawMessage(`{}`),
+		ConsultationGoals:      json.RawMessage(`{}`),
+		ConsultationChallenges: json.RawMessage(`[]`),
+		CreatedBy:              uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+	}
+}
+
+// applyConsultation pre-fills client details and caches consultation
+// insights on the insert params
+func applyConsultation(p *query.InsertProposalParams, c query.Consultation) {
+	p.ConsultationID = uuid.NullUUID{UUID: c.ID, Valid: true}
+	p.ClientID = c.ClientID
+	p.ClientBusinessName = c.BusinessName.String
+	p.ClientContactName = c.ContactPerson.String
+	p.ClientEmail = c.Email.String
+	p.ClientPhone = c.Phone.String
+	p.ClientWebsite = c.Website.String
+
+	challenges := rawOr(c.PrimaryChallenges.RawMessage, c.PrimaryChallenges.Valid, `[]`)
+	goals := rawOr(c.PrimaryGoals.RawMessage, c.PrimaryGoals.Valid, `[]`)
+	p.ConsultationChallenges = challenges
+	p.ConsultationPainPoints = mustJSON(map[string]any{
+		"primary_challenges": challenges,
+		"urgency_level":      c.UrgencyLevel.String,
+	})
+	p.ConsultationGoals = mustJSON(map[string]any{
+		"primary_goals":   goals,
+		"conversion_goal": c.ConversionGoal.String,
+		"budget_range":    c.BudgetRange.String,
+	})
+	if c.PerformanceData.Valid && len(c.PerformanceData.RawMessage) > 0 {
+		p.PerformanceData = c.PerformanceData.RawMessage
+	}
+}
+
+// duplicateParams returns insert params for a draft copy of an existing proposal
+func duplicateParams(src query.Proposal, userID uuid.UUID, number, slug string) query.InsertProposalParams {
+	return query.InsertProposalParams{
+		AgencyID:               src.AgencyID,
+		ConsultationID:         src.ConsultationID,
+		ClientID:               src.ClientID,
+		ProposalNumber:         number,
+		Slug:                   slug,
+		Status:                 string(StatusDraft),
+		ClientBusinessName:     src.ClientBusinessName,
+		ClientContactName:      src.ClientContactName,
+		ClientEmail:            src.ClientEmail,
+		ClientPhone:            src.ClientPhone,
+		ClientWebsite:          src.ClientWebsite,
+		Title:                  src.Title + " (Copy)",
+		CoverImage:             src.CoverImage,
+		PerformanceData:        src.PerformanceData,
+		OpportunityContent:     src.OpportunityContent,
+		CurrentIssues:          src.CurrentIssues,
+		ComplianceIssues:       src.ComplianceIssues,
+		RoiAnalysis:            src.RoiAnalysis,
+		PerformanceStandards:   src.PerformanceStandards,
+		LocalAdvantageContent:  src.LocalAdvantageContent,
+		ProposedPages:          src.ProposedPages,
+		Timeline:               src.Timeline,
+		ClosingContent:         src.ClosingContent,
+		SelectedPackageID:      src.SelectedPackageID,
+		SelectedAddons:         src.SelectedAddons,
+		CustomPricing:          src.CustomPricing,
+		ExecutiveSummary:       src.ExecutiveSummary,
+		NextSteps:              src.NextSteps,
+		ConsultationPainPoints: src.ConsultationPainPoints,
+		ConsultationGoals:      src.ConsultationGoals,
+		ConsultationChallenges: src.ConsultationChallenges,
+		CreatedBy:              uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+	}
+}
+
+// applyUpdate copies the non-nil fields of an update request onto the
+// current proposal and returns the update params
+func applyUpdate(p query.Proposal, req UpdateRequest) (query.UpdateProposalParams, error) {
+	params := query.UpdateProposalParams{
+		ID:                    p.ID,
+		AgencyID:              p.AgencyID,
+		ClientBusinessName:    p.ClientBusinessName,
+		ClientContactName:     p.ClientContactName,
+		ClientEmail:           p.ClientEmail,
+		ClientPhone:           p.ClientPhone,
+		ClientWebsite:         p.ClientWebsite,
+		Title:                 p.Title,
+		CoverImage:            p.CoverImage,
+		PerformanceData:       p.PerformanceData,
+		CurrentIssues:         p.CurrentIssues,
+		ComplianceIssues:      p.ComplianceIssues,
+		PerformanceStandards:  p.PerformanceStandards,
+		LocalAdvantageContent: p.LocalAdvantageContent,
+		ClosingContent:        p.ClosingContent,
+		ExecutiveSummary:      p.ExecutiveSummary,
+		SelectedPackageID:     p.SelectedPackageID,
+		SelectedAddons:        p.SelectedAddons,
+		CustomPricing:         p.CustomPricing,
+		ValidUntil:            p.ValidUntil,
+	}
+	setString(&params.ClientBusinessName, req.ClientBusinessName)
+	setString(&params.ClientContactName, req.ClientContactName)
+	setString(&params.ClientEmail, req.ClientEmail)
+	setString(&params.ClientPhone, req.ClientPhone)
+	setString(&params.ClientWebsite, req.ClientWebsite)
+	setString(&params.Title, req.Title)
+	setString(&params.LocalAdvantageContent, req.LocalAdvantageContent)
+	setString(&params.ClosingContent, req.ClosingContent)
+	setString(&params.ExecutiveSummary, req.ExecutiveSummary)
+	if req.CoverImage != nil {
+		params.CoverImage.String = *req.CoverImage
+		params.CoverImage.Valid = *req.CoverImage != ""
+	}
+	if len(req.PerformanceData) > 0 {
+		params.PerformanceData = req.PerformanceData
+	}
+	if len(req.CurrentIssues) > 0 {
+		params.CurrentIssues = req.CurrentIssues
+	}
+	if len(req.ComplianceIssues) > 0 {
+		params.ComplianceIssues = req.ComplianceIssues
+	}
+	if len(req.PerformanceStandards) > 0 {
+		params.PerformanceStandards = req.PerformanceStandards
+	}
+	if req.SelectedPackageID != nil {
+		params.SelectedPackageID = uuid.NullUUID{UUID: *req.SelectedPackageID, Valid: *req.SelectedPackageID != uuid.Nil}
+	}
+	if req.SelectedAddons != nil {
+		b, err := json.Marshal(req.SelectedAddons)
+		if err != nil {
+			return params, err
+		}
+		params.SelectedAddons = b
+	}
+	if req.CustomPricing != nil {
+		b, err := json.Marshal(req.CustomPricing)
+		if err != nil {
+			return params, err
+		}
+		params.CustomPricing.RawMessage = b
+		params.CustomPricing.Valid = true
+	}
+	if req.ValidUntil != nil {
+		params.ValidUntil.Time = *req.ValidUntil
+		params.ValidUntil.Valid = !req.ValidUntil.IsZero()
+	}
+	return params, nil
+}
+
+// statusParams returns the update params for moving a proposal to a new
+// status, stamping the matching timestamp and client response field
+func statusParams(p query.Proposal, req TransitionRequest, now time.Time) query.UpdateProposalStatusParams {
+	params := query.UpdateProposalStatusParams{
+		ID:                   p.ID,
+		FromStatus:           p.Status,
+		Status:               string(req.Status),
+		ClientComments:       p.ClientComments,
+		DeclineReason:        p.DeclineReason,
+		RevisionRequestNotes: p.RevisionRequestNotes,
+	}
+	switch req.Status {
+	case StatusSent:
+		params.SentAt.Time, params.SentAt.Valid = now, true
+	case StatusAccepted:
+		params.AcceptedAt.Time, params.AcceptedAt.Valid = now, true
+		params.ClientComments = req.Notes
+	case StatusDeclined:
+		params.DeclinedAt.Time, params.DeclinedAt.Valid = now, true
+		params.DeclineReason = req.Notes
+	case StatusRevisionRequested:
+		params.RevisionRequestedAt.Time, params.RevisionRequestedAt.Valid = now, true
+		params.RevisionRequestNotes = req.Notes
+	}
+	return params
+}
+
+func setString(dst *string, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func rawOr(raw json.RawMessage, valid bool, fallback string) json.RawMessage {
+	if !valid || len(raw) == 0 {
+		return json.RawMessage(fallback)
+	}
+	return raw
+}
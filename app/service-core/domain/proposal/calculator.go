@@ -1 +1,308 @@
 package proposal
+
+import (
+	"app/pkg"
+	"app/pkg/money"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	agencypkg "service-core/domain/pkg"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// SelectedAddon is an addon chosen on a proposal. Quantity only applies to
+// per unit addons.
+type SelectedAddon struct {
+	ID       uuid.UUID `json:"id"`
+	Quantity int32     `json:"quantity"`
+}
+
+// PriceLine is a single itemised charge in a pricing breakdown
+type PriceLine struct {
+	Description string              `json:"description"`
+	Category    string              `json:"category"`
+	Frequency   agencypkg.Frequency `json:"frequency"`
+	Quantity    int32               `json:"quantity"`
+	UnitPrice   money.Money         `json:"unitPrice"`
+	Amount      money.Money         `json:"amount"`
+	PackageID   *uuid.UUID          `json:"packageId,omitempty"`
+	AddonID     *uuid.UUID          `json:"addonId,omitempty"`
+}
+
+// PriceTotals are the totals for one billing frequency
+type PriceTotals struct {
+	Subtotal money.Money `json:"subtotal"`
+	Discount money.Money `json:"discount"`
+	Net      money.Money `json:"net"`
+	GST      money.Money `json:"gst"`
+	Total    money.Money `json:"total"`
+}
+
+// PricingBreakdown is the fully itemised price of a proposal. Contracts,
+// invoices and PDFs read their numbers from here so they always agree.
+type PricingBreakdown struct {
+	PackageID         *uuid.UUID    `json:"packageId,omitempty"`
+	PackageName       string        `json:"packageName"`
+	PricingModel      string        `json:"pricingModel"`
+	LineItems         []PriceLine   `json:"lineItems"`
+	DiscountPercent   money.Decimal `json:"discountPercent"`
+	DiscountNote      string        `json:"discountNote"`
+	GSTRegistered     bool          `json:"gstRegistered"`
+	GSTRate           money.Decimal `json:"gstRate"`
+	OneTime           PriceTotals   `json:"oneTime"`
+	Monthly           PriceTotals   `json:"monthly"`
+	MinimumTermMonths int32         `json:"minimumTermMonths"`
+	FirstYearCost     money.Money   `json:"firstYearCost"`
+	MinimumTermValue  money.Money   `json:"minimumTermValue"`
+}
+
+// parseSelectedAddons reads the selected_addons column, which holds either
+// plain addon IDs or objects with an ID and quantity
+func parseSelectedAddons(raw json.RawMessage) ([]SelectedAddon, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+	selected := make([]SelectedAddon, 0, len(items))
+	for _, item := range items {
+		var id uuid.UUID
+		if err := json.Unmarshal(item, &id); err == nil {
+			selected = append(selected, SelectedAddon{ID: id, Quantity: 1})
+			continue
+		}
+		var s SelectedAddon
+		if err := json.Unmarshal(item, &s); err != nil {
+			return nil, err
+		}
+		if s.Quantity < 1 {
+			s.Quantity = 1
+		}
+		selected = append(selected, s)
+	}
+	return selected, nil
+}
+
+// Calculate prices a package, its selected addons and any custom pricing.
+// The discount applies to one-time charges only; GST applies to both
+// one-time and monthly charges when the agency is GST registered.
+func Calculate(
+	pack *query.AgencyPackage,
+	addons []query.AgencyAddon,
+	selected []SelectedAddon,
+	custom *CustomPricing,
+	profile query.AgencyProfile,
+	mode money.Rounding,
+) (*PricingBreakdown, error) {
+	var overrides agencypkg.Overrides
+	if custom != nil {
+		if err := custom.validateDiscount(); err != nil {
+			return nil, err
+		}
+		overrides = agencypkg.Overrides{
+			SetupFee:     custom.SetupFee,
+			MonthlyPrice: custom.MonthlyPrice,
+			OneTimePrice: custom.OneTimePrice,
+			HostingFee:   custom.HostingFee,
+		}
+	}
+	base := query.AgencyPackage{}
+	if pack != nil {
+		base = *pack
+	}
+	pricing, err := agencypkg.Resolve(base, overrides)
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid package pricing", Err: err}
+	}
+	prices := []money.Money{pricing.SetupFee, pricing.OneTimePrice, pricing.MonthlyPrice, pricing.HostingFee}
+	for _, a := range addons {
+		prices = append(prices, a.Price)
+	}
+	if err := money.SameCurrency(prices...); err != nil {
+		return nil, pkg.BadRequestError{Message: "Package and addon prices must share a currency", Err: err}
+	}
+
+	b := &PricingBreakdown{
+		PackageName:       base.Name,
+		PricingModel:      base.PricingModel,
+		LineItems:         []PriceLine{},
+		GSTRegistered:     profile.GstRegistered,
+		GSTRate:           profile.GstRate,
+		MinimumTermMonths: pricing.MinimumTermMonths,
+	}
+	var packageID *uuid.UUID
+	if pack != nil {
+		packageID = &pack.ID
+		b.PackageID = packageID
+	}
+
+	var oneTime, monthly money.Money
+	add := func(description, category string, c agencypkg.Charge, addonID *uuid.UUID) {
+		if c.Amount.IsZero() {
+			return
+		}
+		line := PriceLine{
+			Description: description,
+			Category:    category,
+			Frequency:   c.Frequency,
+			Quantity:    c.Quantity,
+			UnitPrice:   c.UnitPrice,
+			Amount:      c.Amount,
+			AddonID:     addonID,
+		}
+		if addonID == nil {
+			line.PackageID = packageID
+		}
+		b.LineItems = append(b.LineItems, line)
+		if c.Frequency == agencypkg.FrequencyMonthly {
+			monthly = monthly.Add(c.Amount)
+		} else {
+			oneTime = oneTime.Add(c.Amount)
+		}
+	}
+	single := func(amount money.Money, f agencypkg.Frequency) agencypkg.Charge {
+		return agencypkg.Charge{Quantity: 1, UnitPrice: amount, Amount: amount, Frequency: f}
+	}
+	label := func(s string) string {
+		if pack == nil {
+			return s
+		}
+		return pack.Name + " - " + s
+	}
+	add(label("Setup & Development"), "setup", single(pricing.SetupFee, agencypkg.FrequencyOneTime), nil)
+	add(label("Website Development"), "development", single(pricing.OneTimePrice, agencypkg.FrequencyOneTime), nil)
+	add(label("Monthly Subscription"), "subscription", single(pricing.MonthlyPrice, agencypkg.FrequencyMonthly), nil)
+	add(label("Hosting & Maintenance"), "hosting", single(pricing.HostingFee, agencypkg.FrequencyMonthly), nil)
+
+	byID := make(map[uuid.UUID]query.AgencyAddon, len(addons))
+	for _, a := range addons {
+		byID[a.ID] = a
+	}
+	var unavailable pkg.ValidationErrors
+	for _, s := range selected {
+		a, ok := byID[s.ID]
+		if !ok {
+			// Addons deleted since the proposal was priced are dropped
+			continue
+		}
+		if !agencypkg.AddonAvailable(a, base.Slug) {
+			unavailable = append(unavailable, pkg.ValidationError{
+				Field:   "selectedAddons",
+				Tag:     "available",
+				Message: fmt.Sprintf("%s is not available with the %s package", a.Name, base.Name),
+			})
+			continue
+		}
+		c, err := agencypkg.AddonCharge(a, s.Quantity)
+		if err != nil {
+			return nil, pkg.BadRequestError{Message: "Invalid addon pricing", Err: err}
+		}
+		description := a.Name
+		if agencypkg.PricingType(a.PricingType) == agencypkg.PricingTypePerUnit {
+			unit := a.UnitLabel.String
+			if unit == "" {
+				unit = "unit"
+			}
+			description = fmt.Sprintf("%s (%d × %s)", a.Name, c.Quantity, unit)
+		}
+		id := a.ID
+		add(description, "addon", c, &id)
+	}
+	if len(unavailable) > 0 {
+		return nil, unavailable
+	}
+
+	var gstRate, discountRate money.Decimal
+	if profile.GstRegistered {
+		gstRate = profile.GstRate
+	}
+	if custom != nil {
+		b.DiscountPercent = custom.DiscountPercent
+		b.DiscountNote = custom.DiscountNote
+		discountRate = custom.DiscountPercent
+	}
+
+	oneTimeTotal := totals(&b.OneTime, oneTime, discountRate, gstRate, mode)
+	monthlyTotal := totals(&b.Monthly, monthly, money.Decimal{}, gstRate, mode)
+	b.FirstYearCost = oneTimeTotal.Add(monthlyTotal.MulInt(12))
+	b.MinimumTermValue = oneTimeTotal.Add(monthlyTotal.MulInt(int64(pricing.MinimumTermMonths)))
+	return b, nil
+}
+
+// totals fills in the totals for one billing frequency and returns the
+// GST inclusive total
+func totals(t *PriceTotals, subtotal money.Money, discountRate, gstRate money.Decimal, mode money.Rounding) money.Money {
+	t.Subtotal = subtotal
+	t.Discount = subtotal.Percent(discountRate, mode)
+	t.Net = subtotal.Sub(t.Discount)
+	t.GST = t.Net.Percent(gstRate, mode)
+	t.Total = t.Net.Add(t.GST)
+	return t.Total
+}
+
+// CalculatePricing returns the pricing breakdown for a proposal
+func (s *Service) CalculatePricing(ctx context.Context, agencyID, id uuid.UUID) (*PricingBreakdown, error) {
+	p, err := s.GetProposal(ctx, agencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.Price(ctx, p)
+}
+
+// Price returns the pricing breakdown for an already loaded proposal
+func (s *Service) Price(ctx context.Context, p *query.Proposal) (*PricingBreakdown, error) {
+	profile, err := s.store.SelectAgencyProfile(ctx, p.AgencyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Agency profile not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting agency profile", Err: err}
+	}
+
+	var pack *query.AgencyPackage
+	if p.SelectedPackageID.Valid {
+		sp, err := s.store.SelectAgencyPackage(ctx, p.SelectedPackageID.UUID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.InternalError{Message: "Error selecting package", Err: err}
+		}
+		if err == nil && sp.AgencyID == p.AgencyID {
+			pack = &sp
+		}
+	}
+
+	selected, err := parseSelectedAddons(p.SelectedAddons)
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid selected addons", Err: err}
+	}
+	var addons []query.AgencyAddon
+	if len(selected) > 0 {
+		ids := make([]uuid.UUID, len(selected))
+		for i, a := range selected {
+			ids[i] = a.ID
+		}
+		addons, err = s.store.SelectAgencyAddonsByIDs(ctx, query.SelectAgencyAddonsByIDsParams{
+			AgencyID: p.AgencyID,
+			Ids:      ids,
+		})
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error selecting addons", Err: err}
+		}
+	}
+
+	var custom *CustomPricing
+	if p.CustomPricing.Valid && len(p.CustomPricing.RawMessage) > 0 && string(p.CustomPricing.RawMessage) != "null" {
+		custom = &CustomPricing{}
+		if err := json.Unmarshal(p.CustomPricing.RawMessage, custom); err != nil {
+			return nil, pkg.BadRequestError{Message: "Invalid custom pricing", Err: err}
+		}
+	}
+
+	return Calculate(pack, addons, selected, custom, profile, s.cfg.Rounding)
+}
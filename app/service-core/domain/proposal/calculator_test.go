@@ -0,0 +1,116 @@
+package proposal_test
+
+import (
+	"app/pkg"
+	"app/pkg/money"
+	"encoding/json"
+	"errors"
+	"service-core/domain/proposal"
+	"service-core/storage/query"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCalculate(t *testing.T) {
+	t.Parallel()
+	pack := &query.AgencyPackage{
+		ID:                uuid.New(),
+		Name:              "Growth",
+		Slug:              "growth",
+		PricingModel:      "hybrid",
+		SetupFee:          money.MustParse("1000.00", money.AUD),
+		MonthlyPrice:      money.MustParse("99.00", money.AUD),
+		MinimumTermMonths: 12,
+	}
+	pages := query.AgencyAddon{
+		ID:                uuid.New(),
+		Name:              "Extra Page",
+		Slug:              "extra-page",
+		Price:             money.MustParse("150.00", money.AUD),
+		PricingType:       "per_unit",
+		AvailablePackages: json.RawMessage(`[]`),
+	}
+	profile := query.AgencyProfile{GstRegistered: true, GstRate: money.MustParseDecimal("10.00")}
+	custom := &proposal.CustomPricing{DiscountPercent: money.MustParseDecimal("10")}
+
+	b, err := proposal.Calculate(pack, []query.AgencyAddon{pages},
+		[]proposal.SelectedAddon{{ID: pages.ID, Quantity: 2}}, custom, profile, money.HalfEven)
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if len(b.LineItems) != 3 {
+		t.Fatalf("len(LineItems) = %d, want 3", len(b.LineItems))
+	}
+	checks := []struct {
+		name, got, want string
+	}{
+		{"oneTime.subtotal", b.OneTime.Subtotal.String(), "1300.00"},
+		{"oneTime.discount", b.OneTime.Discount.String(), "130.00"},
+		{"oneTime.gst", b.OneTime.GST.String(), "117.00"},
+		{"oneTime.total", b.OneTime.Total.String(), "1287.00"},
+		{"monthly.discount", b.Monthly.Discount.String(), "0.00"},
+		{"monthly.total", b.Monthly.Total.String(), "108.90"},
+		{"firstYearCost", b.FirstYearCost.String(), "2593.80"},
+		{"minimumTermValue", b.MinimumTermValue.String(), "2593.80"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %s, want %s", c.name, c.got, c.want)
+		}
+	}
+
+	pages.AvailablePackages = json.RawMessage(`["starter"]`)
+	_, err = proposal.Calculate(pack, []query.AgencyAddon{pages},
+		[]proposal.SelectedAddon{{ID: pages.ID, Quantity: 1}}, nil, profile, money.HalfEven)
+	var verrs pkg.ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("Calculate() error = %v, want ValidationErrors", err)
+	}
+}
+
+func TestCalculateDiscountPercent(t *testing.T) {
+	t.Parallel()
+	pack := &query.AgencyPackage{
+		Name:         "Starter",
+		Slug:         "starter",
+		PricingModel: "one_time",
+		SetupFee:     money.MustParse("1000.00", money.AUD),
+	}
+	tests := []struct {
+		name    string
+		json    string
+		want    string
+		wantErr bool
+	}{
+		{name: "string", json: `{"discountPercent":"12.5"}`, want: "125.00"},
+		{name: "number", json: `{"discountPercent":10}`, want: "100.00"},
+		{name: "zero", json: `{}`, want: "0.00"},
+		{name: "full", json: `{"discountPercent":"100"}`, want: "1000.00"},
+		{name: "negative", json: `{"discountPercent":"-5"}`, wantErr: true},
+		{name: "over 100", json: `{"discountPercent":"100.01"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var custom proposal.CustomPricing
+			if err := json.Unmarshal([]byte(tt.json), &custom); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			b, err := proposal.Calculate(pack, nil, nil, &custom, query.AgencyProfile{}, money.HalfEven)
+			if tt.wantErr {
+				var bad pkg.BadRequestError
+				if !errors.As(err, &bad) {
+					t.Fatalf("Calculate() error = %v, want BadRequestError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			if got := b.OneTime.Discount.String(); got != tt.want {
+				t.Errorf("OneTime.Discount = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
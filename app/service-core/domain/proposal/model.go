@@ -1 +1,160 @@
 package proposal
+
+import (
+	"app/pkg"
+	"app/pkg/money"
+	"encoding/json"
+	"errors"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is the lifecycle state of a proposal
+type Status string
+
+const (
+	StatusDraft             Status = "draft"
+	StatusReady             Status = "ready"
+	StatusSent              Status = "sent"
+	StatusViewed            Status = "viewed"
+	StatusAccepted          Status = "accepted"
+	StatusDeclined          Status = "declined"
+	StatusRevisionRequested Status = "revision_requested"
+	StatusExpired           Status = "expired"
+)
+
+// transitions lists the statuses a proposal may move to from each status.
+// Viewed is only reached by recording a view, never by an explicit transition.
+var transitions = map[Status][]Status{
+	StatusDraft:             {StatusReady, StatusSent},
+	StatusReady:             {StatusDraft, StatusSent},
+	StatusSent:              {StatusAccepted, StatusDeclined, StatusRevisionRequested, StatusExpired},
+	StatusViewed:            {StatusAccepted, StatusDeclined, StatusRevisionRequested, StatusExpired},
+	StatusRevisionRequested: {StatusDraft, StatusSent},
+}
+
+// CanTransition reports whether a proposal may move from one status to another
+func CanTransition(from, to Status) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEditable reports whether a proposal's content may still be changed.
+// Once the client has responded or the proposal has expired it is frozen.
+func (s Status) IsEditable() bool {
+	return s != StatusAccepted && s != StatusDeclined && s != StatusExpired
+}
+
+// Section identifies a proposal content section that can be updated on its own
+type Section string
+
+const (
+	SectionOpportunity   Section = "opportunity"
+	SectionROIAnalysis   Section = "roi_analysis"
+	SectionProposedPages Section = "proposed_pages"
+	SectionTimeline      Section = "timeline"
+	SectionNextSteps     Section = "next_steps"
+)
+
+// ROIAnalysis is the content of the ROI analysis section
+type ROIAnalysis struct {
+	CurrentVisitors     *float64 `json:"currentVisitors,omitempty"`
+	ProjectedVisitors   *float64 `json:"projectedVisitors,omitempty"`
+	ConversionRate      *float64 `json:"conversionRate,omitempty"`
+	ProjectedLeads      *float64 `json:"projectedLeads,omitempty"`
+	AverageProjectValue *float64 `json:"averageProjectValue,omitempty"`
+	ProjectedRevenue    *float64 `json:"projectedRevenue,omitempty"`
+}
+
+// ProposedPage is a single page in the proposed site architecture
+type ProposedPage struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Features    []string `json:"features,omitempty"`
+}
+
+// TimelinePhase is a single phase of the implementation timeline
+type TimelinePhase struct {
+	Week        string `json:"week"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// NextStep is a single item in the next steps checklist
+type NextStep struct {
+	Text      string `json:"text"`
+	Completed bool   `json:"completed"`
+}
+
+// CustomPricing holds price overrides for the selected package
+type CustomPricing struct {
+	SetupFee        string        `json:"setupFee,omitempty"`
+	MonthlyPrice    string        `json:"monthlyPrice,omitempty"`
+	OneTimePrice    string        `json:"oneTimePrice,omitempty"`
+	HostingFee      string        `json:"hostingFee,omitempty"`
+	DiscountPercent money.Decimal `json:"discountPercent,omitzero"`
+	DiscountNote    string        `json:"discountNote,omitempty"`
+}
+
+var maxDiscountPercent = money.NewDecimal(100)
+
+// validateDiscount checks the discount is a percentage from 0 to 100
+func (c *CustomPricing) validateDiscount() error {
+	if c.DiscountPercent.Cmp(money.Decimal{}) < 0 || c.DiscountPercent.Cmp(maxDiscountPercent) > 0 {
+		return pkg.BadRequestError{
+			Message: "Discount percent must be between 0 and 100",
+			Err:     errors.New("discount percent out of range"),
+		}
+	}
+	return nil
+}
+
+// CreateRequest is the input for creating a proposal
+type CreateRequest struct {
+	ConsultationID    *uuid.UUID `json:"consultationId"`
+	SelectedPackageID *uuid.UUID `json:"selectedPackageId"`
+	Title             string     `json:"title"`
+}
+
+// UpdateRequest is the input for editing a proposal's client details, cover,
+// supporting content and package selection. Nil fields are left unchanged.
+type UpdateRequest struct {
+	ClientBusinessName    *string         `json:"clientBusinessName"`
+	ClientContactName     *string         `json:"clientContactName"`
+	ClientEmail           *string         `json:"clientEmail"`
+	ClientPhone           *string         `json:"clientPhone"`
+	ClientWebsite         *string         `json:"clientWebsite"`
+	Title                 *string         `json:"title"`
+	CoverImage            *string         `json:"coverImage"`
+	PerformanceData       json.RawMessage `json:"performanceData"`
+	CurrentIssues         json.RawMessage `json:"currentIssues"`
+	ComplianceIssues      json.RawMessage `json:"complianceIssues"`
+	PerformanceStandards  json.RawMessage `json:"performanceStandards"`
+	LocalAdvantageContent *string         `json:"localAdvantageContent"`
+	ClosingContent        *string         `json:"closingContent"`
+	ExecutiveSummary      *string         `json:"executiveSummary"`
+	SelectedPackageID     *uuid.UUID      `json:"selectedPackageId"`
+	SelectedAddons        []uuid.UUID     `json:"selectedAddons"`
+	CustomPricing         *CustomPricing  `json:"customPricing"`
+	ValidUntil            *time.Time      `json:"validUntil"`
+}
+
+// TransitionRequest is the input for moving a proposal to a new status.
+// Notes is stored as client comments on acceptance, the decline reason on
+// decline, and the revision notes on a revision request.
+type TransitionRequest struct {
+	Status Status `json:"status"`
+	Notes  string `json:"notes"`
+}
+
+// ListResponse is a page of proposals for an agency
+type ListResponse struct {
+	Count     int64            `json:"count"`
+	Proposals []query.Proposal `json:"proposals"`
+}
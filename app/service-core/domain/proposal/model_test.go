@@ -0,0 +1,46 @@
+package proposal_test
+
+import (
+	"service-core/domain/proposal"
+	"testing"
+)
+
+func TestCanTransition(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		from proposal.Status
+		to   proposal.Status
+		want bool
+	}{
+		{proposal.StatusDraft, proposal.StatusReady, true},
+		{proposal.StatusDraft, proposal.StatusSent, true},
+		{proposal.StatusReady, proposal.StatusDraft, true},
+		{proposal.StatusSent, proposal.StatusAccepted, true},
+		{proposal.StatusViewed, proposal.StatusDeclined, true},
+		{proposal.StatusViewed, proposal.StatusRevisionRequested, true},
+		{proposal.StatusRevisionRequested, proposal.StatusSent, true},
+		{proposal.StatusDraft, proposal.StatusAccepted, false},
+		{proposal.StatusSent, proposal.StatusViewed, false},
+		{proposal.StatusAccepted, proposal.StatusDeclined, false},
+		{proposal.StatusDeclined, proposal.StatusDraft, false},
+	}
+	for _, tt := range tests {
+		if got := proposal.CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestStatusIsEditable(t *testing.T) {
+	t.Parallel()
+	for _, s := range []proposal.Status{proposal.StatusDraft, proposal.StatusSent, proposal.StatusRevisionRequested} {
+		if !s.IsEditable() {
+			t.Errorf("expected %s to be editable", s)
+		}
+	}
+	for _, s := range []proposal.Status{proposal.StatusAccepted, proposal.StatusDeclined, proposal.StatusExpired} {
+		if s.IsEditable() {
+			t.Errorf("expected %s not to be editable", s)
+		}
+	}
+}
@@ -1 +1,438 @@
 package proposal
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"service-core/config"
+	"service-core/domain/activity"
+	"service-core/domain/numbering"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// store defines the database interface for proposal operations
+type store interface {
+	CountProposals(ctx context.Context, arg query.CountProposalsParams) (int64, error)
+	SelectProposals(ctx context.Context, arg query.SelectProposalsParams) ([]query.Proposal, error)
+	SelectProposal(ctx context.Context, id uuid.UUID) (query.Proposal, error)
+	SelectProposalBySlug(ctx context.Context, slug string) (query.Proposal, error)
+	InsertProposal(ctx context.Context, arg query.InsertProposalParams) (query.Proposal, error)
+	UpdateProposal(ctx context.Context, arg query.UpdateProposalParams) (query.Proposal, error)
+	UpdateProposalOpportunity(ctx context.Context, arg query.UpdateProposalOpportunityParams) (query.Proposal, error)
+	UpdateProposalRoiAnalysis(ctx context.Context, arg query.UpdateProposalRoiAnalysisParams) (query.Proposal, error)
+	UpdateProposalProposedPages(ctx context.Context, arg query.UpdateProposalProposedPagesParams) (query.Proposal, error)
+	UpdateProposalTimeline(ctx context.Context, arg query.UpdateProposalTimelineParams) (query.Proposal, error)
+	UpdateProposalNextSteps(ctx context.Context, arg query.UpdateProposalNextStepsParams) (query.Proposal, error)
+	UpdateProposalStatus(ctx context.Context, arg query.UpdateProposalStatusParams) (query.Proposal, error)
+	RecordProposalView(ctx context.Context, id uuid.UUID) (query.Proposal, error)
+	DeleteProposal(ctx context.Context, id uuid.UUID) error
+
+	SelectAgencyProfile(ctx context.Context, agencyID uuid.UUID) (query.AgencyProfile, error)
+	SelectAgencyPackage(ctx context.Context, id uuid.UUID) (query.AgencyPackage, error)
+	SelectAgencyAddonsByIDs(ctx context.Context, arg query.SelectAgencyAddonsByIDsParams) ([]query.AgencyAddon, error)
+	SelectConsultation(ctx context.Context, id uuid.UUID) (query.Consultation, error)
+	InsertActivityLog(ctx context.Context, arg query.InsertActivityLogParams) error
+}
+
+// numberer allocates agency document numbers
+type numberer interface {
+	Allocate(ctx context.Context, agencyID uuid.UUID, docType numbering.DocumentType) (string, error)
+}
+
+// Service handles agency proposal operations
+type Service struct {
+	cfg      *config.Config
+	store    store
+	numberer numberer
+}
+
+// NewService creates a new proposal service
+func NewService(cfg *config.Config, store store, numberer numberer) *Service {
+	return &Service{
+		cfg:      cfg,
+		store:    store,
+		numberer: numberer,
+	}
+}
+
+// ListProposals returns a page of an agency's proposals, optionally filtered by status
+func (s *Service) ListProposals(
+	ctx context.Context,
+	agencyID uuid.UUID,
+	status string,
+	page int32,
+	limit int32,
+) (*ListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+	count, err := s.store.CountProposals(ctx, query.CountProposalsParams{
+		AgencyID: agencyID,
+		Status:   status,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error counting proposals", Err: err}
+	}
+	proposals, err := s.store.SelectProposals(ctx, query.SelectProposalsParams{
+		AgencyID:  agencyID,
+		Status:    status,
+		RowLimit:  limit,
+		RowOffset: (page - 1) * limit,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting proposals", Err: err}
+	}
+	if proposals == nil {
+		proposals = []query.Proposal{}
+	}
+	return &ListResponse{
+		Count:     count,
+		Proposals: proposals,
+	}, nil
+}
+
+// GetProposal returns a single proposal belonging to the agency
+func (s *Service) GetProposal(ctx context.Context, agencyID, id uuid.UUID) (*query.Proposal, error) {
+	p, err := s.store.SelectProposal(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Proposal not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting proposal", Err: err}
+	}
+	// Proposals from other agencies are reported as missing rather than forbidden
+	if p.AgencyID != agencyID {
+		return nil, pkg.NotFoundError{Message: "Proposal not found", Err: fmt.Errorf("proposal %s belongs to another agency", id)}
+	}
+	return &p, nil
+}
+
+// CreateProposal creates a draft proposal, pre-filling client details and
+// consultation insights when a consultation is given
+func (s *Service) CreateProposal(
+	ctx context.Context,
+	user auth.UserAttr,
+	req CreateRequest,
+) (*query.Proposal, error) {
+	if err := auth.Authorize(auth.CreateProposal, user, auth.Resource{OwnerID: user.ID, AgencyID: user.AgencyID}); err != nil {
+		return nil, err
+	}
+	var consultation *query.Consultation
+	if req.ConsultationID != nil {
+		c, err := s.store.SelectConsultation(ctx, *req.ConsultationID)
+		if err != nil || c.AgencyID != user.AgencyID {
+			return nil, pkg.NotFoundError{Message: "Consultation not found", Err: err}
+		}
+		consultation = &c
+	}
+
+	number, slug, err := s.allocate(ctx, user.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+	params := newParams(user.AgencyID, user.ID, number, slug)
+	if consultation != nil {
+		applyConsultation(&params, *consultation)
+	}
+	if req.Title != "" {
+		params.Title = req.Title
+	}
+	if req.SelectedPackageID != nil {
+		params.SelectedPackageID = uuid.NullUUID{UUID: *req.SelectedPackageID, Valid: true}
+	}
+
+	p, err := s.insert(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	s.logActivity(ctx, p, user.ID, "proposal.created", nil, map[string]any{
+		"proposalNumber":    p.ProposalNumber,
+		"consultationId":    req.ConsultationID,
+		"selectedPackageId": req.SelectedPackageID,
+	}, nil)
+	return p, nil
+}
+
+// UpdateProposal edits a proposal's client details, cover, supporting
+// content and package selection
+func (s *Service) UpdateProposal(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	req UpdateRequest,
+) (*query.Proposal, error) {
+	if req.CustomPricing != nil {
+		if err := req.CustomPricing.validateDiscount(); err != nil {
+			return nil, err
+		}
+	}
+	existing, err := s.editable(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+	params, err := applyUpdate(*existing, req)
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid proposal update", Err: err}
+	}
+	err = validate(&schema{
+		title:                params.Title,
+		clientEmail:          params.ClientEmail,
+		performanceData:      params.PerformanceData,
+		currentIssues:        params.CurrentIssues,
+		complianceIssues:     params.ComplianceIssues,
+		performanceStandards: params.PerformanceStandards,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.store.UpdateProposal(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errProposalChanged(err)
+		}
+		return nil, pkg.InternalError{Message: "Error updating proposal", Err: err}
+	}
+	s.logActivity(ctx, &p, user.ID, "proposal.updated", map[string]any{"title": existing.Title}, req, nil)
+	return &p, nil
+}
+
+// UpdateSection replaces the content of a single proposal section
+func (s *Service) UpdateSection(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	section Section,
+	content json.RawMessage,
+) (*query.Proposal, error) {
+	normalised, err := validateSection(section, content)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.editable(ctx, user, id); err != nil {
+		return nil, err
+	}
+
+	var p query.Proposal
+	switch section {
+	case SectionOpportunity:
+		var text string
+		_ = json.Unmarshal(normalised, &text)
+		p, err = s.store.UpdateProposalOpportunity(ctx, query.UpdateProposalOpportunityParams{ID: id, AgencyID: user.AgencyID, OpportunityContent: text})
+	case SectionROIAnalysis:
+		p, err = s.store.UpdateProposalRoiAnalysis(ctx, query.UpdateProposalRoiAnalysisParams{ID: id, AgencyID: user.AgencyID, RoiAnalysis: normalised})
+	case SectionProposedPages:
+		p, err = s.store.UpdateProposalProposedPages(ctx, query.UpdateProposalProposedPagesParams{ID: id, AgencyID: user.AgencyID, ProposedPages: normalised})
+	case SectionTimeline:
+		p, err = s.store.UpdateProposalTimeline(ctx, query.UpdateProposalTimelineParams{ID: id, AgencyID: user.AgencyID, Timeline: normalised})
+	case SectionNextSteps:
+		p, err = s.store.UpdateProposalNextSteps(ctx, query.UpdateProposalNextStepsParams{ID: id, AgencyID: user.AgencyID, NextSteps: normalised})
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errProposalChanged(err)
+		}
+		return nil, pkg.InternalError{Message: "Error updating proposal section", Err: err}
+	}
+	s.logActivity(ctx, &p, user.ID, "proposal.section_updated", nil, nil, map[string]any{"section": section})
+	return &p, nil
+}
+
+// DuplicateProposal creates a draft copy of a proposal with a new number and slug
+func (s *Service) DuplicateProposal(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+) (*query.Proposal, error) {
+	if err := auth.Authorize(auth.CreateProposal, user, auth.Resource{OwnerID: user.ID, AgencyID: user.AgencyID}); err != nil {
+		return nil, err
+	}
+	src, err := s.GetProposal(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	number, slug, err := s.allocate(ctx, user.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.insert(ctx, duplicateParams(*src, user.ID, number, slug))
+	if err != nil {
+		return nil, err
+	}
+	s.logActivity(ctx, p, user.ID, "proposal.duplicated", nil, nil, map[string]any{"sourceProposalId": id})
+	return p, nil
+}
+
+// DeleteProposal removes a proposal
+func (s *Service) DeleteProposal(ctx context.Context, user auth.UserAttr, id uuid.UUID) error {
+	existing, err := s.GetProposal(ctx, user.AgencyID, id)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(auth.RemoveProposal, user, resource(existing)); err != nil {
+		return err
+	}
+	if err := s.store.DeleteProposal(ctx, id); err != nil {
+		return pkg.InternalError{Message: "Error deleting proposal", Err: err}
+	}
+	s.logActivity(ctx, existing, user.ID, "proposal.deleted", map[string]any{
+		"proposalNumber": existing.ProposalNumber,
+		"title":          existing.Title,
+	}, nil, nil)
+	return nil
+}
+
+// TransitionProposal moves a proposal to a new status, stamping the matching
+// timestamp. The update only applies if the status has not changed since it
+// was read, so concurrent transitions cannot both succeed.
+func (s *Service) TransitionProposal(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	req TransitionRequest,
+) (*query.Proposal, error) {
+	existing, err := s.GetProposal(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(auth.EditProposal, user, resource(existing)); err != nil {
+		return nil, err
+	}
+	from := Status(existing.Status)
+	if !CanTransition(from, req.Status) {
+		return nil, pkg.BadRequestError{
+			Message: fmt.Sprintf("Cannot change proposal status from %s to %s", from, req.Status),
+			Err:     errors.New("invalid status transition"),
+		}
+	}
+	if req.Status == StatusRevisionRequested && req.Notes == "" {
+		return nil, pkg.ValidationErrors{{
+			Field:   "notes",
+			Tag:     "required",
+			Message: "Revision notes are required",
+		}}
+	}
+
+	p, err := s.store.UpdateProposalStatus(ctx, statusParams(*existing, req, time.Now()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errProposalChanged(err)
+		}
+		return nil, pkg.InternalError{Message: "Error updating proposal status", Err: err}
+	}
+	s.logActivity(ctx, &p, user.ID, "proposal."+string(req.Status),
+		map[string]any{"status": from},
+		map[string]any{"status": req.Status},
+		nil,
+	)
+	return &p, nil
+}
+
+// RecordView counts a view of a proposal's public page and moves a sent
+// proposal to viewed. It requires no authentication.
+func (s *Service) RecordView(ctx context.Context, slug string) (*query.Proposal, error) {
+	existing, err := s.store.SelectProposalBySlug(ctx, slug)
+	if err != nil {
+		return nil, pkg.NotFoundError{Message: "Proposal not found", Err: err}
+	}
+	p, err := s.store.RecordProposalView(ctx, existing.ID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error recording proposal view", Err: err}
+	}
+	if existing.Status != p.Status {
+		s.logActivity(ctx, &p, uuid.Nil, "proposal.viewed",
+			map[string]any{"status": existing.Status},
+			map[string]any{"status": p.Status},
+			nil,
+		)
+	}
+	return &p, nil
+}
+
+// editable returns the proposal if the user may edit it and its content may
+// still be changed
+func (s *Service) editable(ctx context.Context, user auth.UserAttr, id uuid.UUID) (*query.Proposal, error) {
+	p, err := s.GetProposal(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(auth.EditProposal, user, resource(p)); err != nil {
+		return nil, err
+	}
+	if !Status(p.Status).IsEditable() {
+		return nil, pkg.BadRequestError{
+			Message: fmt.Sprintf("Proposals with status %s can no longer be edited", p.Status),
+			Err:     errors.New("proposal is not editable"),
+		}
+	}
+	return p, nil
+}
+
+// resource returns the attributes a proposal is authorized against
+func resource(p *query.Proposal) auth.Resource {
+	return auth.Resource{OwnerID: p.CreatedBy.UUID, AgencyID: p.AgencyID}
+}
+
+// errProposalChanged reports a conditional update that matched no proposal,
+// because its status changed after it was read
+func errProposalChanged(err error) error {
+	return pkg.BadRequestError{Message: "Proposal status changed, please reload and try again", Err: err}
+}
+
+// allocate reserves the next proposal number for the agency and a public slug
+func (s *Service) allocate(ctx context.Context, agencyID uuid.UUID) (string, string, error) {
+	number, err := s.numberer.Allocate(ctx, agencyID, numbering.Proposal)
+	if err != nil {
+		return "", "", err
+	}
+	slug, err := newSlug()
+	if err != nil {
+		return "", "", pkg.InternalError{Message: "Error generating proposal slug", Err: err}
+	}
+	return number, slug, nil
+}
+
+func (s *Service) insert(ctx context.Context, params query.InsertProposalParams) (*query.Proposal, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating proposal ID", Err: err}
+	}
+	params.ID = id
+	p, err := s.store.InsertProposal(ctx, params)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error inserting proposal", Err: err}
+	}
+	return &p, nil
+}
+
+// logActivity records a change to a proposal in the agency activity log
+func (s *Service) logActivity(
+	ctx context.Context,
+	p *query.Proposal,
+	userID uuid.UUID,
+	action string,
+	oldValues any,
+	newValues any,
+	metadata any,
+) {
+	activity.Log(ctx, s.store, activity.Entry{
+		AgencyID:   p.AgencyID,
+		UserID:     userID,
+		Action:     action,
+		EntityType: "proposal",
+		EntityID:   p.ID,
+		OldValues:  oldValues,
+		NewValues:  newValues,
+		Metadata:   metadata,
+	})
+}
@@ -1 +1,37 @@
 package proposal
+
+import "encoding/json"
+
+const defaultTitle = "Website Proposal"
+
+// defaultTimeline is the implementation timeline a new proposal starts with
+var defaultTimeline = []TimelinePhase{
+	{Week: "1-2", Title: "Discovery & Design", Description: "Kick-off workshop, sitemap and design concepts for review."},
+	{Week: "3-4", Title: "Development", Description: "Build of the approved design with content population."},
+	{Week: "5", Title: "Testing & Launch", Description: "Cross-device testing, performance tuning and go-live."},
+}
+
+// defaultNextSteps is the next steps checklist a new proposal starts with
+var defaultNextSteps = []NextStep{
+	{Text: "Review this proposal and confirm the selected package"},
+	{Text: "Sign the service agreement"},
+	{Text: "Pay the initial setup invoice"},
+	{Text: "Book the project kick-off meeting"},
+}
+
+// defaultPerformanceStandards are the targets a new proposal commits to
+var defaultPerformanceStandards = []map[string]string{
+	{"label": "Page Load", "value": "<2s"},
+	{"label": "Mobile Score", "value": "95+"},
+	{"label": "Accessibility", "value": "WCAG 2.1 AA"},
+	{"label": "SEO Score", "value": "90+"},
+}
+
+// mustJSON marshals package-level template content, which is always valid
+func mustJSON(v any) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,98 @@
+package proposal
+
+import (
+	"app/pkg"
+	"bytes"
+	"encoding/json"
+	"net/mail"
+)
+
+type schema struct {
+	title                string
+	clientEmail          string
+	performanceData      json.RawMessage
+	currentIssues        json.RawMessage
+	complianceIssues     json.RawMessage
+	performanceStandards json.RawMessage
+}
+
+func validate(s *schema) error {
+	var errors pkg.ValidationErrors
+	if s.title == "" {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "title",
+			Tag:     "required",
+			Message: "Title is required",
+		})
+	}
+	if len(s.title) > 255 {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "title",
+			Tag:     "max255",
+			Message: "Title must be at most 255 characters long",
+		})
+	}
+	if s.clientEmail != "" {
+		if _, err := mail.ParseAddress(s.clientEmail); err != nil {
+			errors = append(errors, pkg.ValidationError{
+				Field:   "clientEmail",
+				Tag:     "email",
+				Message: "Client email must be a valid email address",
+			})
+		}
+	}
+	for _, f := range []struct {
+		field string
+		raw   json.RawMessage
+	}{
+		{"performanceData", s.performanceData},
+		{"currentIssues", s.currentIssues},
+		{"complianceIssues", s.complianceIssues},
+		{"performanceStandards", s.performanceStandards},
+	} {
+		if !json.Valid(f.raw) {
+			errors = append(errors, pkg.ValidationError{
+				Field:   f.field,
+				Tag:     "json",
+				Message: "Must be valid JSON",
+			})
+		}
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+// validateSection decodes section content into its typed shape and returns
+// the normalised JSON to store
+func validateSection(section Section, content json.RawMessage) (json.RawMessage, error) {
+	var target any
+	switch section {
+	case SectionOpportunity:
+		target = new(string)
+	case SectionROIAnalysis:
+		target = new(ROIAnalysis)
+	case SectionProposedPages:
+		target = new([]ProposedPage)
+	case SectionTimeline:
+		target = new([]TimelinePhase)
+	case SectionNextSteps:
+		target = new([]NextStep)
+	default:
+		return nil, pkg.BadRequestError{Message: "Unknown proposal section: " + string(section)}
+	}
+	if err := json.Unmarshal(content, target); err != nil || string(bytes.TrimSpace(content)) == "null" {
+		return nil, pkg.ValidationErrors{{
+			Field:   string(section),
+			Tag:     "format",
+			Message: "Content does not match the " + string(section) + " section format",
+		}}
+	}
+	b, err := json.Marshal(target)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error encoding proposal section", Err: err}
+	}
+	return b, nil
+}
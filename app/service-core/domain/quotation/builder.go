@@ -0,0 +1,222 @@
+package quotation
+
+import (
+	"app/pkg/money"
+	"crypto/rand"
+	"encoding/json"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const slugAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// newSlug returns a random 12 character public URL slug
+func newSlug() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = slugAlphabet[int(b[i])%len(slugAlphabet)]
+	}
+	return string(b), nil
+}
+
+// newParams returns insert params for a draft quotation using the agency's
+// GST registration. The expiry defaults to the template's validity period,
+// then the agency's, counted from the prepared date.
+func newParams(
+	agencyID uuid.UUID,
+	userID uuid.UUID,
+	profile query.AgencyProfile,
+	tmpl *query.QuotationTemplate,
+	req CreateRequest,
+	now time.Time,
+) query.InsertQuotationParams {
+	prepared := now
+	if req.PreparedDate != nil {
+		prepared = *req.PreparedDate
+	}
+	days := int(profile.DefaultQuotationValidityDays)
+	if tmpl != nil && tmpl.DefaultValidityDays.Valid && tmpl.DefaultValidityDays.Int32 > 0 {
+		days = int(tmpl.DefaultValidityDays.Int32)
+	}
+	if days <= 0 {
+		days = defaultValidityDays
+	}
+	expiry := prepared.AddDate(0, 0, days)
+	if req.ExpiryDate != nil {
+		expiry = *req.ExpiryDate
+	}
+
+	params := query.InsertQuotationParams{
+		AgencyID:            agencyID,
+		QuotationName:       req.QuotationName,
+		Status:              string(StatusDraft),
+		ClientBusinessName:  req.ClientBusinessName,
+		ClientContactName:   req.ClientContactName,
+		ClientEmail:         req.ClientEmail,
+		ClientPhone:         req.ClientPhone,
+		ClientAddress:       req.ClientAddress,
+		SiteAddress:         req.SiteAddress,
+		SiteReference:       req.SiteReference,
+		PreparedDate:        prepared,
+		ExpiryDate:          expiry,
+		DiscountAmount:      req.DiscountAmount,
+		DiscountDescription: req.DiscountDescription,
+		GstRegistered:       profile.GstRegistered,
+		GstRate:             profile.GstRate,
+		TermsBlocks:         termsJSON(req.TermsBlocks),
+		OptionsNotes:        req.OptionsNotes,
+		Notes:               req.Notes,
+		CreatedBy:           uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+	}
+	if req.ClientID != nil {
+		params.ClientID = uuid.NullUUID{UUID: *req.ClientID, Valid: true}
+	}
+	if tmpl != nil {
+		params.TemplateID = uuid.NullUUID{UUID: tmpl.ID, Valid: true}
+		if params.QuotationName == "" {
+			params.QuotationName = tmpl.Name
+		}
+	}
+	return params
+}
+
+// templateSections copies a template's scope blocks into section requests.
+// The price set on the template link wins over the scope block's default.
+func templateSections(rows []query.SelectQuotationTemplateSectionsRow) []SectionRequest {
+	sections := make([]SectionRequest, 0, len(rows))
+	for _, row := range rows {
+		price := row.DefaultSectionPrice
+		if price.IsZero() {
+			price = row.DefaultPrice
+		}
+		var items []string
+		_ = json.Unmarshal(row.WorkItems, &items)
+		scopeTemplateID := row.ScopeTemplateID
+		sortOrder := row.SortOrder
+		sections = append(sections, SectionRequest{
+			Title:           row.Name,
+			WorkItems:       items,
+			SectionPrice:    price,
+			ScopeTemplateID: &scopeTemplateID,
+			SortOrder:       &sortOrder,
+		})
+	}
+	return sections
+}
+
+// templateTerms copies a template's terms blocks
+func templateTerms(rows []query.SelectQuotationTemplateTermsRow) []TermsBlock {
+	terms := make([]TermsBlock, 0, len(rows))
+	for _, row := range rows {
+		terms = append(terms, TermsBlock{
+			Title:     row.Title,
+			Content:   row.Content,
+			SortOrder: row.SortOrder,
+		})
+	}
+	return terms
+}
+
+// sectionParams returns insert params for a scope section with its GST and
+// GST inclusive total computed
+func sectionParams(
+	quotationID uuid.UUID,
+	index int32,
+	req SectionRequest,
+	gstRegistered bool,
+	gstRate money.Decimal,
+	mode money.Rounding,
+) query.InsertQuotationScopeSectionParams {
+	items := req.WorkItems
+	if items == nil {
+		items = []string{}
+	}
+	workItems, _ := json.Marshal(items)
+	gst, total := SectionTotals(req.SectionPrice, gstRegistered, gstRate, mode)
+	params := query.InsertQuotationScopeSectionParams{
+		QuotationID:  quotationID,
+		Title:        req.Title,
+		WorkItems:    workItems,
+		SectionPrice: req.SectionPrice,
+		SectionGst:   gst,
+		SectionTotal: total,
+		SortOrder:    index,
+	}
+	if req.SortOrder != nil {
+		params.SortOrder = *req.SortOrder
+	}
+	if req.ScopeTemplateID != nil {
+		params.ScopeTemplateID = uuid.NullUUID{UUID: *req.ScopeTemplateID, Valid: true}
+	}
+	return params
+}
+
+// applyUpdate returns update params for a quotation with the requested
+// changes applied
+func applyUpdate(q query.Quotation, req UpdateRequest) query.UpdateQuotationParams {
+	params := query.UpdateQuotationParams{
+		ID:                  q.ID,
+		ClientID:            q.ClientID,
+		QuotationName:       q.QuotationName,
+		ClientBusinessName:  q.ClientBusinessName,
+		ClientContactName:   q.ClientContactName,
+		ClientEmail:         q.ClientEmail,
+		ClientPhone:         q.ClientPhone,
+		ClientAddress:       q.ClientAddress,
+		SiteAddress:         q.SiteAddress,
+		SiteReference:       q.SiteReference,
+		PreparedDate:        q.PreparedDate,
+		ExpiryDate:          q.ExpiryDate,
+		DiscountAmount:      q.DiscountAmount,
+		DiscountDescription: q.DiscountDescription,
+		TermsBlocks:         q.TermsBlocks,
+		OptionsNotes:        q.OptionsNotes,
+		Notes:               q.Notes,
+	}
+	if req.ClientID != nil {
+		params.ClientID = uuid.NullUUID{UUID: *req.ClientID, Valid: *req.ClientID != uuid.Nil}
+	}
+	setString(&params.QuotationName, req.QuotationName)
+	setString(&params.ClientBusinessName, req.ClientBusinessName)
+	setString(&params.ClientContactName, req.ClientContactName)
+	setString(&params.ClientEmail, req.ClientEmail)
+	setString(&params.ClientPhone, req.ClientPhone)
+	setString(&params.ClientAddress, req.ClientAddress)
+	setString(&params.SiteAddress, req.SiteAddress)
+	setString(&params.SiteReference, req.SiteReference)
+	setString(&params.DiscountDescription, req.DiscountDescription)
+	setString(&params.OptionsNotes, req.OptionsNotes)
+	setString(&params.Notes, req.Notes)
+	if req.PreparedDate != nil {
+		params.PreparedDate = *req.PreparedDate
+	}
+	if req.ExpiryDate != nil {
+		params.ExpiryDate = *req.ExpiryDate
+	}
+	if req.DiscountAmount != nil {
+		params.DiscountAmount = *req.DiscountAmount
+	}
+	if req.TermsBlocks != nil {
+		params.TermsBlocks = termsJSON(*req.TermsBlocks)
+	}
+	return params
+}
+
+func termsJSON(terms []TermsBlock) json.RawMessage {
+	if terms == nil {
+		terms = []TermsBlock{}
+	}
+	b, _ := json.Marshal(terms)
+	return b
+}
+
+func setString(dst *string, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
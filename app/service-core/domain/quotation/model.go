@@ -0,0 +1,196 @@
+package quotation
+
+import (
+	"app/pkg/money"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is the lifecycle state of a quotation
+type Status string
+
+const (
+	StatusDraft    Status = "draft"
+	StatusSent     Status = "sent"
+	StatusViewed   Status = "viewed"
+	StatusAccepted Status = "accepted"
+	StatusDeclined Status = "declined"
+	StatusExpired  Status = "expired"
+)
+
+// transitions lists the statuses a quotation may be moved to explicitly.
+// Sending a sent quotation again refreshes its sent date. Viewed is only
+// reached by recording a view, and accepted and declined only by the client.
+var transitions = map[Status][]Status{
+	StatusDraft:  {StatusSent},
+	StatusSent:   {StatusSent, StatusExpired},
+	StatusViewed: {StatusExpired},
+}
+
+// CanTransition reports whether a quotation may be moved from one status to
+// another
+func CanTransition(from, to Status) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEditable reports whether a quotation's details and sections may still be
+// changed. Only drafts are edited; a sent quotation is what the client sees.
+func (s Status) IsEditable() bool {
+	return s == StatusDraft
+}
+
+// IsOpen reports whether the client can still accept or decline
+func (s Status) IsOpen() bool {
+	return s == StatusSent || s == StatusViewed
+}
+
+// defaultValidityDays is used when neither the template nor the agency
+// profile sets a validity period
+const defaultValidityDays = 60
+
+// IsExpired reports whether an open quotation is past its expiry date. A
+// quotation is valid until the end of its expiry day (UTC).
+func IsExpired(q query.Quotation, now time.Time) bool {
+	if !Status(q.Status).IsOpen() {
+		return false
+	}
+	return q.ExpiryDate.Before(now.UTC().Truncate(24 * time.Hour))
+}
+
+// TermsBlock is one terms and conditions block stored in the terms_blocks
+// column
+type TermsBlock struct {
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	SortOrder int32  `json:"sortOrder"`
+}
+
+// Totals are the computed amounts of a quotation
+type Totals struct {
+	Subtotal money.Money `json:"subtotal"`
+	GST      money.Money `json:"gst"`
+	Total    money.Money `json:"total"`
+}
+
+// SectionTotals returns the GST and GST inclusive total of a section price
+func SectionTotals(price money.Money, gstRegistered bool, gstRate money.Decimal, mode money.Rounding) (gst, total money.Money) {
+	gst = money.Zero(price.Currency())
+	if gstRegistered {
+		gst = price.Percent(gstRate, mode)
+	}
+	return gst, price.Add(gst)
+}
+
+// CalculateTotals sums the section prices and applies the discount and GST.
+// GST is charged on the discounted subtotal. Amounts in different
+// currencies return money.ErrCurrencyMismatch.
+func CalculateTotals(sections []query.QuotationScopeSection, discount money.Money, gstRegistered bool, gstRate money.Decimal, mode money.Rounding) (Totals, error) {
+	amounts := []money.Money{discount}
+	for _, s := range sections {
+		amounts = append(amounts, s.SectionPrice)
+	}
+	if err := money.SameCurrency(amounts...); err != nil {
+		return Totals{}, err
+	}
+
+	var subtotal money.Money
+	for _, s := range sections {
+		subtotal = subtotal.Add(s.SectionPrice)
+	}
+	t := Totals{Subtotal: subtotal, GST: money.Zero(subtotal.Currency())}
+	if gstRegistered {
+		t.GST = subtotal.Sub(discount).Percent(gstRate, mode)
+	}
+	t.Total = subtotal.Sub(discount).Add(t.GST)
+	return t, nil
+}
+
+// SectionRequest is the input for a priced scope section
+type SectionRequest struct {
+	Title           string      `json:"title"`
+	WorkItems       []string    `json:"workItems"`
+	SectionPrice    money.Money `json:"sectionPrice"`
+	ScopeTemplateID *uuid.UUID  `json:"scopeTemplateId"`
+	SortOrder       *int32      `json:"sortOrder"`
+}
+
+// CreateRequest is the input for creating a quotation. When a template is
+// given, its scope sections and terms are copied unless Sections or
+// TermsBlocks are provided.
+type CreateRequest struct {
+	TemplateID          *uuid.UUID       `json:"templateId"`
+	ClientID            *uuid.UUID       `json:"clientId"`
+	QuotationName       string           `json:"quotationName"`
+	ClientBusinessName  string           `json:"clientBusinessName"`
+	ClientContactName   string           `json:"clientContactName"`
+	ClientEmail         string           `json:"clientEmail"`
+	ClientPhone         string           `json:"clientPhone"`
+	ClientAddress       string           `json:"clientAddress"`
+	SiteAddress         string           `json:"siteAddress"`
+	SiteReference       string           `json:"siteReference"`
+	PreparedDate        *time.Time       `json:"preparedDate"`
+	ExpiryDate          *time.Time       `json:"expiryDate"`
+	DiscountAmount      money.Money      `json:"discountAmount"`
+	DiscountDescription string           `json:"discountDescription"`
+	Sections            []SectionRequest `json:"sections"`
+	TermsBlocks         []TermsBlock     `json:"termsBlocks"`
+	OptionsNotes        string           `json:"optionsNotes"`
+	Notes               string           `json:"notes"`
+}
+
+// UpdateRequest edits a draft quotation. Nil fields are left unchanged;
+// Sections replaces all scope sections when set.
+type UpdateRequest struct {
+	ClientID            *uuid.UUID        `json:"clientId"`
+	QuotationName       *string           `json:"quotationName"`
+	ClientBusinessName  *string           `json:"clientBusinessName"`
+	ClientContactName   *string           `json:"clientContactName"`
+	ClientEmail         *string           `json:"clientEmail"`
+	ClientPhone         *string           `json:"clientPhone"`
+	ClientAddress       *string           `json:"clientAddress"`
+	SiteAddress         *string           `json:"siteAddress"`
+	SiteReference       *string           `json:"siteReference"`
+	PreparedDate        *time.Time        `json:"preparedDate"`
+	ExpiryDate          *time.Time        `json:"expiryDate"`
+	DiscountAmount      *money.Money      `json:"discountAmount"`
+	DiscountDescription *string           `json:"discountDescription"`
+	Sections            *[]SectionRequest `json:"sections"`
+	TermsBlocks         *[]TermsBlock     `json:"termsBlocks"`
+	OptionsNotes        *string           `json:"optionsNotes"`
+	Notes               *string           `json:"notes"`
+}
+
+// TransitionRequest moves a quotation to a new status
+type TransitionRequest struct {
+	Status Status `json:"status"`
+}
+
+// AcceptRequest is the client's acceptance from the public quotation page
+type AcceptRequest struct {
+	Name  string `json:"acceptedByName"`
+	Title string `json:"acceptedByTitle"`
+}
+
+// DeclineRequest is the client's decline from the public quotation page
+type DeclineRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Detail is a quotation with its scope sections
+type Detail struct {
+	Quotation query.Quotation               `json:"quotation"`
+	Sections  []query.QuotationScopeSection `json:"sections"`
+}
+
+// ListResponse is a page of quotations for an agency
+type ListResponse struct {
+	Count      int64             `json:"count"`
+	Quotations []query.Quotation `json:"quotations"`
+}
@@ -0,0 +1,111 @@
+package quotation_test
+
+import (
+	"app/pkg/money"
+	"service-core/domain/quotation"
+	"service-core/storage/query"
+	"testing"
+	"time"
+)
+
+func TestCanTransition(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		from, to quotation.Status
+		want     bool
+	}{
+		{quotation.StatusDraft, quotation.StatusSent, true},
+		{quotation.StatusSent, quotation.StatusSent, true},
+		{quotation.StatusSent, quotation.StatusExpired, true},
+		{quotation.StatusViewed, quotation.StatusExpired, true},
+		{quotation.StatusDraft, quotation.StatusViewed, false},
+		{quotation.StatusSent, quotation.StatusAccepted, false},
+		{quotation.StatusViewed, quotation.StatusDeclined, false},
+		{quotation.StatusAccepted, quotation.StatusSent, false},
+		{quotation.StatusExpired, quotation.StatusSent, false},
+	}
+	for _, tt := range tests {
+		if got := quotation.CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestSectionTotals(t *testing.T) {
+	t.Parallel()
+	rate := money.MustParseDecimal("10.00")
+	tests := []struct {
+		name       string
+		price      string
+		registered bool
+		gst, total string
+	}{
+		{"registered", "1000.00", true, "100.00", "1100.00"},
+		{"rounded", "333.33", true, "33.33", "366.66"},
+		{"not registered", "1000.00", false, "0.00", "1000.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			gst, total := quotation.SectionTotals(money.MustParse(tt.price, money.AUD), tt.registered, rate, money.HalfEven)
+			if gst.String() != tt.gst || total.String() != tt.total {
+				t.Errorf("SectionTotals(%s) = %s, %s, want %s, %s", tt.price, gst, total, tt.gst, tt.total)
+			}
+		})
+	}
+}
+
+func TestCalculateTotals(t *testing.T) {
+	t.Parallel()
+	rate := money.MustParseDecimal("10.00")
+	sections := []query.QuotationScopeSection{
+		{SectionPrice: money.MustParse("1200.00", money.AUD)},
+		{SectionPrice: money.MustParse("800.00", money.AUD)},
+	}
+	tests := []struct {
+		name                 string
+		discount             string
+		registered           bool
+		subtotal, gst, total string
+	}{
+		{"no discount", "0.00", true, "2000.00", "200.00", "2200.00"},
+		{"gst on discounted subtotal", "500.00", true, "2000.00", "150.00", "1650.00"},
+		{"not registered", "500.00", false, "2000.00", "0.00", "1500.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := quotation.CalculateTotals(sections, money.MustParse(tt.discount, money.AUD), tt.registered, rate, money.HalfEven)
+			if err != nil {
+				t.Fatalf("CalculateTotals() error = %v", err)
+			}
+			if got.Subtotal.String() != tt.subtotal || got.GST.String() != tt.gst || got.Total.String() != tt.total {
+				t.Errorf("CalculateTotals() = %s, %s, %s, want %s, %s, %s",
+					got.Subtotal, got.GST, got.Total, tt.subtotal, tt.gst, tt.total)
+			}
+		})
+	}
+}
+
+func TestIsExpired(t *testing.T) {
+	t.Parallel()
+	expiry := time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		status quotation.Status
+		now    time.Time
+		want   bool
+	}{
+		{"before expiry", quotation.StatusSent, time.Date(2026, 5, 30, 12, 0, 0, 0, time.UTC), false},
+		{"on expiry day", quotation.StatusViewed, time.Date(2026, 5, 31, 23, 59, 0, 0, time.UTC), false},
+		{"day after expiry", quotation.StatusSent, time.Date(2026, 6, 1, 0, 0, 1, 0, time.UTC), true},
+		{"draft", quotation.StatusDraft, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), false},
+		{"accepted", quotation.StatusAccepted, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		q := query.Quotation{Status: string(tt.status), ExpiryDate: expiry}
+		if got := quotation.IsExpired(q, tt.now); got != tt.want {
+			t.Errorf("%s: IsExpired() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,631 @@
+package quotation
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"service-core/config"
+	"service-core/domain/activity"
+	"service-core/domain/numbering"
+	"service-core/storage/query"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// store defines the database interface for quotation operations
+type store interface {
+	CountQuotations(ctx context.Context, arg query.CountQuotationsParams) (int64, error)
+	SelectQuotations(ctx context.Context, arg query.SelectQuotationsParams) ([]query.Quotation, error)
+	SelectQuotation(ctx context.Context, id uuid.UUID) (query.Quotation, error)
+	SelectQuotationBySlug(ctx context.Context, slug string) (query.Quotation, error)
+	InsertQuotation(ctx context.Context, arg query.InsertQuotationParams) (query.Quotation, error)
+	UpdateQuotation(ctx context.Context, arg query.UpdateQuotationParams) (query.Quotation, error)
+	UpdateQuotationTotals(ctx context.Context, arg query.UpdateQuotationTotalsParams) (query.Quotation, error)
+	UpdateQuotationStatus(ctx context.Context, arg query.UpdateQuotationStatusParams) (query.Quotation, error)
+	RecordQuotationView(ctx context.Context, id uuid.UUID) (query.Quotation, error)
+	AcceptQuotation(ctx context.Context, arg query.AcceptQuotationParams) (query.Quotation, error)
+	DeclineQuotation(ctx context.Context, arg query.DeclineQuotationParams) (query.Quotation, error)
+	ExpireQuotations(ctx context.Context, before time.Time) (int64, error)
+	DeleteQuotation(ctx context.Context, id uuid.UUID) error
+
+	SelectQuotationScopeSections(ctx context.Context, quotationID uuid.UUID) ([]query.QuotationScopeSection, error)
+	InsertQuotationScopeSection(ctx context.Context, arg query.InsertQuotationScopeSectionParams) (query.QuotationScopeSection, error)
+	DeleteQuotationScopeSections(ctx context.Context, quotationID uuid.UUID) error
+
+	SelectQuotationTemplate(ctx context.Context, id uuid.UUID) (query.QuotationTemplate, error)
+	SelectQuotationTemplateSections(ctx context.Context, templateID uuid.UUID) ([]query.SelectQuotationTemplateSectionsRow, error)
+	SelectQuotationTemplateTerms(ctx context.Context, templateID uuid.UUID) ([]query.SelectQuotationTemplateTermsRow, error)
+
+	SelectAgencyProfile(ctx context.Context, agencyID uuid.UUID) (query.AgencyProfile, error)
+	InsertActivityLog(ctx context.Context, arg query.InsertActivityLogParams) error
+}
+
+// numberer allocates agency document numbers
+type numberer interface {
+	Allocate(ctx context.Context, agencyID uuid.UUID, docType numbering.DocumentType) (string, error)
+}
+
+// Service handles agency quotation operations
+type Service struct {
+	cfg      *config.Config
+	store    store
+	numberer numberer
+}
+
+// NewService creates a new quotation service
+func NewService(cfg *config.Config, store store, numberer numberer) *Service {
+	return &Service{
+		cfg:      cfg,
+		store:    store,
+		numberer: numberer,
+	}
+}
+
+// ListQuotations returns a page of an agency's quotations, optionally filtered by status
+func (s *Service) ListQuotations(
+	ctx context.Context,
+	agencyID uuid.UUID,
+	status string,
+	page int32,
+	limit int32,
+) (*ListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+	count, err := s.store.CountQuotations(ctx, query.CountQuotationsParams{
+		AgencyID: agencyID,
+		Status:   status,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error counting quotations", Err: err}
+	}
+	quotations, err := s.store.SelectQuotations(ctx, query.SelectQuotationsParams{
+		AgencyID:  agencyID,
+		Status:    status,
+		RowLimit:  limit,
+		RowOffset: (page - 1) * limit,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting quotations", Err: err}
+	}
+	if quotations == nil {
+		quotations = []query.Quotation{}
+	}
+	return &ListResponse{
+		Count:      count,
+		Quotations: quotations,
+	}, nil
+}
+
+// GetQuotation returns a quotation belonging to the agency with its sections
+func (s *Service) GetQuotation(ctx context.Context, agencyID, id uuid.UUID) (*Detail, error) {
+	q, err := s.get(ctx, agencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	sections, err := s.sections(ctx, q.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Detail{Quotation: *q, Sections: sections}, nil
+}
+
+// CreateQuotation creates a draft quotation. A template's scope sections and
+// terms blocks are copied onto the quotation unless the request provides its
+// own, and the totals are computed from the sections.
+func (s *Service) CreateQuotation(
+	ctx context.Context,
+	user auth.UserAttr,
+	req CreateRequest,
+) (*Detail, error) {
+	if err := auth.Authorize(auth.CreateQuotation, user, auth.Resource{OwnerID: user.ID, AgencyID: user.AgencyID}); err != nil {
+		return nil, err
+	}
+	profile, err := s.profile(ctx, user.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+	var tmpl *query.QuotationTemplate
+	if req.TemplateID != nil {
+		tmpl, err = s.template(ctx, user.AgencyID, *req.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		if req.Sections == nil {
+			rows, err := s.store.SelectQuotationTemplateSections(ctx, tmpl.ID)
+			if err != nil {
+				return nil, pkg.InternalError{Message: "Error selecting quotation template sections", Err: err}
+			}
+			req.Sections = templateSections(rows)
+		}
+		if req.TermsBlocks == nil {
+			rows, err := s.store.SelectQuotationTemplateTerms(ctx, tmpl.ID)
+			if err != nil {
+				return nil, pkg.InternalError{Message: "Error selecting quotation template terms", Err: err}
+			}
+			req.TermsBlocks = templateTerms(rows)
+		}
+	}
+	if req.Sections == nil {
+		req.Sections = []SectionRequest{}
+	}
+
+	params := newParams(user.AgencyID, user.ID, profile, tmpl, req, time.Now())
+	err = validate(&schema{
+		clientBusinessName: params.ClientBusinessName,
+		clientEmail:        params.ClientEmail,
+		preparedDate:       params.PreparedDate,
+		expiryDate:         params.ExpiryDate,
+		discountAmount:     params.DiscountAmount,
+		sections:           req.Sections,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating quotation ID", Err: err}
+	}
+	params.ID = id
+	params.QuotationNumber, err = s.numberer.Allocate(ctx, user.AgencyID, numbering.Quotation)
+	if err != nil {
+		return nil, err
+	}
+	params.Slug, err = newSlug()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating quotation slug", Err: err}
+	}
+
+	sections := make([]query.InsertQuotationScopeSectionParams, len(req.Sections))
+	computed := make([]query.QuotationScopeSection, len(req.Sections))
+	for i, section := range req.Sections {
+		sections[i] = sectionParams(id, int32(i), section, params.GstRegistered, params.GstRate, s.cfg.Rounding)
+		computed[i] = query.QuotationScopeSection{SectionPrice: sections[i].SectionPrice}
+	}
+	t, err := CalculateTotals(computed, params.DiscountAmount, params.GstRegistered, params.GstRate, s.cfg.Rounding)
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: "Quotation amounts must share a currency", Err: err}
+	}
+	params.Subtotal, params.GstAmount, params.Total = t.Subtotal, t.GST, t.Total
+
+	q, err := s.store.InsertQuotation(ctx, params)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error inserting quotation", Err: err}
+	}
+	d := &Detail{Quotation: q, Sections: make([]query.QuotationScopeSection, 0, len(sections))}
+	for _, p := range sections {
+		section, err := s.insertSection(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		d.Sections = append(d.Sections, *section)
+	}
+
+	var metadata any
+	if tmpl != nil {
+		metadata = map[string]any{"templateId": tmpl.ID, "templateName": tmpl.Name}
+	}
+	s.logActivity(ctx, &d.Quotation, user.ID, "quotation.created", nil, map[string]any{
+		"quotationNumber": q.QuotationNumber,
+		"total":           q.Total,
+	}, metadata)
+	return d, nil
+}
+
+// UpdateQuotation edits a draft quotation. When sections are given they
+// replace the existing ones, and the totals are always recomputed.
+func (s *Service) UpdateQuotation(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	req UpdateRequest,
+) (*Detail, error) {
+	existing, err := s.get(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(auth.EditQuotation, user, resource(existing)); err != nil {
+		return nil, err
+	}
+	if !Status(existing.Status).IsEditable() {
+		return nil, pkg.BadRequestError{
+			Message: fmt.Sprintf("Quotations with status %s can no longer be edited", existing.Status),
+			Err:     errors.New("quotation is not editable"),
+		}
+	}
+	params := applyUpdate(*existing, req)
+	sc := &schema{
+		clientBusinessName: params.ClientBusinessName,
+		clientEmail:        params.ClientEmail,
+		preparedDate:       params.PreparedDate,
+		expiryDate:         params.ExpiryDate,
+		discountAmount:     params.DiscountAmount,
+	}
+	if req.Sections != nil {
+		sc.sections = *req.Sections
+		if sc.sections == nil {
+			sc.sections = []SectionRequest{}
+		}
+	}
+	if err := validate(sc); err != nil {
+		return nil, err
+	}
+
+	q, err := s.store.UpdateQuotation(ctx, params)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating quotation", Err: err}
+	}
+	if req.Sections != nil {
+		if err := s.store.DeleteQuotationScopeSections(ctx, q.ID); err != nil {
+			return nil, pkg.InternalError{Message: "Error deleting quotation sections", Err: err}
+		}
+		for i, section := range *req.Sections {
+			if _, err := s.insertSection(ctx, sectionParams(q.ID, int32(i), section, q.GstRegistered, q.GstRate, s.cfg.Rounding)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	d, err := s.recalculate(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	s.logActivity(ctx, &d.Quotation, user.ID, "quotation.updated", map[string]any{"total": existing.Total}, req, nil)
+	return d, nil
+}
+
+// TransitionQuotation moves a quotation to a new status. The update only
+// applies if the status has not changed since it was read.
+func (s *Service) TransitionQuotation(
+	ctx context.Context,
+	user auth.UserAttr,
+	id uuid.UUID,
+	req TransitionRequest,
+) (*query.Quotation, error) {
+	existing, err := s.get(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(auth.EditQuotation, user, resource(existing)); err != nil {
+		return nil, err
+	}
+	from := Status(existing.Status)
+	if !CanTransition(from, req.Status) {
+		message := fmt.Sprintf("Cannot change quotation status from %s to %s", from, req.Status)
+		if req.Status == StatusAccepted || req.Status == StatusDeclined {
+			message = "Quotations are accepted or declined by the client"
+		}
+		return nil, pkg.BadRequestError{Message: message, Err: errors.New("invalid status transition")}
+	}
+	if req.Status == StatusSent && existing.ExpiryDate.Before(time.Now()) {
+		return nil, pkg.BadRequestError{
+			Message: "Extend the expiry date before sending this quotation",
+			Err:     fmt.Errorf("quotation %s expired at %s", existing.ID, existing.ExpiryDate),
+		}
+	}
+
+	params := query.UpdateQuotationStatusParams{
+		ID:         existing.ID,
+		Status:     string(req.Status),
+		FromStatus: existing.Status,
+	}
+	if req.Status == StatusSent {
+		params.SentAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	q, err := s.store.UpdateQuotationStatus(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.BadRequestError{Message: "Quotation status changed, please reload and try again", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error updating quotation status", Err: err}
+	}
+	s.logActivity(ctx, &q, user.ID, "quotation."+string(req.Status),
+		map[string]any{"status": from},
+		map[string]any{"status": req.Status},
+		nil,
+	)
+	return &q, nil
+}
+
+// RecordView counts a view of a quotation's public page, moves a sent
+// quotation to viewed and returns it with its sections. Drafts are not
+// visible. It requires no authentication.
+func (s *Service) RecordView(ctx context.Context, slug string) (*Detail, error) {
+	existing, err := s.public(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	q, err := s.store.RecordQuotationView(ctx, existing.ID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error recording quotation view", Err: err}
+	}
+	if existing.Status != q.Status {
+		s.logActivity(ctx, &q, uuid.Nil, "quotation.viewed",
+			map[string]any{"status": existing.Status},
+			map[string]any{"status": q.Status},
+			nil,
+		)
+	}
+	if IsExpired(q, time.Now()) {
+		q = s.expire(ctx, q)
+	}
+	sections, err := s.sections(ctx, q.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Detail{Quotation: q, Sections: sections}, nil
+}
+
+// Accept records the client's acceptance from the public quotation page
+func (s *Service) Accept(ctx context.Context, slug string, req AcceptRequest, ipAddress string) (*query.Quotation, error) {
+	if err := validateAccept(req); err != nil {
+		return nil, err
+	}
+	existing, err := s.open(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if len(ipAddress) > 50 {
+		ipAddress = ipAddress[:50]
+	}
+	q, err := s.store.AcceptQuotation(ctx, query.AcceptQuotationParams{
+		ID:              existing.ID,
+		AcceptedByName:  sql.NullString{String: req.Name, Valid: true},
+		AcceptedByTitle: sql.NullString{String: req.Title, Valid: req.Title != ""},
+		AcceptedAt:      sql.NullTime{Time: time.Now(), Valid: true},
+		AcceptanceIp:    sql.NullString{String: ipAddress, Valid: ipAddress != ""},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.BadRequestError{Message: "This quotation is no longer available for acceptance", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error accepting quotation", Err: err}
+	}
+	s.logActivity(ctx, &q, uuid.Nil, "quotation.accepted",
+		map[string]any{"status": existing.Status},
+		map[string]any{"status": q.Status, "acceptedByName": req.Name, "acceptedByTitle": req.Title},
+		map[string]any{"ipAddress": ipAddress},
+	)
+	return &q, nil
+}
+
+// Decline records the client declining from the public quotation page
+func (s *Service) Decline(ctx context.Context, slug string, req DeclineRequest) (*query.Quotation, error) {
+	if err := validateDecline(req); err != nil {
+		return nil, err
+	}
+	existing, err := s.open(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	q, err := s.store.DeclineQuotation(ctx, query.DeclineQuotationParams{
+		ID:            existing.ID,
+		DeclinedAt:    sql.NullTime{Time: time.Now(), Valid: true},
+		DeclineReason: req.Reason,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.BadRequestError{Message: "This quotation is no longer available for declining", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error declining quotation", Err: err}
+	}
+	s.logActivity(ctx, &q, uuid.Nil, "quotation.declined",
+		map[string]any{"status": existing.Status},
+		map[string]any{"status": q.Status, "reason": req.Reason},
+		nil,
+	)
+	return &q, nil
+}
+
+// ExpireQuotations marks every sent or viewed quotation past its expiry day
+// as expired and returns how many were changed
+func (s *Service) ExpireQuotations(ctx context.Context, now time.Time) (int64, error) {
+	n, err := s.store.ExpireQuotations(ctx, now.UTC().Truncate(24*time.Hour))
+	if err != nil {
+		return 0, pkg.InternalError{Message: "Error expiring quotations", Err: err}
+	}
+	return n, nil
+}
+
+// DeleteQuotation removes a draft quotation. Sent quotations are kept so the
+// numbering has no silent gaps.
+func (s *Service) DeleteQuotation(ctx context.Context, user auth.UserAttr, id uuid.UUID) error {
+	existing, err := s.get(ctx, user.AgencyID, id)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(auth.RemoveQuotation, user, resource(existing)); err != nil {
+		return err
+	}
+	if Status(existing.Status) != StatusDraft {
+		return pkg.BadRequestError{
+			Message: "Only draft quotations can be deleted",
+			Err:     errors.New("quotation is not a draft"),
+		}
+	}
+	if err := s.store.DeleteQuotation(ctx, id); err != nil {
+		return pkg.InternalError{Message: "Error deleting quotation", Err: err}
+	}
+	s.logActivity(ctx, existing, user.ID, "quotation.deleted", map[string]any{
+		"quotationNumber": existing.QuotationNumber,
+		"total":           existing.Total,
+	}, nil, nil)
+	return nil
+}
+
+func (s *Service) get(ctx context.Context, agencyID, id uuid.UUID) (*query.Quotation, error) {
+	q, err := s.store.SelectQuotation(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Quotation not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting quotation", Err: err}
+	}
+	// Quotations from other agencies are reported as missing rather than forbidden
+	if q.AgencyID != agencyID {
+		return nil, pkg.NotFoundError{Message: "Quotation not found", Err: fmt.Errorf("quotation %s belongs to another agency", id)}
+	}
+	return &q, nil
+}
+
+// public returns a quotation by its public slug. Drafts have not been sent
+// and are reported as missing.
+// resource returns the attributes a quotation is authorized against
+func resource(q *query.Quotation) auth.Resource {
+	return auth.Resource{OwnerID: q.CreatedBy.UUID, AgencyID: q.AgencyID}
+}
+
+func (s *Service) public(ctx context.Context, slug string) (*query.Quotation, error) {
+	q, err := s.store.SelectQuotationBySlug(ctx, slug)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.InternalError{Message: "Error selecting quotation", Err: err}
+	}
+	if err != nil || Status(q.Status) == StatusDraft {
+		return nil, pkg.NotFoundError{Message: "Quotation not found", Err: err}
+	}
+	return &q, nil
+}
+
+// open returns a public quotation the client can still respond to. An
+// expired quotation is marked as such.
+func (s *Service) open(ctx context.Context, slug string) (*query.Quotation, error) {
+	q, err := s.public(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if IsExpired(*q, time.Now()) {
+		s.expire(ctx, *q)
+		return nil, pkg.BadRequestError{
+			Message: "This quotation has expired",
+			Err:     fmt.Errorf("quotation %s expired at %s", q.ID, q.ExpiryDate),
+		}
+	}
+	if !Status(q.Status).IsOpen() {
+		return nil, pkg.BadRequestError{
+			Message: fmt.Sprintf("This quotation has already been %s", q.Status),
+			Err:     fmt.Errorf("quotation %s has status %s", q.ID, q.Status),
+		}
+	}
+	return q, nil
+}
+
+// expire marks a quotation found past its expiry date as expired. Failures
+// are logged and the quotation is returned unchanged.
+func (s *Service) expire(ctx context.Context, q query.Quotation) query.Quotation {
+	expired, err := s.store.UpdateQuotationStatus(ctx, query.UpdateQuotationStatusParams{
+		ID:         q.ID,
+		Status:     string(StatusExpired),
+		FromStatus: q.Status,
+	})
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Error("Error expiring quotation", "error", err, "quotation_id", q.ID)
+		}
+		return q
+	}
+	s.logActivity(ctx, &expired, uuid.Nil, "quotation.expired",
+		map[string]any{"status": q.Status},
+		map[string]any{"status": expired.Status},
+		nil,
+	)
+	return expired
+}
+
+// template returns an active template belonging to the agency
+func (s *Service) template(ctx context.Context, agencyID, id uuid.UUID) (*query.QuotationTemplate, error) {
+	tmpl, err := s.store.SelectQuotationTemplate(ctx, id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.InternalError{Message: "Error selecting quotation template", Err: err}
+	}
+	if err != nil || tmpl.AgencyID != agencyID || !tmpl.IsActive {
+		return nil, pkg.NotFoundError{Message: "Quotation template not found", Err: err}
+	}
+	return &tmpl, nil
+}
+
+func (s *Service) profile(ctx context.Context, agencyID uuid.UUID) (query.AgencyProfile, error) {
+	profile, err := s.store.SelectAgencyProfile(ctx, agencyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return profile, pkg.NotFoundError{Message: "Agency profile not found", Err: err}
+		}
+		return profile, pkg.InternalError{Message: "Error selecting agency profile", Err: err}
+	}
+	return profile, nil
+}
+
+func (s *Service) sections(ctx context.Context, quotationID uuid.UUID) ([]query.QuotationScopeSection, error) {
+	sections, err := s.store.SelectQuotationScopeSections(ctx, quotationID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting quotation sections", Err: err}
+	}
+	if sections == nil {
+		sections = []query.QuotationScopeSection{}
+	}
+	return sections, nil
+}
+
+func (s *Service) insertSection(ctx context.Context, params query.InsertQuotationScopeSectionParams) (*query.QuotationScopeSection, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating section ID", Err: err}
+	}
+	params.ID = id
+	section, err := s.store.InsertQuotationScopeSection(ctx, params)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error inserting quotation section", Err: err}
+	}
+	return &section, nil
+}
+
+// recalculate recomputes and stores a quotation's totals from its sections
+func (s *Service) recalculate(ctx context.Context, q query.Quotation) (*Detail, error) {
+	sections, err := s.sections(ctx, q.ID)
+	if err != nil {
+		return nil, err
+	}
+	t, err := CalculateTotals(sections, q.DiscountAmount, q.GstRegistered, q.GstRate, s.cfg.Rounding)
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: "Quotation amounts must share a currency", Err: err}
+	}
+	q, err = s.store.UpdateQuotationTotals(ctx, query.UpdateQuotationTotalsParams{
+		ID:        q.ID,
+		Subtotal:  t.Subtotal,
+		GstAmount: t.GST,
+		Total:     t.Total,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating quotation totals", Err: err}
+	}
+	return &Detail{Quotation: q, Sections: sections}, nil
+}
+
+// logActivity records a change to a quotation in the agency activity log
+func (s *Service) logActivity(
+	ctx context.Context,
+	q *query.Quotation,
+	userID uuid.UUID,
+	action string,
+	oldValues any,
+	newValues any,
+	metadata any,
+) {
+	activity.Log(ctx, s.store, activity.Entry{
+		AgencyID:   q.AgencyID,
+		UserID:     userID,
+		Action:     action,
+		EntityType: "quotation",
+		EntityID:   q.ID,
+		OldValues:  oldValues,
+		NewValues:  newValues,
+		Metadata:   metadata,
+	})
+}
@@ -0,0 +1,130 @@
+package quotation
+
+import (
+	"app/pkg"
+	"app/pkg/money"
+	"fmt"
+	"net/mail"
+	"time"
+)
+
+type schema struct {
+	clientBusinessName string
+	clientEmail        string
+	preparedDate       time.Time
+	expiryDate         time.Time
+	discountAmount     money.Money
+	sections           []SectionRequest
+}
+
+func validate(s *schema) error {
+	var errors pkg.ValidationErrors
+	if s.clientBusinessName == "" {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "clientBusinessName",
+			Tag:     "required",
+			Message: "Client business name is required",
+		})
+	}
+	if _, err := mail.ParseAddress(s.clientEmail); err != nil {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "clientEmail",
+			Tag:     "email",
+			Message: "Client email must be a valid email address",
+		})
+	}
+	if s.expiryDate.Before(s.preparedDate) {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "expiryDate",
+			Tag:     "gtefield",
+			Message: "Expiry date cannot be before the prepared date",
+		})
+	}
+	if s.discountAmount.IsNegative() {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "discountAmount",
+			Tag:     "min",
+			Message: "Discount cannot be negative",
+		})
+	}
+
+	amounts := []money.Money{s.discountAmount}
+	for _, section := range s.sections {
+		amounts = append(amounts, section.SectionPrice)
+	}
+	if err := money.SameCurrency(amounts...); err != nil {
+		return pkg.BadRequestError{Message: "Quotation amounts must share a currency", Err: err}
+	}
+
+	var subtotal money.Money
+	for i, section := range s.sections {
+		field := fmt.Sprintf("sections[%d].", i)
+		if section.Title == "" {
+			errors = append(errors, pkg.ValidationError{
+				Field:   field + "title",
+				Tag:     "required",
+				Message: "Section title is required",
+			})
+		}
+		if section.SectionPrice.IsNegative() {
+			errors = append(errors, pkg.ValidationError{
+				Field:   field + "sectionPrice",
+				Tag:     "min",
+				Message: "Section price cannot be negative",
+			})
+		}
+		subtotal = subtotal.Add(section.SectionPrice)
+	}
+	if s.sections != nil && s.discountAmount.Cmp(subtotal) > 0 {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "discountAmount",
+			Tag:     "max",
+			Message: "Discount cannot exceed the quotation subtotal",
+		})
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+func validateAccept(req AcceptRequest) error {
+	var errors pkg.ValidationErrors
+	if req.Name == "" {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "acceptedByName",
+			Tag:     "required",
+			Message: "Name is required to accept the quotation",
+		})
+	}
+	if len(req.Name) > 255 {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "acceptedByName",
+			Tag:     "max",
+			Message: "Name must be at most 255 characters",
+		})
+	}
+	if len(req.Title) > 255 {
+		errors = append(errors, pkg.ValidationError{
+			Field:   "acceptedByTitle",
+			Tag:     "max",
+			Message: "Title must be at most 255 characters",
+		})
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+func validateDecline(req DeclineRequest) error {
+	if len(req.Reason) > 2000 {
+		return pkg.ValidationErrors{{
+			Field:   "reason",
+			Tag:     "max",
+			Message: "Reason must be at most 2000 characters",
+		}}
+	}
+	return nil
+}
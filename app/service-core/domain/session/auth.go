@@ -0,0 +1,55 @@
+package session
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuthService refuses access tokens whose session has been revoked, so a
+// revoked session is signed out at once rather than when its access token
+// expires
+type AuthService struct {
+	auth.AuthService
+	sessions *Service
+	timeout  time.Duration
+}
+
+// NewAuthService wraps an auth service to check the session of each token
+func NewAuthService(authService auth.AuthService, sessions *Service, timeout time.Duration) *AuthService {
+	return &AuthService{
+		AuthService: authService,
+		sessions:    sessions,
+		timeout:     timeout,
+	}
+}
+
+// Auth authenticates a request made with an access token of an active
+// session and checks it has the access required
+func (a *AuthService) Auth(token string, access int64) (*auth.AccessTokenClaims, error) {
+	claims, err := a.AuthService.Auth(token, access)
+	if err != nil {
+		return nil, err
+	}
+	// Tokens issued before sessions were added have none to check
+	if claims.SessionID == uuid.Nil {
+		return claims, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+	active, err := a.sessions.Active(ctx, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, pkg.UnauthorizedError{Err: errors.New("session has been revoked")}
+	}
+	return claims, nil
+}
+
+// Ensure AuthService implements the auth.AuthService interface
+var _ auth.AuthService = (*AuthService)(nil)
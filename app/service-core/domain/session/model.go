@@ -0,0 +1,95 @@
+package session
+
+import (
+	"service-core/storage/query"
+	"strings"
+	"time"
+)
+
+// Client describes the device a session was signed in from
+type Client struct {
+	UserAgent string
+	IP        string
+}
+
+// Session is a signed-in device. Its ID is shared by every refresh token
+// issued to the device since it signed in.
+type Session struct {
+	ID        string    `json:"id"`
+	Device    string    `json:"device"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	StartedAt time.Time `json:"startedAt"`
+	LastSeen  time.Time `json:"lastSeen"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Current   bool      `json:"current"`
+}
+
+func toSession(t query.SelectUserSessionsRow, current string) Session {
+	return Session{
+		ID:        t.Family,
+		Device:    Device(t.UserAgent),
+		IP:        t.Ip,
+		UserAgent: t.UserAgent,
+		StartedAt: t.Started,
+		LastSeen:  t.Created,
+		ExpiresAt: t.Expires,
+		Current:   t.Family == current,
+	}
+}
+
+// browsers are checked in order, as most browsers' user agents also name
+// the browsers they are built on
+var browsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+var systems = []struct{ token, name string }{
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"CrOS", "ChromeOS"},
+	{"Macintosh", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// Device describes a user agent in a few words, such as "Chrome on macOS".
+// Clients that are not browsers are named by their first product token.
+func Device(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+	browser := ""
+	for _, b := range browsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	system := ""
+	for _, s := range systems {
+		if strings.Contains(userAgent, s.token) {
+			system = s.name
+			break
+		}
+	}
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+	product, _, _ := strings.Cut(userAgent, "/")
+	product, _, _ = strings.Cut(product, " ")
+	return product
+}
@@ -0,0 +1,89 @@
+package session
+
+import (
+	"app/pkg"
+	"context"
+	"errors"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// store defines the database interface for session operations
+type store interface {
+	SelectUserSessions(ctx context.Context, userID string) ([]query.SelectUserSessionsRow, error)
+	SelectSessionActive(ctx context.Context, family string) (bool, error)
+	RevokeUserSession(ctx context.Context, arg query.RevokeUserSessionParams) (int64, error)
+	RevokeUserSessions(ctx context.Context, arg query.RevokeUserSessionsParams) error
+}
+
+// Service lists and revokes the sessions users are signed in with
+type Service struct {
+	store store
+}
+
+// NewService creates a new session service
+func NewService(store store) *Service {
+	return &Service{store: store}
+}
+
+// List returns a user's active sessions, most recently seen first. The
+// session the request was made with is marked current.
+func (s *Service) List(ctx context.Context, userID, current uuid.UUID) ([]Session, error) {
+	rows, err := s.store.SelectUserSessions(ctx, userID.String())
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting sessions", Err: err}
+	}
+	sessions := make([]Session, len(rows))
+	for i, row := range rows {
+		sessions[i] = toSession(row, sessionKey(current))
+	}
+	return sessions, nil
+}
+
+// Revoke signs a user out of one of their sessions. Requests made with the
+// session fail from then on, without waiting for its tokens to expire.
+func (s *Service) Revoke(ctx context.Context, userID, id uuid.UUID) error {
+	n, err := s.store.RevokeUserSession(ctx, query.RevokeUserSessionParams{
+		Family: id.String(),
+		UserID: userID.String(),
+	})
+	if err != nil {
+		return pkg.InternalError{Message: "Error revoking session", Err: err}
+	}
+	if n == 0 {
+		return pkg.NotFoundError{Message: "Session not found", Err: errors.New("no tokens in session")}
+	}
+	return nil
+}
+
+// RevokeAll signs a user out of all their sessions but keep, or out of all
+// of them when keep is nil
+func (s *Service) RevokeAll(ctx context.Context, userID, keep uuid.UUID) error {
+	err := s.store.RevokeUserSessions(ctx, query.RevokeUserSessionsParams{
+		UserID:     userID.String(),
+		KeepFamily: sessionKey(keep),
+	})
+	if err != nil {
+		return pkg.InternalError{Message: "Error revoking sessions", Err: err}
+	}
+	return nil
+}
+
+// Active reports whether a session has not been revoked or expired
+func (s *Service) Active(ctx context.Context, id uuid.UUID) (bool, error) {
+	active, err := s.store.SelectSessionActive(ctx, id.String())
+	if err != nil {
+		return false, pkg.InternalError{Message: "Error selecting session", Err: err}
+	}
+	return active, nil
+}
+
+// sessionKey returns the family the tokens of a session are stored under.
+// The nil ID matches no session.
+func sessionKey(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
@@ -0,0 +1,112 @@
+package session_test
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"errors"
+	"service-core/domain/session"
+	"service-core/storage/query"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type mockStore struct {
+	active map[string]bool
+}
+
+func (m *mockStore) SelectUserSessions(ctx context.Context, userID string) ([]query.SelectUserSessionsRow, error) {
+	return nil, nil
+}
+
+func (m *mockStore) SelectSessionActive(ctx context.Context, family string) (bool, error) {
+	return m.active[family], nil
+}
+
+func (m *mockStore) RevokeUserSession(ctx context.Context, arg query.RevokeUserSessionParams) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStore) RevokeUserSessions(ctx context.Context, arg query.RevokeUserSessionsParams) error {
+	return nil
+}
+
+// mockAuthService accepts any token, taking it as the session ID, or as no
+// session when it is not one
+type mockAuthService struct {
+	auth.AuthService
+}
+
+func (m *mockAuthService) Auth(token string, access int64) (*auth.AccessTokenClaims, error) {
+	sessionID, _ := uuid.Parse(token)
+	return &auth.AccessTokenClaims{ID: uuid.New(), SessionID: sessionID}, nil
+}
+
+func TestAuth(t *testing.T) {
+	t.Parallel()
+	active, revoked := uuid.New(), uuid.New()
+	store := &mockStore{active: map[string]bool{active.String(): true}}
+	a := session.NewAuthService(&mockAuthService{}, session.NewService(store), time.Second)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "active session", token: active.String()},
+		{name: "revoked session", token: revoked.String(), wantErr: true},
+		{name: "token without a session", token: "legacy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := a.Auth(tt.token, 0)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("Auth() error = %v", err)
+				}
+				return
+			}
+			var unauthorized pkg.UnauthorizedError
+			if !errors.As(err, &unauthorized) {
+				t.Errorf("Auth() error = %v, want UnauthorizedError", err)
+			}
+		})
+	}
+}
+
+func TestRevokeMissing(t *testing.T) {
+	t.Parallel()
+	s := session.NewService(&mockStore{})
+	err := s.Revoke(context.Background(), uuid.New(), uuid.New())
+	var notFound pkg.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("Revoke() error = %v, want NotFoundError", err)
+	}
+}
+
+func TestDevice(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		userAgent string
+		want      string
+	}{
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", "Chrome on macOS"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0", "Edge on Windows"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1", "Safari on iOS"},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", "Firefox on Linux"},
+		{"Mozilla/5.0 (Linux; Android 14) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36", "Chrome on Android"},
+		{"curl/8.4.0", "curl"},
+		{"", "Unknown device"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+			if got := session.Device(tt.userAgent); got != tt.want {
+				t.Errorf("Device(%q) = %q, want %q", tt.userAgent, got, tt.want)
+			}
+		})
+	}
+}
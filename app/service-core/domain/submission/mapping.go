@@ -0,0 +1,262 @@
+package submission
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"fmt"
+	"service-core/domain/client"
+	"service-core/domain/consultation"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Mapping maps consultation fields, by their JSON name such as
+// "businessName", to the names of the form fields that answer them.
+// Consultation fields left out of the mapping are answered by a form field
+// with the same name in camel or snake case, such as "business_name".
+type Mapping map[string]string
+
+// textFields are the consultation fields answered with text
+var textFields = []string{
+	"businessName",
+	"contactPerson",
+	"email",
+	"phone",
+	"website",
+	"socialLinkedin",
+	"socialFacebook",
+	"socialInstagram",
+	"industry",
+	"businessType",
+	"websiteStatus",
+	"urgencyLevel",
+	"conversionGoal",
+	"budgetRange",
+	"timeline",
+	"consultationNotes",
+}
+
+// listFields are the consultation fields answered with a list
+var listFields = []string{
+	"primaryChallenges",
+	"primaryGoals",
+	"designStyles",
+	"admiredWebsites",
+}
+
+// parseMapping reads a stored mapping, treating an empty value as no mapping
+func parseMapping(raw json.RawMessage) (Mapping, error) {
+	m := Mapping{}
+	if len(raw) == 0 || string(raw) == "null" {
+		return m, nil
+	}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// validate checks that the mapping names known consultation fields and
+// fields of the form
+func (m Mapping) validate(formFields map[string]bool) error {
+	known := map[string]bool{}
+	for _, f := range textFields {
+		known[f] = true
+	}
+	for _, f := range listFields {
+		known[f] = true
+	}
+	var errors pkg.ValidationErrors
+	for field, name := range m {
+		switch {
+		case !known[field]:
+			errors = append(errors, pkg.ValidationError{
+				Field:   field,
+				Tag:     "oneof",
+				Message: fmt.Sprintf("%q is not a consultation field", field),
+			})
+		case !formFields[name]:
+			errors = append(errors, pkg.ValidationError{
+				Field:   field,
+				Tag:     "exists",
+				Message: fmt.Sprintf("The form has no field named %q", name),
+			})
+		}
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+// source returns the form field answering a consultation field
+func (m Mapping) source(field string, data map[string]any) (string, bool) {
+	if name, ok := m[field]; ok {
+		_, answered := data[name]
+		return name, answered
+	}
+	for _, name := range []string{field, snakeCase(field)} {
+		if _, ok := data[name]; ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Request builds a completed consultation request from submission
+// data. Answers that no consultation field uses are kept in custom data so
+// nothing the client entered is lost.
+func (m Mapping) Request(data map[string]any) consultation.CreateRequest {
+	used := map[string]bool{}
+	var req consultation.CreateRequest
+	texts := map[string]**string{
+		"businessName":      &req.BusinessName,
+		"contactPerson":     &req.ContactPerson,
+		"email":             &req.Email,
+		"phone":             &req.Phone,
+		"website":           &req.Website,
+		"socialLinkedin":    &req.SocialLinkedin,
+		"socialFacebook":    &req.SocialFacebook,
+		"socialInstagram":   &req.SocialInstagram,
+		"industry":          &req.Industry,
+		"businessType":      &req.BusinessType,
+		"websiteStatus":     &req.WebsiteStatus,
+		"urgencyLevel":      &req.UrgencyLevel,
+		"conversionGoal":    &req.ConversionGoal,
+		"budgetRange":       &req.BudgetRange,
+		"timeline":          &req.Timeline,
+		"consultationNotes": &req.ConsultationNotes,
+	}
+	for _, field := range textFields {
+		name, ok := m.source(field, data)
+		if !ok {
+			continue
+		}
+		used[name] = true
+		if s := text(data[name]); s != "" {
+			*texts[field] = &s
+		}
+	}
+	lists := map[string]**json.RawMessage{
+		"primaryChallenges": &req.PrimaryChallenges,
+		"primaryGoals":      &req.PrimaryGoals,
+		"designStyles":      &req.DesignStyles,
+		"admiredWebsites":   &req.AdmiredWebsites,
+	}
+	for _, field := range listFields {
+		name, ok := m.source(field, data)
+		if !ok {
+			continue
+		}
+		used[name] = true
+		if b, ok := list(data[name]); ok {
+			*lists[field] = &b
+		}
+	}
+
+	custom := map[string]any{}
+	for name, v := range data {
+		if !used[name] {
+			custom[name] = v
+		}
+	}
+	if len(custom) > 0 {
+		if b, err := json.Marshal(custom); err == nil {
+			raw := json.RawMessage(b)
+			req.CustomData = &raw
+		}
+	}
+	status := consultation.StatusCompleted
+	req.Status = &status
+	return req
+}
+
+// clientRequest returns the client details from a consultation request.
+// Clients need a business name, so the contact's name or email stands in
+// when the form did not ask for one.
+func clientRequest(req consultation.CreateRequest) client.CreateRequest {
+	value := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return strings.TrimSpace(*s)
+	}
+	c := client.CreateRequest{
+		BusinessName: value(req.BusinessName),
+		Email:        value(req.Email),
+		Phone:        value(req.Phone),
+		ContactName:  value(req.ContactPerson),
+	}
+	if c.BusinessName == "" {
+		c.BusinessName = c.ContactName
+	}
+	if c.BusinessName == "" {
+		c.BusinessName = c.Email
+	}
+	return c
+}
+
+// text returns an answer as text, joining lists with commas
+func text(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case []any:
+		parts := make([]string, 0, len(x))
+		for _, item := range x {
+			if s := text(item); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// list returns an answer as a JSON list, wrapping a single answer
+func list(v any) (json.RawMessage, bool) {
+	var items []any
+	switch x := v.(type) {
+	case nil:
+		return nil, false
+	case []any:
+		items = x
+	case string:
+		if strings.TrimSpace(x) == "" {
+			return nil, false
+		}
+		items = []any{x}
+	default:
+		items = []any{x}
+	}
+	if len(items) == 0 {
+		return nil, false
+	}
+	b, err := json.Marshal(items)
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package submission_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"service-core/domain/submission"
+	"testing"
+)
+
+func TestMappingRequest(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		mapping submission.Mapping
+		data    string
+		want    string
+	}{
+		{
+			name: "same names",
+			data: `{"businessName":"Harbour Cafe","email":"hello@harbour.test","primary_goals":"More bookings","budget_range":"5k-10k"}`,
+			want: `{"businessName":"Harbour Cafe","email":"hello@harbour.test","primaryGoals":["More bookings"],"budgetRange":"5k-10k","status":"completed"}`,
+		},
+		{
+			name:    "mapped fields",
+			mapping: submission.Mapping{"businessName": "company", "email": "contact_email", "designStyles": "styles"},
+			data:    `{"company":"Harbour Cafe","contact_email":"hello@harbour.test","styles":["minimal","bold"],"pages":5}`,
+			want:    `{"businessName":"Harbour Cafe","email":"hello@harbour.test","designStyles":["minimal","bold"],"customData":{"pages":5},"status":"completed"}`,
+		},
+		{
+			name:    "mapping wins over same name",
+			mapping: submission.Mapping{"phone": "mobile"},
+			data:    `{"phone":"02 9000 0000","mobile":"0400 000 000"}`,
+			want:    `{"phone":"0400 000 000","customData":{"phone":"02 9000 0000"},"status":"completed"}`,
+		},
+		{
+			name: "empty answers",
+			data: `{"businessName":"  ","primaryChallenges":[],"timeline":null}`,
+			want: `{"status":"completed"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var data map[string]any
+			if err := json.Unmarshal([]byte(tt.data), &data); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			b, err := json.Marshal(tt.mapping.Request(data))
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			var got, want map[string]any
+			_ = json.Unmarshal(b, &got)
+			_ = json.Unmarshal([]byte(tt.want), &want)
+			for k, v := range got {
+				if v == nil {
+					delete(got, k)
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Request() = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package submission
+
+import (
+	"encoding/json"
+	"service-core/storage/query"
+)
+
+// Status is the lifecycle state of a form submission
+type Status string
+
+const (
+	StatusDraft      Status = "draft"
+	StatusCompleted  Status = "completed"
+	StatusProcessing Status = "processing"
+	StatusProcessed  Status = "processed"
+	StatusArchived   Status = "archived"
+)
+
+// CompleteRequest is the final data of a public form submission
+type CompleteRequest struct {
+	Data json.RawMessage `json:"data"`
+}
+
+// Result is a processed submission with the records it was processed
+// into. Replayed reports that the submission had already been processed
+// and nothing changed.
+type Result struct {
+	Submission    query.FormSubmission `json:"submission"`
+	ClientCreated bool                 `json:"clientCreated"`
+	Replayed      bool                 `json:"replayed"`
+}
+
+// BatchResult counts the outcome of processing waiting submissions
+type BatchResult struct {
+	Processed int `json:"processed"`
+	Failed    int `json:"failed"`
+}
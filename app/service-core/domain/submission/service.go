@@ -0,0 +1,335 @@
+package submission
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"service-core/domain/activity"
+	"service-core/domain/client"
+	"service-core/domain/consultation"
+	"service-core/domain/form"
+	"service-core/storage/query"
+
+	"github.com/google/uuid"
+)
+
+// MaxAttempts is how many times a submission is processed automatically
+// before it is left for a manual replay
+const MaxAttempts = 5
+
+// BatchSize is how many waiting submissions a task run processes
+const BatchSize = 50
+
+// store defines the database interface for submission operations
+type store interface {
+	SelectAgencyForm(ctx context.Context, id uuid.UUID) (query.AgencyForm, error)
+	UpdateAgencyFormMapping(ctx context.Context, arg query.UpdateAgencyFormMappingParams) (query.AgencyForm, error)
+	SelectFormSubmission(ctx context.Context, id uuid.UUID) (query.FormSubmission, error)
+	SelectUnprocessedFormSubmissions(ctx context.Context, arg query.SelectUnprocessedFormSubmissionsParams) ([]query.FormSubmission, error)
+	CompleteFormSubmission(ctx context.Context, arg query.CompleteFormSubmissionParams) (query.FormSubmission, error)
+	UpdateFormSubmissionFailed(ctx context.Context, arg query.UpdateFormSubmissionFailedParams) error
+}
+
+// transactor runs a function in a database transaction
+type transactor interface {
+	InTx(ctx context.Context, fn func(q query.Querier) error) error
+}
+
+// Service completes form submissions and processes them into clients and
+// consultations
+type Service struct {
+	tx                  transactor
+	store               store
+	formService         *form.Service
+	clientService       *client.Service
+	consultationService *consultation.Service
+}
+
+// NewService creates a new submission service
+func NewService(
+	tx transactor,
+	store store,
+	formService *form.Service,
+	clientService *client.Service,
+	consultationService *consultation.Service,
+) *Service {
+	return &Service{
+		tx:                  tx,
+		store:               store,
+		formService:         formService,
+		clientService:       clientService,
+		consultationService: consultationService,
+	}
+}
+
+// UpdateMapping sets which form fields answer which consultation fields
+// when the form's submissions are processed
+func (s *Service) UpdateMapping(ctx context.Context, agencyID, formID uuid.UUID, mapping Mapping) (*query.AgencyForm, error) {
+	f, schema, err := s.formService.Load(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	// Forms from other agencies are reported as missing rather than forbidden
+	if f.AgencyID != agencyID {
+		return nil, pkg.NotFoundError{Message: "Form not found", Err: fmt.Errorf("form %s belongs to another agency", formID)}
+	}
+	if mapping == nil {
+		mapping = Mapping{}
+	}
+	if err := mapping.validate(schema.Names()); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(mapping)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error encoding mapping", Err: err}
+	}
+	updated, err := s.store.UpdateAgencyFormMapping(ctx, query.UpdateAgencyFormMappingParams{
+		ID:                  formID,
+		ConsultationMapping: b,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating form mapping", Err: err}
+	}
+	return &updated, nil
+}
+
+// Complete submits a draft from a public form. The data is validated
+// against the form version the draft is on, and the submission is then
+// processed. A processing failure does not fail the submission: it is
+// recorded for a retry.
+func (s *Service) Complete(ctx context.Context, id uuid.UUID, req CompleteRequest) (*query.FormSubmission, error) {
+	sub, err := s.store.SelectFormSubmission(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Submission not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting submission", Err: err}
+	}
+	if Status(sub.Status) != StatusDraft {
+		return nil, pkg.BadRequestError{
+			Message: "Submission has already been completed",
+			Err:     fmt.Errorf("submission %s is %s", id, sub.Status),
+		}
+	}
+	data, err := form.DecodeData(req.Data)
+	if err != nil {
+		return nil, err
+	}
+	var step int32
+	if sub.FormID.Valid {
+		_, schema, err := s.formService.LoadVersion(ctx, sub.FormID.UUID, sub.FormVersion)
+		if err != nil {
+			return nil, err
+		}
+		if err := schema.Validate(data, false); err != nil {
+			return nil, err
+		}
+		_, step = schema.Progress(data)
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error encoding submission data", Err: err}
+	}
+	sub, err = s.store.CompleteFormSubmission(ctx, query.CompleteFormSubmissionParams{
+		ID:          id,
+		Data:        b,
+		CurrentStep: step,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.BadRequestError{Message: "Submission has already been completed", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error completing submission", Err: err}
+	}
+
+	result, err := s.Process(ctx, uuid.Nil, uuid.Nil, id)
+	if err != nil {
+		slog.Error("Error processing submission", "error", err, "submission_id", id)
+		return &sub, nil
+	}
+	return &result.Submission, nil
+}
+
+// Process turns a completed submission into a client and a consultation
+// and marks it processed. Processing is idempotent: a processed submission
+// is returned unchanged, and a client or consultation already linked is
+// reused, so a failed run can be replayed. Failures are recorded on the
+// submission. A nil agency ID processes any agency's submission, for
+// system and admin use; a nil user ID attributes the consultation to the
+// form's creator or the agency owner.
+func (s *Service) Process(ctx context.Context, agencyID, userID, id uuid.UUID) (*Result, error) {
+	result, err := s.process(ctx, agencyID, userID, id)
+	if err != nil {
+		var notFound pkg.NotFoundError
+		if errors.As(err, &notFound) {
+			return nil, err
+		}
+		ferr := s.store.UpdateFormSubmissionFailed(ctx, query.UpdateFormSubmissionFailedParams{
+			ID:              id,
+			ProcessingError: err.Error(),
+		})
+		if ferr != nil {
+			slog.Error("Error recording submission failure", "error", ferr, "submission_id", id)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// ProcessPending processes completed submissions that are waiting, such as
+// those whose processing failed, up to limit at a time
+func (s *Service) ProcessPending(ctx context.Context, limit int32) (*BatchResult, error) {
+	if limit < 1 || limit > 100 {
+		limit = BatchSize
+	}
+	pending, err := s.store.SelectUnprocessedFormSubmissions(ctx, query.SelectUnprocessedFormSubmissionsParams{
+		MaxAttempts: MaxAttempts,
+		RowLimit:    limit,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting submissions", Err: err}
+	}
+	result := &BatchResult{}
+	for _, sub := range pending {
+		if _, err := s.Process(ctx, uuid.Nil, uuid.Nil, sub.ID); err != nil {
+			slog.Error("Error processing submission", "error", err, "submission_id", sub.ID)
+			result.Failed++
+			continue
+		}
+		result.Processed++
+	}
+	return result, nil
+}
+
+// process runs processTx in a transaction, so a failure leaves neither a
+// client, a consultation nor a processed submission behind
+func (s *Service) process(ctx context.Context, agencyID, userID, id uuid.UUID) (*Result, error) {
+	var result *Result
+	err := s.tx.InTx(ctx, func(q query.Querier) error {
+		var err error
+		result, err = s.processTx(ctx, q, agencyID, userID, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Service) processTx(ctx context.Context, q query.Querier, agencyID, userID, id uuid.UUID) (*Result, error) {
+	sub, err := q.SelectFormSubmissionForUpdate(ctx, id)
+	if err != nil || (agencyID != uuid.Nil && sub.AgencyID != agencyID) {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.NotFoundError{Message: "Submission not found", Err: err}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting submission", Err: err}
+	}
+	switch Status(sub.Status) {
+	case StatusProcessed:
+		return &Result{Submission: sub, Replayed: true}, nil
+	case StatusCompleted, StatusProcessing:
+	default:
+		return nil, pkg.BadRequestError{
+			Message: "Only completed submissions can be processed",
+			Err:     fmt.Errorf("submission %s is %s", id, sub.Status),
+		}
+	}
+
+	data, err := form.DecodeData(sub.Data)
+	if err != nil {
+		return nil, err
+	}
+	mapping := Mapping{}
+	var createdBy uuid.NullUUID
+	if sub.FormID.Valid {
+		f, err := q.SelectAgencyForm(ctx, sub.FormID.UUID)
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error selecting form", Err: err}
+		}
+		mapping, err = parseMapping(f.ConsultationMapping)
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error reading form mapping", Err: err}
+		}
+		createdBy = f.CreatedBy
+	}
+	if userID == uuid.Nil {
+		userID, err = s.owner(ctx, q, sub.AgencyID, createdBy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req := mapping.Request(data)
+	clientID, created := sub.ClientID.UUID, false
+	if !sub.ClientID.Valid {
+		c, isNew, err := s.clientService.FindOrCreate(ctx, q, sub.AgencyID, clientRequest(req))
+		if err != nil {
+			return nil, err
+		}
+		clientID, created = c.ID, isNew
+	}
+	req.ClientID = &clientID
+
+	consultationID := sub.ConsultationID.UUID
+	if !sub.ConsultationID.Valid {
+		c, err := s.consultationService.Create(ctx, q, sub.AgencyID, userID, req)
+		if err != nil {
+			return nil, err
+		}
+		consultationID = c.ID
+	}
+
+	details := clientRequest(req)
+	sub, err = q.UpdateFormSubmissionProcessed(ctx, query.UpdateFormSubmissionProcessedParams{
+		ID:                 sub.ID,
+		ClientID:           clientID,
+		ConsultationID:     consultationID,
+		ClientBusinessName: details.BusinessName,
+		ClientEmail:        client.NormaliseEmail(details.Email),
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error updating submission", Err: err}
+	}
+
+	// The processed event is written with the submission so notifications
+	// are raised exactly once
+	err = insertActivity(ctx, q, &sub, "form_submission.processed", map[string]any{
+		"clientId":       clientID,
+		"consultationId": consultationID,
+		"clientCreated":  created,
+	})
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error logging submission activity", Err: err}
+	}
+	return &Result{Submission: sub, ClientCreated: created}, nil
+}
+
+// owner returns the user a system-processed submission is attributed to:
+// the form's creator, or else the agency owner
+func (s *Service) owner(ctx context.Context, q query.Querier, agencyID uuid.UUID, createdBy uuid.NullUUID) (uuid.UUID, error) {
+	if createdBy.Valid {
+		return createdBy.UUID, nil
+	}
+	id, err := q.SelectAgencyOwnerID(ctx, agencyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, pkg.BadRequestError{Message: "Agency has no owner to attribute the submission to", Err: err}
+		}
+		return uuid.Nil, pkg.InternalError{Message: "Error selecting agency owner", Err: err}
+	}
+	return id, nil
+}
+
+func insertActivity(ctx context.Context, q query.Querier, sub *query.FormSubmission, action string, newValues any) error {
+	return activity.Insert(ctx, q, activity.Entry{
+		AgencyID:   sub.AgencyID,
+		Action:     action,
+		EntityType: "form_submission",
+		EntityID:   sub.ID,
+		NewValues:  newValues,
+	})
+}
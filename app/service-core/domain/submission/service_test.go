@@ -0,0 +1,318 @@
+package submission_test
+
+import (
+	"app/pkg"
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"service-core/domain/client"
+	"service-core/domain/consultation"
+	"service-core/domain/form"
+	"service-core/domain/submission"
+	"service-core/storage/query"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var (
+	agencyID     = uuid.MustParse("00000000-0000-0000-0000-000000000100")
+	ownerID      = uuid.MustParse("00000000-0000-0000-0000-000000000010")
+	submissionID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+)
+
+type mockStore struct {
+	*query.Queries
+	submissions   map[uuid.UUID]query.FormSubmission
+	clients       map[uuid.UUID]query.Client
+	consultations map[uuid.UUID]query.Consultation
+	activity      []query.InsertActivityLogParams
+	// consultationErr fails inserting a consultation, after the client is
+	// written
+	consultationErr error
+}
+
+func newMockStore(sub query.FormSubmission) *mockStore {
+	return &mockStore{
+		submissions:   map[uuid.UUID]query.FormSubmission{sub.ID: sub},
+		clients:       map[uuid.UUID]query.Client{},
+		consultations: map[uuid.UUID]query.Consultation{},
+	}
+}
+
+// InTx runs fn against the store, restoring it if fn fails, as rolling back
+// would
+func (m *mockStore) InTx(ctx context.Context, fn func(q query.Querier) error) error {
+	submissions, clients, consultations, activity :=
+		maps.Clone(m.submissions), maps.Clone(m.clients), maps.Clone(m.consultations), slices.Clone(m.activity)
+	if err := fn(m); err != nil {
+		m.submissions, m.clients, m.consultations, m.activity = submissions, clients, consultations, activity
+		return err
+	}
+	return nil
+}
+
+func (m *mockStore) SelectFormSubmissionForUpdate(ctx context.Context, id uuid.UUID) (query.FormSubmission, error) {
+	sub, ok := m.submissions[id]
+	if !ok {
+		return query.FormSubmission{}, sql.ErrNoRows
+	}
+	return sub, nil
+}
+
+func (m *mockStore) SelectAgencyOwnerID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	return ownerID, nil
+}
+
+func (m *mockStore) SelectClientByEmail(ctx context.Context, arg query.SelectClientByEmailParams) (query.Client, error) {
+	for _, c := range m.clients {
+		if c.AgencyID == arg.AgencyID && c.Email == arg.Email {
+			return c, nil
+		}
+	}
+	return query.Client{}, sql.ErrNoRows
+}
+
+func (m *mockStore) InsertClient(ctx context.Context, arg query.InsertClientParams) (query.Client, error) {
+	c := query.Client{ID: arg.ID, AgencyID: arg.AgencyID, BusinessName: arg.BusinessName, Email: arg.Email}
+	m.clients[c.ID] = c
+	return c, nil
+}
+
+func (m *mockStore) InsertConsultation(ctx context.Context, arg query.InsertConsultationParams) (query.Consultation, error) {
+	if m.consultationErr != nil {
+		return query.Consultation{}, m.consultationErr
+	}
+	c := query.Consultation{ID: arg.ID, AgencyID: arg.AgencyID, ClientID: arg.ClientID, BusinessName: arg.BusinessName}
+	m.consultations[c.ID] = c
+	return c, nil
+}
+
+func (m *mockStore) UpdateConsultation(ctx context.Context, arg query.UpdateConsultationParams) (query.Consultation, error) {
+	return m.consultations[arg.ID], nil
+}
+
+func (m *mockStore) SelectLatestConsultationVersionNumber(ctx context.Context, id uuid.UUID) (int32, error) {
+	return 0, nil
+}
+
+func (m *mockStore) InsertConsultationVersion(ctx context.Context, arg query.InsertConsultationVersionParams) (query.ConsultationVersion, error) {
+	return query.ConsultationVersion{ID: arg.ID, ConsultationID: arg.ConsultationID}, nil
+}
+
+func (m *mockStore) UpdateFormSubmissionProcessed(ctx context.Context, arg query.UpdateFormSubmissionProcessedParams) (query.FormSubmission, error) {
+	sub := m.submissions[arg.ID]
+	sub.ClientID = uuid.NullUUID{UUID: arg.ClientID, Valid: true}
+	sub.ConsultationID = uuid.NullUUID{UUID: arg.ConsultationID, Valid: true}
+	sub.ClientBusinessName, sub.ClientEmail = arg.ClientBusinessName, arg.ClientEmail
+	sub.Status = string(submission.StatusProcessed)
+	sub.ProcessingAttempts++
+	sub.ProcessingError = ""
+	m.submissions[arg.ID] = sub
+	return sub, nil
+}
+
+func (m *mockStore) UpdateFormSubmissionFailed(ctx context.Context, arg query.UpdateFormSubmissionFailedParams) error {
+	sub := m.submissions[arg.ID]
+	sub.ProcessingAttempts++
+	sub.ProcessingError = arg.ProcessingError
+	m.submissions[arg.ID] = sub
+	return nil
+}
+
+func (m *mockStore) InsertActivityLog(ctx context.Context, arg query.InsertActivityLogParams) error {
+	m.activity = append(m.activity, arg)
+	return nil
+}
+
+func newService(store *mockStore) *submission.Service {
+	return submission.NewService(
+		store,
+		store,
+		form.NewService(nil, store),
+		client.NewService(store, store),
+		consultation.NewService(nil, store),
+	)
+}
+
+func TestProcess(t *testing.T) {
+	t.Parallel()
+	completed := query.FormSubmission{
+		ID:       submissionID,
+		AgencyID: agencyID,
+		Data:     []byte(`{"businessName":"Harbour Cafe","email":"Hello@Harbour.test"}`),
+		Status:   string(submission.StatusCompleted),
+	}
+	existing := query.Client{
+		ID:           uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+		AgencyID:     agencyID,
+		BusinessName: "Harbour Cafe",
+		Email:        "hello@harbour.test",
+	}
+
+	tests := []struct {
+		name            string
+		sub             query.FormSubmission
+		clients         []query.Client
+		consultationErr error
+		runs            int
+		wantErr         error
+		wantStatus      submission.Status
+		wantCreated     bool
+		wantReplayed    bool
+		wantClients     int
+		wantActivity    int
+		wantAttempts    int32
+	}{
+		{
+			name:         "creates a client and consultation",
+			sub:          completed,
+			runs:         1,
+			wantStatus:   submission.StatusProcessed,
+			wantCreated:  true,
+			wantClients:  1,
+			wantActivity: 1,
+			wantAttempts: 1,
+		},
+		{
+			name:         "processing twice creates one client",
+			sub:          completed,
+			runs:         2,
+			wantStatus:   submission.StatusProcessed,
+			wantReplayed: true,
+			wantClients:  1,
+			wantActivity: 1,
+			wantAttempts: 1,
+		},
+		{
+			name:         "reuses the client with the email",
+			sub:          completed,
+			clients:      []query.Client{existing},
+			runs:         1,
+			wantStatus:   submission.StatusProcessed,
+			wantClients:  1,
+			wantActivity: 1,
+			wantAttempts: 1,
+		},
+		{
+			name:            "failure rolls back",
+			sub:             completed,
+			consultationErr: errors.New("connection reset"),
+			runs:            1,
+			wantErr:         pkg.InternalError{},
+			wantStatus:      submission.StatusCompleted,
+			wantAttempts:    1,
+		},
+		{
+			name: "drafts are not processed",
+			sub: query.FormSubmission{
+				ID:       submissionID,
+				AgencyID: agencyID,
+				Data:     completed.Data,
+				Status:   string(submission.StatusDraft),
+			},
+			runs:         1,
+			wantErr:      pkg.BadRequestError{},
+			wantStatus:   submission.StatusDraft,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newMockStore(tt.sub)
+			for _, c := range tt.clients {
+				store.clients[c.ID] = c
+			}
+			store.consultationErr = tt.consultationErr
+			s := newService(store)
+
+			var result *submission.Result
+			var err error
+			for range tt.runs {
+				result, err = s.Process(context.Background(), uuid.Nil, uuid.Nil, submissionID)
+			}
+			switch tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("Process() error = %v", err)
+				}
+				if result.ClientCreated != tt.wantCreated || result.Replayed != tt.wantReplayed {
+					t.Errorf("Process() created %v, replayed %v, want %v, %v",
+						result.ClientCreated, result.Replayed, tt.wantCreated, tt.wantReplayed)
+				}
+			case pkg.InternalError:
+				var internal pkg.InternalError
+				if !errors.As(err, &internal) {
+					t.Fatalf("Process() error = %v, want internal", err)
+				}
+			case pkg.BadRequestError:
+				var badRequest pkg.BadRequestError
+				if !errors.As(err, &badRequest) {
+					t.Fatalf("Process() error = %v, want bad request", err)
+				}
+			}
+
+			sub := store.submissions[submissionID]
+			if submission.Status(sub.Status) != tt.wantStatus || sub.ProcessingAttempts != tt.wantAttempts {
+				t.Errorf("submission is %s after %d attempts, want %s after %d",
+					sub.Status, sub.ProcessingAttempts, tt.wantStatus, tt.wantAttempts)
+			}
+			if tt.wantErr != nil && sub.ProcessingError == "" {
+				t.Error("failure was not recorded on the submission")
+			}
+			if len(store.clients) != tt.wantClients {
+				t.Errorf("%d clients, want %d", len(store.clients), tt.wantClients)
+			}
+			wantConsultations := 0
+			if tt.wantErr == nil {
+				wantConsultations = 1
+			}
+			if len(store.consultations) != wantConsultations {
+				t.Errorf("%d consultations, want %d", len(store.consultations), wantConsultations)
+			}
+			if len(store.activity) != tt.wantActivity {
+				t.Errorf("%d activity entries, want %d", len(store.activity), tt.wantActivity)
+			}
+			if tt.wantErr == nil {
+				for _, c := range store.clients {
+					if sub.ClientID.UUID != c.ID || store.consultations[sub.ConsultationID.UUID].ClientID.UUID != c.ID {
+						t.Errorf("submission and consultation are not linked to client %s", c.ID)
+					}
+				}
+			}
+		})
+	}
+}
+
+// A submission whose processing failed is processed in full on a retry,
+// since the failed run left nothing behind
+func TestProcessRetryAfterFailure(t *testing.T) {
+	t.Parallel()
+	store := newMockStore(query.FormSubmission{
+		ID:       submissionID,
+		AgencyID: agencyID,
+		Data:     []byte(`{"businessName":"Harbour Cafe","email":"hello@harbour.test"}`),
+		Status:   string(submission.StatusCompleted),
+	})
+	store.consultationErr = errors.New("connection reset")
+	s := newService(store)
+
+	if _, err := s.Process(context.Background(), uuid.Nil, uuid.Nil, submissionID); err == nil {
+		t.Fatal("Process() error = nil, want the consultation failure")
+	}
+	store.consultationErr = nil
+	result, err := s.Process(context.Background(), uuid.Nil, uuid.Nil, submissionID)
+	if err != nil {
+		t.Fatalf("retry error = %v", err)
+	}
+	if !result.ClientCreated || len(store.clients) != 1 || len(store.consultations) != 1 {
+		t.Errorf("retry created %v with %d clients and %d consultations, want one of each",
+			result.ClientCreated, len(store.clients), len(store.consultations))
+	}
+	if sub := store.submissions[submissionID]; sub.ProcessingAttempts != 2 || sub.ProcessingError != "" {
+		t.Errorf("submission has %d attempts and error %q, want 2 and none", sub.ProcessingAttempts, sub.ProcessingError)
+	}
+}
@@ -0,0 +1,41 @@
+package twofactor
+
+import "time"
+
+// Issuer names the app in users' authenticator apps
+const Issuer = "Webkit"
+
+// RecoveryCodeCount is how many recovery codes a user is given at a time
+const RecoveryCodeCount = 10
+
+// MaxFailedAttempts is how many invalid codes in a row lock a user's codes
+// for LockoutDuration
+const MaxFailedAttempts = 5
+
+// LockoutDuration is how long a user's codes are locked after too many
+// invalid ones
+const LockoutDuration = 15 * time.Minute
+
+// CodeRequest carries a code from the user's authenticator app, or one of
+// their recovery codes where either is accepted
+type CodeRequest struct {
+	Code string `json:"code"`
+}
+
+// Status describes a user's two-factor enrolment
+type Status struct {
+	TOTPEnabled            bool  `json:"totpEnabled"`
+	RecoveryCodesRemaining int64 `json:"recoveryCodesRemaining"`
+}
+
+// Setup is a pending authenticator app enrolment. The URI is shown as a QR
+// code, and the secret for apps that cannot scan one.
+type Setup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// RecoveryCodes are new recovery codes, which are only ever shown once
+type RecoveryCodes struct {
+	Codes []string `json:"codes"`
+}
@@ -0,0 +1,65 @@
+package twofactor
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// sealedPrefix marks an encrypted secret. Secrets stored before encryption
+// was added are plain base32, which never contains it.
+const sealedPrefix = "v1:"
+
+// sealSecret encrypts a user's TOTP secret with AES-256-GCM. The user's ID
+// is authenticated with it, so a secret cannot be moved to another user.
+func sealSecret(key []byte, userID uuid.UUID, secret string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(secret), userID[:])
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openSecret decrypts a user's stored TOTP secret, and reports whether it
+// was stored in plain text
+func openSecret(key []byte, userID uuid.UUID, stored string) (string, bool, error) {
+	encoded, ok := strings.CutPrefix(stored, sealedPrefix)
+	if !ok {
+		return stored, true, nil
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", false, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", false, errors.New("sealed secret is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	secret, err := aead.Open(nil, nonce, ciphertext, userID[:])
+	if err != nil {
+		return "", false, err
+	}
+	return string(secret), false, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
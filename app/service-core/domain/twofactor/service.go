@@ -0,0 +1,363 @@
+package twofactor
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"service-core/config"
+	"service-core/storage/query"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// store defines the database interface for two-factor operations
+type store interface {
+	SelectUserTOTP(ctx context.Context, userID uuid.UUID) (query.UserTotp, error)
+	UpsertPendingUserTOTP(ctx context.Context, arg query.UpsertPendingUserTOTPParams) (query.UserTotp, error)
+	UpdateUserTOTPStep(ctx context.Context, arg query.UpdateUserTOTPStepParams) (int64, error)
+	UpdateUserTOTPSecret(ctx context.Context, arg query.UpdateUserTOTPSecretParams) error
+	UseUserRecoveryCode(ctx context.Context, arg query.UseUserRecoveryCodeParams) (int64, error)
+	RecordUserTOTPFailure(ctx context.Context, arg query.RecordUserTOTPFailureParams) error
+	ResetUserTOTPFailures(ctx context.Context, userID uuid.UUID) error
+	CountUserRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+// Service enrols users in TOTP two-factor authentication and checks their
+// codes at sign-in. Secrets are stored encrypted with the configured key.
+type Service struct {
+	cfg   *config.Config
+	db    *sql.DB
+	store store
+}
+
+// NewService creates a new two-factor service
+func NewService(cfg *config.Config, db *sql.DB, store store) *Service {
+	return &Service{cfg: cfg, db: db, store: store}
+}
+
+// Status returns a user's two-factor enrolment
+func (s *Service) Status(ctx context.Context, userID uuid.UUID) (*Status, error) {
+	enabled, err := s.Enabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	remaining, err := s.store.CountUserRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error counting recovery codes", Err: err}
+	}
+	return &Status{TOTPEnabled: enabled, RecoveryCodesRemaining: remaining}, nil
+}
+
+// Enabled reports whether a user must complete sign-in with TOTP
+func (s *Service) Enabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	totp, err := s.store.SelectUserTOTP(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, pkg.InternalError{Message: "Error selecting two-factor enrolment", Err: err}
+	}
+	return totp.EnabledAt.Valid, nil
+}
+
+// Setup starts enrolling a user with a new secret, replacing any pending
+// one. Enrolment completes when a code from the secret is confirmed with
+// Enable.
+func (s *Service) Setup(ctx context.Context, userID uuid.UUID, account string) (*Setup, error) {
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error generating secret", Err: err}
+	}
+	sealed, err := sealSecret(s.cfg.TOTPEncryptionKey, userID, secret)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error encrypting secret", Err: err}
+	}
+	_, err = s.store.UpsertPendingUserTOTP(ctx, query.UpsertPendingUserTOTPParams{
+		UserID: userID,
+		Secret: sealed,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.BadRequestError{Message: "Two-factor authentication is already enabled", Err: err}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error starting two-factor enrolment", Err: err}
+	}
+	return &Setup{Secret: secret, URI: auth.TOTPURI(Issuer, account, secret)}, nil
+}
+
+// Enable completes enrolment with a code from the pending secret and
+// returns the user's recovery codes
+func (s *Service) Enable(ctx context.Context, userID uuid.UUID, code string) (*RecoveryCodes, error) {
+	totp, err := s.store.SelectUserTOTP(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.BadRequestError{Message: "Two-factor enrolment has not been started", Err: err}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error selecting two-factor enrolment", Err: err}
+	}
+	if totp.EnabledAt.Valid {
+		return nil, pkg.BadRequestError{Message: "Two-factor authentication is already enabled", Err: fmt.Errorf("user %s is enrolled", userID)}
+	}
+	secret, _, err := openSecret(s.cfg.TOTPEncryptionKey, userID, totp.Secret)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error decrypting secret", Err: err}
+	}
+	step, ok := auth.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return nil, invalidCode()
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error starting two-factor enrolment", Err: err}
+	}
+	defer tx.Rollback()
+	q := query.New(tx)
+	_, err = q.EnableUserTOTP(ctx, query.EnableUserTOTPParams{UserID: userID, LastStep: step})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pkg.BadRequestError{Message: "Two-factor authentication is already enabled", Err: err}
+	}
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error enabling two-factor authentication", Err: err}
+	}
+	codes, err := replaceRecoveryCodes(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, pkg.InternalError{Message: "Error enabling two-factor authentication", Err: err}
+	}
+	return codes, nil
+}
+
+// Disable removes a user's enrolment and recovery codes, given a code from
+// their authenticator app or a recovery code
+func (s *Service) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	ok, err := s.check(ctx, userID, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return invalidCode()
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return pkg.InternalError{Message: "Error disabling two-factor authentication", Err: err}
+	}
+	defer tx.Rollback()
+	q := query.New(tx)
+	if err := q.DeleteUserTOTP(ctx, userID); err != nil {
+		return pkg.InternalError{Message: "Error disabling two-factor authentication", Err: err}
+	}
+	if err := q.DeleteUserRecoveryCodes(ctx, userID); err != nil {
+		return pkg.InternalError{Message: "Error deleting recovery codes", Err: err}
+	}
+	if err := tx.Commit(); err != nil {
+		return pkg.InternalError{Message: "Error disabling two-factor authentication", Err: err}
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces a user's recovery codes, given a code
+// from their authenticator app or a recovery code
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) (*RecoveryCodes, error) {
+	ok, err := s.check(ctx, userID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, invalidCode()
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, pkg.InternalError{Message: "Error replacing recovery codes", Err: err}
+	}
+	defer tx.Rollback()
+	codes, err := replaceRecoveryCodes(ctx, query.New(tx), userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, pkg.InternalError{Message: "Error replacing recovery codes", Err: err}
+	}
+	return codes, nil
+}
+
+// Verify checks the code a user completes sign-in with: a code from their
+// authenticator app, or one of their recovery codes. Either is accepted
+// only once.
+func (s *Service) Verify(ctx context.Context, userID uuid.UUID, code string) error {
+	ok, err := s.check(ctx, userID, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return pkg.UnauthorizedError{Err: errors.New("invalid two-factor code")}
+	}
+	return nil
+}
+
+// check reports whether a code is a valid, unused code of an enrolled user,
+// and uses it up if so. Invalid codes are counted, and too many in a row
+// lock the user's codes for a while.
+func (s *Service) check(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	totp, err := s.store.SelectUserTOTP(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, pkg.BadRequestError{Message: "Two-factor authentication is not enabled", Err: err}
+	}
+	if err != nil {
+		return false, pkg.InternalError{Message: "Error selecting two-factor enrolment", Err: err}
+	}
+	if !totp.EnabledAt.Valid {
+		return false, pkg.BadRequestError{Message: "Two-factor authentication is not enabled", Err: fmt.Errorf("user %s is not enrolled", userID)}
+	}
+	if totp.LockedUntil.Valid && time.Now().Before(totp.LockedUntil.Time) {
+		return false, pkg.TooManyRequestsError{
+			Message: "Too many invalid codes, try again later",
+			Err:     fmt.Errorf("user %s is locked until %s", userID, totp.LockedUntil.Time.Format(time.RFC3339)),
+		}
+	}
+
+	secret, err := s.secret(ctx, totp)
+	if err != nil {
+		return false, err
+	}
+	ok, err := s.use(ctx, totp.UserID, secret, code)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		err := s.store.RecordUserTOTPFailure(ctx, query.RecordUserTOTPFailureParams{
+			UserID:      userID,
+			MaxAttempts: MaxFailedAttempts,
+			LockedUntil: time.Now().Add(LockoutDuration),
+		})
+		if err != nil {
+			return false, pkg.InternalError{Message: "Error recording invalid code", Err: err}
+		}
+		return false, nil
+	}
+	if totp.FailedAttempts > 0 {
+		if err := s.store.ResetUserTOTPFailures(ctx, userID); err != nil {
+			return false, pkg.InternalError{Message: "Error recording two-factor code", Err: err}
+		}
+	}
+	return true, nil
+}
+
+// secret decrypts a user's TOTP secret. A secret stored in plain text,
+// before encryption was added, is encrypted in its place.
+func (s *Service) secret(ctx context.Context, totp query.UserTotp) (string, error) {
+	secret, plain, err := openSecret(s.cfg.TOTPEncryptionKey, totp.UserID, totp.Secret)
+	if err != nil {
+		return "", pkg.InternalError{Message: "Error decrypting secret", Err: err}
+	}
+	if !plain {
+		return secret, nil
+	}
+	sealed, err := sealSecret(s.cfg.TOTPEncryptionKey, totp.UserID, secret)
+	if err != nil {
+		return "", pkg.InternalError{Message: "Error encrypting secret", Err: err}
+	}
+	err = s.store.UpdateUserTOTPSecret(ctx, query.UpdateUserTOTPSecretParams{UserID: totp.UserID, Secret: sealed})
+	if err != nil {
+		return "", pkg.InternalError{Message: "Error encrypting secret", Err: err}
+	}
+	return secret, nil
+}
+
+// use uses up a code from the user's authenticator app or one of their
+// recovery codes, reporting whether it was valid and unused
+func (s *Service) use(ctx context.Context, userID uuid.UUID, secret string, code string) (bool, error) {
+	if step, ok := auth.ValidateTOTP(secret, code, time.Now()); ok {
+		// Only a later step than the last accepted one is recorded, so a
+		// code seen before is refused
+		n, err := s.store.UpdateUserTOTPStep(ctx, query.UpdateUserTOTPStepParams{UserID: userID, LastStep: step})
+		if err != nil {
+			return false, pkg.InternalError{Message: "Error recording two-factor code", Err: err}
+		}
+		return n == 1, nil
+	}
+
+	n, err := s.store.UseUserRecoveryCode(ctx, query.UseUserRecoveryCodeParams{
+		UserID:   userID,
+		CodeHash: hashRecoveryCode(code),
+	})
+	if err != nil {
+		return false, pkg.InternalError{Message: "Error using recovery code", Err: err}
+	}
+	if n == 1 {
+		slog.Info("Recovery code used", "user_id", userID)
+	}
+	return n == 1, nil
+}
+
+func invalidCode() error {
+	return pkg.ValidationErrors{{
+		Field:   "code",
+		Tag:     "code",
+		Message: "Invalid code",
+	}}
+}
+
+// recoveryEncoding writes recovery codes in lower case base32, which has no
+// characters that are easily confused
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// replaceRecoveryCodes replaces a user's recovery codes with new ones
+func replaceRecoveryCodes(ctx context.Context, q *query.Queries, userID uuid.UUID) (*RecoveryCodes, error) {
+	if err := q.DeleteUserRecoveryCodes(ctx, userID); err != nil {
+		return nil, pkg.InternalError{Message: "Error deleting recovery codes", Err: err}
+	}
+	codes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error generating recovery code", Err: err}
+		}
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error generating UUID", Err: err}
+		}
+		err = q.InsertUserRecoveryCode(ctx, query.InsertUserRecoveryCodeParams{
+			ID:       id,
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(code),
+		})
+		if err != nil {
+			return nil, pkg.InternalError{Message: "Error inserting recovery code", Err: err}
+		}
+		codes[i] = code
+	}
+	return &RecoveryCodes{Codes: codes}, nil
+}
+
+// generateRecoveryCode returns a random code of 50 bits, written as two
+// groups of five characters
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := recoveryEncoding.EncodeToString(b)[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// hashRecoveryCode hashes a recovery code as the user may have typed it,
+// ignoring case, spaces and dashes
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,308 @@
+package twofactor_test
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"service-core/config"
+	"service-core/domain/twofactor"
+	"service-core/storage/query"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type mockStore struct {
+	mu    sync.Mutex
+	totp  map[uuid.UUID]query.UserTotp
+	codes map[string]bool
+}
+
+func (m *mockStore) SelectUserTOTP(ctx context.Context, userID uuid.UUID) (query.UserTotp, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.totp[userID]
+	if !ok {
+		return query.UserTotp{}, sql.ErrNoRows
+	}
+	return t, nil
+}
+
+func (m *mockStore) UpsertPendingUserTOTP(ctx context.Context, arg query.UpsertPendingUserTOTPParams) (query.UserTotp, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.totp[arg.UserID]; ok && t.EnabledAt.Valid {
+		return query.UserTotp{}, sql.ErrNoRows
+	}
+	t := query.UserTotp{UserID: arg.UserID, Secret: arg.Secret}
+	m.totp[arg.UserID] = t
+	return t, nil
+}
+
+func (m *mockStore) UpdateUserTOTPStep(ctx context.Context, arg query.UpdateUserTOTPStepParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.totp[arg.UserID]
+	if t.LastStep >= arg.LastStep {
+		return 0, nil
+	}
+	t.LastStep = arg.LastStep
+	m.totp[arg.UserID] = t
+	return 1, nil
+}
+
+func (m *mockStore) UseUserRecoveryCode(ctx context.Context, arg query.UseUserRecoveryCodeParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := arg.UserID.String() + arg.CodeHash
+	if !m.codes[key] {
+		return 0, nil
+	}
+	m.codes[key] = false
+	return 1, nil
+}
+
+func (m *mockStore) UpdateUserTOTPSecret(ctx context.Context, arg query.UpdateUserTOTPSecretParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.totp[arg.UserID]
+	t.Secret = arg.Secret
+	m.totp[arg.UserID] = t
+	return nil
+}
+
+func (m *mockStore) RecordUserTOTPFailure(ctx context.Context, arg query.RecordUserTOTPFailureParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.totp[arg.UserID]
+	t.FailedAttempts++
+	if t.FailedAttempts >= arg.MaxAttempts {
+		t.FailedAttempts = 0
+		t.LockedUntil = sql.NullTime{Time: arg.LockedUntil, Valid: true}
+	}
+	m.totp[arg.UserID] = t
+	return nil
+}
+
+func (m *mockStore) ResetUserTOTPFailures(ctx context.Context, userID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.totp[userID]
+	t.FailedAttempts = 0
+	m.totp[userID] = t
+	return nil
+}
+
+func (m *mockStore) CountUserRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+var cfg = &config.Config{TOTPEncryptionKey: make([]byte, 32)}
+
+func hash(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+	enrolled, pending, other := uuid.New(), uuid.New(), uuid.New()
+	store := &mockStore{
+		totp: map[uuid.UUID]query.UserTotp{
+			enrolled: {UserID: enrolled, Secret: secret, EnabledAt: sql.NullTime{Time: time.Now(), Valid: true}},
+			pending:  {UserID: pending, Secret: secret},
+		},
+		codes: map[string]bool{enrolled.String() + hash("abcdefghij"): true},
+	}
+	s := twofactor.NewService(cfg, nil, store)
+	code, err := auth.TOTPCode(secret, auth.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatalf("TOTPCode() error = %v", err)
+	}
+
+	// The cases run in order, as codes are used up
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		code    string
+		wantErr error
+	}{
+		{name: "authenticator code", userID: enrolled, code: code},
+		{name: "authenticator code replayed", userID: enrolled, code: code, wantErr: pkg.UnauthorizedError{}},
+		{name: "wrong code", userID: enrolled, code: "not-a-code", wantErr: pkg.UnauthorizedError{}},
+		{name: "recovery code as typed", userID: enrolled, code: "ABCDE-FGHIJ"},
+		{name: "recovery code reused", userID: enrolled, code: "abcde-fghij", wantErr: pkg.UnauthorizedError{}},
+		{name: "pending enrolment", userID: pending, code: code, wantErr: pkg.BadRequestError{}},
+		{name: "not enrolled", userID: other, code: code, wantErr: pkg.BadRequestError{}},
+	}
+	for _, tt := range tests {
+		err := s.Verify(context.Background(), tt.userID, tt.code)
+		switch want := tt.wantErr.(type) {
+		case nil:
+			if err != nil {
+				t.Errorf("%s: Verify() error = %v", tt.name, err)
+			}
+		case pkg.UnauthorizedError:
+			if !errors.As(err, &want) {
+				t.Errorf("%s: Verify() error = %v, want UnauthorizedError", tt.name, err)
+			}
+		case pkg.BadRequestError:
+			if !errors.As(err, &want) {
+				t.Errorf("%s: Verify() error = %v, want BadRequestError", tt.name, err)
+			}
+		}
+	}
+}
+
+func TestVerifyLockout(t *testing.T) {
+	t.Parallel()
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+	userID := uuid.New()
+	store := &mockStore{totp: map[uuid.UUID]query.UserTotp{
+		userID: {UserID: userID, Secret: secret, EnabledAt: sql.NullTime{Time: time.Now(), Valid: true}},
+	}}
+	s := twofactor.NewService(cfg, nil, store)
+	code, err := auth.TOTPCode(secret, auth.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatalf("TOTPCode() error = %v", err)
+	}
+
+	for i := range twofactor.MaxFailedAttempts {
+		var unauthorized pkg.UnauthorizedError
+		if err := s.Verify(context.Background(), userID, "not-a-code"); !errors.As(err, &unauthorized) {
+			t.Fatalf("attempt %d: Verify() error = %v, want UnauthorizedError", i+1, err)
+		}
+	}
+
+	// Even a valid code is refused while the user is locked out
+	var tooMany pkg.TooManyRequestsError
+	if err := s.Verify(context.Background(), userID, code); !errors.As(err, &tooMany) {
+		t.Fatalf("Verify() error = %v, want TooManyRequestsError", err)
+	}
+	locked, _ := store.SelectUserTOTP(context.Background(), userID)
+	if !locked.LockedUntil.Valid || locked.LockedUntil.Time.Before(time.Now().Add(twofactor.LockoutDuration-time.Minute)) {
+		t.Errorf("LockedUntil = %v, want about %v from now", locked.LockedUntil, twofactor.LockoutDuration)
+	}
+
+	// Once the lockout ends, a valid code is accepted
+	store.mu.Lock()
+	locked.LockedUntil.Time = time.Now().Add(-time.Second)
+	store.totp[userID] = locked
+	store.mu.Unlock()
+	if err := s.Verify(context.Background(), userID, code); err != nil {
+		t.Errorf("Verify() after lockout error = %v", err)
+	}
+}
+
+func TestSetup(t *testing.T) {
+	t.Parallel()
+	enrolled, user := uuid.New(), uuid.New()
+	store := &mockStore{totp: map[uuid.UUID]query.UserTotp{
+		enrolled: {UserID: enrolled, Secret: "SECRET", EnabledAt: sql.NullTime{Time: time.Now(), Valid: true}},
+	}}
+	s := twofactor.NewService(cfg, nil, store)
+
+	t.Run("starts a pending enrolment", func(t *testing.T) {
+		t.Parallel()
+		setup, err := s.Setup(context.Background(), user, "user@example.com")
+		if err != nil {
+			t.Fatalf("Setup() error = %v", err)
+		}
+		if setup.Secret == "" || setup.URI != auth.TOTPURI(twofactor.Issuer, "user@example.com", setup.Secret) {
+			t.Errorf("Setup() = %+v", setup)
+		}
+		enabled, err := s.Enabled(context.Background(), user)
+		if err != nil || enabled {
+			t.Errorf("Enabled() = %v, %v, want false until a code is confirmed", enabled, err)
+		}
+	})
+
+	t.Run("keeps an enabled secret", func(t *testing.T) {
+		t.Parallel()
+		_, err := s.Setup(context.Background(), enrolled, "user@example.com")
+		var badRequest pkg.BadRequestError
+		if !errors.As(err, &badRequest) {
+			t.Errorf("Setup() error = %v, want BadRequestError", err)
+		}
+		if got, _ := store.SelectUserTOTP(context.Background(), enrolled); got.Secret != "SECRET" {
+			t.Error("enabled secret was replaced")
+		}
+	})
+}
+
+func TestSecretEncryption(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stores new secrets encrypted", func(t *testing.T) {
+		t.Parallel()
+		userID := uuid.New()
+		store := &mockStore{totp: map[uuid.UUID]query.UserTotp{}}
+		s := twofactor.NewService(cfg, nil, store)
+
+		setup, err := s.Setup(context.Background(), userID, "user@example.com")
+		if err != nil {
+			t.Fatalf("Setup() error = %v", err)
+		}
+		stored, _ := store.SelectUserTOTP(context.Background(), userID)
+		if strings.Contains(stored.Secret, setup.Secret) {
+			t.Fatalf("stored secret %q contains the secret in plain text", stored.Secret)
+		}
+
+		// The encrypted secret is only readable with the key
+		other := twofactor.NewService(&config.Config{TOTPEncryptionKey: []byte(strings.Repeat("k", 32))}, nil, store)
+		code, err := auth.TOTPCode(setup.Secret, auth.TOTPStep(time.Now()))
+		if err != nil {
+			t.Fatalf("TOTPCode() error = %v", err)
+		}
+		var internal pkg.InternalError
+		if _, err := other.Enable(context.Background(), userID, code); !errors.As(err, &internal) {
+			t.Errorf("Enable() with another key error = %v, want InternalError", err)
+		}
+	})
+
+	t.Run("encrypts a secret stored in plain text when it is used", func(t *testing.T) {
+		t.Parallel()
+		secret, err := auth.GenerateTOTPSecret()
+		if err != nil {
+			t.Fatalf("GenerateTOTPSecret() error = %v", err)
+		}
+		userID := uuid.New()
+		store := &mockStore{totp: map[uuid.UUID]query.UserTotp{
+			userID: {UserID: userID, Secret: secret, EnabledAt: sql.NullTime{Time: time.Now(), Valid: true}},
+		}}
+		s := twofactor.NewService(cfg, nil, store)
+		code, err := auth.TOTPCode(secret, auth.TOTPStep(time.Now()))
+		if err != nil {
+			t.Fatalf("TOTPCode() error = %v", err)
+		}
+
+		if err := s.Verify(context.Background(), userID, code); err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		stored, _ := store.SelectUserTOTP(context.Background(), userID)
+		if stored.Secret == secret {
+			t.Fatal("secret is still stored in plain text")
+		}
+		next, err := auth.TOTPCode(secret, auth.TOTPStep(time.Now())+1)
+		if err != nil {
+			t.Fatalf("TOTPCode() error = %v", err)
+		}
+		if err := s.Verify(context.Background(), userID, next); err != nil {
+			t.Errorf("Verify() with the encrypted secret error = %v", err)
+		}
+	})
+}
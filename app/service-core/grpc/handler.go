@@ -2,32 +2,47 @@ package grpc
 
 import (
 	"app/pkg/auth"
+	"service-core/config"
+	"service-core/domain/invoice"
 	"service-core/domain/login"
 	"service-core/domain/note"
+	"service-core/domain/proposal"
+	"service-core/domain/submission"
 	"service-core/domain/user"
-	"service-core/config"
 )
 
 type Handler struct {
-	cfg          *config.Config
-	authService  *auth.Service
-	loginService *login.Service
-	userService  *user.Service
-	noteService  *note.Service
+	cfg               *config.Config
+	store             membershipStore
+	authService       auth.AuthService
+	loginService      *login.Service
+	userService       *user.Service
+	noteService       *note.Service
+	proposalService   *proposal.Service
+	invoiceService    *invoice.Service
+	submissionService *submission.Service
 }
 
 func NewHandler(
 	cfg *config.Config,
-	authService *auth.Service,
+	store membershipStore,
+	authService auth.AuthService,
 	loginService *login.Service,
 	userService *user.Service,
 	noteService *note.Service,
+	proposalService *proposal.Service,
+	invoiceService *invoice.Service,
+	submissionService *submission.Service,
 ) *Handler {
 	return &Handler{
-		cfg:          cfg,
-		authService:  authService,
-		loginService: loginService,
-		userService:  userService,
-		noteService:  noteService,
+		cfg:               cfg,
+		store:             store,
+		authService:       authService,
+		loginService:      loginService,
+		userService:       userService,
+		noteService:       noteService,
+		proposalService:   proposalService,
+		invoiceService:    invoiceService,
+		submissionService: submissionService,
 	}
 }
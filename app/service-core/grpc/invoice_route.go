@@ -0,0 +1,454 @@
+package grpc
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"app/pkg/money"
+	"context"
+	"service-core/domain/invoice"
+	"service-core/storage/query"
+	"time"
+
+	pb "service-core/proto"
+
+	"github.com/google/uuid"
+)
+
+func (s *invoiceServer) GetInvoices(in *pb.InvoiceListRequest, stream pb.InvoiceService_GetInvoicesServer) error {
+	ctx := stream.Context()
+	user, err := s.handler.authAgency(ctx, auth.GetInvoices, in.GetAgencyId())
+	if err != nil {
+		return writeResponse(err)
+	}
+	r, err := s.handler.invoiceService.ListInvoices(ctx, user.AgencyID, in.GetStatus(), int32(in.GetPage()), int32(in.GetLimit()))
+	if err != nil {
+		return writeResponse(err)
+	}
+	for _, inv := range r.Invoices {
+		err := stream.Send(invoiceToPB(&inv, nil))
+		if err != nil {
+			return writeResponse(err)
+		}
+	}
+	return nil
+}
+
+func (s *invoiceServer) GetInvoiceByID(ctx context.Context, in *pb.InvoiceID) (*pb.Invoice, error) {
+	user, err := s.handler.authAgency(ctx, auth.GetInvoices, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetId(), "invoice")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	d, err := s.handler.invoiceService.GetInvoice(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return invoiceToPB(&d.Invoice, d.LineItems), nil
+}
+
+func (s *invoiceServer) CreateInvoice(ctx context.Context, in *pb.CreateInvoiceRequest) (*pb.Invoice, error) {
+	user, err := s.handler.authAgency(ctx, auth.CreateInvoice, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	req, err := createInvoiceRequestFromPB(in)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	d, err := s.handler.invoiceService.CreateInvoice(ctx, user, req)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return invoiceToPB(&d.Invoice, d.LineItems), nil
+}
+
+func (s *invoiceServer) CreateInvoiceFromProposal(ctx context.Context, in *pb.InvoiceSourceRequest) (*pb.Invoice, error) {
+	user, err := s.handler.authAgency(ctx, auth.CreateInvoice, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetSourceId(), "proposal")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	d, err := s.handler.invoiceService.CreateFromProposal(ctx, user, id)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return invoiceToPB(&d.Invoice, d.LineItems), nil
+}
+
+func (s *invoiceServer) CreateInvoiceFromContract(ctx context.Context, in *pb.InvoiceSourceRequest) (*pb.Invoice, error) {
+	user, err := s.handler.authAgency(ctx, auth.CreateInvoice, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetSourceId(), "contract")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	d, err := s.handler.invoiceService.CreateFromContract(ctx, user, id)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return invoiceToPB(&d.Invoice, d.LineItems), nil
+}
+
+func (s *invoiceServer) EditInvoice(ctx context.Context, in *pb.EditInvoiceRequest) (*pb.Invoice, error) {
+	user, err := s.handler.authAgency(ctx, auth.EditInvoice, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetId(), "invoice")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	req, err := editInvoiceRequestFromPB(in)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	d, err := s.handler.invoiceService.UpdateInvoice(ctx, user, id, req)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return invoiceToPB(&d.Invoice, d.LineItems), nil
+}
+
+func (s *invoiceServer) AddInvoiceLineItem(ctx context.Context, in *pb.InvoiceLineItemRequest) (*pb.Invoice, error) {
+	user, err := s.handler.authAgency(ctx, auth.EditInvoice, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetInvoiceId(), "invoice")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	req, err := lineItemRequestFromPB(in)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	d, err := s.handler.invoiceService.AddLineItem(ctx, user, id, req)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return invoiceToPB(&d.Invoice, d.LineItems), nil
+}
+
+func (s *invoiceServer) EditInvoiceLineItem(ctx context.Context, in *pb.InvoiceLineItemRequest) (*pb.Invoice, error) {
+	user, err := s.handler.authAgency(ctx, auth.EditInvoice, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetInvoiceId(), "invoice")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	itemID, err := uuid.Parse(in.GetId())
+	if err != nil {
+		return nil, writeResponse(pkg.BadRequestError{Message: "Invalid line item ID", Err: err})
+	}
+	req, err := lineItemRequestFromPB(in)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	d, err := s.handler.invoiceService.UpdateLineItem(ctx, user, id, itemID, req)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return invoiceToPB(&d.Invoice, d.LineItems), nil
+}
+
+func (s *invoiceServer) RemoveInvoiceLineItem(ctx context.Context, in *pb.InvoiceLineItemRequest) (*pb.Invoice, error) {
+	user, err := s.handler.authAgency(ctx, auth.EditInvoice, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetInvoiceId(), "invoice")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	itemID, err := uuid.Parse(in.GetId())
+	if err != nil {
+		return nil, writeResponse(pkg.BadRequestError{Message: "Invalid line item ID", Err: err})
+	}
+	d, err := s.handler.invoiceService.RemoveLineItem(ctx, user, id, itemID)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return invoiceToPB(&d.Invoice, d.LineItems), nil
+}
+
+func (s *invoiceServer) TransitionInvoice(ctx context.Context, in *pb.InvoiceStatusRequest) (*pb.Invoice, error) {
+	user, err := s.handler.authAgency(ctx, auth.EditInvoice, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetId(), "invoice")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	inv, err := s.handler.invoiceService.TransitionInvoice(ctx, user, id, invoice.TransitionRequest{
+		Status: invoice.Status(in.GetStatus()),
+	})
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return invoiceToPB(inv, nil), nil
+}
+
+func (s *invoiceServer) RecordInvoicePayment(ctx context.Context, in *pb.InvoicePaymentRequest) (*pb.Invoice, error) {
+	user, err := s.handler.authAgency(ctx, auth.EditInvoice, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetId(), "invoice")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	req := invoice.PaymentRequest{
+		Method:    invoice.PaymentMethod(in.GetMethod()),
+		Reference: in.GetReference(),
+		Notes:     in.GetNotes(),
+	}
+	if in.GetAmount() != "" {
+		amount, err := parseMoney(in.GetAmount(), "amount")
+		if err != nil {
+			return nil, writeResponse(err)
+		}
+		req.Amount = &amount
+	}
+	if in.GetPaidAt() != "" {
+		paidAt, err := parseTime(in.GetPaidAt(), "paid at")
+		if err != nil {
+			return nil, writeResponse(err)
+		}
+		req.PaidAt = &paidAt
+	}
+	inv, err := s.handler.invoiceService.RecordPayment(ctx, user, id, req)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return invoiceToPB(inv, nil), nil
+}
+
+func (s *invoiceServer) RemoveInvoice(ctx context.Context, in *pb.InvoiceID) (*pb.Empty, error) {
+	user, err := s.handler.authAgency(ctx, auth.RemoveInvoice, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetId(), "invoice")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	err = s.handler.invoiceService.DeleteInvoice(ctx, user, id)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func parseMoney(s, field string) (money.Money, error) {
+	m, err := money.Parse(s, money.DefaultCurrency)
+	if err != nil {
+		return m, pkg.BadRequestError{Message: "Invalid " + field, Err: err}
+	}
+	return m, nil
+}
+
+func parseTime(s, field string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return t, pkg.BadRequestError{Message: "Invalid " + field + " date", Err: err}
+	}
+	return t, nil
+}
+
+func parseOptionalUUID(s, label string) (*uuid.UUID, error) {
+	if s == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return nil, pkg.BadRequestError{Message: "Invalid " + label + " ID", Err: err}
+	}
+	return &id, nil
+}
+
+func lineItemRequestFromPB(in *pb.InvoiceLineItemRequest) (invoice.LineItemRequest, error) {
+	req := invoice.LineItemRequest{
+		Description: in.GetDescription(),
+		Quantity:    money.NewDecimal(1),
+		IsTaxable:   in.IsTaxable,
+		SortOrder:   in.SortOrder,
+		Category:    in.GetCategory(),
+	}
+	if in.GetQuantity() != "" {
+		q, err := money.ParseDecimal(in.GetQuantity())
+		if err != nil {
+			return req, pkg.BadRequestError{Message: "Invalid quantity", Err: err}
+		}
+		req.Quantity = q
+	}
+	var err error
+	if req.UnitPrice, err = parseMoney(in.GetUnitPrice(), "unit price"); err != nil {
+		return req, err
+	}
+	if req.PackageID, err = parseOptionalUUID(in.GetPackageId(), "package"); err != nil {
+		return req, err
+	}
+	if req.AddonID, err = parseOptionalUUID(in.GetAddonId(), "addon"); err != nil {
+		return req, err
+	}
+	return req, nil
+}
+
+func createInvoiceRequestFromPB(in *pb.CreateInvoiceRequest) (invoice.CreateRequest, error) {
+	req := invoice.CreateRequest{
+		ClientBusinessName:  in.GetClientBusinessName(),
+		ClientContactName:   in.GetClientContactName(),
+		ClientEmail:         in.GetClientEmail(),
+		ClientPhone:         in.GetClientPhone(),
+		ClientAddress:       in.GetClientAddress(),
+		ClientAbn:           in.GetClientAbn(),
+		PaymentTerms:        in.GetPaymentTerms(),
+		PaymentTermsCustom:  in.GetPaymentTermsCustom(),
+		Notes:               in.GetNotes(),
+		PublicNotes:         in.GetPublicNotes(),
+		DiscountDescription: in.GetDiscountDescription(),
+	}
+	var err error
+	if req.ClientID, err = parseOptionalUUID(in.GetClientId(), "client"); err != nil {
+		return req, err
+	}
+	if in.GetIssueDate() != "" {
+		t, err := parseTime(in.GetIssueDate(), "issue")
+		if err != nil {
+			return req, err
+		}
+		req.IssueDate = &t
+	}
+	if in.GetDueDate() != "" {
+		t, err := parseTime(in.GetDueDate(), "due")
+		if err != nil {
+			return req, err
+		}
+		req.DueDate = &t
+	}
+	if in.GetDiscountAmount() != "" {
+		if req.DiscountAmount, err = parseMoney(in.GetDiscountAmount(), "discount amount"); err != nil {
+			return req, err
+		}
+	}
+	for _, item := range in.GetLineItems() {
+		li, err := lineItemRequestFromPB(item)
+		if err != nil {
+			return req, err
+		}
+		req.LineItems = append(req.LineItems, li)
+	}
+	return req, nil
+}
+
+func editInvoiceRequestFromPB(in *pb.EditInvoiceRequest) (invoice.UpdateRequest, error) {
+	req := invoice.UpdateRequest{
+		ClientBusinessName:   in.ClientBusinessName,
+		ClientContactName:    in.ClientContactName,
+		ClientEmail:          in.ClientEmail,
+		ClientPhone:          in.ClientPhone,
+		ClientAddress:        in.ClientAddress,
+		ClientAbn:            in.ClientAbn,
+		PaymentTerms:         in.PaymentTerms,
+		PaymentTermsCustom:   in.PaymentTermsCustom,
+		Notes:                in.Notes,
+		PublicNotes:          in.PublicNotes,
+		DiscountDescription:  in.DiscountDescription,
+		OnlinePaymentEnabled: in.OnlinePaymentEnabled,
+	}
+	if in.IssueDate != nil {
+		t, err := parseTime(*in.IssueDate, "issue")
+		if err != nil {
+			return req, err
+		}
+		req.IssueDate = &t
+	}
+	if in.DueDate != nil {
+		t, err := parseTime(*in.DueDate, "due")
+		if err != nil {
+			return req, err
+		}
+		req.DueDate = &t
+	}
+	if in.DiscountAmount != nil {
+		amount, err := parseMoney(*in.DiscountAmount, "discount amount")
+		if err != nil {
+			return req, err
+		}
+		req.DiscountAmount = &amount
+	}
+	return req, nil
+}
+
+func invoiceToPB(inv *query.Invoice, items []query.InvoiceLineItem) *pb.Invoice {
+	out := &pb.Invoice{
+		Id:                   inv.ID.String(),
+		CreatedAt:            inv.CreatedAt.Format(timeFormat),
+		UpdatedAt:            inv.UpdatedAt.Format(timeFormat),
+		AgencyId:             inv.AgencyID.String(),
+		ProposalId:           nullUUID(inv.ProposalID),
+		ContractId:           nullUUID(inv.ContractID),
+		ClientId:             nullUUID(inv.ClientID),
+		InvoiceNumber:        inv.InvoiceNumber,
+		Slug:                 inv.Slug,
+		Status:               inv.Status,
+		ClientBusinessName:   inv.ClientBusinessName,
+		ClientContactName:    inv.ClientContactName,
+		ClientEmail:          inv.ClientEmail,
+		ClientPhone:          inv.ClientPhone,
+		ClientAddress:        inv.ClientAddress,
+		ClientAbn:            inv.ClientAbn,
+		IssueDate:            inv.IssueDate.Format(timeFormat),
+		DueDate:              inv.DueDate.Format(timeFormat),
+		Subtotal:             inv.Subtotal.String(),
+		DiscountAmount:       inv.DiscountAmount.String(),
+		DiscountDescription:  inv.DiscountDescription,
+		GstAmount:            inv.GstAmount.String(),
+		Total:                inv.Total.String(),
+		AmountPaid:           inv.AmountPaid.String(),
+		GstRegistered:        inv.GstRegistered,
+		GstRate:              inv.GstRate.String(),
+		PaymentTerms:         inv.PaymentTerms,
+		PaymentTermsCustom:   inv.PaymentTermsCustom,
+		Notes:                inv.Notes,
+		PublicNotes:          inv.PublicNotes,
+		ViewCount:            inv.ViewCount,
+		LastViewedAt:         nullTime(inv.LastViewedAt),
+		SentAt:               nullTime(inv.SentAt),
+		PaidAt:               nullTime(inv.PaidAt),
+		PaymentMethod:        inv.PaymentMethod.String,
+		PaymentReference:     inv.PaymentReference.String,
+		PaymentNotes:         inv.PaymentNotes.String,
+		PdfUrl:               inv.PdfUrl.String,
+		OnlinePaymentEnabled: inv.OnlinePaymentEnabled,
+		StripePaymentLinkUrl: inv.StripePaymentLinkUrl.String,
+		CreatedBy:            nullUUID(inv.CreatedBy),
+	}
+	for _, item := range items {
+		out.LineItems = append(out.LineItems, &pb.InvoiceLineItem{
+			Id:          item.ID.String(),
+			InvoiceId:   item.InvoiceID.String(),
+			Description: item.Description,
+			Quantity:    item.Quantity.String(),
+			UnitPrice:   item.UnitPrice.String(),
+			Amount:      item.Amount.String(),
+			IsTaxable:   item.IsTaxable,
+			SortOrder:   item.SortOrder,
+			Category:    item.Category.String,
+			PackageId:   nullUUID(item.PackageID),
+			AddonId:     nullUUID(item.AddonID),
+		})
+	}
+	return out
+}
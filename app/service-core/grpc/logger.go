@@ -1,6 +1,7 @@
 package grpc
 
 import (
+	"app/pkg"
 	"context"
 	"log/slog"
 	"time"
@@ -58,6 +59,7 @@ func SlogStreamServerInterceptor() grpc.StreamServerInterceptor {
 			slog.Duration("duration", duration),
 			slog.Bool("is_client_stream", info.IsClientStream),
 			slog.Bool("is_server_stream", info.IsServerStream),
+			slog.String("request_id", pkg.RequestIDFromContext(ctx)),
 		}
 
 		logLevel := slog.LevelInfo
@@ -122,6 +124,7 @@ func SlogUnaryServerInterceptor() grpc.UnaryServerInterceptor {
 			slog.String("remote_addr", clientIP),
 			slog.String("user_agent", userAgent),
 			slog.Bool("auth_token_present", authTokenPresent),
+			slog.String("request_id", pkg.RequestIDFromContext(ctx)),
 		}
 
 		logLevel := slog.LevelInfo
@@ -140,3 +143,74 @@ func SlogUnaryServerInterceptor() grpc.UnaryServerInterceptor {
 		return resp, err
 	}
 }
+
+// requestIDKey is the metadata key carrying the ID that ties a call to its
+// logs and errors
+const requestIDKey = "x-request-id"
+
+// requestID returns the caller's request ID, or a new one, and sends it
+// back in the response header
+func requestID(ctx context.Context) string {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDKey); len(ids) > 0 && len(ids[0]) <= 128 {
+			id = ids[0]
+		}
+	}
+	if id == "" {
+		id = pkg.NewRequestID()
+	}
+	if err := grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id)); err != nil {
+		slog.Error("Error setting request ID header", "error", err)
+	}
+	return id
+}
+
+// RequestIDUnaryServerInterceptor gives every call a request ID and adds
+// it to the details of failed calls
+func RequestIDUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		id := requestID(ctx)
+		resp, err := handler(pkg.WithRequestID(ctx, id), req)
+		if err != nil {
+			return resp, withRequestID(err, id)
+		}
+		return resp, nil
+	}
+}
+
+// RequestIDStreamServerInterceptor gives every stream a request ID and
+// adds it to the details of failed streams
+func RequestIDStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		id := requestID(ss.Context())
+		err := handler(srv, &requestIDStream{
+			ServerStream: ss,
+			ctx:          pkg.WithRequestID(ss.Context(), id),
+		})
+		if err != nil {
+			return withRequestID(err, id)
+		}
+		return nil
+	}
+}
+
+// requestIDStream is a server stream whose context carries the request ID
+type requestIDStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *requestIDStream) Context() context.Context {
+	return s.ctx
+}
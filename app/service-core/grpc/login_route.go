@@ -2,6 +2,7 @@ package grpc
 
 import (
 	"context"
+	"service-core/domain/session"
 	pb "service-core/proto"
 	"strings"
 
@@ -21,7 +22,9 @@ func (s *authServer) Refresh(ctx context.Context, _ *pb.Empty) (*pb.AuthResponse
 		return nil, status.Errorf(codes.InvalidArgument, "Invalid refresh token")
 	}
 
-	r, err := s.handler.loginService.Refresh(ctx, accessToken, refreshToken)
+	// The caller is a server acting for the browser, so the session keeps
+	// the device it was signed in with
+	r, err := s.handler.loginService.Refresh(ctx, accessToken, refreshToken, session.Client{})
 	if err != nil {
 		return nil, writeResponse(err)
 	}
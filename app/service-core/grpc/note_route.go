@@ -41,7 +41,7 @@ func (s *noteServer) GetAllNotes(_ *pb.Empty, stream pb.NoteService_GetAllNotesS
 
 func (s *noteServer) GetNoteByID(ctx context.Context, in *pb.ID) (*pb.Note, error) {
 	token := getToken(ctx)
-	_, err := s.handler.authService.Auth(token, auth.GetNotes)
+	user, err := s.handler.authService.Auth(token, auth.GetNotes)
 	if err != nil {
 		return nil, writeResponse(err)
 	}
@@ -49,7 +49,7 @@ func (s *noteServer) GetNoteByID(ctx context.Context, in *pb.ID) (*pb.Note, erro
 	if err != nil {
 		return nil, writeResponse(err)
 	}
-	note, err := s.handler.noteService.GetNoteByID(ctx, id)
+	note, err := s.handler.noteService.GetNoteByID(ctx, user.Attr(), id)
 	if err != nil {
 		return nil, writeResponse(err)
 	}
@@ -95,7 +95,7 @@ func (s *noteServer) EditNote(ctx context.Context, in *pb.NoteRequest) (*pb.Note
 	if err != nil {
 		return nil, writeResponse(err)
 	}
-	note, err := s.handler.noteService.EditNote(ctx, id, in.GetTitle(), in.GetCategory(), in.GetContent())
+	note, err := s.handler.noteService.EditNote(ctx, user.Attr(), id, in.GetTitle(), in.GetCategory(), in.GetContent())
 	if err != nil {
 		return nil, writeResponse(err)
 	}
@@ -112,7 +112,7 @@ func (s *noteServer) EditNote(ctx context.Context, in *pb.NoteRequest) (*pb.Note
 
 func (s *noteServer) RemoveNote(ctx context.Context, in *pb.ID) (*pb.Empty, error) {
 	token := getToken(ctx)
-	_, err := s.handler.authService.Auth(token, auth.RemoveNote)
+	user, err := s.handler.authService.Auth(token, auth.RemoveNote)
 	if err != nil {
 		return nil, writeResponse(err)
 	}
@@ -120,7 +120,7 @@ func (s *noteServer) RemoveNote(ctx context.Context, in *pb.ID) (*pb.Empty, erro
 	if err != nil {
 		return nil, writeResponse(err)
 	}
-	err = s.handler.noteService.RemoveNote(ctx, id)
+	err = s.handler.noteService.RemoveNote(ctx, user.Attr(), id)
 	if err != nil {
 		return nil, writeResponse(err)
 	}
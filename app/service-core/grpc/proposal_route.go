@@ -0,0 +1,290 @@
+package grpc
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"service-core/domain/proposal"
+	"service-core/storage/query"
+	"time"
+
+	pb "service-core/proto"
+
+	"github.com/google/uuid"
+)
+
+const timeFormat = "2006-01-02 15:04:05"
+
+func (s *proposalServer) GetProposals(in *pb.ProposalListRequest, stream pb.ProposalService_GetProposalsServer) error {
+	ctx := stream.Context()
+	user, err := s.handler.authAgency(ctx, auth.GetProposals, in.GetAgencyId())
+	if err != nil {
+		return writeResponse(err)
+	}
+	r, err := s.handler.proposalService.ListProposals(ctx, user.AgencyID, in.GetStatus(), int32(in.GetPage()), int32(in.GetLimit()))
+	if err != nil {
+		return writeResponse(err)
+	}
+	for _, p := range r.Proposals {
+		err := stream.Send(proposalToPB(&p))
+		if err != nil {
+			return writeResponse(err)
+		}
+	}
+	return nil
+}
+
+func (s *proposalServer) GetProposalByID(ctx context.Context, in *pb.ProposalID) (*pb.Proposal, error) {
+	user, err := s.handler.authAgency(ctx, auth.GetProposals, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetId(), "proposal")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	p, err := s.handler.proposalService.GetProposal(ctx, user.AgencyID, id)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return proposalToPB(p), nil
+}
+
+func (s *proposalServer) CreateProposal(ctx context.Context, in *pb.CreateProposalRequest) (*pb.Proposal, error) {
+	user, err := s.handler.authAgency(ctx, auth.CreateProposal, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	req := proposal.CreateRequest{Title: in.GetTitle()}
+	if in.GetConsultationId() != "" {
+		id, err := uuid.Parse(in.GetConsultationId())
+		if err != nil {
+			return nil, writeResponse(pkg.BadRequestError{Message: "Invalid consultation ID", Err: err})
+		}
+		req.ConsultationID = &id
+	}
+	if in.GetSelectedPackageId() != "" {
+		id, err := uuid.Parse(in.GetSelectedPackageId())
+		if err != nil {
+			return nil, writeResponse(pkg.BadRequestError{Message: "Invalid package ID", Err: err})
+		}
+		req.SelectedPackageID = &id
+	}
+	p, err := s.handler.proposalService.CreateProposal(ctx, user, req)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return proposalToPB(p), nil
+}
+
+func (s *proposalServer) EditProposal(ctx context.Context, in *pb.EditProposalRequest) (*pb.Proposal, error) {
+	user, err := s.handler.authAgency(ctx, auth.EditProposal, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetId(), "proposal")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	req, err := editRequestFromPB(in)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	p, err := s.handler.proposalService.UpdateProposal(ctx, user, id, req)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return proposalToPB(p), nil
+}
+
+func (s *proposalServer) UpdateProposalSection(ctx context.Context, in *pb.ProposalSectionRequest) (*pb.Proposal, error) {
+	user, err := s.handler.authAgency(ctx, auth.EditProposal, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetId(), "proposal")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	p, err := s.handler.proposalService.UpdateSection(ctx, user, id, proposal.Section(in.GetSection()), json.RawMessage(in.GetContent()))
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return proposalToPB(p), nil
+}
+
+func (s *proposalServer) DuplicateProposal(ctx context.Context, in *pb.ProposalID) (*pb.Proposal, error) {
+	user, err := s.handler.authAgency(ctx, auth.CreateProposal, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetId(), "proposal")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	p, err := s.handler.proposalService.DuplicateProposal(ctx, user, id)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return proposalToPB(p), nil
+}
+
+func (s *proposalServer) TransitionProposal(ctx context.Context, in *pb.ProposalStatusRequest) (*pb.Proposal, error) {
+	user, err := s.handler.authAgency(ctx, auth.EditProposal, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetId(), "proposal")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	p, err := s.handler.proposalService.TransitionProposal(ctx, user, id, proposal.TransitionRequest{
+		Status: proposal.Status(in.GetStatus()),
+		Notes:  in.GetNotes(),
+	})
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return proposalToPB(p), nil
+}
+
+func (s *proposalServer) RemoveProposal(ctx context.Context, in *pb.ProposalID) (*pb.Empty, error) {
+	user, err := s.handler.authAgency(ctx, auth.RemoveProposal, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetId(), "proposal")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	err = s.handler.proposalService.DeleteProposal(ctx, user, id)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func editRequestFromPB(in *pb.EditProposalRequest) (proposal.UpdateRequest, error) {
+	req := proposal.UpdateRequest{
+		ClientBusinessName:    in.ClientBusinessName,
+		ClientContactName:     in.ClientContactName,
+		ClientEmail:           in.ClientEmail,
+		ClientPhone:           in.ClientPhone,
+		ClientWebsite:         in.ClientWebsite,
+		Title:                 in.Title,
+		CoverImage:            in.CoverImage,
+		ExecutiveSummary:      in.ExecutiveSummary,
+		LocalAdvantageContent: in.LocalAdvantageContent,
+		ClosingContent:        in.ClosingContent,
+	}
+	if in.PerformanceData != nil {
+		req.PerformanceData = json.RawMessage(*in.PerformanceData)
+	}
+	if in.CurrentIssues != nil {
+		req.CurrentIssues = json.RawMessage(*in.CurrentIssues)
+	}
+	if in.ComplianceIssues != nil {
+		req.ComplianceIssues = json.RawMessage(*in.ComplianceIssues)
+	}
+	if in.PerformanceStandards != nil {
+		req.PerformanceStandards = json.RawMessage(*in.PerformanceStandards)
+	}
+	if in.SelectedPackageId != nil {
+		id := uuid.Nil
+		if *in.SelectedPackageId != "" {
+			parsed, err := uuid.Parse(*in.SelectedPackageId)
+			if err != nil {
+				return req, pkg.BadRequestError{Message: "Invalid package ID", Err: err}
+			}
+			id = parsed
+		}
+		req.SelectedPackageID = &id
+	}
+	if in.SelectedAddons != nil {
+		req.SelectedAddons = []uuid.UUID{}
+		if err := json.Unmarshal([]byte(*in.SelectedAddons), &req.SelectedAddons); err != nil {
+			return req, pkg.BadRequestError{Message: "Invalid selected addons", Err: err}
+		}
+	}
+	if in.CustomPricing != nil {
+		req.CustomPricing = &proposal.CustomPricing{}
+		if err := json.Unmarshal([]byte(*in.CustomPricing), req.CustomPricing); err != nil {
+			return req, pkg.BadRequestError{Message: "Invalid custom pricing", Err: err}
+		}
+	}
+	if in.ValidUntil != nil {
+		t := time.Time{}
+		if *in.ValidUntil != "" {
+			parsed, err := time.Parse(time.RFC3339, *in.ValidUntil)
+			if err != nil {
+				return req, pkg.BadRequestError{Message: "Invalid valid until date", Err: err}
+			}
+			t = parsed
+		}
+		req.ValidUntil = &t
+	}
+	return req, nil
+}
+
+func proposalToPB(p *query.Proposal) *pb.Proposal {
+	return &pb.Proposal{
+		Id:                    p.ID.String(),
+		CreatedAt:             p.CreatedAt.Format(timeFormat),
+		UpdatedAt:             p.UpdatedAt.Format(timeFormat),
+		AgencyId:              p.AgencyID.String(),
+		ConsultationId:        nullUUID(p.ConsultationID),
+		ClientId:              nullUUID(p.ClientID),
+		ProposalNumber:        p.ProposalNumber,
+		Slug:                  p.Slug,
+		Status:                p.Status,
+		ClientBusinessName:    p.ClientBusinessName,
+		ClientContactName:     p.ClientContactName,
+		ClientEmail:           p.ClientEmail,
+		ClientPhone:           p.ClientPhone,
+		ClientWebsite:         p.ClientWebsite,
+		Title:                 p.Title,
+		CoverImage:            p.CoverImage.String,
+		ExecutiveSummary:      p.ExecutiveSummary,
+		PerformanceData:       string(p.PerformanceData),
+		OpportunityContent:    p.OpportunityContent,
+		CurrentIssues:         string(p.CurrentIssues),
+		ComplianceIssues:      string(p.ComplianceIssues),
+		RoiAnalysis:           string(p.RoiAnalysis),
+		PerformanceStandards:  string(p.PerformanceStandards),
+		LocalAdvantageContent: p.LocalAdvantageContent,
+		ProposedPages:         string(p.ProposedPages),
+		Timeline:              string(p.Timeline),
+		ClosingContent:        p.ClosingContent,
+		NextSteps:             string(p.NextSteps),
+		SelectedPackageId:     nullUUID(p.SelectedPackageID),
+		SelectedAddons:        string(p.SelectedAddons),
+		CustomPricing:         string(p.CustomPricing.RawMessage),
+		ValidUntil:            nullTime(p.ValidUntil),
+		ViewCount:             p.ViewCount,
+		LastViewedAt:          nullTime(p.LastViewedAt),
+		SentAt:                nullTime(p.SentAt),
+		AcceptedAt:            nullTime(p.AcceptedAt),
+		DeclinedAt:            nullTime(p.DeclinedAt),
+		RevisionRequestedAt:   nullTime(p.RevisionRequestedAt),
+		ClientComments:        p.ClientComments,
+		DeclineReason:         p.DeclineReason,
+		RevisionRequestNotes:  p.RevisionRequestNotes,
+		CreatedBy:             nullUUID(p.CreatedBy),
+	}
+}
+
+func nullUUID(id uuid.NullUUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return id.UUID.String()
+}
+
+func nullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.Format(timeFormat)
+}
@@ -3,16 +3,18 @@ package grpc
 import (
 	"app/pkg"
 	"context"
-	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"strings"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/anypb"
 
 	pb "service-core/proto"
 )
@@ -34,6 +36,24 @@ type noteServer struct {
 	handler *Handler
 }
 
+type proposalServer struct {
+	pb.UnimplementedProposalServiceServer
+
+	handler *Handler
+}
+
+type invoiceServer struct {
+	pb.UnimplementedInvoiceServiceServer
+
+	handler *Handler
+}
+
+type submissionServer struct {
+	pb.UnimplementedSubmissionServiceServer
+
+	handler *Handler
+}
+
 func Run(handler *Handler) *grpc.Server {
 	cfg := handler.cfg
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%v", cfg.GRPCPort))
@@ -43,7 +63,10 @@ func Run(handler *Handler) *grpc.Server {
 	}
 	unaryLogger := SlogUnaryServerInterceptor()
 	streamLogger := SlogStreamServerInterceptor()
-	s := grpc.NewServer(grpc.UnaryInterceptor(unaryLogger), grpc.StreamInterceptor(streamLogger))
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(RequestIDUnaryServerInterceptor(), unaryLogger),
+		grpc.ChainStreamInterceptor(RequestIDStreamServerInterceptor(), streamLogger),
+	)
 	pb.RegisterAuthServiceServer(s, &authServer{
 		UnimplementedAuthServiceServer: pb.UnimplementedAuthServiceServer{},
 		handler:                        handler,
@@ -56,6 +79,18 @@ func Run(handler *Handler) *grpc.Server {
 		UnimplementedNoteServiceServer: pb.UnimplementedNoteServiceServer{},
 		handler:                        handler,
 	})
+	pb.RegisterProposalServiceServer(s, &proposalServer{
+		UnimplementedProposalServiceServer: pb.UnimplementedProposalServiceServer{},
+		handler:                            handler,
+	})
+	pb.RegisterInvoiceServiceServer(s, &invoiceServer{
+		UnimplementedInvoiceServiceServer: pb.UnimplementedInvoiceServiceServer{},
+		handler:                           handler,
+	})
+	pb.RegisterSubmissionServiceServer(s, &submissionServer{
+		UnimplementedSubmissionServiceServer: pb.UnimplementedSubmissionServiceServer{},
+		handler:                              handler,
+	})
 	go func() {
 		slog.Info("gRPC server listening on", "port", cfg.GRPCPort)
 		if err := s.Serve(lis); err != nil {
@@ -66,34 +101,99 @@ func Run(handler *Handler) *grpc.Server {
 	return s
 }
 
+// getToken returns the credential a call was made with: an access token,
+// or an API key sent as "ApiKey <key>"
 func getToken(ctx context.Context) string {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return ""
 	}
 	token := strings.Join(md.Get("Authorization"), "")
+	if key, ok := strings.CutPrefix(token, "ApiKey "); ok {
+		return strings.TrimSpace(key)
+	}
 	return token
 }
 
+// errorDomain names this service in the error details of failed calls
+const errorDomain = "service-core"
+
+// writeResponse converts an error to a gRPC status. The status carries the
+// stable error code as ErrorInfo details, and field violations for
+// validation errors, so clients can tell the kinds of failure apart.
 func writeResponse(err error) error {
-	if err != nil {
-		var unauthorizedError pkg.UnauthorizedError
-		if errors.As(err, &unauthorizedError) {
-			return status.Errorf(codes.Unauthenticated, "Unauthorized")
+	if err == nil {
+		return nil
+	}
+	problem := pkg.NewProblem(err)
+	st := status.New(grpcCode(problem.Code), problem.Detail)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason: problem.Code,
+		Domain: errorDomain,
+	}}
+	if len(problem.Errors) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, len(problem.Errors))
+		for i, e := range problem.Errors {
+			violations[i] = &errdetails.BadRequest_FieldViolation{
+				Field:       e.Field,
+				Description: e.Message,
+			}
 		}
-		var internalError pkg.InternalError
-		if errors.As(err, &internalError) {
-			return status.Errorf(codes.Internal, "Internal error: %s", internalError.Message)
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	withDetails, derr := st.WithDetails(details...)
+	if derr != nil {
+		slog.Error("Error adding error details", "error", derr)
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// grpcCode returns the gRPC status code for an error code
+func grpcCode(code string) codes.Code {
+	switch code {
+	case pkg.CodeUnauthorized:
+		return codes.Unauthenticated
+	case pkg.CodeForbidden:
+		return codes.PermissionDenied
+	case pkg.CodeNotFound:
+		return codes.NotFound
+	case pkg.CodeMethodNotAllowed:
+		return codes.Unimplemented
+	case pkg.CodeConflict:
+		return codes.Aborted
+	case pkg.CodeTooManyRequests:
+		return codes.ResourceExhausted
+	case pkg.CodeBadRequest, pkg.CodeValidation:
+		return codes.InvalidArgument
+	default:
+		return codes.Internal
+	}
+}
+
+// withRequestID adds the request ID to the ErrorInfo details of a failed
+// call's status
+func withRequestID(err error, requestID string) error {
+	st, ok := status.FromError(err)
+	if !ok || requestID == "" {
+		return err
+	}
+	p := st.Proto()
+	for i, d := range p.Details {
+		info := &errdetails.ErrorInfo{}
+		if !d.MessageIs(info) || d.UnmarshalTo(info) != nil {
+			continue
 		}
-		var notFoundError pkg.NotFoundError
-		if errors.As(err, &notFoundError) {
-			return status.Errorf(codes.NotFound, "Not found: %s", notFoundError.Message)
+		if info.Metadata == nil {
+			info.Metadata = map[string]string{}
 		}
-		var validationErrors pkg.ValidationErrors
-		if errors.As(err, &validationErrors) {
-			return status.Errorf(codes.InvalidArgument, "%s", validationErrors.Error())
+		info.Metadata["request_id"] = requestID
+		a, err := anypb.New(info)
+		if err != nil {
+			return st.Err()
 		}
-		return status.Errorf(codes.Internal, "Internal error: %s", err.Error())
+		p.Details[i] = a
+		return status.ErrorProto(p)
 	}
-	return nil
+	return err
 }
@@ -0,0 +1,48 @@
+package grpc
+
+import (
+	"app/pkg/auth"
+	"context"
+	"service-core/storage/query"
+
+	pb "service-core/proto"
+)
+
+func (s *submissionServer) ProcessSubmission(ctx context.Context, in *pb.SubmissionID) (*pb.ProcessSubmissionResponse, error) {
+	user, err := s.handler.authAgency(ctx, auth.EditForms, in.GetAgencyId())
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	id, err := parseID(in.GetId(), "submission")
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	r, err := s.handler.submissionService.Process(ctx, user.AgencyID, user.ID, id)
+	if err != nil {
+		return nil, writeResponse(err)
+	}
+	return &pb.ProcessSubmissionResponse{
+		Submission:    submissionToPB(&r.Submission),
+		ClientCreated: r.ClientCreated,
+		Replayed:      r.Replayed,
+	}, nil
+}
+
+func submissionToPB(sub *query.FormSubmission) *pb.FormSubmission {
+	return &pb.FormSubmission{
+		Id:                 sub.ID.String(),
+		CreatedAt:          sub.CreatedAt.Format(timeFormat),
+		AgencyId:           sub.AgencyID.String(),
+		FormId:             nullUUID(sub.FormID),
+		FormVersion:        sub.FormVersion,
+		ClientId:           nullUUID(sub.ClientID),
+		ConsultationId:     nullUUID(sub.ConsultationID),
+		Status:             sub.Status,
+		ClientBusinessName: sub.ClientBusinessName,
+		ClientEmail:        sub.ClientEmail,
+		SubmittedAt:        nullTime(sub.SubmittedAt),
+		ProcessedAt:        nullTime(sub.ProcessedAt),
+		ProcessingAttempts: sub.ProcessingAttempts,
+		ProcessingError:    sub.ProcessingError,
+	}
+}
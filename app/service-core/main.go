@@ -11,11 +11,25 @@ import (
 	"time"
 
 	"service-core/config"
+	"service-core/domain/apikey"
 	"service-core/domain/billing"
+	"service-core/domain/client"
+	"service-core/domain/consultation"
+	"service-core/domain/contract"
 	"service-core/domain/email"
 	"service-core/domain/file"
+	"service-core/domain/form"
+	"service-core/domain/invoice"
 	"service-core/domain/login"
 	"service-core/domain/note"
+	"service-core/domain/numbering"
+	"service-core/domain/passkey"
+	"service-core/domain/pdf"
+	"service-core/domain/proposal"
+	"service-core/domain/quotation"
+	"service-core/domain/session"
+	"service-core/domain/submission"
+	"service-core/domain/twofactor"
 	"service-core/domain/user"
 	"service-core/grpc"
 	"service-core/rest"
@@ -45,10 +59,18 @@ func main() {
 	slog.Info("Database connected")
 
 	// Set up the REST handlers
-	restHandler := setupRESTHandlers(cfg, s)
+	restHandler, emailService := setupRESTHandlers(cfg, s)
 	// Run the REST server
 	restServer := rest.Run(restHandler)
 
+	// Run the email outbox workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	workersDone := make(chan struct{})
+	go func() {
+		emailService.Run(workerCtx)
+		close(workersDone)
+	}()
+
 	// Set up the gRPC handlers
 	grpcHandler := setupGRPCHandlers(cfg, s)
 	// Run the gRPC server
@@ -69,45 +91,97 @@ func main() {
 
 	grpcServer.GracefulStop()
 
+	stopWorkers()
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		slog.Error("Email workers did not stop in time")
+	}
+
 	slog.Info("Servers stopped gracefully")
 }
 
-func setupRESTHandlers(cfg *config.Config, storage *storage.Storage) *rest.Handler {
+func setupRESTHandlers(cfg *config.Config, storage *storage.Storage) (*rest.Handler, *email.Service) {
 	store := query.New(storage.Conn)
 	authService := auth.NewService()
 	fileProvider := file.NewProvider(cfg)
 	fileService := file.NewService(cfg, store, fileProvider)
 	emailProvider := email.NewProvider(cfg)
-	emailService := email.NewService(cfg, store, emailProvider, fileService)
-	loginService := login.NewService(cfg, store, authService, emailService)
+	emailService := email.NewService(cfg, storage, store, emailProvider, fileService)
+	twoFactorService := twofactor.NewService(cfg, storage.Conn, store)
+	passkeyService := passkey.NewService(cfg, store)
+	loginService := login.NewService(cfg, store, authService, emailService, twoFactorService, passkeyService)
 	billingService := billing.NewService(cfg, store)
 	noteService := note.NewService(store)
+	numberingService := numbering.NewService(storage, store)
+	proposalService := proposal.NewService(cfg, store, numberingService)
+	pdfService := pdf.NewService(cfg, store, fileService, proposalService)
+	invoiceService := invoice.NewService(cfg, store, proposalService, numberingService)
+	contractService := contract.NewService(cfg, storage.Conn, store, proposalService, numberingService)
+	quotationService := quotation.NewService(cfg, store, numberingService)
+	clientService := client.NewService(storage, store)
+	consultationService := consultation.NewService(storage.Conn, store)
+	formService := form.NewService(storage.Conn, store)
+	submissionService := submission.NewService(storage, store, formService, clientService, consultationService)
+	apiKeyService := apikey.NewService(store)
+	sessionService := session.NewService(store)
+	sessionAuthService := session.NewAuthService(authService, sessionService, cfg.ContextTimeout)
 
 	apiHandler := rest.NewHandler(
 		cfg,
 		storage,
-		authService,
+		apikey.NewAuthService(sessionAuthService, apiKeyService, cfg.ContextTimeout),
 		loginService,
 		billingService,
 		emailService,
 		fileService,
 		noteService,
+		proposalService,
+		pdfService,
+		invoiceService,
+		numberingService,
+		contractService,
+		quotationService,
+		clientService,
+		consultationService,
+		formService,
+		submissionService,
+		apiKeyService,
+		sessionService,
+		twoFactorService,
+		passkeyService,
 	)
-	return apiHandler
+	return apiHandler, emailService
 }
 
 func setupGRPCHandlers(cfg *config.Config, storage *storage.Storage) *grpc.Handler {
 	store := query.New(storage.Conn)
 	authService := auth.NewService()
-	loginService := login.NewService(cfg, store, authService, nil) // Email service is not used in gRPC
+	twoFactorService := twofactor.NewService(cfg, storage.Conn, store)
+	passkeyService := passkey.NewService(cfg, store)
+	loginService := login.NewService(cfg, store, authService, nil, twoFactorService, passkeyService) // Email service is not used in gRPC
 	userService := user.NewService(cfg, store)
 	noteService := note.NewService(store)
+	numberingService := numbering.NewService(storage, store)
+	proposalService := proposal.NewService(cfg, store, numberingService)
+	invoiceService := invoice.NewService(cfg, store, proposalService, numberingService)
+	clientService := client.NewService(storage, store)
+	consultationService := consultation.NewService(storage.Conn, store)
+	formService := form.NewService(storage.Conn, store)
+	submissionService := submission.NewService(storage, store, formService, clientService, consultationService)
+	apiKeyService := apikey.NewService(store)
+	sessionService := session.NewService(store)
+	sessionAuthService := session.NewAuthService(authService, sessionService, cfg.ContextTimeout)
 	grpcHandler := grpc.NewHandler(
 		cfg,
-		authService,
+		store,
+		apikey.NewAuthService(sessionAuthService, apiKeyService, cfg.ContextTimeout),
 		loginService,
 		userService,
 		noteService,
+		proposalService,
+		invoiceService,
+		submissionService,
 	)
 	return grpcHandler
 }
@@ -559,3 +559,1001 @@ var NoteService_ServiceDesc = grpc.ServiceDesc{
 	},
 	Metadata: "main.proto",
 }
+
+const (
+	ProposalService_GetProposals_FullMethodName          = "/proto.ProposalService/GetProposals"
+	ProposalService_GetProposalByID_FullMethodName       = "/proto.ProposalService/GetProposalByID"
+	ProposalService_CreateProposal_FullMethodName        = "/proto.ProposalService/CreateProposal"
+	ProposalService_EditProposal_FullMethodName          = "/proto.ProposalService/EditProposal"
+	ProposalService_UpdateProposalSection_FullMethodName = "/proto.ProposalService/UpdateProposalSection"
+	ProposalService_DuplicateProposal_FullMethodName     = "/proto.ProposalService/DuplicateProposal"
+	ProposalService_TransitionProposal_FullMethodName    = "/proto.ProposalService/TransitionProposal"
+	ProposalService_RemoveProposal_FullMethodName        = "/proto.ProposalService/RemoveProposal"
+)
+
+// ProposalServiceClient is the client API for ProposalService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ProposalServiceClient interface {
+	GetProposals(ctx context.Context, in *ProposalListRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Proposal], error)
+	GetProposalByID(ctx context.Context, in *ProposalID, opts ...grpc.CallOption) (*Proposal, error)
+	CreateProposal(ctx context.Context, in *CreateProposalRequest, opts ...grpc.CallOption) (*Proposal, error)
+	EditProposal(ctx context.Context, in *EditProposalRequest, opts ...grpc.CallOption) (*Proposal, error)
+	UpdateProposalSection(ctx context.Context, in *ProposalSectionRequest, opts ...grpc.CallOption) (*Proposal, error)
+	DuplicateProposal(ctx context.Context, in *ProposalID, opts ...grpc.CallOption) (*Proposal, error)
+	TransitionProposal(ctx context.Context, in *ProposalStatusRequest, opts ...grpc.CallOption) (*Proposal, error)
+	RemoveProposal(ctx context.Context, in *ProposalID, opts ...grpc.CallOption) (*Empty, error)
+}
+
+type proposalServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProposalServiceClient(cc grpc.ClientConnInterface) ProposalServiceClient {
+	return &proposalServiceClient{cc}
+}
+
+func (c *proposalServiceClient) GetProposals(ctx context.Context, in *ProposalListRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Proposal], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ProposalService_ServiceDesc.Streams[0], ProposalService_GetProposals_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ProposalListRequest, Proposal]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ProposalService_GetProposalsClient = grpc.ServerStreamingClient[Proposal]
+
+func (c *proposalServiceClient) GetProposalByID(ctx context.Context, in *ProposalID, opts ...grpc.CallOption) (*Proposal, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Proposal)
+	err := c.cc.Invoke(ctx, ProposalService_GetProposalByID_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proposalServiceClient) CreateProposal(ctx context.Context, in *CreateProposalRequest, opts ...grpc.CallOption) (*Proposal, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Proposal)
+	err := c.cc.Invoke(ctx, ProposalService_CreateProposal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proposalServiceClient) EditProposal(ctx context.Context, in *EditProposalRequest, opts ...grpc.CallOption) (*Proposal, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Proposal)
+	err := c.cc.Invoke(ctx, ProposalService_EditProposal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proposalServiceClient) UpdateProposalSection(ctx context.Context, in *ProposalSectionRequest, opts ...grpc.CallOption) (*Proposal, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Proposal)
+	err := c.cc.Invoke(ctx, ProposalService_UpdateProposalSection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proposalServiceClient) DuplicateProposal(ctx context.Context, in *ProposalID, opts ...grpc.CallOption) (*Proposal, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Proposal)
+	err := c.cc.Invoke(ctx, ProposalService_DuplicateProposal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proposalServiceClient) TransitionProposal(ctx context.Context, in *ProposalStatusRequest, opts ...grpc.CallOption) (*Proposal, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Proposal)
+	err := c.cc.Invoke(ctx, ProposalService_TransitionProposal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proposalServiceClient) RemoveProposal(ctx context.Context, in *ProposalID, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, ProposalService_RemoveProposal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProposalServiceServer is the server API for ProposalService service.
+// All implementations must embed UnimplementedProposalServiceServer
+// for forward compatibility.
+type ProposalServiceServer interface {
+	GetProposals(*ProposalListRequest, grpc.ServerStreamingServer[Proposal]) error
+	GetProposalByID(context.Context, *ProposalID) (*Proposal, error)
+	CreateProposal(context.Context, *CreateProposalRequest) (*Proposal, error)
+	EditProposal(context.Context, *EditProposalRequest) (*Proposal, error)
+	UpdateProposalSection(context.Context, *ProposalSectionRequest) (*Proposal, error)
+	DuplicateProposal(context.Context, *ProposalID) (*Proposal, error)
+	TransitionProposal(context.Context, *ProposalStatusRequest) (*Proposal, error)
+	RemoveProposal(context.Context, *ProposalID) (*Empty, error)
+	mustEmbedUnimplementedProposalServiceServer()
+}
+
+// UnimplementedProposalServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedProposalServiceServer struct{}
+
+func (UnimplementedProposalServiceServer) GetProposals(*ProposalListRequest, grpc.ServerStreamingServer[Proposal]) error {
+	return status.Errorf(codes.Unimplemented, "method GetProposals not implemented")
+}
+func (UnimplementedProposalServiceServer) GetProposalByID(context.Context, *ProposalID) (*Proposal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProposalByID not implemented")
+}
+func (UnimplementedProposalServiceServer) CreateProposal(context.Context, *CreateProposalRequest) (*Proposal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateProposal not implemented")
+}
+func (UnimplementedProposalServiceServer) EditProposal(context.Context, *EditProposalRequest) (*Proposal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditProposal not implemented")
+}
+func (UnimplementedProposalServiceServer) UpdateProposalSection(context.Context, *ProposalSectionRequest) (*Proposal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateProposalSection not implemented")
+}
+func (UnimplementedProposalServiceServer) DuplicateProposal(context.Context, *ProposalID) (*Proposal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DuplicateProposal not implemented")
+}
+func (UnimplementedProposalServiceServer) TransitionProposal(context.Context, *ProposalStatusRequest) (*Proposal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TransitionProposal not implemented")
+}
+func (UnimplementedProposalServiceServer) RemoveProposal(context.Context, *ProposalID) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveProposal not implemented")
+}
+func (UnimplementedProposalServiceServer) mustEmbedUnimplementedProposalServiceServer() {}
+func (UnimplementedProposalServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeProposalServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProposalServiceServer will
+// result in compilation errors.
+type UnsafeProposalServiceServer interface {
+	mustEmbedUnimplementedProposalServiceServer()
+}
+
+func RegisterProposalServiceServer(s grpc.ServiceRegistrar, srv ProposalServiceServer) {
+	// If the following call pancis, it indicates UnimplementedProposalServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ProposalService_ServiceDesc, srv)
+}
+
+func _ProposalService_GetProposals_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ProposalListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ProposalServiceServer).GetProposals(m, &grpc.GenericServerStream[ProposalListRequest, Proposal]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ProposalService_GetProposalsServer = grpc.ServerStreamingServer[Proposal]
+
+func _ProposalService_GetProposalByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProposalID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProposalServiceServer).GetProposalByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProposalService_GetProposalByID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProposalServiceServer).GetProposalByID(ctx, req.(*ProposalID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProposalService_CreateProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateProposalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProposalServiceServer).CreateProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProposalService_CreateProposal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProposalServiceServer).CreateProposal(ctx, req.(*CreateProposalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProposalService_EditProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditProposalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProposalServiceServer).EditProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProposalService_EditProposal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProposalServiceServer).EditProposal(ctx, req.(*EditProposalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProposalService_UpdateProposalSection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProposalSectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProposalServiceServer).UpdateProposalSection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProposalService_UpdateProposalSection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProposalServiceServer).UpdateProposalSection(ctx, req.(*ProposalSectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProposalService_DuplicateProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProposalID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProposalServiceServer).DuplicateProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProposalService_DuplicateProposal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProposalServiceServer).DuplicateProposal(ctx, req.(*ProposalID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProposalService_TransitionProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProposalStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProposalServiceServer).TransitionProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProposalService_TransitionProposal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProposalServiceServer).TransitionProposal(ctx, req.(*ProposalStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProposalService_RemoveProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProposalID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProposalServiceServer).RemoveProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProposalService_RemoveProposal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProposalServiceServer).RemoveProposal(ctx, req.(*ProposalID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ProposalService_ServiceDesc is the grpc.ServiceDesc for ProposalService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ProposalService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.ProposalService",
+	HandlerType: (*ProposalServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetProposalByID",
+			Handler:    _ProposalService_GetProposalByID_Handler,
+		},
+		{
+			MethodName: "CreateProposal",
+			Handler:    _ProposalService_CreateProposal_Handler,
+		},
+		{
+			MethodName: "EditProposal",
+			Handler:    _ProposalService_EditProposal_Handler,
+		},
+		{
+			MethodName: "UpdateProposalSection",
+			Handler:    _ProposalService_UpdateProposalSection_Handler,
+		},
+		{
+			MethodName: "DuplicateProposal",
+			Handler:    _ProposalService_DuplicateProposal_Handler,
+		},
+		{
+			MethodName: "TransitionProposal",
+			Handler:    _ProposalService_TransitionProposal_Handler,
+		},
+		{
+			MethodName: "RemoveProposal",
+			Handler:    _ProposalService_RemoveProposal_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetProposals",
+			Handler:       _ProposalService_GetProposals_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "main.proto",
+}
+
+const (
+	InvoiceService_GetInvoices_FullMethodName               = "/proto.InvoiceService/GetInvoices"
+	InvoiceService_GetInvoiceByID_FullMethodName            = "/proto.InvoiceService/GetInvoiceByID"
+	InvoiceService_CreateInvoice_FullMethodName             = "/proto.InvoiceService/CreateInvoice"
+	InvoiceService_CreateInvoiceFromProposal_FullMethodName = "/proto.InvoiceService/CreateInvoiceFromProposal"
+	InvoiceService_CreateInvoiceFromContract_FullMethodName = "/proto.InvoiceService/CreateInvoiceFromContract"
+	InvoiceService_EditInvoice_FullMethodName               = "/proto.InvoiceService/EditInvoice"
+	InvoiceService_AddInvoiceLineItem_FullMethodName        = "/proto.InvoiceService/AddInvoiceLineItem"
+	InvoiceService_EditInvoiceLineItem_FullMethodName       = "/proto.InvoiceService/EditInvoiceLineItem"
+	InvoiceService_RemoveInvoiceLineItem_FullMethodName     = "/proto.InvoiceService/RemoveInvoiceLineItem"
+	InvoiceService_TransitionInvoice_FullMethodName         = "/proto.InvoiceService/TransitionInvoice"
+	InvoiceService_RecordInvoicePayment_FullMethodName      = "/proto.InvoiceService/RecordInvoicePayment"
+	InvoiceService_RemoveInvoice_FullMethodName             = "/proto.InvoiceService/RemoveInvoice"
+)
+
+// InvoiceServiceClient is the client API for InvoiceService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type InvoiceServiceClient interface {
+	GetInvoices(ctx context.Context, in *InvoiceListRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Invoice], error)
+	GetInvoiceByID(ctx context.Context, in *InvoiceID, opts ...grpc.CallOption) (*Invoice, error)
+	CreateInvoice(ctx context.Context, in *CreateInvoiceRequest, opts ...grpc.CallOption) (*Invoice, error)
+	CreateInvoiceFromProposal(ctx context.Context, in *InvoiceSourceRequest, opts ...grpc.CallOption) (*Invoice, error)
+	CreateInvoiceFromContract(ctx context.Context, in *InvoiceSourceRequest, opts ...grpc.CallOption) (*Invoice, error)
+	EditInvoice(ctx context.Context, in *EditInvoiceRequest, opts ...grpc.CallOption) (*Invoice, error)
+	AddInvoiceLineItem(ctx context.Context, in *InvoiceLineItemRequest, opts ...grpc.CallOption) (*Invoice, error)
+	EditInvoiceLineItem(ctx context.Context, in *InvoiceLineItemRequest, opts ...grpc.CallOption) (*Invoice, error)
+	RemoveInvoiceLineItem(ctx context.Context, in *InvoiceLineItemRequest, opts ...grpc.CallOption) (*Invoice, error)
+	TransitionInvoice(ctx context.Context, in *InvoiceStatusRequest, opts ...grpc.CallOption) (*Invoice, error)
+	RecordInvoicePayment(ctx context.Context, in *InvoicePaymentRequest, opts ...grpc.CallOption) (*Invoice, error)
+	RemoveInvoice(ctx context.Context, in *InvoiceID, opts ...grpc.CallOption) (*Empty, error)
+}
+
+type invoiceServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInvoiceServiceClient(cc grpc.ClientConnInterface) InvoiceServiceClient {
+	return &invoiceServiceClient{cc}
+}
+
+func (c *invoiceServiceClient) GetInvoices(ctx context.Context, in *InvoiceListRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Invoice], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &InvoiceService_ServiceDesc.Streams[0], InvoiceService_GetInvoices_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[InvoiceListRequest, Invoice]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type InvoiceService_GetInvoicesClient = grpc.ServerStreamingClient[Invoice]
+
+func (c *invoiceServiceClient) GetInvoiceByID(ctx context.Context, in *InvoiceID, opts ...grpc.CallOption) (*Invoice, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Invoice)
+	err := c.cc.Invoke(ctx, InvoiceService_GetInvoiceByID_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invoiceServiceClient) CreateInvoice(ctx context.Context, in *CreateInvoiceRequest, opts ...grpc.CallOption) (*Invoice, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Invoice)
+	err := c.cc.Invoke(ctx, InvoiceService_CreateInvoice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invoiceServiceClient) CreateInvoiceFromProposal(ctx context.Context, in *InvoiceSourceRequest, opts ...grpc.CallOption) (*Invoice, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Invoice)
+	err := c.cc.Invoke(ctx, InvoiceService_CreateInvoiceFromProposal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invoiceServiceClient) CreateInvoiceFromContract(ctx context.Context, in *InvoiceSourceRequest, opts ...grpc.CallOption) (*Invoice, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Invoice)
+	err := c.cc.Invoke(ctx, InvoiceService_CreateInvoiceFromContract_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invoiceServiceClient) EditInvoice(ctx context.Context, in *EditInvoiceRequest, opts ...grpc.CallOption) (*Invoice, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Invoice)
+	err := c.cc.Invoke(ctx, InvoiceService_EditInvoice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invoiceServiceClient) AddInvoiceLineItem(ctx context.Context, in *InvoiceLineItemRequest, opts ...grpc.CallOption) (*Invoice, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Invoice)
+	err := c.cc.Invoke(ctx, InvoiceService_AddInvoiceLineItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invoiceServiceClient) EditInvoiceLineItem(ctx context.Context, in *InvoiceLineItemRequest, opts ...grpc.CallOption) (*Invoice, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Invoice)
+	err := c.cc.Invoke(ctx, InvoiceService_EditInvoiceLineItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invoiceServiceClient) RemoveInvoiceLineItem(ctx context.Context, in *InvoiceLineItemRequest, opts ...grpc.CallOption) (*Invoice, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Invoice)
+	err := c.cc.Invoke(ctx, InvoiceService_RemoveInvoiceLineItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invoiceServiceClient) TransitionInvoice(ctx context.Context, in *InvoiceStatusRequest, opts ...grpc.CallOption) (*Invoice, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Invoice)
+	err := c.cc.Invoke(ctx, InvoiceService_TransitionInvoice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invoiceServiceClient) RecordInvoicePayment(ctx context.Context, in *InvoicePaymentRequest, opts ...grpc.CallOption) (*Invoice, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Invoice)
+	err := c.cc.Invoke(ctx, InvoiceService_RecordInvoicePayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invoiceServiceClient) RemoveInvoice(ctx context.Context, in *InvoiceID, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, InvoiceService_RemoveInvoice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InvoiceServiceServer is the server API for InvoiceService service.
+// All implementations must embed UnimplementedInvoiceServiceServer
+// for forward compatibility.
+type InvoiceServiceServer interface {
+	GetInvoices(*InvoiceListRequest, grpc.ServerStreamingServer[Invoice]) error
+	GetInvoiceByID(context.Context, *InvoiceID) (*Invoice, error)
+	CreateInvoice(context.Context, *CreateInvoiceRequest) (*Invoice, error)
+	CreateInvoiceFromProposal(context.Context, *InvoiceSourceRequest) (*Invoice, error)
+	CreateInvoiceFromContract(context.Context, *InvoiceSourceRequest) (*Invoice, error)
+	EditInvoice(context.Context, *EditInvoiceRequest) (*Invoice, error)
+	AddInvoiceLineItem(context.Context, *InvoiceLineItemRequest) (*Invoice, error)
+	EditInvoiceLineItem(context.Context, *InvoiceLineItemRequest) (*Invoice, error)
+	RemoveInvoiceLineItem(context.Context, *InvoiceLineItemRequest) (*Invoice, error)
+	TransitionInvoice(context.Context, *InvoiceStatusRequest) (*Invoice, error)
+	RecordInvoicePayment(context.Context, *InvoicePaymentRequest) (*Invoice, error)
+	RemoveInvoice(context.Context, *InvoiceID) (*Empty, error)
+	mustEmbedUnimplementedInvoiceServiceServer()
+}
+
+// UnimplementedInvoiceServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedInvoiceServiceServer struct{}
+
+func (UnimplementedInvoiceServiceServer) GetInvoices(*InvoiceListRequest, grpc.ServerStreamingServer[Invoice]) error {
+	return status.Errorf(codes.Unimplemented, "method GetInvoices not implemented")
+}
+func (UnimplementedInvoiceServiceServer) GetInvoiceByID(context.Context, *InvoiceID) (*Invoice, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInvoiceByID not implemented")
+}
+func (UnimplementedInvoiceServiceServer) CreateInvoice(context.Context, *CreateInvoiceRequest) (*Invoice, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateInvoice not implemented")
+}
+func (UnimplementedInvoiceServiceServer) CreateInvoiceFromProposal(context.Context, *InvoiceSourceRequest) (*Invoice, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateInvoiceFromProposal not implemented")
+}
+func (UnimplementedInvoiceServiceServer) CreateInvoiceFromContract(context.Context, *InvoiceSourceRequest) (*Invoice, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateInvoiceFromContract not implemented")
+}
+func (UnimplementedInvoiceServiceServer) EditInvoice(context.Context, *EditInvoiceRequest) (*Invoice, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditInvoice not implemented")
+}
+func (UnimplementedInvoiceServiceServer) AddInvoiceLineItem(context.Context, *InvoiceLineItemRequest) (*Invoice, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddInvoiceLineItem not implemented")
+}
+func (UnimplementedInvoiceServiceServer) EditInvoiceLineItem(context.Context, *InvoiceLineItemRequest) (*Invoice, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditInvoiceLineItem not implemented")
+}
+func (UnimplementedInvoiceServiceServer) RemoveInvoiceLineItem(context.Context, *InvoiceLineItemRequest) (*Invoice, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveInvoiceLineItem not implemented")
+}
+func (UnimplementedInvoiceServiceServer) TransitionInvoice(context.Context, *InvoiceStatusRequest) (*Invoice, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TransitionInvoice not implemented")
+}
+func (UnimplementedInvoiceServiceServer) RecordInvoicePayment(context.Context, *InvoicePaymentRequest) (*Invoice, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordInvoicePayment not implemented")
+}
+func (UnimplementedInvoiceServiceServer) RemoveInvoice(context.Context, *InvoiceID) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveInvoice not implemented")
+}
+func (UnimplementedInvoiceServiceServer) mustEmbedUnimplementedInvoiceServiceServer() {}
+func (UnimplementedInvoiceServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeInvoiceServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InvoiceServiceServer will
+// result in compilation errors.
+type UnsafeInvoiceServiceServer interface {
+	mustEmbedUnimplementedInvoiceServiceServer()
+}
+
+func RegisterInvoiceServiceServer(s grpc.ServiceRegistrar, srv InvoiceServiceServer) {
+	// If the following call pancis, it indicates UnimplementedInvoiceServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&InvoiceService_ServiceDesc, srv)
+}
+
+func _InvoiceService_GetInvoices_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(InvoiceListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(InvoiceServiceServer).GetInvoices(m, &grpc.GenericServerStream[InvoiceListRequest, Invoice]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type InvoiceService_GetInvoicesServer = grpc.ServerStreamingServer[Invoice]
+
+func _InvoiceService_GetInvoiceByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvoiceID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvoiceServiceServer).GetInvoiceByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvoiceService_GetInvoiceByID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvoiceServiceServer).GetInvoiceByID(ctx, req.(*InvoiceID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvoiceService_CreateInvoice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateInvoiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvoiceServiceServer).CreateInvoice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvoiceService_CreateInvoice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvoiceServiceServer).CreateInvoice(ctx, req.(*CreateInvoiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvoiceService_CreateInvoiceFromProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvoiceSourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvoiceServiceServer).CreateInvoiceFromProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvoiceService_CreateInvoiceFromProposal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvoiceServiceServer).CreateInvoiceFromProposal(ctx, req.(*InvoiceSourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvoiceService_CreateInvoiceFromContract_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvoiceSourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvoiceServiceServer).CreateInvoiceFromContract(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvoiceService_CreateInvoiceFromContract_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvoiceServiceServer).CreateInvoiceFromContract(ctx, req.(*InvoiceSourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvoiceService_EditInvoice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditInvoiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvoiceServiceServer).EditInvoice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvoiceService_EditInvoice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvoiceServiceServer).EditInvoice(ctx, req.(*EditInvoiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvoiceService_AddInvoiceLineItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvoiceLineItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvoiceServiceServer).AddInvoiceLineItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvoiceService_AddInvoiceLineItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvoiceServiceServer).AddInvoiceLineItem(ctx, req.(*InvoiceLineItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvoiceService_EditInvoiceLineItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvoiceLineItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvoiceServiceServer).EditInvoiceLineItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvoiceService_EditInvoiceLineItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvoiceServiceServer).EditInvoiceLineItem(ctx, req.(*InvoiceLineItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvoiceService_RemoveInvoiceLineItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvoiceLineItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvoiceServiceServer).RemoveInvoiceLineItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvoiceService_RemoveInvoiceLineItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvoiceServiceServer).RemoveInvoiceLineItem(ctx, req.(*InvoiceLineItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvoiceService_TransitionInvoice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvoiceStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvoiceServiceServer).TransitionInvoice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvoiceService_TransitionInvoice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvoiceServiceServer).TransitionInvoice(ctx, req.(*InvoiceStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvoiceService_RecordInvoicePayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvoicePaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvoiceServiceServer).RecordInvoicePayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvoiceService_RecordInvoicePayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvoiceServiceServer).RecordInvoicePayment(ctx, req.(*InvoicePaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InvoiceService_RemoveInvoice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvoiceID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvoiceServiceServer).RemoveInvoice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InvoiceService_RemoveInvoice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvoiceServiceServer).RemoveInvoice(ctx, req.(*InvoiceID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// InvoiceService_ServiceDesc is the grpc.ServiceDesc for InvoiceService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InvoiceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.InvoiceService",
+	HandlerType: (*InvoiceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetInvoiceByID",
+			Handler:    _InvoiceService_GetInvoiceByID_Handler,
+		},
+		{
+			MethodName: "CreateInvoice",
+			Handler:    _InvoiceService_CreateInvoice_Handler,
+		},
+		{
+			MethodName: "CreateInvoiceFromProposal",
+			Handler:    _InvoiceService_CreateInvoiceFromProposal_Handler,
+		},
+		{
+			MethodName: "CreateInvoiceFromContract",
+			Handler:    _InvoiceService_CreateInvoiceFromContract_Handler,
+		},
+		{
+			MethodName: "EditInvoice",
+			Handler:    _InvoiceService_EditInvoice_Handler,
+		},
+		{
+			MethodName: "AddInvoiceLineItem",
+			Handler:    _InvoiceService_AddInvoiceLineItem_Handler,
+		},
+		{
+			MethodName: "EditInvoiceLineItem",
+			Handler:    _InvoiceService_EditInvoiceLineItem_Handler,
+		},
+		{
+			MethodName: "RemoveInvoiceLineItem",
+			Handler:    _InvoiceService_RemoveInvoiceLineItem_Handler,
+		},
+		{
+			MethodName: "TransitionInvoice",
+			Handler:    _InvoiceService_TransitionInvoice_Handler,
+		},
+		{
+			MethodName: "RecordInvoicePayment",
+			Handler:    _InvoiceService_RecordInvoicePayment_Handler,
+		},
+		{
+			MethodName: "RemoveInvoice",
+			Handler:    _InvoiceService_RemoveInvoice_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetInvoices",
+			Handler:       _InvoiceService_GetInvoices_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "main.proto",
+}
+
+const (
+	SubmissionService_ProcessSubmission_FullMethodName = "/proto.SubmissionService/ProcessSubmission"
+)
+
+// SubmissionServiceClient is the client API for SubmissionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SubmissionServiceClient interface {
+	ProcessSubmission(ctx context.Context, in *SubmissionID, opts ...grpc.CallOption) (*ProcessSubmissionResponse, error)
+}
+
+type submissionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSubmissionServiceClient(cc grpc.ClientConnInterface) SubmissionServiceClient {
+	return &submissionServiceClient{cc}
+}
+
+func (c *submissionServiceClient) ProcessSubmission(ctx context.Context, in *SubmissionID, opts ...grpc.CallOption) (*ProcessSubmissionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProcessSubmissionResponse)
+	err := c.cc.Invoke(ctx, SubmissionService_ProcessSubmission_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SubmissionServiceServer is the server API for SubmissionService service.
+// All implementations must embed UnimplementedSubmissionServiceServer
+// for forward compatibility.
+type SubmissionServiceServer interface {
+	ProcessSubmission(context.Context, *SubmissionID) (*ProcessSubmissionResponse, error)
+	mustEmbedUnimplementedSubmissionServiceServer()
+}
+
+// UnimplementedSubmissionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSubmissionServiceServer struct{}
+
+func (UnimplementedSubmissionServiceServer) ProcessSubmission(context.Context, *SubmissionID) (*ProcessSubmissionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProcessSubmission not implemented")
+}
+func (UnimplementedSubmissionServiceServer) mustEmbedUnimplementedSubmissionServiceServer() {}
+func (UnimplementedSubmissionServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeSubmissionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SubmissionServiceServer will
+// result in compilation errors.
+type UnsafeSubmissionServiceServer interface {
+	mustEmbedUnimplementedSubmissionServiceServer()
+}
+
+func RegisterSubmissionServiceServer(s grpc.ServiceRegistrar, srv SubmissionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSubmissionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SubmissionService_ServiceDesc, srv)
+}
+
+func _SubmissionService_ProcessSubmission_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmissionID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubmissionServiceServer).ProcessSubmission(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SubmissionService_ProcessSubmission_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubmissionServiceServer).ProcessSubmission(ctx, req.(*SubmissionID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SubmissionService_ServiceDesc is the grpc.ServiceDesc for SubmissionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SubmissionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.SubmissionService",
+	HandlerType: (*SubmissionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ProcessSubmission",
+			Handler:    _SubmissionService_ProcessSubmission_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "main.proto",
+}
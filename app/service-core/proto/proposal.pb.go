@@ -0,0 +1,1097 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v6.31.1
+// source: proposal.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// JSON columns (performance data, sections, pricing) are carried as JSON strings.
+type Proposal struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Id                    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt             string                 `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt             string                 `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	AgencyId              string                 `protobuf:"bytes,4,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	ConsultationId        string                 `protobuf:"bytes,5,opt,name=consultation_id,json=consultationId,proto3" json:"consultation_id,omitempty"`
+	ClientId              string                 `protobuf:"bytes,6,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	ProposalNumber        string                 `protobuf:"bytes,7,opt,name=proposal_number,json=proposalNumber,proto3" json:"proposal_number,omitempty"`
+	Slug                  string                 `protobuf:"bytes,8,opt,name=slug,proto3" json:"slug,omitempty"`
+	Status                string                 `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`
+	ClientBusinessName    string                 `protobuf:"bytes,10,opt,name=client_business_name,json=clientBusinessName,proto3" json:"client_business_name,omitempty"`
+	ClientContactName     string                 `protobuf:"bytes,11,opt,name=client_contact_name,json=clientContactName,proto3" json:"client_contact_name,omitempty"`
+	ClientEmail           string                 `protobuf:"bytes,12,opt,name=client_email,json=clientEmail,proto3" json:"client_email,omitempty"`
+	ClientPhone           string                 `protobuf:"bytes,13,opt,name=client_phone,json=clientPhone,proto3" json:"client_phone,omitempty"`
+	ClientWebsite         string                 `protobuf:"bytes,14,opt,name=client_website,json=clientWebsite,proto3" json:"client_website,omitempty"`
+	Title                 string                 `protobuf:"bytes,15,opt,name=title,proto3" json:"title,omitempty"`
+	CoverImage            string                 `protobuf:"bytes,16,opt,name=cover_image,json=coverImage,proto3" json:"cover_image,omitempty"`
+	ExecutiveSummary      string                 `protobuf:"bytes,17,opt,name=executive_summary,json=executiveSummary,proto3" json:"executive_summary,omitempty"`
+	PerformanceData       string                 `protobuf:"bytes,18,opt,name=performance_data,json=performanceData,proto3" json:"performance_data,omitempty"`
+	OpportunityContent    string                 `protobuf:"bytes,19,opt,name=opportunity_content,json=opportunityContent,proto3" json:"opportunity_content,omitempty"`
+	CurrentIssues         string                 `protobuf:"bytes,20,opt,name=current_issues,json=currentIssues,proto3" json:"current_issues,omitempty"`
+	ComplianceIssues      string                 `protobuf:"bytes,21,opt,name=compliance_issues,json=complianceIssues,proto3" json:"compliance_issues,omitempty"`
+	RoiAnalysis           string                 `protobuf:"bytes,22,opt,name=roi_analysis,json=roiAnalysis,proto3" json:"roi_analysis,omitempty"`
+	PerformanceStandards  string                 `protobuf:"bytes,23,opt,name=performance_standards,json=performanceStandards,proto3" json:"performance_standards,omitempty"`
+	LocalAdvantageContent string                 `protobuf:"bytes,24,opt,name=local_advantage_content,json=localAdvantageContent,proto3" json:"local_advantage_content,omitempty"`
+	ProposedPages         string                 `protobuf:"bytes,25,opt,name=proposed_pages,json=proposedPages,proto3" json:"proposed_pages,omitempty"`
+	Timeline              string                 `protobuf:"bytes,26,opt,name=timeline,proto3" json:"timeline,omitempty"`
+	ClosingContent        string                 `protobuf:"bytes,27,opt,name=closing_content,json=closingContent,proto3" json:"closing_content,omitempty"`
+	NextSteps             string                 `protobuf:"bytes,28,opt,name=next_steps,json=nextSteps,proto3" json:"next_steps,omitempty"`
+	SelectedPackageId     string                 `protobuf:"bytes,29,opt,name=selected_package_id,json=selectedPackageId,proto3" json:"selected_package_id,omitempty"`
+	SelectedAddons        string                 `protobuf:"bytes,30,opt,name=selected_addons,json=selectedAddons,proto3" json:"selected_addons,omitempty"`
+	CustomPricing         string                 `protobuf:"bytes,31,opt,name=custom_pricing,json=customPricing,proto3" json:"custom_pricing,omitempty"`
+	ValidUntil            string                 `protobuf:"bytes,32,opt,name=valid_until,json=validUntil,proto3" json:"valid_until,omitempty"`
+	ViewCount             int32                  `protobuf:"varint,33,opt,name=view_count,json=viewCount,proto3" json:"view_count,omitempty"`
+	LastViewedAt          string                 `protobuf:"bytes,34,opt,name=last_viewed_at,json=lastViewedAt,proto3" json:"last_viewed_at,omitempty"`
+	SentAt                string                 `protobuf:"bytes,35,opt,name=sent_at,json=sentAt,proto3" json:"sent_at,omitempty"`
+	AcceptedAt            string                 `protobuf:"bytes,36,opt,name=accepted_at,json=acceptedAt,proto3" json:"accepted_at,omitempty"`
+	DeclinedAt            string                 `protobuf:"bytes,37,opt,name=declined_at,json=declinedAt,proto3" json:"declined_at,omitempty"`
+	RevisionRequestedAt   string                 `protobuf:"bytes,38,opt,name=revision_requested_at,json=revisionRequestedAt,proto3" json:"revision_requested_at,omitempty"`
+	ClientComments        string                 `protobuf:"bytes,39,opt,name=client_comments,json=clientComments,proto3" json:"client_comments,omitempty"`
+	DeclineReason         string                 `protobuf:"bytes,40,opt,name=decline_reason,json=declineReason,proto3" json:"decline_reason,omitempty"`
+	RevisionRequestNotes  string                 `protobuf:"bytes,41,opt,name=revision_request_notes,json=revisionRequestNotes,proto3" json:"revision_request_notes,omitempty"`
+	CreatedBy             string                 `protobuf:"bytes,42,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *Proposal) Reset() {
+	*x = Proposal{}
+	mi := &file_proposal_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Proposal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Proposal) ProtoMessage() {}
+
+func (x *Proposal) ProtoReflect() protoreflect.Message {
+	mi := &file_proposal_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Proposal.ProtoReflect.Descriptor instead.
+func (*Proposal) Descriptor() ([]byte, []int) {
+	return file_proposal_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Proposal) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Proposal) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Proposal) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *Proposal) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *Proposal) GetConsultationId() string {
+	if x != nil {
+		return x.ConsultationId
+	}
+	return ""
+}
+
+func (x *Proposal) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *Proposal) GetProposalNumber() string {
+	if x != nil {
+		return x.ProposalNumber
+	}
+	return ""
+}
+
+func (x *Proposal) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *Proposal) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Proposal) GetClientBusinessName() string {
+	if x != nil {
+		return x.ClientBusinessName
+	}
+	return ""
+}
+
+func (x *Proposal) GetClientContactName() string {
+	if x != nil {
+		return x.ClientContactName
+	}
+	return ""
+}
+
+func (x *Proposal) GetClientEmail() string {
+	if x != nil {
+		return x.ClientEmail
+	}
+	return ""
+}
+
+func (x *Proposal) GetClientPhone() string {
+	if x != nil {
+		return x.ClientPhone
+	}
+	return ""
+}
+
+func (x *Proposal) GetClientWebsite() string {
+	if x != nil {
+		return x.ClientWebsite
+	}
+	return ""
+}
+
+func (x *Proposal) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Proposal) GetCoverImage() string {
+	if x != nil {
+		return x.CoverImage
+	}
+	return ""
+}
+
+func (x *Proposal) GetExecutiveSummary() string {
+	if x != nil {
+		return x.ExecutiveSummary
+	}
+	return ""
+}
+
+func (x *Proposal) GetPerformanceData() string {
+	if x != nil {
+		return x.PerformanceData
+	}
+	return ""
+}
+
+func (x *Proposal) GetOpportunityContent() string {
+	if x != nil {
+		return x.OpportunityContent
+	}
+	return ""
+}
+
+func (x *Proposal) GetCurrentIssues() string {
+	if x != nil {
+		return x.CurrentIssues
+	}
+	return ""
+}
+
+func (x *Proposal) GetComplianceIssues() string {
+	if x != nil {
+		return x.ComplianceIssues
+	}
+	return ""
+}
+
+func (x *Proposal) GetRoiAnalysis() string {
+	if x != nil {
+		return x.RoiAnalysis
+	}
+	return ""
+}
+
+func (x *Proposal) GetPerformanceStandards() string {
+	if x != nil {
+		return x.PerformanceStandards
+	}
+	return ""
+}
+
+func (x *Proposal) GetLocalAdvantageContent() string {
+	if x != nil {
+		return x.LocalAdvantageContent
+	}
+	return ""
+}
+
+func (x *Proposal) GetProposedPages() string {
+	if x != nil {
+		return x.ProposedPages
+	}
+	return ""
+}
+
+func (x *Proposal) GetTimeline() string {
+	if x != nil {
+		return x.Timeline
+	}
+	return ""
+}
+
+func (x *Proposal) GetClosingContent() string {
+	if x != nil {
+		return x.ClosingContent
+	}
+	return ""
+}
+
+func (x *Proposal) GetNextSteps() string {
+	if x != nil {
+		return x.NextSteps
+	}
+	return ""
+}
+
+func (x *Proposal) GetSelectedPackageId() string {
+	if x != nil {
+		return x.SelectedPackageId
+	}
+	return ""
+}
+
+func (x *Proposal) GetSelectedAddons() string {
+	if x != nil {
+		return x.SelectedAddons
+	}
+	return ""
+}
+
+func (x *Proposal) GetCustomPricing() string {
+	if x != nil {
+		return x.CustomPricing
+	}
+	return ""
+}
+
+func (x *Proposal) GetValidUntil() string {
+	if x != nil {
+		return x.ValidUntil
+	}
+	return ""
+}
+
+func (x *Proposal) GetViewCount() int32 {
+	if x != nil {
+		return x.ViewCount
+	}
+	return 0
+}
+
+func (x *Proposal) GetLastViewedAt() string {
+	if x != nil {
+		return x.LastViewedAt
+	}
+	return ""
+}
+
+func (x *Proposal) GetSentAt() string {
+	if x != nil {
+		return x.SentAt
+	}
+	return ""
+}
+
+func (x *Proposal) GetAcceptedAt() string {
+	if x != nil {
+		return x.AcceptedAt
+	}
+	return ""
+}
+
+func (x *Proposal) GetDeclinedAt() string {
+	if x != nil {
+		return x.DeclinedAt
+	}
+	return ""
+}
+
+func (x *Proposal) GetRevisionRequestedAt() string {
+	if x != nil {
+		return x.RevisionRequestedAt
+	}
+	return ""
+}
+
+func (x *Proposal) GetClientComments() string {
+	if x != nil {
+		return x.ClientComments
+	}
+	return ""
+}
+
+func (x *Proposal) GetDeclineReason() string {
+	if x != nil {
+		return x.DeclineReason
+	}
+	return ""
+}
+
+func (x *Proposal) GetRevisionRequestNotes() string {
+	if x != nil {
+		return x.RevisionRequestNotes
+	}
+	return ""
+}
+
+func (x *Proposal) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+type ProposalID struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgencyId      string                 `protobuf:"bytes,1,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProposalID) Reset() {
+	*x = ProposalID{}
+	mi := &file_proposal_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProposalID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProposalID) ProtoMessage() {}
+
+func (x *ProposalID) ProtoReflect() protoreflect.Message {
+	mi := &file_proposal_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProposalID.ProtoReflect.Descriptor instead.
+func (*ProposalID) Descriptor() ([]byte, []int) {
+	return file_proposal_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ProposalID) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *ProposalID) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ProposalListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgencyId      string                 `protobuf:"bytes,1,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Page          int64                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	Limit         int64                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProposalListRequest) Reset() {
+	*x = ProposalListRequest{}
+	mi := &file_proposal_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProposalListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProposalListRequest) ProtoMessage() {}
+
+func (x *ProposalListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proposal_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProposalListRequest.ProtoReflect.Descriptor instead.
+func (*ProposalListRequest) Descriptor() ([]byte, []int) {
+	return file_proposal_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProposalListRequest) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *ProposalListRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ProposalListRequest) GetPage() int64 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ProposalListRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type CreateProposalRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	AgencyId          string                 `protobuf:"bytes,1,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	ConsultationId    string                 `protobuf:"bytes,2,opt,name=consultation_id,json=consultationId,proto3" json:"consultation_id,omitempty"`
+	SelectedPackageId string                 `protobuf:"bytes,3,opt,name=selected_package_id,json=selectedPackageId,proto3" json:"selected_package_id,omitempty"`
+	Title             string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *CreateProposalRequest) Reset() {
+	*x = CreateProposalRequest{}
+	mi := &file_proposal_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateProposalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateProposalRequest) ProtoMessage() {}
+
+func (x *CreateProposalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proposal_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateProposalRequest.ProtoReflect.Descriptor instead.
+func (*CreateProposalRequest) Descriptor() ([]byte, []int) {
+	return file_proposal_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateProposalRequest) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *CreateProposalRequest) GetConsultationId() string {
+	if x != nil {
+		return x.ConsultationId
+	}
+	return ""
+}
+
+func (x *CreateProposalRequest) GetSelectedPackageId() string {
+	if x != nil {
+		return x.SelectedPackageId
+	}
+	return ""
+}
+
+func (x *CreateProposalRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+// Unset fields are left unchanged.
+type EditProposalRequest struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	AgencyId              string                 `protobuf:"bytes,1,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	Id                    string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	ClientBusinessName    *string                `protobuf:"bytes,3,opt,name=client_business_name,json=clientBusinessName,proto3,oneof" json:"client_business_name,omitempty"`
+	ClientContactName     *string                `protobuf:"bytes,4,opt,name=client_contact_name,json=clientContactName,proto3,oneof" json:"client_contact_name,omitempty"`
+	ClientEmail           *string                `protobuf:"bytes,5,opt,name=client_email,json=clientEmail,proto3,oneof" json:"client_email,omitempty"`
+	ClientPhone           *string                `protobuf:"bytes,6,opt,name=client_phone,json=clientPhone,proto3,oneof" json:"client_phone,omitempty"`
+	ClientWebsite         *string                `protobuf:"bytes,7,opt,name=client_website,json=clientWebsite,proto3,oneof" json:"client_website,omitempty"`
+	Title                 *string                `protobuf:"bytes,8,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	CoverImage            *string                `protobuf:"bytes,9,opt,name=cover_image,json=coverImage,proto3,oneof" json:"cover_image,omitempty"`
+	ExecutiveSummary      *string                `protobuf:"bytes,10,opt,name=executive_summary,json=executiveSummary,proto3,oneof" json:"executive_summary,omitempty"`
+	PerformanceData       *string                `protobuf:"bytes,11,opt,name=performance_data,json=performanceData,proto3,oneof" json:"performance_data,omitempty"`
+	CurrentIssues         *string                `protobuf:"bytes,12,opt,name=current_issues,json=currentIssues,proto3,oneof" json:"current_issues,omitempty"`
+	ComplianceIssues      *string                `protobuf:"bytes,13,opt,name=compliance_issues,json=complianceIssues,proto3,oneof" json:"compliance_issues,omitempty"`
+	PerformanceStandards  *string                `protobuf:"bytes,14,opt,name=performance_standards,json=performanceStandards,proto3,oneof" json:"performance_standards,omitempty"`
+	LocalAdvantageContent *string                `protobuf:"bytes,15,opt,name=local_advantage_content,json=localAdvantageContent,proto3,oneof" json:"local_advantage_content,omitempty"`
+	ClosingContent        *string                `protobuf:"bytes,16,opt,name=closing_content,json=closingContent,proto3,oneof" json:"closing_content,omitempty"`
+	SelectedPackageId     *string                `protobuf:"bytes,17,opt,name=selected_package_id,json=selectedPackageId,proto3,oneof" json:"selected_package_id,omitempty"`
+	SelectedAddons        *string                `protobuf:"bytes,18,opt,name=selected_addons,json=selectedAddons,proto3,oneof" json:"selected_addons,omitempty"`
+	CustomPricing         *string                `protobuf:"bytes,19,opt,name=custom_pricing,json=customPricing,proto3,oneof" json:"custom_pricing,omitempty"`
+	ValidUntil            *string                `protobuf:"bytes,20,opt,name=valid_until,json=validUntil,proto3,oneof" json:"valid_until,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *EditProposalRequest) Reset() {
+	*x = EditProposalRequest{}
+	mi := &file_proposal_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditProposalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditProposalRequest) ProtoMessage() {}
+
+func (x *EditProposalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proposal_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditProposalRequest.ProtoReflect.Descriptor instead.
+func (*EditProposalRequest) Descriptor() ([]byte, []int) {
+	return file_proposal_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *EditProposalRequest) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetClientBusinessName() string {
+	if x != nil && x.ClientBusinessName != nil {
+		return *x.ClientBusinessName
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetClientContactName() string {
+	if x != nil && x.ClientContactName != nil {
+		return *x.ClientContactName
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetClientEmail() string {
+	if x != nil && x.ClientEmail != nil {
+		return *x.ClientEmail
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetClientPhone() string {
+	if x != nil && x.ClientPhone != nil {
+		return *x.ClientPhone
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetClientWebsite() string {
+	if x != nil && x.ClientWebsite != nil {
+		return *x.ClientWebsite
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetCoverImage() string {
+	if x != nil && x.CoverImage != nil {
+		return *x.CoverImage
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetExecutiveSummary() string {
+	if x != nil && x.ExecutiveSummary != nil {
+		return *x.ExecutiveSummary
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetPerformanceData() string {
+	if x != nil && x.PerformanceData != nil {
+		return *x.PerformanceData
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetCurrentIssues() string {
+	if x != nil && x.CurrentIssues != nil {
+		return *x.CurrentIssues
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetComplianceIssues() string {
+	if x != nil && x.ComplianceIssues != nil {
+		return *x.ComplianceIssues
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetPerformanceStandards() string {
+	if x != nil && x.PerformanceStandards != nil {
+		return *x.PerformanceStandards
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetLocalAdvantageContent() string {
+	if x != nil && x.LocalAdvantageContent != nil {
+		return *x.LocalAdvantageContent
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetClosingContent() string {
+	if x != nil && x.ClosingContent != nil {
+		return *x.ClosingContent
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetSelectedPackageId() string {
+	if x != nil && x.SelectedPackageId != nil {
+		return *x.SelectedPackageId
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetSelectedAddons() string {
+	if x != nil && x.SelectedAddons != nil {
+		return *x.SelectedAddons
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetCustomPricing() string {
+	if x != nil && x.CustomPricing != nil {
+		return *x.CustomPricing
+	}
+	return ""
+}
+
+func (x *EditProposalRequest) GetValidUntil() string {
+	if x != nil && x.ValidUntil != nil {
+		return *x.ValidUntil
+	}
+	return ""
+}
+
+type ProposalSectionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgencyId      string                 `protobuf:"bytes,1,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Section       string                 `protobuf:"bytes,3,opt,name=section,proto3" json:"section,omitempty"`
+	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProposalSectionRequest) Reset() {
+	*x = ProposalSectionRequest{}
+	mi := &file_proposal_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProposalSectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProposalSectionRequest) ProtoMessage() {}
+
+func (x *ProposalSectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proposal_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProposalSectionRequest.ProtoReflect.Descriptor instead.
+func (*ProposalSectionRequest) Descriptor() ([]byte, []int) {
+	return file_proposal_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ProposalSectionRequest) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *ProposalSectionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ProposalSectionRequest) GetSection() string {
+	if x != nil {
+		return x.Section
+	}
+	return ""
+}
+
+func (x *ProposalSectionRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type ProposalStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgencyId      string                 `protobuf:"bytes,1,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Notes         string                 `protobuf:"bytes,4,opt,name=notes,proto3" json:"notes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProposalStatusRequest) Reset() {
+	*x = ProposalStatusRequest{}
+	mi := &file_proposal_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProposalStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProposalStatusRequest) ProtoMessage() {}
+
+func (x *ProposalStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proposal_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProposalStatusRequest.ProtoReflect.Descriptor instead.
+func (*ProposalStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proposal_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ProposalStatusRequest) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *ProposalStatusRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ProposalStatusRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ProposalStatusRequest) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+var File_proposal_proto protoreflect.FileDescriptor
+
+const file_proposal_proto_rawDesc = "" +
+	"\n" +
+	"\x0eproposal.proto\x12\x05proto\"\xa8\f\n" +
+	"\bProposal\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\tR\tupdatedAt\x12\x1b\n" +
+	"\tagency_id\x18\x04 \x01(\tR\bagencyId\x12'\n" +
+	"\x0fconsultation_id\x18\x05 \x01(\tR\x0econsultationId\x12\x1b\n" +
+	"\tclient_id\x18\x06 \x01(\tR\bclientId\x12'\n" +
+	"\x0fproposal_number\x18\a \x01(\tR\x0eproposalNumber\x12\x12\n" +
+	"\x04slug\x18\b \x01(\tR\x04slug\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x120\n" +
+	"\x14client_business_name\x18\n" +
+	" \x01(\tR\x12clientBusinessName\x12.\n" +
+	"\x13client_contact_name\x18\v \x01(\tR\x11clientContactName\x12!\n" +
+	"\fclient_email\x18\f \x01(\tR\vclientEmail\x12!\n" +
+	"\fclient_phone\x18\r \x01(\tR\vclientPhone\x12%\n" +
+	"\x0eclient_website\x18\x0e \x01(\tR\rclientWebsite\x12\x14\n" +
+	"\x05title\x18\x0f \x01(\tR\x05title\x12\x1f\n" +
+	"\vcover_image\x18\x10 \x01(\tR\n" +
+	"coverImage\x12+\n" +
+	"\x11executive_summary\x18\x11 \x01(\tR\x10executiveSummary\x12)\n" +
+	"\x10performance_data\x18\x12 \x01(\tR\x0fperformanceData\x12/\n" +
+	"\x13opportunity_content\x18\x13 \x01(\tR\x12opportunityContent\x12%\n" +
+	"\x0ecurrent_issues\x18\x14 \x01(\tR\rcurrentIssues\x12+\n" +
+	"\x11compliance_issues\x18\x15 \x01(\tR\x10complianceIssues\x12!\n" +
+	"\froi_analysis\x18\x16 \x01(\tR\vroiAnalysis\x123\n" +
+	"\x15performance_standards\x18\x17 \x01(\tR\x14performanceStandards\x126\n" +
+	"\x17local_advantage_content\x18\x18 \x01(\tR\x15localAdvantageContent\x12%\n" +
+	"\x0eproposed_pages\x18\x19 \x01(\tR\rproposedPages\x12\x1a\n" +
+	"\btimeline\x18\x1a \x01(\tR\btimeline\x12'\n" +
+	"\x0fclosing_content\x18\x1b \x01(\tR\x0eclosingContent\x12\x1d\n" +
+	"\n" +
+	"next_steps\x18\x1c \x01(\tR\tnextSteps\x12.\n" +
+	"\x13selected_package_id\x18\x1d \x01(\tR\x11selectedPackageId\x12'\n" +
+	"\x0fselected_addons\x18\x1e \x01(\tR\x0eselectedAddons\x12%\n" +
+	"\x0ecustom_pricing\x18\x1f \x01(\tR\rcustomPricing\x12\x1f\n" +
+	"\vvalid_until\x18  \x01(\tR\n" +
+	"validUntil\x12\x1d\n" +
+	"\n" +
+	"view_count\x18! \x01(\x05R\tviewCount\x12$\n" +
+	"\x0elast_viewed_at\x18\" \x01(\tR\flastViewedAt\x12\x17\n" +
+	"\asent_at\x18# \x01(\tR\x06sentAt\x12\x1f\n" +
+	"\vaccepted_at\x18$ \x01(\tR\n" +
+	"acceptedAt\x12\x1f\n" +
+	"\vdeclined_at\x18% \x01(\tR\n" +
+	"declinedAt\x122\n" +
+	"\x15revision_requested_at\x18& \x01(\tR\x13revisionRequestedAt\x12'\n" +
+	"\x0fclient_comments\x18' \x01(\tR\x0eclientComments\x12%\n" +
+	"\x0edecline_reason\x18( \x01(\tR\rdeclineReason\x124\n" +
+	"\x16revision_request_notes\x18) \x01(\tR\x14revisionRequestNotes\x12\x1d\n" +
+	"\n" +
+	"created_by\x18* \x01(\tR\tcreatedBy\"9\n" +
+	"\n" +
+	"ProposalID\x12\x1b\n" +
+	"\tagency_id\x18\x01 \x01(\tR\bagencyId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"t\n" +
+	"\x13ProposalListRequest\x12\x1b\n" +
+	"\tagency_id\x18\x01 \x01(\tR\bagencyId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x03R\x04page\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x03R\x05limit\"\xa3\x01\n" +
+	"\x15CreateProposalRequest\x12\x1b\n" +
+	"\tagency_id\x18\x01 \x01(\tR\bagencyId\x12'\n" +
+	"\x0fconsultation_id\x18\x02 \x01(\tR\x0econsultationId\x12.\n" +
+	"\x13selected_package_id\x18\x03 \x01(\tR\x11selectedPackageId\x12\x14\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\"\xf2\t\n" +
+	"\x13EditProposalRequest\x12\x1b\n" +
+	"\tagency_id\x18\x01 \x01(\tR\bagencyId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x125\n" +
+	"\x14client_business_name\x18\x03 \x01(\tH\x00R\x12clientBusinessName\x88\x01\x01\x123\n" +
+	"\x13client_contact_name\x18\x04 \x01(\tH\x01R\x11clientContactName\x88\x01\x01\x12&\n" +
+	"\fclient_email\x18\x05 \x01(\tH\x02R\vclientEmail\x88\x01\x01\x12&\n" +
+	"\fclient_phone\x18\x06 \x01(\tH\x03R\vclientPhone\x88\x01\x01\x12*\n" +
+	"\x0eclient_website\x18\a \x01(\tH\x04R\rclientWebsite\x88\x01\x01\x12\x19\n" +
+	"\x05title\x18\b \x01(\tH\x05R\x05title\x88\x01\x01\x12$\n" +
+	"\vcover_image\x18\t \x01(\tH\x06R\n" +
+	"coverImage\x88\x01\x01\x120\n" +
+	"\x11executive_summary\x18\n" +
+	" \x01(\tH\aR\x10executiveSummary\x88\x01\x01\x12.\n" +
+	"\x10performance_data\x18\v \x01(\tH\bR\x0fperformanceData\x88\x01\x01\x12*\n" +
+	"\x0ecurrent_issues\x18\f \x01(\tH\tR\rcurrentIssues\x88\x01\x01\x120\n" +
+	"\x11compliance_issues\x18\r \x01(\tH\n" +
+	"R\x10complianceIssues\x88\x01\x01\x128\n" +
+	"\x15performance_standards\x18\x0e \x01(\tH\vR\x14performanceStandards\x88\x01\x01\x12;\n" +
+	"\x17local_advantage_content\x18\x0f \x01(\tH\fR\x15localAdvantageContent\x88\x01\x01\x12,\n" +
+	"\x0fclosing_content\x18\x10 \x01(\tH\rR\x0eclosingContent\x88\x01\x01\x123\n" +
+	"\x13selected_package_id\x18\x11 \x01(\tH\x0eR\x11selectedPackageId\x88\x01\x01\x12,\n" +
+	"\x0fselected_addons\x18\x12 \x01(\tH\x0fR\x0eselectedAddons\x88\x01\x01\x12*\n" +
+	"\x0ecustom_pricing\x18\x13 \x01(\tH\x10R\rcustomPricing\x88\x01\x01\x12$\n" +
+	"\vvalid_until\x18\x14 \x01(\tH\x11R\n" +
+	"validUntil\x88\x01\x01B\x17\n" +
+	"\x15_client_business_nameB\x16\n" +
+	"\x14_client_contact_nameB\x0f\n" +
+	"\r_client_emailB\x0f\n" +
+	"\r_client_phoneB\x11\n" +
+	"\x0f_client_websiteB\b\n" +
+	"\x06_titleB\x0e\n" +
+	"\f_cover_imageB\x14\n" +
+	"\x12_executive_summaryB\x13\n" +
+	"\x11_performance_dataB\x11\n" +
+	"\x0f_current_issuesB\x14\n" +
+	"\x12_compliance_issuesB\x18\n" +
+	"\x16_performance_standardsB\x1a\n" +
+	"\x18_local_advantage_contentB\x12\n" +
+	"\x10_closing_contentB\x16\n" +
+	"\x14_selected_package_idB\x12\n" +
+	"\x10_selected_addonsB\x11\n" +
+	"\x0f_custom_pricingB\x0e\n" +
+	"\f_valid_until\"y\n" +
+	"\x16ProposalSectionRequest\x12\x1b\n" +
+	"\tagency_id\x18\x01 \x01(\tR\bagencyId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x18\n" +
+	"\asection\x18\x03 \x01(\tR\asection\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\"r\n" +
+	"\x15ProposalStatusRequest\x12\x1b\n" +
+	"\tagency_id\x18\x01 \x01(\tR\bagencyId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x14\n" +
+	"\x05notes\x18\x04 \x01(\tR\x05notesB\x0eZ\fgofast/protob\x06proto3"
+
+var (
+	file_proposal_proto_rawDescOnce sync.Once
+	file_proposal_proto_rawDescData []byte
+)
+
+func file_proposal_proto_rawDescGZIP() []byte {
+	file_proposal_proto_rawDescOnce.Do(func() {
+		file_proposal_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proposal_proto_rawDesc), len(file_proposal_proto_rawDesc)))
+	})
+	return file_proposal_proto_rawDescData
+}
+
+var file_proposal_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_proposal_proto_goTypes = []any{
+	(*Proposal)(nil),               // 0: proto.Proposal
+	(*ProposalID)(nil),             // 1: proto.ProposalID
+	(*ProposalListRequest)(nil),    // 2: proto.ProposalListRequest
+	(*CreateProposalRequest)(nil),  // 3: proto.CreateProposalRequest
+	(*EditProposalRequest)(nil),    // 4: proto.EditProposalRequest
+	(*ProposalSectionRequest)(nil), // 5: proto.ProposalSectionRequest
+	(*ProposalStatusRequest)(nil),  // 6: proto.ProposalStatusRequest
+}
+var file_proposal_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_proposal_proto_init() }
+func file_proposal_proto_init() {
+	if File_proposal_proto != nil {
+		return
+	}
+	file_proposal_proto_msgTypes[4].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proposal_proto_rawDesc), len(file_proposal_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proposal_proto_goTypes,
+		DependencyIndexes: file_proposal_proto_depIdxs,
+		MessageInfos:      file_proposal_proto_msgTypes,
+	}.Build()
+	File_proposal_proto = out.File
+	file_proposal_proto_goTypes = nil
+	file_proposal_proto_depIdxs = nil
+}
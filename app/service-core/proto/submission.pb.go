@@ -0,0 +1,365 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v6.31.1
+// source: submission.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FormSubmission struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt          string                 `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	AgencyId           string                 `protobuf:"bytes,3,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	FormId             string                 `protobuf:"bytes,4,opt,name=form_id,json=formId,proto3" json:"form_id,omitempty"`
+	FormVersion        int32                  `protobuf:"varint,5,opt,name=form_version,json=formVersion,proto3" json:"form_version,omitempty"`
+	ClientId           string                 `protobuf:"bytes,6,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	ConsultationId     string                 `protobuf:"bytes,7,opt,name=consultation_id,json=consultationId,proto3" json:"consultation_id,omitempty"`
+	Status             string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`
+	ClientBusinessName string                 `protobuf:"bytes,9,opt,name=client_business_name,json=clientBusinessName,proto3" json:"client_business_name,omitempty"`
+	ClientEmail        string                 `protobuf:"bytes,10,opt,name=client_email,json=clientEmail,proto3" json:"client_email,omitempty"`
+	SubmittedAt        string                 `protobuf:"bytes,11,opt,name=submitted_at,json=submittedAt,proto3" json:"submitted_at,omitempty"`
+	ProcessedAt        string                 `protobuf:"bytes,12,opt,name=processed_at,json=processedAt,proto3" json:"processed_at,omitempty"`
+	ProcessingAttempts int32                  `protobuf:"varint,13,opt,name=processing_attempts,json=processingAttempts,proto3" json:"processing_attempts,omitempty"`
+	ProcessingError    string                 `protobuf:"bytes,14,opt,name=processing_error,json=processingError,proto3" json:"processing_error,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *FormSubmission) Reset() {
+	*x = FormSubmission{}
+	mi := &file_submission_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FormSubmission) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FormSubmission) ProtoMessage() {}
+
+func (x *FormSubmission) ProtoReflect() protoreflect.Message {
+	mi := &file_submission_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FormSubmission.ProtoReflect.Descriptor instead.
+func (*FormSubmission) Descriptor() ([]byte, []int) {
+	return file_submission_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FormSubmission) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *FormSubmission) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *FormSubmission) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *FormSubmission) GetFormId() string {
+	if x != nil {
+		return x.FormId
+	}
+	return ""
+}
+
+func (x *FormSubmission) GetFormVersion() int32 {
+	if x != nil {
+		return x.FormVersion
+	}
+	return 0
+}
+
+func (x *FormSubmission) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *FormSubmission) GetConsultationId() string {
+	if x != nil {
+		return x.ConsultationId
+	}
+	return ""
+}
+
+func (x *FormSubmission) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *FormSubmission) GetClientBusinessName() string {
+	if x != nil {
+		return x.ClientBusinessName
+	}
+	return ""
+}
+
+func (x *FormSubmission) GetClientEmail() string {
+	if x != nil {
+		return x.ClientEmail
+	}
+	return ""
+}
+
+func (x *FormSubmission) GetSubmittedAt() string {
+	if x != nil {
+		return x.SubmittedAt
+	}
+	return ""
+}
+
+func (x *FormSubmission) GetProcessedAt() string {
+	if x != nil {
+		return x.ProcessedAt
+	}
+	return ""
+}
+
+func (x *FormSubmission) GetProcessingAttempts() int32 {
+	if x != nil {
+		return x.ProcessingAttempts
+	}
+	return 0
+}
+
+func (x *FormSubmission) GetProcessingError() string {
+	if x != nil {
+		return x.ProcessingError
+	}
+	return ""
+}
+
+type SubmissionID struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgencyId      string                 `protobuf:"bytes,1,opt,name=agency_id,json=agencyId,proto3" json:"agency_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmissionID) Reset() {
+	*x = SubmissionID{}
+	mi := &file_submission_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmissionID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmissionID) ProtoMessage() {}
+
+func (x *SubmissionID) ProtoReflect() protoreflect.Message {
+	mi := &file_submission_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmissionID.ProtoReflect.Descriptor instead.
+func (*SubmissionID) Descriptor() ([]byte, []int) {
+	return file_submission_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SubmissionID) GetAgencyId() string {
+	if x != nil {
+		return x.AgencyId
+	}
+	return ""
+}
+
+func (x *SubmissionID) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ProcessSubmissionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Submission    *FormSubmission        `protobuf:"bytes,1,opt,name=submission,proto3" json:"submission,omitempty"`
+	ClientCreated bool                   `protobuf:"varint,2,opt,name=client_created,json=clientCreated,proto3" json:"client_created,omitempty"`
+	Replayed      bool                   `protobuf:"varint,3,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessSubmissionResponse) Reset() {
+	*x = ProcessSubmissionResponse{}
+	mi := &file_submission_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessSubmissionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessSubmissionResponse) ProtoMessage() {}
+
+func (x *ProcessSubmissionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_submission_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessSubmissionResponse.ProtoReflect.Descriptor instead.
+func (*ProcessSubmissionResponse) Descriptor() ([]byte, []int) {
+	return file_submission_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProcessSubmissionResponse) GetSubmission() *FormSubmission {
+	if x != nil {
+		return x.Submission
+	}
+	return nil
+}
+
+func (x *ProcessSubmissionResponse) GetClientCreated() bool {
+	if x != nil {
+		return x.ClientCreated
+	}
+	return false
+}
+
+func (x *ProcessSubmissionResponse) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+var File_submission_proto protoreflect.FileDescriptor
+
+const file_submission_proto_rawDesc = "" +
+	"\n" +
+	"\x10submission.proto\x12\x05proto\"\xed\x03\n" +
+	"\x0eFormSubmission\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\tR\tcreatedAt\x12\x1b\n" +
+	"\tagency_id\x18\x03 \x01(\tR\bagencyId\x12\x17\n" +
+	"\aform_id\x18\x04 \x01(\tR\x06formId\x12!\n" +
+	"\fform_version\x18\x05 \x01(\x05R\vformVersion\x12\x1b\n" +
+	"\tclient_id\x18\x06 \x01(\tR\bclientId\x12'\n" +
+	"\x0fconsultation_id\x18\a \x01(\tR\x0econsultationId\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x120\n" +
+	"\x14client_business_name\x18\t \x01(\tR\x12clientBusinessName\x12!\n" +
+	"\fclient_email\x18\n" +
+	" \x01(\tR\vclientEmail\x12!\n" +
+	"\fsubmitted_at\x18\v \x01(\tR\vsubmittedAt\x12!\n" +
+	"\fprocessed_at\x18\f \x01(\tR\vprocessedAt\x12/\n" +
+	"\x13processing_attempts\x18\r \x01(\x05R\x12processingAttempts\x12)\n" +
+	"\x10processing_error\x18\x0e \x01(\tR\x0fprocessingError\";\n" +
+	"\fSubmissionID\x12\x1b\n" +
+	"\tagency_id\x18\x01 \x01(\tR\bagencyId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\x95\x01\n" +
+	"\x19ProcessSubmissionResponse\x125\n" +
+	"\n" +
+	"submission\x18\x01 \x01(\v2\x15.proto.FormSubmissionR\n" +
+	"submission\x12%\n" +
+	"\x0eclient_created\x18\x02 \x01(\bR\rclientCreated\x12\x1a\n" +
+	"\breplayed\x18\x03 \x01(\bR\breplayedB\x0eZ\fgofast/protob\x06proto3"
+
+var (
+	file_submission_proto_rawDescOnce sync.Once
+	file_submission_proto_rawDescData []byte
+)
+
+func file_submission_proto_rawDescGZIP() []byte {
+	file_submission_proto_rawDescOnce.Do(func() {
+		file_submission_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_submission_proto_rawDesc), len(file_submission_proto_rawDesc)))
+	})
+	return file_submission_proto_rawDescData
+}
+
+var file_submission_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_submission_proto_goTypes = []any{
+	(*FormSubmission)(nil),            // 0: proto.FormSubmission
+	(*SubmissionID)(nil),              // 1: proto.SubmissionID
+	(*ProcessSubmissionResponse)(nil), // 2: proto.ProcessSubmissionResponse
+}
+var file_submission_proto_depIdxs = []int32{
+	0, // 0: proto.ProcessSubmissionResponse.submission:type_name -> proto.FormSubmission
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_submission_proto_init() }
+func file_submission_proto_init() {
+	if File_submission_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_submission_proto_rawDesc), len(file_submission_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_submission_proto_goTypes,
+		DependencyIndexes: file_submission_proto_depIdxs,
+		MessageInfos:      file_submission_proto_msgTypes,
+	}.Build()
+	File_submission_proto = out.File
+	file_submission_proto_goTypes = nil
+	file_submission_proto_depIdxs = nil
+}
@@ -0,0 +1,141 @@
+package rest
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"service-core/domain/apikey"
+
+	"github.com/google/uuid"
+)
+
+// requireSession refuses requests made with an API key, so keys cannot be
+// used to create or revoke keys, or to manage sessions
+func requireSession(user *auth.AccessTokenClaims) error {
+	if user.APIKeyID != uuid.Nil {
+		return pkg.ForbiddenError{Err: errors.New("request requires a signed-in session, not an API key")}
+	}
+	return nil
+}
+
+func (h *Handler) handleAPIKeysCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), 0)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		response, err := h.apiKeyService.ListUserKeys(r.Context(), user.ID)
+		writeResponse(h.cfg, w, r, response, err)
+
+	case http.MethodPost:
+		if err := requireSession(user); err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+		var req apikey.CreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+		response, err := h.apiKeyService.CreateUserKey(r.Context(), user.ID, user.Access, req)
+		writeResponse(h.cfg, w, r, response, err)
+
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+	}
+}
+
+func (h *Handler) handleAPIKeyResource(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	id, err := parsePathID(r, "id", "API key")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), 0)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	if err := requireSession(user); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	response, err := h.apiKeyService.RevokeUserKey(r.Context(), user.ID, id)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+func (h *Handler) handleAgencyAPIKeysCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	agency, ok := GetAgencyFromContext(r)
+	user, found := GetUserFromContext(r)
+	if !ok || !found {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("agency not resolved")})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		response, err := h.apiKeyService.ListAgencyKeys(r.Context(), agency.ID)
+		writeResponse(h.cfg, w, r, response, err)
+
+	case http.MethodPost:
+		if err := requireSession(user); err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+		var req apikey.CreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+		response, err := h.apiKeyService.CreateAgencyKey(r.Context(), agency.ID, user.ID, user.Access, agency.Role, req)
+		writeResponse(h.cfg, w, r, response, err)
+
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+	}
+}
+
+func (h *Handler) handleAgencyAPIKeyResource(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	id, err := parsePathID(r, "id", "API key")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	agency, ok := GetAgencyFromContext(r)
+	user, found := GetUserFromContext(r)
+	if !ok || !found {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("agency not resolved")})
+		return
+	}
+	if err := requireSession(user); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	response, err := h.apiKeyService.RevokeAgencyKey(r.Context(), agency.ID, user.ID, id)
+	writeResponse(h.cfg, w, r, response, err)
+}
@@ -30,7 +30,7 @@ type BillingUpgradeRequest struct {
 // If sessionId is provided, it will auto-sync from Stripe if DB is behind.
 func (h *Handler) handleBillingInfo(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
 		return
 	}
 
@@ -61,7 +61,7 @@ func (h *Handler) handleBillingInfo(w http.ResponseWriter, r *http.Request) {
 // handleBillingCheckout creates a Stripe Checkout session for subscription
 func (h *Handler) handleBillingCheckout(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
 		return
 	}
 
@@ -103,7 +103,7 @@ func (h *Handler) handleBillingCheckout(w http.ResponseWriter, r *http.Request)
 // handleBillingPortal creates a Stripe Billing Portal session
 func (h *Handler) handleBillingPortal(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
 		return
 	}
 
@@ -133,7 +133,7 @@ func (h *Handler) handleBillingPortal(w http.ResponseWriter, r *http.Request) {
 // handleBillingSessionStatus returns the status of a checkout session from Stripe
 func (h *Handler) handleBillingSessionStatus(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
 		return
 	}
 
@@ -155,7 +155,7 @@ func (h *Handler) handleBillingSessionStatus(w http.ResponseWriter, r *http.Requ
 // handleBillingUpgrade upgrades an existing subscription with proration
 func (h *Handler) handleBillingUpgrade(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
 		return
 	}
 
@@ -195,7 +195,7 @@ func (h *Handler) handleBillingUpgrade(w http.ResponseWriter, r *http.Request) {
 // handleBillingSyncSession syncs subscription from a completed checkout session
 func (h *Handler) handleBillingSyncSession(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
 		return
 	}
 
@@ -217,7 +217,7 @@ func (h *Handler) handleBillingSyncSession(w http.ResponseWriter, r *http.Reques
 // handleBillingWebhook processes Stripe billing webhooks
 func (h *Handler) handleBillingWebhook(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Method not allowed"})
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
 		return
 	}
 
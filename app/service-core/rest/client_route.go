@@ -0,0 +1,209 @@
+package rest
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"encoding/json"
+	"net/http"
+	"service-core/domain/client"
+	"strconv"
+)
+
+func (h *Handler) handleClientsCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	token := extractAccessToken(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		_, err := h.authService.Auth(token, auth.GetClients)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		page, _ := strconv.ParseInt(r.URL.Query().Get("page"), 10, 32)
+		limit, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 32)
+		status := r.URL.Query().Get("status")
+		search := r.URL.Query().Get("search")
+
+		response, err := h.clientService.ListClients(r.Context(), agencyID, status, search, int32(page), int32(limit))
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	case http.MethodPost:
+		user, err := h.authService.Auth(token, auth.CreateClient)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		var req client.CreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+
+		response, err := h.clientService.CreateClient(r.Context(), agencyUser(r, user), req)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+}
+
+func (h *Handler) handleClientResource(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	clientID, err := parsePathID(r, "id", "client")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	token := extractAccessToken(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		_, err := h.authService.Auth(token, auth.GetClients)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		response, err := h.clientService.GetClient(r.Context(), agencyID, clientID)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	case http.MethodPut:
+		user, err := h.authService.Auth(token, auth.EditClient)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		var req client.UpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+
+		response, err := h.clientService.UpdateClient(r.Context(), agencyUser(r, user), clientID, req)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	case http.MethodDelete:
+		user, err := h.authService.Auth(token, auth.RemoveClient)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		err = h.clientService.DeleteClient(r.Context(), agencyUser(r, user), clientID)
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+}
+
+// handleClientStatus archives or restores a client
+func (h *Handler) handleClientStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	clientID, err := parsePathID(r, "id", "client")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.EditClient)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req client.StatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+
+	response, err := h.clientService.SetStatus(r.Context(), agencyUser(r, user), clientID, req)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleClientDuplicates lists groups of clients that appear to be the same
+// business
+func (h *Handler) handleClientDuplicates(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	_, err = h.authService.Auth(extractAccessToken(r), auth.GetClients)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	response, err := h.clientService.FindDuplicates(r.Context(), agencyID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleClientMerge merges duplicate clients into one
+func (h *Handler) handleClientMerge(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	// Merging deletes the source clients
+	user, err := h.authService.Auth(extractAccessToken(r), auth.RemoveClient)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req client.MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+
+	response, err := h.clientService.MergeClients(r.Context(), agencyUser(r, user), req)
+	writeResponse(h.cfg, w, r, response, err)
+}
@@ -0,0 +1,217 @@
+package rest
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"encoding/json"
+	"net/http"
+	"service-core/domain/consultation"
+	"strconv"
+)
+
+// parseVersion reads a version number from the request path
+func parseVersion(r *http.Request) (int32, error) {
+	n, err := strconv.ParseInt(r.PathValue("version"), 10, 32)
+	if err != nil || n < 1 {
+		return 0, pkg.BadRequestError{Message: "Invalid version number", Err: err}
+	}
+	return int32(n), nil
+}
+
+func (h *Handler) handleConsultationsCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	token := extractAccessToken(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		_, err := h.authService.Auth(token, auth.GetConsultations)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		page, _ := strconv.ParseInt(r.URL.Query().Get("page"), 10, 32)
+		limit, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 32)
+		status := r.URL.Query().Get("status")
+
+		response, err := h.consultationService.ListConsultations(r.Context(), agencyID, status, int32(page), int32(limit))
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	case http.MethodPost:
+		user, err := h.authService.Auth(token, auth.CreateConsultation)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		var req consultation.CreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+
+		response, err := h.consultationService.CreateConsultation(r.Context(), agencyUser(r, user), req)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+}
+
+func (h *Handler) handleConsultationResource(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	consultationID, err := parsePathID(r, "id", "consultation")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	token := extractAccessToken(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		_, err := h.authService.Auth(token, auth.GetConsultations)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		response, err := h.consultationService.GetConsultation(r.Context(), agencyID, consultationID)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	case http.MethodPut:
+		user, err := h.authService.Auth(token, auth.EditConsultation)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		var req consultation.UpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+
+		response, err := h.consultationService.UpdateConsultation(r.Context(), agencyUser(r, user), consultationID, req)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+}
+
+// handleConsultationVersions lists a consultation's version history
+func (h *Handler) handleConsultationVersions(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	consultationID, err := parsePathID(r, "id", "consultation")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	_, err = h.authService.Auth(extractAccessToken(r), auth.GetConsultations)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	response, err := h.consultationService.ListVersions(r.Context(), agencyID, consultationID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleConsultationVersion returns one version of a consultation
+func (h *Handler) handleConsultationVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	consultationID, err := parsePathID(r, "id", "consultation")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	version, err := parseVersion(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	_, err = h.authService.Auth(extractAccessToken(r), auth.GetConsultations)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	response, err := h.consultationService.GetVersion(r.Context(), agencyID, consultationID, version)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleConsultationRestore restores a consultation to a prior version
+func (h *Handler) handleConsultationRestore(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	consultationID, err := parsePathID(r, "id", "consultation")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	version, err := parseVersion(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.EditConsultation)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	response, err := h.consultationService.RestoreVersion(r.Context(), agencyUser(r, user), consultationID, version)
+	writeResponse(h.cfg, w, r, response, err)
+}
@@ -0,0 +1,293 @@
+package rest
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"encoding/json"
+	"net/http"
+	"service-core/domain/contract"
+	"strconv"
+)
+
+// signingOrigin records where a signature request came from
+func signingOrigin(r *http.Request) contract.Origin {
+	return contract.Origin{
+		IPAddress: getClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+func (h *Handler) handleContractsCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	token := extractAccessToken(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		_, err := h.authService.Auth(token, auth.GetContracts)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		page, _ := strconv.ParseInt(r.URL.Query().Get("page"), 10, 32)
+		limit, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 32)
+		status := r.URL.Query().Get("status")
+
+		response, err := h.contractService.ListContracts(r.Context(), agencyID, status, int32(page), int32(limit))
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	case http.MethodPost:
+		user, err := h.authService.Auth(token, auth.CreateContract)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		var req contract.CreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+
+		response, err := h.contractService.CreateContract(r.Context(), agencyUser(r, user), req)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+}
+
+func (h *Handler) handleContractResource(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	contractID, err := parsePathID(r, "id", "contract")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	token := extractAccessToken(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		_, err := h.authService.Auth(token, auth.GetContracts)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		response, err := h.contractService.GetContract(r.Context(), agencyID, contractID)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	case http.MethodPut:
+		user, err := h.authService.Auth(token, auth.EditContract)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		var req contract.UpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+
+		response, err := h.contractService.UpdateContract(r.Context(), agencyUser(r, user), contractID, req)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	case http.MethodDelete:
+		user, err := h.authService.Auth(token, auth.RemoveContract)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		err = h.contractService.DeleteContract(r.Context(), agencyUser(r, user), contractID)
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+}
+
+// handleContractStatus moves a contract to a new status
+func (h *Handler) handleContractStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	contractID, err := parsePathID(r, "id", "contract")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.EditContract)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req contract.TransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+
+	response, err := h.contractService.TransitionContract(r.Context(), agencyUser(r, user), contractID, req)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleContractSign records the agency's signature on a contract
+func (h *Handler) handleContractSign(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	contractID, err := parsePathID(r, "id", "contract")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.EditContract)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req contract.SignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+
+	response, err := h.contractService.SignAsAgency(r.Context(), agencyUser(r, user), contractID, req, signingOrigin(r))
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleContractVerify checks a contract's content and signatures against
+// their stored hashes
+func (h *Handler) handleContractVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	contractID, err := parsePathID(r, "id", "contract")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	_, err = h.authService.Auth(extractAccessToken(r), auth.GetContracts)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	response, err := h.contractService.VerifyContract(r.Context(), agencyID, contractID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleContractPDF renders and stores a contract PDF
+func (h *Handler) handleContractPDF(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	contractID, err := parsePathID(r, "id", "contract")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.GetContracts)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	response, err := h.pdfService.GenerateContract(r.Context(), agencyID, user.ID, contractID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleContractView records a view of a contract's public page and returns
+// the contract (no auth required)
+func (h *Handler) handleContractView(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+
+	response, err := h.contractService.RecordView(r.Context(), r.PathValue("slug"))
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleContractClientSign records the client's signature from the public
+// contract page (no auth required)
+func (h *Handler) handleContractClientSign(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+
+	var req contract.SignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+
+	response, err := h.contractService.SignAsClient(r.Context(), r.PathValue("slug"), req, signingOrigin(r))
+	writeResponse(h.cfg, w, r, response, err)
+}
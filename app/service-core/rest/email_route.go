@@ -3,8 +3,10 @@ package rest
 import (
 	"app/pkg"
 	"app/pkg/auth"
+	"io"
 	"log/slog"
 	"net/http"
+	"service-core/domain/email"
 
 	"github.com/google/uuid"
 )
@@ -20,7 +22,7 @@ func (h *Handler) handleEmails(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		emails, err := h.emailService.GetEmails(r.Context(), user.ID)
+		emails, err := h.emailService.GetEmails(r.Context(), user.ID, r.URL.Query().Get("status"))
 		writeResponse(h.cfg, w, r, emails, err)
 		return
 
@@ -50,7 +52,27 @@ func (h *Handler) handleEmails(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
-		response, err := h.emailService.SendEmail(r.Context(), user.ID, emailTo, emailSubject, emailBody, parsedAttachmentIDs)
+		// An email sent for an agency may name the email log it is recorded in
+		var emailLogID uuid.NullUUID
+		if idStr := r.FormValue("email_log_id"); idStr != "" {
+			parsedID, err := uuid.Parse(idStr)
+			if err != nil {
+				writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid email log ID format", Err: err})
+				return
+			}
+			emailLogID = uuid.NullUUID{UUID: parsedID, Valid: true}
+		}
+
+		response, err := h.emailService.Queue(r.Context(), email.Message{
+			UserID:        user.ID,
+			AgencyID:      user.AgencyID,
+			To:            emailTo,
+			Subject:       emailSubject,
+			Body:          emailBody,
+			AttachmentIDs: parsedAttachmentIDs,
+			EmailLogID:    emailLogID,
+			Bulk:          r.FormValue("bulk") == "true",
+		})
 		writeResponse(h.cfg, w, r, response, err)
 		return
 
@@ -63,3 +85,80 @@ func (h *Handler) handleEmails(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (h *Handler) handleEmailResend(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.SendEmail)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	id, err := parsePathID(r, "id", "email")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	response, err := h.emailService.Resend(r.Context(), user.ID, id)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleEmailWebhook records the delivery events an email provider reports.
+// Requests are authenticated by the provider's signature rather than a
+// user's token.
+func (h *Handler) handleEmailWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+
+	// SendGrid batches events, so payloads can be larger than a single event
+	const MaxBodyBytes = int64(1 << 20)
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{
+			Message: "Error reading request body",
+			Err:     err,
+		})
+		return
+	}
+
+	err = h.emailService.HandleWebhook(r.Context(), r.PathValue("provider"), r.Header, payload)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleEmailUnsubscribe serves the unsubscribe link of bulk emails. It is
+// public: the token in the link is the recipient's only credential. Mail
+// clients post to it to unsubscribe in one click (RFC 8058), and a
+// recipient who opens the link is asked to confirm first.
+func (h *Handler) handleEmailUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	var page string
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		page, err = h.emailService.UnsubscribePage(r.Context(), r.PathValue("token"), false)
+	case http.MethodPost:
+		page, err = h.emailService.UnsubscribePage(r.Context(), r.PathValue("token"), true)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, page)
+}
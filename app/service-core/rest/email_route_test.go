@@ -0,0 +1,55 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/url"
+	"service-core/storage/query"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEmailAttachmentRoutes(t *testing.T) {
+	t.Parallel()
+	fileID := uuid.MustParse("00000000-0000-0000-0000-000000000030")
+	missingID := uuid.MustParse("00000000-0000-0000-0000-000000000031")
+
+	// Emails can attach the files their sender may download
+	tests := []struct {
+		name       string
+		agencyID   uuid.NullUUID
+		user       string
+		attachment uuid.UUID
+		want       int
+	}{
+		{"personal file/owner", uuid.NullUUID{}, "owner", fileID, http.StatusOK},
+		{"personal file/other member", uuid.NullUUID{}, "other member", fileID, http.StatusForbidden},
+		{"personal file/other agency", uuid.NullUUID{}, "other agency", fileID, http.StatusForbidden},
+		{"personal file/admin", uuid.NullUUID{}, "admin", fileID, http.StatusForbidden},
+		{"agency file/owner", uuid.NullUUID{UUID: agencyID, Valid: true}, "owner", fileID, http.StatusOK},
+		{"agency file/other member", uuid.NullUUID{UUID: agencyID, Valid: true}, "other member", fileID, http.StatusOK},
+		{"agency file/other agency", uuid.NullUUID{UUID: agencyID, Valid: true}, "other agency", fileID, http.StatusForbidden},
+		{"agency file/admin", uuid.NullUUID{UUID: agencyID, Valid: true}, "admin", fileID, http.StatusOK},
+		{"missing file", uuid.NullUUID{}, "owner", missingID, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newMockStore()
+			store.files[fileID] = query.File{ID: fileID, UserID: ownerID, FileName: "INV-0001.pdf", AgencyID: tt.agencyID}
+			form := url.Values{
+				"email_to":       {"client@example.com"},
+				"email_subject":  {"Your invoice"},
+				"email_body":     {"Please find your invoice attached."},
+				"attachment_ids": {tt.attachment.String()},
+			}
+
+			if got := serve(newRouter(store), http.MethodPost, "/api/v1/emails", tt.user, form); got != tt.want {
+				t.Fatalf("POST = %d, want %d", got, tt.want)
+			}
+			if sent := store.emails > 0; sent != (tt.want == http.StatusOK) {
+				t.Errorf("email queued = %t, want %t", sent, !sent)
+			}
+		})
+	}
+}
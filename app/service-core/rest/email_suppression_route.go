@@ -0,0 +1,63 @@
+package rest
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"service-core/domain/email"
+)
+
+func (h *Handler) handleEmailSuppressionsCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	agency, ok := GetAgencyFromContext(r)
+	if !ok {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("agency not resolved")})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		response, err := h.emailService.ListSuppressions(r.Context(), agency.ID)
+		writeResponse(h.cfg, w, r, response, err)
+
+	case http.MethodPost:
+		var req email.SuppressionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+		response, err := h.emailService.AddSuppression(r.Context(), agency.ID, req)
+		writeResponse(h.cfg, w, r, response, err)
+
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+	}
+}
+
+func (h *Handler) handleEmailSuppressionResource(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agency, ok := GetAgencyFromContext(r)
+	if !ok {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("agency not resolved")})
+		return
+	}
+	id, err := parsePathID(r, "id", "suppression")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	err = h.emailService.RemoveSuppression(r.Context(), agency.ID, id)
+	writeResponse(h.cfg, w, r, nil, err)
+}
@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"app/pkg"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"service-core/domain/email"
+)
+
+func (h *Handler) handleEmailTemplatesCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agency, ok := GetAgencyFromContext(r)
+	if !ok {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("agency not resolved")})
+		return
+	}
+
+	response, err := h.emailService.ListTemplates(r.Context(), agency.ID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+func (h *Handler) handleEmailTemplateResource(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	agency, ok := GetAgencyFromContext(r)
+	user, found := GetUserFromContext(r)
+	if !ok || !found {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("agency not resolved")})
+		return
+	}
+	name := r.PathValue("name")
+
+	switch r.Method {
+	case http.MethodGet:
+		response, err := h.emailService.GetTemplate(r.Context(), agency.ID, name)
+		writeResponse(h.cfg, w, r, response, err)
+
+	case http.MethodPut:
+		var req email.TemplateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+		response, err := h.emailService.SaveTemplate(r.Context(), agency.ID, user.ID, name, req)
+		writeResponse(h.cfg, w, r, response, err)
+
+	case http.MethodDelete:
+		err := h.emailService.RevertTemplate(r.Context(), agency.ID, name)
+		writeResponse(h.cfg, w, r, nil, err)
+
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+	}
+}
+
+func (h *Handler) handleEmailTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agency, ok := GetAgencyFromContext(r)
+	if !ok {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("agency not resolved")})
+		return
+	}
+
+	response, err := h.emailService.GetTemplateVersions(r.Context(), agency.ID, r.PathValue("name"))
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+func (h *Handler) handleEmailTemplatePreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agency, ok := GetAgencyFromContext(r)
+	if !ok {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("agency not resolved")})
+		return
+	}
+
+	var req email.PreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+	response, err := h.emailService.PreviewTemplate(r.Context(), agency.ID, r.PathValue("name"), req)
+	writeResponse(h.cfg, w, r, response, err)
+}
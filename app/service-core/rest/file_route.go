@@ -71,13 +71,13 @@ func (h *Handler) handleFileResource(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case http.MethodGet:
-		_, errAuth := h.authService.Auth(token, auth.DownloadFile)
+		user, errAuth := h.authService.Auth(token, auth.DownloadFile)
 		if errAuth != nil {
 			writeResponse(h.cfg, w, r, nil, errAuth)
 			return
 		}
 
-		fileInfo, dataBytes, errDownload := h.fileService.DownloadFile(r.Context(), id)
+		fileInfo, dataBytes, errDownload := h.fileService.DownloadFile(r.Context(), user.Attr(), id)
 		if errDownload != nil {
 			writeResponse(h.cfg, w, r, nil, errDownload)
 			return
@@ -96,13 +96,13 @@ func (h *Handler) handleFileResource(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case http.MethodDelete:
-		_, errAuth := h.authService.Auth(token, auth.RemoveFile)
+		user, errAuth := h.authService.Auth(token, auth.RemoveFile)
 		if errAuth != nil {
 			writeResponse(h.cfg, w, r, nil, errAuth)
 			return
 		}
 
-		errRemove := h.fileService.RemoveFile(r.Context(), id)
+		errRemove := h.fileService.RemoveFile(r.Context(), user.Attr(), id)
 		writeResponse(h.cfg, w, r, nil, errRemove)
 		return
 
@@ -0,0 +1,60 @@
+package rest_test
+
+import (
+	"net/http"
+	"service-core/storage/query"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestFileRoutes(t *testing.T) {
+	t.Parallel()
+	fileID := uuid.MustParse("00000000-0000-0000-0000-000000000020")
+	path := "/api/v1/files/" + fileID.String()
+
+	// Uploaded files are personal. An agency's documents can be read by its
+	// members, and removed by their owner and the agency's admins.
+	tests := []struct {
+		name     string
+		agencyID uuid.NullUUID
+		user     string
+		get      int
+		delete   int
+	}{
+		{"personal file/owner", uuid.NullUUID{}, "owner", http.StatusOK, http.StatusNoContent},
+		{"personal file/other member", uuid.NullUUID{}, "other member", http.StatusForbidden, http.StatusForbidden},
+		{"personal file/other agency", uuid.NullUUID{}, "other agency", http.StatusForbidden, http.StatusForbidden},
+		{"personal file/admin", uuid.NullUUID{}, "admin", http.StatusForbidden, http.StatusForbidden},
+		{"agency file/owner", uuid.NullUUID{UUID: agencyID, Valid: true}, "owner", http.StatusOK, http.StatusNoContent},
+		{"agency file/other member", uuid.NullUUID{UUID: agencyID, Valid: true}, "other member", http.StatusOK, http.StatusForbidden},
+		{"agency file/other agency", uuid.NullUUID{UUID: agencyID, Valid: true}, "other agency", http.StatusForbidden, http.StatusForbidden},
+		{"agency file/admin", uuid.NullUUID{UUID: agencyID, Valid: true}, "admin", http.StatusOK, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newMockStore()
+			store.files[fileID] = query.File{
+				ID:          fileID,
+				UserID:      ownerID,
+				FileKey:     "key",
+				FileName:    "INV-0001.pdf",
+				FileSize:    int64(len("content")),
+				ContentType: "application/pdf",
+				AgencyID:    tt.agencyID,
+			}
+			router := newRouter(store)
+
+			if got := serve(router, http.MethodGet, path, tt.user, nil); got != tt.get {
+				t.Errorf("GET = %d, want %d", got, tt.get)
+			}
+			if got := serve(router, http.MethodDelete, path, tt.user, nil); got != tt.delete {
+				t.Errorf("DELETE = %d, want %d", got, tt.delete)
+			}
+			if deleted := len(store.deleted) > 0; deleted != (tt.delete == http.StatusNoContent) {
+				t.Errorf("file deleted = %t, want %t", deleted, !deleted)
+			}
+		})
+	}
+}
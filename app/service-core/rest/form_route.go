@@ -0,0 +1,176 @@
+package rest
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"encoding/json"
+	"net/http"
+	"service-core/domain/form"
+	"service-core/domain/submission"
+)
+
+// handleFormEvaluate validates submission data from a public form and
+// returns its completion (no auth required)
+func (h *Handler) handleFormEvaluate(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	formID, err := parsePathID(r, "id", "form")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req form.EvaluateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+
+	response, err := h.formService.Evaluate(r.Context(), formID, req)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleFormPublish publishes a new schema version of a form and migrates
+// or pins its draft submissions
+func (h *Handler) handleFormPublish(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	formID, err := parsePathID(r, "id", "form")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.EditForms)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req form.PublishRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+
+	response, err := h.formService.Publish(r.Context(), agencyID, user.ID, formID, req)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleFormVersions lists the published versions of a form
+func (h *Handler) handleFormVersions(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	formID, err := parsePathID(r, "id", "form")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	_, err = h.authService.Auth(extractAccessToken(r), auth.GetForms)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	response, err := h.formService.ListVersions(r.Context(), agencyID, formID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleFormVersion returns one published version of a form
+func (h *Handler) handleFormVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	formID, err := parsePathID(r, "id", "form")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	version, err := parseVersion(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	_, err = h.authService.Auth(extractAccessToken(r), auth.GetForms)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	response, err := h.formService.GetVersion(r.Context(), agencyID, formID, version)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleFormMapping sets how a form's submissions map to consultation
+// fields when they are processed
+func (h *Handler) handleFormMapping(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPut {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	formID, err := parsePathID(r, "id", "form")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	_, err = h.authService.Auth(extractAccessToken(r), auth.EditForms)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req submission.Mapping
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+
+	response, err := h.submissionService.UpdateMapping(r.Context(), agencyID, formID, req)
+	writeResponse(h.cfg, w, r, response, err)
+}
@@ -3,23 +3,51 @@ package rest
 import (
 	"app/pkg/auth"
 	"service-core/config"
+	"service-core/domain/apikey"
 	"service-core/domain/billing"
+	"service-core/domain/client"
+	"service-core/domain/consultation"
+	"service-core/domain/contract"
 	"service-core/domain/email"
 	"service-core/domain/file"
+	"service-core/domain/form"
+	"service-core/domain/invoice"
 	"service-core/domain/login"
 	"service-core/domain/note"
+	"service-core/domain/numbering"
+	"service-core/domain/passkey"
+	"service-core/domain/pdf"
+	"service-core/domain/proposal"
+	"service-core/domain/quotation"
+	"service-core/domain/session"
+	"service-core/domain/submission"
+	"service-core/domain/twofactor"
 	"service-core/storage"
 )
 
 type Handler struct {
-	cfg            *config.Config
-	storage        *storage.Storage
-	authService    auth.AuthService
-	loginService   *login.Service
-	billingService *billing.Service
-	emailService   *email.Service
-	fileService    *file.Service
-	noteService    *note.Service
+	cfg                 *config.Config
+	storage             *storage.Storage
+	authService         auth.AuthService
+	loginService        *login.Service
+	billingService      *billing.Service
+	emailService        *email.Service
+	fileService         *file.Service
+	noteService         *note.Service
+	proposalService     *proposal.Service
+	pdfService          *pdf.Service
+	invoiceService      *invoice.Service
+	numberingService    *numbering.Service
+	contractService     *contract.Service
+	quotationService    *quotation.Service
+	clientService       *client.Service
+	consultationService *consultation.Service
+	formService         *form.Service
+	submissionService   *submission.Service
+	apiKeyService       *apikey.Service
+	sessionService      *session.Service
+	twoFactorService    *twofactor.Service
+	passkeyService      *passkey.Service
 }
 
 func NewHandler(
@@ -31,15 +59,43 @@ func NewHandler(
 	emailService *email.Service,
 	fileService *file.Service,
 	noteService *note.Service,
+	proposalService *proposal.Service,
+	pdfService *pdf.Service,
+	invoiceService *invoice.Service,
+	numberingService *numbering.Service,
+	contractService *contract.Service,
+	quotationService *quotation.Service,
+	clientService *client.Service,
+	consultationService *consultation.Service,
+	formService *form.Service,
+	submissionService *submission.Service,
+	apiKeyService *apikey.Service,
+	sessionService *session.Service,
+	twoFactorService *twofactor.Service,
+	passkeyService *passkey.Service,
 ) *Handler {
 	return &Handler{
-		cfg:            config,
-		storage:        storage,
-		authService:    authService,
-		loginService:   loginService,
-		billingService: billingService,
-		emailService:   emailService,
-		fileService:    fileService,
-		noteService:    noteService,
+		cfg:                 config,
+		storage:             storage,
+		authService:         authService,
+		loginService:        loginService,
+		billingService:      billingService,
+		emailService:        emailService,
+		fileService:         fileService,
+		noteService:         noteService,
+		proposalService:     proposalService,
+		pdfService:          pdfService,
+		invoiceService:      invoiceService,
+		numberingService:    numberingService,
+		contractService:     contractService,
+		quotationService:    quotationService,
+		clientService:       clientService,
+		consultationService: consultationService,
+		formService:         formService,
+		submissionService:   submissionService,
+		apiKeyService:       apiKeyService,
+		sessionService:      sessionService,
+		twoFactorService:    twoFactorService,
+		passkeyService:      passkeyService,
 	}
 }
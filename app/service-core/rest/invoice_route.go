@@ -0,0 +1,393 @@
+package rest
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"encoding/json"
+	"net/http"
+	"service-core/domain/invoice"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// parsePathID reads a UUID path value, naming it in the error
+func parsePathID(r *http.Request, name, label string) (uuid.UUID, error) {
+	id, err := uuid.Parse(r.PathValue(name))
+	if err != nil {
+		return uuid.Nil, pkg.BadRequestError{Message: "Invalid " + label + " ID", Err: err}
+	}
+	return id, nil
+}
+
+func (h *Handler) handleInvoicesCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	token := extractAccessToken(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		_, err := h.authService.Auth(token, auth.GetInvoices)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		page, _ := strconv.ParseInt(r.URL.Query().Get("page"), 10, 32)
+		limit, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 32)
+		status := r.URL.Query().Get("status")
+
+		response, err := h.invoiceService.ListInvoices(r.Context(), agencyID, status, int32(page), int32(limit))
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	case http.MethodPost:
+		user, err := h.authService.Auth(token, auth.CreateInvoice)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		var req invoice.CreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+
+		response, err := h.invoiceService.CreateInvoice(r.Context(), agencyUser(r, user), req)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+}
+
+func (h *Handler) handleInvoiceResource(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	invoiceID, err := parsePathID(r, "id", "invoice")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	token := extractAccessToken(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		_, err := h.authService.Auth(token, auth.GetInvoices)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		response, err := h.invoiceService.GetInvoice(r.Context(), agencyID, invoiceID)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	case http.MethodPut:
+		user, err := h.authService.Auth(token, auth.EditInvoice)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		var req invoice.UpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+
+		response, err := h.invoiceService.UpdateInvoice(r.Context(), agencyUser(r, user), invoiceID, req)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	case http.MethodDelete:
+		user, err := h.authService.Auth(token, auth.RemoveInvoice)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		err = h.invoiceService.DeleteInvoice(r.Context(), agencyUser(r, user), invoiceID)
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+}
+
+// handleInvoiceLineItems adds a line item to an invoice
+func (h *Handler) handleInvoiceLineItems(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	invoiceID, err := parsePathID(r, "id", "invoice")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.EditInvoice)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req invoice.LineItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+
+	response, err := h.invoiceService.AddLineItem(r.Context(), agencyUser(r, user), invoiceID, req)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleInvoiceLineItem edits or removes a single line item
+func (h *Handler) handleInvoiceLineItem(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	invoiceID, err := parsePathID(r, "id", "invoice")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	itemID, err := parsePathID(r, "itemId", "line item")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.EditInvoice)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var req invoice.LineItemRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+
+		response, err := h.invoiceService.UpdateLineItem(r.Context(), agencyUser(r, user), invoiceID, itemID, req)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	case http.MethodDelete:
+		response, err := h.invoiceService.RemoveLineItem(r.Context(), agencyUser(r, user), invoiceID, itemID)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+}
+
+// handleInvoiceStatus moves an invoice to a new status
+func (h *Handler) handleInvoiceStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	invoiceID, err := parsePathID(r, "id", "invoice")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.EditInvoice)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req invoice.TransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+
+	response, err := h.invoiceService.TransitionInvoice(r.Context(), agencyUser(r, user), invoiceID, req)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleInvoicePayments records a payment against an invoice
+func (h *Handler) handleInvoicePayments(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	invoiceID, err := parsePathID(r, "id", "invoice")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.EditInvoice)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req invoice.PaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+
+	response, err := h.invoiceService.RecordPayment(r.Context(), agencyUser(r, user), invoiceID, req)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleInvoicePDF renders and stores an invoice PDF
+func (h *Handler) handleInvoicePDF(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	invoiceID, err := parsePathID(r, "id", "invoice")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.GetInvoices)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	response, err := h.pdfService.GenerateInvoice(r.Context(), agencyID, user.ID, invoiceID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleProposalInvoice creates a draft invoice from an accepted proposal
+func (h *Handler) handleProposalInvoice(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	proposalID, err := parseProposalID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.CreateInvoice)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	response, err := h.invoiceService.CreateFromProposal(r.Context(), agencyUser(r, user), proposalID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleContractInvoice creates a draft invoice from a signed contract
+func (h *Handler) handleContractInvoice(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	contractID, err := parsePathID(r, "id", "contract")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.CreateInvoice)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	response, err := h.invoiceService.CreateFromContract(r.Context(), agencyUser(r, user), contractID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleQuotationInvoice creates a draft invoice from an accepted quotation
+func (h *Handler) handleQuotationInvoice(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	quotationID, err := parsePathID(r, "id", "quotation")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.CreateInvoice)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	response, err := h.invoiceService.CreateFromQuotation(r.Context(), agencyUser(r, user), quotationID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleInvoiceView records a view of an invoice's public page (no auth required)
+func (h *Handler) handleInvoiceView(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+
+	_, err := h.invoiceService.RecordView(r.Context(), r.PathValue("slug"))
+	writeResponse(h.cfg, w, r, nil, err)
+}
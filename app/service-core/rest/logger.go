@@ -1,11 +1,15 @@
 package rest
 
 import (
+	"app/pkg"
 	"log/slog"
 	"net/http"
 	"time"
 )
 
+// requestIDHeader carries the ID that ties a request to its logs and errors
+const requestIDHeader = "X-Request-ID"
+
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		startTime := time.Now()
@@ -29,7 +33,35 @@ func loggingMiddleware(next http.Handler) http.Handler {
 			slog.String("remote_addr", r.RemoteAddr),
 			slog.String("user_agent", r.UserAgent()),
 			slog.Bool("auth_token_present", authTokenPresent),
+			slog.String("request_id", pkg.RequestIDFromContext(r.Context())),
 		)
 	})
 }
 
+// requestIDMiddleware gives every request an ID, keeping one sent by the
+// caller so a request can be traced across services. The ID is returned in
+// the X-Request-ID header and in error responses.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = pkg.NewRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(pkg.WithRequestID(r.Context(), id)))
+	})
+}
+
+// validRequestID reports whether a caller's request ID is safe to log and
+// echo back
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
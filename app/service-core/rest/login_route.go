@@ -2,7 +2,9 @@ package rest
 
 import (
 	"app/pkg"
+	"app/pkg/auth"
 	"errors"
+	"log/slog"
 	"net/http"
 	"service-core/domain/login"
 	"strings"
@@ -24,7 +26,7 @@ func (h *Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		accessToken = &http.Cookie{Value: ""}
 	}
-	response, err := h.loginService.Refresh(r.Context(), accessToken.Value, refreshToken.Value)
+	response, err := h.loginService.Refresh(r.Context(), accessToken.Value, refreshToken.Value, sessionClient(r))
 	if err != nil {
 		writeResponse(h.cfg, w, r, nil, err)
 		return
@@ -59,6 +61,12 @@ func (h *Handler) handleLogout(w http.ResponseWriter, r *http.Request) {
 		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("invalid origin")})
 		return
 	}
+	// Revoke the session; the cookies are cleared even if that fails
+	if refreshToken, err := r.Cookie("refresh_token"); err == nil {
+		if err := h.loginService.Logout(r.Context(), refreshToken.Value); err != nil {
+			slog.Warn("Error revoking session on logout", "error", err)
+		}
+	}
 	http.SetCookie(w, &http.Cookie{
 		Path:     "/",
 		Name:     "access_token",
@@ -114,7 +122,7 @@ func (h *Handler) handleLoginCallback(w http.ResponseWriter, r *http.Request) {
 	code := r.URL.Query().Get("code")
 	state := r.URL.Query().Get("state")
 	userEmail := r.URL.Query().Get("email")
-	response, err := h.loginService.LoginCallback(r.Context(), state, code, userEmail, login.Provider(p))
+	response, err := h.loginService.LoginCallback(r.Context(), state, code, userEmail, login.Provider(p), sessionClient(r))
 	if err != nil {
 		writeResponse(h.cfg, w, r, nil, err)
 		return
@@ -131,9 +139,14 @@ func (h *Handler) handleLoginCallback(w http.ResponseWriter, r *http.Request) {
 			Domain:   h.cfg.Domain,
 			MaxAge:   int(h.cfg.AccessTokenExp.Seconds()),
 		})
-		if response.HasPhone {
+		switch {
+		case response.TwoFactorMethod == auth.TwoFactorPasskey:
+			http.Redirect(w, r, response.ReturnURL+"/login/passkey", http.StatusFound)
+		case response.TwoFactorMethod == auth.TwoFactorTOTP:
+			http.Redirect(w, r, response.ReturnURL+"/login/totp", http.StatusFound)
+		case response.HasPhone:
 			http.Redirect(w, r, response.ReturnURL+"/login/verify", http.StatusFound)
-		} else {
+		default:
 			http.Redirect(w, r, response.ReturnURL+"/login/phone", http.StatusFound)
 		}
 	} else {
@@ -174,6 +187,10 @@ func (h *Handler) handleLoginPhone(w http.ResponseWriter, r *http.Request) {
 		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: err})
 		return
 	}
+	if claims.Method != auth.TwoFactorSMS {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("session token is not for SMS")})
+		return
+	}
 	if claims.Phone != "" {
 		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("phone already exists")})
 		return
@@ -210,6 +227,10 @@ func (h *Handler) handleLoginVerify(w http.ResponseWriter, r *http.Request) {
 		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: err})
 		return
 	}
+	if claims.Method != auth.TwoFactorSMS {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("session token is not for SMS")})
+		return
+	}
 	phone := claims.Phone
 	if phone == "" {
 		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("phone not found")})
@@ -217,7 +238,53 @@ func (h *Handler) handleLoginVerify(w http.ResponseWriter, r *http.Request) {
 	}
 
 	code := r.FormValue("code")
-	tokens, err := h.loginService.LoginVerify(r.Context(), claims.ID, phone, code)
+	tokens, err := h.loginService.LoginVerify(r.Context(), claims.ID, phone, code, sessionClient(r))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Path:     "/",
+		Name:     "access_token",
+		Value:    tokens.AccessToken,
+		Secure:   isSecureCookie(h.cfg.Domain),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Domain:   h.cfg.Domain,
+		MaxAge:   int(h.cfg.AccessTokenExp.Seconds()),
+	})
+	http.SetCookie(w, &http.Cookie{
+		Path:     "/",
+		Name:     "refresh_token",
+		Value:    tokens.RefreshToken,
+		Secure:   isSecureCookie(h.cfg.Domain),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Domain:   h.cfg.Domain,
+		MaxAge:   int(h.cfg.RefreshTokenExp.Seconds()),
+	})
+	http.Redirect(w, r, returnURL, http.StatusFound)
+}
+
+func (h *Handler) handleLoginTOTP(w http.ResponseWriter, r *http.Request) {
+	returnURL := r.FormValue("return_url")
+	sessionToken, err := r.Cookie("session_token")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: err})
+		return
+	}
+	claims, err := h.authService.ValidateSessionToken(sessionToken.Value)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: err})
+		return
+	}
+	if claims.Method != auth.TwoFactorTOTP {
+		writeResponse(h.cfg, w, r, nil, pkg.UnauthorizedError{Err: errors.New("session token is not for TOTP")})
+		return
+	}
+
+	code := r.FormValue("code")
+	tokens, err := h.loginService.LoginTOTP(r.Context(), claims, code, sessionClient(r))
 	if err != nil {
 		writeResponse(h.cfg, w, r, nil, err)
 		return
@@ -0,0 +1,43 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoginTOTPRoute(t *testing.T) {
+	t.Parallel()
+
+	// loginTOTP posts a code with a session token from a client's address
+	loginTOTP := func(router http.Handler, sessionToken, remoteAddr string) int {
+		r := httptest.NewRequest(http.MethodPost, "/login-totp", nil)
+		r.AddCookie(&http.Cookie{Name: "session_token", Value: sessionToken})
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	t.Run("refuses session tokens for other second factors", func(t *testing.T) {
+		t.Parallel()
+		if got := loginTOTP(newRouter(newMockStore()), "passkey", "192.0.2.1:1234"); got != http.StatusUnauthorized {
+			t.Errorf("POST with a passkey session token = %d, want %d", got, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("limits attempts per client", func(t *testing.T) {
+		t.Parallel()
+		router := newRouter(newMockStore())
+		var last int
+		for range 20 {
+			last = loginTOTP(router, "passkey", "192.0.2.2:1234")
+		}
+		if last != http.StatusTooManyRequests {
+			t.Errorf("POST after 20 attempts = %d, want %d", last, http.StatusTooManyRequests)
+		}
+		if got := loginTOTP(router, "passkey", "192.0.2.3:1234"); got != http.StatusUnauthorized {
+			t.Errorf("POST from another client = %d, want %d", got, http.StatusUnauthorized)
+		}
+	})
+}
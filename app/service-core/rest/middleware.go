@@ -4,31 +4,43 @@ import (
 	"app/pkg"
 	"app/pkg/auth"
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net/http"
+	"service-core/config"
+	"service-core/storage/query"
+	"slices"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // contextKey is a typed key for context values to avoid collisions.
 type contextKey string
 
-const userContextKey contextKey = "user"
+const (
+	userContextKey   contextKey = "user"
+	agencyContextKey contextKey = "agency"
+)
 
 // Middleware types
 type Middleware func(http.HandlerFunc) http.HandlerFunc
 
-// AuthMiddleware adds authentication to requests
-func AuthMiddleware(authService *auth.Service, requiredAccess int64) Middleware {
+// AuthMiddleware adds authentication to requests. Requests may be made with
+// an access token or, when authService accepts them, an API key.
+func AuthMiddleware(authService auth.AuthService, requiredAccess int64) Middleware {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			accessToken := extractAccessToken(r)
 
 			user, err := authService.Auth(accessToken, requiredAccess)
 			if err != nil {
-				writeResponse(nil, w, r, nil, err)
+				writeProblem(w, r, err)
 				return
 			}
 
@@ -41,6 +53,114 @@ func AuthMiddleware(authService *auth.Service, requiredAccess int64) Middleware
 	}
 }
 
+// Permissions maps the HTTP methods an endpoint accepts to the access each
+// requires
+type Permissions map[string]int64
+
+// allow lists the accepted methods for the Allow header of a 405 response
+func (p Permissions) allow() string {
+	return strings.Join(append(slices.Sorted(maps.Keys(p)), http.MethodOptions), ", ")
+}
+
+// AgencyAccess is the agency a request acts for and the user's role in it
+type AgencyAccess struct {
+	ID   uuid.UUID
+	Role auth.Role
+}
+
+// membershipStore looks up the agencies users belong to
+type membershipStore interface {
+	SelectAgencyMembership(ctx context.Context, arg query.SelectAgencyMembershipParams) (query.AgencyMembership, error)
+}
+
+// AgencyMiddleware authorizes requests for an agency's data. The agency is
+// taken from the agencyId route value, the X-Agency-ID header or the
+// agencyId query parameter, in that order, falling back to the active
+// agency in the access token. The user must hold an accepted membership of
+// the agency, and both their access and their role there must grant what
+// the endpoint requires for the request method. The role is read from the
+// membership rather than the token so role changes apply immediately.
+func AgencyMiddleware(
+	cfg *config.Config,
+	authService auth.AuthService,
+	store membershipStore,
+	permissions Permissions,
+) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions {
+				next(w, r)
+				return
+			}
+			access, ok := permissions[r.Method]
+			if !ok {
+				w.Header().Set("Allow", permissions.allow())
+				writeResponse(cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+				return
+			}
+			user, err := authService.Auth(extractAccessToken(r), access)
+			if err != nil {
+				writeResponse(cfg, w, r, nil, err)
+				return
+			}
+			agency, err := resolveAgency(r, store, user)
+			if err != nil {
+				writeResponse(cfg, w, r, nil, err)
+				return
+			}
+			if !agency.Role.Allows(access) {
+				writeResponse(cfg, w, r, nil, pkg.ForbiddenError{
+					Err: fmt.Errorf("role %q does not have access to this resource", agency.Role),
+				})
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), userContextKey, user)
+			ctx = context.WithValue(ctx, agencyContextKey, agency)
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// resolveAgency returns the agency a request acts for, verifying the user
+// belongs to it
+func resolveAgency(r *http.Request, store membershipStore, user *auth.AccessTokenClaims) (*AgencyAccess, error) {
+	agencyID := user.AgencyID
+	raw := r.PathValue("agencyId")
+	if raw == "" {
+		raw = r.Header.Get("X-Agency-ID")
+	}
+	if raw == "" {
+		raw = r.URL.Query().Get("agencyId")
+	}
+	if raw != "" {
+		var err error
+		agencyID, err = uuid.Parse(raw)
+		if err != nil {
+			return nil, pkg.BadRequestError{Message: "Invalid agencyId", Err: err}
+		}
+	}
+	if agencyID == uuid.Nil {
+		return nil, pkg.BadRequestError{Message: "agencyId is required"}
+	}
+	// Agency API keys only act for the agency they belong to
+	if user.APIKeyID != uuid.Nil && user.AgencyID != uuid.Nil && agencyID != user.AgencyID {
+		return nil, pkg.ForbiddenError{Err: fmt.Errorf("API key %s belongs to another agency", user.APIKeyID)}
+	}
+
+	m, err := store.SelectAgencyMembership(r.Context(), query.SelectAgencyMembershipParams{
+		UserID:   user.ID,
+		AgencyID: agencyID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkg.ForbiddenError{Err: fmt.Errorf("user %s is not a member of agency %s", user.ID, agencyID)}
+		}
+		return nil, pkg.InternalError{Message: "Error selecting agency membership", Err: err}
+	}
+	return &AgencyAccess{ID: m.AgencyID, Role: auth.Role(m.Role)}, nil
+}
+
 // RateLimitMiddleware implements basic rate limiting
 func RateLimitMiddleware(requestsPerMinute int) Middleware {
 	// Simple in-memory rate limiter
@@ -93,7 +213,7 @@ func RateLimitMiddleware(requestsPerMinute int) Middleware {
 			// Check rate limit
 			if len(clients[clientIP]) >= requestsPerMinute {
 				mu.Unlock()
-				writeResponse(nil, w, r, nil, pkg.BadRequestError{
+				writeProblem(w, r, pkg.TooManyRequestsError{
 					Message: "Rate limit exceeded",
 					Err:     fmt.Errorf("too many requests from %s", clientIP),
 				})
@@ -255,6 +375,23 @@ func GetUserFromContext(r *http.Request) (*auth.AccessTokenClaims, bool) {
 	return user, ok
 }
 
+// GetAgencyFromContext returns the agency resolved by AgencyMiddleware
+func GetAgencyFromContext(r *http.Request) (*AgencyAccess, bool) {
+	agency, ok := r.Context().Value(agencyContextKey).(*AgencyAccess)
+	return agency, ok
+}
+
+// agencyUser returns the attributes of the user within the request's agency,
+// which services authorize agency records against
+func agencyUser(r *http.Request, user *auth.AccessTokenClaims) auth.UserAttr {
+	attr := auth.UserAttr{ID: user.ID}
+	if agency, ok := GetAgencyFromContext(r); ok {
+		attr.AgencyID = agency.ID
+		attr.Role = agency.Role
+	}
+	return attr
+}
+
 // RequireAuth is a helper that ensures a user is authenticated
 func RequireAuth(w http.ResponseWriter, r *http.Request) (*auth.AccessTokenClaims, bool) {
 	user, ok := GetUserFromContext(r)
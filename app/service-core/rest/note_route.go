@@ -80,18 +80,18 @@ func (h *Handler) handleNoteResource(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case http.MethodGet:
-		_, err := h.authService.Auth(token, auth.GetNotes)
+		user, err := h.authService.Auth(token, auth.GetNotes)
 		if err != nil {
 			writeResponse(h.cfg, w, r, nil, err)
 			return
 		}
 
-		response, err := h.noteService.GetNoteByID(r.Context(), noteID)
+		response, err := h.noteService.GetNoteByID(r.Context(), user.Attr(), noteID)
 		writeResponse(h.cfg, w, r, response, err)
 		return
 
 	case http.MethodPut:
-		_, err := h.authService.Auth(token, auth.EditNote)
+		user, err := h.authService.Auth(token, auth.EditNote)
 		if err != nil {
 			writeResponse(h.cfg, w, r, nil, err)
 			return
@@ -101,18 +101,18 @@ func (h *Handler) handleNoteResource(w http.ResponseWriter, r *http.Request) {
 		category := r.FormValue("category")
 		content := r.FormValue("content")
 
-		response, err := h.noteService.EditNote(r.Context(), noteID, title, category, content)
+		response, err := h.noteService.EditNote(r.Context(), user.Attr(), noteID, title, category, content)
 		writeResponse(h.cfg, w, r, response, err)
 		return
 
 	case http.MethodDelete:
-		_, err := h.authService.Auth(token, auth.RemoveNote)
+		user, err := h.authService.Auth(token, auth.RemoveNote)
 		if err != nil {
 			writeResponse(h.cfg, w, r, nil, err)
 			return
 		}
 
-		err = h.noteService.RemoveNote(r.Context(), noteID)
+		err = h.noteService.RemoveNote(r.Context(), user.Attr(), noteID)
 		writeResponse(h.cfg, w, r, nil, err) 
 		return
 
@@ -0,0 +1,51 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/url"
+	"service-core/storage/query"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNoteRoutes(t *testing.T) {
+	t.Parallel()
+	noteID := uuid.MustParse("00000000-0000-0000-0000-000000000010")
+	path := "/api/v1/notes/" + noteID.String()
+	edit := url.Values{"title": {"Updated"}, "category": {"General"}, "content": {"Updated note content"}}
+
+	// Notes are personal, so agency roles grant nothing on them
+	tests := []struct {
+		user   string
+		get    int
+		put    int
+		delete int
+	}{
+		{"owner", http.StatusOK, http.StatusOK, http.StatusNoContent},
+		{"other member", http.StatusForbidden, http.StatusForbidden, http.StatusForbidden},
+		{"other agency", http.StatusForbidden, http.StatusForbidden, http.StatusForbidden},
+		{"admin", http.StatusForbidden, http.StatusForbidden, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.user, func(t *testing.T) {
+			t.Parallel()
+			store := newMockStore()
+			store.notes[noteID] = query.Note{ID: noteID, UserID: ownerID, Title: "Note"}
+			router := newRouter(store)
+
+			if got := serve(router, http.MethodGet, path, tt.user, nil); got != tt.get {
+				t.Errorf("GET = %d, want %d", got, tt.get)
+			}
+			if got := serve(router, http.MethodPut, path, tt.user, edit); got != tt.put {
+				t.Errorf("PUT = %d, want %d", got, tt.put)
+			}
+			if got := serve(router, http.MethodDelete, path, tt.user, nil); got != tt.delete {
+				t.Errorf("DELETE = %d, want %d", got, tt.delete)
+			}
+			if deleted := len(store.deleted) > 0; deleted != (tt.delete == http.StatusNoContent) {
+				t.Errorf("note deleted = %t, want %t", deleted, !deleted)
+			}
+		})
+	}
+}
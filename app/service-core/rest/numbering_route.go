@@ -0,0 +1,106 @@
+package rest
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"encoding/json"
+	"net/http"
+	"service-core/domain/numbering"
+	"strconv"
+)
+
+// parseDocumentType reads the document type path value
+func parseDocumentType(r *http.Request) (numbering.DocumentType, error) {
+	docType := numbering.DocumentType(r.PathValue("type"))
+	if !docType.IsValid() {
+		return "", pkg.BadRequestError{Message: "Invalid document type"}
+	}
+	return docType, nil
+}
+
+func (h *Handler) handleNumberingCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	_, err = h.authService.Auth(extractAccessToken(r), auth.GetSettings)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	response, err := h.numberingService.ListSettings(r.Context(), agencyID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+func (h *Handler) handleNumberingResource(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	docType, err := parseDocumentType(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.EditSettings)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req numbering.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+	response, err := h.numberingService.UpdateSettings(r.Context(), agencyID, user.ID, docType, req)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+func (h *Handler) handleNumberingGaps(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	docType, err := parseDocumentType(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	_, err = h.authService.Auth(extractAccessToken(r), auth.GetSettings)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	year, _ := strconv.Atoi(r.URL.Query().Get("year"))
+	response, err := h.numberingService.Gaps(r.Context(), agencyID, docType, year)
+	writeResponse(h.cfg, w, r, response, err)
+}
@@ -0,0 +1,221 @@
+package rest
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"service-core/domain/login"
+	"service-core/domain/passkey"
+)
+
+// PasskeyLoginOptionsRequest starts a passkey sign-in, naming where the
+// user returns to once signed in
+type PasskeyLoginOptionsRequest struct {
+	ReturnURL string `json:"returnUrl"`
+}
+
+// setAuthCookies sets the cookies of a completed sign-in
+func (h *Handler) setAuthCookies(w http.ResponseWriter, tokens *login.AuthResponse) {
+	http.SetCookie(w, &http.Cookie{
+		Path:     "/",
+		Name:     "access_token",
+		Value:    tokens.AccessToken,
+		Secure:   isSecureCookie(h.cfg.Domain),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Domain:   h.cfg.Domain,
+		MaxAge:   int(h.cfg.AccessTokenExp.Seconds()),
+	})
+	http.SetCookie(w, &http.Cookie{
+		Path:     "/",
+		Name:     "refresh_token",
+		Value:    tokens.RefreshToken,
+		Secure:   isSecureCookie(h.cfg.Domain),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Domain:   h.cfg.Domain,
+		MaxAge:   int(h.cfg.RefreshTokenExp.Seconds()),
+	})
+}
+
+// passkeySessionToken returns the claims of the session token of a sign-in
+// that is to be completed with a passkey
+func (h *Handler) passkeySessionToken(r *http.Request) (*auth.SessionTokenClaims, error) {
+	sessionToken, err := r.Cookie("session_token")
+	if err != nil {
+		return nil, pkg.UnauthorizedError{Err: err}
+	}
+	claims, err := h.authService.ValidateSessionToken(sessionToken.Value)
+	if err != nil {
+		return nil, pkg.UnauthorizedError{Err: err}
+	}
+	if claims.Method != auth.TwoFactorPasskey {
+		return nil, pkg.UnauthorizedError{Err: errors.New("session token is not for a passkey")}
+	}
+	return claims, nil
+}
+
+func decodeAssertion(r *http.Request) (passkey.AssertionCredential, error) {
+	var cred passkey.AssertionCredential
+	if err := json.NewDecoder(r.Body).Decode(&cred); err != nil {
+		return cred, pkg.BadRequestError{Message: "Invalid request body", Err: err}
+	}
+	return cred, nil
+}
+
+func (h *Handler) handleLoginPasskeyOptions(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	var req PasskeyLoginOptionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+	response, err := h.loginService.LoginPasskeyOptions(r.Context(), req.ReturnURL)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+func (h *Handler) handleLoginPasskey(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	cred, err := decodeAssertion(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	tokens, err := h.loginService.LoginPasskey(r.Context(), cred, sessionClient(r))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	h.setAuthCookies(w, tokens)
+	writeResponse(h.cfg, w, r, map[string]string{"returnUrl": tokens.ReturnURL}, nil)
+}
+
+func (h *Handler) handleLoginPasskeyVerifyOptions(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	claims, err := h.passkeySessionToken(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	response, err := h.passkeyService.VerifyOptions(r.Context(), claims.ID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+func (h *Handler) handleLoginPasskeyVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	claims, err := h.passkeySessionToken(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	cred, err := decodeAssertion(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	tokens, err := h.loginService.LoginPasskeyVerify(r.Context(), claims.ID, cred, sessionClient(r))
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	h.setAuthCookies(w, tokens)
+	writeResponse(h.cfg, w, r, nil, nil)
+}
+
+func (h *Handler) handlePasskeysCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	user, err := h.authSession(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		response, err := h.passkeyService.List(r.Context(), user.ID)
+		writeResponse(h.cfg, w, r, response, err)
+	case http.MethodPost:
+		var req passkey.RegistrationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+		response, err := h.passkeyService.Register(r.Context(), user.ID, req)
+		writeResponse(h.cfg, w, r, response, err)
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+	}
+}
+
+func (h *Handler) handlePasskeyOptions(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	user, err := h.authSession(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	response, err := h.passkeyService.RegistrationOptions(r.Context(), user.ID, user.Email)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+func (h *Handler) handlePasskeyResource(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	user, err := h.authSession(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	id, err := parsePathID(r, "id", "passkey")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	err = h.passkeyService.Remove(r.Context(), user.ID, id)
+	writeResponse(h.cfg, w, r, nil, err)
+}
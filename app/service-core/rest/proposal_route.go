@@ -0,0 +1,320 @@
+package rest
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"encoding/json"
+	"net/http"
+	"service-core/domain/proposal"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// ProposalSectionRequest represents the request body for updating a proposal section
+type ProposalSectionRequest struct {
+	Content json.RawMessage `json:"content"`
+}
+
+// parseAgencyID returns the agency resolved by AgencyMiddleware, or else
+// reads the required agencyId query parameter
+func parseAgencyID(r *http.Request) (uuid.UUID, error) {
+	if agency, ok := GetAgencyFromContext(r); ok {
+		return agency.ID, nil
+	}
+	agencyIDStr := r.URL.Query().Get("agencyId")
+	if agencyIDStr == "" {
+		return uuid.Nil, pkg.BadRequestError{Message: "agencyId is required"}
+	}
+	agencyID, err := uuid.Parse(agencyIDStr)
+	if err != nil {
+		return uuid.Nil, pkg.BadRequestError{Message: "Invalid agencyId", Err: err}
+	}
+	return agencyID, nil
+}
+
+// parseProposalID reads the proposal ID path value
+func parseProposalID(r *http.Request) (uuid.UUID, error) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return uuid.Nil, pkg.BadRequestError{Message: "Invalid proposal ID", Err: err}
+	}
+	return id, nil
+}
+
+func (h *Handler) handleProposalsCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	token := extractAccessToken(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		_, err := h.authService.Auth(token, auth.GetProposals)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		page, _ := strconv.ParseInt(r.URL.Query().Get("page"), 10, 32)
+		limit, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 32)
+		status := r.URL.Query().Get("status")
+
+		response, err := h.proposalService.ListProposals(r.Context(), agencyID, status, int32(page), int32(limit))
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	case http.MethodPost:
+		user, err := h.authService.Auth(token, auth.CreateProposal)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		var req proposal.CreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+
+		response, err := h.proposalService.CreateProposal(r.Context(), agencyUser(r, user), req)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+}
+
+func (h *Handler) handleProposalResource(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	proposalID, err := parseProposalID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	token := extractAccessToken(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		_, err := h.authService.Auth(token, auth.GetProposals)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		response, err := h.proposalService.GetProposal(r.Context(), agencyID, proposalID)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	case http.MethodPut:
+		user, err := h.authService.Auth(token, auth.EditProposal)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		var req proposal.UpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+			return
+		}
+
+		response, err := h.proposalService.UpdateProposal(r.Context(), agencyUser(r, user), proposalID, req)
+		writeResponse(h.cfg, w, r, response, err)
+		return
+
+	case http.MethodDelete:
+		user, err := h.authService.Auth(token, auth.RemoveProposal)
+		if err != nil {
+			writeResponse(h.cfg, w, r, nil, err)
+			return
+		}
+
+		err = h.proposalService.DeleteProposal(r.Context(), agencyUser(r, user), proposalID)
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+}
+
+// handleProposalSection replaces the content of a single proposal section
+func (h *Handler) handleProposalSection(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPut {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	proposalID, err := parseProposalID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.EditProposal)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req ProposalSectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+
+	section := proposal.Section(r.PathValue("section"))
+	response, err := h.proposalService.UpdateSection(r.Context(), agencyUser(r, user), proposalID, section, req.Content)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleProposalDuplicate creates a draft copy of a proposal
+func (h *Handler) handleProposalDuplicate(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	proposalID, err := parseProposalID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.CreateProposal)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	response, err := h.proposalService.DuplicateProposal(r.Context(), agencyUser(r, user), proposalID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleProposalPricing returns the itemised pricing breakdown of a proposal
+func (h *Handler) handleProposalPricing(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	proposalID, err := parseProposalID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	_, err = h.authService.Auth(extractAccessToken(r), auth.GetProposals)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	response, err := h.proposalService.CalculatePricing(r.Context(), agencyID, proposalID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleProposalPDF renders and stores a proposal PDF
+func (h *Handler) handleProposalPDF(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	agencyID, err := parseAgencyID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	proposalID, err := parseProposalID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.GetProposals)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	response, err := h.pdfService.GenerateProposal(r.Context(), agencyID, user.ID, proposalID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleProposalStatus moves a proposal to a new status
+func (h *Handler) handleProposalStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	proposalID, err := parseProposalID(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), auth.EditProposal)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	var req proposal.TransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(h.cfg, w, r, nil, pkg.BadRequestError{Message: "Invalid request body", Err: err})
+		return
+	}
+
+	response, err := h.proposalService.TransitionProposal(r.Context(), agencyUser(r, user), proposalID, req)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+// handleProposalView records a view of a proposal's public page (no auth required)
+func (h *Handler) handleProposalView(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+
+	_, err := h.proposalService.RecordView(r.Context(), r.PathValue("slug"))
+	writeResponse(h.cfg, w, r, nil, err)
+}
@@ -0,0 +1,202 @@
+package rest_test
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"service-core/config"
+	"service-core/domain/email"
+	"service-core/domain/file"
+	"service-core/domain/note"
+	"service-core/rest"
+	"service-core/storage/query"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	agencyID      = uuid.MustParse("00000000-0000-0000-0000-0000000000a1")
+	otherAgencyID = uuid.MustParse("00000000-0000-0000-0000-0000000000a2")
+
+	ownerID    = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	memberID   = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	outsiderID = uuid.MustParse("00000000-0000-0000-0000-000000000003")
+	adminID    = uuid.MustParse("00000000-0000-0000-0000-000000000004")
+)
+
+// users are the callers of the route tests, by access token. The owner of
+// the records and another member belong to one agency, which the admin
+// administers; the outsider owns another agency.
+var users = map[string]*auth.AccessTokenClaims{
+	"owner":        {ID: ownerID, Access: auth.UserAccess, AgencyID: agencyID, AgencyRole: auth.RoleMember},
+	"other member": {ID: memberID, Access: auth.UserAccess, AgencyID: agencyID, AgencyRole: auth.RoleMember},
+	"other agency": {ID: outsiderID, Access: auth.UserAccess, AgencyID: otherAgencyID, AgencyRole: auth.RoleOwner},
+	"admin":        {ID: adminID, Access: auth.AdminAccess, AgencyID: agencyID, AgencyRole: auth.RoleAdmin},
+}
+
+// fakeAuth authenticates requests by their access token, which names one of
+// the users
+type fakeAuth struct {
+	auth.AuthService
+}
+
+func (fakeAuth) Auth(token string, access int64) (*auth.AccessTokenClaims, error) {
+	user, ok := users[token]
+	if !ok {
+		return nil, pkg.UnauthorizedError{Err: errors.New("invalid token")}
+	}
+	if user.Access&access != access {
+		return nil, pkg.ForbiddenError{Err: fmt.Errorf("user %s does not have access", user.ID)}
+	}
+	return user, nil
+}
+
+// sessions are the session tokens of users signing in, by token
+var sessions = map[string]*auth.SessionTokenClaims{
+	"passkey": {ID: ownerID, Method: auth.TwoFactorPasskey, TokenID: uuid.New()},
+}
+
+func (fakeAuth) ValidateSessionToken(token string) (*auth.SessionTokenClaims, error) {
+	session, ok := sessions[token]
+	if !ok {
+		return nil, errors.New("invalid session token")
+	}
+	return session, nil
+}
+
+// mockStore holds the records the routes act on. Queries the tests do not
+// expect fall through to the nil *query.Queries and panic.
+type mockStore struct {
+	*query.Queries
+	mu      sync.Mutex
+	notes   map[uuid.UUID]query.Note
+	files   map[uuid.UUID]query.File
+	deleted []uuid.UUID
+	emails  int
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{
+		notes: map[uuid.UUID]query.Note{},
+		files: map[uuid.UUID]query.File{},
+	}
+}
+
+func (m *mockStore) SelectAgencyMembership(ctx context.Context, arg query.SelectAgencyMembershipParams) (query.AgencyMembership, error) {
+	for _, user := range users {
+		if user.ID == arg.UserID && user.AgencyID == arg.AgencyID {
+			return query.AgencyMembership{UserID: user.ID, AgencyID: user.AgencyID, Role: string(user.AgencyRole), Status: "active"}, nil
+		}
+	}
+	return query.AgencyMembership{}, sql.ErrNoRows
+}
+
+func (m *mockStore) SelectUser(ctx context.Context, id uuid.UUID) (query.User, error) {
+	return query.User{ID: id}, nil
+}
+
+func (m *mockStore) SelectNote(ctx context.Context, id uuid.UUID) (query.Note, error) {
+	n, ok := m.notes[id]
+	if !ok {
+		return query.Note{}, sql.ErrNoRows
+	}
+	return n, nil
+}
+
+func (m *mockStore) UpdateNote(ctx context.Context, params query.UpdateNoteParams) (query.Note, error) {
+	n := m.notes[params.ID]
+	n.Title, n.Category, n.Content = params.Title, params.Category, params.Content
+	return n, nil
+}
+
+func (m *mockStore) DeleteNote(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+func (m *mockStore) SelectFile(ctx context.Context, id uuid.UUID) (query.File, error) {
+	f, ok := m.files[id]
+	if !ok {
+		return query.File{}, sql.ErrNoRows
+	}
+	return f, nil
+}
+
+func (m *mockStore) DeleteFile(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+func (m *mockStore) SelectEmailSuppression(ctx context.Context, arg query.SelectEmailSuppressionParams) (query.EmailSuppression, error) {
+	return query.EmailSuppression{}, sql.ErrNoRows
+}
+
+func (m *mockStore) InTx(ctx context.Context, fn func(q query.Querier) error) error {
+	return fn(m)
+}
+
+func (m *mockStore) InsertEmailAttachment(ctx context.Context, params query.InsertEmailAttachmentParams) (query.EmailAttachment, error) {
+	return query.EmailAttachment{ID: params.ID, EmailID: params.EmailID, FileID: params.FileID}, nil
+}
+
+func (m *mockStore) InsertEmail(ctx context.Context, params query.InsertEmailParams) (query.Email, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.emails++
+	return query.Email{ID: params.ID, UserID: params.UserID, EmailTo: params.EmailTo}, nil
+}
+
+type mockProvider struct{}
+
+func (mockProvider) Upload(ctx context.Context, f *file.File) error {
+	return nil
+}
+
+func (mockProvider) Download(ctx context.Context, fileKey string) ([]byte, error) {
+	return []byte("content"), nil
+}
+
+func (mockProvider) Remove(ctx context.Context, fileKey string) error {
+	return nil
+}
+
+// newRouter returns the REST routes backed by the store
+func newRouter(store *mockStore) http.Handler {
+	cfg := &config.Config{ContextTimeout: time.Second, EmailFrom: "noreply@example.com"}
+	files := file.NewService(cfg, store, mockProvider{})
+	h := rest.NewHandler(
+		cfg,
+		nil,
+		fakeAuth{},
+		nil,
+		nil,
+		email.NewService(cfg, store, store, nil, files),
+		files,
+		note.NewService(store),
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+	)
+	return rest.NewRouter(h, store)
+}
+
+// serve makes a request as the user with the token and returns the status
+func serve(router http.Handler, method, path, token string, form url.Values) int {
+	r := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w.Code
+}
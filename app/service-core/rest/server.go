@@ -2,17 +2,49 @@ package rest
 
 import (
 	"app/pkg"
+	"app/pkg/auth"
 	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
 	"service-core/config"
+	"service-core/storage/query"
+	"strings"
 )
 
 func Run(apiHandler *Handler) *http.Server {
+	cfg := apiHandler.cfg
+	handler := NewRouter(apiHandler, query.New(apiHandler.storage.Conn))
+
+	server := &http.Server{Addr: ":" + cfg.HTTPPort, Handler: handler, ReadHeaderTimeout: cfg.HTTPTimeout, WriteTimeout: cfg.HTTPTimeout}
+	go func() {
+		slog.Info("HTTP server listening on", "port", cfg.HTTPPort)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("Error serving HTTP", "error", err)
+			panic(err)
+		}
+	}()
+	return server
+}
+
+// loginTOTPRequestsPerMinute limits how often a client may try to complete
+// sign-in with a two-factor code
+const loginTOTPRequestsPerMinute = 10
+
+// NewRouter returns the REST API's routes and middleware. Agency routes
+// resolve the agency and check the user's membership, looked up in
+// memberships, and role before the handler runs.
+func NewRouter(apiHandler *Handler, memberships membershipStore) http.Handler {
 	cfg := apiHandler.cfg
 	mux := http.NewServeMux()
 
+	agency := func(next http.HandlerFunc, permissions Permissions) http.HandlerFunc {
+		return AgencyMiddleware(cfg, apiHandler.authService, memberships, permissions)(next)
+	}
+
+	// Two-factor codes are also locked per user after too many invalid ones
+	loginTOTP := RateLimitMiddleware(loginTOTPRequestsPerMinute)(apiHandler.handleLoginTOTP)
+
 	// Login and authentication
 	mux.HandleFunc("/refresh", apiHandler.handleRefresh)
 	mux.HandleFunc("/logout", apiHandler.handleLogout)
@@ -20,6 +52,11 @@ func Run(apiHandler *Handler) *http.Server {
 	mux.HandleFunc("/login-callback/{provider}", apiHandler.handleLoginCallback)
 	mux.HandleFunc("/login-phone", apiHandler.handleLoginPhone)
 	mux.HandleFunc("/login-verify", apiHandler.handleLoginVerify)
+	mux.HandleFunc("/login-totp", loginTOTP)
+	mux.HandleFunc("/login-passkey/options", apiHandler.handleLoginPasskeyOptions)
+	mux.HandleFunc("/login-passkey", apiHandler.handleLoginPasskey)
+	mux.HandleFunc("/login-passkey-verify/options", apiHandler.handleLoginPasskeyVerifyOptions)
+	mux.HandleFunc("/login-passkey-verify", apiHandler.handleLoginPasskeyVerify)
 
 	// API v1 routes (what the frontend expects)
 	mux.HandleFunc("/api/v1/refresh", apiHandler.handleRefresh)
@@ -28,6 +65,11 @@ func Run(apiHandler *Handler) *http.Server {
 	mux.HandleFunc("/api/v1/login-callback/{provider}", apiHandler.handleLoginCallback)
 	mux.HandleFunc("/api/v1/login-phone", apiHandler.handleLoginPhone)
 	mux.HandleFunc("/api/v1/login-verify", apiHandler.handleLoginVerify)
+	mux.HandleFunc("/api/v1/login-totp", loginTOTP)
+	mux.HandleFunc("/api/v1/login-passkey/options", apiHandler.handleLoginPasskeyOptions)
+	mux.HandleFunc("/api/v1/login-passkey", apiHandler.handleLoginPasskey)
+	mux.HandleFunc("/api/v1/login-passkey-verify/options", apiHandler.handleLoginPasskeyVerifyOptions)
+	mux.HandleFunc("/api/v1/login-passkey-verify", apiHandler.handleLoginPasskeyVerify)
 
 	// Billing (agency subscriptions)
 	mux.HandleFunc("/api/v1/billing/info", apiHandler.handleBillingInfo)
@@ -38,8 +80,49 @@ func Run(apiHandler *Handler) *http.Server {
 	mux.HandleFunc("/api/v1/billing/sync-session", apiHandler.handleBillingSyncSession)
 	mux.HandleFunc("/api/v1/billing/webhook", apiHandler.handleBillingWebhook)
 
+	// Sessions
+	mux.HandleFunc("/api/v1/sessions", apiHandler.handleSessionsCollection)
+	mux.HandleFunc("/api/v1/sessions/{id}", apiHandler.handleSessionResource)
+
+	// Two-factor authentication
+	mux.HandleFunc("/api/v1/two-factor", apiHandler.handleTwoFactorStatus)
+	mux.HandleFunc("/api/v1/two-factor/totp/setup", apiHandler.handleTOTPSetup)
+	mux.HandleFunc("/api/v1/two-factor/totp/enable", apiHandler.handleTOTPEnable)
+	mux.HandleFunc("/api/v1/two-factor/totp/disable", apiHandler.handleTOTPDisable)
+	mux.HandleFunc("/api/v1/two-factor/recovery-codes", apiHandler.handleRecoveryCodes)
+
+	// Passkeys
+	mux.HandleFunc("/api/v1/passkeys", apiHandler.handlePasskeysCollection)
+	mux.HandleFunc("/api/v1/passkeys/options", apiHandler.handlePasskeyOptions)
+	mux.HandleFunc("/api/v1/passkeys/{id}", apiHandler.handlePasskeyResource)
+
+	// API keys
+	mux.HandleFunc("/api/v1/api-keys", apiHandler.handleAPIKeysCollection)
+	mux.HandleFunc("/api/v1/api-keys/{id}", apiHandler.handleAPIKeyResource)
+	mux.HandleFunc("/api/v1/agency-api-keys", agency(apiHandler.handleAgencyAPIKeysCollection, Permissions{
+		http.MethodGet:  auth.GetSettings,
+		http.MethodPost: auth.EditSettings,
+	}))
+	mux.HandleFunc("/api/v1/agency-api-keys/{id}", agency(apiHandler.handleAgencyAPIKeyResource, Permissions{http.MethodDelete: auth.EditSettings}))
+
 	// Emails
 	mux.HandleFunc("/api/v1/emails", apiHandler.handleEmails)
+	mux.HandleFunc("/api/v1/emails/{id}/resend", apiHandler.handleEmailResend)
+	mux.HandleFunc("/api/v1/email-webhooks/{provider}", apiHandler.handleEmailWebhook)
+	mux.HandleFunc("/api/v1/email-templates", agency(apiHandler.handleEmailTemplatesCollection, Permissions{http.MethodGet: auth.GetSettings}))
+	mux.HandleFunc("/api/v1/email-templates/{name}", agency(apiHandler.handleEmailTemplateResource, Permissions{
+		http.MethodGet:    auth.GetSettings,
+		http.MethodPut:    auth.EditSettings,
+		http.MethodDelete: auth.EditSettings,
+	}))
+	mux.HandleFunc("/api/v1/email-templates/{name}/versions", agency(apiHandler.handleEmailTemplateVersions, Permissions{http.MethodGet: auth.GetSettings}))
+	mux.HandleFunc("/api/v1/email-templates/{name}/preview", agency(apiHandler.handleEmailTemplatePreview, Permissions{http.MethodPost: auth.GetSettings}))
+	mux.HandleFunc("/api/v1/email-suppressions", agency(apiHandler.handleEmailSuppressionsCollection, Permissions{
+		http.MethodGet:  auth.GetSettings,
+		http.MethodPost: auth.EditSettings,
+	}))
+	mux.HandleFunc("/api/v1/email-suppressions/{id}", agency(apiHandler.handleEmailSuppressionResource, Permissions{http.MethodDelete: auth.EditSettings}))
+	mux.HandleFunc("/api/v1/email-unsubscribe/{token}", apiHandler.handleEmailUnsubscribe)
 
 	// Files
 	mux.HandleFunc("/api/v1/files", apiHandler.handleFilesCollection)
@@ -49,8 +132,123 @@ func Run(apiHandler *Handler) *http.Server {
 	mux.HandleFunc("/api/v1/notes", apiHandler.handleNotesCollection)
 	mux.HandleFunc("/api/v1/notes/{id}", apiHandler.handleNoteResource)
 
+	// Proposals
+	mux.HandleFunc("/api/v1/proposals", agency(apiHandler.handleProposalsCollection, Permissions{
+		http.MethodGet:  auth.GetProposals,
+		http.MethodPost: auth.CreateProposal,
+	}))
+	mux.HandleFunc("/api/v1/proposals/{id}", agency(apiHandler.handleProposalResource, Permissions{
+		http.MethodGet:    auth.GetProposals,
+		http.MethodPut:    auth.EditProposal,
+		http.MethodDelete: auth.RemoveProposal,
+	}))
+	mux.HandleFunc("/api/v1/proposals/{id}/sections/{section}", agency(apiHandler.handleProposalSection, Permissions{http.MethodPut: auth.EditProposal}))
+	mux.HandleFunc("/api/v1/proposals/{id}/duplicate", agency(apiHandler.handleProposalDuplicate, Permissions{http.MethodPost: auth.CreateProposal}))
+	mux.HandleFunc("/api/v1/proposals/{id}/status", agency(apiHandler.handleProposalStatus, Permissions{http.MethodPost: auth.EditProposal}))
+	mux.HandleFunc("/api/v1/proposals/{id}/pricing", agency(apiHandler.handleProposalPricing, Permissions{http.MethodGet: auth.GetProposals}))
+	mux.HandleFunc("/api/v1/proposals/{id}/pdf", agency(apiHandler.handleProposalPDF, Permissions{http.MethodPost: auth.GetProposals}))
+	mux.HandleFunc("/api/v1/proposals/{id}/invoice", agency(apiHandler.handleProposalInvoice, Permissions{http.MethodPost: auth.CreateInvoice}))
+	mux.HandleFunc("/api/v1/public/proposals/{slug}/view", apiHandler.handleProposalView)
+
+	// Invoices
+	mux.HandleFunc("/api/v1/invoices", agency(apiHandler.handleInvoicesCollection, Permissions{
+		http.MethodGet:  auth.GetInvoices,
+		http.MethodPost: auth.CreateInvoice,
+	}))
+	mux.HandleFunc("/api/v1/invoices/{id}", agency(apiHandler.handleInvoiceResource, Permissions{
+		http.MethodGet:    auth.GetInvoices,
+		http.MethodPut:    auth.EditInvoice,
+		http.MethodDelete: auth.RemoveInvoice,
+	}))
+	mux.HandleFunc("/api/v1/invoices/{id}/line-items", agency(apiHandler.handleInvoiceLineItems, Permissions{http.MethodPost: auth.EditInvoice}))
+	mux.HandleFunc("/api/v1/invoices/{id}/line-items/{itemId}", agency(apiHandler.handleInvoiceLineItem, Permissions{
+		http.MethodPut:    auth.EditInvoice,
+		http.MethodDelete: auth.EditInvoice,
+	}))
+	mux.HandleFunc("/api/v1/invoices/{id}/status", agency(apiHandler.handleInvoiceStatus, Permissions{http.MethodPost: auth.EditInvoice}))
+	mux.HandleFunc("/api/v1/invoices/{id}/payments", agency(apiHandler.handleInvoicePayments, Permissions{http.MethodPost: auth.EditInvoice}))
+	mux.HandleFunc("/api/v1/invoices/{id}/pdf", agency(apiHandler.handleInvoicePDF, Permissions{http.MethodPost: auth.GetInvoices}))
+	mux.HandleFunc("/api/v1/public/invoices/{slug}/view", apiHandler.handleInvoiceView)
+
+	// Contracts
+	mux.HandleFunc("/api/v1/contracts", agency(apiHandler.handleContractsCollection, Permissions{
+		http.MethodGet:  auth.GetContracts,
+		http.MethodPost: auth.CreateContract,
+	}))
+	mux.HandleFunc("/api/v1/contracts/{id}", agency(apiHandler.handleContractResource, Permissions{
+		http.MethodGet:    auth.GetContracts,
+		http.MethodPut:    auth.EditContract,
+		http.MethodDelete: auth.RemoveContract,
+	}))
+	mux.HandleFunc("/api/v1/contracts/{id}/status", agency(apiHandler.handleContractStatus, Permissions{http.MethodPost: auth.EditContract}))
+	mux.HandleFunc("/api/v1/contracts/{id}/sign", agency(apiHandler.handleContractSign, Permissions{http.MethodPost: auth.EditContract}))
+	mux.HandleFunc("/api/v1/contracts/{id}/verify", agency(apiHandler.handleContractVerify, Permissions{http.MethodGet: auth.GetContracts}))
+	mux.HandleFunc("/api/v1/contracts/{id}/pdf", agency(apiHandler.handleContractPDF, Permissions{http.MethodPost: auth.GetContracts}))
+	mux.HandleFunc("/api/v1/contracts/{id}/invoice", agency(apiHandler.handleContractInvoice, Permissions{http.MethodPost: auth.CreateInvoice}))
+	mux.HandleFunc("/api/v1/public/contracts/{slug}/view", apiHandler.handleContractView)
+	mux.HandleFunc("/api/v1/public/contracts/{slug}/sign", apiHandler.handleContractClientSign)
+
+	// Consultations
+	mux.HandleFunc("/api/v1/consultations", agency(apiHandler.handleConsultationsCollection, Permissions{
+		http.MethodGet:  auth.GetConsultations,
+		http.MethodPost: auth.CreateConsultation,
+	}))
+	mux.HandleFunc("/api/v1/consultations/{id}", agency(apiHandler.handleConsultationResource, Permissions{
+		http.MethodGet: auth.GetConsultations,
+		http.MethodPut: auth.EditConsultation,
+	}))
+	mux.HandleFunc("/api/v1/consultations/{id}/versions", agency(apiHandler.handleConsultationVersions, Permissions{http.MethodGet: auth.GetConsultations}))
+	mux.HandleFunc("/api/v1/consultations/{id}/versions/{version}", agency(apiHandler.handleConsultationVersion, Permissions{http.MethodGet: auth.GetConsultations}))
+	mux.HandleFunc("/api/v1/consultations/{id}/versions/{version}/restore", agency(apiHandler.handleConsultationRestore, Permissions{http.MethodPost: auth.EditConsultation}))
+
+	// Forms
+	mux.HandleFunc("/api/v1/forms/{id}/mapping", agency(apiHandler.handleFormMapping, Permissions{http.MethodPut: auth.EditForms}))
+	mux.HandleFunc("/api/v1/forms/{id}/publish", agency(apiHandler.handleFormPublish, Permissions{http.MethodPost: auth.EditForms}))
+	mux.HandleFunc("/api/v1/forms/{id}/versions", agency(apiHandler.handleFormVersions, Permissions{http.MethodGet: auth.GetForms}))
+	mux.HandleFunc("/api/v1/forms/{id}/versions/{version}", agency(apiHandler.handleFormVersion, Permissions{http.MethodGet: auth.GetForms}))
+	mux.HandleFunc("/api/v1/public/forms/{id}/evaluate", apiHandler.handleFormEvaluate)
+	mux.HandleFunc("/api/v1/form-submissions/{id}/process", agency(apiHandler.handleSubmissionProcess, Permissions{http.MethodPost: auth.EditForms}))
+	mux.HandleFunc("/api/v1/public/form-submissions/{id}/complete", apiHandler.handleSubmissionComplete)
+
+	// Clients
+	mux.HandleFunc("/api/v1/clients", agency(apiHandler.handleClientsCollection, Permissions{
+		http.MethodGet:  auth.GetClients,
+		http.MethodPost: auth.CreateClient,
+	}))
+	mux.HandleFunc("/api/v1/clients/duplicates", agency(apiHandler.handleClientDuplicates, Permissions{http.MethodGet: auth.GetClients}))
+	mux.HandleFunc("/api/v1/clients/merge", agency(apiHandler.handleClientMerge, Permissions{http.MethodPost: auth.RemoveClient}))
+	mux.HandleFunc("/api/v1/clients/{id}", agency(apiHandler.handleClientResource, Permissions{
+		http.MethodGet:    auth.GetClients,
+		http.MethodPut:    auth.EditClient,
+		http.MethodDelete: auth.RemoveClient,
+	}))
+	mux.HandleFunc("/api/v1/clients/{id}/status", agency(apiHandler.handleClientStatus, Permissions{http.MethodPost: auth.EditClient}))
+
+	// Quotations
+	mux.HandleFunc("/api/v1/quotations", agency(apiHandler.handleQuotationsCollection, Permissions{
+		http.MethodGet:  auth.GetQuotations,
+		http.MethodPost: auth.CreateQuotation,
+	}))
+	mux.HandleFunc("/api/v1/quotations/{id}", agency(apiHandler.handleQuotationResource, Permissions{
+		http.MethodGet:    auth.GetQuotations,
+		http.MethodPut:    auth.EditQuotation,
+		http.MethodDelete: auth.RemoveQuotation,
+	}))
+	mux.HandleFunc("/api/v1/quotations/{id}/status", agency(apiHandler.handleQuotationStatus, Permissions{http.MethodPost: auth.EditQuotation}))
+	mux.HandleFunc("/api/v1/quotations/{id}/invoice", agency(apiHandler.handleQuotationInvoice, Permissions{http.MethodPost: auth.CreateInvoice}))
+	mux.HandleFunc("/api/v1/public/quotations/{slug}/view", apiHandler.handleQuotationView)
+	mux.HandleFunc("/api/v1/public/quotations/{slug}/accept", apiHandler.handleQuotationAccept)
+	mux.HandleFunc("/api/v1/public/quotations/{slug}/decline", apiHandler.handleQuotationDecline)
+
+	// Document numbering
+	mux.HandleFunc("/api/v1/numbering", agency(apiHandler.handleNumberingCollection, Permissions{http.MethodGet: auth.GetSettings}))
+	mux.HandleFunc("/api/v1/numbering/{type}", agency(apiHandler.handleNumberingResource, Permissions{http.MethodPut: auth.EditSettings}))
+	mux.HandleFunc("/api/v1/numbering/{type}/gaps", agency(apiHandler.handleNumberingGaps, Permissions{http.MethodGet: auth.GetSettings}))
+
 	// Cron jobs
 	mux.HandleFunc("/tasks/delete-tokens", apiHandler.handleTasksDeleteTokens)
+	mux.HandleFunc("/tasks/expire-quotations", apiHandler.handleTasksExpireQuotations)
+	mux.HandleFunc("/tasks/process-submissions", apiHandler.handleTasksProcessSubmissions)
 
 	// Health checks
 	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
@@ -87,17 +285,7 @@ func Run(apiHandler *Handler) *http.Server {
 
 	// Apply CORS middleware globally
 	corsHandler := corsMiddleware(cfg, mux)
-	handler := loggingMiddleware(corsHandler)
-
-	server := &http.Server{Addr: ":" + cfg.HTTPPort, Handler: handler, ReadHeaderTimeout: cfg.HTTPTimeout, WriteTimeout: cfg.HTTPTimeout}
-	go func() {
-		slog.Info("HTTP server listening on", "port", cfg.HTTPPort)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			slog.Error("Error serving HTTP", "error", err)
-			panic(err)
-		}
-	}()
-	return server
+	return requestIDMiddleware(loggingMiddleware(corsHandler))
 }
 
 // corsMiddleware handles CORS headers globally
@@ -107,7 +295,8 @@ func corsMiddleware(cfg *config.Config, next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Origin", cfg.ClientURL)
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, X-Agency-ID, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
 		// Handle preflight requests
@@ -120,6 +309,9 @@ func corsMiddleware(cfg *config.Config, next http.Handler) http.Handler {
 	})
 }
 
+// extractAccessToken returns the credential a request was made with: the
+// access token cookie, or else a bearer token or API key from the
+// Authorization header
 func extractAccessToken(r *http.Request) string {
 	token, err := r.Cookie("access_token")
 	if err != nil {
@@ -127,6 +319,9 @@ func extractAccessToken(r *http.Request) string {
 		if authHeader != "" && len(authHeader) > 7 && authHeader[:7] == "Bearer " {
 			return authHeader[7:]
 		}
+		if key, ok := strings.CutPrefix(authHeader, "ApiKey "); ok {
+			return strings.TrimSpace(key)
+		}
 		return authHeader
 	}
 	return token.Value
@@ -136,82 +331,18 @@ func writeResponse(cfg *config.Config, w http.ResponseWriter, r *http.Request, d
 	w.Header().Set("Access-Control-Allow-Origin", cfg.ClientURL)
 	w.Header().Set("Access-Control-Allow-Credentials", "true")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, X-Agency-ID, X-Request-ID")
+	w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 
 	if err != nil {
 		var unauthorizedError pkg.UnauthorizedError
-		var internalError pkg.InternalError
-		var badRequestError pkg.BadRequestError
-		var notFoundError pkg.NotFoundError
-		var validationErrors pkg.ValidationErrors
-		switch {
-		case errors.As(err, &unauthorizedError):
+		if returnURL := r.FormValue("return_url"); returnURL != "" && errors.As(err, &unauthorizedError) {
 			slog.Error("Unauthorized", "error", err)
-			returnURL := r.FormValue("return_url")
-			if returnURL == "" {
-				// Return JSON error for API requests
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				json.NewEncoder(w).Encode(map[string]interface{}{
-					"success": false,
-					"message": "Unauthorized",
-					"code":    401,
-				})
-			} else {
-				http.Redirect(w, r, returnURL+"/login?error=unauthorized", http.StatusSeeOther)
-			}
-			return
-		case errors.As(err, &internalError):
-			slog.Error("Internal error", "error", internalError)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"message": internalError.Message,
-				"code":    500,
-			})
-			return
-		case errors.As(err, &badRequestError):
-			slog.Error("Bad request error", "error", badRequestError)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"message": badRequestError.Message,
-				"code":    400,
-			})
-			return
-		case errors.As(err, &notFoundError):
-			slog.Error("Not found error", "error", notFoundError)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"message": notFoundError.Message,
-				"code":    404,
-			})
-			return
-		case errors.As(err, &validationErrors):
-			slog.Error("Validation error", "error", validationErrors)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusUnprocessableEntity)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"message": validationErrors.Error(),
-				"code":    422,
-			})
-			return
-		default:
-			slog.Error("Error", "error", err)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"message": "An internal error occurred",
-				"code":    500,
-			})
+			http.Redirect(w, r, returnURL+"/login?error=unauthorized", http.StatusSeeOther)
 			return
 		}
+		writeProblem(w, r, err)
+		return
 	}
 	if data == nil {
 		w.WriteHeader(http.StatusNoContent)
@@ -232,4 +363,41 @@ func writeResponse(cfg *config.Config, w http.ResponseWriter, r *http.Request, d
 		http.Error(w, "Error writing response", http.StatusInternalServerError)
 		return
 	}
+}
+
+// problemResponse is the problem details body of an error response. The
+// success, message and code members keep it readable by clients of the
+// Safe<T> format.
+type problemResponse struct {
+	pkg.Problem
+	Success    bool   `json:"success"`
+	Message    string `json:"message"`
+	StatusCode int    `json:"code"`
+}
+
+// writeProblem writes an error as an RFC 7807 problem response
+func writeProblem(w http.ResponseWriter, r *http.Request, err error) {
+	problem := pkg.NewProblem(err)
+	problem.Instance = r.URL.Path
+	problem.RequestID = pkg.RequestIDFromContext(r.Context())
+	level := slog.LevelWarn
+	if problem.Status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	slog.Log(r.Context(), level, problem.Title,
+		"error", err,
+		"error_code", problem.Code,
+		"request_id", problem.RequestID,
+	)
+	w.Header().Set("Content-Type", pkg.ProblemContentType)
+	w.WriteHeader(problem.Status)
+	err = json.NewEncoder(w).Encode(problemResponse{
+		Problem:    problem,
+		Success:    false,
+		Message:    problem.Detail,
+		StatusCode: problem.Status,
+	})
+	if err != nil {
+		slog.Error("Error writing problem response", "error", err)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"app/pkg"
+	"net"
+	"net/http"
+	"service-core/domain/session"
+
+	"github.com/google/uuid"
+)
+
+// maxUserAgentLength bounds the user agent kept for a session
+const maxUserAgentLength = 512
+
+// sessionClient describes the device a request was made from
+func sessionClient(r *http.Request) session.Client {
+	ip := getClientIP(r)
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return session.Client{UserAgent: userAgent, IP: ip}
+}
+
+func (h *Handler) handleSessionsCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), 0)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	if err := requireSession(user); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		response, err := h.sessionService.List(r.Context(), user.ID, user.SessionID)
+		writeResponse(h.cfg, w, r, response, err)
+
+	case http.MethodDelete:
+		// ?others=true keeps the session the request was made with
+		keep := uuid.Nil
+		if r.URL.Query().Get("others") == "true" {
+			keep = user.SessionID
+		}
+		err := h.sessionService.RevokeAll(r.Context(), user.ID, keep)
+		writeResponse(h.cfg, w, r, nil, err)
+
+	default:
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+	}
+}
+
+func (h *Handler) handleSessionResource(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	id, err := parsePathID(r, "id", "session")
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	user, err := h.authService.Auth(extractAccessToken(r), 0)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	if err := requireSession(user); err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	err = h.sessionService.Revoke(r.Context(), user.ID, id)
+	writeResponse(h.cfg, w, r, nil, err)
+}
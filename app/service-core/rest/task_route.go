@@ -1,9 +1,13 @@
 package rest
 
 import (
+	"app/pkg"
+	"errors"
 	"log/slog"
 	"net/http"
+	"service-core/domain/submission"
 	"service-core/storage/query"
+	"time"
 )
 
 func (h *Handler) handleTasksDeleteTokens(w http.ResponseWriter, r *http.Request) {
@@ -11,15 +15,51 @@ func (h *Handler) handleTasksDeleteTokens(w http.ResponseWriter, r *http.Request
 	apiKey := r.Header.Get("X-Api-Key")
 	if apiKey != h.cfg.TaskToken {
 		slog.Error("Invalid API key")
-		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		writeProblem(w, r, pkg.UnauthorizedError{Err: errors.New("invalid API key")})
 		return
 	}
 	store := query.New(h.storage.Conn)
 	err := store.DeleteTokens(r.Context())
 	if err != nil {
 		slog.Error("Error deleting tokens", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeProblem(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
+
+func (h *Handler) handleTasksExpireQuotations(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Running Task: Expire Quotations")
+	apiKey := r.Header.Get("X-Api-Key")
+	if apiKey != h.cfg.TaskToken {
+		slog.Error("Invalid API key")
+		writeProblem(w, r, pkg.UnauthorizedError{Err: errors.New("invalid API key")})
+		return
+	}
+	n, err := h.quotationService.ExpireQuotations(r.Context(), time.Now())
+	if err != nil {
+		slog.Error("Error expiring quotations", "error", err)
+		writeProblem(w, r, err)
+		return
+	}
+	slog.Info("Expired quotations", "count", n)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) handleTasksProcessSubmissions(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Running Task: Process Submissions")
+	apiKey := r.Header.Get("X-Api-Key")
+	if apiKey != h.cfg.TaskToken {
+		slog.Error("Invalid API key")
+		writeProblem(w, r, pkg.UnauthorizedError{Err: errors.New("invalid API key")})
+		return
+	}
+	result, err := h.submissionService.ProcessPending(r.Context(), submission.BatchSize)
+	if err != nil {
+		slog.Error("Error processing submissions", "error", err)
+		writeProblem(w, r, err)
+		return
+	}
+	slog.Info("Processed submissions", "processed", result.Processed, "failed", result.Failed)
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,135 @@
+package rest
+
+import (
+	"app/pkg"
+	"app/pkg/auth"
+	"encoding/json"
+	"net/http"
+	"service-core/domain/twofactor"
+)
+
+// authSession authenticates a two-factor or passkey management request,
+// which must be made by a signed-in user rather than with an API key
+func (h *Handler) authSession(r *http.Request) (*auth.AccessTokenClaims, error) {
+	user, err := h.authService.Auth(extractAccessToken(r), 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireSession(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func decodeCodeRequest(r *http.Request) (string, error) {
+	var req twofactor.CodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return "", pkg.BadRequestError{Message: "Invalid request body", Err: err}
+	}
+	return req.Code, nil
+}
+
+func (h *Handler) handleTwoFactorStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	user, err := h.authSession(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	response, err := h.twoFactorService.Status(r.Context(), user.ID)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+func (h *Handler) handleTOTPSetup(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	user, err := h.authSession(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	response, err := h.twoFactorService.Setup(r.Context(), user.ID, user.Email)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+func (h *Handler) handleTOTPEnable(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	user, err := h.authSession(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	code, err := decodeCodeRequest(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	response, err := h.twoFactorService.Enable(r.Context(), user.ID, code)
+	writeResponse(h.cfg, w, r, response, err)
+}
+
+func (h *Handler) handleTOTPDisable(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	user, err := h.authSession(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	code, err := decodeCodeRequest(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	err = h.twoFactorService.Disable(r.Context(), user.ID, code)
+	writeResponse(h.cfg, w, r, nil, err)
+}
+
+func (h *Handler) handleRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		writeResponse(h.cfg, w, r, nil, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeResponse(h.cfg, w, r, nil, pkg.MethodNotAllowedError{Method: r.Method})
+		return
+	}
+	user, err := h.authSession(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	code, err := decodeCodeRequest(r)
+	if err != nil {
+		writeResponse(h.cfg, w, r, nil, err)
+		return
+	}
+	response, err := h.twoFactorService.RegenerateRecoveryCodes(r.Context(), user.ID, code)
+	writeResponse(h.cfg, w, r, response, err)
+}
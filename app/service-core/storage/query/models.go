@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"time"
 
+	"app/pkg/money"
 	"github.com/google/uuid"
 	"github.com/sqlc-dev/pqtype"
 )
@@ -67,7 +68,7 @@ type AgencyAddon struct {
 	Name              string          `json:"name"`
 	Slug              string          `json:"slug"`
 	Description       string          `json:"description"`
-	Price             string          `json:"price"`
+	Price             money.Money     `json:"price"`
 	PricingType       string          `json:"pricing_type"`
 	UnitLabel         sql.NullString  `json:"unit_label"`
 	AvailablePackages json.RawMessage `json:"available_packages"`
@@ -88,26 +89,38 @@ type AgencyDocumentBranding struct {
 	AccentGradient    sql.NullString `json:"accent_gradient"`
 }
 
+type AgencyDocumentNumbering struct {
+	ID           uuid.UUID `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	AgencyID     uuid.UUID `json:"agency_id"`
+	DocumentType string    `json:"document_type"`
+	Format       string    `json:"format"`
+	ResetYearly  bool      `json:"reset_yearly"`
+	SequenceYear int32     `json:"sequence_year"`
+}
+
 type AgencyForm struct {
-	ID               uuid.UUID             `json:"id"`
-	AgencyID         uuid.UUID             `json:"agency_id"`
-	Name             string                `json:"name"`
-	Slug             string                `json:"slug"`
-	Description      sql.NullString        `json:"description"`
-	FormType         string                `json:"form_type"`
-	Schema           json.RawMessage       `json:"schema"`
-	UiConfig         json.RawMessage       `json:"ui_config"`
-	Branding         pqtype.NullRawMessage `json:"branding"`
-	IsActive         bool                  `json:"is_active"`
-	IsDefault        bool                  `json:"is_default"`
-	RequiresAuth     bool                  `json:"requires_auth"`
-	SourceTemplateID uuid.NullUUID         `json:"source_template_id"`
-	IsCustomized     bool                  `json:"is_customized"`
-	PreviousSchema   pqtype.NullRawMessage `json:"previous_schema"`
-	Version          int32                 `json:"version"`
-	CreatedAt        time.Time             `json:"created_at"`
-	UpdatedAt        time.Time             `json:"updated_at"`
-	CreatedBy        uuid.NullUUID         `json:"created_by"`
+	ID                  uuid.UUID             `json:"id"`
+	AgencyID            uuid.UUID             `json:"agency_id"`
+	Name                string                `json:"name"`
+	Slug                string                `json:"slug"`
+	Description         sql.NullString        `json:"description"`
+	FormType            string                `json:"form_type"`
+	Schema              json.RawMessage       `json:"schema"`
+	UiConfig            json.RawMessage       `json:"ui_config"`
+	Branding            pqtype.NullRawMessage `json:"branding"`
+	IsActive            bool                  `json:"is_active"`
+	IsDefault           bool                  `json:"is_default"`
+	RequiresAuth        bool                  `json:"requires_auth"`
+	SourceTemplateID    uuid.NullUUID         `json:"source_template_id"`
+	IsCustomized        bool                  `json:"is_customized"`
+	PreviousSchema      pqtype.NullRawMessage `json:"previous_schema"`
+	ConsultationMapping json.RawMessage       `json:"consultation_mapping"`
+	Version             int32                 `json:"version"`
+	CreatedAt           time.Time             `json:"created_at"`
+	UpdatedAt           time.Time             `json:"updated_at"`
+	CreatedBy           uuid.NullUUID         `json:"created_by"`
 }
 
 type AgencyFormOption struct {
@@ -124,6 +137,18 @@ type AgencyFormOption struct {
 	Metadata  json.RawMessage `json:"metadata"`
 }
 
+type AgencyFormVersion struct {
+	ID        uuid.UUID       `json:"id"`
+	FormID    uuid.UUID       `json:"form_id"`
+	AgencyID  uuid.UUID       `json:"agency_id"`
+	Version   int32           `json:"version"`
+	Schema    json.RawMessage `json:"schema"`
+	UiConfig  json.RawMessage `json:"ui_config"`
+	Changes   json.RawMessage `json:"changes"`
+	CreatedAt time.Time       `json:"created_at"`
+	CreatedBy uuid.NullUUID   `json:"created_by"`
+}
+
 type AgencyMembership struct {
 	ID          uuid.UUID     `json:"id"`
 	CreatedAt   time.Time     `json:"created_at"`
@@ -147,13 +172,13 @@ type AgencyPackage struct {
 	Slug                  string          `json:"slug"`
 	Description           string          `json:"description"`
 	PricingModel          string          `json:"pricing_model"`
-	SetupFee              string          `json:"setup_fee"`
-	MonthlyPrice          string          `json:"monthly_price"`
-	OneTimePrice          string          `json:"one_time_price"`
-	HostingFee            string          `json:"hosting_fee"`
+	SetupFee              money.Money     `json:"setup_fee"`
+	MonthlyPrice          money.Money     `json:"monthly_price"`
+	OneTimePrice          money.Money     `json:"one_time_price"`
+	HostingFee            money.Money     `json:"hosting_fee"`
 	MinimumTermMonths     int32           `json:"minimum_term_months"`
 	CancellationFeeType   sql.NullString  `json:"cancellation_fee_type"`
-	CancellationFeeAmount string          `json:"cancellation_fee_amount"`
+	CancellationFeeAmount money.Money     `json:"cancellation_fee_amount"`
 	IncludedFeatures      json.RawMessage `json:"included_features"`
 	MaxPages              sql.NullInt32   `json:"max_pages"`
 	DisplayOrder          int32           `json:"display_order"`
@@ -162,48 +187,51 @@ type AgencyPackage struct {
 }
 
 type AgencyProfile struct {
-	ID                       uuid.UUID      `json:"id"`
-	CreatedAt                time.Time      `json:"created_at"`
-	UpdatedAt                time.Time      `json:"updated_at"`
-	AgencyID                 uuid.UUID      `json:"agency_id"`
-	Abn                      string         `json:"abn"`
-	Acn                      string         `json:"acn"`
-	LegalEntityName          string         `json:"legal_entity_name"`
-	TradingName              string         `json:"trading_name"`
-	AddressLine1             string         `json:"address_line_1"`
-	AddressLine2             string         `json:"address_line_2"`
-	City                     string         `json:"city"`
-	State                    string         `json:"state"`
-	Postcode                 string         `json:"postcode"`
-	Country                  string         `json:"country"`
-	BankName                 string         `json:"bank_name"`
-	Bsb                      string         `json:"bsb"`
-	AccountNumber            string         `json:"account_number"`
-	AccountName              string         `json:"account_name"`
-	GstRegistered            bool           `json:"gst_registered"`
-	TaxFileNumber            string         `json:"tax_file_number"`
-	GstRate                  string         `json:"gst_rate"`
-	Tagline                  string         `json:"tagline"`
-	SocialLinkedin           string         `json:"social_linkedin"`
-	SocialFacebook           string         `json:"social_facebook"`
-	SocialInstagram          string         `json:"social_instagram"`
-	SocialTwitter            string         `json:"social_twitter"`
-	BrandFont                string         `json:"brand_font"`
-	DefaultPaymentTerms      string         `json:"default_payment_terms"`
-	InvoicePrefix            string         `json:"invoice_prefix"`
-	InvoiceFooter            string         `json:"invoice_footer"`
-	NextInvoiceNumber        int32          `json:"next_invoice_number"`
-	ContractPrefix           string         `json:"contract_prefix"`
-	ContractFooter           string         `json:"contract_footer"`
-	NextContractNumber       int32          `json:"next_contract_number"`
-	ProposalPrefix           string         `json:"proposal_prefix"`
-	NextProposalNumber       int32          `json:"next_proposal_number"`
-	StripeAccountID          sql.NullString `json:"stripe_account_id"`
-	StripeAccountStatus      string         `json:"stripe_account_status"`
-	StripeOnboardingComplete bool           `json:"stripe_onboarding_complete"`
-	StripeConnectedAt        sql.NullTime   `json:"stripe_connected_at"`
-	StripePayoutsEnabled     bool           `json:"stripe_payouts_enabled"`
-	StripeChargesEnabled     bool           `json:"stripe_charges_enabled"`
+	ID                           uuid.UUID      `json:"id"`
+	CreatedAt                    time.Time      `json:"created_at"`
+	UpdatedAt                    time.Time      `json:"updated_at"`
+	AgencyID                     uuid.UUID      `json:"agency_id"`
+	Abn                          string         `json:"abn"`
+	Acn                          string         `json:"acn"`
+	LegalEntityName              string         `json:"legal_entity_name"`
+	TradingName                  string         `json:"trading_name"`
+	AddressLine1                 string         `json:"address_line_1"`
+	AddressLine2                 string         `json:"address_line_2"`
+	City                         string         `json:"city"`
+	State                        string         `json:"state"`
+	Postcode                     string         `json:"postcode"`
+	Country                      string         `json:"country"`
+	BankName                     string         `json:"bank_name"`
+	Bsb                          string         `json:"bsb"`
+	AccountNumber                string         `json:"account_number"`
+	AccountName                  string         `json:"account_name"`
+	GstRegistered                bool           `json:"gst_registered"`
+	TaxFileNumber                string         `json:"tax_file_number"`
+	GstRate                      money.Decimal  `json:"gst_rate"`
+	Tagline                      string         `json:"tagline"`
+	SocialLinkedin               string         `json:"social_linkedin"`
+	SocialFacebook               string         `json:"social_facebook"`
+	SocialInstagram              string         `json:"social_instagram"`
+	SocialTwitter                string         `json:"social_twitter"`
+	BrandFont                    string         `json:"brand_font"`
+	DefaultPaymentTerms          string         `json:"default_payment_terms"`
+	InvoicePrefix                string         `json:"invoice_prefix"`
+	InvoiceFooter                string         `json:"invoice_footer"`
+	NextInvoiceNumber            int32          `json:"next_invoice_number"`
+	ContractPrefix               string         `json:"contract_prefix"`
+	ContractFooter               string         `json:"contract_footer"`
+	NextContractNumber           int32          `json:"next_contract_number"`
+	ProposalPrefix               string         `json:"proposal_prefix"`
+	NextProposalNumber           int32          `json:"next_proposal_number"`
+	QuotationPrefix              string         `json:"quotation_prefix"`
+	NextQuotationNumber          int32          `json:"next_quotation_number"`
+	DefaultQuotationValidityDays int32          `json:"default_quotation_validity_days"`
+	StripeAccountID              sql.NullString `json:"stripe_account_id"`
+	StripeAccountStatus          string         `json:"stripe_account_status"`
+	StripeOnboardingComplete     bool           `json:"stripe_onboarding_complete"`
+	StripeConnectedAt            sql.NullTime   `json:"stripe_connected_at"`
+	StripePayoutsEnabled         bool           `json:"stripe_payouts_enabled"`
+	StripeChargesEnabled         bool           `json:"stripe_charges_enabled"`
 }
 
 type AgencyProposalTemplate struct {
@@ -219,6 +247,21 @@ type AgencyProposalTemplate struct {
 	Settings      json.RawMessage `json:"settings"`
 }
 
+type ApiKey struct {
+	ID         uuid.UUID     `json:"id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	UserID     uuid.UUID     `json:"user_id"`
+	AgencyID   uuid.NullUUID `json:"agency_id"`
+	Name       string        `json:"name"`
+	Prefix     string        `json:"prefix"`
+	KeyHash    string        `json:"key_hash"`
+	Access     int64         `json:"access"`
+	ExpiresAt  sql.NullTime  `json:"expires_at"`
+	LastUsedAt sql.NullTime  `json:"last_used_at"`
+	RevokedAt  sql.NullTime  `json:"revoked_at"`
+}
+
 type BetaInvite struct {
 	ID             uuid.UUID      `json:"id"`
 	CreatedAt      time.Time      `json:"created_at"`
@@ -240,7 +283,9 @@ type Client struct {
 	Phone        sql.NullString `json:"phone"`
 	ContactName  sql.NullString `json:"contact_name"`
 	Notes        sql.NullString `json:"notes"`
+	Abn          string         `json:"abn"`
 	Status       string         `json:"status"`
+	CreatedBy    uuid.NullUUID  `json:"created_by"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 }
@@ -293,16 +338,17 @@ type ConsultationDraft struct {
 }
 
 type ConsultationVersion struct {
-	ID                   uuid.UUID       `json:"id"`
-	ConsultationID       uuid.UUID       `json:"consultation_id"`
-	UserID               uuid.UUID       `json:"user_id"`
-	AgencyID             uuid.NullUUID   `json:"agency_id"`
-	VersionNumber        int32           `json:"version_number"`
-	Status               string          `json:"status"`
-	CompletionPercentage int32           `json:"completion_percentage"`
-	ChangeSummary        sql.NullString  `json:"change_summary"`
-	ChangedFields        json.RawMessage `json:"changed_fields"`
-	CreatedAt            time.Time       `json:"created_at"`
+	ID                   uuid.UUID             `json:"id"`
+	ConsultationID       uuid.UUID             `json:"consultation_id"`
+	UserID               uuid.UUID             `json:"user_id"`
+	AgencyID             uuid.NullUUID         `json:"agency_id"`
+	VersionNumber        int32                 `json:"version_number"`
+	Status               string                `json:"status"`
+	CompletionPercentage int32                 `json:"completion_percentage"`
+	ChangeSummary        sql.NullString        `json:"change_summary"`
+	ChangedFields        json.RawMessage       `json:"changed_fields"`
+	Snapshot             pqtype.NullRawMessage `json:"snapshot"`
+	CreatedAt            time.Time             `json:"created_at"`
 }
 
 type Contract struct {
@@ -326,7 +372,7 @@ type Contract struct {
 	CommencementDate         sql.NullTime    `json:"commencement_date"`
 	CompletionDate           sql.NullTime    `json:"completion_date"`
 	SpecialConditions        string          `json:"special_conditions"`
-	TotalPrice               string          `json:"total_price"`
+	TotalPrice               money.Money     `json:"total_price"`
 	PriceIncludesGst         bool            `json:"price_includes_gst"`
 	PaymentTerms             string          `json:"payment_terms"`
 	GeneratedCoverHtml       sql.NullString  `json:"generated_cover_html"`
@@ -348,6 +394,9 @@ type Contract struct {
 	VisibleFields            json.RawMessage `json:"visible_fields"`
 	IncludedScheduleIds      json.RawMessage `json:"included_schedule_ids"`
 	CreatedBy                uuid.NullUUID   `json:"created_by"`
+	AgencySignatureIp        sql.NullString  `json:"agency_signature_ip"`
+	AgencySignatureUserAgent sql.NullString  `json:"agency_signature_user_agent"`
+	ContentHash              sql.NullString  `json:"content_hash"`
 }
 
 type ContractSchedule struct {
@@ -363,6 +412,23 @@ type ContractSchedule struct {
 	IsActive        bool          `json:"is_active"`
 }
 
+type ContractSignature struct {
+	ID             uuid.UUID     `json:"id"`
+	CreatedAt      time.Time     `json:"created_at"`
+	ContractID     uuid.UUID     `json:"contract_id"`
+	AgencyID       uuid.UUID     `json:"agency_id"`
+	Party          string        `json:"party"`
+	SignatoryName  string        `json:"signatory_name"`
+	SignatoryTitle string        `json:"signatory_title"`
+	SignedAt       time.Time     `json:"signed_at"`
+	IpAddress      string        `json:"ip_address"`
+	UserAgent      string        `json:"user_agent"`
+	UserID         uuid.NullUUID `json:"user_id"`
+	ContentHash    string        `json:"content_hash"`
+	PreviousHash   string        `json:"previous_hash"`
+	SignatureHash  string        `json:"signature_hash"`
+}
+
 type ContractTemplate struct {
 	ID              uuid.UUID       `json:"id"`
 	CreatedAt       time.Time       `json:"created_at"`
@@ -380,22 +446,33 @@ type ContractTemplate struct {
 }
 
 type Email struct {
-	ID           uuid.UUID `json:"id"`
-	Created      time.Time `json:"created"`
-	Updated      time.Time `json:"updated"`
-	UserID       uuid.UUID `json:"user_id"`
-	EmailTo      string    `json:"email_to"`
-	EmailFrom    string    `json:"email_from"`
-	EmailSubject string    `json:"email_subject"`
-	EmailBody    string    `json:"email_body"`
+	ID                uuid.UUID      `json:"id"`
+	Created           time.Time      `json:"created"`
+	Updated           time.Time      `json:"updated"`
+	UserID            uuid.UUID      `json:"user_id"`
+	EmailTo           string         `json:"email_to"`
+	EmailFrom         string         `json:"email_from"`
+	EmailSubject      string         `json:"email_subject"`
+	EmailBody         string         `json:"email_body"`
+	Status            string         `json:"status"`
+	RetryCount        int32          `json:"retry_count"`
+	ErrorMessage      string         `json:"error_message"`
+	NextAttemptAt     time.Time      `json:"next_attempt_at"`
+	LockedUntil       sql.NullTime   `json:"locked_until"`
+	SentAt            sql.NullTime   `json:"sent_at"`
+	EmailLogID        uuid.NullUUID  `json:"email_log_id"`
+	ProviderMessageID string         `json:"provider_message_id"`
+	AgencyID          uuid.NullUUID  `json:"agency_id"`
+	UnsubscribeToken  sql.NullString `json:"unsubscribe_token"`
 }
 
 type EmailAttachment struct {
-	ID          uuid.UUID `json:"id"`
-	Created     time.Time `json:"created"`
-	EmailID     uuid.UUID `json:"email_id"`
-	FileName    string    `json:"file_name"`
-	ContentType string    `json:"content_type"`
+	ID          uuid.UUID     `json:"id"`
+	Created     time.Time     `json:"created"`
+	EmailID     uuid.UUID     `json:"email_id"`
+	FileName    string        `json:"file_name"`
+	ContentType string        `json:"content_type"`
+	FileID      uuid.NullUUID `json:"file_id"`
 }
 
 type EmailLog struct {
@@ -413,14 +490,37 @@ type EmailLog struct {
 	BodyHtml           string         `json:"body_html"`
 	HasAttachment      bool           `json:"has_attachment"`
 	AttachmentFilename sql.NullString `json:"attachment_filename"`
-	ResendMessageID    sql.NullString `json:"resend_message_id"`
+	ProviderMessageID  sql.NullString `json:"provider_message_id"`
 	Status             string         `json:"status"`
 	SentAt             sql.NullTime   `json:"sent_at"`
 	DeliveredAt        sql.NullTime   `json:"delivered_at"`
 	OpenedAt           sql.NullTime   `json:"opened_at"`
+	BouncedAt          sql.NullTime   `json:"bounced_at"`
+	ComplainedAt       sql.NullTime   `json:"complained_at"`
 	ErrorMessage       sql.NullString `json:"error_message"`
 	RetryCount         int32          `json:"retry_count"`
 	SentBy             uuid.NullUUID  `json:"sent_by"`
+	QuotationID        uuid.NullUUID  `json:"quotation_id"`
+}
+
+type EmailSuppression struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	AgencyID  uuid.UUID `json:"agency_id"`
+	Email     string    `json:"email"`
+	Reason    string    `json:"reason"`
+	Detail    string    `json:"detail"`
+}
+
+type EmailTemplate struct {
+	ID        uuid.UUID     `json:"id"`
+	CreatedAt time.Time     `json:"created_at"`
+	AgencyID  uuid.UUID     `json:"agency_id"`
+	Name      string        `json:"name"`
+	Version   int32         `json:"version"`
+	Subject   string        `json:"subject"`
+	Body      string        `json:"body"`
+	CreatedBy uuid.NullUUID `json:"created_by"`
 }
 
 type FieldOptionSet struct {
@@ -436,14 +536,15 @@ type FieldOptionSet struct {
 }
 
 type File struct {
-	ID          uuid.UUID `json:"id"`
-	Created     time.Time `json:"created"`
-	Updated     time.Time `json:"updated"`
-	UserID      uuid.UUID `json:"user_id"`
-	FileKey     string    `json:"file_key"`
-	FileName    string    `json:"file_name"`
-	FileSize    int64     `json:"file_size"`
-	ContentType string    `json:"content_type"`
+	ID          uuid.UUID     `json:"id"`
+	Created     time.Time     `json:"created"`
+	Updated     time.Time     `json:"updated"`
+	UserID      uuid.UUID     `json:"user_id"`
+	FileKey     string        `json:"file_key"`
+	FileName    string        `json:"file_name"`
+	FileSize    int64         `json:"file_size"`
+	ContentType string        `json:"content_type"`
+	AgencyID    uuid.NullUUID `json:"agency_id"`
 }
 
 type FormSubmission struct {
@@ -468,6 +569,8 @@ type FormSubmission struct {
 	SubmittedAt          sql.NullTime    `json:"submitted_at"`
 	ProcessedAt          sql.NullTime    `json:"processed_at"`
 	FormVersion          int32           `json:"form_version"`
+	ProcessingAttempts   int32           `json:"processing_attempts"`
+	ProcessingError      string          `json:"processing_error"`
 }
 
 type FormTemplate struct {
@@ -506,13 +609,13 @@ type Invoice struct {
 	ClientAbn               string         `json:"client_abn"`
 	IssueDate               time.Time      `json:"issue_date"`
 	DueDate                 time.Time      `json:"due_date"`
-	Subtotal                string         `json:"subtotal"`
-	DiscountAmount          string         `json:"discount_amount"`
+	Subtotal                money.Money    `json:"subtotal"`
+	DiscountAmount          money.Money    `json:"discount_amount"`
 	DiscountDescription     string         `json:"discount_description"`
-	GstAmount               string         `json:"gst_amount"`
-	Total                   string         `json:"total"`
+	GstAmount               money.Money    `json:"gst_amount"`
+	Total                   money.Money    `json:"total"`
 	GstRegistered           bool           `json:"gst_registered"`
-	GstRate                 string         `json:"gst_rate"`
+	GstRate                 money.Decimal  `json:"gst_rate"`
 	PaymentTerms            string         `json:"payment_terms"`
 	PaymentTermsCustom      string         `json:"payment_terms_custom"`
 	Notes                   string         `json:"notes"`
@@ -524,6 +627,7 @@ type Invoice struct {
 	PaymentMethod           sql.NullString `json:"payment_method"`
 	PaymentReference        sql.NullString `json:"payment_reference"`
 	PaymentNotes            sql.NullString `json:"payment_notes"`
+	AmountPaid              money.Money    `json:"amount_paid"`
 	PdfUrl                  sql.NullString `json:"pdf_url"`
 	PdfGeneratedAt          sql.NullTime   `json:"pdf_generated_at"`
 	StripePaymentLinkID     sql.NullString `json:"stripe_payment_link_id"`
@@ -532,6 +636,7 @@ type Invoice struct {
 	StripeCheckoutSessionID sql.NullString `json:"stripe_checkout_session_id"`
 	OnlinePaymentEnabled    bool           `json:"online_payment_enabled"`
 	CreatedBy               uuid.NullUUID  `json:"created_by"`
+	QuotationID             uuid.NullUUID  `json:"quotation_id"`
 }
 
 type InvoiceLineItem struct {
@@ -540,9 +645,9 @@ type InvoiceLineItem struct {
 	UpdatedAt   time.Time      `json:"updated_at"`
 	InvoiceID   uuid.UUID      `json:"invoice_id"`
 	Description string         `json:"description"`
-	Quantity    string         `json:"quantity"`
-	UnitPrice   string         `json:"unit_price"`
-	Amount      string         `json:"amount"`
+	Quantity    money.Decimal  `json:"quantity"`
+	UnitPrice   money.Money    `json:"unit_price"`
+	Amount      money.Money    `json:"amount"`
 	IsTaxable   bool           `json:"is_taxable"`
 	SortOrder   int32          `json:"sort_order"`
 	Category    sql.NullString `json:"category"`
@@ -560,6 +665,29 @@ type Note struct {
 	Content  string    `json:"content"`
 }
 
+type Passkey struct {
+	ID           uuid.UUID       `json:"id"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	UserID       uuid.UUID       `json:"user_id"`
+	Name         string          `json:"name"`
+	CredentialID string          `json:"credential_id"`
+	PublicKey    []byte          `json:"public_key"`
+	Algorithm    int32           `json:"algorithm"`
+	SignCount    int64           `json:"sign_count"`
+	Transports   json.RawMessage `json:"transports"`
+	LastUsedAt   sql.NullTime    `json:"last_used_at"`
+}
+
+type PasskeyChallenge struct {
+	ID        string        `json:"id"`
+	CreatedAt time.Time     `json:"created_at"`
+	ExpiresAt time.Time     `json:"expires_at"`
+	Purpose   string        `json:"purpose"`
+	UserID    uuid.NullUUID `json:"user_id"`
+	ReturnUrl string        `json:"return_url"`
+}
+
 type Proposal struct {
 	ID                     uuid.UUID             `json:"id"`
 	CreatedAt              time.Time             `json:"created_at"`
@@ -628,11 +756,135 @@ type QuestionnaireResponse struct {
 	LastActivityAt       sql.NullTime    `json:"last_activity_at"`
 }
 
+type Quotation struct {
+	ID                  uuid.UUID       `json:"id"`
+	CreatedAt           time.Time       `json:"created_at"`
+	UpdatedAt           time.Time       `json:"updated_at"`
+	AgencyID            uuid.UUID       `json:"agency_id"`
+	ClientID            uuid.NullUUID   `json:"client_id"`
+	TemplateID          uuid.NullUUID   `json:"template_id"`
+	QuotationNumber     string          `json:"quotation_number"`
+	Slug                string          `json:"slug"`
+	QuotationName       string          `json:"quotation_name"`
+	Status              string          `json:"status"`
+	ClientBusinessName  string          `json:"client_business_name"`
+	ClientContactName   string          `json:"client_contact_name"`
+	ClientEmail         string          `json:"client_email"`
+	ClientPhone         string          `json:"client_phone"`
+	ClientAddress       string          `json:"client_address"`
+	SiteAddress         string          `json:"site_address"`
+	SiteReference       string          `json:"site_reference"`
+	PreparedDate        time.Time       `json:"prepared_date"`
+	ExpiryDate          time.Time       `json:"expiry_date"`
+	Subtotal            money.Money     `json:"subtotal"`
+	DiscountAmount      money.Money     `json:"discount_amount"`
+	DiscountDescription string          `json:"discount_description"`
+	GstAmount           money.Money     `json:"gst_amount"`
+	Total               money.Money     `json:"total"`
+	GstRegistered       bool            `json:"gst_registered"`
+	GstRate             money.Decimal   `json:"gst_rate"`
+	TermsBlocks         json.RawMessage `json:"terms_blocks"`
+	OptionsNotes        string          `json:"options_notes"`
+	Notes               string          `json:"notes"`
+	ViewCount           int32           `json:"view_count"`
+	LastViewedAt        sql.NullTime    `json:"last_viewed_at"`
+	SentAt              sql.NullTime    `json:"sent_at"`
+	DeclinedAt          sql.NullTime    `json:"declined_at"`
+	DeclineReason       string          `json:"decline_reason"`
+	AcceptedByName      sql.NullString  `json:"accepted_by_name"`
+	AcceptedByTitle     sql.NullString  `json:"accepted_by_title"`
+	AcceptedAt          sql.NullTime    `json:"accepted_at"`
+	AcceptanceIp        sql.NullString  `json:"acceptance_ip"`
+	CreatedBy           uuid.NullUUID   `json:"created_by"`
+}
+
+type QuotationScopeSection struct {
+	ID              uuid.UUID       `json:"id"`
+	CreatedAt       time.Time       `json:"created_at"`
+	QuotationID     uuid.UUID       `json:"quotation_id"`
+	Title           string          `json:"title"`
+	WorkItems       json.RawMessage `json:"work_items"`
+	SectionPrice    money.Money     `json:"section_price"`
+	SectionGst      money.Money     `json:"section_gst"`
+	SectionTotal    money.Money     `json:"section_total"`
+	SortOrder       int32           `json:"sort_order"`
+	ScopeTemplateID uuid.NullUUID   `json:"scope_template_id"`
+}
+
+type QuotationScopeTemplate struct {
+	ID           uuid.UUID       `json:"id"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	AgencyID     uuid.UUID       `json:"agency_id"`
+	Name         string          `json:"name"`
+	Slug         string          `json:"slug"`
+	Description  string          `json:"description"`
+	Category     sql.NullString  `json:"category"`
+	WorkItems    json.RawMessage `json:"work_items"`
+	DefaultPrice money.Money     `json:"default_price"`
+	IsActive     bool            `json:"is_active"`
+	SortOrder    int32           `json:"sort_order"`
+	CreatedBy    uuid.NullUUID   `json:"created_by"`
+}
+
+type QuotationTemplate struct {
+	ID                  uuid.UUID      `json:"id"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	AgencyID            uuid.UUID      `json:"agency_id"`
+	Name                string         `json:"name"`
+	Description         string         `json:"description"`
+	Category            sql.NullString `json:"category"`
+	DefaultValidityDays sql.NullInt32  `json:"default_validity_days"`
+	IsDefault           bool           `json:"is_default"`
+	IsActive            bool           `json:"is_active"`
+	SortOrder           int32          `json:"sort_order"`
+	CreatedBy           uuid.NullUUID  `json:"created_by"`
+}
+
+type QuotationTemplateSection struct {
+	ID                  uuid.UUID   `json:"id"`
+	TemplateID          uuid.UUID   `json:"template_id"`
+	ScopeTemplateID     uuid.UUID   `json:"scope_template_id"`
+	DefaultSectionPrice money.Money `json:"default_section_price"`
+	SortOrder           int32       `json:"sort_order"`
+}
+
+type QuotationTemplateTerm struct {
+	ID              uuid.UUID `json:"id"`
+	TemplateID      uuid.UUID `json:"template_id"`
+	TermsTemplateID uuid.UUID `json:"terms_template_id"`
+	SortOrder       int32     `json:"sort_order"`
+}
+
+type QuotationTermsTemplate struct {
+	ID        uuid.UUID     `json:"id"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	AgencyID  uuid.UUID     `json:"agency_id"`
+	Title     string        `json:"title"`
+	Content   string        `json:"content"`
+	IsDefault bool          `json:"is_default"`
+	SortOrder int32         `json:"sort_order"`
+	IsActive  bool          `json:"is_active"`
+	CreatedBy uuid.NullUUID `json:"created_by"`
+}
+
 type Token struct {
-	ID       string    `json:"id"`
-	Expires  time.Time `json:"expires"`
-	Target   string    `json:"target"`
-	Callback string    `json:"callback"`
+	ID        string       `json:"id"`
+	Expires   time.Time    `json:"expires"`
+	Target    string       `json:"target"`
+	Callback  string       `json:"callback"`
+	Family    string       `json:"family"`
+	Created   time.Time    `json:"created"`
+	Rotated   sql.NullTime `json:"rotated"`
+	UserAgent string       `json:"user_agent"`
+	Ip        string       `json:"ip"`
+}
+
+type UsedSessionToken struct {
+	ID        uuid.UUID `json:"id"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type User struct {
@@ -653,3 +905,22 @@ type User struct {
 	SuspendedAt     sql.NullTime   `json:"suspended_at"`
 	SuspendedReason sql.NullString `json:"suspended_reason"`
 }
+
+type UserRecoveryCode struct {
+	ID        uuid.UUID    `json:"id"`
+	CreatedAt time.Time    `json:"created_at"`
+	UserID    uuid.UUID    `json:"user_id"`
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+}
+
+type UserTotp struct {
+	UserID         uuid.UUID    `json:"user_id"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	Secret         string       `json:"secret"`
+	EnabledAt      sql.NullTime `json:"enabled_at"`
+	LastStep       int64        `json:"last_step"`
+	FailedAttempts int32        `json:"failed_attempts"`
+	LockedUntil    sql.NullTime `json:"locked_until"`
+}
@@ -6,43 +6,323 @@ package query
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
 	AcceptPendingMemberships(ctx context.Context, userID uuid.UUID) error
+	AcceptQuotation(ctx context.Context, arg AcceptQuotationParams) (Quotation, error)
+	// Takes the emails that are due for sending, and those whose worker's lease
+	// has run out, leasing them to the caller. Emails claimed by another
+	// worker are skipped.
+	ClaimEmails(ctx context.Context, arg ClaimEmailsParams) ([]Email, error)
+	CompleteFormSubmission(ctx context.Context, arg CompleteFormSubmissionParams) (FormSubmission, error)
+	// Deletes a challenge and returns it, so each can only be answered once
+	ConsumePasskeyChallenge(ctx context.Context, id string) (PasskeyChallenge, error)
+	CountClientDocuments(ctx context.Context, clientID uuid.UUID) (CountClientDocumentsRow, error)
+	// =============================================================================
+	// Client Queries
+	// =============================================================================
+	CountClients(ctx context.Context, arg CountClientsParams) (int64, error)
+	CountConsultations(ctx context.Context, arg CountConsultationsParams) (int64, error)
+	// =============================================================================
+	// Contract Queries
+	// =============================================================================
+	CountContracts(ctx context.Context, arg CountContractsParams) (int64, error)
+	// =============================================================================
+	// Invoice Queries
+	// =============================================================================
+	CountInvoices(ctx context.Context, arg CountInvoicesParams) (int64, error)
 	CountNotes(ctx context.Context, userID uuid.UUID) (int64, error)
+	// =============================================================================
+	// Proposal Queries
+	// =============================================================================
+	CountProposals(ctx context.Context, arg CountProposalsParams) (int64, error)
+	// =============================================================================
+	// Quotation Queries
+	// =============================================================================
+	CountQuotations(ctx context.Context, arg CountQuotationsParams) (int64, error)
+	CountUserPasskeys(ctx context.Context, userID uuid.UUID) (int64, error)
+	// Counts the recovery codes a user has left
+	CountUserRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error)
+	DeclineQuotation(ctx context.Context, arg DeclineQuotationParams) (Quotation, error)
+	DeleteClient(ctx context.Context, id uuid.UUID) error
+	DeleteContract(ctx context.Context, id uuid.UUID) error
+	DeleteEmailSuppression(ctx context.Context, arg DeleteEmailSuppressionParams) (int64, error)
+	// Removes every version of an agency's template, going back to the
+	// built-in one
+	DeleteEmailTemplates(ctx context.Context, arg DeleteEmailTemplatesParams) (int64, error)
+	DeleteExpiredPasskeyChallenges(ctx context.Context) error
+	DeleteExpiredUsedSessionTokens(ctx context.Context) error
 	DeleteFile(ctx context.Context, id uuid.UUID) error
+	DeleteInvoice(ctx context.Context, id uuid.UUID) error
+	DeleteInvoiceLineItem(ctx context.Context, id uuid.UUID) error
 	DeleteNote(ctx context.Context, id uuid.UUID) error
+	DeleteProposal(ctx context.Context, id uuid.UUID) error
+	DeleteQuotation(ctx context.Context, id uuid.UUID) error
+	DeleteQuotationScopeSections(ctx context.Context, quotationID uuid.UUID) error
 	DeleteTokens(ctx context.Context) error
+	DeleteUserPasskey(ctx context.Context, arg DeleteUserPasskeyParams) (int64, error)
+	DeleteUserRecoveryCodes(ctx context.Context, userID uuid.UUID) error
+	DeleteUserTOTP(ctx context.Context, userID uuid.UUID) error
+	DocumentNumberExists(ctx context.Context, arg DocumentNumberExistsParams) (bool, error)
 	DowngradeAgencyToFree(ctx context.Context, id uuid.UUID) error
+	EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) (UserTotp, error)
+	// Quotations still open after their expiry date are marked expired
+	ExpireQuotations(ctx context.Context, before time.Time) (int64, error)
 	// =============================================================================
 	// Agency Billing Queries (Platform Subscriptions)
 	// =============================================================================
 	GetAgencyBillingInfo(ctx context.Context, id uuid.UUID) (GetAgencyBillingInfoRow, error)
 	GetAgencyByStripeCustomer(ctx context.Context, stripeCustomerID string) (Agency, error)
+	// =============================================================================
+	// API Key Queries
+	// =============================================================================
+	InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (ApiKey, error)
+	// =============================================================================
+	// Agency Activity Log Queries
+	// =============================================================================
+	InsertActivityLog(ctx context.Context, arg InsertActivityLogParams) error
+	InsertAgencyFormVersion(ctx context.Context, arg InsertAgencyFormVersionParams) (AgencyFormVersion, error)
+	InsertClient(ctx context.Context, arg InsertClientParams) (Client, error)
+	InsertConsultation(ctx context.Context, arg InsertConsultationParams) (Consultation, error)
+	InsertConsultationVersion(ctx context.Context, arg InsertConsultationVersionParams) (ConsultationVersion, error)
+	InsertContract(ctx context.Context, arg InsertContractParams) (Contract, error)
+	InsertContractSignature(ctx context.Context, arg InsertContractSignatureParams) (ContractSignature, error)
+	InsertDocumentNumbering(ctx context.Context, arg InsertDocumentNumberingParams) error
 	InsertEmail(ctx context.Context, arg InsertEmailParams) (Email, error)
 	InsertEmailAttachment(ctx context.Context, arg InsertEmailAttachmentParams) (EmailAttachment, error)
+	// Adds the next version of an agency's template
+	InsertEmailTemplate(ctx context.Context, arg InsertEmailTemplateParams) (EmailTemplate, error)
 	InsertFile(ctx context.Context, arg InsertFileParams) (File, error)
+	InsertInvoice(ctx context.Context, arg InsertInvoiceParams) (Invoice, error)
+	InsertInvoiceLineItem(ctx context.Context, arg InsertInvoiceLineItemParams) (InvoiceLineItem, error)
 	InsertNote(ctx context.Context, arg InsertNoteParams) (Note, error)
+	// =============================================================================
+	// Passkey Queries
+	// =============================================================================
+	InsertPasskey(ctx context.Context, arg InsertPasskeyParams) (Passkey, error)
+	InsertPasskeyChallenge(ctx context.Context, arg InsertPasskeyChallengeParams) error
+	InsertProposal(ctx context.Context, arg InsertProposalParams) (Proposal, error)
+	InsertQuotation(ctx context.Context, arg InsertQuotationParams) (Quotation, error)
+	InsertQuotationScopeSection(ctx context.Context, arg InsertQuotationScopeSectionParams) (QuotationScopeSection, error)
+	// Inserts a refresh token in a session's family of tokens
+	InsertSessionToken(ctx context.Context, arg InsertSessionTokenParams) (Token, error)
 	InsertToken(ctx context.Context, arg InsertTokenParams) (Token, error)
+	// Records a session token as used. No row is inserted for a token used
+	// before.
+	InsertUsedSessionToken(ctx context.Context, arg InsertUsedSessionTokenParams) (int64, error)
 	InsertUser(ctx context.Context, arg InsertUserParams) (User, error)
+	InsertUserRecoveryCode(ctx context.Context, arg InsertUserRecoveryCodeParams) error
+	// =============================================================================
+	// Document Numbering Queries
+	// =============================================================================
+	LockAgencyNumbering(ctx context.Context, agencyID uuid.UUID) (LockAgencyNumberingRow, error)
+	ReassignConsultationsClient(ctx context.Context, arg ReassignConsultationsClientParams) (int64, error)
+	ReassignContractsClient(ctx context.Context, arg ReassignContractsClientParams) (int64, error)
+	ReassignFormSubmissionsClient(ctx context.Context, arg ReassignFormSubmissionsClientParams) (int64, error)
+	ReassignInvoicesClient(ctx context.Context, arg ReassignInvoicesClientParams) (int64, error)
+	ReassignProposalsClient(ctx context.Context, arg ReassignProposalsClientParams) (int64, error)
+	ReassignQuotationsClient(ctx context.Context, arg ReassignQuotationsClientParams) (int64, error)
+	RecordContractView(ctx context.Context, id uuid.UUID) (Contract, error)
+	RecordInvoicePayment(ctx context.Context, arg RecordInvoicePaymentParams) (Invoice, error)
+	RecordInvoiceView(ctx context.Context, id uuid.UUID) (Invoice, error)
+	RecordProposalView(ctx context.Context, id uuid.UUID) (Proposal, error)
+	RecordQuotationView(ctx context.Context, id uuid.UUID) (Quotation, error)
+	// Counts an invalid code. The user's codes are locked until locked_until
+	// once max_attempts have been invalid in a row, and the count starts over.
+	RecordUserTOTPFailure(ctx context.Context, arg RecordUserTOTPFailureParams) error
+	ResetUserTOTPFailures(ctx context.Context, userID uuid.UUID) error
+	// Queues a failed email to be sent again, with a fresh set of retries
+	RetryEmail(ctx context.Context, arg RetryEmailParams) (Email, error)
+	RevokeAgencyAPIKey(ctx context.Context, arg RevokeAgencyAPIKeyParams) (ApiKey, error)
+	RevokeTokenFamily(ctx context.Context, family string) error
+	RevokeUserAPIKey(ctx context.Context, arg RevokeUserAPIKeyParams) (ApiKey, error)
+	RevokeUserSession(ctx context.Context, arg RevokeUserSessionParams) (int64, error)
+	// Revokes all of a user's sessions other than the one kept, if any
+	RevokeUserSessions(ctx context.Context, arg RevokeUserSessionsParams) error
+	// Marks a refresh token as exchanged. Only one exchange of a token succeeds.
+	RotateToken(ctx context.Context, id string) (Token, error)
+	// The key with the user it acts as
+	SelectAPIKeyByHash(ctx context.Context, keyHash string) (SelectAPIKeyByHashRow, error)
+	// =============================================================================
+	// Document Queries
+	// =============================================================================
+	SelectAgency(ctx context.Context, id uuid.UUID) (Agency, error)
+	SelectAgencyAPIKeys(ctx context.Context, agencyID uuid.UUID) ([]ApiKey, error)
+	SelectAgencyAddonsByIDs(ctx context.Context, arg SelectAgencyAddonsByIDsParams) ([]AgencyAddon, error)
+	SelectAgencyClients(ctx context.Context, agencyID uuid.UUID) ([]Client, error)
+	SelectAgencyDocumentBranding(ctx context.Context, arg SelectAgencyDocumentBrandingParams) (AgencyDocumentBranding, error)
+	// =============================================================================
+	// Form Queries
+	// =============================================================================
+	SelectAgencyForm(ctx context.Context, id uuid.UUID) (AgencyForm, error)
+	SelectAgencyFormForUpdate(ctx context.Context, id uuid.UUID) (AgencyForm, error)
+	SelectAgencyFormVersion(ctx context.Context, arg SelectAgencyFormVersionParams) (AgencyFormVersion, error)
+	SelectAgencyFormVersions(ctx context.Context, formID uuid.UUID) ([]AgencyFormVersion, error)
+	// =============================================================================
+	// Agency Membership Queries
+	// =============================================================================
+	// A membership counts once it is active in an active agency. Invited
+	// members must also have accepted; memberships created directly, such as
+	// the founding owner's, need no acceptance.
+	SelectAgencyMembership(ctx context.Context, arg SelectAgencyMembershipParams) (AgencyMembership, error)
+	SelectAgencyNumbering(ctx context.Context, agencyID uuid.UUID) (SelectAgencyNumberingRow, error)
+	SelectAgencyOwnerID(ctx context.Context, agencyID uuid.UUID) (uuid.UUID, error)
+	SelectAgencyPackage(ctx context.Context, id uuid.UUID) (AgencyPackage, error)
+	// =============================================================================
+	// Agency Package & Pricing Queries
+	// =============================================================================
+	SelectAgencyProfile(ctx context.Context, agencyID uuid.UUID) (AgencyProfile, error)
+	SelectClient(ctx context.Context, id uuid.UUID) (Client, error)
+	SelectClientByEmail(ctx context.Context, arg SelectClientByEmailParams) (Client, error)
+	SelectClients(ctx context.Context, arg SelectClientsParams) ([]Client, error)
+	// =============================================================================
+	// Consultation Queries
+	// =============================================================================
+	SelectConsultation(ctx context.Context, id uuid.UUID) (Consultation, error)
+	SelectConsultationForUpdate(ctx context.Context, id uuid.UUID) (Consultation, error)
+	SelectConsultationVersion(ctx context.Context, arg SelectConsultationVersionParams) (ConsultationVersion, error)
+	SelectConsultationVersions(ctx context.Context, consultationID uuid.UUID) ([]ConsultationVersion, error)
+	SelectConsultations(ctx context.Context, arg SelectConsultationsParams) ([]Consultation, error)
+	SelectContract(ctx context.Context, id uuid.UUID) (Contract, error)
+	SelectContractBySlug(ctx context.Context, slug string) (Contract, error)
+	SelectContractSchedules(ctx context.Context, templateID uuid.UUID) ([]ContractSchedule, error)
+	SelectContractSignatures(ctx context.Context, contractID uuid.UUID) ([]ContractSignature, error)
+	SelectContractTemplate(ctx context.Context, id uuid.UUID) (ContractTemplate, error)
+	SelectContracts(ctx context.Context, arg SelectContractsParams) ([]Contract, error)
+	// The agency a user works in by default: their default agency, else the
+	// one where they hold the highest role
+	SelectDefaultAgencyMembership(ctx context.Context, userID uuid.UUID) (AgencyMembership, error)
+	SelectDefaultContractTemplate(ctx context.Context, agencyID uuid.UUID) (ContractTemplate, error)
+	SelectDocumentNumbering(ctx context.Context, arg SelectDocumentNumberingParams) (AgencyDocumentNumbering, error)
+	SelectDocumentNumberings(ctx context.Context, agencyID uuid.UUID) ([]AgencyDocumentNumbering, error)
+	SelectDocumentNumbers(ctx context.Context, arg SelectDocumentNumbersParams) ([]string, error)
+	SelectDraftFormSubmissions(ctx context.Context, formID uuid.UUID) ([]FormSubmission, error)
 	SelectEmailAttachments(ctx context.Context, emailID uuid.UUID) ([]EmailAttachment, error)
+	SelectEmailByUnsubscribeToken(ctx context.Context, unsubscribeToken sql.NullString) (Email, error)
+	SelectEmailLogAgencyID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	// =============================================================================
+	// Email Suppression Queries
+	// =============================================================================
+	SelectEmailSuppression(ctx context.Context, arg SelectEmailSuppressionParams) (EmailSuppression, error)
+	SelectEmailSuppressions(ctx context.Context, agencyID uuid.UUID) ([]EmailSuppression, error)
+	// =============================================================================
+	// Email Template Queries
+	// =============================================================================
+	// Returns the latest version of an agency's template
+	SelectEmailTemplate(ctx context.Context, arg SelectEmailTemplateParams) (EmailTemplate, error)
+	SelectEmailTemplateVersions(ctx context.Context, arg SelectEmailTemplateVersionsParams) ([]EmailTemplate, error)
+	// Returns the latest version of each of an agency's templates
+	SelectEmailTemplates(ctx context.Context, agencyID uuid.UUID) ([]EmailTemplate, error)
 	SelectEmails(ctx context.Context, userID uuid.UUID) ([]Email, error)
+	SelectEmailsByStatus(ctx context.Context, arg SelectEmailsByStatusParams) ([]Email, error)
+	// System option sets first so an agency's own set replaces one with the
+	// same slug
+	SelectFieldOptionSets(ctx context.Context, agencyID uuid.UUID) ([]FieldOptionSet, error)
 	SelectFile(ctx context.Context, id uuid.UUID) (File, error)
 	SelectFiles(ctx context.Context, userID uuid.UUID) ([]File, error)
+	SelectFormSubmission(ctx context.Context, id uuid.UUID) (FormSubmission, error)
+	SelectFormSubmissionForUpdate(ctx context.Context, id uuid.UUID) (FormSubmission, error)
+	SelectInvoice(ctx context.Context, id uuid.UUID) (Invoice, error)
+	SelectInvoiceBySlug(ctx context.Context, slug string) (Invoice, error)
+	SelectInvoiceLineItem(ctx context.Context, id uuid.UUID) (InvoiceLineItem, error)
+	SelectInvoiceLineItems(ctx context.Context, invoiceID uuid.UUID) ([]InvoiceLineItem, error)
+	SelectInvoices(ctx context.Context, arg SelectInvoicesParams) ([]Invoice, error)
+	SelectLatestConsultationVersionNumber(ctx context.Context, consultationID uuid.UUID) (int32, error)
 	SelectNote(ctx context.Context, id uuid.UUID) (Note, error)
 	SelectNotes(ctx context.Context, arg SelectNotesParams) ([]Note, error)
+	SelectPasskeyByCredentialID(ctx context.Context, credentialID string) (Passkey, error)
+	SelectProposal(ctx context.Context, id uuid.UUID) (Proposal, error)
+	SelectProposalBySlug(ctx context.Context, slug string) (Proposal, error)
+	SelectProposals(ctx context.Context, arg SelectProposalsParams) ([]Proposal, error)
+	SelectQuotation(ctx context.Context, id uuid.UUID) (Quotation, error)
+	SelectQuotationBySlug(ctx context.Context, slug string) (Quotation, error)
+	SelectQuotationScopeSections(ctx context.Context, quotationID uuid.UUID) ([]QuotationScopeSection, error)
+	SelectQuotationTemplate(ctx context.Context, id uuid.UUID) (QuotationTemplate, error)
+	SelectQuotationTemplateSections(ctx context.Context, templateID uuid.UUID) ([]SelectQuotationTemplateSectionsRow, error)
+	SelectQuotationTemplateTerms(ctx context.Context, templateID uuid.UUID) ([]SelectQuotationTemplateTermsRow, error)
+	SelectQuotations(ctx context.Context, arg SelectQuotationsParams) ([]Quotation, error)
+	// A session is active while any token of its family is unrevoked and unexpired
+	SelectSessionActive(ctx context.Context, family string) (bool, error)
 	SelectToken(ctx context.Context, id string) (Token, error)
+	// Completed submissions waiting to be processed, skipping any that have
+	// failed too often to retry automatically
+	SelectUnprocessedFormSubmissions(ctx context.Context, arg SelectUnprocessedFormSubmissionsParams) ([]FormSubmission, error)
 	SelectUser(ctx context.Context, id uuid.UUID) (User, error)
+	// A user's personal keys; agency keys are listed with their agency
+	SelectUserAPIKeys(ctx context.Context, userID uuid.UUID) ([]ApiKey, error)
 	SelectUserByCustomerID(ctx context.Context, customerID string) (User, error)
 	SelectUserByEmail(ctx context.Context, email string) (User, error)
 	SelectUserByEmailAndSub(ctx context.Context, arg SelectUserByEmailAndSubParams) (User, error)
+	SelectUserPasskeys(ctx context.Context, userID uuid.UUID) ([]Passkey, error)
+	// Returns the current token of each of a user's active sessions, with when
+	// the session was started
+	SelectUserSessions(ctx context.Context, userID string) ([]SelectUserSessionsRow, error)
+	// =============================================================================
+	// Two-Factor Queries
+	// =============================================================================
+	SelectUserTOTP(ctx context.Context, userID uuid.UUID) (UserTotp, error)
 	SelectUsers(ctx context.Context) ([]User, error)
+	SetNextDocumentNumber(ctx context.Context, arg SetNextDocumentNumberParams) error
+	SignContractAsAgency(ctx context.Context, arg SignContractAsAgencyParams) (Contract, error)
+	SignContractAsClient(ctx context.Context, arg SignContractAsClientParams) (Contract, error)
+	// Usage is recorded at most once a minute so busy keys do not write on
+	// every request
+	UpdateAPIKeyLastUsed(ctx context.Context, id uuid.UUID) error
+	UpdateAgencyFormMapping(ctx context.Context, arg UpdateAgencyFormMappingParams) (AgencyForm, error)
+	UpdateAgencyFormSchema(ctx context.Context, arg UpdateAgencyFormSchemaParams) (AgencyForm, error)
 	UpdateAgencyStripeCustomer(ctx context.Context, arg UpdateAgencyStripeCustomerParams) error
 	UpdateAgencySubscription(ctx context.Context, arg UpdateAgencySubscriptionParams) error
+	UpdateClient(ctx context.Context, arg UpdateClientParams) (Client, error)
+	UpdateClientStatus(ctx context.Context, arg UpdateClientStatusParams) (Client, error)
+	UpdateConsultation(ctx context.Context, arg UpdateConsultationParams) (Consultation, error)
+	// Content is frozen once a contract is sent or signed, and an edit only
+	// applies to the version it was made against
+	UpdateContract(ctx context.Context, arg UpdateContractParams) (Contract, error)
+	UpdateContractPdf(ctx context.Context, arg UpdateContractPdfParams) error
+	UpdateContractStatus(ctx context.Context, arg UpdateContractStatusParams) (Contract, error)
+	UpdateDocumentSequenceYear(ctx context.Context, arg UpdateDocumentSequenceYearParams) error
+	// Records a failed attempt. The email is retried at next_attempt_at while
+	// its status is pending. Like UpdateEmailSent, it only applies under the
+	// caller's lease.
+	UpdateEmailFailed(ctx context.Context, arg UpdateEmailFailedParams) (Email, error)
+	// Mirrors an outbox email's delivery onto the email log it was sent for
+	UpdateEmailLogDelivery(ctx context.Context, arg UpdateEmailLogDeliveryParams) error
+	// Records an event a provider reported for the emails it sent with a
+	// message ID, returning their agencies and recipients. Events can arrive
+	// out of order, so a status only moves forward: delivered, then opened. A
+	// bounce or a complaint overrides them, and a complaint is never replaced.
+	UpdateEmailLogEvent(ctx context.Context, arg UpdateEmailLogEventParams) ([]UpdateEmailLogEventRow, error)
+	// Only applies while the caller still holds the lease it claimed the email
+	// with, so a worker whose lease ran out cannot overwrite the outcome of the
+	// worker that took the email over
+	UpdateEmailSent(ctx context.Context, arg UpdateEmailSentParams) (Email, error)
+	UpdateFormSubmissionFailed(ctx context.Context, arg UpdateFormSubmissionFailedParams) error
+	UpdateFormSubmissionProcessed(ctx context.Context, arg UpdateFormSubmissionProcessedParams) (FormSubmission, error)
+	UpdateFormSubmissionVersion(ctx context.Context, arg UpdateFormSubmissionVersionParams) error
+	UpdateInvoice(ctx context.Context, arg UpdateInvoiceParams) (Invoice, error)
+	UpdateInvoiceLineItem(ctx context.Context, arg UpdateInvoiceLineItemParams) (InvoiceLineItem, error)
+	UpdateInvoicePdf(ctx context.Context, arg UpdateInvoicePdfParams) error
+	UpdateInvoiceStatus(ctx context.Context, arg UpdateInvoiceStatusParams) (Invoice, error)
+	UpdateInvoiceTotals(ctx context.Context, arg UpdateInvoiceTotalsParams) (Invoice, error)
 	UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, error)
+	UpdatePasskeyUsed(ctx context.Context, arg UpdatePasskeyUsedParams) (int64, error)
+	// The status guard mirrors proposal.Status.IsEditable, so a proposal frozen
+	// after it was read is not changed.
+	UpdateProposal(ctx context.Context, arg UpdateProposalParams) (Proposal, error)
+	UpdateProposalNextSteps(ctx context.Context, arg UpdateProposalNextStepsParams) (Proposal, error)
+	UpdateProposalOpportunity(ctx context.Context, arg UpdateProposalOpportunityParams) (Proposal, error)
+	UpdateProposalProposedPages(ctx context.Context, arg UpdateProposalProposedPagesParams) (Proposal, error)
+	UpdateProposalRoiAnalysis(ctx context.Context, arg UpdateProposalRoiAnalysisParams) (Proposal, error)
+	UpdateProposalStatus(ctx context.Context, arg UpdateProposalStatusParams) (Proposal, error)
+	UpdateProposalTimeline(ctx context.Context, arg UpdateProposalTimelineParams) (Proposal, error)
+	UpdateQuotation(ctx context.Context, arg UpdateQuotationParams) (Quotation, error)
+	UpdateQuotationStatus(ctx context.Context, arg UpdateQuotationStatusParams) (Quotation, error)
+	UpdateQuotationTotals(ctx context.Context, arg UpdateQuotationTotalsParams) (Quotation, error)
 	UpdateToken(ctx context.Context, arg UpdateTokenParams) error
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateUserAccess(ctx context.Context, arg UpdateUserAccessParams) (User, error)
@@ -51,6 +331,17 @@ type Querier interface {
 	UpdateUserPhone(ctx context.Context, arg UpdateUserPhoneParams) error
 	UpdateUserSub(ctx context.Context, arg UpdateUserSubParams) error
 	UpdateUserSubscription(ctx context.Context, arg UpdateUserSubscriptionParams) error
+	UpdateUserTOTPSecret(ctx context.Context, arg UpdateUserTOTPSecretParams) error
+	// Records an accepted code. Only a later time step than the last accepted
+	// one is recorded, so each code is accepted once.
+	UpdateUserTOTPStep(ctx context.Context, arg UpdateUserTOTPStepParams) (int64, error)
+	UpsertDocumentNumbering(ctx context.Context, arg UpsertDocumentNumberingParams) (AgencyDocumentNumbering, error)
+	// Suppresses an address, replacing the reason of one already suppressed
+	UpsertEmailSuppression(ctx context.Context, arg UpsertEmailSuppressionParams) (EmailSuppression, error)
+	// Starts enrolment with a new secret. An enabled secret is never replaced:
+	// no row is returned for it.
+	UpsertPendingUserTOTP(ctx context.Context, arg UpsertPendingUserTOTPParams) (UserTotp, error)
+	UseUserRecoveryCode(ctx context.Context, arg UseUserRecoveryCodeParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -8,9 +8,13 @@ package query
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
+	"app/pkg/money"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sqlc-dev/pqtype"
 )
 
 const acceptPendingMemberships = `-- name: AcceptPendingMemberships :exec
@@ -22,6 +26,336 @@ func (q *Queries) AcceptPendingMemberships(ctx context.Context, userID uuid.UUID
 	return err
 }
 
+const acceptQuotation = `-- name: AcceptQuotation :one
+UPDATE quotations
+SET
+    status = 'accepted',
+    accepted_by_name = $1,
+    accepted_by_title = $2,
+    accepted_at = $3,
+    acceptance_ip = $4,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $5 AND status IN ('sent', 'viewed')
+RETURNING id, created_at, updated_at, agency_id, client_id, template_id, quotation_number, slug, quotation_name, status, client_business_name, client_contact_name, client_email, client_phone, client_address, site_address, site_reference, prepared_date, expiry_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, terms_blocks, options_notes, notes, view_count, last_viewed_at, sent_at, declined_at, decline_reason, accepted_by_name, accepted_by_title, accepted_at, acceptance_ip, created_by
+`
+
+type AcceptQuotationParams struct {
+	AcceptedByName  sql.NullString `json:"accepted_by_name"`
+	AcceptedByTitle sql.NullString `json:"accepted_by_title"`
+	AcceptedAt      sql.NullTime   `json:"accepted_at"`
+	AcceptanceIp    sql.NullString `json:"acceptance_ip"`
+	ID              uuid.UUID      `json:"id"`
+}
+
+func (q *Queries) AcceptQuotation(ctx context.Context, arg AcceptQuotationParams) (Quotation, error) {
+	row := q.db.QueryRowContext(ctx, acceptQuotation,
+		arg.AcceptedByName,
+		arg.AcceptedByTitle,
+		arg.AcceptedAt,
+		arg.AcceptanceIp,
+		arg.ID,
+	)
+	var i Quotation
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ClientID,
+		&i.TemplateID,
+		&i.QuotationNumber,
+		&i.Slug,
+		&i.QuotationName,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.SiteAddress,
+		&i.SiteReference,
+		&i.PreparedDate,
+		&i.ExpiryDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.TermsBlocks,
+		&i.OptionsNotes,
+		&i.Notes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.DeclinedAt,
+		&i.DeclineReason,
+		&i.AcceptedByName,
+		&i.AcceptedByTitle,
+		&i.AcceptedAt,
+		&i.AcceptanceIp,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const claimEmails = `-- name: ClaimEmails :many
+update emails set locked_until = $1, updated = current_timestamp
+where id in (
+    select id from emails
+    where status = 'pending'
+      and next_attempt_at <= current_timestamp
+      and (locked_until is null or locked_until < current_timestamp)
+    order by next_attempt_at
+    limit $2
+    for update skip locked
+)
+returning id, created, updated, user_id, email_to, email_from, email_subject, email_body, status, retry_count, error_message, next_attempt_at, locked_until, sent_at, email_log_id, provider_message_id, agency_id, unsubscribe_token
+`
+
+type ClaimEmailsParams struct {
+	LockedUntil sql.NullTime `json:"locked_until"`
+	RowLimit    int32        `json:"row_limit"`
+}
+
+// Takes the emails that are due for sending, and those whose worker's lease
+// has run out, leasing them to the caller. Emails claimed by another
+// worker are skipped.
+func (q *Queries) ClaimEmails(ctx context.Context, arg ClaimEmailsParams) ([]Email, error) {
+	rows, err := q.db.QueryContext(ctx, claimEmails, arg.LockedUntil, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Email
+	for rows.Next() {
+		var i Email
+		if err := rows.Scan(
+			&i.ID,
+			&i.Created,
+			&i.Updated,
+			&i.UserID,
+			&i.EmailTo,
+			&i.EmailFrom,
+			&i.EmailSubject,
+			&i.EmailBody,
+			&i.Status,
+			&i.RetryCount,
+			&i.ErrorMessage,
+			&i.NextAttemptAt,
+			&i.LockedUntil,
+			&i.SentAt,
+			&i.EmailLogID,
+			&i.ProviderMessageID,
+			&i.AgencyID,
+			&i.UnsubscribeToken,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const completeFormSubmission = `-- name: CompleteFormSubmission :one
+UPDATE form_submissions
+SET data = $1,
+    status = 'completed',
+    current_step = $2,
+    completion_percentage = 100,
+    submitted_at = CURRENT_TIMESTAMP,
+    last_activity_at = CURRENT_TIMESTAMP
+WHERE id = $3 AND status = 'draft'
+RETURNING id, form_id, agency_id, slug, client_id, client_business_name, client_email, data, current_step, completion_percentage, started_at, last_activity_at, consultation_id, proposal_id, contract_id, metadata, status, created_at, submitted_at, processed_at, form_version, processing_attempts, processing_error
+`
+
+type CompleteFormSubmissionParams struct {
+	Data        json.RawMessage `json:"data"`
+	CurrentStep int32           `json:"current_step"`
+	ID          uuid.UUID       `json:"id"`
+}
+
+func (q *Queries) CompleteFormSubmission(ctx context.Context, arg CompleteFormSubmissionParams) (FormSubmission, error) {
+	row := q.db.QueryRowContext(ctx, completeFormSubmission, arg.Data, arg.CurrentStep, arg.ID)
+	var i FormSubmission
+	err := row.Scan(
+		&i.ID,
+		&i.FormID,
+		&i.AgencyID,
+		&i.Slug,
+		&i.ClientID,
+		&i.ClientBusinessName,
+		&i.ClientEmail,
+		&i.Data,
+		&i.CurrentStep,
+		&i.CompletionPercentage,
+		&i.StartedAt,
+		&i.LastActivityAt,
+		&i.ConsultationID,
+		&i.ProposalID,
+		&i.ContractID,
+		&i.Metadata,
+		&i.Status,
+		&i.CreatedAt,
+		&i.SubmittedAt,
+		&i.ProcessedAt,
+		&i.FormVersion,
+		&i.ProcessingAttempts,
+		&i.ProcessingError,
+	)
+	return i, err
+}
+
+const consumePasskeyChallenge = `-- name: ConsumePasskeyChallenge :one
+DELETE FROM passkey_challenges WHERE id = $1 RETURNING id, created_at, expires_at, purpose, user_id, return_url
+`
+
+// Deletes a challenge and returns it, so each can only be answered once
+func (q *Queries) ConsumePasskeyChallenge(ctx context.Context, id string) (PasskeyChallenge, error) {
+	row := q.db.QueryRowContext(ctx, consumePasskeyChallenge, id)
+	var i PasskeyChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.Purpose,
+		&i.UserID,
+		&i.ReturnUrl,
+	)
+	return i, err
+}
+
+const countClientDocuments = `-- name: CountClientDocuments :one
+SELECT
+    (SELECT count(*) FROM consultations c WHERE c.client_id = $1::uuid) AS consultations,
+    (SELECT count(*) FROM proposals p WHERE p.client_id = $1::uuid) AS proposals,
+    (SELECT count(*) FROM contracts ct WHERE ct.client_id = $1::uuid) AS contracts,
+    (SELECT count(*) FROM invoices i WHERE i.client_id = $1::uuid) AS invoices,
+    (SELECT count(*) FROM quotations q WHERE q.client_id = $1::uuid) AS quotations,
+    (SELECT count(*) FROM form_submissions fs WHERE fs.client_id = $1::uuid) AS form_submissions
+`
+
+type CountClientDocumentsRow struct {
+	Consultations   int64 `json:"consultations"`
+	Proposals       int64 `json:"proposals"`
+	Contracts       int64 `json:"contracts"`
+	Invoices        int64 `json:"invoices"`
+	Quotations      int64 `json:"quotations"`
+	FormSubmissions int64 `json:"form_submissions"`
+}
+
+func (q *Queries) CountClientDocuments(ctx context.Context, clientID uuid.UUID) (CountClientDocumentsRow, error) {
+	row := q.db.QueryRowContext(ctx, countClientDocuments, clientID)
+	var i CountClientDocumentsRow
+	err := row.Scan(
+		&i.Consultations,
+		&i.Proposals,
+		&i.Contracts,
+		&i.Invoices,
+		&i.Quotations,
+		&i.FormSubmissions,
+	)
+	return i, err
+}
+
+const countClients = `-- name: CountClients :one
+
+SELECT count(*) FROM clients
+WHERE agency_id = $1
+  AND ($2::text = '' OR status = $2::text)
+  AND (
+    $3::text = ''
+    OR business_name ILIKE '%' || $3::text || '%'
+    OR email ILIKE '%' || $3::text || '%'
+    OR contact_name ILIKE '%' || $3::text || '%'
+  )
+`
+
+type CountClientsParams struct {
+	AgencyID uuid.UUID `json:"agency_id"`
+	Status   string    `json:"status"`
+	Search   string    `json:"search"`
+}
+
+// =============================================================================
+// Client Queries
+// =============================================================================
+func (q *Queries) CountClients(ctx context.Context, arg CountClientsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countClients, arg.AgencyID, arg.Status, arg.Search)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countConsultations = `-- name: CountConsultations :one
+SELECT count(*) FROM consultations
+WHERE agency_id = $1
+  AND ($2::text = '' OR status = $2::text)
+`
+
+type CountConsultationsParams struct {
+	AgencyID uuid.UUID `json:"agency_id"`
+	Status   string    `json:"status"`
+}
+
+func (q *Queries) CountConsultations(ctx context.Context, arg CountConsultationsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countConsultations, arg.AgencyID, arg.Status)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countContracts = `-- name: CountContracts :one
+
+SELECT count(*) FROM contracts
+WHERE agency_id = $1
+  AND ($2::text = '' OR status = $2::text)
+`
+
+type CountContractsParams struct {
+	AgencyID uuid.UUID `json:"agency_id"`
+	Status   string    `json:"status"`
+}
+
+// =============================================================================
+// Contract Queries
+// =============================================================================
+func (q *Queries) CountContracts(ctx context.Context, arg CountContractsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countContracts, arg.AgencyID, arg.Status)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countInvoices = `-- name: CountInvoices :one
+
+SELECT count(*) FROM invoices
+WHERE agency_id = $1
+  AND ($2::text = '' OR status = $2::text)
+`
+
+type CountInvoicesParams struct {
+	AgencyID uuid.UUID `json:"agency_id"`
+	Status   string    `json:"status"`
+}
+
+// =============================================================================
+// Invoice Queries
+// =============================================================================
+func (q *Queries) CountInvoices(ctx context.Context, arg CountInvoicesParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countInvoices, arg.AgencyID, arg.Status)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countNotes = `-- name: CountNotes :one
 select count(*) from notes where user_id = $1
 `
@@ -33,6 +367,211 @@ func (q *Queries) CountNotes(ctx context.Context, userID uuid.UUID) (int64, erro
 	return count, err
 }
 
+const countProposals = `-- name: CountProposals :one
+
+SELECT count(*) FROM proposals
+WHERE agency_id = $1
+  AND ($2::text = '' OR status = $2::text)
+`
+
+type CountProposalsParams struct {
+	AgencyID uuid.UUID `json:"agency_id"`
+	Status   string    `json:"status"`
+}
+
+// =============================================================================
+// Proposal Queries
+// =============================================================================
+func (q *Queries) CountProposals(ctx context.Context, arg CountProposalsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countProposals, arg.AgencyID, arg.Status)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countQuotations = `-- name: CountQuotations :one
+
+SELECT count(*) FROM quotations
+WHERE agency_id = $1
+  AND ($2::text = '' OR status = $2::text)
+`
+
+type CountQuotationsParams struct {
+	AgencyID uuid.UUID `json:"agency_id"`
+	Status   string    `json:"status"`
+}
+
+// =============================================================================
+// Quotation Queries
+// =============================================================================
+func (q *Queries) CountQuotations(ctx context.Context, arg CountQuotationsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countQuotations, arg.AgencyID, arg.Status)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserPasskeys = `-- name: CountUserPasskeys :one
+SELECT COUNT(*) FROM passkeys WHERE user_id = $1
+`
+
+func (q *Queries) CountUserPasskeys(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUserPasskeys, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserRecoveryCodes = `-- name: CountUserRecoveryCodes :one
+SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL
+`
+
+// Counts the recovery codes a user has left
+func (q *Queries) CountUserRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUserRecoveryCodes, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const declineQuotation = `-- name: DeclineQuotation :one
+UPDATE quotations
+SET
+    status = 'declined',
+    declined_at = $1,
+    decline_reason = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $3 AND status IN ('sent', 'viewed')
+RETURNING id, created_at, updated_at, agency_id, client_id, template_id, quotation_number, slug, quotation_name, status, client_business_name, client_contact_name, client_email, client_phone, client_address, site_address, site_reference, prepared_date, expiry_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, terms_blocks, options_notes, notes, view_count, last_viewed_at, sent_at, declined_at, decline_reason, accepted_by_name, accepted_by_title, accepted_at, acceptance_ip, created_by
+`
+
+type DeclineQuotationParams struct {
+	DeclinedAt    sql.NullTime `json:"declined_at"`
+	DeclineReason string       `json:"decline_reason"`
+	ID            uuid.UUID    `json:"id"`
+}
+
+func (q *Queries) DeclineQuotation(ctx context.Context, arg DeclineQuotationParams) (Quotation, error) {
+	row := q.db.QueryRowContext(ctx, declineQuotation, arg.DeclinedAt, arg.DeclineReason, arg.ID)
+	var i Quotation
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ClientID,
+		&i.TemplateID,
+		&i.QuotationNumber,
+		&i.Slug,
+		&i.QuotationName,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.SiteAddress,
+		&i.SiteReference,
+		&i.PreparedDate,
+		&i.ExpiryDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.TermsBlocks,
+		&i.OptionsNotes,
+		&i.Notes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.DeclinedAt,
+		&i.DeclineReason,
+		&i.AcceptedByName,
+		&i.AcceptedByTitle,
+		&i.AcceptedAt,
+		&i.AcceptanceIp,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const deleteClient = `-- name: DeleteClient :exec
+DELETE FROM clients
+WHERE id = $1
+`
+
+func (q *Queries) DeleteClient(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteClient, id)
+	return err
+}
+
+const deleteContract = `-- name: DeleteContract :exec
+DELETE FROM contracts
+WHERE id = $1
+`
+
+func (q *Queries) DeleteContract(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteContract, id)
+	return err
+}
+
+const deleteEmailSuppression = `-- name: DeleteEmailSuppression :execrows
+DELETE FROM email_suppressions WHERE id = $1 AND agency_id = $2
+`
+
+type DeleteEmailSuppressionParams struct {
+	ID       uuid.UUID `json:"id"`
+	AgencyID uuid.UUID `json:"agency_id"`
+}
+
+func (q *Queries) DeleteEmailSuppression(ctx context.Context, arg DeleteEmailSuppressionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteEmailSuppression, arg.ID, arg.AgencyID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteEmailTemplates = `-- name: DeleteEmailTemplates :execrows
+DELETE FROM email_templates WHERE agency_id = $1 AND name = $2
+`
+
+type DeleteEmailTemplatesParams struct {
+	AgencyID uuid.UUID `json:"agency_id"`
+	Name     string    `json:"name"`
+}
+
+// Removes every version of an agency's template, going back to the
+// built-in one
+func (q *Queries) DeleteEmailTemplates(ctx context.Context, arg DeleteEmailTemplatesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteEmailTemplates, arg.AgencyID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteExpiredPasskeyChallenges = `-- name: DeleteExpiredPasskeyChallenges :exec
+DELETE FROM passkey_challenges WHERE expires_at < CURRENT_TIMESTAMP
+`
+
+func (q *Queries) DeleteExpiredPasskeyChallenges(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredPasskeyChallenges)
+	return err
+}
+
+const deleteExpiredUsedSessionTokens = `-- name: DeleteExpiredUsedSessionTokens :exec
+DELETE FROM used_session_tokens WHERE expires_at < CURRENT_TIMESTAMP
+`
+
+func (q *Queries) DeleteExpiredUsedSessionTokens(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredUsedSessionTokens)
+	return err
+}
+
 const deleteFile = `-- name: DeleteFile :exec
 delete from files where id = $1
 `
@@ -42,6 +581,26 @@ func (q *Queries) DeleteFile(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const deleteInvoice = `-- name: DeleteInvoice :exec
+DELETE FROM invoices
+WHERE id = $1
+`
+
+func (q *Queries) DeleteInvoice(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteInvoice, id)
+	return err
+}
+
+const deleteInvoiceLineItem = `-- name: DeleteInvoiceLineItem :exec
+DELETE FROM invoice_line_items
+WHERE id = $1
+`
+
+func (q *Queries) DeleteInvoiceLineItem(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteInvoiceLineItem, id)
+	return err
+}
+
 const deleteNote = `-- name: DeleteNote :exec
 delete from notes where id = $1
 `
@@ -51,36 +610,171 @@ func (q *Queries) DeleteNote(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
-const deleteTokens = `-- name: DeleteTokens :exec
-delete from tokens where expires < current_timestamp
+const deleteProposal = `-- name: DeleteProposal :exec
+DELETE FROM proposals
+WHERE id = $1
 `
 
-func (q *Queries) DeleteTokens(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, deleteTokens)
+func (q *Queries) DeleteProposal(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteProposal, id)
 	return err
 }
 
-const downgradeAgencyToFree = `-- name: DowngradeAgencyToFree :exec
-UPDATE agencies
-SET
-    subscription_tier = 'free',
-    subscription_id = '',
-    subscription_end = NULL,
-    updated_at = CURRENT_TIMESTAMP
+const deleteQuotation = `-- name: DeleteQuotation :exec
+DELETE FROM quotations
 WHERE id = $1
 `
 
-func (q *Queries) DowngradeAgencyToFree(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, downgradeAgencyToFree, id)
+func (q *Queries) DeleteQuotation(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteQuotation, id)
 	return err
 }
 
-const getAgencyBillingInfo = `-- name: GetAgencyBillingInfo :one
+const deleteQuotationScopeSections = `-- name: DeleteQuotationScopeSections :exec
+DELETE FROM quotation_scope_sections
+WHERE quotation_id = $1
+`
 
-SELECT
-    id,
-    name,
-    slug,
+func (q *Queries) DeleteQuotationScopeSections(ctx context.Context, quotationID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteQuotationScopeSections, quotationID)
+	return err
+}
+
+const deleteTokens = `-- name: DeleteTokens :exec
+delete from tokens where expires < current_timestamp
+`
+
+func (q *Queries) DeleteTokens(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteTokens)
+	return err
+}
+
+const deleteUserPasskey = `-- name: DeleteUserPasskey :execrows
+DELETE FROM passkeys WHERE id = $1 AND user_id = $2
+`
+
+type DeleteUserPasskeyParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteUserPasskey(ctx context.Context, arg DeleteUserPasskeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUserPasskey, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteUserRecoveryCodes = `-- name: DeleteUserRecoveryCodes :exec
+DELETE FROM user_recovery_codes WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserRecoveryCodes(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUserRecoveryCodes, userID)
+	return err
+}
+
+const deleteUserTOTP = `-- name: DeleteUserTOTP :exec
+DELETE FROM user_totp WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserTOTP(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUserTOTP, userID)
+	return err
+}
+
+const documentNumberExists = `-- name: DocumentNumberExists :one
+SELECT EXISTS (
+    SELECT 1 FROM proposals WHERE $1::text = 'proposal' AND proposals.agency_id = $2::uuid AND proposal_number = $3::text
+    UNION ALL
+    SELECT 1 FROM contracts WHERE $1::text = 'contract' AND contracts.agency_id = $2::uuid AND contract_number = $3::text
+    UNION ALL
+    SELECT 1 FROM invoices WHERE $1::text = 'invoice' AND invoices.agency_id = $2::uuid AND invoice_number = $3::text
+    UNION ALL
+    SELECT 1 FROM quotations WHERE $1::text = 'quotation' AND quotations.agency_id = $2::uuid AND quotation_number = $3::text
+)
+`
+
+type DocumentNumberExistsParams struct {
+	DocumentType string    `json:"document_type"`
+	AgencyID     uuid.UUID `json:"agency_id"`
+	Number       string    `json:"number"`
+}
+
+func (q *Queries) DocumentNumberExists(ctx context.Context, arg DocumentNumberExistsParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, documentNumberExists, arg.DocumentType, arg.AgencyID, arg.Number)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const downgradeAgencyToFree = `-- name: DowngradeAgencyToFree :exec
+UPDATE agencies
+SET
+    subscription_tier = 'free',
+    subscription_id = '',
+    subscription_end = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) DowngradeAgencyToFree(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, downgradeAgencyToFree, id)
+	return err
+}
+
+const enableUserTOTP = `-- name: EnableUserTOTP :one
+UPDATE user_totp
+SET enabled_at = CURRENT_TIMESTAMP, last_step = $1, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $2 AND enabled_at IS NULL
+RETURNING user_id, created_at, updated_at, secret, enabled_at, last_step, failed_attempts, locked_until
+`
+
+type EnableUserTOTPParams struct {
+	LastStep int64     `json:"last_step"`
+	UserID   uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) (UserTotp, error) {
+	row := q.db.QueryRowContext(ctx, enableUserTOTP, arg.LastStep, arg.UserID)
+	var i UserTotp
+	err := row.Scan(
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Secret,
+		&i.EnabledAt,
+		&i.LastStep,
+		&i.FailedAttempts,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const expireQuotations = `-- name: ExpireQuotations :execrows
+UPDATE quotations
+SET
+    status = 'expired',
+    updated_at = CURRENT_TIMESTAMP
+WHERE status IN ('sent', 'viewed') AND expiry_date < $1
+`
+
+// Quotations still open after their expiry date are marked expired
+func (q *Queries) ExpireQuotations(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, expireQuotations, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getAgencyBillingInfo = `-- name: GetAgencyBillingInfo :one
+
+SELECT
+    id,
+    name,
+    slug,
     subscription_tier,
     subscription_id,
     subscription_end,
@@ -129,68 +823,6559 @@ func (q *Queries) GetAgencyBillingInfo(ctx context.Context, id uuid.UUID) (GetAg
 	return i, err
 }
 
-const getAgencyByStripeCustomer = `-- name: GetAgencyByStripeCustomer :one
-SELECT id, created_at, updated_at, name, slug, logo_url, logo_avatar_url, primary_color, secondary_color, accent_color, accent_gradient, email, phone, website, status, subscription_tier, subscription_id, subscription_end, stripe_customer_id, ai_generations_this_month, ai_generations_reset_at, is_freemium, freemium_reason, freemium_expires_at, freemium_granted_at, freemium_granted_by, deleted_at, deletion_scheduled_for FROM agencies
-WHERE stripe_customer_id = $1
+const getAgencyByStripeCustomer = `-- name: GetAgencyByStripeCustomer :one
+SELECT id, created_at, updated_at, name, slug, logo_url, logo_avatar_url, primary_color, secondary_color, accent_color, accent_gradient, email, phone, website, status, subscription_tier, subscription_id, subscription_end, stripe_customer_id, ai_generations_this_month, ai_generations_reset_at, is_freemium, freemium_reason, freemium_expires_at, freemium_granted_at, freemium_granted_by, deleted_at, deletion_scheduled_for FROM agencies
+WHERE stripe_customer_id = $1
+`
+
+func (q *Queries) GetAgencyByStripeCustomer(ctx context.Context, stripeCustomerID string) (Agency, error) {
+	row := q.db.QueryRowContext(ctx, getAgencyByStripeCustomer, stripeCustomerID)
+	var i Agency
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Slug,
+		&i.LogoUrl,
+		&i.LogoAvatarUrl,
+		&i.PrimaryColor,
+		&i.SecondaryColor,
+		&i.AccentColor,
+		&i.AccentGradient,
+		&i.Email,
+		&i.Phone,
+		&i.Website,
+		&i.Status,
+		&i.SubscriptionTier,
+		&i.SubscriptionID,
+		&i.SubscriptionEnd,
+		&i.StripeCustomerID,
+		&i.AiGenerationsThisMonth,
+		&i.AiGenerationsResetAt,
+		&i.IsFreemium,
+		&i.FreemiumReason,
+		&i.FreemiumExpiresAt,
+		&i.FreemiumGrantedAt,
+		&i.FreemiumGrantedBy,
+		&i.DeletedAt,
+		&i.DeletionScheduledFor,
+	)
+	return i, err
+}
+
+const insertAPIKey = `-- name: InsertAPIKey :one
+
+INSERT INTO api_keys (id, user_id, agency_id, name, prefix, key_hash, access, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, created_at, updated_at, user_id, agency_id, name, prefix, key_hash, access, expires_at, last_used_at, revoked_at
+`
+
+type InsertAPIKeyParams struct {
+	ID        uuid.UUID     `json:"id"`
+	UserID    uuid.UUID     `json:"user_id"`
+	AgencyID  uuid.NullUUID `json:"agency_id"`
+	Name      string        `json:"name"`
+	Prefix    string        `json:"prefix"`
+	KeyHash   string        `json:"key_hash"`
+	Access    int64         `json:"access"`
+	ExpiresAt sql.NullTime  `json:"expires_at"`
+}
+
+// =============================================================================
+// API Key Queries
+// =============================================================================
+func (q *Queries) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, insertAPIKey,
+		arg.ID,
+		arg.UserID,
+		arg.AgencyID,
+		arg.Name,
+		arg.Prefix,
+		arg.KeyHash,
+		arg.Access,
+		arg.ExpiresAt,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.AgencyID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Access,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const insertActivityLog = `-- name: InsertActivityLog :exec
+
+INSERT INTO agency_activity_log (id, agency_id, user_id, action, entity_type, entity_id, old_values, new_values, metadata)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+`
+
+type InsertActivityLogParams struct {
+	ID         uuid.UUID             `json:"id"`
+	AgencyID   uuid.UUID             `json:"agency_id"`
+	UserID     uuid.NullUUID         `json:"user_id"`
+	Action     string                `json:"action"`
+	EntityType string                `json:"entity_type"`
+	EntityID   uuid.NullUUID         `json:"entity_id"`
+	OldValues  pqtype.NullRawMessage `json:"old_values"`
+	NewValues  pqtype.NullRawMessage `json:"new_values"`
+	Metadata   json.RawMessage       `json:"metadata"`
+}
+
+// =============================================================================
+// Agency Activity Log Queries
+// =============================================================================
+func (q *Queries) InsertActivityLog(ctx context.Context, arg InsertActivityLogParams) error {
+	_, err := q.db.ExecContext(ctx, insertActivityLog,
+		arg.ID,
+		arg.AgencyID,
+		arg.UserID,
+		arg.Action,
+		arg.EntityType,
+		arg.EntityID,
+		arg.OldValues,
+		arg.NewValues,
+		arg.Metadata,
+	)
+	return err
+}
+
+const insertAgencyFormVersion = `-- name: InsertAgencyFormVersion :one
+INSERT INTO agency_form_versions (id, form_id, agency_id, version, schema, ui_config, changes, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, form_id, agency_id, version, schema, ui_config, changes, created_at, created_by
+`
+
+type InsertAgencyFormVersionParams struct {
+	ID        uuid.UUID       `json:"id"`
+	FormID    uuid.UUID       `json:"form_id"`
+	AgencyID  uuid.UUID       `json:"agency_id"`
+	Version   int32           `json:"version"`
+	Schema    json.RawMessage `json:"schema"`
+	UiConfig  json.RawMessage `json:"ui_config"`
+	Changes   json.RawMessage `json:"changes"`
+	CreatedBy uuid.NullUUID   `json:"created_by"`
+}
+
+func (q *Queries) InsertAgencyFormVersion(ctx context.Context, arg InsertAgencyFormVersionParams) (AgencyFormVersion, error) {
+	row := q.db.QueryRowContext(ctx, insertAgencyFormVersion,
+		arg.ID,
+		arg.FormID,
+		arg.AgencyID,
+		arg.Version,
+		arg.Schema,
+		arg.UiConfig,
+		arg.Changes,
+		arg.CreatedBy,
+	)
+	var i AgencyFormVersion
+	err := row.Scan(
+		&i.ID,
+		&i.FormID,
+		&i.AgencyID,
+		&i.Version,
+		&i.Schema,
+		&i.UiConfig,
+		&i.Changes,
+		&i.CreatedAt,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const insertClient = `-- name: InsertClient :one
+INSERT INTO clients (id, agency_id, business_name, email, phone, contact_name, notes, abn, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, agency_id, business_name, email, phone, contact_name, notes, abn, status, created_by, created_at, updated_at
+`
+
+type InsertClientParams struct {
+	ID           uuid.UUID      `json:"id"`
+	AgencyID     uuid.UUID      `json:"agency_id"`
+	BusinessName string         `json:"business_name"`
+	Email        string         `json:"email"`
+	Phone        sql.NullString `json:"phone"`
+	ContactName  sql.NullString `json:"contact_name"`
+	Notes        sql.NullString `json:"notes"`
+	Abn          string         `json:"abn"`
+	CreatedBy    uuid.NullUUID  `json:"created_by"`
+}
+
+func (q *Queries) InsertClient(ctx context.Context, arg InsertClientParams) (Client, error) {
+	row := q.db.QueryRowContext(ctx, insertClient,
+		arg.ID,
+		arg.AgencyID,
+		arg.BusinessName,
+		arg.Email,
+		arg.Phone,
+		arg.ContactName,
+		arg.Notes,
+		arg.Abn,
+		arg.CreatedBy,
+	)
+	var i Client
+	err := row.Scan(
+		&i.ID,
+		&i.AgencyID,
+		&i.BusinessName,
+		&i.Email,
+		&i.Phone,
+		&i.ContactName,
+		&i.Notes,
+		&i.Abn,
+		&i.Status,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const insertConsultation = `-- name: InsertConsultation :one
+INSERT INTO consultations (
+    id,
+    user_id,
+    agency_id,
+    client_id,
+    business_name,
+    contact_person,
+    email,
+    phone,
+    website,
+    social_linkedin,
+    social_facebook,
+    social_instagram,
+    industry,
+    business_type,
+    website_status,
+    primary_challenges,
+    urgency_level,
+    primary_goals,
+    conversion_goal,
+    budget_range,
+    timeline,
+    design_styles,
+    admired_websites,
+    consultation_notes,
+    custom_data,
+    created_by,
+    status,
+    completion_percentage
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
+    $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28
+)
+RETURNING id, user_id, agency_id, business_name, contact_person, email, phone, website, social_linkedin, social_facebook, social_instagram, industry, business_type, website_status, primary_challenges, urgency_level, primary_goals, conversion_goal, budget_range, timeline, design_styles, admired_websites, consultation_notes, created_by, performance_data, client_id, custom_data, form_id, status, completion_percentage, created_at, updated_at, completed_at
+`
+
+type InsertConsultationParams struct {
+	ID                   uuid.UUID             `json:"id"`
+	UserID               uuid.NullUUID         `json:"user_id"`
+	AgencyID             uuid.UUID             `json:"agency_id"`
+	ClientID             uuid.NullUUID         `json:"client_id"`
+	BusinessName         sql.NullString        `json:"business_name"`
+	ContactPerson        sql.NullString        `json:"contact_person"`
+	Email                sql.NullString        `json:"email"`
+	Phone                sql.NullString        `json:"phone"`
+	Website              sql.NullString        `json:"website"`
+	SocialLinkedin       sql.NullString        `json:"social_linkedin"`
+	SocialFacebook       sql.NullString        `json:"social_facebook"`
+	SocialInstagram      sql.NullString        `json:"social_instagram"`
+	Industry             sql.NullString        `json:"industry"`
+	BusinessType         sql.NullString        `json:"business_type"`
+	WebsiteStatus        sql.NullString        `json:"website_status"`
+	PrimaryChallenges    pqtype.NullRawMessage `json:"primary_challenges"`
+	UrgencyLevel         sql.NullString        `json:"urgency_level"`
+	PrimaryGoals         pqtype.NullRawMessage `json:"primary_goals"`
+	ConversionGoal       sql.NullString        `json:"conversion_goal"`
+	BudgetRange          sql.NullString        `json:"budget_range"`
+	Timeline             sql.NullString        `json:"timeline"`
+	DesignStyles         pqtype.NullRawMessage `json:"design_styles"`
+	AdmiredWebsites      pqtype.NullRawMessage `json:"admired_websites"`
+	ConsultationNotes    sql.NullString        `json:"consultation_notes"`
+	CustomData           pqtype.NullRawMessage `json:"custom_data"`
+	CreatedBy            uuid.NullUUID         `json:"created_by"`
+	Status               string                `json:"status"`
+	CompletionPercentage int32                 `json:"completion_percentage"`
+}
+
+func (q *Queries) InsertConsultation(ctx context.Context, arg InsertConsultationParams) (Consultation, error) {
+	row := q.db.QueryRowContext(ctx, insertConsultation,
+		arg.ID,
+		arg.UserID,
+		arg.AgencyID,
+		arg.ClientID,
+		arg.BusinessName,
+		arg.ContactPerson,
+		arg.Email,
+		arg.Phone,
+		arg.Website,
+		arg.SocialLinkedin,
+		arg.SocialFacebook,
+		arg.SocialInstagram,
+		arg.Industry,
+		arg.BusinessType,
+		arg.WebsiteStatus,
+		arg.PrimaryChallenges,
+		arg.UrgencyLevel,
+		arg.PrimaryGoals,
+		arg.ConversionGoal,
+		arg.BudgetRange,
+		arg.Timeline,
+		arg.DesignStyles,
+		arg.AdmiredWebsites,
+		arg.ConsultationNotes,
+		arg.CustomData,
+		arg.CreatedBy,
+		arg.Status,
+		arg.CompletionPercentage,
+	)
+	var i Consultation
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.AgencyID,
+		&i.BusinessName,
+		&i.ContactPerson,
+		&i.Email,
+		&i.Phone,
+		&i.Website,
+		&i.SocialLinkedin,
+		&i.SocialFacebook,
+		&i.SocialInstagram,
+		&i.Industry,
+		&i.BusinessType,
+		&i.WebsiteStatus,
+		&i.PrimaryChallenges,
+		&i.UrgencyLevel,
+		&i.PrimaryGoals,
+		&i.ConversionGoal,
+		&i.BudgetRange,
+		&i.Timeline,
+		&i.DesignStyles,
+		&i.AdmiredWebsites,
+		&i.ConsultationNotes,
+		&i.CreatedBy,
+		&i.PerformanceData,
+		&i.ClientID,
+		&i.CustomData,
+		&i.FormID,
+		&i.Status,
+		&i.CompletionPercentage,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const insertConsultationVersion = `-- name: InsertConsultationVersion :one
+INSERT INTO consultation_versions (
+    id,
+    consultation_id,
+    user_id,
+    agency_id,
+    version_number,
+    status,
+    completion_percentage,
+    change_summary,
+    changed_fields,
+    snapshot
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, consultation_id, user_id, agency_id, version_number, status, completion_percentage, change_summary, changed_fields, snapshot, created_at
+`
+
+type InsertConsultationVersionParams struct {
+	ID                   uuid.UUID             `json:"id"`
+	ConsultationID       uuid.UUID             `json:"consultation_id"`
+	UserID               uuid.UUID             `json:"user_id"`
+	AgencyID             uuid.NullUUID         `json:"agency_id"`
+	VersionNumber        int32                 `json:"version_number"`
+	Status               string                `json:"status"`
+	CompletionPercentage int32                 `json:"completion_percentage"`
+	ChangeSummary        sql.NullString        `json:"change_summary"`
+	ChangedFields        json.RawMessage       `json:"changed_fields"`
+	Snapshot             pqtype.NullRawMessage `json:"snapshot"`
+}
+
+func (q *Queries) InsertConsultationVersion(ctx context.Context, arg InsertConsultationVersionParams) (ConsultationVersion, error) {
+	row := q.db.QueryRowContext(ctx, insertConsultationVersion,
+		arg.ID,
+		arg.ConsultationID,
+		arg.UserID,
+		arg.AgencyID,
+		arg.VersionNumber,
+		arg.Status,
+		arg.CompletionPercentage,
+		arg.ChangeSummary,
+		arg.ChangedFields,
+		arg.Snapshot,
+	)
+	var i ConsultationVersion
+	err := row.Scan(
+		&i.ID,
+		&i.ConsultationID,
+		&i.UserID,
+		&i.AgencyID,
+		&i.VersionNumber,
+		&i.Status,
+		&i.CompletionPercentage,
+		&i.ChangeSummary,
+		&i.ChangedFields,
+		&i.Snapshot,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertContract = `-- name: InsertContract :one
+INSERT INTO contracts (
+    id,
+    agency_id,
+    proposal_id,
+    template_id,
+    client_id,
+    contract_number,
+    slug,
+    status,
+    client_business_name,
+    client_contact_name,
+    client_email,
+    client_phone,
+    client_address,
+    services_description,
+    commencement_date,
+    completion_date,
+    special_conditions,
+    total_price,
+    price_includes_gst,
+    payment_terms,
+    generated_cover_html,
+    generated_terms_html,
+    generated_schedule_html,
+    valid_until,
+    agency_signatory_name,
+    agency_signatory_title,
+    included_schedule_ids,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
+    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28
+) RETURNING id, created_at, updated_at, agency_id, proposal_id, template_id, client_id, contract_number, slug, version, status, client_business_name, client_contact_name, client_email, client_phone, client_address, services_description, commencement_date, completion_date, special_conditions, total_price, price_includes_gst, payment_terms, generated_cover_html, generated_terms_html, generated_schedule_html, valid_until, agency_signatory_name, agency_signatory_title, agency_signed_at, client_signatory_name, client_signatory_title, client_signed_at, client_signature_ip, client_signature_user_agent, view_count, last_viewed_at, sent_at, signed_pdf_url, visible_fields, included_schedule_ids, created_by, agency_signature_ip, agency_signature_user_agent, content_hash
+`
+
+type InsertContractParams struct {
+	ID                    uuid.UUID       `json:"id"`
+	AgencyID              uuid.UUID       `json:"agency_id"`
+	ProposalID            uuid.UUID       `json:"proposal_id"`
+	TemplateID            uuid.NullUUID   `json:"template_id"`
+	ClientID              uuid.NullUUID   `json:"client_id"`
+	ContractNumber        string          `json:"contract_number"`
+	Slug                  string          `json:"slug"`
+	Status                string          `json:"status"`
+	ClientBusinessName    string          `json:"client_business_name"`
+	ClientContactName     string          `json:"client_contact_name"`
+	ClientEmail           string          `json:"client_email"`
+	ClientPhone           string          `json:"client_phone"`
+	ClientAddress         string          `json:"client_address"`
+	ServicesDescription   string          `json:"services_description"`
+	CommencementDate      sql.NullTime    `json:"commencement_date"`
+	CompletionDate        sql.NullTime    `json:"completion_date"`
+	SpecialConditions     string          `json:"special_conditions"`
+	TotalPrice            money.Money     `json:"total_price"`
+	PriceIncludesGst      bool            `json:"price_includes_gst"`
+	PaymentTerms          string          `json:"payment_terms"`
+	GeneratedCoverHtml    sql.NullString  `json:"generated_cover_html"`
+	GeneratedTermsHtml    sql.NullString  `json:"generated_terms_html"`
+	GeneratedScheduleHtml sql.NullString  `json:"generated_schedule_html"`
+	ValidUntil            sql.NullTime    `json:"valid_until"`
+	AgencySignatoryName   sql.NullString  `json:"agency_signatory_name"`
+	AgencySignatoryTitle  sql.NullString  `json:"agency_signatory_title"`
+	IncludedScheduleIds   json.RawMessage `json:"included_schedule_ids"`
+	CreatedBy             uuid.NullUUID   `json:"created_by"`
+}
+
+func (q *Queries) InsertContract(ctx context.Context, arg InsertContractParams) (Contract, error) {
+	row := q.db.QueryRowContext(ctx, insertContract,
+		arg.ID,
+		arg.AgencyID,
+		arg.ProposalID,
+		arg.TemplateID,
+		arg.ClientID,
+		arg.ContractNumber,
+		arg.Slug,
+		arg.Status,
+		arg.ClientBusinessName,
+		arg.ClientContactName,
+		arg.ClientEmail,
+		arg.ClientPhone,
+		arg.ClientAddress,
+		arg.ServicesDescription,
+		arg.CommencementDate,
+		arg.CompletionDate,
+		arg.SpecialConditions,
+		arg.TotalPrice,
+		arg.PriceIncludesGst,
+		arg.PaymentTerms,
+		arg.GeneratedCoverHtml,
+		arg.GeneratedTermsHtml,
+		arg.GeneratedScheduleHtml,
+		arg.ValidUntil,
+		arg.AgencySignatoryName,
+		arg.AgencySignatoryTitle,
+		arg.IncludedScheduleIds,
+		arg.CreatedBy,
+	)
+	var i Contract
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.TemplateID,
+		&i.ClientID,
+		&i.ContractNumber,
+		&i.Slug,
+		&i.Version,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ServicesDescription,
+		&i.CommencementDate,
+		&i.CompletionDate,
+		&i.SpecialConditions,
+		&i.TotalPrice,
+		&i.PriceIncludesGst,
+		&i.PaymentTerms,
+		&i.GeneratedCoverHtml,
+		&i.GeneratedTermsHtml,
+		&i.GeneratedScheduleHtml,
+		&i.ValidUntil,
+		&i.AgencySignatoryName,
+		&i.AgencySignatoryTitle,
+		&i.AgencySignedAt,
+		&i.ClientSignatoryName,
+		&i.ClientSignatoryTitle,
+		&i.ClientSignedAt,
+		&i.ClientSignatureIp,
+		&i.ClientSignatureUserAgent,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.SignedPdfUrl,
+		&i.VisibleFields,
+		&i.IncludedScheduleIds,
+		&i.CreatedBy,
+		&i.AgencySignatureIp,
+		&i.AgencySignatureUserAgent,
+		&i.ContentHash,
+	)
+	return i, err
+}
+
+const insertContractSignature = `-- name: InsertContractSignature :one
+INSERT INTO contract_signatures (
+    id,
+    contract_id,
+    agency_id,
+    party,
+    signatory_name,
+    signatory_title,
+    signed_at,
+    ip_address,
+    user_agent,
+    user_id,
+    content_hash,
+    previous_hash,
+    signature_hash
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING id, created_at, contract_id, agency_id, party, signatory_name, signatory_title, signed_at, ip_address, user_agent, user_id, content_hash, previous_hash, signature_hash
+`
+
+type InsertContractSignatureParams struct {
+	ID             uuid.UUID     `json:"id"`
+	ContractID     uuid.UUID     `json:"contract_id"`
+	AgencyID       uuid.UUID     `json:"agency_id"`
+	Party          string        `json:"party"`
+	SignatoryName  string        `json:"signatory_name"`
+	SignatoryTitle string        `json:"signatory_title"`
+	SignedAt       time.Time     `json:"signed_at"`
+	IpAddress      string        `json:"ip_address"`
+	UserAgent      string        `json:"user_agent"`
+	UserID         uuid.NullUUID `json:"user_id"`
+	ContentHash    string        `json:"content_hash"`
+	PreviousHash   string        `json:"previous_hash"`
+	SignatureHash  string        `json:"signature_hash"`
+}
+
+func (q *Queries) InsertContractSignature(ctx context.Context, arg InsertContractSignatureParams) (ContractSignature, error) {
+	row := q.db.QueryRowContext(ctx, insertContractSignature,
+		arg.ID,
+		arg.ContractID,
+		arg.AgencyID,
+		arg.Party,
+		arg.SignatoryName,
+		arg.SignatoryTitle,
+		arg.SignedAt,
+		arg.IpAddress,
+		arg.UserAgent,
+		arg.UserID,
+		arg.ContentHash,
+		arg.PreviousHash,
+		arg.SignatureHash,
+	)
+	var i ContractSignature
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ContractID,
+		&i.AgencyID,
+		&i.Party,
+		&i.SignatoryName,
+		&i.SignatoryTitle,
+		&i.SignedAt,
+		&i.IpAddress,
+		&i.UserAgent,
+		&i.UserID,
+		&i.ContentHash,
+		&i.PreviousHash,
+		&i.SignatureHash,
+	)
+	return i, err
+}
+
+const insertDocumentNumbering = `-- name: InsertDocumentNumbering :exec
+INSERT INTO agency_document_numbering (id, agency_id, document_type, format, reset_yearly, sequence_year)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (agency_id, document_type) DO UPDATE
+SET sequence_year = EXCLUDED.sequence_year,
+    updated_at = CURRENT_TIMESTAMP
+`
+
+type InsertDocumentNumberingParams struct {
+	ID           uuid.UUID `json:"id"`
+	AgencyID     uuid.UUID `json:"agency_id"`
+	DocumentType string    `json:"document_type"`
+	Format       string    `json:"format"`
+	ResetYearly  bool      `json:"reset_yearly"`
+	SequenceYear int32     `json:"sequence_year"`
+}
+
+func (q *Queries) InsertDocumentNumbering(ctx context.Context, arg InsertDocumentNumberingParams) error {
+	_, err := q.db.ExecContext(ctx, insertDocumentNumbering,
+		arg.ID,
+		arg.AgencyID,
+		arg.DocumentType,
+		arg.Format,
+		arg.ResetYearly,
+		arg.SequenceYear,
+	)
+	return err
+}
+
+const insertEmail = `-- name: InsertEmail :one
+insert into emails (id, user_id, email_to, email_from, email_subject, email_body, email_log_id, agency_id, unsubscribe_token) values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning id, created, updated, user_id, email_to, email_from, email_subject, email_body, status, retry_count, error_message, next_attempt_at, locked_until, sent_at, email_log_id, provider_message_id, agency_id, unsubscribe_token
+`
+
+type InsertEmailParams struct {
+	ID               uuid.UUID      `json:"id"`
+	UserID           uuid.UUID      `json:"user_id"`
+	EmailTo          string         `json:"email_to"`
+	EmailFrom        string         `json:"email_from"`
+	EmailSubject     string         `json:"email_subject"`
+	EmailBody        string         `json:"email_body"`
+	EmailLogID       uuid.NullUUID  `json:"email_log_id"`
+	AgencyID         uuid.NullUUID  `json:"agency_id"`
+	UnsubscribeToken sql.NullString `json:"unsubscribe_token"`
+}
+
+func (q *Queries) InsertEmail(ctx context.Context, arg InsertEmailParams) (Email, error) {
+	row := q.db.QueryRowContext(ctx, insertEmail,
+		arg.ID,
+		arg.UserID,
+		arg.EmailTo,
+		arg.EmailFrom,
+		arg.EmailSubject,
+		arg.EmailBody,
+		arg.EmailLogID,
+		arg.AgencyID,
+		arg.UnsubscribeToken,
+	)
+	var i Email
+	err := row.Scan(
+		&i.ID,
+		&i.Created,
+		&i.Updated,
+		&i.UserID,
+		&i.EmailTo,
+		&i.EmailFrom,
+		&i.EmailSubject,
+		&i.EmailBody,
+		&i.Status,
+		&i.RetryCount,
+		&i.ErrorMessage,
+		&i.NextAttemptAt,
+		&i.LockedUntil,
+		&i.SentAt,
+		&i.EmailLogID,
+		&i.ProviderMessageID,
+		&i.AgencyID,
+		&i.UnsubscribeToken,
+	)
+	return i, err
+}
+
+const insertEmailAttachment = `-- name: InsertEmailAttachment :one
+insert into email_attachments (id, email_id, file_name, content_type, file_id) values ($1, $2, $3, $4, $5) returning id, created, email_id, file_name, content_type, file_id
+`
+
+type InsertEmailAttachmentParams struct {
+	ID          uuid.UUID     `json:"id"`
+	EmailID     uuid.UUID     `json:"email_id"`
+	FileName    string        `json:"file_name"`
+	ContentType string        `json:"content_type"`
+	FileID      uuid.NullUUID `json:"file_id"`
+}
+
+func (q *Queries) InsertEmailAttachment(ctx context.Context, arg InsertEmailAttachmentParams) (EmailAttachment, error) {
+	row := q.db.QueryRowContext(ctx, insertEmailAttachment,
+		arg.ID,
+		arg.EmailID,
+		arg.FileName,
+		arg.ContentType,
+		arg.FileID,
+	)
+	var i EmailAttachment
+	err := row.Scan(
+		&i.ID,
+		&i.Created,
+		&i.EmailID,
+		&i.FileName,
+		&i.ContentType,
+		&i.FileID,
+	)
+	return i, err
+}
+
+const insertEmailTemplate = `-- name: InsertEmailTemplate :one
+INSERT INTO email_templates (id, agency_id, name, version, subject, body, created_by)
+VALUES (
+    $1, $2, $3,
+    (SELECT COALESCE(MAX(version), 0) + 1 FROM email_templates WHERE agency_id = $2 AND name = $3),
+    $4, $5, $6
+)
+RETURNING id, created_at, agency_id, name, version, subject, body, created_by
+`
+
+type InsertEmailTemplateParams struct {
+	ID        uuid.UUID     `json:"id"`
+	AgencyID  uuid.UUID     `json:"agency_id"`
+	Name      string        `json:"name"`
+	Subject   string        `json:"subject"`
+	Body      string        `json:"body"`
+	CreatedBy uuid.NullUUID `json:"created_by"`
+}
+
+// Adds the next version of an agency's template
+func (q *Queries) InsertEmailTemplate(ctx context.Context, arg InsertEmailTemplateParams) (EmailTemplate, error) {
+	row := q.db.QueryRowContext(ctx, insertEmailTemplate,
+		arg.ID,
+		arg.AgencyID,
+		arg.Name,
+		arg.Subject,
+		arg.Body,
+		arg.CreatedBy,
+	)
+	var i EmailTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.AgencyID,
+		&i.Name,
+		&i.Version,
+		&i.Subject,
+		&i.Body,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const insertFile = `-- name: InsertFile :one
+insert into files (id, user_id, file_key, file_name, file_size, content_type, agency_id) values ($1, $2, $3, $4, $5, $6, $7) returning id, created, updated, user_id, file_key, file_name, file_size, content_type, agency_id
+`
+
+type InsertFileParams struct {
+	ID          uuid.UUID     `json:"id"`
+	UserID      uuid.UUID     `json:"user_id"`
+	FileKey     string        `json:"file_key"`
+	FileName    string        `json:"file_name"`
+	FileSize    int64         `json:"file_size"`
+	ContentType string        `json:"content_type"`
+	AgencyID    uuid.NullUUID `json:"agency_id"`
+}
+
+func (q *Queries) InsertFile(ctx context.Context, arg InsertFileParams) (File, error) {
+	row := q.db.QueryRowContext(ctx, insertFile,
+		arg.ID,
+		arg.UserID,
+		arg.FileKey,
+		arg.FileName,
+		arg.FileSize,
+		arg.ContentType,
+		arg.AgencyID,
+	)
+	var i File
+	err := row.Scan(
+		&i.ID,
+		&i.Created,
+		&i.Updated,
+		&i.UserID,
+		&i.FileKey,
+		&i.FileName,
+		&i.FileSize,
+		&i.ContentType,
+		&i.AgencyID,
+	)
+	return i, err
+}
+
+const insertInvoice = `-- name: InsertInvoice :one
+INSERT INTO invoices (
+    id,
+    agency_id,
+    proposal_id,
+    contract_id,
+    client_id,
+    invoice_number,
+    slug,
+    status,
+    client_business_name,
+    client_contact_name,
+    client_email,
+    client_phone,
+    client_address,
+    client_abn,
+    issue_date,
+    due_date,
+    subtotal,
+    discount_amount,
+    discount_description,
+    gst_amount,
+    total,
+    gst_registered,
+    gst_rate,
+    payment_terms,
+    payment_terms_custom,
+    notes,
+    public_notes,
+    created_by,
+    quotation_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
+    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29
+) RETURNING id, created_at, updated_at, agency_id, proposal_id, contract_id, client_id, invoice_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_address, client_abn, issue_date, due_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, payment_terms, payment_terms_custom, notes, public_notes, view_count, last_viewed_at, sent_at, paid_at, payment_method, payment_reference, payment_notes, amount_paid, pdf_url, pdf_generated_at, stripe_payment_link_id, stripe_payment_link_url, stripe_payment_intent_id, stripe_checkout_session_id, online_payment_enabled, created_by, quotation_id
+`
+
+type InsertInvoiceParams struct {
+	ID                  uuid.UUID     `json:"id"`
+	AgencyID            uuid.UUID     `json:"agency_id"`
+	ProposalID          uuid.NullUUID `json:"proposal_id"`
+	ContractID          uuid.NullUUID `json:"contract_id"`
+	ClientID            uuid.NullUUID `json:"client_id"`
+	InvoiceNumber       string        `json:"invoice_number"`
+	Slug                string        `json:"slug"`
+	Status              string        `json:"status"`
+	ClientBusinessName  string        `json:"client_business_name"`
+	ClientContactName   string        `json:"client_contact_name"`
+	ClientEmail         string        `json:"client_email"`
+	ClientPhone         string        `json:"client_phone"`
+	ClientAddress       string        `json:"client_address"`
+	ClientAbn           string        `json:"client_abn"`
+	IssueDate           time.Time     `json:"issue_date"`
+	DueDate             time.Time     `json:"due_date"`
+	Subtotal            money.Money   `json:"subtotal"`
+	DiscountAmount      money.Money   `json:"discount_amount"`
+	DiscountDescription string        `json:"discount_description"`
+	GstAmount           money.Money   `json:"gst_amount"`
+	Total               money.Money   `json:"total"`
+	GstRegistered       bool          `json:"gst_registered"`
+	GstRate             money.Decimal `json:"gst_rate"`
+	PaymentTerms        string        `json:"payment_terms"`
+	PaymentTermsCustom  string        `json:"payment_terms_custom"`
+	Notes               string        `json:"notes"`
+	PublicNotes         string        `json:"public_notes"`
+	CreatedBy           uuid.NullUUID `json:"created_by"`
+	QuotationID         uuid.NullUUID `json:"quotation_id"`
+}
+
+func (q *Queries) InsertInvoice(ctx context.Context, arg InsertInvoiceParams) (Invoice, error) {
+	row := q.db.QueryRowContext(ctx, insertInvoice,
+		arg.ID,
+		arg.AgencyID,
+		arg.ProposalID,
+		arg.ContractID,
+		arg.ClientID,
+		arg.InvoiceNumber,
+		arg.Slug,
+		arg.Status,
+		arg.ClientBusinessName,
+		arg.ClientContactName,
+		arg.ClientEmail,
+		arg.ClientPhone,
+		arg.ClientAddress,
+		arg.ClientAbn,
+		arg.IssueDate,
+		arg.DueDate,
+		arg.Subtotal,
+		arg.DiscountAmount,
+		arg.DiscountDescription,
+		arg.GstAmount,
+		arg.Total,
+		arg.GstRegistered,
+		arg.GstRate,
+		arg.PaymentTerms,
+		arg.PaymentTermsCustom,
+		arg.Notes,
+		arg.PublicNotes,
+		arg.CreatedBy,
+		arg.QuotationID,
+	)
+	var i Invoice
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.ContractID,
+		&i.ClientID,
+		&i.InvoiceNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ClientAbn,
+		&i.IssueDate,
+		&i.DueDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.PaymentTerms,
+		&i.PaymentTermsCustom,
+		&i.Notes,
+		&i.PublicNotes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.PaidAt,
+		&i.PaymentMethod,
+		&i.PaymentReference,
+		&i.PaymentNotes,
+		&i.AmountPaid,
+		&i.PdfUrl,
+		&i.PdfGeneratedAt,
+		&i.StripePaymentLinkID,
+		&i.StripePaymentLinkUrl,
+		&i.StripePaymentIntentID,
+		&i.StripeCheckoutSessionID,
+		&i.OnlinePaymentEnabled,
+		&i.CreatedBy,
+		&i.QuotationID,
+	)
+	return i, err
+}
+
+const insertInvoiceLineItem = `-- name: InsertInvoiceLineItem :one
+INSERT INTO invoice_line_items (
+    id,
+    invoice_id,
+    description,
+    quantity,
+    unit_price,
+    amount,
+    is_taxable,
+    sort_order,
+    category,
+    package_id,
+    addon_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING id, created_at, updated_at, invoice_id, description, quantity, unit_price, amount, is_taxable, sort_order, category, package_id, addon_id
+`
+
+type InsertInvoiceLineItemParams struct {
+	ID          uuid.UUID      `json:"id"`
+	InvoiceID   uuid.UUID      `json:"invoice_id"`
+	Description string         `json:"description"`
+	Quantity    money.Decimal  `json:"quantity"`
+	UnitPrice   money.Money    `json:"unit_price"`
+	Amount      money.Money    `json:"amount"`
+	IsTaxable   bool           `json:"is_taxable"`
+	SortOrder   int32          `json:"sort_order"`
+	Category    sql.NullString `json:"category"`
+	PackageID   uuid.NullUUID  `json:"package_id"`
+	AddonID     uuid.NullUUID  `json:"addon_id"`
+}
+
+func (q *Queries) InsertInvoiceLineItem(ctx context.Context, arg InsertInvoiceLineItemParams) (InvoiceLineItem, error) {
+	row := q.db.QueryRowContext(ctx, insertInvoiceLineItem,
+		arg.ID,
+		arg.InvoiceID,
+		arg.Description,
+		arg.Quantity,
+		arg.UnitPrice,
+		arg.Amount,
+		arg.IsTaxable,
+		arg.SortOrder,
+		arg.Category,
+		arg.PackageID,
+		arg.AddonID,
+	)
+	var i InvoiceLineItem
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.InvoiceID,
+		&i.Description,
+		&i.Quantity,
+		&i.UnitPrice,
+		&i.Amount,
+		&i.IsTaxable,
+		&i.SortOrder,
+		&i.Category,
+		&i.PackageID,
+		&i.AddonID,
+	)
+	return i, err
+}
+
+const insertNote = `-- name: InsertNote :one
+insert into notes (id, user_id, title, category, content) values ($1, $2, $3, $4, $5) returning id, created, updated, user_id, title, category, content
+`
+
+type InsertNoteParams struct {
+	ID       uuid.UUID `json:"id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Title    string    `json:"title"`
+	Category string    `json:"category"`
+	Content  string    `json:"content"`
+}
+
+func (q *Queries) InsertNote(ctx context.Context, arg InsertNoteParams) (Note, error) {
+	row := q.db.QueryRowContext(ctx, insertNote,
+		arg.ID,
+		arg.UserID,
+		arg.Title,
+		arg.Category,
+		arg.Content,
+	)
+	var i Note
+	err := row.Scan(
+		&i.ID,
+		&i.Created,
+		&i.Updated,
+		&i.UserID,
+		&i.Title,
+		&i.Category,
+		&i.Content,
+	)
+	return i, err
+}
+
+const insertPasskey = `-- name: InsertPasskey :one
+
+INSERT INTO passkeys (id, user_id, name, credential_id, public_key, algorithm, sign_count, transports)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, created_at, updated_at, user_id, name, credential_id, public_key, algorithm, sign_count, transports, last_used_at
+`
+
+type InsertPasskeyParams struct {
+	ID           uuid.UUID       `json:"id"`
+	UserID       uuid.UUID       `json:"user_id"`
+	Name         string          `json:"name"`
+	CredentialID string          `json:"credential_id"`
+	PublicKey    []byte          `json:"public_key"`
+	Algorithm    int32           `json:"algorithm"`
+	SignCount    int64           `json:"sign_count"`
+	Transports   json.RawMessage `json:"transports"`
+}
+
+// =============================================================================
+// Passkey Queries
+// =============================================================================
+func (q *Queries) InsertPasskey(ctx context.Context, arg InsertPasskeyParams) (Passkey, error) {
+	row := q.db.QueryRowContext(ctx, insertPasskey,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.CredentialID,
+		arg.PublicKey,
+		arg.Algorithm,
+		arg.SignCount,
+		arg.Transports,
+	)
+	var i Passkey
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+		&i.CredentialID,
+		&i.PublicKey,
+		&i.Algorithm,
+		&i.SignCount,
+		&i.Transports,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const insertPasskeyChallenge = `-- name: InsertPasskeyChallenge :exec
+INSERT INTO passkey_challenges (id, expires_at, purpose, user_id, return_url)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type InsertPasskeyChallengeParams struct {
+	ID        string        `json:"id"`
+	ExpiresAt time.Time     `json:"expires_at"`
+	Purpose   string        `json:"purpose"`
+	UserID    uuid.NullUUID `json:"user_id"`
+	ReturnUrl string        `json:"return_url"`
+}
+
+func (q *Queries) InsertPasskeyChallenge(ctx context.Context, arg InsertPasskeyChallengeParams) error {
+	_, err := q.db.ExecContext(ctx, insertPasskeyChallenge,
+		arg.ID,
+		arg.ExpiresAt,
+		arg.Purpose,
+		arg.UserID,
+		arg.ReturnUrl,
+	)
+	return err
+}
+
+const insertProposal = `-- name: InsertProposal :one
+INSERT INTO proposals (
+    id,
+    agency_id,
+    consultation_id,
+    client_id,
+    proposal_number,
+    slug,
+    status,
+    client_business_name,
+    client_contact_name,
+    client_email,
+    client_phone,
+    client_website,
+    title,
+    cover_image,
+    performance_data,
+    opportunity_content,
+    current_issues,
+    compliance_issues,
+    roi_analysis,
+    performance_standards,
+    local_advantage_content,
+    proposed_pages,
+    timeline,
+    closing_content,
+    selected_package_id,
+    selected_addons,
+    custom_pricing,
+    valid_until,
+    executive_summary,
+    next_steps,
+    consultation_pain_points,
+    consultation_goals,
+    consultation_challenges,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
+    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34
+) RETURNING id, created_at, updated_at, agency_id, consultation_id, client_id, proposal_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_website, title, cover_image, performance_data, opportunity_content, current_issues, compliance_issues, roi_analysis, performance_standards, local_advantage_content, proposed_pages, timeline, closing_content, selected_package_id, selected_addons, custom_pricing, valid_until, view_count, last_viewed_at, sent_at, accepted_at, declined_at, client_comments, decline_reason, revision_request_notes, revision_requested_at, executive_summary, next_steps, consultation_pain_points, consultation_goals, consultation_challenges, created_by
+`
+
+type InsertProposalParams struct {
+	ID                     uuid.UUID             `json:"id"`
+	AgencyID               uuid.UUID             `json:"agency_id"`
+	ConsultationID         uuid.NullUUID         `json:"consultation_id"`
+	ClientID               uuid.NullUUID         `json:"client_id"`
+	ProposalNumber         string                `json:"proposal_number"`
+	Slug                   string                `json:"slug"`
+	Status                 string                `json:"status"`
+	ClientBusinessName     string                `json:"client_business_name"`
+	ClientContactName      string                `json:"client_contact_name"`
+	ClientEmail            string                `json:"client_email"`
+	ClientPhone            string                `json:"client_phone"`
+	ClientWebsite          string                `json:"client_website"`
+	Title                  string                `json:"title"`
+	CoverImage             sql.NullString        `json:"cover_image"`
+	PerformanceData        json.RawMessage       `json:"performance_data"`
+	OpportunityContent     string                `json:"opportunity_content"`
+	CurrentIssues          json.RawMessage       `json:"current_issues"`
+	ComplianceIssues       json.RawMessage       `json:"compliance_issues"`
+	RoiAnalysis            json.RawMessage       `json:"roi_analysis"`
+	PerformanceStandards   json.RawMessage       `json:"performance_standards"`
+	LocalAdvantageContent  string                `json:"local_advantage_content"`
+	ProposedPages          json.RawMessage       `json:"proposed_pages"`
+	Timeline               json.RawMessage       `json:"timeline"`
+	ClosingContent         string                `json:"closing_content"`
+	SelectedPackageID      uuid.NullUUID         `json:"selected_package_id"`
+	SelectedAddons         json.RawMessage       `json:"selected_addons"`
+	CustomPricing          pqtype.NullRawMessage `json:"custom_pricing"`
+	ValidUntil             sql.NullTime          `json:"valid_until"`
+	ExecutiveSummary       string                `json:"executive_summary"`
+	NextSteps              json.RawMessage       `json:"next_steps"`
+	ConsultationPainPoints json.RawMessage       `json:"consultation_pain_points"`
+	ConsultationGoals      json.RawMessage       `json:"consultation_goals"`
+	ConsultationChallenges json.RawMessage       `json:"consultation_challenges"`
+	CreatedBy              uuid.NullUUID         `json:"created_by"`
+}
+
+func (q *Queries) InsertProposal(ctx context.Context, arg InsertProposalParams) (Proposal, error) {
+	row := q.db.QueryRowContext(ctx, insertProposal,
+		arg.ID,
+		arg.AgencyID,
+		arg.ConsultationID,
+		arg.ClientID,
+		arg.ProposalNumber,
+		arg.Slug,
+		arg.Status,
+		arg.ClientBusinessName,
+		arg.ClientContactName,
+		arg.ClientEmail,
+		arg.ClientPhone,
+		arg.ClientWebsite,
+		arg.Title,
+		arg.CoverImage,
+		arg.PerformanceData,
+		arg.OpportunityContent,
+		arg.CurrentIssues,
+		arg.ComplianceIssues,
+		arg.RoiAnalysis,
+		arg.PerformanceStandards,
+		arg.LocalAdvantageContent,
+		arg.ProposedPages,
+		arg.Timeline,
+		arg.ClosingContent,
+		arg.SelectedPackageID,
+		arg.SelectedAddons,
+		arg.CustomPricing,
+		arg.ValidUntil,
+		arg.ExecutiveSummary,
+		arg.NextSteps,
+		arg.ConsultationPainPoints,
+		arg.ConsultationGoals,
+		arg.ConsultationChallenges,
+		arg.CreatedBy,
+	)
+	var i Proposal
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ConsultationID,
+		&i.ClientID,
+		&i.ProposalNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientWebsite,
+		&i.Title,
+		&i.CoverImage,
+		&i.PerformanceData,
+		&i.OpportunityContent,
+		&i.CurrentIssues,
+		&i.ComplianceIssues,
+		&i.RoiAnalysis,
+		&i.PerformanceStandards,
+		&i.LocalAdvantageContent,
+		&i.ProposedPages,
+		&i.Timeline,
+		&i.ClosingContent,
+		&i.SelectedPackageID,
+		&i.SelectedAddons,
+		&i.CustomPricing,
+		&i.ValidUntil,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.AcceptedAt,
+		&i.DeclinedAt,
+		&i.ClientComments,
+		&i.DeclineReason,
+		&i.RevisionRequestNotes,
+		&i.RevisionRequestedAt,
+		&i.ExecutiveSummary,
+		&i.NextSteps,
+		&i.ConsultationPainPoints,
+		&i.ConsultationGoals,
+		&i.ConsultationChallenges,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const insertQuotation = `-- name: InsertQuotation :one
+INSERT INTO quotations (
+    id,
+    agency_id,
+    client_id,
+    template_id,
+    quotation_number,
+    slug,
+    quotation_name,
+    status,
+    client_business_name,
+    client_contact_name,
+    client_email,
+    client_phone,
+    client_address,
+    site_address,
+    site_reference,
+    prepared_date,
+    expiry_date,
+    subtotal,
+    discount_amount,
+    discount_description,
+    gst_amount,
+    total,
+    gst_registered,
+    gst_rate,
+    terms_blocks,
+    options_notes,
+    notes,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
+    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28
+) RETURNING id, created_at, updated_at, agency_id, client_id, template_id, quotation_number, slug, quotation_name, status, client_business_name, client_contact_name, client_email, client_phone, client_address, site_address, site_reference, prepared_date, expiry_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, terms_blocks, options_notes, notes, view_count, last_viewed_at, sent_at, declined_at, decline_reason, accepted_by_name, accepted_by_title, accepted_at, acceptance_ip, created_by
+`
+
+type InsertQuotationParams struct {
+	ID                  uuid.UUID       `json:"id"`
+	AgencyID            uuid.UUID       `json:"agency_id"`
+	ClientID            uuid.NullUUID   `json:"client_id"`
+	TemplateID          uuid.NullUUID   `json:"template_id"`
+	QuotationNumber     string          `json:"quotation_number"`
+	Slug                string          `json:"slug"`
+	QuotationName       string          `json:"quotation_name"`
+	Status              string          `json:"status"`
+	ClientBusinessName  string          `json:"client_business_name"`
+	ClientContactName   string          `json:"client_contact_name"`
+	ClientEmail         string          `json:"client_email"`
+	ClientPhone         string          `json:"client_phone"`
+	ClientAddress       string          `json:"client_address"`
+	SiteAddress         string          `json:"site_address"`
+	SiteReference       string          `json:"site_reference"`
+	PreparedDate        time.Time       `json:"prepared_date"`
+	ExpiryDate          time.Time       `json:"expiry_date"`
+	Subtotal            money.Money     `json:"subtotal"`
+	DiscountAmount      money.Money     `json:"discount_amount"`
+	DiscountDescription string          `json:"discount_description"`
+	GstAmount           money.Money     `json:"gst_amount"`
+	Total               money.Money     `json:"total"`
+	GstRegistered       bool            `json:"gst_registered"`
+	GstRate             money.Decimal   `json:"gst_rate"`
+	TermsBlocks         json.RawMessage `json:"terms_blocks"`
+	OptionsNotes        string          `json:"options_notes"`
+	Notes               string          `json:"notes"`
+	CreatedBy           uuid.NullUUID   `json:"created_by"`
+}
+
+func (q *Queries) InsertQuotation(ctx context.Context, arg InsertQuotationParams) (Quotation, error) {
+	row := q.db.QueryRowContext(ctx, insertQuotation,
+		arg.ID,
+		arg.AgencyID,
+		arg.ClientID,
+		arg.TemplateID,
+		arg.QuotationNumber,
+		arg.Slug,
+		arg.QuotationName,
+		arg.Status,
+		arg.ClientBusinessName,
+		arg.ClientContactName,
+		arg.ClientEmail,
+		arg.ClientPhone,
+		arg.ClientAddress,
+		arg.SiteAddress,
+		arg.SiteReference,
+		arg.PreparedDate,
+		arg.ExpiryDate,
+		arg.Subtotal,
+		arg.DiscountAmount,
+		arg.DiscountDescription,
+		arg.GstAmount,
+		arg.Total,
+		arg.GstRegistered,
+		arg.GstRate,
+		arg.TermsBlocks,
+		arg.OptionsNotes,
+		arg.Notes,
+		arg.CreatedBy,
+	)
+	var i Quotation
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ClientID,
+		&i.TemplateID,
+		&i.QuotationNumber,
+		&i.Slug,
+		&i.QuotationName,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.SiteAddress,
+		&i.SiteReference,
+		&i.PreparedDate,
+		&i.ExpiryDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.TermsBlocks,
+		&i.OptionsNotes,
+		&i.Notes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.DeclinedAt,
+		&i.DeclineReason,
+		&i.AcceptedByName,
+		&i.AcceptedByTitle,
+		&i.AcceptedAt,
+		&i.AcceptanceIp,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const insertQuotationScopeSection = `-- name: InsertQuotationScopeSection :one
+INSERT INTO quotation_scope_sections (
+    id,
+    quotation_id,
+    title,
+    work_items,
+    section_price,
+    section_gst,
+    section_total,
+    sort_order,
+    scope_template_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING id, created_at, quotation_id, title, work_items, section_price, section_gst, section_total, sort_order, scope_template_id
+`
+
+type InsertQuotationScopeSectionParams struct {
+	ID              uuid.UUID       `json:"id"`
+	QuotationID     uuid.UUID       `json:"quotation_id"`
+	Title           string          `json:"title"`
+	WorkItems       json.RawMessage `json:"work_items"`
+	SectionPrice    money.Money     `json:"section_price"`
+	SectionGst      money.Money     `json:"section_gst"`
+	SectionTotal    money.Money     `json:"section_total"`
+	SortOrder       int32           `json:"sort_order"`
+	ScopeTemplateID uuid.NullUUID   `json:"scope_template_id"`
+}
+
+func (q *Queries) InsertQuotationScopeSection(ctx context.Context, arg InsertQuotationScopeSectionParams) (QuotationScopeSection, error) {
+	row := q.db.QueryRowContext(ctx, insertQuotationScopeSection,
+		arg.ID,
+		arg.QuotationID,
+		arg.Title,
+		arg.WorkItems,
+		arg.SectionPrice,
+		arg.SectionGst,
+		arg.SectionTotal,
+		arg.SortOrder,
+		arg.ScopeTemplateID,
+	)
+	var i QuotationScopeSection
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.QuotationID,
+		&i.Title,
+		&i.WorkItems,
+		&i.SectionPrice,
+		&i.SectionGst,
+		&i.SectionTotal,
+		&i.SortOrder,
+		&i.ScopeTemplateID,
+	)
+	return i, err
+}
+
+const insertSessionToken = `-- name: InsertSessionToken :one
+insert into tokens (id, expires, target, callback, family, user_agent, ip)
+values ($1, $2, $3, '', $4, $5, $6)
+returning id, expires, target, callback, family, created, rotated, user_agent, ip
+`
+
+type InsertSessionTokenParams struct {
+	ID        string    `json:"id"`
+	Expires   time.Time `json:"expires"`
+	Target    string    `json:"target"`
+	Family    string    `json:"family"`
+	UserAgent string    `json:"user_agent"`
+	Ip        string    `json:"ip"`
+}
+
+// Inserts a refresh token in a session's family of tokens
+func (q *Queries) InsertSessionToken(ctx context.Context, arg InsertSessionTokenParams) (Token, error) {
+	row := q.db.QueryRowContext(ctx, insertSessionToken,
+		arg.ID,
+		arg.Expires,
+		arg.Target,
+		arg.Family,
+		arg.UserAgent,
+		arg.Ip,
+	)
+	var i Token
+	err := row.Scan(
+		&i.ID,
+		&i.Expires,
+		&i.Target,
+		&i.Callback,
+		&i.Family,
+		&i.Created,
+		&i.Rotated,
+		&i.UserAgent,
+		&i.Ip,
+	)
+	return i, err
+}
+
+const insertToken = `-- name: InsertToken :one
+insert into tokens (id, expires, target, callback) values ($1, $2, $3, $4) returning id, expires, target, callback, family, created, rotated, user_agent, ip
+`
+
+type InsertTokenParams struct {
+	ID       string    `json:"id"`
+	Expires  time.Time `json:"expires"`
+	Target   string    `json:"target"`
+	Callback string    `json:"callback"`
+}
+
+func (q *Queries) InsertToken(ctx context.Context, arg InsertTokenParams) (Token, error) {
+	row := q.db.QueryRowContext(ctx, insertToken,
+		arg.ID,
+		arg.Expires,
+		arg.Target,
+		arg.Callback,
+	)
+	var i Token
+	err := row.Scan(
+		&i.ID,
+		&i.Expires,
+		&i.Target,
+		&i.Callback,
+		&i.Family,
+		&i.Created,
+		&i.Rotated,
+		&i.UserAgent,
+		&i.Ip,
+	)
+	return i, err
+}
+
+const insertUsedSessionToken = `-- name: InsertUsedSessionToken :execrows
+INSERT INTO used_session_tokens (id, expires_at) VALUES ($1, $2)
+ON CONFLICT (id) DO NOTHING
+`
+
+type InsertUsedSessionTokenParams struct {
+	ID        uuid.UUID `json:"id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Records a session token as used. No row is inserted for a token used
+// before.
+func (q *Queries) InsertUsedSessionToken(ctx context.Context, arg InsertUsedSessionTokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertUsedSessionToken, arg.ID, arg.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const insertUser = `-- name: InsertUser :one
+insert into users (id, email, access, sub, avatar, api_key) values ($1, $2, $3, $4, $5, $6) returning id, created, updated, email, phone, access, sub, avatar, customer_id, subscription_id, subscription_end, api_key, default_agency_id, suspended, suspended_at, suspended_reason
+`
+
+type InsertUserParams struct {
+	ID     uuid.UUID `json:"id"`
+	Email  string    `json:"email"`
+	Access int64     `json:"access"`
+	Sub    string    `json:"sub"`
+	Avatar string    `json:"avatar"`
+	ApiKey string    `json:"api_key"`
+}
+
+func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, insertUser,
+		arg.ID,
+		arg.Email,
+		arg.Access,
+		arg.Sub,
+		arg.Avatar,
+		arg.ApiKey,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Created,
+		&i.Updated,
+		&i.Email,
+		&i.Phone,
+		&i.Access,
+		&i.Sub,
+		&i.Avatar,
+		&i.CustomerID,
+		&i.SubscriptionID,
+		&i.SubscriptionEnd,
+		&i.ApiKey,
+		&i.DefaultAgencyID,
+		&i.Suspended,
+		&i.SuspendedAt,
+		&i.SuspendedReason,
+	)
+	return i, err
+}
+
+const insertUserRecoveryCode = `-- name: InsertUserRecoveryCode :exec
+INSERT INTO user_recovery_codes (id, user_id, code_hash) VALUES ($1, $2, $3)
+`
+
+type InsertUserRecoveryCodeParams struct {
+	ID       uuid.UUID `json:"id"`
+	UserID   uuid.UUID `json:"user_id"`
+	CodeHash string    `json:"code_hash"`
+}
+
+func (q *Queries) InsertUserRecoveryCode(ctx context.Context, arg InsertUserRecoveryCodeParams) error {
+	_, err := q.db.ExecContext(ctx, insertUserRecoveryCode, arg.ID, arg.UserID, arg.CodeHash)
+	return err
+}
+
+const lockAgencyNumbering = `-- name: LockAgencyNumbering :one
+
+SELECT proposal_prefix, next_proposal_number,
+       contract_prefix, next_contract_number,
+       invoice_prefix, next_invoice_number,
+       quotation_prefix, next_quotation_number
+FROM agency_profiles
+WHERE agency_id = $1
+FOR UPDATE
+`
+
+type LockAgencyNumberingRow struct {
+	ProposalPrefix      string `json:"proposal_prefix"`
+	NextProposalNumber  int32  `json:"next_proposal_number"`
+	ContractPrefix      string `json:"contract_prefix"`
+	NextContractNumber  int32  `json:"next_contract_number"`
+	InvoicePrefix       string `json:"invoice_prefix"`
+	NextInvoiceNumber   int32  `json:"next_invoice_number"`
+	QuotationPrefix     string `json:"quotation_prefix"`
+	NextQuotationNumber int32  `json:"next_quotation_number"`
+}
+
+// =============================================================================
+// Document Numbering Queries
+// =============================================================================
+func (q *Queries) LockAgencyNumbering(ctx context.Context, agencyID uuid.UUID) (LockAgencyNumberingRow, error) {
+	row := q.db.QueryRowContext(ctx, lockAgencyNumbering, agencyID)
+	var i LockAgencyNumberingRow
+	err := row.Scan(
+		&i.ProposalPrefix,
+		&i.NextProposalNumber,
+		&i.ContractPrefix,
+		&i.NextContractNumber,
+		&i.InvoicePrefix,
+		&i.NextInvoiceNumber,
+		&i.QuotationPrefix,
+		&i.NextQuotationNumber,
+	)
+	return i, err
+}
+
+const reassignConsultationsClient = `-- name: ReassignConsultationsClient :execrows
+UPDATE consultations
+SET client_id = $1, updated_at = CURRENT_TIMESTAMP
+WHERE client_id = $2
+`
+
+type ReassignConsultationsClientParams struct {
+	ToClientID   uuid.NullUUID `json:"to_client_id"`
+	FromClientID uuid.NullUUID `json:"from_client_id"`
+}
+
+func (q *Queries) ReassignConsultationsClient(ctx context.Context, arg ReassignConsultationsClientParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reassignConsultationsClient, arg.ToClientID, arg.FromClientID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reassignContractsClient = `-- name: ReassignContractsClient :execrows
+UPDATE contracts
+SET client_id = $1, updated_at = CURRENT_TIMESTAMP
+WHERE client_id = $2
+`
+
+type ReassignContractsClientParams struct {
+	ToClientID   uuid.NullUUID `json:"to_client_id"`
+	FromClientID uuid.NullUUID `json:"from_client_id"`
+}
+
+func (q *Queries) ReassignContractsClient(ctx context.Context, arg ReassignContractsClientParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reassignContractsClient, arg.ToClientID, arg.FromClientID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reassignFormSubmissionsClient = `-- name: ReassignFormSubmissionsClient :execrows
+UPDATE form_submissions
+SET client_id = $1
+WHERE client_id = $2
+`
+
+type ReassignFormSubmissionsClientParams struct {
+	ToClientID   uuid.NullUUID `json:"to_client_id"`
+	FromClientID uuid.NullUUID `json:"from_client_id"`
+}
+
+func (q *Queries) ReassignFormSubmissionsClient(ctx context.Context, arg ReassignFormSubmissionsClientParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reassignFormSubmissionsClient, arg.ToClientID, arg.FromClientID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reassignInvoicesClient = `-- name: ReassignInvoicesClient :execrows
+UPDATE invoices
+SET client_id = $1, updated_at = CURRENT_TIMESTAMP
+WHERE client_id = $2
+`
+
+type ReassignInvoicesClientParams struct {
+	ToClientID   uuid.NullUUID `json:"to_client_id"`
+	FromClientID uuid.NullUUID `json:"from_client_id"`
+}
+
+func (q *Queries) ReassignInvoicesClient(ctx context.Context, arg ReassignInvoicesClientParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reassignInvoicesClient, arg.ToClientID, arg.FromClientID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reassignProposalsClient = `-- name: ReassignProposalsClient :execrows
+UPDATE proposals
+SET client_id = $1, updated_at = CURRENT_TIMESTAMP
+WHERE client_id = $2
+`
+
+type ReassignProposalsClientParams struct {
+	ToClientID   uuid.NullUUID `json:"to_client_id"`
+	FromClientID uuid.NullUUID `json:"from_client_id"`
+}
+
+func (q *Queries) ReassignProposalsClient(ctx context.Context, arg ReassignProposalsClientParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reassignProposalsClient, arg.ToClientID, arg.FromClientID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reassignQuotationsClient = `-- name: ReassignQuotationsClient :execrows
+UPDATE quotations
+SET client_id = $1, updated_at = CURRENT_TIMESTAMP
+WHERE client_id = $2
+`
+
+type ReassignQuotationsClientParams struct {
+	ToClientID   uuid.NullUUID `json:"to_client_id"`
+	FromClientID uuid.NullUUID `json:"from_client_id"`
+}
+
+func (q *Queries) ReassignQuotationsClient(ctx context.Context, arg ReassignQuotationsClientParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reassignQuotationsClient, arg.ToClientID, arg.FromClientID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const recordContractView = `-- name: RecordContractView :one
+UPDATE contracts
+SET
+    view_count = view_count + 1,
+    last_viewed_at = CURRENT_TIMESTAMP,
+    status = CASE WHEN status = 'sent' THEN 'viewed' ELSE status END
+WHERE id = $1
+RETURNING id, created_at, updated_at, agency_id, proposal_id, template_id, client_id, contract_number, slug, version, status, client_business_name, client_contact_name, client_email, client_phone, client_address, services_description, commencement_date, completion_date, special_conditions, total_price, price_includes_gst, payment_terms, generated_cover_html, generated_terms_html, generated_schedule_html, valid_until, agency_signatory_name, agency_signatory_title, agency_signed_at, client_signatory_name, client_signatory_title, client_signed_at, client_signature_ip, client_signature_user_agent, view_count, last_viewed_at, sent_at, signed_pdf_url, visible_fields, included_schedule_ids, created_by, agency_signature_ip, agency_signature_user_agent, content_hash
+`
+
+func (q *Queries) RecordContractView(ctx context.Context, id uuid.UUID) (Contract, error) {
+	row := q.db.QueryRowContext(ctx, recordContractView, id)
+	var i Contract
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.TemplateID,
+		&i.ClientID,
+		&i.ContractNumber,
+		&i.Slug,
+		&i.Version,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ServicesDescription,
+		&i.CommencementDate,
+		&i.CompletionDate,
+		&i.SpecialConditions,
+		&i.TotalPrice,
+		&i.PriceIncludesGst,
+		&i.PaymentTerms,
+		&i.GeneratedCoverHtml,
+		&i.GeneratedTermsHtml,
+		&i.GeneratedScheduleHtml,
+		&i.ValidUntil,
+		&i.AgencySignatoryName,
+		&i.AgencySignatoryTitle,
+		&i.AgencySignedAt,
+		&i.ClientSignatoryName,
+		&i.ClientSignatoryTitle,
+		&i.ClientSignedAt,
+		&i.ClientSignatureIp,
+		&i.ClientSignatureUserAgent,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.SignedPdfUrl,
+		&i.VisibleFields,
+		&i.IncludedScheduleIds,
+		&i.CreatedBy,
+		&i.AgencySignatureIp,
+		&i.AgencySignatureUserAgent,
+		&i.ContentHash,
+	)
+	return i, err
+}
+
+const recordInvoicePayment = `-- name: RecordInvoicePayment :one
+UPDATE invoices
+SET
+    status = $1,
+    amount_paid = $2,
+    paid_at = COALESCE($3, paid_at),
+    payment_method = $4,
+    payment_reference = $5,
+    payment_notes = $6,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $7 AND status = $8
+RETURNING id, created_at, updated_at, agency_id, proposal_id, contract_id, client_id, invoice_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_address, client_abn, issue_date, due_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, payment_terms, payment_terms_custom, notes, public_notes, view_count, last_viewed_at, sent_at, paid_at, payment_method, payment_reference, payment_notes, amount_paid, pdf_url, pdf_generated_at, stripe_payment_link_id, stripe_payment_link_url, stripe_payment_intent_id, stripe_checkout_session_id, online_payment_enabled, created_by, quotation_id
+`
+
+type RecordInvoicePaymentParams struct {
+	Status           string         `json:"status"`
+	AmountPaid       money.Money    `json:"amount_paid"`
+	PaidAt           sql.NullTime   `json:"paid_at"`
+	PaymentMethod    sql.NullString `json:"payment_method"`
+	PaymentReference sql.NullString `json:"payment_reference"`
+	PaymentNotes     sql.NullString `json:"payment_notes"`
+	ID               uuid.UUID      `json:"id"`
+	FromStatus       string         `json:"from_status"`
+}
+
+func (q *Queries) RecordInvoicePayment(ctx context.Context, arg RecordInvoicePaymentParams) (Invoice, error) {
+	row := q.db.QueryRowContext(ctx, recordInvoicePayment,
+		arg.Status,
+		arg.AmountPaid,
+		arg.PaidAt,
+		arg.PaymentMethod,
+		arg.PaymentReference,
+		arg.PaymentNotes,
+		arg.ID,
+		arg.FromStatus,
+	)
+	var i Invoice
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.ContractID,
+		&i.ClientID,
+		&i.InvoiceNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ClientAbn,
+		&i.IssueDate,
+		&i.DueDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.PaymentTerms,
+		&i.PaymentTermsCustom,
+		&i.Notes,
+		&i.PublicNotes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.PaidAt,
+		&i.PaymentMethod,
+		&i.PaymentReference,
+		&i.PaymentNotes,
+		&i.AmountPaid,
+		&i.PdfUrl,
+		&i.PdfGeneratedAt,
+		&i.StripePaymentLinkID,
+		&i.StripePaymentLinkUrl,
+		&i.StripePaymentIntentID,
+		&i.StripeCheckoutSessionID,
+		&i.OnlinePaymentEnabled,
+		&i.CreatedBy,
+		&i.QuotationID,
+	)
+	return i, err
+}
+
+const recordInvoiceView = `-- name: RecordInvoiceView :one
+UPDATE invoices
+SET
+    view_count = view_count + 1,
+    last_viewed_at = CURRENT_TIMESTAMP,
+    status = CASE WHEN status = 'sent' THEN 'viewed' ELSE status END
+WHERE id = $1
+RETURNING id, created_at, updated_at, agency_id, proposal_id, contract_id, client_id, invoice_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_address, client_abn, issue_date, due_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, payment_terms, payment_terms_custom, notes, public_notes, view_count, last_viewed_at, sent_at, paid_at, payment_method, payment_reference, payment_notes, amount_paid, pdf_url, pdf_generated_at, stripe_payment_link_id, stripe_payment_link_url, stripe_payment_intent_id, stripe_checkout_session_id, online_payment_enabled, created_by, quotation_id
+`
+
+func (q *Queries) RecordInvoiceView(ctx context.Context, id uuid.UUID) (Invoice, error) {
+	row := q.db.QueryRowContext(ctx, recordInvoiceView, id)
+	var i Invoice
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.ContractID,
+		&i.ClientID,
+		&i.InvoiceNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ClientAbn,
+		&i.IssueDate,
+		&i.DueDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.PaymentTerms,
+		&i.PaymentTermsCustom,
+		&i.Notes,
+		&i.PublicNotes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.PaidAt,
+		&i.PaymentMethod,
+		&i.PaymentReference,
+		&i.PaymentNotes,
+		&i.AmountPaid,
+		&i.PdfUrl,
+		&i.PdfGeneratedAt,
+		&i.StripePaymentLinkID,
+		&i.StripePaymentLinkUrl,
+		&i.StripePaymentIntentID,
+		&i.StripeCheckoutSessionID,
+		&i.OnlinePaymentEnabled,
+		&i.CreatedBy,
+		&i.QuotationID,
+	)
+	return i, err
+}
+
+const recordProposalView = `-- name: RecordProposalView :one
+UPDATE proposals
+SET
+    view_count = view_count + 1,
+    last_viewed_at = CURRENT_TIMESTAMP,
+    status = CASE WHEN status = 'sent' THEN 'viewed' ELSE status END
+WHERE id = $1
+RETURNING id, created_at, updated_at, agency_id, consultation_id, client_id, proposal_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_website, title, cover_image, performance_data, opportunity_content, current_issues, compliance_issues, roi_analysis, performance_standards, local_advantage_content, proposed_pages, timeline, closing_content, selected_package_id, selected_addons, custom_pricing, valid_until, view_count, last_viewed_at, sent_at, accepted_at, declined_at, client_comments, decline_reason, revision_request_notes, revision_requested_at, executive_summary, next_steps, consultation_pain_points, consultation_goals, consultation_challenges, created_by
+`
+
+func (q *Queries) RecordProposalView(ctx context.Context, id uuid.UUID) (Proposal, error) {
+	row := q.db.QueryRowContext(ctx, recordProposalView, id)
+	var i Proposal
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ConsultationID,
+		&i.ClientID,
+		&i.ProposalNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientWebsite,
+		&i.Title,
+		&i.CoverImage,
+		&i.PerformanceData,
+		&i.OpportunityContent,
+		&i.CurrentIssues,
+		&i.ComplianceIssues,
+		&i.RoiAnalysis,
+		&i.PerformanceStandards,
+		&i.LocalAdvantageContent,
+		&i.ProposedPages,
+		&i.Timeline,
+		&i.ClosingContent,
+		&i.SelectedPackageID,
+		&i.SelectedAddons,
+		&i.CustomPricing,
+		&i.ValidUntil,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.AcceptedAt,
+		&i.DeclinedAt,
+		&i.ClientComments,
+		&i.DeclineReason,
+		&i.RevisionRequestNotes,
+		&i.RevisionRequestedAt,
+		&i.ExecutiveSummary,
+		&i.NextSteps,
+		&i.ConsultationPainPoints,
+		&i.ConsultationGoals,
+		&i.ConsultationChallenges,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const recordQuotationView = `-- name: RecordQuotationView :one
+UPDATE quotations
+SET
+    view_count = view_count + 1,
+    last_viewed_at = CURRENT_TIMESTAMP,
+    status = CASE WHEN status = 'sent' THEN 'viewed' ELSE status END
+WHERE id = $1
+RETURNING id, created_at, updated_at, agency_id, client_id, template_id, quotation_number, slug, quotation_name, status, client_business_name, client_contact_name, client_email, client_phone, client_address, site_address, site_reference, prepared_date, expiry_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, terms_blocks, options_notes, notes, view_count, last_viewed_at, sent_at, declined_at, decline_reason, accepted_by_name, accepted_by_title, accepted_at, acceptance_ip, created_by
+`
+
+func (q *Queries) RecordQuotationView(ctx context.Context, id uuid.UUID) (Quotation, error) {
+	row := q.db.QueryRowContext(ctx, recordQuotationView, id)
+	var i Quotation
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ClientID,
+		&i.TemplateID,
+		&i.QuotationNumber,
+		&i.Slug,
+		&i.QuotationName,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.SiteAddress,
+		&i.SiteReference,
+		&i.PreparedDate,
+		&i.ExpiryDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.TermsBlocks,
+		&i.OptionsNotes,
+		&i.Notes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.DeclinedAt,
+		&i.DeclineReason,
+		&i.AcceptedByName,
+		&i.AcceptedByTitle,
+		&i.AcceptedAt,
+		&i.AcceptanceIp,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const recordUserTOTPFailure = `-- name: RecordUserTOTPFailure :exec
+UPDATE user_totp
+SET failed_attempts = CASE WHEN failed_attempts + 1 >= $1::integer THEN 0 ELSE failed_attempts + 1 END,
+    locked_until = CASE WHEN failed_attempts + 1 >= $1::integer THEN $2::timestamptz ELSE locked_until END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $3
+`
+
+type RecordUserTOTPFailureParams struct {
+	MaxAttempts int32     `json:"max_attempts"`
+	LockedUntil time.Time `json:"locked_until"`
+	UserID      uuid.UUID `json:"user_id"`
+}
+
+// Counts an invalid code. The user's codes are locked until locked_until
+// once max_attempts have been invalid in a row, and the count starts over.
+func (q *Queries) RecordUserTOTPFailure(ctx context.Context, arg RecordUserTOTPFailureParams) error {
+	_, err := q.db.ExecContext(ctx, recordUserTOTPFailure, arg.MaxAttempts, arg.LockedUntil, arg.UserID)
+	return err
+}
+
+const resetUserTOTPFailures = `-- name: ResetUserTOTPFailures :exec
+UPDATE user_totp SET failed_attempts = 0, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND failed_attempts > 0
+`
+
+func (q *Queries) ResetUserTOTPFailures(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, resetUserTOTPFailures, userID)
+	return err
+}
+
+const retryEmail = `-- name: RetryEmail :one
+update emails
+set status = 'pending', retry_count = 0, next_attempt_at = current_timestamp, updated = current_timestamp
+where id = $1 and user_id = $2 and status = 'failed'
+returning id, created, updated, user_id, email_to, email_from, email_subject, email_body, status, retry_count, error_message, next_attempt_at, locked_until, sent_at, email_log_id, provider_message_id, agency_id, unsubscribe_token
+`
+
+type RetryEmailParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// Queues a failed email to be sent again, with a fresh set of retries
+func (q *Queries) RetryEmail(ctx context.Context, arg RetryEmailParams) (Email, error) {
+	row := q.db.QueryRowContext(ctx, retryEmail, arg.ID, arg.UserID)
+	var i Email
+	err := row.Scan(
+		&i.ID,
+		&i.Created,
+		&i.Updated,
+		&i.UserID,
+		&i.EmailTo,
+		&i.EmailFrom,
+		&i.EmailSubject,
+		&i.EmailBody,
+		&i.Status,
+		&i.RetryCount,
+		&i.ErrorMessage,
+		&i.NextAttemptAt,
+		&i.LockedUntil,
+		&i.SentAt,
+		&i.EmailLogID,
+		&i.ProviderMessageID,
+		&i.AgencyID,
+		&i.UnsubscribeToken,
+	)
+	return i, err
+}
+
+const revokeAgencyAPIKey = `-- name: RevokeAgencyAPIKey :one
+UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1::uuid AND agency_id = $2::uuid AND revoked_at IS NULL
+RETURNING id, created_at, updated_at, user_id, agency_id, name, prefix, key_hash, access, expires_at, last_used_at, revoked_at
+`
+
+type RevokeAgencyAPIKeyParams struct {
+	ID       uuid.UUID `json:"id"`
+	AgencyID uuid.UUID `json:"agency_id"`
+}
+
+func (q *Queries) RevokeAgencyAPIKey(ctx context.Context, arg RevokeAgencyAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, revokeAgencyAPIKey, arg.ID, arg.AgencyID)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.AgencyID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Access,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const revokeTokenFamily = `-- name: RevokeTokenFamily :exec
+update tokens set target = '' where family = $1 and family <> ''
+`
+
+func (q *Queries) RevokeTokenFamily(ctx context.Context, family string) error {
+	_, err := q.db.ExecContext(ctx, revokeTokenFamily, family)
+	return err
+}
+
+const revokeUserAPIKey = `-- name: RevokeUserAPIKey :one
+UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1::uuid AND user_id = $2::uuid
+    AND agency_id IS NULL AND revoked_at IS NULL
+RETURNING id, created_at, updated_at, user_id, agency_id, name, prefix, key_hash, access, expires_at, last_used_at, revoked_at
+`
+
+type RevokeUserAPIKeyParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) RevokeUserAPIKey(ctx context.Context, arg RevokeUserAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, revokeUserAPIKey, arg.ID, arg.UserID)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.AgencyID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Access,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const revokeUserSession = `-- name: RevokeUserSession :execrows
+update tokens set target = ''
+where family = $1 and family <> '' and target = $2
+`
+
+type RevokeUserSessionParams struct {
+	Family string `json:"family"`
+	UserID string `json:"user_id"`
+}
+
+func (q *Queries) RevokeUserSession(ctx context.Context, arg RevokeUserSessionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeUserSession, arg.Family, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const revokeUserSessions = `-- name: RevokeUserSessions :exec
+update tokens set target = ''
+where target = $1 and family <> '' and family <> $2
+`
+
+type RevokeUserSessionsParams struct {
+	UserID     string `json:"user_id"`
+	KeepFamily string `json:"keep_family"`
+}
+
+// Revokes all of a user's sessions other than the one kept, if any
+func (q *Queries) RevokeUserSessions(ctx context.Context, arg RevokeUserSessionsParams) error {
+	_, err := q.db.ExecContext(ctx, revokeUserSessions, arg.UserID, arg.KeepFamily)
+	return err
+}
+
+const rotateToken = `-- name: RotateToken :one
+update tokens set rotated = current_timestamp
+where id = $1 and rotated is null and target <> ''
+returning id, expires, target, callback, family, created, rotated, user_agent, ip
+`
+
+// Marks a refresh token as exchanged. Only one exchange of a token succeeds.
+func (q *Queries) RotateToken(ctx context.Context, id string) (Token, error) {
+	row := q.db.QueryRowContext(ctx, rotateToken, id)
+	var i Token
+	err := row.Scan(
+		&i.ID,
+		&i.Expires,
+		&i.Target,
+		&i.Callback,
+		&i.Family,
+		&i.Created,
+		&i.Rotated,
+		&i.UserAgent,
+		&i.Ip,
+	)
+	return i, err
+}
+
+const selectAPIKeyByHash = `-- name: SelectAPIKeyByHash :one
+SELECT k.id, k.created_at, k.updated_at, k.user_id, k.agency_id, k.name, k.prefix, k.key_hash, k.access, k.expires_at, k.last_used_at, k.revoked_at, u.email, u.avatar, u.access AS user_access, u.subscription_end, u.suspended
+FROM api_keys k
+JOIN users u ON u.id = k.user_id
+WHERE k.key_hash = $1
+`
+
+type SelectAPIKeyByHashRow struct {
+	ID              uuid.UUID     `json:"id"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+	UserID          uuid.UUID     `json:"user_id"`
+	AgencyID        uuid.NullUUID `json:"agency_id"`
+	Name            string        `json:"name"`
+	Prefix          string        `json:"prefix"`
+	KeyHash         string        `json:"key_hash"`
+	Access          int64         `json:"access"`
+	ExpiresAt       sql.NullTime  `json:"expires_at"`
+	LastUsedAt      sql.NullTime  `json:"last_used_at"`
+	RevokedAt       sql.NullTime  `json:"revoked_at"`
+	Email           string        `json:"email"`
+	Avatar          string        `json:"avatar"`
+	UserAccess      int64         `json:"user_access"`
+	SubscriptionEnd time.Time     `json:"subscription_end"`
+	Suspended       bool          `json:"suspended"`
+}
+
+// The key with the user it acts as
+func (q *Queries) SelectAPIKeyByHash(ctx context.Context, keyHash string) (SelectAPIKeyByHashRow, error) {
+	row := q.db.QueryRowContext(ctx, selectAPIKeyByHash, keyHash)
+	var i SelectAPIKeyByHashRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.AgencyID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Access,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.Email,
+		&i.Avatar,
+		&i.UserAccess,
+		&i.SubscriptionEnd,
+		&i.Suspended,
+	)
+	return i, err
+}
+
+const selectAgency = `-- name: SelectAgency :one
+
+SELECT id, created_at, updated_at, name, slug, logo_url, logo_avatar_url, primary_color, secondary_color, accent_color, accent_gradient, email, phone, website, status, subscription_tier, subscription_id, subscription_end, stripe_customer_id, ai_generations_this_month, ai_generations_reset_at, is_freemium, freemium_reason, freemium_expires_at, freemium_granted_at, freemium_granted_by, deleted_at, deletion_scheduled_for FROM agencies
+WHERE id = $1
+`
+
+// =============================================================================
+// Document Queries
+// =============================================================================
+func (q *Queries) SelectAgency(ctx context.Context, id uuid.UUID) (Agency, error) {
+	row := q.db.QueryRowContext(ctx, selectAgency, id)
+	var i Agency
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Slug,
+		&i.LogoUrl,
+		&i.LogoAvatarUrl,
+		&i.PrimaryColor,
+		&i.SecondaryColor,
+		&i.AccentColor,
+		&i.AccentGradient,
+		&i.Email,
+		&i.Phone,
+		&i.Website,
+		&i.Status,
+		&i.SubscriptionTier,
+		&i.SubscriptionID,
+		&i.SubscriptionEnd,
+		&i.StripeCustomerID,
+		&i.AiGenerationsThisMonth,
+		&i.AiGenerationsResetAt,
+		&i.IsFreemium,
+		&i.FreemiumReason,
+		&i.FreemiumExpiresAt,
+		&i.FreemiumGrantedAt,
+		&i.FreemiumGrantedBy,
+		&i.DeletedAt,
+		&i.DeletionScheduledFor,
+	)
+	return i, err
+}
+
+const selectAgencyAPIKeys = `-- name: SelectAgencyAPIKeys :many
+SELECT id, created_at, updated_at, user_id, agency_id, name, prefix, key_hash, access, expires_at, last_used_at, revoked_at FROM api_keys
+WHERE agency_id = $1::uuid
+ORDER BY created_at DESC
+`
+
+func (q *Queries) SelectAgencyAPIKeys(ctx context.Context, agencyID uuid.UUID) ([]ApiKey, error) {
+	rows, err := q.db.QueryContext(ctx, selectAgencyAPIKeys, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.AgencyID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.Access,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectAgencyAddonsByIDs = `-- name: SelectAgencyAddonsByIDs :many
+SELECT id, created_at, updated_at, agency_id, name, slug, description, price, pricing_type, unit_label, available_packages, display_order, is_active FROM agency_addons
+WHERE agency_id = $1 AND id = ANY($2::uuid[])
+ORDER BY display_order, name
+`
+
+type SelectAgencyAddonsByIDsParams struct {
+	AgencyID uuid.UUID   `json:"agency_id"`
+	Ids      []uuid.UUID `json:"ids"`
+}
+
+func (q *Queries) SelectAgencyAddonsByIDs(ctx context.Context, arg SelectAgencyAddonsByIDsParams) ([]AgencyAddon, error) {
+	rows, err := q.db.QueryContext(ctx, selectAgencyAddonsByIDs, arg.AgencyID, pq.Array(arg.Ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AgencyAddon
+	for rows.Next() {
+		var i AgencyAddon
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AgencyID,
+			&i.Name,
+			&i.Slug,
+			&i.Description,
+			&i.Price,
+			&i.PricingType,
+			&i.UnitLabel,
+			&i.AvailablePackages,
+			&i.DisplayOrder,
+			&i.IsActive,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectAgencyClients = `-- name: SelectAgencyClients :many
+SELECT id, agency_id, business_name, email, phone, contact_name, notes, abn, status, created_by, created_at, updated_at FROM clients
+WHERE agency_id = $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) SelectAgencyClients(ctx context.Context, agencyID uuid.UUID) ([]Client, error) {
+	rows, err := q.db.QueryContext(ctx, selectAgencyClients, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Client
+	for rows.Next() {
+		var i Client
+		if err := rows.Scan(
+			&i.ID,
+			&i.AgencyID,
+			&i.BusinessName,
+			&i.Email,
+			&i.Phone,
+			&i.ContactName,
+			&i.Notes,
+			&i.Abn,
+			&i.Status,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectAgencyDocumentBranding = `-- name: SelectAgencyDocumentBranding :one
+SELECT id, created_at, updated_at, agency_id, document_type, use_custom_branding, logo_url, primary_color, accent_color, accent_gradient FROM agency_document_branding
+WHERE agency_id = $1 AND document_type = $2
+`
+
+type SelectAgencyDocumentBrandingParams struct {
+	AgencyID     uuid.UUID `json:"agency_id"`
+	DocumentType string    `json:"document_type"`
+}
+
+func (q *Queries) SelectAgencyDocumentBranding(ctx context.Context, arg SelectAgencyDocumentBrandingParams) (AgencyDocumentBranding, error) {
+	row := q.db.QueryRowContext(ctx, selectAgencyDocumentBranding, arg.AgencyID, arg.DocumentType)
+	var i AgencyDocumentBranding
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.DocumentType,
+		&i.UseCustomBranding,
+		&i.LogoUrl,
+		&i.PrimaryColor,
+		&i.AccentColor,
+		&i.AccentGradient,
+	)
+	return i, err
+}
+
+const selectAgencyForm = `-- name: SelectAgencyForm :one
+
+SELECT id, agency_id, name, slug, description, form_type, schema, ui_config, branding, is_active, is_default, requires_auth, source_template_id, is_customized, previous_schema, consultation_mapping, version, created_at, updated_at, created_by FROM agency_forms WHERE id = $1
+`
+
+// =============================================================================
+// Form Queries
+// =============================================================================
+func (q *Queries) SelectAgencyForm(ctx context.Context, id uuid.UUID) (AgencyForm, error) {
+	row := q.db.QueryRowContext(ctx, selectAgencyForm, id)
+	var i AgencyForm
+	err := row.Scan(
+		&i.ID,
+		&i.AgencyID,
+		&i.Name,
+		&i.Slug,
+		&i.Description,
+		&i.FormType,
+		&i.Schema,
+		&i.UiConfig,
+		&i.Branding,
+		&i.IsActive,
+		&i.IsDefault,
+		&i.RequiresAuth,
+		&i.SourceTemplateID,
+		&i.IsCustomized,
+		&i.PreviousSchema,
+		&i.ConsultationMapping,
+		&i.Version,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const selectAgencyFormForUpdate = `-- name: SelectAgencyFormForUpdate :one
+SELECT id, agency_id, name, slug, description, form_type, schema, ui_config, branding, is_active, is_default, requires_auth, source_template_id, is_customized, previous_schema, consultation_mapping, version, created_at, updated_at, created_by FROM agency_forms WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) SelectAgencyFormForUpdate(ctx context.Context, id uuid.UUID) (AgencyForm, error) {
+	row := q.db.QueryRowContext(ctx, selectAgencyFormForUpdate, id)
+	var i AgencyForm
+	err := row.Scan(
+		&i.ID,
+		&i.AgencyID,
+		&i.Name,
+		&i.Slug,
+		&i.Description,
+		&i.FormType,
+		&i.Schema,
+		&i.UiConfig,
+		&i.Branding,
+		&i.IsActive,
+		&i.IsDefault,
+		&i.RequiresAuth,
+		&i.SourceTemplateID,
+		&i.IsCustomized,
+		&i.PreviousSchema,
+		&i.ConsultationMapping,
+		&i.Version,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const selectAgencyFormVersion = `-- name: SelectAgencyFormVersion :one
+SELECT id, form_id, agency_id, version, schema, ui_config, changes, created_at, created_by FROM agency_form_versions
+WHERE form_id = $1 AND version = $2
+`
+
+type SelectAgencyFormVersionParams struct {
+	FormID  uuid.UUID `json:"form_id"`
+	Version int32     `json:"version"`
+}
+
+func (q *Queries) SelectAgencyFormVersion(ctx context.Context, arg SelectAgencyFormVersionParams) (AgencyFormVersion, error) {
+	row := q.db.QueryRowContext(ctx, selectAgencyFormVersion, arg.FormID, arg.Version)
+	var i AgencyFormVersion
+	err := row.Scan(
+		&i.ID,
+		&i.FormID,
+		&i.AgencyID,
+		&i.Version,
+		&i.Schema,
+		&i.UiConfig,
+		&i.Changes,
+		&i.CreatedAt,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const selectAgencyFormVersions = `-- name: SelectAgencyFormVersions :many
+SELECT id, form_id, agency_id, version, schema, ui_config, changes, created_at, created_by FROM agency_form_versions
+WHERE form_id = $1
+ORDER BY version DESC
+`
+
+func (q *Queries) SelectAgencyFormVersions(ctx context.Context, formID uuid.UUID) ([]AgencyFormVersion, error) {
+	rows, err := q.db.QueryContext(ctx, selectAgencyFormVersions, formID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AgencyFormVersion
+	for rows.Next() {
+		var i AgencyFormVersion
+		if err := rows.Scan(
+			&i.ID,
+			&i.FormID,
+			&i.AgencyID,
+			&i.Version,
+			&i.Schema,
+			&i.UiConfig,
+			&i.Changes,
+			&i.CreatedAt,
+			&i.CreatedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectAgencyMembership = `-- name: SelectAgencyMembership :one
+
+SELECT m.id, m.created_at, m.updated_at, m.user_id, m.agency_id, m.role, m.display_name, m.status, m.invited_at, m.invited_by, m.accepted_at FROM agency_memberships m
+JOIN agencies a ON a.id = m.agency_id
+WHERE m.user_id = $1 AND m.agency_id = $2
+    AND m.status = 'active' AND a.status = 'active'
+    AND (m.accepted_at IS NOT NULL OR m.invited_at IS NULL)
+`
+
+type SelectAgencyMembershipParams struct {
+	UserID   uuid.UUID `json:"user_id"`
+	AgencyID uuid.UUID `json:"agency_id"`
+}
+
+// =============================================================================
+// Agency Membership Queries
+// =============================================================================
+// A membership counts once it is active in an active agency. Invited
+// members must also have accepted; memberships created directly, such as
+// the founding owner's, need no acceptance.
+func (q *Queries) SelectAgencyMembership(ctx context.Context, arg SelectAgencyMembershipParams) (AgencyMembership, error) {
+	row := q.db.QueryRowContext(ctx, selectAgencyMembership, arg.UserID, arg.AgencyID)
+	var i AgencyMembership
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.AgencyID,
+		&i.Role,
+		&i.DisplayName,
+		&i.Status,
+		&i.InvitedAt,
+		&i.InvitedBy,
+		&i.AcceptedAt,
+	)
+	return i, err
+}
+
+const selectAgencyNumbering = `-- name: SelectAgencyNumbering :one
+SELECT proposal_prefix, next_proposal_number,
+       contract_prefix, next_contract_number,
+       invoice_prefix, next_invoice_number,
+       quotation_prefix, next_quotation_number
+FROM agency_profiles
+WHERE agency_id = $1
+`
+
+type SelectAgencyNumberingRow struct {
+	ProposalPrefix      string `json:"proposal_prefix"`
+	NextProposalNumber  int32  `json:"next_proposal_number"`
+	ContractPrefix      string `json:"contract_prefix"`
+	NextContractNumber  int32  `json:"next_contract_number"`
+	InvoicePrefix       string `json:"invoice_prefix"`
+	NextInvoiceNumber   int32  `json:"next_invoice_number"`
+	QuotationPrefix     string `json:"quotation_prefix"`
+	NextQuotationNumber int32  `json:"next_quotation_number"`
+}
+
+func (q *Queries) SelectAgencyNumbering(ctx context.Context, agencyID uuid.UUID) (SelectAgencyNumberingRow, error) {
+	row := q.db.QueryRowContext(ctx, selectAgencyNumbering, agencyID)
+	var i SelectAgencyNumberingRow
+	err := row.Scan(
+		&i.ProposalPrefix,
+		&i.NextProposalNumber,
+		&i.ContractPrefix,
+		&i.NextContractNumber,
+		&i.InvoicePrefix,
+		&i.NextInvoiceNumber,
+		&i.QuotationPrefix,
+		&i.NextQuotationNumber,
+	)
+	return i, err
+}
+
+const selectAgencyOwnerID = `-- name: SelectAgencyOwnerID :one
+SELECT user_id FROM agency_memberships
+WHERE agency_id = $1 AND role = 'owner' AND status = 'active'
+ORDER BY created_at
+LIMIT 1
+`
+
+func (q *Queries) SelectAgencyOwnerID(ctx context.Context, agencyID uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, selectAgencyOwnerID, agencyID)
+	var user_id uuid.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const selectAgencyPackage = `-- name: SelectAgencyPackage :one
+SELECT id, created_at, updated_at, agency_id, name, slug, description, pricing_model, setup_fee, monthly_price, one_time_price, hosting_fee, minimum_term_months, cancellation_fee_type, cancellation_fee_amount, included_features, max_pages, display_order, is_featured, is_active FROM agency_packages
+WHERE id = $1
+`
+
+func (q *Queries) SelectAgencyPackage(ctx context.Context, id uuid.UUID) (AgencyPackage, error) {
+	row := q.db.QueryRowContext(ctx, selectAgencyPackage, id)
+	var i AgencyPackage
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.Name,
+		&i.Slug,
+		&i.Description,
+		&i.PricingModel,
+		&i.SetupFee,
+		&i.MonthlyPrice,
+		&i.OneTimePrice,
+		&i.HostingFee,
+		&i.MinimumTermMonths,
+		&i.CancellationFeeType,
+		&i.CancellationFeeAmount,
+		&i.IncludedFeatures,
+		&i.MaxPages,
+		&i.DisplayOrder,
+		&i.IsFeatured,
+		&i.IsActive,
+	)
+	return i, err
+}
+
+const selectAgencyProfile = `-- name: SelectAgencyProfile :one
+
+SELECT id, created_at, updated_at, agency_id, abn, acn, legal_entity_name, trading_name, address_line_1, address_line_2, city, state, postcode, country, bank_name, bsb, account_number, account_name, gst_registered, tax_file_number, gst_rate, tagline, social_linkedin, social_facebook, social_instagram, social_twitter, brand_font, default_payment_terms, invoice_prefix, invoice_footer, next_invoice_number, contract_prefix, contract_footer, next_contract_number, proposal_prefix, next_proposal_number, quotation_prefix, next_quotation_number, default_quotation_validity_days, stripe_account_id, stripe_account_status, stripe_onboarding_complete, stripe_connected_at, stripe_payouts_enabled, stripe_charges_enabled FROM agency_profiles
+WHERE agency_id = $1
+`
+
+// =============================================================================
+// Agency Package & Pricing Queries
+// =============================================================================
+func (q *Queries) SelectAgencyProfile(ctx context.Context, agencyID uuid.UUID) (AgencyProfile, error) {
+	row := q.db.QueryRowContext(ctx, selectAgencyProfile, agencyID)
+	var i AgencyProfile
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.Abn,
+		&i.Acn,
+		&i.LegalEntityName,
+		&i.TradingName,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.City,
+		&i.State,
+		&i.Postcode,
+		&i.Country,
+		&i.BankName,
+		&i.Bsb,
+		&i.AccountNumber,
+		&i.AccountName,
+		&i.GstRegistered,
+		&i.TaxFileNumber,
+		&i.GstRate,
+		&i.Tagline,
+		&i.SocialLinkedin,
+		&i.SocialFacebook,
+		&i.SocialInstagram,
+		&i.SocialTwitter,
+		&i.BrandFont,
+		&i.DefaultPaymentTerms,
+		&i.InvoicePrefix,
+		&i.InvoiceFooter,
+		&i.NextInvoiceNumber,
+		&i.ContractPrefix,
+		&i.ContractFooter,
+		&i.NextContractNumber,
+		&i.ProposalPrefix,
+		&i.NextProposalNumber,
+		&i.QuotationPrefix,
+		&i.NextQuotationNumber,
+		&i.DefaultQuotationValidityDays,
+		&i.StripeAccountID,
+		&i.StripeAccountStatus,
+		&i.StripeOnboardingComplete,
+		&i.StripeConnectedAt,
+		&i.StripePayoutsEnabled,
+		&i.StripeChargesEnabled,
+	)
+	return i, err
+}
+
+const selectClient = `-- name: SelectClient :one
+SELECT id, agency_id, business_name, email, phone, contact_name, notes, abn, status, created_by, created_at, updated_at FROM clients
+WHERE id = $1
+`
+
+func (q *Queries) SelectClient(ctx context.Context, id uuid.UUID) (Client, error) {
+	row := q.db.QueryRowContext(ctx, selectClient, id)
+	var i Client
+	err := row.Scan(
+		&i.ID,
+		&i.AgencyID,
+		&i.BusinessName,
+		&i.Email,
+		&i.Phone,
+		&i.ContactName,
+		&i.Notes,
+		&i.Abn,
+		&i.Status,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const selectClientByEmail = `-- name: SelectClientByEmail :one
+SELECT id, agency_id, business_name, email, phone, contact_name, notes, abn, status, created_by, created_at, updated_at FROM clients
+WHERE agency_id = $1 AND lower(email) = lower($2::text)
+`
+
+type SelectClientByEmailParams struct {
+	AgencyID uuid.UUID `json:"agency_id"`
+	Email    string    `json:"email"`
+}
+
+func (q *Queries) SelectClientByEmail(ctx context.Context, arg SelectClientByEmailParams) (Client, error) {
+	row := q.db.QueryRowContext(ctx, selectClientByEmail, arg.AgencyID, arg.Email)
+	var i Client
+	err := row.Scan(
+		&i.ID,
+		&i.AgencyID,
+		&i.BusinessName,
+		&i.Email,
+		&i.Phone,
+		&i.ContactName,
+		&i.Notes,
+		&i.Abn,
+		&i.Status,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const selectClients = `-- name: SelectClients :many
+SELECT id, agency_id, business_name, email, phone, contact_name, notes, abn, status, created_by, created_at, updated_at FROM clients
+WHERE agency_id = $1
+  AND ($2::text = '' OR status = $2::text)
+  AND (
+    $3::text = ''
+    OR business_name ILIKE '%' || $3::text || '%'
+    OR email ILIKE '%' || $3::text || '%'
+    OR contact_name ILIKE '%' || $3::text || '%'
+  )
+ORDER BY business_name ASC
+LIMIT $5 OFFSET $4
+`
+
+type SelectClientsParams struct {
+	AgencyID  uuid.UUID `json:"agency_id"`
+	Status    string    `json:"status"`
+	Search    string    `json:"search"`
+	RowOffset int32     `json:"row_offset"`
+	RowLimit  int32     `json:"row_limit"`
+}
+
+func (q *Queries) SelectClients(ctx context.Context, arg SelectClientsParams) ([]Client, error) {
+	rows, err := q.db.QueryContext(ctx, selectClients,
+		arg.AgencyID,
+		arg.Status,
+		arg.Search,
+		arg.RowOffset,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Client
+	for rows.Next() {
+		var i Client
+		if err := rows.Scan(
+			&i.ID,
+			&i.AgencyID,
+			&i.BusinessName,
+			&i.Email,
+			&i.Phone,
+			&i.ContactName,
+			&i.Notes,
+			&i.Abn,
+			&i.Status,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectConsultation = `-- name: SelectConsultation :one
+
+SELECT id, user_id, agency_id, business_name, contact_person, email, phone, website, social_linkedin, social_facebook, social_instagram, industry, business_type, website_status, primary_challenges, urgency_level, primary_goals, conversion_goal, budget_range, timeline, design_styles, admired_websites, consultation_notes, created_by, performance_data, client_id, custom_data, form_id, status, completion_percentage, created_at, updated_at, completed_at FROM consultations
+WHERE id = $1
+`
+
+// =============================================================================
+// Consultation Queries
+// =============================================================================
+func (q *Queries) SelectConsultation(ctx context.Context, id uuid.UUID) (Consultation, error) {
+	row := q.db.QueryRowContext(ctx, selectConsultation, id)
+	var i Consultation
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.AgencyID,
+		&i.BusinessName,
+		&i.ContactPerson,
+		&i.Email,
+		&i.Phone,
+		&i.Website,
+		&i.SocialLinkedin,
+		&i.SocialFacebook,
+		&i.SocialInstagram,
+		&i.Industry,
+		&i.BusinessType,
+		&i.WebsiteStatus,
+		&i.PrimaryChallenges,
+		&i.UrgencyLevel,
+		&i.PrimaryGoals,
+		&i.ConversionGoal,
+		&i.BudgetRange,
+		&i.Timeline,
+		&i.DesignStyles,
+		&i.AdmiredWebsites,
+		&i.ConsultationNotes,
+		&i.CreatedBy,
+		&i.PerformanceData,
+		&i.ClientID,
+		&i.CustomData,
+		&i.FormID,
+		&i.Status,
+		&i.CompletionPercentage,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const selectConsultationForUpdate = `-- name: SelectConsultationForUpdate :one
+SELECT id, user_id, agency_id, business_name, contact_person, email, phone, website, social_linkedin, social_facebook, social_instagram, industry, business_type, website_status, primary_challenges, urgency_level, primary_goals, conversion_goal, budget_range, timeline, design_styles, admired_websites, consultation_notes, created_by, performance_data, client_id, custom_data, form_id, status, completion_percentage, created_at, updated_at, completed_at FROM consultations
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) SelectConsultationForUpdate(ctx context.Context, id uuid.UUID) (Consultation, error) {
+	row := q.db.QueryRowContext(ctx, selectConsultationForUpdate, id)
+	var i Consultation
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.AgencyID,
+		&i.BusinessName,
+		&i.ContactPerson,
+		&i.Email,
+		&i.Phone,
+		&i.Website,
+		&i.SocialLinkedin,
+		&i.SocialFacebook,
+		&i.SocialInstagram,
+		&i.Industry,
+		&i.BusinessType,
+		&i.WebsiteStatus,
+		&i.PrimaryChallenges,
+		&i.UrgencyLevel,
+		&i.PrimaryGoals,
+		&i.ConversionGoal,
+		&i.BudgetRange,
+		&i.Timeline,
+		&i.DesignStyles,
+		&i.AdmiredWebsites,
+		&i.ConsultationNotes,
+		&i.CreatedBy,
+		&i.PerformanceData,
+		&i.ClientID,
+		&i.CustomData,
+		&i.FormID,
+		&i.Status,
+		&i.CompletionPercentage,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const selectConsultationVersion = `-- name: SelectConsultationVersion :one
+SELECT id, consultation_id, user_id, agency_id, version_number, status, completion_percentage, change_summary, changed_fields, snapshot, created_at FROM consultation_versions
+WHERE consultation_id = $1 AND version_number = $2
+`
+
+type SelectConsultationVersionParams struct {
+	ConsultationID uuid.UUID `json:"consultation_id"`
+	VersionNumber  int32     `json:"version_number"`
+}
+
+func (q *Queries) SelectConsultationVersion(ctx context.Context, arg SelectConsultationVersionParams) (ConsultationVersion, error) {
+	row := q.db.QueryRowContext(ctx, selectConsultationVersion, arg.ConsultationID, arg.VersionNumber)
+	var i ConsultationVersion
+	err := row.Scan(
+		&i.ID,
+		&i.ConsultationID,
+		&i.UserID,
+		&i.AgencyID,
+		&i.VersionNumber,
+		&i.Status,
+		&i.CompletionPercentage,
+		&i.ChangeSummary,
+		&i.ChangedFields,
+		&i.Snapshot,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const selectConsultationVersions = `-- name: SelectConsultationVersions :many
+SELECT id, consultation_id, user_id, agency_id, version_number, status, completion_percentage, change_summary, changed_fields, snapshot, created_at FROM consultation_versions
+WHERE consultation_id = $1
+ORDER BY version_number DESC
+`
+
+func (q *Queries) SelectConsultationVersions(ctx context.Context, consultationID uuid.UUID) ([]ConsultationVersion, error) {
+	rows, err := q.db.QueryContext(ctx, selectConsultationVersions, consultationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ConsultationVersion
+	for rows.Next() {
+		var i ConsultationVersion
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConsultationID,
+			&i.UserID,
+			&i.AgencyID,
+			&i.VersionNumber,
+			&i.Status,
+			&i.CompletionPercentage,
+			&i.ChangeSummary,
+			&i.ChangedFields,
+			&i.Snapshot,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectConsultations = `-- name: SelectConsultations :many
+SELECT id, user_id, agency_id, business_name, contact_person, email, phone, website, social_linkedin, social_facebook, social_instagram, industry, business_type, website_status, primary_challenges, urgency_level, primary_goals, conversion_goal, budget_range, timeline, design_styles, admired_websites, consultation_notes, created_by, performance_data, client_id, custom_data, form_id, status, completion_percentage, created_at, updated_at, completed_at FROM consultations
+WHERE agency_id = $1
+  AND ($2::text = '' OR status = $2::text)
+ORDER BY updated_at DESC
+LIMIT $4 OFFSET $3
+`
+
+type SelectConsultationsParams struct {
+	AgencyID  uuid.UUID `json:"agency_id"`
+	Status    string    `json:"status"`
+	RowOffset int32     `json:"row_offset"`
+	RowLimit  int32     `json:"row_limit"`
+}
+
+func (q *Queries) SelectConsultations(ctx context.Context, arg SelectConsultationsParams) ([]Consultation, error) {
+	rows, err := q.db.QueryContext(ctx, selectConsultations,
+		arg.AgencyID,
+		arg.Status,
+		arg.RowOffset,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Consultation
+	for rows.Next() {
+		var i Consultation
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.AgencyID,
+			&i.BusinessName,
+			&i.ContactPerson,
+			&i.Email,
+			&i.Phone,
+			&i.Website,
+			&i.SocialLinkedin,
+			&i.SocialFacebook,
+			&i.SocialInstagram,
+			&i.Industry,
+			&i.BusinessType,
+			&i.WebsiteStatus,
+			&i.PrimaryChallenges,
+			&i.UrgencyLevel,
+			&i.PrimaryGoals,
+			&i.ConversionGoal,
+			&i.BudgetRange,
+			&i.Timeline,
+			&i.DesignStyles,
+			&i.AdmiredWebsites,
+			&i.ConsultationNotes,
+			&i.CreatedBy,
+			&i.PerformanceData,
+			&i.ClientID,
+			&i.CustomData,
+			&i.FormID,
+			&i.Status,
+			&i.CompletionPercentage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectContract = `-- name: SelectContract :one
+SELECT id, created_at, updated_at, agency_id, proposal_id, template_id, client_id, contract_number, slug, version, status, client_business_name, client_contact_name, client_email, client_phone, client_address, services_description, commencement_date, completion_date, special_conditions, total_price, price_includes_gst, payment_terms, generated_cover_html, generated_terms_html, generated_schedule_html, valid_until, agency_signatory_name, agency_signatory_title, agency_signed_at, client_signatory_name, client_signatory_title, client_signed_at, client_signature_ip, client_signature_user_agent, view_count, last_viewed_at, sent_at, signed_pdf_url, visible_fields, included_schedule_ids, created_by, agency_signature_ip, agency_signature_user_agent, content_hash FROM contracts
+WHERE id = $1
+`
+
+func (q *Queries) SelectContract(ctx context.Context, id uuid.UUID) (Contract, error) {
+	row := q.db.QueryRowContext(ctx, selectContract, id)
+	var i Contract
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.TemplateID,
+		&i.ClientID,
+		&i.ContractNumber,
+		&i.Slug,
+		&i.Version,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ServicesDescription,
+		&i.CommencementDate,
+		&i.CompletionDate,
+		&i.SpecialConditions,
+		&i.TotalPrice,
+		&i.PriceIncludesGst,
+		&i.PaymentTerms,
+		&i.GeneratedCoverHtml,
+		&i.GeneratedTermsHtml,
+		&i.GeneratedScheduleHtml,
+		&i.ValidUntil,
+		&i.AgencySignatoryName,
+		&i.AgencySignatoryTitle,
+		&i.AgencySignedAt,
+		&i.ClientSignatoryName,
+		&i.ClientSignatoryTitle,
+		&i.ClientSignedAt,
+		&i.ClientSignatureIp,
+		&i.ClientSignatureUserAgent,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.SignedPdfUrl,
+		&i.VisibleFields,
+		&i.IncludedScheduleIds,
+		&i.CreatedBy,
+		&i.AgencySignatureIp,
+		&i.AgencySignatureUserAgent,
+		&i.ContentHash,
+	)
+	return i, err
+}
+
+const selectContractBySlug = `-- name: SelectContractBySlug :one
+SELECT id, created_at, updated_at, agency_id, proposal_id, template_id, client_id, contract_number, slug, version, status, client_business_name, client_contact_name, client_email, client_phone, client_address, services_description, commencement_date, completion_date, special_conditions, total_price, price_includes_gst, payment_terms, generated_cover_html, generated_terms_html, generated_schedule_html, valid_until, agency_signatory_name, agency_signatory_title, agency_signed_at, client_signatory_name, client_signatory_title, client_signed_at, client_signature_ip, client_signature_user_agent, view_count, last_viewed_at, sent_at, signed_pdf_url, visible_fields, included_schedule_ids, created_by, agency_signature_ip, agency_signature_user_agent, content_hash FROM contracts
+WHERE slug = $1
+`
+
+func (q *Queries) SelectContractBySlug(ctx context.Context, slug string) (Contract, error) {
+	row := q.db.QueryRowContext(ctx, selectContractBySlug, slug)
+	var i Contract
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.TemplateID,
+		&i.ClientID,
+		&i.ContractNumber,
+		&i.Slug,
+		&i.Version,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ServicesDescription,
+		&i.CommencementDate,
+		&i.CompletionDate,
+		&i.SpecialConditions,
+		&i.TotalPrice,
+		&i.PriceIncludesGst,
+		&i.PaymentTerms,
+		&i.GeneratedCoverHtml,
+		&i.GeneratedTermsHtml,
+		&i.GeneratedScheduleHtml,
+		&i.ValidUntil,
+		&i.AgencySignatoryName,
+		&i.AgencySignatoryTitle,
+		&i.AgencySignedAt,
+		&i.ClientSignatoryName,
+		&i.ClientSignatoryTitle,
+		&i.ClientSignedAt,
+		&i.ClientSignatureIp,
+		&i.ClientSignatureUserAgent,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.SignedPdfUrl,
+		&i.VisibleFields,
+		&i.IncludedScheduleIds,
+		&i.CreatedBy,
+		&i.AgencySignatureIp,
+		&i.AgencySignatureUserAgent,
+		&i.ContentHash,
+	)
+	return i, err
+}
+
+const selectContractSchedules = `-- name: SelectContractSchedules :many
+SELECT id, created_at, updated_at, template_id, package_id, name, display_order, section_category, content, is_active FROM contract_schedules
+WHERE template_id = $1 AND is_active = true
+ORDER BY display_order, name
+`
+
+func (q *Queries) SelectContractSchedules(ctx context.Context, templateID uuid.UUID) ([]ContractSchedule, error) {
+	rows, err := q.db.QueryContext(ctx, selectContractSchedules, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContractSchedule
+	for rows.Next() {
+		var i ContractSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TemplateID,
+			&i.PackageID,
+			&i.Name,
+			&i.DisplayOrder,
+			&i.SectionCategory,
+			&i.Content,
+			&i.IsActive,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectContractSignatures = `-- name: SelectContractSignatures :many
+SELECT id, created_at, contract_id, agency_id, party, signatory_name, signatory_title, signed_at, ip_address, user_agent, user_id, content_hash, previous_hash, signature_hash FROM contract_signatures
+WHERE contract_id = $1
+ORDER BY signed_at, created_at
+`
+
+func (q *Queries) SelectContractSignatures(ctx context.Context, contractID uuid.UUID) ([]ContractSignature, error) {
+	rows, err := q.db.QueryContext(ctx, selectContractSignatures, contractID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContractSignature
+	for rows.Next() {
+		var i ContractSignature
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.ContractID,
+			&i.AgencyID,
+			&i.Party,
+			&i.SignatoryName,
+			&i.SignatoryTitle,
+			&i.SignedAt,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.UserID,
+			&i.ContentHash,
+			&i.PreviousHash,
+			&i.SignatureHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectContractTemplate = `-- name: SelectContractTemplate :one
+SELECT id, created_at, updated_at, agency_id, name, description, version, cover_page_config, terms_content, signature_config, is_default, is_active, created_by FROM contract_templates
+WHERE id = $1
+`
+
+func (q *Queries) SelectContractTemplate(ctx context.Context, id uuid.UUID) (ContractTemplate, error) {
+	row := q.db.QueryRowContext(ctx, selectContractTemplate, id)
+	var i ContractTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.Name,
+		&i.Description,
+		&i.Version,
+		&i.CoverPageConfig,
+		&i.TermsContent,
+		&i.SignatureConfig,
+		&i.IsDefault,
+		&i.IsActive,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const selectContracts = `-- name: SelectContracts :many
+SELECT id, created_at, updated_at, agency_id, proposal_id, template_id, client_id, contract_number, slug, version, status, client_business_name, client_contact_name, client_email, client_phone, client_address, services_description, commencement_date, completion_date, special_conditions, total_price, price_includes_gst, payment_terms, generated_cover_html, generated_terms_html, generated_schedule_html, valid_until, agency_signatory_name, agency_signatory_title, agency_signed_at, client_signatory_name, client_signatory_title, client_signed_at, client_signature_ip, client_signature_user_agent, view_count, last_viewed_at, sent_at, signed_pdf_url, visible_fields, included_schedule_ids, created_by, agency_signature_ip, agency_signature_user_agent, content_hash FROM contracts
+WHERE agency_id = $1
+  AND ($2::text = '' OR status = $2::text)
+ORDER BY created_at DESC
+LIMIT $4 OFFSET $3
+`
+
+type SelectContractsParams struct {
+	AgencyID  uuid.UUID `json:"agency_id"`
+	Status    string    `json:"status"`
+	RowOffset int32     `json:"row_offset"`
+	RowLimit  int32     `json:"row_limit"`
+}
+
+func (q *Queries) SelectContracts(ctx context.Context, arg SelectContractsParams) ([]Contract, error) {
+	rows, err := q.db.QueryContext(ctx, selectContracts,
+		arg.AgencyID,
+		arg.Status,
+		arg.RowOffset,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Contract
+	for rows.Next() {
+		var i Contract
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AgencyID,
+			&i.ProposalID,
+			&i.TemplateID,
+			&i.ClientID,
+			&i.ContractNumber,
+			&i.Slug,
+			&i.Version,
+			&i.Status,
+			&i.ClientBusinessName,
+			&i.ClientContactName,
+			&i.ClientEmail,
+			&i.ClientPhone,
+			&i.ClientAddress,
+			&i.ServicesDescription,
+			&i.CommencementDate,
+			&i.CompletionDate,
+			&i.SpecialConditions,
+			&i.TotalPrice,
+			&i.PriceIncludesGst,
+			&i.PaymentTerms,
+			&i.GeneratedCoverHtml,
+			&i.GeneratedTermsHtml,
+			&i.GeneratedScheduleHtml,
+			&i.ValidUntil,
+			&i.AgencySignatoryName,
+			&i.AgencySignatoryTitle,
+			&i.AgencySignedAt,
+			&i.ClientSignatoryName,
+			&i.ClientSignatoryTitle,
+			&i.ClientSignedAt,
+			&i.ClientSignatureIp,
+			&i.ClientSignatureUserAgent,
+			&i.ViewCount,
+			&i.LastViewedAt,
+			&i.SentAt,
+			&i.SignedPdfUrl,
+			&i.VisibleFields,
+			&i.IncludedScheduleIds,
+			&i.CreatedBy,
+			&i.AgencySignatureIp,
+			&i.AgencySignatureUserAgent,
+			&i.ContentHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectDefaultAgencyMembership = `-- name: SelectDefaultAgencyMembership :one
+SELECT m.id, m.created_at, m.updated_at, m.user_id, m.agency_id, m.role, m.display_name, m.status, m.invited_at, m.invited_by, m.accepted_at FROM agency_memberships m
+JOIN agencies a ON a.id = m.agency_id
+JOIN users u ON u.id = m.user_id
+WHERE m.user_id = $1
+    AND m.status = 'active' AND a.status = 'active'
+    AND (m.accepted_at IS NOT NULL OR m.invited_at IS NULL)
+ORDER BY
+    (m.agency_id = u.default_agency_id) IS TRUE DESC,
+    CASE m.role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 ELSE 2 END,
+    m.created_at
+LIMIT 1
+`
+
+// The agency a user works in by default: their default agency, else the
+// one where they hold the highest role
+func (q *Queries) SelectDefaultAgencyMembership(ctx context.Context, userID uuid.UUID) (AgencyMembership, error) {
+	row := q.db.QueryRowContext(ctx, selectDefaultAgencyMembership, userID)
+	var i AgencyMembership
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.AgencyID,
+		&i.Role,
+		&i.DisplayName,
+		&i.Status,
+		&i.InvitedAt,
+		&i.InvitedBy,
+		&i.AcceptedAt,
+	)
+	return i, err
+}
+
+const selectDefaultContractTemplate = `-- name: SelectDefaultContractTemplate :one
+SELECT id, created_at, updated_at, agency_id, name, description, version, cover_page_config, terms_content, signature_config, is_default, is_active, created_by FROM contract_templates
+WHERE agency_id = $1 AND is_default = true AND is_active = true
+ORDER BY updated_at DESC
+LIMIT 1
+`
+
+func (q *Queries) SelectDefaultContractTemplate(ctx context.Context, agencyID uuid.UUID) (ContractTemplate, error) {
+	row := q.db.QueryRowContext(ctx, selectDefaultContractTemplate, agencyID)
+	var i ContractTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.Name,
+		&i.Description,
+		&i.Version,
+		&i.CoverPageConfig,
+		&i.TermsContent,
+		&i.SignatureConfig,
+		&i.IsDefault,
+		&i.IsActive,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const selectDocumentNumbering = `-- name: SelectDocumentNumbering :one
+SELECT id, created_at, updated_at, agency_id, document_type, format, reset_yearly, sequence_year FROM agency_document_numbering
+WHERE agency_id = $1 AND document_type = $2
+`
+
+type SelectDocumentNumberingParams struct {
+	AgencyID     uuid.UUID `json:"agency_id"`
+	DocumentType string    `json:"document_type"`
+}
+
+func (q *Queries) SelectDocumentNumbering(ctx context.Context, arg SelectDocumentNumberingParams) (AgencyDocumentNumbering, error) {
+	row := q.db.QueryRowContext(ctx, selectDocumentNumbering, arg.AgencyID, arg.DocumentType)
+	var i AgencyDocumentNumbering
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.DocumentType,
+		&i.Format,
+		&i.ResetYearly,
+		&i.SequenceYear,
+	)
+	return i, err
+}
+
+const selectDocumentNumberings = `-- name: SelectDocumentNumberings :many
+SELECT id, created_at, updated_at, agency_id, document_type, format, reset_yearly, sequence_year FROM agency_document_numbering
+WHERE agency_id = $1
+ORDER BY document_type
+`
+
+func (q *Queries) SelectDocumentNumberings(ctx context.Context, agencyID uuid.UUID) ([]AgencyDocumentNumbering, error) {
+	rows, err := q.db.QueryContext(ctx, selectDocumentNumberings, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AgencyDocumentNumbering
+	for rows.Next() {
+		var i AgencyDocumentNumbering
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AgencyID,
+			&i.DocumentType,
+			&i.Format,
+			&i.ResetYearly,
+			&i.SequenceYear,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectDocumentNumbers = `-- name: SelectDocumentNumbers :many
+SELECT proposal_number AS number FROM proposals
+WHERE $1::text = 'proposal' AND proposals.agency_id = $2::uuid
+UNION ALL
+SELECT contract_number FROM contracts
+WHERE $1::text = 'contract' AND contracts.agency_id = $2::uuid
+UNION ALL
+SELECT invoice_number FROM invoices
+WHERE $1::text = 'invoice' AND invoices.agency_id = $2::uuid
+UNION ALL
+SELECT quotation_number FROM quotations
+WHERE $1::text = 'quotation' AND quotations.agency_id = $2::uuid
+ORDER BY number
+`
+
+type SelectDocumentNumbersParams struct {
+	DocumentType string    `json:"document_type"`
+	AgencyID     uuid.UUID `json:"agency_id"`
+}
+
+func (q *Queries) SelectDocumentNumbers(ctx context.Context, arg SelectDocumentNumbersParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, selectDocumentNumbers, arg.DocumentType, arg.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			return nil, err
+		}
+		items = append(items, number)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectDraftFormSubmissions = `-- name: SelectDraftFormSubmissions :many
+SELECT id, form_id, agency_id, slug, client_id, client_business_name, client_email, data, current_step, completion_percentage, started_at, last_activity_at, consultation_id, proposal_id, contract_id, metadata, status, created_at, submitted_at, processed_at, form_version, processing_attempts, processing_error FROM form_submissions
+WHERE form_id = $1::uuid AND status = 'draft'
+ORDER BY created_at
+FOR UPDATE
+`
+
+func (q *Queries) SelectDraftFormSubmissions(ctx context.Context, formID uuid.UUID) ([]FormSubmission, error) {
+	rows, err := q.db.QueryContext(ctx, selectDraftFormSubmissions, formID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FormSubmission
+	for rows.Next() {
+		var i FormSubmission
+		if err := rows.Scan(
+			&i.ID,
+			&i.FormID,
+			&i.AgencyID,
+			&i.Slug,
+			&i.ClientID,
+			&i.ClientBusinessName,
+			&i.ClientEmail,
+			&i.Data,
+			&i.CurrentStep,
+			&i.CompletionPercentage,
+			&i.StartedAt,
+			&i.LastActivityAt,
+			&i.ConsultationID,
+			&i.ProposalID,
+			&i.ContractID,
+			&i.Metadata,
+			&i.Status,
+			&i.CreatedAt,
+			&i.SubmittedAt,
+			&i.ProcessedAt,
+			&i.FormVersion,
+			&i.ProcessingAttempts,
+			&i.ProcessingError,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectEmailAttachments = `-- name: SelectEmailAttachments :many
+select id, created, email_id, file_name, content_type, file_id from email_attachments where email_id = $1
+`
+
+func (q *Queries) SelectEmailAttachments(ctx context.Context, emailID uuid.UUID) ([]EmailAttachment, error) {
+	rows, err := q.db.QueryContext(ctx, selectEmailAttachments, emailID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EmailAttachment
+	for rows.Next() {
+		var i EmailAttachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.Created,
+			&i.EmailID,
+			&i.FileName,
+			&i.ContentType,
+			&i.FileID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectEmailByUnsubscribeToken = `-- name: SelectEmailByUnsubscribeToken :one
+SELECT id, created, updated, user_id, email_to, email_from, email_subject, email_body, status, retry_count, error_message, next_attempt_at, locked_until, sent_at, email_log_id, provider_message_id, agency_id, unsubscribe_token FROM emails WHERE unsubscribe_token = $1
+`
+
+func (q *Queries) SelectEmailByUnsubscribeToken(ctx context.Context, unsubscribeToken sql.NullString) (Email, error) {
+	row := q.db.QueryRowContext(ctx, selectEmailByUnsubscribeToken, unsubscribeToken)
+	var i Email
+	err := row.Scan(
+		&i.ID,
+		&i.Created,
+		&i.Updated,
+		&i.UserID,
+		&i.EmailTo,
+		&i.EmailFrom,
+		&i.EmailSubject,
+		&i.EmailBody,
+		&i.Status,
+		&i.RetryCount,
+		&i.ErrorMessage,
+		&i.NextAttemptAt,
+		&i.LockedUntil,
+		&i.SentAt,
+		&i.EmailLogID,
+		&i.ProviderMessageID,
+		&i.AgencyID,
+		&i.UnsubscribeToken,
+	)
+	return i, err
+}
+
+const selectEmailLogAgencyID = `-- name: SelectEmailLogAgencyID :one
+select agency_id from email_logs where id = $1
+`
+
+func (q *Queries) SelectEmailLogAgencyID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, selectEmailLogAgencyID, id)
+	var agency_id uuid.UUID
+	err := row.Scan(&agency_id)
+	return agency_id, err
+}
+
+const selectEmailSuppression = `-- name: SelectEmailSuppression :one
+
+SELECT id, created_at, agency_id, email, reason, detail FROM email_suppressions WHERE agency_id = $1 AND email = $2
+`
+
+type SelectEmailSuppressionParams struct {
+	AgencyID uuid.UUID `json:"agency_id"`
+	Email    string    `json:"email"`
+}
+
+// =============================================================================
+// Email Suppression Queries
+// =============================================================================
+func (q *Queries) SelectEmailSuppression(ctx context.Context, arg SelectEmailSuppressionParams) (EmailSuppression, error) {
+	row := q.db.QueryRowContext(ctx, selectEmailSuppression, arg.AgencyID, arg.Email)
+	var i EmailSuppression
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.AgencyID,
+		&i.Email,
+		&i.Reason,
+		&i.Detail,
+	)
+	return i, err
+}
+
+const selectEmailSuppressions = `-- name: SelectEmailSuppressions :many
+SELECT id, created_at, agency_id, email, reason, detail FROM email_suppressions
+WHERE agency_id = $1
+ORDER BY created_at DESC
+`
+
+func (q *Queries) SelectEmailSuppressions(ctx context.Context, agencyID uuid.UUID) ([]EmailSuppression, error) {
+	rows, err := q.db.QueryContext(ctx, selectEmailSuppressions, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EmailSuppression
+	for rows.Next() {
+		var i EmailSuppression
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.AgencyID,
+			&i.Email,
+			&i.Reason,
+			&i.Detail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectEmailTemplate = `-- name: SelectEmailTemplate :one
+
+SELECT id, created_at, agency_id, name, version, subject, body, created_by FROM email_templates
+WHERE agency_id = $1 AND name = $2
+ORDER BY version DESC
+LIMIT 1
+`
+
+type SelectEmailTemplateParams struct {
+	AgencyID uuid.UUID `json:"agency_id"`
+	Name     string    `json:"name"`
+}
+
+// =============================================================================
+// Email Template Queries
+// =============================================================================
+// Returns the latest version of an agency's template
+func (q *Queries) SelectEmailTemplate(ctx context.Context, arg SelectEmailTemplateParams) (EmailTemplate, error) {
+	row := q.db.QueryRowContext(ctx, selectEmailTemplate, arg.AgencyID, arg.Name)
+	var i EmailTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.AgencyID,
+		&i.Name,
+		&i.Version,
+		&i.Subject,
+		&i.Body,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const selectEmailTemplateVersions = `-- name: SelectEmailTemplateVersions :many
+SELECT id, created_at, agency_id, name, version, subject, body, created_by FROM email_templates
+WHERE agency_id = $1 AND name = $2
+ORDER BY version DESC
+`
+
+type SelectEmailTemplateVersionsParams struct {
+	AgencyID uuid.UUID `json:"agency_id"`
+	Name     string    `json:"name"`
+}
+
+func (q *Queries) SelectEmailTemplateVersions(ctx context.Context, arg SelectEmailTemplateVersionsParams) ([]EmailTemplate, error) {
+	rows, err := q.db.QueryContext(ctx, selectEmailTemplateVersions, arg.AgencyID, arg.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EmailTemplate
+	for rows.Next() {
+		var i EmailTemplate
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.AgencyID,
+			&i.Name,
+			&i.Version,
+			&i.Subject,
+			&i.Body,
+			&i.CreatedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectEmailTemplates = `-- name: SelectEmailTemplates :many
+SELECT DISTINCT ON (name) id, created_at, agency_id, name, version, subject, body, created_by FROM email_templates
+WHERE agency_id = $1
+ORDER BY name, version DESC
+`
+
+// Returns the latest version of each of an agency's templates
+func (q *Queries) SelectEmailTemplates(ctx context.Context, agencyID uuid.UUID) ([]EmailTemplate, error) {
+	rows, err := q.db.QueryContext(ctx, selectEmailTemplates, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EmailTemplate
+	for rows.Next() {
+		var i EmailTemplate
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.AgencyID,
+			&i.Name,
+			&i.Version,
+			&i.Subject,
+			&i.Body,
+			&i.CreatedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectEmails = `-- name: SelectEmails :many
+select id, created, updated, user_id, email_to, email_from, email_subject, email_body, status, retry_count, error_message, next_attempt_at, locked_until, sent_at, email_log_id, provider_message_id, agency_id, unsubscribe_token from emails where user_id = $1
+`
+
+func (q *Queries) SelectEmails(ctx context.Context, userID uuid.UUID) ([]Email, error) {
+	rows, err := q.db.QueryContext(ctx, selectEmails, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Email
+	for rows.Next() {
+		var i Email
+		if err := rows.Scan(
+			&i.ID,
+			&i.Created,
+			&i.Updated,
+			&i.UserID,
+			&i.EmailTo,
+			&i.EmailFrom,
+			&i.EmailSubject,
+			&i.EmailBody,
+			&i.Status,
+			&i.RetryCount,
+			&i.ErrorMessage,
+			&i.NextAttemptAt,
+			&i.LockedUntil,
+			&i.SentAt,
+			&i.EmailLogID,
+			&i.ProviderMessageID,
+			&i.AgencyID,
+			&i.UnsubscribeToken,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectEmailsByStatus = `-- name: SelectEmailsByStatus :many
+select id, created, updated, user_id, email_to, email_from, email_subject, email_body, status, retry_count, error_message, next_attempt_at, locked_until, sent_at, email_log_id, provider_message_id, agency_id, unsubscribe_token from emails where user_id = $1 and status = $2 order by created desc
+`
+
+type SelectEmailsByStatusParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Status string    `json:"status"`
+}
+
+func (q *Queries) SelectEmailsByStatus(ctx context.Context, arg SelectEmailsByStatusParams) ([]Email, error) {
+	rows, err := q.db.QueryContext(ctx, selectEmailsByStatus, arg.UserID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Email
+	for rows.Next() {
+		var i Email
+		if err := rows.Scan(
+			&i.ID,
+			&i.Created,
+			&i.Updated,
+			&i.UserID,
+			&i.EmailTo,
+			&i.EmailFrom,
+			&i.EmailSubject,
+			&i.EmailBody,
+			&i.Status,
+			&i.RetryCount,
+			&i.ErrorMessage,
+			&i.NextAttemptAt,
+			&i.LockedUntil,
+			&i.SentAt,
+			&i.EmailLogID,
+			&i.ProviderMessageID,
+			&i.AgencyID,
+			&i.UnsubscribeToken,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectFieldOptionSets = `-- name: SelectFieldOptionSets :many
+SELECT id, agency_id, name, slug, description, options, is_system, created_at, updated_at FROM field_option_sets
+WHERE agency_id = $1::uuid OR agency_id IS NULL
+ORDER BY agency_id NULLS FIRST, slug
+`
+
+// System option sets first so an agency's own set replaces one with the
+// same slug
+func (q *Queries) SelectFieldOptionSets(ctx context.Context, agencyID uuid.UUID) ([]FieldOptionSet, error) {
+	rows, err := q.db.QueryContext(ctx, selectFieldOptionSets, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FieldOptionSet
+	for rows.Next() {
+		var i FieldOptionSet
+		if err := rows.Scan(
+			&i.ID,
+			&i.AgencyID,
+			&i.Name,
+			&i.Slug,
+			&i.Description,
+			&i.Options,
+			&i.IsSystem,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectFile = `-- name: SelectFile :one
+select id, created, updated, user_id, file_key, file_name, file_size, content_type, agency_id from files where id = $1
+`
+
+func (q *Queries) SelectFile(ctx context.Context, id uuid.UUID) (File, error) {
+	row := q.db.QueryRowContext(ctx, selectFile, id)
+	var i File
+	err := row.Scan(
+		&i.ID,
+		&i.Created,
+		&i.Updated,
+		&i.UserID,
+		&i.FileKey,
+		&i.FileName,
+		&i.FileSize,
+		&i.ContentType,
+		&i.AgencyID,
+	)
+	return i, err
+}
+
+const selectFiles = `-- name: SelectFiles :many
+select id, created, updated, user_id, file_key, file_name, file_size, content_type, agency_id from files where user_id = $1
+`
+
+func (q *Queries) SelectFiles(ctx context.Context, userID uuid.UUID) ([]File, error) {
+	rows, err := q.db.QueryContext(ctx, selectFiles, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []File
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.ID,
+			&i.Created,
+			&i.Updated,
+			&i.UserID,
+			&i.FileKey,
+			&i.FileName,
+			&i.FileSize,
+			&i.ContentType,
+			&i.AgencyID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectFormSubmission = `-- name: SelectFormSubmission :one
+SELECT id, form_id, agency_id, slug, client_id, client_business_name, client_email, data, current_step, completion_percentage, started_at, last_activity_at, consultation_id, proposal_id, contract_id, metadata, status, created_at, submitted_at, processed_at, form_version, processing_attempts, processing_error FROM form_submissions WHERE id = $1
+`
+
+func (q *Queries) SelectFormSubmission(ctx context.Context, id uuid.UUID) (FormSubmission, error) {
+	row := q.db.QueryRowContext(ctx, selectFormSubmission, id)
+	var i FormSubmission
+	err := row.Scan(
+		&i.ID,
+		&i.FormID,
+		&i.AgencyID,
+		&i.Slug,
+		&i.ClientID,
+		&i.ClientBusinessName,
+		&i.ClientEmail,
+		&i.Data,
+		&i.CurrentStep,
+		&i.CompletionPercentage,
+		&i.StartedAt,
+		&i.LastActivityAt,
+		&i.ConsultationID,
+		&i.ProposalID,
+		&i.ContractID,
+		&i.Metadata,
+		&i.Status,
+		&i.CreatedAt,
+		&i.SubmittedAt,
+		&i.ProcessedAt,
+		&i.FormVersion,
+		&i.ProcessingAttempts,
+		&i.ProcessingError,
+	)
+	return i, err
+}
+
+const selectFormSubmissionForUpdate = `-- name: SelectFormSubmissionForUpdate :one
+SELECT id, form_id, agency_id, slug, client_id, client_business_name, client_email, data, current_step, completion_percentage, started_at, last_activity_at, consultation_id, proposal_id, contract_id, metadata, status, created_at, submitted_at, processed_at, form_version, processing_attempts, processing_error FROM form_submissions WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) SelectFormSubmissionForUpdate(ctx context.Context, id uuid.UUID) (FormSubmission, error) {
+	row := q.db.QueryRowContext(ctx, selectFormSubmissionForUpdate, id)
+	var i FormSubmission
+	err := row.Scan(
+		&i.ID,
+		&i.FormID,
+		&i.AgencyID,
+		&i.Slug,
+		&i.ClientID,
+		&i.ClientBusinessName,
+		&i.ClientEmail,
+		&i.Data,
+		&i.CurrentStep,
+		&i.CompletionPercentage,
+		&i.StartedAt,
+		&i.LastActivityAt,
+		&i.ConsultationID,
+		&i.ProposalID,
+		&i.ContractID,
+		&i.Metadata,
+		&i.Status,
+		&i.CreatedAt,
+		&i.SubmittedAt,
+		&i.ProcessedAt,
+		&i.FormVersion,
+		&i.ProcessingAttempts,
+		&i.ProcessingError,
+	)
+	return i, err
+}
+
+const selectInvoice = `-- name: SelectInvoice :one
+SELECT id, created_at, updated_at, agency_id, proposal_id, contract_id, client_id, invoice_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_address, client_abn, issue_date, due_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, payment_terms, payment_terms_custom, notes, public_notes, view_count, last_viewed_at, sent_at, paid_at, payment_method, payment_reference, payment_notes, amount_paid, pdf_url, pdf_generated_at, stripe_payment_link_id, stripe_payment_link_url, stripe_payment_intent_id, stripe_checkout_session_id, online_payment_enabled, created_by, quotation_id FROM invoices
+WHERE id = $1
+`
+
+func (q *Queries) SelectInvoice(ctx context.Context, id uuid.UUID) (Invoice, error) {
+	row := q.db.QueryRowContext(ctx, selectInvoice, id)
+	var i Invoice
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.ContractID,
+		&i.ClientID,
+		&i.InvoiceNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ClientAbn,
+		&i.IssueDate,
+		&i.DueDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.PaymentTerms,
+		&i.PaymentTermsCustom,
+		&i.Notes,
+		&i.PublicNotes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.PaidAt,
+		&i.PaymentMethod,
+		&i.PaymentReference,
+		&i.PaymentNotes,
+		&i.AmountPaid,
+		&i.PdfUrl,
+		&i.PdfGeneratedAt,
+		&i.StripePaymentLinkID,
+		&i.StripePaymentLinkUrl,
+		&i.StripePaymentIntentID,
+		&i.StripeCheckoutSessionID,
+		&i.OnlinePaymentEnabled,
+		&i.CreatedBy,
+		&i.QuotationID,
+	)
+	return i, err
+}
+
+const selectInvoiceBySlug = `-- name: SelectInvoiceBySlug :one
+SELECT id, created_at, updated_at, agency_id, proposal_id, contract_id, client_id, invoice_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_address, client_abn, issue_date, due_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, payment_terms, payment_terms_custom, notes, public_notes, view_count, last_viewed_at, sent_at, paid_at, payment_method, payment_reference, payment_notes, amount_paid, pdf_url, pdf_generated_at, stripe_payment_link_id, stripe_payment_link_url, stripe_payment_intent_id, stripe_checkout_session_id, online_payment_enabled, created_by, quotation_id FROM invoices
+WHERE slug = $1
+`
+
+func (q *Queries) SelectInvoiceBySlug(ctx context.Context, slug string) (Invoice, error) {
+	row := q.db.QueryRowContext(ctx, selectInvoiceBySlug, slug)
+	var i Invoice
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.ContractID,
+		&i.ClientID,
+		&i.InvoiceNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ClientAbn,
+		&i.IssueDate,
+		&i.DueDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.PaymentTerms,
+		&i.PaymentTermsCustom,
+		&i.Notes,
+		&i.PublicNotes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.PaidAt,
+		&i.PaymentMethod,
+		&i.PaymentReference,
+		&i.PaymentNotes,
+		&i.AmountPaid,
+		&i.PdfUrl,
+		&i.PdfGeneratedAt,
+		&i.StripePaymentLinkID,
+		&i.StripePaymentLinkUrl,
+		&i.StripePaymentIntentID,
+		&i.StripeCheckoutSessionID,
+		&i.OnlinePaymentEnabled,
+		&i.CreatedBy,
+		&i.QuotationID,
+	)
+	return i, err
+}
+
+const selectInvoiceLineItem = `-- name: SelectInvoiceLineItem :one
+SELECT id, created_at, updated_at, invoice_id, description, quantity, unit_price, amount, is_taxable, sort_order, category, package_id, addon_id FROM invoice_line_items
+WHERE id = $1
+`
+
+func (q *Queries) SelectInvoiceLineItem(ctx context.Context, id uuid.UUID) (InvoiceLineItem, error) {
+	row := q.db.QueryRowContext(ctx, selectInvoiceLineItem, id)
+	var i InvoiceLineItem
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.InvoiceID,
+		&i.Description,
+		&i.Quantity,
+		&i.UnitPrice,
+		&i.Amount,
+		&i.IsTaxable,
+		&i.SortOrder,
+		&i.Category,
+		&i.PackageID,
+		&i.AddonID,
+	)
+	return i, err
+}
+
+const selectInvoiceLineItems = `-- name: SelectInvoiceLineItems :many
+SELECT id, created_at, updated_at, invoice_id, description, quantity, unit_price, amount, is_taxable, sort_order, category, package_id, addon_id FROM invoice_line_items
+WHERE invoice_id = $1
+ORDER BY sort_order, created_at
+`
+
+func (q *Queries) SelectInvoiceLineItems(ctx context.Context, invoiceID uuid.UUID) ([]InvoiceLineItem, error) {
+	rows, err := q.db.QueryContext(ctx, selectInvoiceLineItems, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InvoiceLineItem
+	for rows.Next() {
+		var i InvoiceLineItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.InvoiceID,
+			&i.Description,
+			&i.Quantity,
+			&i.UnitPrice,
+			&i.Amount,
+			&i.IsTaxable,
+			&i.SortOrder,
+			&i.Category,
+			&i.PackageID,
+			&i.AddonID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectInvoices = `-- name: SelectInvoices :many
+SELECT id, created_at, updated_at, agency_id, proposal_id, contract_id, client_id, invoice_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_address, client_abn, issue_date, due_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, payment_terms, payment_terms_custom, notes, public_notes, view_count, last_viewed_at, sent_at, paid_at, payment_method, payment_reference, payment_notes, amount_paid, pdf_url, pdf_generated_at, stripe_payment_link_id, stripe_payment_link_url, stripe_payment_intent_id, stripe_checkout_session_id, online_payment_enabled, created_by, quotation_id FROM invoices
+WHERE agency_id = $1
+  AND ($2::text = '' OR status = $2::text)
+ORDER BY created_at DESC
+LIMIT $4 OFFSET $3
+`
+
+type SelectInvoicesParams struct {
+	AgencyID  uuid.UUID `json:"agency_id"`
+	Status    string    `json:"status"`
+	RowOffset int32     `json:"row_offset"`
+	RowLimit  int32     `json:"row_limit"`
+}
+
+func (q *Queries) SelectInvoices(ctx context.Context, arg SelectInvoicesParams) ([]Invoice, error) {
+	rows, err := q.db.QueryContext(ctx, selectInvoices,
+		arg.AgencyID,
+		arg.Status,
+		arg.RowOffset,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Invoice
+	for rows.Next() {
+		var i Invoice
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AgencyID,
+			&i.ProposalID,
+			&i.ContractID,
+			&i.ClientID,
+			&i.InvoiceNumber,
+			&i.Slug,
+			&i.Status,
+			&i.ClientBusinessName,
+			&i.ClientContactName,
+			&i.ClientEmail,
+			&i.ClientPhone,
+			&i.ClientAddress,
+			&i.ClientAbn,
+			&i.IssueDate,
+			&i.DueDate,
+			&i.Subtotal,
+			&i.DiscountAmount,
+			&i.DiscountDescription,
+			&i.GstAmount,
+			&i.Total,
+			&i.GstRegistered,
+			&i.GstRate,
+			&i.PaymentTerms,
+			&i.PaymentTermsCustom,
+			&i.Notes,
+			&i.PublicNotes,
+			&i.ViewCount,
+			&i.LastViewedAt,
+			&i.SentAt,
+			&i.PaidAt,
+			&i.PaymentMethod,
+			&i.PaymentReference,
+			&i.PaymentNotes,
+			&i.AmountPaid,
+			&i.PdfUrl,
+			&i.PdfGeneratedAt,
+			&i.StripePaymentLinkID,
+			&i.StripePaymentLinkUrl,
+			&i.StripePaymentIntentID,
+			&i.StripeCheckoutSessionID,
+			&i.OnlinePaymentEnabled,
+			&i.CreatedBy,
+			&i.QuotationID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectLatestConsultationVersionNumber = `-- name: SelectLatestConsultationVersionNumber :one
+SELECT COALESCE(MAX(version_number), 0)::integer AS version_number
+FROM consultation_versions
+WHERE consultation_id = $1
+`
+
+func (q *Queries) SelectLatestConsultationVersionNumber(ctx context.Context, consultationID uuid.UUID) (int32, error) {
+	row := q.db.QueryRowContext(ctx, selectLatestConsultationVersionNumber, consultationID)
+	var version_number int32
+	err := row.Scan(&version_number)
+	return version_number, err
+}
+
+const selectNote = `-- name: SelectNote :one
+select id, created, updated, user_id, title, category, content from notes where id = $1
+`
+
+func (q *Queries) SelectNote(ctx context.Context, id uuid.UUID) (Note, error) {
+	row := q.db.QueryRowContext(ctx, selectNote, id)
+	var i Note
+	err := row.Scan(
+		&i.ID,
+		&i.Created,
+		&i.Updated,
+		&i.UserID,
+		&i.Title,
+		&i.Category,
+		&i.Content,
+	)
+	return i, err
+}
+
+const selectNotes = `-- name: SelectNotes :many
+select id, created, updated, user_id, title, category, content from notes where user_id = $1 order by created desc limit $2 offset $3
+`
+
+type SelectNotesParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Limit  int32     `json:"limit"`
+	Offset int32     `json:"offset"`
+}
+
+func (q *Queries) SelectNotes(ctx context.Context, arg SelectNotesParams) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, selectNotes, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Note
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.Created,
+			&i.Updated,
+			&i.UserID,
+			&i.Title,
+			&i.Category,
+			&i.Content,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectPasskeyByCredentialID = `-- name: SelectPasskeyByCredentialID :one
+SELECT id, created_at, updated_at, user_id, name, credential_id, public_key, algorithm, sign_count, transports, last_used_at FROM passkeys WHERE credential_id = $1
+`
+
+func (q *Queries) SelectPasskeyByCredentialID(ctx context.Context, credentialID string) (Passkey, error) {
+	row := q.db.QueryRowContext(ctx, selectPasskeyByCredentialID, credentialID)
+	var i Passkey
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+		&i.CredentialID,
+		&i.PublicKey,
+		&i.Algorithm,
+		&i.SignCount,
+		&i.Transports,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const selectProposal = `-- name: SelectProposal :one
+SELECT id, created_at, updated_at, agency_id, consultation_id, client_id, proposal_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_website, title, cover_image, performance_data, opportunity_content, current_issues, compliance_issues, roi_analysis, performance_standards, local_advantage_content, proposed_pages, timeline, closing_content, selected_package_id, selected_addons, custom_pricing, valid_until, view_count, last_viewed_at, sent_at, accepted_at, declined_at, client_comments, decline_reason, revision_request_notes, revision_requested_at, executive_summary, next_steps, consultation_pain_points, consultation_goals, consultation_challenges, created_by FROM proposals
+WHERE id = $1
+`
+
+func (q *Queries) SelectProposal(ctx context.Context, id uuid.UUID) (Proposal, error) {
+	row := q.db.QueryRowContext(ctx, selectProposal, id)
+	var i Proposal
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ConsultationID,
+		&i.ClientID,
+		&i.ProposalNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientWebsite,
+		&i.Title,
+		&i.CoverImage,
+		&i.PerformanceData,
+		&i.OpportunityContent,
+		&i.CurrentIssues,
+		&i.ComplianceIssues,
+		&i.RoiAnalysis,
+		&i.PerformanceStandards,
+		&i.LocalAdvantageContent,
+		&i.ProposedPages,
+		&i.Timeline,
+		&i.ClosingContent,
+		&i.SelectedPackageID,
+		&i.SelectedAddons,
+		&i.CustomPricing,
+		&i.ValidUntil,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.AcceptedAt,
+		&i.DeclinedAt,
+		&i.ClientComments,
+		&i.DeclineReason,
+		&i.RevisionRequestNotes,
+		&i.RevisionRequestedAt,
+		&i.ExecutiveSummary,
+		&i.NextSteps,
+		&i.ConsultationPainPoints,
+		&i.ConsultationGoals,
+		&i.ConsultationChallenges,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const selectProposalBySlug = `-- name: SelectProposalBySlug :one
+SELECT id, created_at, updated_at, agency_id, consultation_id, client_id, proposal_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_website, title, cover_image, performance_data, opportunity_content, current_issues, compliance_issues, roi_analysis, performance_standards, local_advantage_content, proposed_pages, timeline, closing_content, selected_package_id, selected_addons, custom_pricing, valid_until, view_count, last_viewed_at, sent_at, accepted_at, declined_at, client_comments, decline_reason, revision_request_notes, revision_requested_at, executive_summary, next_steps, consultation_pain_points, consultation_goals, consultation_challenges, created_by FROM proposals
+WHERE slug = $1
+`
+
+func (q *Queries) SelectProposalBySlug(ctx context.Context, slug string) (Proposal, error) {
+	row := q.db.QueryRowContext(ctx, selectProposalBySlug, slug)
+	var i Proposal
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ConsultationID,
+		&i.ClientID,
+		&i.ProposalNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientWebsite,
+		&i.Title,
+		&i.CoverImage,
+		&i.PerformanceData,
+		&i.OpportunityContent,
+		&i.CurrentIssues,
+		&i.ComplianceIssues,
+		&i.RoiAnalysis,
+		&i.PerformanceStandards,
+		&i.LocalAdvantageContent,
+		&i.ProposedPages,
+		&i.Timeline,
+		&i.ClosingContent,
+		&i.SelectedPackageID,
+		&i.SelectedAddons,
+		&i.CustomPricing,
+		&i.ValidUntil,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.AcceptedAt,
+		&i.DeclinedAt,
+		&i.ClientComments,
+		&i.DeclineReason,
+		&i.RevisionRequestNotes,
+		&i.RevisionRequestedAt,
+		&i.ExecutiveSummary,
+		&i.NextSteps,
+		&i.ConsultationPainPoints,
+		&i.ConsultationGoals,
+		&i.ConsultationChallenges,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const selectProposals = `-- name: SelectProposals :many
+SELECT id, created_at, updated_at, agency_id, consultation_id, client_id, proposal_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_website, title, cover_image, performance_data, opportunity_content, current_issues, compliance_issues, roi_analysis, performance_standards, local_advantage_content, proposed_pages, timeline, closing_content, selected_package_id, selected_addons, custom_pricing, valid_until, view_count, last_viewed_at, sent_at, accepted_at, declined_at, client_comments, decline_reason, revision_request_notes, revision_requested_at, executive_summary, next_steps, consultation_pain_points, consultation_goals, consultation_challenges, created_by FROM proposals
+WHERE agency_id = $1
+  AND ($2::text = '' OR status = $2::text)
+ORDER BY created_at DESC
+LIMIT $4 OFFSET $3
+`
+
+type SelectProposalsParams struct {
+	AgencyID  uuid.UUID `json:"agency_id"`
+	Status    string    `json:"status"`
+	RowOffset int32     `json:"row_offset"`
+	RowLimit  int32     `json:"row_limit"`
+}
+
+func (q *Queries) SelectProposals(ctx context.Context, arg SelectProposalsParams) ([]Proposal, error) {
+	rows, err := q.db.QueryContext(ctx, selectProposals,
+		arg.AgencyID,
+		arg.Status,
+		arg.RowOffset,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Proposal
+	for rows.Next() {
+		var i Proposal
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AgencyID,
+			&i.ConsultationID,
+			&i.ClientID,
+			&i.ProposalNumber,
+			&i.Slug,
+			&i.Status,
+			&i.ClientBusinessName,
+			&i.ClientContactName,
+			&i.ClientEmail,
+			&i.ClientPhone,
+			&i.ClientWebsite,
+			&i.Title,
+			&i.CoverImage,
+			&i.PerformanceData,
+			&i.OpportunityContent,
+			&i.CurrentIssues,
+			&i.ComplianceIssues,
+			&i.RoiAnalysis,
+			&i.PerformanceStandards,
+			&i.LocalAdvantageContent,
+			&i.ProposedPages,
+			&i.Timeline,
+			&i.ClosingContent,
+			&i.SelectedPackageID,
+			&i.SelectedAddons,
+			&i.CustomPricing,
+			&i.ValidUntil,
+			&i.ViewCount,
+			&i.LastViewedAt,
+			&i.SentAt,
+			&i.AcceptedAt,
+			&i.DeclinedAt,
+			&i.ClientComments,
+			&i.DeclineReason,
+			&i.RevisionRequestNotes,
+			&i.RevisionRequestedAt,
+			&i.ExecutiveSummary,
+			&i.NextSteps,
+			&i.ConsultationPainPoints,
+			&i.ConsultationGoals,
+			&i.ConsultationChallenges,
+			&i.CreatedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectQuotation = `-- name: SelectQuotation :one
+SELECT id, created_at, updated_at, agency_id, client_id, template_id, quotation_number, slug, quotation_name, status, client_business_name, client_contact_name, client_email, client_phone, client_address, site_address, site_reference, prepared_date, expiry_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, terms_blocks, options_notes, notes, view_count, last_viewed_at, sent_at, declined_at, decline_reason, accepted_by_name, accepted_by_title, accepted_at, acceptance_ip, created_by FROM quotations
+WHERE id = $1
+`
+
+func (q *Queries) SelectQuotation(ctx context.Context, id uuid.UUID) (Quotation, error) {
+	row := q.db.QueryRowContext(ctx, selectQuotation, id)
+	var i Quotation
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ClientID,
+		&i.TemplateID,
+		&i.QuotationNumber,
+		&i.Slug,
+		&i.QuotationName,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.SiteAddress,
+		&i.SiteReference,
+		&i.PreparedDate,
+		&i.ExpiryDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.TermsBlocks,
+		&i.OptionsNotes,
+		&i.Notes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.DeclinedAt,
+		&i.DeclineReason,
+		&i.AcceptedByName,
+		&i.AcceptedByTitle,
+		&i.AcceptedAt,
+		&i.AcceptanceIp,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const selectQuotationBySlug = `-- name: SelectQuotationBySlug :one
+SELECT id, created_at, updated_at, agency_id, client_id, template_id, quotation_number, slug, quotation_name, status, client_business_name, client_contact_name, client_email, client_phone, client_address, site_address, site_reference, prepared_date, expiry_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, terms_blocks, options_notes, notes, view_count, last_viewed_at, sent_at, declined_at, decline_reason, accepted_by_name, accepted_by_title, accepted_at, acceptance_ip, created_by FROM quotations
+WHERE slug = $1
+`
+
+func (q *Queries) SelectQuotationBySlug(ctx context.Context, slug string) (Quotation, error) {
+	row := q.db.QueryRowContext(ctx, selectQuotationBySlug, slug)
+	var i Quotation
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ClientID,
+		&i.TemplateID,
+		&i.QuotationNumber,
+		&i.Slug,
+		&i.QuotationName,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.SiteAddress,
+		&i.SiteReference,
+		&i.PreparedDate,
+		&i.ExpiryDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.TermsBlocks,
+		&i.OptionsNotes,
+		&i.Notes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.DeclinedAt,
+		&i.DeclineReason,
+		&i.AcceptedByName,
+		&i.AcceptedByTitle,
+		&i.AcceptedAt,
+		&i.AcceptanceIp,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const selectQuotationScopeSections = `-- name: SelectQuotationScopeSections :many
+SELECT id, created_at, quotation_id, title, work_items, section_price, section_gst, section_total, sort_order, scope_template_id FROM quotation_scope_sections
+WHERE quotation_id = $1
+ORDER BY sort_order, created_at
+`
+
+func (q *Queries) SelectQuotationScopeSections(ctx context.Context, quotationID uuid.UUID) ([]QuotationScopeSection, error) {
+	rows, err := q.db.QueryContext(ctx, selectQuotationScopeSections, quotationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []QuotationScopeSection
+	for rows.Next() {
+		var i QuotationScopeSection
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.QuotationID,
+			&i.Title,
+			&i.WorkItems,
+			&i.SectionPrice,
+			&i.SectionGst,
+			&i.SectionTotal,
+			&i.SortOrder,
+			&i.ScopeTemplateID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectQuotationTemplate = `-- name: SelectQuotationTemplate :one
+SELECT id, created_at, updated_at, agency_id, name, description, category, default_validity_days, is_default, is_active, sort_order, created_by FROM quotation_templates
+WHERE id = $1
+`
+
+func (q *Queries) SelectQuotationTemplate(ctx context.Context, id uuid.UUID) (QuotationTemplate, error) {
+	row := q.db.QueryRowContext(ctx, selectQuotationTemplate, id)
+	var i QuotationTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.Name,
+		&i.Description,
+		&i.Category,
+		&i.DefaultValidityDays,
+		&i.IsDefault,
+		&i.IsActive,
+		&i.SortOrder,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const selectQuotationTemplateSections = `-- name: SelectQuotationTemplateSections :many
+SELECT
+    quotation_scope_templates.id AS scope_template_id,
+    quotation_scope_templates.name,
+    quotation_scope_templates.work_items,
+    quotation_scope_templates.default_price,
+    quotation_template_sections.default_section_price,
+    quotation_template_sections.sort_order
+FROM quotation_template_sections
+JOIN quotation_scope_templates ON quotation_scope_templates.id = quotation_template_sections.scope_template_id
+WHERE quotation_template_sections.template_id = $1
+  AND quotation_scope_templates.is_active = true
+ORDER BY quotation_template_sections.sort_order
+`
+
+type SelectQuotationTemplateSectionsRow struct {
+	ScopeTemplateID     uuid.UUID       `json:"scope_template_id"`
+	Name                string          `json:"name"`
+	WorkItems           json.RawMessage `json:"work_items"`
+	DefaultPrice        money.Money     `json:"default_price"`
+	DefaultSectionPrice money.Money     `json:"default_section_price"`
+	SortOrder           int32           `json:"sort_order"`
+}
+
+func (q *Queries) SelectQuotationTemplateSections(ctx context.Context, templateID uuid.UUID) ([]SelectQuotationTemplateSectionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, selectQuotationTemplateSections, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectQuotationTemplateSectionsRow
+	for rows.Next() {
+		var i SelectQuotationTemplateSectionsRow
+		if err := rows.Scan(
+			&i.ScopeTemplateID,
+			&i.Name,
+			&i.WorkItems,
+			&i.DefaultPrice,
+			&i.DefaultSectionPrice,
+			&i.SortOrder,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectQuotationTemplateTerms = `-- name: SelectQuotationTemplateTerms :many
+SELECT
+    quotation_terms_templates.id AS terms_template_id,
+    quotation_terms_templates.title,
+    quotation_terms_templates.content,
+    quotation_template_terms.sort_order
+FROM quotation_template_terms
+JOIN quotation_terms_templates ON quotation_terms_templates.id = quotation_template_terms.terms_template_id
+WHERE quotation_template_terms.template_id = $1
+  AND quotation_terms_templates.is_active = true
+ORDER BY quotation_template_terms.sort_order
+`
+
+type SelectQuotationTemplateTermsRow struct {
+	TermsTemplateID uuid.UUID `json:"terms_template_id"`
+	Title           string    `json:"title"`
+	Content         string    `json:"content"`
+	SortOrder       int32     `json:"sort_order"`
+}
+
+func (q *Queries) SelectQuotationTemplateTerms(ctx context.Context, templateID uuid.UUID) ([]SelectQuotationTemplateTermsRow, error) {
+	rows, err := q.db.QueryContext(ctx, selectQuotationTemplateTerms, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectQuotationTemplateTermsRow
+	for rows.Next() {
+		var i SelectQuotationTemplateTermsRow
+		if err := rows.Scan(
+			&i.TermsTemplateID,
+			&i.Title,
+			&i.Content,
+			&i.SortOrder,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectQuotations = `-- name: SelectQuotations :many
+SELECT id, created_at, updated_at, agency_id, client_id, template_id, quotation_number, slug, quotation_name, status, client_business_name, client_contact_name, client_email, client_phone, client_address, site_address, site_reference, prepared_date, expiry_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, terms_blocks, options_notes, notes, view_count, last_viewed_at, sent_at, declined_at, decline_reason, accepted_by_name, accepted_by_title, accepted_at, acceptance_ip, created_by FROM quotations
+WHERE agency_id = $1
+  AND ($2::text = '' OR status = $2::text)
+ORDER BY created_at DESC
+LIMIT $4 OFFSET $3
+`
+
+type SelectQuotationsParams struct {
+	AgencyID  uuid.UUID `json:"agency_id"`
+	Status    string    `json:"status"`
+	RowOffset int32     `json:"row_offset"`
+	RowLimit  int32     `json:"row_limit"`
+}
+
+func (q *Queries) SelectQuotations(ctx context.Context, arg SelectQuotationsParams) ([]Quotation, error) {
+	rows, err := q.db.QueryContext(ctx, selectQuotations,
+		arg.AgencyID,
+		arg.Status,
+		arg.RowOffset,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Quotation
+	for rows.Next() {
+		var i Quotation
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AgencyID,
+			&i.ClientID,
+			&i.TemplateID,
+			&i.QuotationNumber,
+			&i.Slug,
+			&i.QuotationName,
+			&i.Status,
+			&i.ClientBusinessName,
+			&i.ClientContactName,
+			&i.ClientEmail,
+			&i.ClientPhone,
+			&i.ClientAddress,
+			&i.SiteAddress,
+			&i.SiteReference,
+			&i.PreparedDate,
+			&i.ExpiryDate,
+			&i.Subtotal,
+			&i.DiscountAmount,
+			&i.DiscountDescription,
+			&i.GstAmount,
+			&i.Total,
+			&i.GstRegistered,
+			&i.GstRate,
+			&i.TermsBlocks,
+			&i.OptionsNotes,
+			&i.Notes,
+			&i.ViewCount,
+			&i.LastViewedAt,
+			&i.SentAt,
+			&i.DeclinedAt,
+			&i.DeclineReason,
+			&i.AcceptedByName,
+			&i.AcceptedByTitle,
+			&i.AcceptedAt,
+			&i.AcceptanceIp,
+			&i.CreatedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectSessionActive = `-- name: SelectSessionActive :one
+select exists (
+    select 1 from tokens
+    where family = $1 and family <> '' and target <> '' and expires > current_timestamp
+)::boolean as active
+`
+
+// A session is active while any token of its family is unrevoked and unexpired
+func (q *Queries) SelectSessionActive(ctx context.Context, family string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, selectSessionActive, family)
+	var active bool
+	err := row.Scan(&active)
+	return active, err
+}
+
+const selectToken = `-- name: SelectToken :one
+select id, expires, target, callback, family, created, rotated, user_agent, ip from tokens where id = $1
+`
+
+func (q *Queries) SelectToken(ctx context.Context, id string) (Token, error) {
+	row := q.db.QueryRowContext(ctx, selectToken, id)
+	var i Token
+	err := row.Scan(
+		&i.ID,
+		&i.Expires,
+		&i.Target,
+		&i.Callback,
+		&i.Family,
+		&i.Created,
+		&i.Rotated,
+		&i.UserAgent,
+		&i.Ip,
+	)
+	return i, err
+}
+
+const selectUnprocessedFormSubmissions = `-- name: SelectUnprocessedFormSubmissions :many
+SELECT id, form_id, agency_id, slug, client_id, client_business_name, client_email, data, current_step, completion_percentage, started_at, last_activity_at, consultation_id, proposal_id, contract_id, metadata, status, created_at, submitted_at, processed_at, form_version, processing_attempts, processing_error FROM form_submissions
+WHERE status = 'completed'
+  AND processed_at IS NULL
+  AND processing_attempts < $1
+ORDER BY submitted_at NULLS LAST, created_at
+LIMIT $2
+`
+
+type SelectUnprocessedFormSubmissionsParams struct {
+	MaxAttempts int32 `json:"max_attempts"`
+	RowLimit    int32 `json:"row_limit"`
+}
+
+// Completed submissions waiting to be processed, skipping any that have
+// failed too often to retry automatically
+func (q *Queries) SelectUnprocessedFormSubmissions(ctx context.Context, arg SelectUnprocessedFormSubmissionsParams) ([]FormSubmission, error) {
+	rows, err := q.db.QueryContext(ctx, selectUnprocessedFormSubmissions, arg.MaxAttempts, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FormSubmission
+	for rows.Next() {
+		var i FormSubmission
+		if err := rows.Scan(
+			&i.ID,
+			&i.FormID,
+			&i.AgencyID,
+			&i.Slug,
+			&i.ClientID,
+			&i.ClientBusinessName,
+			&i.ClientEmail,
+			&i.Data,
+			&i.CurrentStep,
+			&i.CompletionPercentage,
+			&i.StartedAt,
+			&i.LastActivityAt,
+			&i.ConsultationID,
+			&i.ProposalID,
+			&i.ContractID,
+			&i.Metadata,
+			&i.Status,
+			&i.CreatedAt,
+			&i.SubmittedAt,
+			&i.ProcessedAt,
+			&i.FormVersion,
+			&i.ProcessingAttempts,
+			&i.ProcessingError,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUser = `-- name: SelectUser :one
+select id, created, updated, email, phone, access, sub, avatar, customer_id, subscription_id, subscription_end, api_key, default_agency_id, suspended, suspended_at, suspended_reason from users where id = $1
+`
+
+func (q *Queries) SelectUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, selectUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Created,
+		&i.Updated,
+		&i.Email,
+		&i.Phone,
+		&i.Access,
+		&i.Sub,
+		&i.Avatar,
+		&i.CustomerID,
+		&i.SubscriptionID,
+		&i.SubscriptionEnd,
+		&i.ApiKey,
+		&i.DefaultAgencyID,
+		&i.Suspended,
+		&i.SuspendedAt,
+		&i.SuspendedReason,
+	)
+	return i, err
+}
+
+const selectUserAPIKeys = `-- name: SelectUserAPIKeys :many
+SELECT id, created_at, updated_at, user_id, agency_id, name, prefix, key_hash, access, expires_at, last_used_at, revoked_at FROM api_keys
+WHERE user_id = $1 AND agency_id IS NULL
+ORDER BY created_at DESC
+`
+
+// A user's personal keys; agency keys are listed with their agency
+func (q *Queries) SelectUserAPIKeys(ctx context.Context, userID uuid.UUID) ([]ApiKey, error) {
+	rows, err := q.db.QueryContext(ctx, selectUserAPIKeys, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.AgencyID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.Access,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserByCustomerID = `-- name: SelectUserByCustomerID :one
+select id, created, updated, email, phone, access, sub, avatar, customer_id, subscription_id, subscription_end, api_key, default_agency_id, suspended, suspended_at, suspended_reason from users where customer_id = $1
+`
+
+func (q *Queries) SelectUserByCustomerID(ctx context.Context, customerID string) (User, error) {
+	row := q.db.QueryRowContext(ctx, selectUserByCustomerID, customerID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Created,
+		&i.Updated,
+		&i.Email,
+		&i.Phone,
+		&i.Access,
+		&i.Sub,
+		&i.Avatar,
+		&i.CustomerID,
+		&i.SubscriptionID,
+		&i.SubscriptionEnd,
+		&i.ApiKey,
+		&i.DefaultAgencyID,
+		&i.Suspended,
+		&i.SuspendedAt,
+		&i.SuspendedReason,
+	)
+	return i, err
+}
+
+const selectUserByEmail = `-- name: SelectUserByEmail :one
+select id, created, updated, email, phone, access, sub, avatar, customer_id, subscription_id, subscription_end, api_key, default_agency_id, suspended, suspended_at, suspended_reason from users where email = $1
+`
+
+func (q *Queries) SelectUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, selectUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Created,
+		&i.Updated,
+		&i.Email,
+		&i.Phone,
+		&i.Access,
+		&i.Sub,
+		&i.Avatar,
+		&i.CustomerID,
+		&i.SubscriptionID,
+		&i.SubscriptionEnd,
+		&i.ApiKey,
+		&i.DefaultAgencyID,
+		&i.Suspended,
+		&i.SuspendedAt,
+		&i.SuspendedReason,
+	)
+	return i, err
+}
+
+const selectUserByEmailAndSub = `-- name: SelectUserByEmailAndSub :one
+select id, created, updated, email, phone, access, sub, avatar, customer_id, subscription_id, subscription_end, api_key, default_agency_id, suspended, suspended_at, suspended_reason from users where email = $1 and sub = $2
+`
+
+type SelectUserByEmailAndSubParams struct {
+	Email string `json:"email"`
+	Sub   string `json:"sub"`
+}
+
+func (q *Queries) SelectUserByEmailAndSub(ctx context.Context, arg SelectUserByEmailAndSubParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, selectUserByEmailAndSub, arg.Email, arg.Sub)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Created,
+		&i.Updated,
+		&i.Email,
+		&i.Phone,
+		&i.Access,
+		&i.Sub,
+		&i.Avatar,
+		&i.CustomerID,
+		&i.SubscriptionID,
+		&i.SubscriptionEnd,
+		&i.ApiKey,
+		&i.DefaultAgencyID,
+		&i.Suspended,
+		&i.SuspendedAt,
+		&i.SuspendedReason,
+	)
+	return i, err
+}
+
+const selectUserPasskeys = `-- name: SelectUserPasskeys :many
+SELECT id, created_at, updated_at, user_id, name, credential_id, public_key, algorithm, sign_count, transports, last_used_at FROM passkeys WHERE user_id = $1 ORDER BY created_at
+`
+
+func (q *Queries) SelectUserPasskeys(ctx context.Context, userID uuid.UUID) ([]Passkey, error) {
+	rows, err := q.db.QueryContext(ctx, selectUserPasskeys, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Passkey
+	for rows.Next() {
+		var i Passkey
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Name,
+			&i.CredentialID,
+			&i.PublicKey,
+			&i.Algorithm,
+			&i.SignCount,
+			&i.Transports,
+			&i.LastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserSessions = `-- name: SelectUserSessions :many
+select t.id, t.expires, t.target, t.callback, t.family, t.created, t.rotated, t.user_agent, t.ip, (select min(f.created) from tokens f where f.family = t.family)::timestamptz as started
+from tokens t
+where t.target = $1 and t.family <> '' and t.rotated is null and t.expires > current_timestamp
+order by t.created desc
+`
+
+type SelectUserSessionsRow struct {
+	ID        string       `json:"id"`
+	Expires   time.Time    `json:"expires"`
+	Target    string       `json:"target"`
+	Callback  string       `json:"callback"`
+	Family    string       `json:"family"`
+	Created   time.Time    `json:"created"`
+	Rotated   sql.NullTime `json:"rotated"`
+	UserAgent string       `json:"user_agent"`
+	Ip        string       `json:"ip"`
+	Started   time.Time    `json:"started"`
+}
+
+// Returns the current token of each of a user's active sessions, with when
+// the session was started
+func (q *Queries) SelectUserSessions(ctx context.Context, userID string) ([]SelectUserSessionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, selectUserSessions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectUserSessionsRow
+	for rows.Next() {
+		var i SelectUserSessionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Expires,
+			&i.Target,
+			&i.Callback,
+			&i.Family,
+			&i.Created,
+			&i.Rotated,
+			&i.UserAgent,
+			&i.Ip,
+			&i.Started,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserTOTP = `-- name: SelectUserTOTP :one
+
+SELECT user_id, created_at, updated_at, secret, enabled_at, last_step, failed_attempts, locked_until FROM user_totp WHERE user_id = $1
+`
+
+// =============================================================================
+// Two-Factor Queries
+// =============================================================================
+func (q *Queries) SelectUserTOTP(ctx context.Context, userID uuid.UUID) (UserTotp, error) {
+	row := q.db.QueryRowContext(ctx, selectUserTOTP, userID)
+	var i UserTotp
+	err := row.Scan(
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Secret,
+		&i.EnabledAt,
+		&i.LastStep,
+		&i.FailedAttempts,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const selectUsers = `-- name: SelectUsers :many
+select id, created, updated, email, phone, access, sub, avatar, customer_id, subscription_id, subscription_end, api_key, default_agency_id, suspended, suspended_at, suspended_reason from users
+`
+
+func (q *Queries) SelectUsers(ctx context.Context) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, selectUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Created,
+			&i.Updated,
+			&i.Email,
+			&i.Phone,
+			&i.Access,
+			&i.Sub,
+			&i.Avatar,
+			&i.CustomerID,
+			&i.SubscriptionID,
+			&i.SubscriptionEnd,
+			&i.ApiKey,
+			&i.DefaultAgencyID,
+			&i.Suspended,
+			&i.SuspendedAt,
+			&i.SuspendedReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setNextDocumentNumber = `-- name: SetNextDocumentNumber :exec
+UPDATE agency_profiles
+SET next_proposal_number = CASE WHEN CAST($1 AS TEXT) = 'proposal' THEN $2 ELSE next_proposal_number END,
+    next_contract_number = CASE WHEN CAST($1 AS TEXT) = 'contract' THEN $2 ELSE next_contract_number END,
+    next_invoice_number = CASE WHEN CAST($1 AS TEXT) = 'invoice' THEN $2 ELSE next_invoice_number END,
+    next_quotation_number = CASE WHEN CAST($1 AS TEXT) = 'quotation' THEN $2 ELSE next_quotation_number END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE agency_id = $3
+`
+
+type SetNextDocumentNumberParams struct {
+	DocumentType string    `json:"document_type"`
+	NextNumber   int32     `json:"next_number"`
+	AgencyID     uuid.UUID `json:"agency_id"`
+}
+
+func (q *Queries) SetNextDocumentNumber(ctx context.Context, arg SetNextDocumentNumberParams) error {
+	_, err := q.db.ExecContext(ctx, setNextDocumentNumber, arg.DocumentType, arg.NextNumber, arg.AgencyID)
+	return err
+}
+
+const signContractAsAgency = `-- name: SignContractAsAgency :one
+UPDATE contracts
+SET
+    agency_signatory_name = $1,
+    agency_signatory_title = $2,
+    agency_signed_at = $3,
+    agency_signature_ip = $4,
+    agency_signature_user_agent = $5,
+    content_hash = $6,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $7
+  AND version = $8
+  AND agency_signed_at IS NULL
+  AND (content_hash IS NULL OR content_hash = $6)
+RETURNING id, created_at, updated_at, agency_id, proposal_id, template_id, client_id, contract_number, slug, version, status, client_business_name, client_contact_name, client_email, client_phone, client_address, services_description, commencement_date, completion_date, special_conditions, total_price, price_includes_gst, payment_terms, generated_cover_html, generated_terms_html, generated_schedule_html, valid_until, agency_signatory_name, agency_signatory_title, agency_signed_at, client_signatory_name, client_signatory_title, client_signed_at, client_signature_ip, client_signature_user_agent, view_count, last_viewed_at, sent_at, signed_pdf_url, visible_fields, included_schedule_ids, created_by, agency_signature_ip, agency_signature_user_agent, content_hash
+`
+
+type SignContractAsAgencyParams struct {
+	SignatoryName  sql.NullString `json:"signatory_name"`
+	SignatoryTitle sql.NullString `json:"signatory_title"`
+	SignedAt       sql.NullTime   `json:"signed_at"`
+	IpAddress      sql.NullString `json:"ip_address"`
+	UserAgent      sql.NullString `json:"user_agent"`
+	ContentHash    sql.NullString `json:"content_hash"`
+	ID             uuid.UUID      `json:"id"`
+	Version        int32          `json:"version"`
+}
+
+func (q *Queries) SignContractAsAgency(ctx context.Context, arg SignContractAsAgencyParams) (Contract, error) {
+	row := q.db.QueryRowContext(ctx, signContractAsAgency,
+		arg.SignatoryName,
+		arg.SignatoryTitle,
+		arg.SignedAt,
+		arg.IpAddress,
+		arg.UserAgent,
+		arg.ContentHash,
+		arg.ID,
+		arg.Version,
+	)
+	var i Contract
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.TemplateID,
+		&i.ClientID,
+		&i.ContractNumber,
+		&i.Slug,
+		&i.Version,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ServicesDescription,
+		&i.CommencementDate,
+		&i.CompletionDate,
+		&i.SpecialConditions,
+		&i.TotalPrice,
+		&i.PriceIncludesGst,
+		&i.PaymentTerms,
+		&i.GeneratedCoverHtml,
+		&i.GeneratedTermsHtml,
+		&i.GeneratedScheduleHtml,
+		&i.ValidUntil,
+		&i.AgencySignatoryName,
+		&i.AgencySignatoryTitle,
+		&i.AgencySignedAt,
+		&i.ClientSignatoryName,
+		&i.ClientSignatoryTitle,
+		&i.ClientSignedAt,
+		&i.ClientSignatureIp,
+		&i.ClientSignatureUserAgent,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.SignedPdfUrl,
+		&i.VisibleFields,
+		&i.IncludedScheduleIds,
+		&i.CreatedBy,
+		&i.AgencySignatureIp,
+		&i.AgencySignatureUserAgent,
+		&i.ContentHash,
+	)
+	return i, err
+}
+
+const signContractAsClient = `-- name: SignContractAsClient :one
+UPDATE contracts
+SET
+    status = 'signed',
+    client_signatory_name = $1,
+    client_signatory_title = $2,
+    client_signed_at = $3,
+    client_signature_ip = $4,
+    client_signature_user_agent = $5,
+    content_hash = $6,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $7
+  AND version = $8
+  AND client_signed_at IS NULL
+  AND status IN ('sent', 'viewed')
+  AND (content_hash IS NULL OR content_hash = $6)
+RETURNING id, created_at, updated_at, agency_id, proposal_id, template_id, client_id, contract_number, slug, version, status, client_business_name, client_contact_name, client_email, client_phone, client_address, services_description, commencement_date, completion_date, special_conditions, total_price, price_includes_gst, payment_terms, generated_cover_html, generated_terms_html, generated_schedule_html, valid_until, agency_signatory_name, agency_signatory_title, agency_signed_at, client_signatory_name, client_signatory_title, client_signed_at, client_signature_ip, client_signature_user_agent, view_count, last_viewed_at, sent_at, signed_pdf_url, visible_fields, included_schedule_ids, created_by, agency_signature_ip, agency_signature_user_agent, content_hash
+`
+
+type SignContractAsClientParams struct {
+	SignatoryName  sql.NullString `json:"signatory_name"`
+	SignatoryTitle sql.NullString `json:"signatory_title"`
+	SignedAt       sql.NullTime   `json:"signed_at"`
+	IpAddress      sql.NullString `json:"ip_address"`
+	UserAgent      sql.NullString `json:"user_agent"`
+	ContentHash    sql.NullString `json:"content_hash"`
+	ID             uuid.UUID      `json:"id"`
+	Version        int32          `json:"version"`
+}
+
+func (q *Queries) SignContractAsClient(ctx context.Context, arg SignContractAsClientParams) (Contract, error) {
+	row := q.db.QueryRowContext(ctx, signContractAsClient,
+		arg.SignatoryName,
+		arg.SignatoryTitle,
+		arg.SignedAt,
+		arg.IpAddress,
+		arg.UserAgent,
+		arg.ContentHash,
+		arg.ID,
+		arg.Version,
+	)
+	var i Contract
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.TemplateID,
+		&i.ClientID,
+		&i.ContractNumber,
+		&i.Slug,
+		&i.Version,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ServicesDescription,
+		&i.CommencementDate,
+		&i.CompletionDate,
+		&i.SpecialConditions,
+		&i.TotalPrice,
+		&i.PriceIncludesGst,
+		&i.PaymentTerms,
+		&i.GeneratedCoverHtml,
+		&i.GeneratedTermsHtml,
+		&i.GeneratedScheduleHtml,
+		&i.ValidUntil,
+		&i.AgencySignatoryName,
+		&i.AgencySignatoryTitle,
+		&i.AgencySignedAt,
+		&i.ClientSignatoryName,
+		&i.ClientSignatoryTitle,
+		&i.ClientSignedAt,
+		&i.ClientSignatureIp,
+		&i.ClientSignatureUserAgent,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.SignedPdfUrl,
+		&i.VisibleFields,
+		&i.IncludedScheduleIds,
+		&i.CreatedBy,
+		&i.AgencySignatureIp,
+		&i.AgencySignatureUserAgent,
+		&i.ContentHash,
+	)
+	return i, err
+}
+
+const updateAPIKeyLastUsed = `-- name: UpdateAPIKeyLastUsed :exec
+UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')
+`
+
+// Usage is recorded at most once a minute so busy keys do not write on
+// every request
+func (q *Queries) UpdateAPIKeyLastUsed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, updateAPIKeyLastUsed, id)
+	return err
+}
+
+const updateAgencyFormMapping = `-- name: UpdateAgencyFormMapping :one
+UPDATE agency_forms
+SET consultation_mapping = $1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+RETURNING id, agency_id, name, slug, description, form_type, schema, ui_config, branding, is_active, is_default, requires_auth, source_template_id, is_customized, previous_schema, consultation_mapping, version, created_at, updated_at, created_by
+`
+
+type UpdateAgencyFormMappingParams struct {
+	ConsultationMapping json.RawMessage `json:"consultation_mapping"`
+	ID                  uuid.UUID       `json:"id"`
+}
+
+func (q *Queries) UpdateAgencyFormMapping(ctx context.Context, arg UpdateAgencyFormMappingParams) (AgencyForm, error) {
+	row := q.db.QueryRowContext(ctx, updateAgencyFormMapping, arg.ConsultationMapping, arg.ID)
+	var i AgencyForm
+	err := row.Scan(
+		&i.ID,
+		&i.AgencyID,
+		&i.Name,
+		&i.Slug,
+		&i.Description,
+		&i.FormType,
+		&i.Schema,
+		&i.UiConfig,
+		&i.Branding,
+		&i.IsActive,
+		&i.IsDefault,
+		&i.RequiresAuth,
+		&i.SourceTemplateID,
+		&i.IsCustomized,
+		&i.PreviousSchema,
+		&i.ConsultationMapping,
+		&i.Version,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const updateAgencyFormSchema = `-- name: UpdateAgencyFormSchema :one
+UPDATE agency_forms
+SET schema = $1,
+    ui_config = $2,
+    previous_schema = $3,
+    version = $4,
+    is_customized = $5,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $6
+RETURNING id, agency_id, name, slug, description, form_type, schema, ui_config, branding, is_active, is_default, requires_auth, source_template_id, is_customized, previous_schema, consultation_mapping, version, created_at, updated_at, created_by
+`
+
+type UpdateAgencyFormSchemaParams struct {
+	Schema         json.RawMessage       `json:"schema"`
+	UiConfig       json.RawMessage       `json:"ui_config"`
+	PreviousSchema pqtype.NullRawMessage `json:"previous_schema"`
+	Version        int32                 `json:"version"`
+	IsCustomized   bool                  `json:"is_customized"`
+	ID             uuid.UUID             `json:"id"`
+}
+
+func (q *Queries) UpdateAgencyFormSchema(ctx context.Context, arg UpdateAgencyFormSchemaParams) (AgencyForm, error) {
+	row := q.db.QueryRowContext(ctx, updateAgencyFormSchema,
+		arg.Schema,
+		arg.UiConfig,
+		arg.PreviousSchema,
+		arg.Version,
+		arg.IsCustomized,
+		arg.ID,
+	)
+	var i AgencyForm
+	err := row.Scan(
+		&i.ID,
+		&i.AgencyID,
+		&i.Name,
+		&i.Slug,
+		&i.Description,
+		&i.FormType,
+		&i.Schema,
+		&i.UiConfig,
+		&i.Branding,
+		&i.IsActive,
+		&i.IsDefault,
+		&i.RequiresAuth,
+		&i.SourceTemplateID,
+		&i.IsCustomized,
+		&i.PreviousSchema,
+		&i.ConsultationMapping,
+		&i.Version,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const updateAgencyStripeCustomer = `-- name: UpdateAgencyStripeCustomer :exec
+UPDATE agencies
+SET stripe_customer_id = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+type UpdateAgencyStripeCustomerParams struct {
+	ID               uuid.UUID `json:"id"`
+	StripeCustomerID string    `json:"stripe_customer_id"`
+}
+
+func (q *Queries) UpdateAgencyStripeCustomer(ctx context.Context, arg UpdateAgencyStripeCustomerParams) error {
+	_, err := q.db.ExecContext(ctx, updateAgencyStripeCustomer, arg.ID, arg.StripeCustomerID)
+	return err
+}
+
+const updateAgencySubscription = `-- name: UpdateAgencySubscription :exec
+UPDATE agencies
+SET
+    subscription_tier = $2,
+    subscription_id = $3,
+    subscription_end = $4,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+type UpdateAgencySubscriptionParams struct {
+	ID               uuid.UUID    `json:"id"`
+	SubscriptionTier string       `json:"subscription_tier"`
+	SubscriptionID   string       `json:"subscription_id"`
+	SubscriptionEnd  sql.NullTime `json:"subscription_end"`
+}
+
+func (q *Queries) UpdateAgencySubscription(ctx context.Context, arg UpdateAgencySubscriptionParams) error {
+	_, err := q.db.ExecContext(ctx, updateAgencySubscription,
+		arg.ID,
+		arg.SubscriptionTier,
+		arg.SubscriptionID,
+		arg.SubscriptionEnd,
+	)
+	return err
+}
+
+const updateClient = `-- name: UpdateClient :one
+UPDATE clients
+SET
+    business_name = $2,
+    email = $3,
+    phone = $4,
+    contact_name = $5,
+    notes = $6,
+    abn = $7,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, agency_id, business_name, email, phone, contact_name, notes, abn, status, created_by, created_at, updated_at
+`
+
+type UpdateClientParams struct {
+	ID           uuid.UUID      `json:"id"`
+	BusinessName string         `json:"business_name"`
+	Email        string         `json:"email"`
+	Phone        sql.NullString `json:"phone"`
+	ContactName  sql.NullString `json:"contact_name"`
+	Notes        sql.NullString `json:"notes"`
+	Abn          string         `json:"abn"`
+}
+
+func (q *Queries) UpdateClient(ctx context.Context, arg UpdateClientParams) (Client, error) {
+	row := q.db.QueryRowContext(ctx, updateClient,
+		arg.ID,
+		arg.BusinessName,
+		arg.Email,
+		arg.Phone,
+		arg.ContactName,
+		arg.Notes,
+		arg.Abn,
+	)
+	var i Client
+	err := row.Scan(
+		&i.ID,
+		&i.AgencyID,
+		&i.BusinessName,
+		&i.Email,
+		&i.Phone,
+		&i.ContactName,
+		&i.Notes,
+		&i.Abn,
+		&i.Status,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateClientStatus = `-- name: UpdateClientStatus :one
+UPDATE clients
+SET
+    status = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, agency_id, business_name, email, phone, contact_name, notes, abn, status, created_by, created_at, updated_at
+`
+
+type UpdateClientStatusParams struct {
+	ID     uuid.UUID `json:"id"`
+	Status string    `json:"status"`
+}
+
+func (q *Queries) UpdateClientStatus(ctx context.Context, arg UpdateClientStatusParams) (Client, error) {
+	row := q.db.QueryRowContext(ctx, updateClientStatus, arg.ID, arg.Status)
+	var i Client
+	err := row.Scan(
+		&i.ID,
+		&i.AgencyID,
+		&i.BusinessName,
+		&i.Email,
+		&i.Phone,
+		&i.ContactName,
+		&i.Notes,
+		&i.Abn,
+		&i.Status,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateConsultation = `-- name: UpdateConsultation :one
+UPDATE consultations
+SET
+    client_id = $2,
+    business_name = $3,
+    contact_person = $4,
+    email = $5,
+    phone = $6,
+    website = $7,
+    social_linkedin = $8,
+    social_facebook = $9,
+    social_instagram = $10,
+    industry = $11,
+    business_type = $12,
+    website_status = $13,
+    primary_challenges = $14,
+    urgency_level = $15,
+    primary_goals = $16,
+    conversion_goal = $17,
+    budget_range = $18,
+    timeline = $19,
+    design_styles = $20,
+    admired_websites = $21,
+    consultation_notes = $22,
+    custom_data = $23,
+    status = $24,
+    completion_percentage = $25,
+    completed_at = $26,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, user_id, agency_id, business_name, contact_person, email, phone, website, social_linkedin, social_facebook, social_instagram, industry, business_type, website_status, primary_challenges, urgency_level, primary_goals, conversion_goal, budget_range, timeline, design_styles, admired_websites, consultation_notes, created_by, performance_data, client_id, custom_data, form_id, status, completion_percentage, created_at, updated_at, completed_at
+`
+
+type UpdateConsultationParams struct {
+	ID                   uuid.UUID             `json:"id"`
+	ClientID             uuid.NullUUID         `json:"client_id"`
+	BusinessName         sql.NullString        `json:"business_name"`
+	ContactPerson        sql.NullString        `json:"contact_person"`
+	Email                sql.NullString        `json:"email"`
+	Phone                sql.NullString        `json:"phone"`
+	Website              sql.NullString        `json:"website"`
+	SocialLinkedin       sql.NullString        `json:"social_linkedin"`
+	SocialFacebook       sql.NullString        `json:"social_facebook"`
+	SocialInstagram      sql.NullString        `json:"social_instagram"`
+	Industry             sql.NullString        `json:"industry"`
+	BusinessType         sql.NullString        `json:"business_type"`
+	WebsiteStatus        sql.NullString        `json:"website_status"`
+	PrimaryChallenges    pqtype.NullRawMessage `json:"primary_challenges"`
+	UrgencyLevel         sql.NullString        `json:"urgency_level"`
+	PrimaryGoals         pqtype.NullRawMessage `json:"primary_goals"`
+	ConversionGoal       sql.NullString        `json:"conversion_goal"`
+	BudgetRange          sql.NullString        `json:"budget_range"`
+	Timeline             sql.NullString        `json:"timeline"`
+	DesignStyles         pqtype.NullRawMessage `json:"design_styles"`
+	AdmiredWebsites      pqtype.NullRawMessage `json:"admired_websites"`
+	ConsultationNotes    sql.NullString        `json:"consultation_notes"`
+	CustomData           pqtype.NullRawMessage `json:"custom_data"`
+	Status               string                `json:"status"`
+	CompletionPercentage int32                 `json:"completion_percentage"`
+	CompletedAt          sql.NullTime          `json:"completed_at"`
+}
+
+func (q *Queries) UpdateConsultation(ctx context.Context, arg UpdateConsultationParams) (Consultation, error) {
+	row := q.db.QueryRowContext(ctx, updateConsultation,
+		arg.ID,
+		arg.ClientID,
+		arg.BusinessName,
+		arg.ContactPerson,
+		arg.Email,
+		arg.Phone,
+		arg.Website,
+		arg.SocialLinkedin,
+		arg.SocialFacebook,
+		arg.SocialInstagram,
+		arg.Industry,
+		arg.BusinessType,
+		arg.WebsiteStatus,
+		arg.PrimaryChallenges,
+		arg.UrgencyLevel,
+		arg.PrimaryGoals,
+		arg.ConversionGoal,
+		arg.BudgetRange,
+		arg.Timeline,
+		arg.DesignStyles,
+		arg.AdmiredWebsites,
+		arg.ConsultationNotes,
+		arg.CustomData,
+		arg.Status,
+		arg.CompletionPercentage,
+		arg.CompletedAt,
+	)
+	var i Consultation
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.AgencyID,
+		&i.BusinessName,
+		&i.ContactPerson,
+		&i.Email,
+		&i.Phone,
+		&i.Website,
+		&i.SocialLinkedin,
+		&i.SocialFacebook,
+		&i.SocialInstagram,
+		&i.Industry,
+		&i.BusinessType,
+		&i.WebsiteStatus,
+		&i.PrimaryChallenges,
+		&i.UrgencyLevel,
+		&i.PrimaryGoals,
+		&i.ConversionGoal,
+		&i.BudgetRange,
+		&i.Timeline,
+		&i.DesignStyles,
+		&i.AdmiredWebsites,
+		&i.ConsultationNotes,
+		&i.CreatedBy,
+		&i.PerformanceData,
+		&i.ClientID,
+		&i.CustomData,
+		&i.FormID,
+		&i.Status,
+		&i.CompletionPercentage,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const updateContract = `-- name: UpdateContract :one
+UPDATE contracts
+SET
+    client_business_name = $2,
+    client_contact_name = $3,
+    client_email = $4,
+    client_phone = $5,
+    client_address = $6,
+    services_description = $7,
+    commencement_date = $8,
+    completion_date = $9,
+    special_conditions = $10,
+    total_price = $11,
+    price_includes_gst = $12,
+    payment_terms = $13,
+    generated_cover_html = $14,
+    generated_terms_html = $15,
+    generated_schedule_html = $16,
+    valid_until = $17,
+    agency_signatory_name = $18,
+    agency_signatory_title = $19,
+    included_schedule_ids = $20,
+    version = version + 1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND version = $21 AND status = 'draft' AND content_hash IS NULL
+RETURNING id, created_at, updated_at, agency_id, proposal_id, template_id, client_id, contract_number, slug, version, status, client_business_name, client_contact_name, client_email, client_phone, client_address, services_description, commencement_date, completion_date, special_conditions, total_price, price_includes_gst, payment_terms, generated_cover_html, generated_terms_html, generated_schedule_html, valid_until, agency_signatory_name, agency_signatory_title, agency_signed_at, client_signatory_name, client_signatory_title, client_signed_at, client_signature_ip, client_signature_user_agent, view_count, last_viewed_at, sent_at, signed_pdf_url, visible_fields, included_schedule_ids, created_by, agency_signature_ip, agency_signature_user_agent, content_hash
+`
+
+type UpdateContractParams struct {
+	ID                    uuid.UUID       `json:"id"`
+	ClientBusinessName    string          `json:"client_business_name"`
+	ClientContactName     string          `json:"client_contact_name"`
+	ClientEmail           string          `json:"client_email"`
+	ClientPhone           string          `json:"client_phone"`
+	ClientAddress         string          `json:"client_address"`
+	ServicesDescription   string          `json:"services_description"`
+	CommencementDate      sql.NullTime    `json:"commencement_date"`
+	CompletionDate        sql.NullTime    `json:"completion_date"`
+	SpecialConditions     string          `json:"special_conditions"`
+	TotalPrice            money.Money     `json:"total_price"`
+	PriceIncludesGst      bool            `json:"price_includes_gst"`
+	PaymentTerms          string          `json:"payment_terms"`
+	GeneratedCoverHtml    sql.NullString  `json:"generated_cover_html"`
+	GeneratedTermsHtml    sql.NullString  `json:"generated_terms_html"`
+	GeneratedScheduleHtml sql.NullString  `json:"generated_schedule_html"`
+	ValidUntil            sql.NullTime    `json:"valid_until"`
+	AgencySignatoryName   sql.NullString  `json:"agency_signatory_name"`
+	AgencySignatoryTitle  sql.NullString  `json:"agency_signatory_title"`
+	IncludedScheduleIds   json.RawMessage `json:"included_schedule_ids"`
+	Version               int32           `json:"version"`
+}
+
+// Content is frozen once a contract is sent or signed, and an edit only
+// applies to the version it was made against
+func (q *Queries) UpdateContract(ctx context.Context, arg UpdateContractParams) (Contract, error) {
+	row := q.db.QueryRowContext(ctx, updateContract,
+		arg.ID,
+		arg.ClientBusinessName,
+		arg.ClientContactName,
+		arg.ClientEmail,
+		arg.ClientPhone,
+		arg.ClientAddress,
+		arg.ServicesDescription,
+		arg.CommencementDate,
+		arg.CompletionDate,
+		arg.SpecialConditions,
+		arg.TotalPrice,
+		arg.PriceIncludesGst,
+		arg.PaymentTerms,
+		arg.GeneratedCoverHtml,
+		arg.GeneratedTermsHtml,
+		arg.GeneratedScheduleHtml,
+		arg.ValidUntil,
+		arg.AgencySignatoryName,
+		arg.AgencySignatoryTitle,
+		arg.IncludedScheduleIds,
+		arg.Version,
+	)
+	var i Contract
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.TemplateID,
+		&i.ClientID,
+		&i.ContractNumber,
+		&i.Slug,
+		&i.Version,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ServicesDescription,
+		&i.CommencementDate,
+		&i.CompletionDate,
+		&i.SpecialConditions,
+		&i.TotalPrice,
+		&i.PriceIncludesGst,
+		&i.PaymentTerms,
+		&i.GeneratedCoverHtml,
+		&i.GeneratedTermsHtml,
+		&i.GeneratedScheduleHtml,
+		&i.ValidUntil,
+		&i.AgencySignatoryName,
+		&i.AgencySignatoryTitle,
+		&i.AgencySignedAt,
+		&i.ClientSignatoryName,
+		&i.ClientSignatoryTitle,
+		&i.ClientSignedAt,
+		&i.ClientSignatureIp,
+		&i.ClientSignatureUserAgent,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.SignedPdfUrl,
+		&i.VisibleFields,
+		&i.IncludedScheduleIds,
+		&i.CreatedBy,
+		&i.AgencySignatureIp,
+		&i.AgencySignatureUserAgent,
+		&i.ContentHash,
+	)
+	return i, err
+}
+
+const updateContractPdf = `-- name: UpdateContractPdf :exec
+UPDATE contracts
+SET
+    signed_pdf_url = $1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
 `
 
-func (q *Queries) GetAgencyByStripeCustomer(ctx context.Context, stripeCustomerID string) (Agency, error) {
-	row := q.db.QueryRowContext(ctx, getAgencyByStripeCustomer, stripeCustomerID)
-	var i Agency
+type UpdateContractPdfParams struct {
+	SignedPdfUrl sql.NullString `json:"signed_pdf_url"`
+	ID           uuid.UUID      `json:"id"`
+}
+
+func (q *Queries) UpdateContractPdf(ctx context.Context, arg UpdateContractPdfParams) error {
+	_, err := q.db.ExecContext(ctx, updateContractPdf, arg.SignedPdfUrl, arg.ID)
+	return err
+}
+
+const updateContractStatus = `-- name: UpdateContractStatus :one
+UPDATE contracts
+SET
+    status = $1,
+    sent_at = COALESCE($2, sent_at),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $3 AND status = $4
+RETURNING id, created_at, updated_at, agency_id, proposal_id, template_id, client_id, contract_number, slug, version, status, client_business_name, client_contact_name, client_email, client_phone, client_address, services_description, commencement_date, completion_date, special_conditions, total_price, price_includes_gst, payment_terms, generated_cover_html, generated_terms_html, generated_schedule_html, valid_until, agency_signatory_name, agency_signatory_title, agency_signed_at, client_signatory_name, client_signatory_title, client_signed_at, client_signature_ip, client_signature_user_agent, view_count, last_viewed_at, sent_at, signed_pdf_url, visible_fields, included_schedule_ids, created_by, agency_signature_ip, agency_signature_user_agent, content_hash
+`
+
+type UpdateContractStatusParams struct {
+	Status     string       `json:"status"`
+	SentAt     sql.NullTime `json:"sent_at"`
+	ID         uuid.UUID    `json:"id"`
+	FromStatus string       `json:"from_status"`
+}
+
+func (q *Queries) UpdateContractStatus(ctx context.Context, arg UpdateContractStatusParams) (Contract, error) {
+	row := q.db.QueryRowContext(ctx, updateContractStatus,
+		arg.Status,
+		arg.SentAt,
+		arg.ID,
+		arg.FromStatus,
+	)
+	var i Contract
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Name,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.TemplateID,
+		&i.ClientID,
+		&i.ContractNumber,
 		&i.Slug,
-		&i.LogoUrl,
-		&i.LogoAvatarUrl,
-		&i.PrimaryColor,
-		&i.SecondaryColor,
-		&i.AccentColor,
-		&i.AccentGradient,
-		&i.Email,
-		&i.Phone,
-		&i.Website,
+		&i.Version,
 		&i.Status,
-		&i.SubscriptionTier,
-		&i.SubscriptionID,
-		&i.SubscriptionEnd,
-		&i.StripeCustomerID,
-		&i.AiGenerationsThisMonth,
-		&i.AiGenerationsResetAt,
-		&i.IsFreemium,
-		&i.FreemiumReason,
-		&i.FreemiumExpiresAt,
-		&i.FreemiumGrantedAt,
-		&i.FreemiumGrantedBy,
-		&i.DeletedAt,
-		&i.DeletionScheduledFor,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ServicesDescription,
+		&i.CommencementDate,
+		&i.CompletionDate,
+		&i.SpecialConditions,
+		&i.TotalPrice,
+		&i.PriceIncludesGst,
+		&i.PaymentTerms,
+		&i.GeneratedCoverHtml,
+		&i.GeneratedTermsHtml,
+		&i.GeneratedScheduleHtml,
+		&i.ValidUntil,
+		&i.AgencySignatoryName,
+		&i.AgencySignatoryTitle,
+		&i.AgencySignedAt,
+		&i.ClientSignatoryName,
+		&i.ClientSignatoryTitle,
+		&i.ClientSignedAt,
+		&i.ClientSignatureIp,
+		&i.ClientSignatureUserAgent,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.SignedPdfUrl,
+		&i.VisibleFields,
+		&i.IncludedScheduleIds,
+		&i.CreatedBy,
+		&i.AgencySignatureIp,
+		&i.AgencySignatureUserAgent,
+		&i.ContentHash,
 	)
 	return i, err
 }
 
-const insertEmail = `-- name: InsertEmail :one
-insert into emails (id, user_id, email_to, email_from, email_subject, email_body) values ($1, $2, $3, $4, $5, $6) returning id, created, updated, user_id, email_to, email_from, email_subject, email_body
+const updateDocumentSequenceYear = `-- name: UpdateDocumentSequenceYear :exec
+UPDATE agency_document_numbering
+SET sequence_year = $3, updated_at = CURRENT_TIMESTAMP
+WHERE agency_id = $1 AND document_type = $2
 `
 
-type InsertEmailParams struct {
-	ID           uuid.UUID `json:"id"`
-	UserID       uuid.UUID `json:"user_id"`
-	EmailTo      string    `json:"email_to"`
-	EmailFrom    string    `json:"email_from"`
-	EmailSubject string    `json:"email_subject"`
-	EmailBody    string    `json:"email_body"`
+type UpdateDocumentSequenceYearParams struct {
+	AgencyID     uuid.UUID `json:"agency_id"`
+	DocumentType string    `json:"document_type"`
+	SequenceYear int32     `json:"sequence_year"`
 }
 
-func (q *Queries) InsertEmail(ctx context.Context, arg InsertEmailParams) (Email, error) {
-	row := q.db.QueryRowContext(ctx, insertEmail,
+func (q *Queries) UpdateDocumentSequenceYear(ctx context.Context, arg UpdateDocumentSequenceYearParams) error {
+	_, err := q.db.ExecContext(ctx, updateDocumentSequenceYear, arg.AgencyID, arg.DocumentType, arg.SequenceYear)
+	return err
+}
+
+const updateEmailFailed = `-- name: UpdateEmailFailed :one
+update emails
+set status = $1,
+    retry_count = retry_count + 1,
+    error_message = $2,
+    next_attempt_at = $3,
+    locked_until = null,
+    updated = current_timestamp
+where id = $4 and status = 'pending' and locked_until = $5
+returning id, created, updated, user_id, email_to, email_from, email_subject, email_body, status, retry_count, error_message, next_attempt_at, locked_until, sent_at, email_log_id, provider_message_id, agency_id, unsubscribe_token
+`
+
+type UpdateEmailFailedParams struct {
+	Status        string       `json:"status"`
+	ErrorMessage  string       `json:"error_message"`
+	NextAttemptAt time.Time    `json:"next_attempt_at"`
+	ID            uuid.UUID    `json:"id"`
+	ClaimedUntil  sql.NullTime `json:"claimed_until"`
+}
+
+// Records a failed attempt. The email is retried at next_attempt_at while
+// its status is pending. Like UpdateEmailSent, it only applies under the
+// caller's lease.
+func (q *Queries) UpdateEmailFailed(ctx context.Context, arg UpdateEmailFailedParams) (Email, error) {
+	row := q.db.QueryRowContext(ctx, updateEmailFailed,
+		arg.Status,
+		arg.ErrorMessage,
+		arg.NextAttemptAt,
 		arg.ID,
-		arg.UserID,
-		arg.EmailTo,
-		arg.EmailFrom,
-		arg.EmailSubject,
-		arg.EmailBody,
+		arg.ClaimedUntil,
 	)
 	var i Email
 	err := row.Scan(
@@ -202,311 +7387,622 @@ func (q *Queries) InsertEmail(ctx context.Context, arg InsertEmailParams) (Email
 		&i.EmailFrom,
 		&i.EmailSubject,
 		&i.EmailBody,
+		&i.Status,
+		&i.RetryCount,
+		&i.ErrorMessage,
+		&i.NextAttemptAt,
+		&i.LockedUntil,
+		&i.SentAt,
+		&i.EmailLogID,
+		&i.ProviderMessageID,
+		&i.AgencyID,
+		&i.UnsubscribeToken,
 	)
 	return i, err
 }
 
-const insertEmailAttachment = `-- name: InsertEmailAttachment :one
-insert into email_attachments (id, email_id, file_name, content_type) values ($1, $2, $3, $4) returning id, created, email_id, file_name, content_type
+const updateEmailLogDelivery = `-- name: UpdateEmailLogDelivery :exec
+update email_logs
+set status = $1,
+    retry_count = $2,
+    error_message = $3,
+    provider_message_id = $4,
+    sent_at = $5
+where id = $6
 `
 
-type InsertEmailAttachmentParams struct {
-	ID          uuid.UUID `json:"id"`
-	EmailID     uuid.UUID `json:"email_id"`
-	FileName    string    `json:"file_name"`
-	ContentType string    `json:"content_type"`
+type UpdateEmailLogDeliveryParams struct {
+	Status            string         `json:"status"`
+	RetryCount        int32          `json:"retry_count"`
+	ErrorMessage      sql.NullString `json:"error_message"`
+	ProviderMessageID sql.NullString `json:"provider_message_id"`
+	SentAt            sql.NullTime   `json:"sent_at"`
+	ID                uuid.UUID      `json:"id"`
 }
 
-func (q *Queries) InsertEmailAttachment(ctx context.Context, arg InsertEmailAttachmentParams) (EmailAttachment, error) {
-	row := q.db.QueryRowContext(ctx, insertEmailAttachment,
+// Mirrors an outbox email's delivery onto the email log it was sent for
+func (q *Queries) UpdateEmailLogDelivery(ctx context.Context, arg UpdateEmailLogDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, updateEmailLogDelivery,
+		arg.Status,
+		arg.RetryCount,
+		arg.ErrorMessage,
+		arg.ProviderMessageID,
+		arg.SentAt,
 		arg.ID,
-		arg.EmailID,
-		arg.FileName,
-		arg.ContentType,
-	)
-	var i EmailAttachment
-	err := row.Scan(
-		&i.ID,
-		&i.Created,
-		&i.EmailID,
-		&i.FileName,
-		&i.ContentType,
 	)
-	return i, err
+	return err
 }
 
-const insertFile = `-- name: InsertFile :one
-insert into files (id, user_id, file_key, file_name, file_size, content_type) values ($1, $2, $3, $4, $5, $6) returning id, created, updated, user_id, file_key, file_name, file_size, content_type
+const updateEmailLogEvent = `-- name: UpdateEmailLogEvent :many
+update email_logs
+set status = case
+        when $1::text = 'complained' then 'complained'
+        when $1::text = 'bounced' and status <> 'complained' then 'bounced'
+        when $1::text = 'opened' and status in ('pending', 'sent', 'delivered') then 'opened'
+        when $1::text = 'delivered' and status in ('pending', 'sent') then 'delivered'
+        else status
+    end,
+    delivered_at = case when $1::text = 'delivered' then coalesce(delivered_at, $2::timestamptz) else delivered_at end,
+    opened_at = case when $1::text = 'opened' then coalesce(opened_at, $2::timestamptz) else opened_at end,
+    bounced_at = case when $1::text = 'bounced' then coalesce(bounced_at, $2::timestamptz) else bounced_at end,
+    complained_at = case when $1::text = 'complained' then coalesce(complained_at, $2::timestamptz) else complained_at end,
+    error_message = case when $1::text = 'bounced' then $3::text else error_message end
+where provider_message_id = $4::text
+returning agency_id, recipient_email
 `
 
-type InsertFileParams struct {
-	ID          uuid.UUID `json:"id"`
-	UserID      uuid.UUID `json:"user_id"`
-	FileKey     string    `json:"file_key"`
-	FileName    string    `json:"file_name"`
-	FileSize    int64     `json:"file_size"`
-	ContentType string    `json:"content_type"`
+type UpdateEmailLogEventParams struct {
+	Event             string    `json:"event"`
+	OccurredAt        time.Time `json:"occurred_at"`
+	Reason            string    `json:"reason"`
+	ProviderMessageID string    `json:"provider_message_id"`
 }
 
-func (q *Queries) InsertFile(ctx context.Context, arg InsertFileParams) (File, error) {
-	row := q.db.QueryRowContext(ctx, insertFile,
-		arg.ID,
-		arg.UserID,
-		arg.FileKey,
-		arg.FileName,
-		arg.FileSize,
-		arg.ContentType,
+type UpdateEmailLogEventRow struct {
+	AgencyID       uuid.UUID `json:"agency_id"`
+	RecipientEmail string    `json:"recipient_email"`
+}
+
+// Records an event a provider reported for the emails it sent with a
+// message ID, returning their agencies and recipients. Events can arrive
+// out of order, so a status only moves forward: delivered, then opened. A
+// bounce or a complaint overrides them, and a complaint is never replaced.
+func (q *Queries) UpdateEmailLogEvent(ctx context.Context, arg UpdateEmailLogEventParams) ([]UpdateEmailLogEventRow, error) {
+	rows, err := q.db.QueryContext(ctx, updateEmailLogEvent,
+		arg.Event,
+		arg.OccurredAt,
+		arg.Reason,
+		arg.ProviderMessageID,
 	)
-	var i File
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UpdateEmailLogEventRow
+	for rows.Next() {
+		var i UpdateEmailLogEventRow
+		if err := rows.Scan(&i.AgencyID, &i.RecipientEmail); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateEmailSent = `-- name: UpdateEmailSent :one
+update emails
+set status = 'sent', provider_message_id = $1, sent_at = current_timestamp, locked_until = null, updated = current_timestamp
+where id = $2 and status = 'pending' and locked_until = $3
+returning id, created, updated, user_id, email_to, email_from, email_subject, email_body, status, retry_count, error_message, next_attempt_at, locked_until, sent_at, email_log_id, provider_message_id, agency_id, unsubscribe_token
+`
+
+type UpdateEmailSentParams struct {
+	ProviderMessageID string       `json:"provider_message_id"`
+	ID                uuid.UUID    `json:"id"`
+	ClaimedUntil      sql.NullTime `json:"claimed_until"`
+}
+
+// Only applies while the caller still holds the lease it claimed the email
+// with, so a worker whose lease ran out cannot overwrite the outcome of the
+// worker that took the email over
+func (q *Queries) UpdateEmailSent(ctx context.Context, arg UpdateEmailSentParams) (Email, error) {
+	row := q.db.QueryRowContext(ctx, updateEmailSent, arg.ProviderMessageID, arg.ID, arg.ClaimedUntil)
+	var i Email
 	err := row.Scan(
 		&i.ID,
 		&i.Created,
 		&i.Updated,
 		&i.UserID,
-		&i.FileKey,
-		&i.FileName,
-		&i.FileSize,
-		&i.ContentType,
+		&i.EmailTo,
+		&i.EmailFrom,
+		&i.EmailSubject,
+		&i.EmailBody,
+		&i.Status,
+		&i.RetryCount,
+		&i.ErrorMessage,
+		&i.NextAttemptAt,
+		&i.LockedUntil,
+		&i.SentAt,
+		&i.EmailLogID,
+		&i.ProviderMessageID,
+		&i.AgencyID,
+		&i.UnsubscribeToken,
 	)
 	return i, err
 }
 
-const insertNote = `-- name: InsertNote :one
-insert into notes (id, user_id, title, category, content) values ($1, $2, $3, $4, $5) returning id, created, updated, user_id, title, category, content
+const updateFormSubmissionFailed = `-- name: UpdateFormSubmissionFailed :exec
+UPDATE form_submissions
+SET processing_attempts = processing_attempts + 1,
+    processing_error = $1
+WHERE id = $2
 `
 
-type InsertNoteParams struct {
-	ID       uuid.UUID `json:"id"`
-	UserID   uuid.UUID `json:"user_id"`
-	Title    string    `json:"title"`
-	Category string    `json:"category"`
-	Content  string    `json:"content"`
+type UpdateFormSubmissionFailedParams struct {
+	ProcessingError string    `json:"processing_error"`
+	ID              uuid.UUID `json:"id"`
 }
 
-func (q *Queries) InsertNote(ctx context.Context, arg InsertNoteParams) (Note, error) {
-	row := q.db.QueryRowContext(ctx, insertNote,
+func (q *Queries) UpdateFormSubmissionFailed(ctx context.Context, arg UpdateFormSubmissionFailedParams) error {
+	_, err := q.db.ExecContext(ctx, updateFormSubmissionFailed, arg.ProcessingError, arg.ID)
+	return err
+}
+
+const updateFormSubmissionProcessed = `-- name: UpdateFormSubmissionProcessed :one
+UPDATE form_submissions
+SET client_id = $1::uuid,
+    consultation_id = $2::uuid,
+    client_business_name = $3,
+    client_email = $4,
+    status = 'processed',
+    processed_at = CURRENT_TIMESTAMP,
+    processing_attempts = processing_attempts + 1,
+    processing_error = ''
+WHERE id = $5
+RETURNING id, form_id, agency_id, slug, client_id, client_business_name, client_email, data, current_step, completion_percentage, started_at, last_activity_at, consultation_id, proposal_id, contract_id, metadata, status, created_at, submitted_at, processed_at, form_version, processing_attempts, processing_error
+`
+
+type UpdateFormSubmissionProcessedParams struct {
+	ClientID           uuid.UUID `json:"client_id"`
+	ConsultationID     uuid.UUID `json:"consultation_id"`
+	ClientBusinessName string    `json:"client_business_name"`
+	ClientEmail        string    `json:"client_email"`
+	ID                 uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateFormSubmissionProcessed(ctx context.Context, arg UpdateFormSubmissionProcessedParams) (FormSubmission, error) {
+	row := q.db.QueryRowContext(ctx, updateFormSubmissionProcessed,
+		arg.ClientID,
+		arg.ConsultationID,
+		arg.ClientBusinessName,
+		arg.ClientEmail,
 		arg.ID,
-		arg.UserID,
-		arg.Title,
-		arg.Category,
-		arg.Content,
 	)
-	var i Note
+	var i FormSubmission
 	err := row.Scan(
 		&i.ID,
-		&i.Created,
-		&i.Updated,
-		&i.UserID,
-		&i.Title,
-		&i.Category,
-		&i.Content,
+		&i.FormID,
+		&i.AgencyID,
+		&i.Slug,
+		&i.ClientID,
+		&i.ClientBusinessName,
+		&i.ClientEmail,
+		&i.Data,
+		&i.CurrentStep,
+		&i.CompletionPercentage,
+		&i.StartedAt,
+		&i.LastActivityAt,
+		&i.ConsultationID,
+		&i.ProposalID,
+		&i.ContractID,
+		&i.Metadata,
+		&i.Status,
+		&i.CreatedAt,
+		&i.SubmittedAt,
+		&i.ProcessedAt,
+		&i.FormVersion,
+		&i.ProcessingAttempts,
+		&i.ProcessingError,
 	)
 	return i, err
 }
 
-const insertToken = `-- name: InsertToken :one
-insert into tokens (id, expires, target, callback) values ($1, $2, $3, $4) returning id, expires, target, callback
+const updateFormSubmissionVersion = `-- name: UpdateFormSubmissionVersion :exec
+UPDATE form_submissions
+SET data = $1,
+    metadata = $2,
+    form_version = $3,
+    current_step = $4,
+    completion_percentage = $5
+WHERE id = $6
 `
 
-type InsertTokenParams struct {
-	ID       string    `json:"id"`
-	Expires  time.Time `json:"expires"`
-	Target   string    `json:"target"`
-	Callback string    `json:"callback"`
+type UpdateFormSubmissionVersionParams struct {
+	Data                 json.RawMessage `json:"data"`
+	Metadata             json.RawMessage `json:"metadata"`
+	FormVersion          int32           `json:"form_version"`
+	CurrentStep          int32           `json:"current_step"`
+	CompletionPercentage int32           `json:"completion_percentage"`
+	ID                   uuid.UUID       `json:"id"`
 }
 
-func (q *Queries) InsertToken(ctx context.Context, arg InsertTokenParams) (Token, error) {
-	row := q.db.QueryRowContext(ctx, insertToken,
+func (q *Queries) UpdateFormSubmissionVersion(ctx context.Context, arg UpdateFormSubmissionVersionParams) error {
+	_, err := q.db.ExecContext(ctx, updateFormSubmissionVersion,
+		arg.Data,
+		arg.Metadata,
+		arg.FormVersion,
+		arg.CurrentStep,
+		arg.CompletionPercentage,
 		arg.ID,
-		arg.Expires,
-		arg.Target,
-		arg.Callback,
-	)
-	var i Token
-	err := row.Scan(
-		&i.ID,
-		&i.Expires,
-		&i.Target,
-		&i.Callback,
 	)
-	return i, err
+	return err
 }
 
-const insertUser = `-- name: InsertUser :one
-insert into users (id, email, access, sub, avatar, api_key) values ($1, $2, $3, $4, $5, $6) returning id, created, updated, email, phone, access, sub, avatar, customer_id, subscription_id, subscription_end, api_key, default_agency_id, suspended, suspended_at, suspended_reason
+const updateInvoice = `-- name: UpdateInvoice :one
+UPDATE invoices
+SET
+    client_business_name = $2,
+    client_contact_name = $3,
+    client_email = $4,
+    client_phone = $5,
+    client_address = $6,
+    client_abn = $7,
+    issue_date = $8,
+    due_date = $9,
+    discount_amount = $10,
+    discount_description = $11,
+    payment_terms = $12,
+    payment_terms_custom = $13,
+    notes = $14,
+    public_notes = $15,
+    online_payment_enabled = $16,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, created_at, updated_at, agency_id, proposal_id, contract_id, client_id, invoice_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_address, client_abn, issue_date, due_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, payment_terms, payment_terms_custom, notes, public_notes, view_count, last_viewed_at, sent_at, paid_at, payment_method, payment_reference, payment_notes, amount_paid, pdf_url, pdf_generated_at, stripe_payment_link_id, stripe_payment_link_url, stripe_payment_intent_id, stripe_checkout_session_id, online_payment_enabled, created_by, quotation_id
 `
 
-type InsertUserParams struct {
-	ID     uuid.UUID `json:"id"`
-	Email  string    `json:"email"`
-	Access int64     `json:"access"`
-	Sub    string    `json:"sub"`
-	Avatar string    `json:"avatar"`
-	ApiKey string    `json:"api_key"`
+type UpdateInvoiceParams struct {
+	ID                   uuid.UUID   `json:"id"`
+	ClientBusinessName   string      `json:"client_business_name"`
+	ClientContactName    string      `json:"client_contact_name"`
+	ClientEmail          string      `json:"client_email"`
+	ClientPhone          string      `json:"client_phone"`
+	ClientAddress        string      `json:"client_address"`
+	ClientAbn            string      `json:"client_abn"`
+	IssueDate            time.Time   `json:"issue_date"`
+	DueDate              time.Time   `json:"due_date"`
+	DiscountAmount       money.Money `json:"discount_amount"`
+	DiscountDescription  string      `json:"discount_description"`
+	PaymentTerms         string      `json:"payment_terms"`
+	PaymentTermsCustom   string      `json:"payment_terms_custom"`
+	Notes                string      `json:"notes"`
+	PublicNotes          string      `json:"public_notes"`
+	OnlinePaymentEnabled bool        `json:"online_payment_enabled"`
 }
 
-func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, insertUser,
+func (q *Queries) UpdateInvoice(ctx context.Context, arg UpdateInvoiceParams) (Invoice, error) {
+	row := q.db.QueryRowContext(ctx, updateInvoice,
 		arg.ID,
-		arg.Email,
-		arg.Access,
-		arg.Sub,
-		arg.Avatar,
-		arg.ApiKey,
+		arg.ClientBusinessName,
+		arg.ClientContactName,
+		arg.ClientEmail,
+		arg.ClientPhone,
+		arg.ClientAddress,
+		arg.ClientAbn,
+		arg.IssueDate,
+		arg.DueDate,
+		arg.DiscountAmount,
+		arg.DiscountDescription,
+		arg.PaymentTerms,
+		arg.PaymentTermsCustom,
+		arg.Notes,
+		arg.PublicNotes,
+		arg.OnlinePaymentEnabled,
 	)
-	var i User
+	var i Invoice
 	err := row.Scan(
 		&i.ID,
-		&i.Created,
-		&i.Updated,
-		&i.Email,
-		&i.Phone,
-		&i.Access,
-		&i.Sub,
-		&i.Avatar,
-		&i.CustomerID,
-		&i.SubscriptionID,
-		&i.SubscriptionEnd,
-		&i.ApiKey,
-		&i.DefaultAgencyID,
-		&i.Suspended,
-		&i.SuspendedAt,
-		&i.SuspendedReason,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.ContractID,
+		&i.ClientID,
+		&i.InvoiceNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ClientAbn,
+		&i.IssueDate,
+		&i.DueDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.PaymentTerms,
+		&i.PaymentTermsCustom,
+		&i.Notes,
+		&i.PublicNotes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.PaidAt,
+		&i.PaymentMethod,
+		&i.PaymentReference,
+		&i.PaymentNotes,
+		&i.AmountPaid,
+		&i.PdfUrl,
+		&i.PdfGeneratedAt,
+		&i.StripePaymentLinkID,
+		&i.StripePaymentLinkUrl,
+		&i.StripePaymentIntentID,
+		&i.StripeCheckoutSessionID,
+		&i.OnlinePaymentEnabled,
+		&i.CreatedBy,
+		&i.QuotationID,
 	)
 	return i, err
 }
 
-const selectEmailAttachments = `-- name: SelectEmailAttachments :many
-select id, created, email_id, file_name, content_type from email_attachments where email_id = $1
-`
-
-func (q *Queries) SelectEmailAttachments(ctx context.Context, emailID uuid.UUID) ([]EmailAttachment, error) {
-	rows, err := q.db.QueryContext(ctx, selectEmailAttachments, emailID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []EmailAttachment
-	for rows.Next() {
-		var i EmailAttachment
-		if err := rows.Scan(
-			&i.ID,
-			&i.Created,
-			&i.EmailID,
-			&i.FileName,
-			&i.ContentType,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+const updateInvoiceLineItem = `-- name: UpdateInvoiceLineItem :one
+UPDATE invoice_line_items
+SET
+    description = $2,
+    quantity = $3,
+    unit_price = $4,
+    amount = $5,
+    is_taxable = $6,
+    sort_order = $7,
+    category = $8,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, created_at, updated_at, invoice_id, description, quantity, unit_price, amount, is_taxable, sort_order, category, package_id, addon_id
+`
+
+type UpdateInvoiceLineItemParams struct {
+	ID          uuid.UUID      `json:"id"`
+	Description string         `json:"description"`
+	Quantity    money.Decimal  `json:"quantity"`
+	UnitPrice   money.Money    `json:"unit_price"`
+	Amount      money.Money    `json:"amount"`
+	IsTaxable   bool           `json:"is_taxable"`
+	SortOrder   int32          `json:"sort_order"`
+	Category    sql.NullString `json:"category"`
 }
 
-const selectEmails = `-- name: SelectEmails :many
-select id, created, updated, user_id, email_to, email_from, email_subject, email_body from emails where user_id = $1
+func (q *Queries) UpdateInvoiceLineItem(ctx context.Context, arg UpdateInvoiceLineItemParams) (InvoiceLineItem, error) {
+	row := q.db.QueryRowContext(ctx, updateInvoiceLineItem,
+		arg.ID,
+		arg.Description,
+		arg.Quantity,
+		arg.UnitPrice,
+		arg.Amount,
+		arg.IsTaxable,
+		arg.SortOrder,
+		arg.Category,
+	)
+	var i InvoiceLineItem
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.InvoiceID,
+		&i.Description,
+		&i.Quantity,
+		&i.UnitPrice,
+		&i.Amount,
+		&i.IsTaxable,
+		&i.SortOrder,
+		&i.Category,
+		&i.PackageID,
+		&i.AddonID,
+	)
+	return i, err
+}
+
+const updateInvoicePdf = `-- name: UpdateInvoicePdf :exec
+UPDATE invoices
+SET
+    pdf_url = $1,
+    pdf_generated_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
 `
 
-func (q *Queries) SelectEmails(ctx context.Context, userID uuid.UUID) ([]Email, error) {
-	rows, err := q.db.QueryContext(ctx, selectEmails, userID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Email
-	for rows.Next() {
-		var i Email
-		if err := rows.Scan(
-			&i.ID,
-			&i.Created,
-			&i.Updated,
-			&i.UserID,
-			&i.EmailTo,
-			&i.EmailFrom,
-			&i.EmailSubject,
-			&i.EmailBody,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type UpdateInvoicePdfParams struct {
+	PdfUrl sql.NullString `json:"pdf_url"`
+	ID     uuid.UUID      `json:"id"`
 }
 
-const selectFile = `-- name: SelectFile :one
-select id, created, updated, user_id, file_key, file_name, file_size, content_type from files where id = $1
+func (q *Queries) UpdateInvoicePdf(ctx context.Context, arg UpdateInvoicePdfParams) error {
+	_, err := q.db.ExecContext(ctx, updateInvoicePdf, arg.PdfUrl, arg.ID)
+	return err
+}
+
+const updateInvoiceStatus = `-- name: UpdateInvoiceStatus :one
+UPDATE invoices
+SET
+    status = $1,
+    sent_at = COALESCE($2, sent_at),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $3 AND status = $4
+RETURNING id, created_at, updated_at, agency_id, proposal_id, contract_id, client_id, invoice_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_address, client_abn, issue_date, due_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, payment_terms, payment_terms_custom, notes, public_notes, view_count, last_viewed_at, sent_at, paid_at, payment_method, payment_reference, payment_notes, amount_paid, pdf_url, pdf_generated_at, stripe_payment_link_id, stripe_payment_link_url, stripe_payment_intent_id, stripe_checkout_session_id, online_payment_enabled, created_by, quotation_id
 `
 
-func (q *Queries) SelectFile(ctx context.Context, id uuid.UUID) (File, error) {
-	row := q.db.QueryRowContext(ctx, selectFile, id)
-	var i File
+type UpdateInvoiceStatusParams struct {
+	Status     string       `json:"status"`
+	SentAt     sql.NullTime `json:"sent_at"`
+	ID         uuid.UUID    `json:"id"`
+	FromStatus string       `json:"from_status"`
+}
+
+func (q *Queries) UpdateInvoiceStatus(ctx context.Context, arg UpdateInvoiceStatusParams) (Invoice, error) {
+	row := q.db.QueryRowContext(ctx, updateInvoiceStatus,
+		arg.Status,
+		arg.SentAt,
+		arg.ID,
+		arg.FromStatus,
+	)
+	var i Invoice
 	err := row.Scan(
 		&i.ID,
-		&i.Created,
-		&i.Updated,
-		&i.UserID,
-		&i.FileKey,
-		&i.FileName,
-		&i.FileSize,
-		&i.ContentType,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.ContractID,
+		&i.ClientID,
+		&i.InvoiceNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ClientAbn,
+		&i.IssueDate,
+		&i.DueDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.PaymentTerms,
+		&i.PaymentTermsCustom,
+		&i.Notes,
+		&i.PublicNotes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.PaidAt,
+		&i.PaymentMethod,
+		&i.PaymentReference,
+		&i.PaymentNotes,
+		&i.AmountPaid,
+		&i.PdfUrl,
+		&i.PdfGeneratedAt,
+		&i.StripePaymentLinkID,
+		&i.StripePaymentLinkUrl,
+		&i.StripePaymentIntentID,
+		&i.StripeCheckoutSessionID,
+		&i.OnlinePaymentEnabled,
+		&i.CreatedBy,
+		&i.QuotationID,
 	)
 	return i, err
 }
 
-const selectFiles = `-- name: SelectFiles :many
-select id, created, updated, user_id, file_key, file_name, file_size, content_type from files where user_id = $1
+const updateInvoiceTotals = `-- name: UpdateInvoiceTotals :one
+UPDATE invoices
+SET
+    subtotal = $1,
+    gst_amount = $2,
+    total = $3,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $4
+RETURNING id, created_at, updated_at, agency_id, proposal_id, contract_id, client_id, invoice_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_address, client_abn, issue_date, due_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, payment_terms, payment_terms_custom, notes, public_notes, view_count, last_viewed_at, sent_at, paid_at, payment_method, payment_reference, payment_notes, amount_paid, pdf_url, pdf_generated_at, stripe_payment_link_id, stripe_payment_link_url, stripe_payment_intent_id, stripe_checkout_session_id, online_payment_enabled, created_by, quotation_id
 `
 
-func (q *Queries) SelectFiles(ctx context.Context, userID uuid.UUID) ([]File, error) {
-	rows, err := q.db.QueryContext(ctx, selectFiles, userID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []File
-	for rows.Next() {
-		var i File
-		if err := rows.Scan(
-			&i.ID,
-			&i.Created,
-			&i.Updated,
-			&i.UserID,
-			&i.FileKey,
-			&i.FileName,
-			&i.FileSize,
-			&i.ContentType,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type UpdateInvoiceTotalsParams struct {
+	Subtotal  money.Money `json:"subtotal"`
+	GstAmount money.Money `json:"gst_amount"`
+	Total     money.Money `json:"total"`
+	ID        uuid.UUID   `json:"id"`
 }
 
-const selectNote = `-- name: SelectNote :one
-select id, created, updated, user_id, title, category, content from notes where id = $1
+func (q *Queries) UpdateInvoiceTotals(ctx context.Context, arg UpdateInvoiceTotalsParams) (Invoice, error) {
+	row := q.db.QueryRowContext(ctx, updateInvoiceTotals,
+		arg.Subtotal,
+		arg.GstAmount,
+		arg.Total,
+		arg.ID,
+	)
+	var i Invoice
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ProposalID,
+		&i.ContractID,
+		&i.ClientID,
+		&i.InvoiceNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.ClientAbn,
+		&i.IssueDate,
+		&i.DueDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.PaymentTerms,
+		&i.PaymentTermsCustom,
+		&i.Notes,
+		&i.PublicNotes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.PaidAt,
+		&i.PaymentMethod,
+		&i.PaymentReference,
+		&i.PaymentNotes,
+		&i.AmountPaid,
+		&i.PdfUrl,
+		&i.PdfGeneratedAt,
+		&i.StripePaymentLinkID,
+		&i.StripePaymentLinkUrl,
+		&i.StripePaymentIntentID,
+		&i.StripeCheckoutSessionID,
+		&i.OnlinePaymentEnabled,
+		&i.CreatedBy,
+		&i.QuotationID,
+	)
+	return i, err
+}
+
+const updateNote = `-- name: UpdateNote :one
+update notes set title = $1, category = $2, content = $3 where id = $4 returning id, created, updated, user_id, title, category, content
 `
 
-func (q *Queries) SelectNote(ctx context.Context, id uuid.UUID) (Note, error) {
-	row := q.db.QueryRowContext(ctx, selectNote, id)
+type UpdateNoteParams struct {
+	Title    string    `json:"title"`
+	Category string    `json:"category"`
+	Content  string    `json:"content"`
+	ID       uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, error) {
+	row := q.db.QueryRowContext(ctx, updateNote,
+		arg.Title,
+		arg.Category,
+		arg.Content,
+		arg.ID,
+	)
 	var i Note
 	err := row.Scan(
 		&i.ID,
@@ -520,300 +8016,824 @@ func (q *Queries) SelectNote(ctx context.Context, id uuid.UUID) (Note, error) {
 	return i, err
 }
 
-const selectNotes = `-- name: SelectNotes :many
-select id, created, updated, user_id, title, category, content from notes where user_id = $1 order by created desc limit $2 offset $3
+const updatePasskeyUsed = `-- name: UpdatePasskeyUsed :execrows
+UPDATE passkeys
+SET sign_count = $1, last_used_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+  AND (sign_count < $1 OR (sign_count = 0 AND $1 = 0))
 `
 
-type SelectNotesParams struct {
-	UserID uuid.UUID `json:"user_id"`
-	Limit  int32     `json:"limit"`
-	Offset int32     `json:"offset"`
+type UpdatePasskeyUsedParams struct {
+	SignCount int64     `json:"sign_count"`
+	ID        uuid.UUID `json:"id"`
 }
 
-func (q *Queries) SelectNotes(ctx context.Context, arg SelectNotesParams) ([]Note, error) {
-	rows, err := q.db.QueryContext(ctx, selectNotes, arg.UserID, arg.Limit, arg.Offset)
+func (q *Queries) UpdatePasskeyUsed(ctx context.Context, arg UpdatePasskeyUsedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updatePasskeyUsed, arg.SignCount, arg.ID)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Note
-	for rows.Next() {
-		var i Note
-		if err := rows.Scan(
-			&i.ID,
-			&i.Created,
-			&i.Updated,
-			&i.UserID,
-			&i.Title,
-			&i.Category,
-			&i.Content,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected()
 }
 
-const selectToken = `-- name: SelectToken :one
-select id, expires, target, callback from tokens where id = $1
+const updateProposal = `-- name: UpdateProposal :one
+UPDATE proposals
+SET
+    client_business_name = $2,
+    client_contact_name = $3,
+    client_email = $4,
+    client_phone = $5,
+    client_website = $6,
+    title = $7,
+    cover_image = $8,
+    performance_data = $9,
+    current_issues = $10,
+    compliance_issues = $11,
+    performance_standards = $12,
+    local_advantage_content = $13,
+    closing_content = $14,
+    executive_summary = $15,
+    selected_package_id = $16,
+    selected_addons = $17,
+    custom_pricing = $18,
+    valid_until = $19,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND agency_id = $20 AND status NOT IN ('accepted', 'declined', 'expired')
+RETURNING id, created_at, updated_at, agency_id, consultation_id, client_id, proposal_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_website, title, cover_image, performance_data, opportunity_content, current_issues, compliance_issues, roi_analysis, performance_standards, local_advantage_content, proposed_pages, timeline, closing_content, selected_package_id, selected_addons, custom_pricing, valid_until, view_count, last_viewed_at, sent_at, accepted_at, declined_at, client_comments, decline_reason, revision_request_notes, revision_requested_at, executive_summary, next_steps, consultation_pain_points, consultation_goals, consultation_challenges, created_by
 `
 
-func (q *Queries) SelectToken(ctx context.Context, id string) (Token, error) {
-	row := q.db.QueryRowContext(ctx, selectToken, id)
-	var i Token
+type UpdateProposalParams struct {
+	ID                    uuid.UUID             `json:"id"`
+	ClientBusinessName    string                `json:"client_business_name"`
+	ClientContactName     string                `json:"client_contact_name"`
+	ClientEmail           string                `json:"client_email"`
+	ClientPhone           string                `json:"client_phone"`
+	ClientWebsite         string                `json:"client_website"`
+	Title                 string                `json:"title"`
+	CoverImage            sql.NullString        `json:"cover_image"`
+	PerformanceData       json.RawMessage       `json:"performance_data"`
+	CurrentIssues         json.RawMessage       `json:"current_issues"`
+	ComplianceIssues      json.RawMessage       `json:"compliance_issues"`
+	PerformanceStandards  json.RawMessage       `json:"performance_standards"`
+	LocalAdvantageContent string                `json:"local_advantage_content"`
+	ClosingContent        string                `json:"closing_content"`
+	ExecutiveSummary      string                `json:"executive_summary"`
+	SelectedPackageID     uuid.NullUUID         `json:"selected_package_id"`
+	SelectedAddons        json.RawMessage       `json:"selected_addons"`
+	CustomPricing         pqtype.NullRawMessage `json:"custom_pricing"`
+	ValidUntil            sql.NullTime          `json:"valid_until"`
+	AgencyID              uuid.UUID             `json:"agency_id"`
+}
+
+// The status guard mirrors proposal.Status.IsEditable, so a proposal frozen
+// after it was read is not changed.
+func (q *Queries) UpdateProposal(ctx context.Context, arg UpdateProposalParams) (Proposal, error) {
+	row := q.db.QueryRowContext(ctx, updateProposal,
+		arg.ID,
+		arg.ClientBusinessName,
+		arg.ClientContactName,
+		arg.ClientEmail,
+		arg.ClientPhone,
+		arg.ClientWebsite,
+		arg.Title,
+		arg.CoverImage,
+		arg.PerformanceData,
+		arg.CurrentIssues,
+		arg.ComplianceIssues,
+		arg.PerformanceStandards,
+		arg.LocalAdvantageContent,
+		arg.ClosingContent,
+		arg.ExecutiveSummary,
+		arg.SelectedPackageID,
+		arg.SelectedAddons,
+		arg.CustomPricing,
+		arg.ValidUntil,
+		arg.AgencyID,
+	)
+	var i Proposal
 	err := row.Scan(
 		&i.ID,
-		&i.Expires,
-		&i.Target,
-		&i.Callback,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ConsultationID,
+		&i.ClientID,
+		&i.ProposalNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientWebsite,
+		&i.Title,
+		&i.CoverImage,
+		&i.PerformanceData,
+		&i.OpportunityContent,
+		&i.CurrentIssues,
+		&i.ComplianceIssues,
+		&i.RoiAnalysis,
+		&i.PerformanceStandards,
+		&i.LocalAdvantageContent,
+		&i.ProposedPages,
+		&i.Timeline,
+		&i.ClosingContent,
+		&i.SelectedPackageID,
+		&i.SelectedAddons,
+		&i.CustomPricing,
+		&i.ValidUntil,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.AcceptedAt,
+		&i.DeclinedAt,
+		&i.ClientComments,
+		&i.DeclineReason,
+		&i.RevisionRequestNotes,
+		&i.RevisionRequestedAt,
+		&i.ExecutiveSummary,
+		&i.NextSteps,
+		&i.ConsultationPainPoints,
+		&i.ConsultationGoals,
+		&i.ConsultationChallenges,
+		&i.CreatedBy,
 	)
 	return i, err
 }
 
-const selectUser = `-- name: SelectUser :one
-select id, created, updated, email, phone, access, sub, avatar, customer_id, subscription_id, subscription_end, api_key, default_agency_id, suspended, suspended_at, suspended_reason from users where id = $1
+const updateProposalNextSteps = `-- name: UpdateProposalNextSteps :one
+UPDATE proposals
+SET next_steps = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND agency_id = $3 AND status NOT IN ('accepted', 'declined', 'expired')
+RETURNING id, created_at, updated_at, agency_id, consultation_id, client_id, proposal_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_website, title, cover_image, performance_data, opportunity_content, current_issues, compliance_issues, roi_analysis, performance_standards, local_advantage_content, proposed_pages, timeline, closing_content, selected_package_id, selected_addons, custom_pricing, valid_until, view_count, last_viewed_at, sent_at, accepted_at, declined_at, client_comments, decline_reason, revision_request_notes, revision_requested_at, executive_summary, next_steps, consultation_pain_points, consultation_goals, consultation_challenges, created_by
 `
 
-func (q *Queries) SelectUser(ctx context.Context, id uuid.UUID) (User, error) {
-	row := q.db.QueryRowContext(ctx, selectUser, id)
-	var i User
+type UpdateProposalNextStepsParams struct {
+	ID        uuid.UUID       `json:"id"`
+	NextSteps json.RawMessage `json:"next_steps"`
+	AgencyID  uuid.UUID       `json:"agency_id"`
+}
+
+func (q *Queries) UpdateProposalNextSteps(ctx context.Context, arg UpdateProposalNextStepsParams) (Proposal, error) {
+	row := q.db.QueryRowContext(ctx, updateProposalNextSteps, arg.ID, arg.NextSteps, arg.AgencyID)
+	var i Proposal
 	err := row.Scan(
 		&i.ID,
-		&i.Created,
-		&i.Updated,
-		&i.Email,
-		&i.Phone,
-		&i.Access,
-		&i.Sub,
-		&i.Avatar,
-		&i.CustomerID,
-		&i.SubscriptionID,
-		&i.SubscriptionEnd,
-		&i.ApiKey,
-		&i.DefaultAgencyID,
-		&i.Suspended,
-		&i.SuspendedAt,
-		&i.SuspendedReason,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ConsultationID,
+		&i.ClientID,
+		&i.ProposalNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientWebsite,
+		&i.Title,
+		&i.CoverImage,
+		&i.PerformanceData,
+		&i.OpportunityContent,
+		&i.CurrentIssues,
+		&i.ComplianceIssues,
+		&i.RoiAnalysis,
+		&i.PerformanceStandards,
+		&i.LocalAdvantageContent,
+		&i.ProposedPages,
+		&i.Timeline,
+		&i.ClosingContent,
+		&i.SelectedPackageID,
+		&i.SelectedAddons,
+		&i.CustomPricing,
+		&i.ValidUntil,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.AcceptedAt,
+		&i.DeclinedAt,
+		&i.ClientComments,
+		&i.DeclineReason,
+		&i.RevisionRequestNotes,
+		&i.RevisionRequestedAt,
+		&i.ExecutiveSummary,
+		&i.NextSteps,
+		&i.ConsultationPainPoints,
+		&i.ConsultationGoals,
+		&i.ConsultationChallenges,
+		&i.CreatedBy,
 	)
 	return i, err
 }
 
-const selectUserByCustomerID = `-- name: SelectUserByCustomerID :one
-select id, created, updated, email, phone, access, sub, avatar, customer_id, subscription_id, subscription_end, api_key, default_agency_id, suspended, suspended_at, suspended_reason from users where customer_id = $1
+const updateProposalOpportunity = `-- name: UpdateProposalOpportunity :one
+UPDATE proposals
+SET opportunity_content = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND agency_id = $3 AND status NOT IN ('accepted', 'declined', 'expired')
+RETURNING id, created_at, updated_at, agency_id, consultation_id, client_id, proposal_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_website, title, cover_image, performance_data, opportunity_content, current_issues, compliance_issues, roi_analysis, performance_standards, local_advantage_content, proposed_pages, timeline, closing_content, selected_package_id, selected_addons, custom_pricing, valid_until, view_count, last_viewed_at, sent_at, accepted_at, declined_at, client_comments, decline_reason, revision_request_notes, revision_requested_at, executive_summary, next_steps, consultation_pain_points, consultation_goals, consultation_challenges, created_by
 `
 
-func (q *Queries) SelectUserByCustomerID(ctx context.Context, customerID string) (User, error) {
-	row := q.db.QueryRowContext(ctx, selectUserByCustomerID, customerID)
-	var i User
+type UpdateProposalOpportunityParams struct {
+	ID                 uuid.UUID `json:"id"`
+	OpportunityContent string    `json:"opportunity_content"`
+	AgencyID           uuid.UUID `json:"agency_id"`
+}
+
+func (q *Queries) UpdateProposalOpportunity(ctx context.Context, arg UpdateProposalOpportunityParams) (Proposal, error) {
+	row := q.db.QueryRowContext(ctx, updateProposalOpportunity, arg.ID, arg.OpportunityContent, arg.AgencyID)
+	var i Proposal
 	err := row.Scan(
 		&i.ID,
-		&i.Created,
-		&i.Updated,
-		&i.Email,
-		&i.Phone,
-		&i.Access,
-		&i.Sub,
-		&i.Avatar,
-		&i.CustomerID,
-		&i.SubscriptionID,
-		&i.SubscriptionEnd,
-		&i.ApiKey,
-		&i.DefaultAgencyID,
-		&i.Suspended,
-		&i.SuspendedAt,
-		&i.SuspendedReason,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ConsultationID,
+		&i.ClientID,
+		&i.ProposalNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientWebsite,
+		&i.Title,
+		&i.CoverImage,
+		&i.PerformanceData,
+		&i.OpportunityContent,
+		&i.CurrentIssues,
+		&i.ComplianceIssues,
+		&i.RoiAnalysis,
+		&i.PerformanceStandards,
+		&i.LocalAdvantageContent,
+		&i.ProposedPages,
+		&i.Timeline,
+		&i.ClosingContent,
+		&i.SelectedPackageID,
+		&i.SelectedAddons,
+		&i.CustomPricing,
+		&i.ValidUntil,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.AcceptedAt,
+		&i.DeclinedAt,
+		&i.ClientComments,
+		&i.DeclineReason,
+		&i.RevisionRequestNotes,
+		&i.RevisionRequestedAt,
+		&i.ExecutiveSummary,
+		&i.NextSteps,
+		&i.ConsultationPainPoints,
+		&i.ConsultationGoals,
+		&i.ConsultationChallenges,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const updateProposalProposedPages = `-- name: UpdateProposalProposedPages :one
+UPDATE proposals
+SET proposed_pages = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND agency_id = $3 AND status NOT IN ('accepted', 'declined', 'expired')
+RETURNING id, created_at, updated_at, agency_id, consultation_id, client_id, proposal_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_website, title, cover_image, performance_data, opportunity_content, current_issues, compliance_issues, roi_analysis, performance_standards, local_advantage_content, proposed_pages, timeline, closing_content, selected_package_id, selected_addons, custom_pricing, valid_until, view_count, last_viewed_at, sent_at, accepted_at, declined_at, client_comments, decline_reason, revision_request_notes, revision_requested_at, executive_summary, next_steps, consultation_pain_points, consultation_goals, consultation_challenges, created_by
+`
+
+type UpdateProposalProposedPagesParams struct {
+	ID            uuid.UUID       `json:"id"`
+	ProposedPages json.RawMessage `json:"proposed_pages"`
+	AgencyID      uuid.UUID       `json:"agency_id"`
+}
+
+func (q *Queries) UpdateProposalProposedPages(ctx context.Context, arg UpdateProposalProposedPagesParams) (Proposal, error) {
+	row := q.db.QueryRowContext(ctx, updateProposalProposedPages, arg.ID, arg.ProposedPages, arg.AgencyID)
+	var i Proposal
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ConsultationID,
+		&i.ClientID,
+		&i.ProposalNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientWebsite,
+		&i.Title,
+		&i.CoverImage,
+		&i.PerformanceData,
+		&i.OpportunityContent,
+		&i.CurrentIssues,
+		&i.ComplianceIssues,
+		&i.RoiAnalysis,
+		&i.PerformanceStandards,
+		&i.LocalAdvantageContent,
+		&i.ProposedPages,
+		&i.Timeline,
+		&i.ClosingContent,
+		&i.SelectedPackageID,
+		&i.SelectedAddons,
+		&i.CustomPricing,
+		&i.ValidUntil,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.AcceptedAt,
+		&i.DeclinedAt,
+		&i.ClientComments,
+		&i.DeclineReason,
+		&i.RevisionRequestNotes,
+		&i.RevisionRequestedAt,
+		&i.ExecutiveSummary,
+		&i.NextSteps,
+		&i.ConsultationPainPoints,
+		&i.ConsultationGoals,
+		&i.ConsultationChallenges,
+		&i.CreatedBy,
 	)
 	return i, err
 }
 
-const selectUserByEmail = `-- name: SelectUserByEmail :one
-select id, created, updated, email, phone, access, sub, avatar, customer_id, subscription_id, subscription_end, api_key, default_agency_id, suspended, suspended_at, suspended_reason from users where email = $1
+const updateProposalRoiAnalysis = `-- name: UpdateProposalRoiAnalysis :one
+UPDATE proposals
+SET roi_analysis = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND agency_id = $3 AND status NOT IN ('accepted', 'declined', 'expired')
+RETURNING id, created_at, updated_at, agency_id, consultation_id, client_id, proposal_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_website, title, cover_image, performance_data, opportunity_content, current_issues, compliance_issues, roi_analysis, performance_standards, local_advantage_content, proposed_pages, timeline, closing_content, selected_package_id, selected_addons, custom_pricing, valid_until, view_count, last_viewed_at, sent_at, accepted_at, declined_at, client_comments, decline_reason, revision_request_notes, revision_requested_at, executive_summary, next_steps, consultation_pain_points, consultation_goals, consultation_challenges, created_by
 `
 
-func (q *Queries) SelectUserByEmail(ctx context.Context, email string) (User, error) {
-	row := q.db.QueryRowContext(ctx, selectUserByEmail, email)
-	var i User
+type UpdateProposalRoiAnalysisParams struct {
+	ID          uuid.UUID       `json:"id"`
+	RoiAnalysis json.RawMessage `json:"roi_analysis"`
+	AgencyID    uuid.UUID       `json:"agency_id"`
+}
+
+func (q *Queries) UpdateProposalRoiAnalysis(ctx context.Context, arg UpdateProposalRoiAnalysisParams) (Proposal, error) {
+	row := q.db.QueryRowContext(ctx, updateProposalRoiAnalysis, arg.ID, arg.RoiAnalysis, arg.AgencyID)
+	var i Proposal
 	err := row.Scan(
 		&i.ID,
-		&i.Created,
-		&i.Updated,
-		&i.Email,
-		&i.Phone,
-		&i.Access,
-		&i.Sub,
-		&i.Avatar,
-		&i.CustomerID,
-		&i.SubscriptionID,
-		&i.SubscriptionEnd,
-		&i.ApiKey,
-		&i.DefaultAgencyID,
-		&i.Suspended,
-		&i.SuspendedAt,
-		&i.SuspendedReason,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ConsultationID,
+		&i.ClientID,
+		&i.ProposalNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientWebsite,
+		&i.Title,
+		&i.CoverImage,
+		&i.PerformanceData,
+		&i.OpportunityContent,
+		&i.CurrentIssues,
+		&i.ComplianceIssues,
+		&i.RoiAnalysis,
+		&i.PerformanceStandards,
+		&i.LocalAdvantageContent,
+		&i.ProposedPages,
+		&i.Timeline,
+		&i.ClosingContent,
+		&i.SelectedPackageID,
+		&i.SelectedAddons,
+		&i.CustomPricing,
+		&i.ValidUntil,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.AcceptedAt,
+		&i.DeclinedAt,
+		&i.ClientComments,
+		&i.DeclineReason,
+		&i.RevisionRequestNotes,
+		&i.RevisionRequestedAt,
+		&i.ExecutiveSummary,
+		&i.NextSteps,
+		&i.ConsultationPainPoints,
+		&i.ConsultationGoals,
+		&i.ConsultationChallenges,
+		&i.CreatedBy,
 	)
 	return i, err
 }
 
-const selectUserByEmailAndSub = `-- name: SelectUserByEmailAndSub :one
-select id, created, updated, email, phone, access, sub, avatar, customer_id, subscription_id, subscription_end, api_key, default_agency_id, suspended, suspended_at, suspended_reason from users where email = $1 and sub = $2
+const updateProposalStatus = `-- name: UpdateProposalStatus :one
+UPDATE proposals
+SET
+    status = $1,
+    sent_at = COALESCE($2, sent_at),
+    accepted_at = COALESCE($3, accepted_at),
+    declined_at = COALESCE($4, declined_at),
+    revision_requested_at = COALESCE($5, revision_requested_at),
+    client_comments = $6,
+    decline_reason = $7,
+    revision_request_notes = $8,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $9 AND status = $10
+RETURNING id, created_at, updated_at, agency_id, consultation_id, client_id, proposal_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_website, title, cover_image, performance_data, opportunity_content, current_issues, compliance_issues, roi_analysis, performance_standards, local_advantage_content, proposed_pages, timeline, closing_content, selected_package_id, selected_addons, custom_pricing, valid_until, view_count, last_viewed_at, sent_at, accepted_at, declined_at, client_comments, decline_reason, revision_request_notes, revision_requested_at, executive_summary, next_steps, consultation_pain_points, consultation_goals, consultation_challenges, created_by
 `
 
-type SelectUserByEmailAndSubParams struct {
-	Email string `json:"email"`
-	Sub   string `json:"sub"`
+type UpdateProposalStatusParams struct {
+	Status               string       `json:"status"`
+	SentAt               sql.NullTime `json:"sent_at"`
+	AcceptedAt           sql.NullTime `json:"accepted_at"`
+	DeclinedAt           sql.NullTime `json:"declined_at"`
+	RevisionRequestedAt  sql.NullTime `json:"revision_requested_at"`
+	ClientComments       string       `json:"client_comments"`
+	DeclineReason        string       `json:"decline_reason"`
+	RevisionRequestNotes string       `json:"revision_request_notes"`
+	ID                   uuid.UUID    `json:"id"`
+	FromStatus           string       `json:"from_status"`
 }
 
-func (q *Queries) SelectUserByEmailAndSub(ctx context.Context, arg SelectUserByEmailAndSubParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, selectUserByEmailAndSub, arg.Email, arg.Sub)
-	var i User
+func (q *Queries) UpdateProposalStatus(ctx context.Context, arg UpdateProposalStatusParams) (Proposal, error) {
+	row := q.db.QueryRowContext(ctx, updateProposalStatus,
+		arg.Status,
+		arg.SentAt,
+		arg.AcceptedAt,
+		arg.DeclinedAt,
+		arg.RevisionRequestedAt,
+		arg.ClientComments,
+		arg.DeclineReason,
+		arg.RevisionRequestNotes,
+		arg.ID,
+		arg.FromStatus,
+	)
+	var i Proposal
 	err := row.Scan(
 		&i.ID,
-		&i.Created,
-		&i.Updated,
-		&i.Email,
-		&i.Phone,
-		&i.Access,
-		&i.Sub,
-		&i.Avatar,
-		&i.CustomerID,
-		&i.SubscriptionID,
-		&i.SubscriptionEnd,
-		&i.ApiKey,
-		&i.DefaultAgencyID,
-		&i.Suspended,
-		&i.SuspendedAt,
-		&i.SuspendedReason,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ConsultationID,
+		&i.ClientID,
+		&i.ProposalNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientWebsite,
+		&i.Title,
+		&i.CoverImage,
+		&i.PerformanceData,
+		&i.OpportunityContent,
+		&i.CurrentIssues,
+		&i.ComplianceIssues,
+		&i.RoiAnalysis,
+		&i.PerformanceStandards,
+		&i.LocalAdvantageContent,
+		&i.ProposedPages,
+		&i.Timeline,
+		&i.ClosingContent,
+		&i.SelectedPackageID,
+		&i.SelectedAddons,
+		&i.CustomPricing,
+		&i.ValidUntil,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.AcceptedAt,
+		&i.DeclinedAt,
+		&i.ClientComments,
+		&i.DeclineReason,
+		&i.RevisionRequestNotes,
+		&i.RevisionRequestedAt,
+		&i.ExecutiveSummary,
+		&i.NextSteps,
+		&i.ConsultationPainPoints,
+		&i.ConsultationGoals,
+		&i.ConsultationChallenges,
+		&i.CreatedBy,
 	)
 	return i, err
 }
 
-const selectUsers = `-- name: SelectUsers :many
-select id, created, updated, email, phone, access, sub, avatar, customer_id, subscription_id, subscription_end, api_key, default_agency_id, suspended, suspended_at, suspended_reason from users
+const updateProposalTimeline = `-- name: UpdateProposalTimeline :one
+UPDATE proposals
+SET timeline = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND agency_id = $3 AND status NOT IN ('accepted', 'declined', 'expired')
+RETURNING id, created_at, updated_at, agency_id, consultation_id, client_id, proposal_number, slug, status, client_business_name, client_contact_name, client_email, client_phone, client_website, title, cover_image, performance_data, opportunity_content, current_issues, compliance_issues, roi_analysis, performance_standards, local_advantage_content, proposed_pages, timeline, closing_content, selected_package_id, selected_addons, custom_pricing, valid_until, view_count, last_viewed_at, sent_at, accepted_at, declined_at, client_comments, decline_reason, revision_request_notes, revision_requested_at, executive_summary, next_steps, consultation_pain_points, consultation_goals, consultation_challenges, created_by
 `
 
-func (q *Queries) SelectUsers(ctx context.Context) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, selectUsers)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []User
-	for rows.Next() {
-		var i User
-		if err := rows.Scan(
-			&i.ID,
-			&i.Created,
-			&i.Updated,
-			&i.Email,
-			&i.Phone,
-			&i.Access,
-			&i.Sub,
-			&i.Avatar,
-			&i.CustomerID,
-			&i.SubscriptionID,
-			&i.SubscriptionEnd,
-			&i.ApiKey,
-			&i.DefaultAgencyID,
-			&i.Suspended,
-			&i.SuspendedAt,
-			&i.SuspendedReason,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type UpdateProposalTimelineParams struct {
+	ID       uuid.UUID       `json:"id"`
+	Timeline json.RawMessage `json:"timeline"`
+	AgencyID uuid.UUID       `json:"agency_id"`
 }
 
-const updateAgencyStripeCustomer = `-- name: UpdateAgencyStripeCustomer :exec
-UPDATE agencies
-SET stripe_customer_id = $2, updated_at = CURRENT_TIMESTAMP
+func (q *Queries) UpdateProposalTimeline(ctx context.Context, arg UpdateProposalTimelineParams) (Proposal, error) {
+	row := q.db.QueryRowContext(ctx, updateProposalTimeline, arg.ID, arg.Timeline, arg.AgencyID)
+	var i Proposal
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ConsultationID,
+		&i.ClientID,
+		&i.ProposalNumber,
+		&i.Slug,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientWebsite,
+		&i.Title,
+		&i.CoverImage,
+		&i.PerformanceData,
+		&i.OpportunityContent,
+		&i.CurrentIssues,
+		&i.ComplianceIssues,
+		&i.RoiAnalysis,
+		&i.PerformanceStandards,
+		&i.LocalAdvantageContent,
+		&i.ProposedPages,
+		&i.Timeline,
+		&i.ClosingContent,
+		&i.SelectedPackageID,
+		&i.SelectedAddons,
+		&i.CustomPricing,
+		&i.ValidUntil,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.AcceptedAt,
+		&i.DeclinedAt,
+		&i.ClientComments,
+		&i.DeclineReason,
+		&i.RevisionRequestNotes,
+		&i.RevisionRequestedAt,
+		&i.ExecutiveSummary,
+		&i.NextSteps,
+		&i.ConsultationPainPoints,
+		&i.ConsultationGoals,
+		&i.ConsultationChallenges,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const updateQuotation = `-- name: UpdateQuotation :one
+UPDATE quotations
+SET
+    client_id = $2,
+    quotation_name = $3,
+    client_business_name = $4,
+    client_contact_name = $5,
+    client_email = $6,
+    client_phone = $7,
+    client_address = $8,
+    site_address = $9,
+    site_reference = $10,
+    prepared_date = $11,
+    expiry_date = $12,
+    discount_amount = $13,
+    discount_description = $14,
+    terms_blocks = $15,
+    options_notes = $16,
+    notes = $17,
+    updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
+RETURNING id, created_at, updated_at, agency_id, client_id, template_id, quotation_number, slug, quotation_name, status, client_business_name, client_contact_name, client_email, client_phone, client_address, site_address, site_reference, prepared_date, expiry_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, terms_blocks, options_notes, notes, view_count, last_viewed_at, sent_at, declined_at, decline_reason, accepted_by_name, accepted_by_title, accepted_at, acceptance_ip, created_by
 `
 
-type UpdateAgencyStripeCustomerParams struct {
-	ID               uuid.UUID `json:"id"`
-	StripeCustomerID string    `json:"stripe_customer_id"`
+type UpdateQuotationParams struct {
+	ID                  uuid.UUID       `json:"id"`
+	ClientID            uuid.NullUUID   `json:"client_id"`
+	QuotationName       string          `json:"quotation_name"`
+	ClientBusinessName  string          `json:"client_business_name"`
+	ClientContactName   string          `json:"client_contact_name"`
+	ClientEmail         string          `json:"client_email"`
+	ClientPhone         string          `json:"client_phone"`
+	ClientAddress       string          `json:"client_address"`
+	SiteAddress         string          `json:"site_address"`
+	SiteReference       string          `json:"site_reference"`
+	PreparedDate        time.Time       `json:"prepared_date"`
+	ExpiryDate          time.Time       `json:"expiry_date"`
+	DiscountAmount      money.Money     `json:"discount_amount"`
+	DiscountDescription string          `json:"discount_description"`
+	TermsBlocks         json.RawMessage `json:"terms_blocks"`
+	OptionsNotes        string          `json:"options_notes"`
+	Notes               string          `json:"notes"`
 }
 
-func (q *Queries) UpdateAgencyStripeCustomer(ctx context.Context, arg UpdateAgencyStripeCustomerParams) error {
-	_, err := q.db.ExecContext(ctx, updateAgencyStripeCustomer, arg.ID, arg.StripeCustomerID)
-	return err
+func (q *Queries) UpdateQuotation(ctx context.Context, arg UpdateQuotationParams) (Quotation, error) {
+	row := q.db.QueryRowContext(ctx, updateQuotation,
+		arg.ID,
+		arg.ClientID,
+		arg.QuotationName,
+		arg.ClientBusinessName,
+		arg.ClientContactName,
+		arg.ClientEmail,
+		arg.ClientPhone,
+		arg.ClientAddress,
+		arg.SiteAddress,
+		arg.SiteReference,
+		arg.PreparedDate,
+		arg.ExpiryDate,
+		arg.DiscountAmount,
+		arg.DiscountDescription,
+		arg.TermsBlocks,
+		arg.OptionsNotes,
+		arg.Notes,
+	)
+	var i Quotation
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ClientID,
+		&i.TemplateID,
+		&i.QuotationNumber,
+		&i.Slug,
+		&i.QuotationName,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.SiteAddress,
+		&i.SiteReference,
+		&i.PreparedDate,
+		&i.ExpiryDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.TermsBlocks,
+		&i.OptionsNotes,
+		&i.Notes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.DeclinedAt,
+		&i.DeclineReason,
+		&i.AcceptedByName,
+		&i.AcceptedByTitle,
+		&i.AcceptedAt,
+		&i.AcceptanceIp,
+		&i.CreatedBy,
+	)
+	return i, err
 }
 
-const updateAgencySubscription = `-- name: UpdateAgencySubscription :exec
-UPDATE agencies
+const updateQuotationStatus = `-- name: UpdateQuotationStatus :one
+UPDATE quotations
 SET
-    subscription_tier = $2,
-    subscription_id = $3,
-    subscription_end = $4,
+    status = $1,
+    sent_at = COALESCE($2, sent_at),
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
+WHERE id = $3 AND status = $4
+RETURNING id, created_at, updated_at, agency_id, client_id, template_id, quotation_number, slug, quotation_name, status, client_business_name, client_contact_name, client_email, client_phone, client_address, site_address, site_reference, prepared_date, expiry_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, terms_blocks, options_notes, notes, view_count, last_viewed_at, sent_at, declined_at, decline_reason, accepted_by_name, accepted_by_title, accepted_at, acceptance_ip, created_by
 `
 
-type UpdateAgencySubscriptionParams struct {
-	ID               uuid.UUID    `json:"id"`
-	SubscriptionTier string       `json:"subscription_tier"`
-	SubscriptionID   string       `json:"subscription_id"`
-	SubscriptionEnd  sql.NullTime `json:"subscription_end"`
+type UpdateQuotationStatusParams struct {
+	Status     string       `json:"status"`
+	SentAt     sql.NullTime `json:"sent_at"`
+	ID         uuid.UUID    `json:"id"`
+	FromStatus string       `json:"from_status"`
 }
 
-func (q *Queries) UpdateAgencySubscription(ctx context.Context, arg UpdateAgencySubscriptionParams) error {
-	_, err := q.db.ExecContext(ctx, updateAgencySubscription,
+func (q *Queries) UpdateQuotationStatus(ctx context.Context, arg UpdateQuotationStatusParams) (Quotation, error) {
+	row := q.db.QueryRowContext(ctx, updateQuotationStatus,
+		arg.Status,
+		arg.SentAt,
 		arg.ID,
-		arg.SubscriptionTier,
-		arg.SubscriptionID,
-		arg.SubscriptionEnd,
+		arg.FromStatus,
 	)
-	return err
+	var i Quotation
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ClientID,
+		&i.TemplateID,
+		&i.QuotationNumber,
+		&i.Slug,
+		&i.QuotationName,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.SiteAddress,
+		&i.SiteReference,
+		&i.PreparedDate,
+		&i.ExpiryDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.TermsBlocks,
+		&i.OptionsNotes,
+		&i.Notes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.DeclinedAt,
+		&i.DeclineReason,
+		&i.AcceptedByName,
+		&i.AcceptedByTitle,
+		&i.AcceptedAt,
+		&i.AcceptanceIp,
+		&i.CreatedBy,
+	)
+	return i, err
 }
 
-const updateNote = `-- name: UpdateNote :one
-update notes set title = $1, category = $2, content = $3 where id = $4 returning id, created, updated, user_id, title, category, content
+const updateQuotationTotals = `-- name: UpdateQuotationTotals :one
+UPDATE quotations
+SET
+    subtotal = $2,
+    gst_amount = $3,
+    total = $4,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, created_at, updated_at, agency_id, client_id, template_id, quotation_number, slug, quotation_name, status, client_business_name, client_contact_name, client_email, client_phone, client_address, site_address, site_reference, prepared_date, expiry_date, subtotal, discount_amount, discount_description, gst_amount, total, gst_registered, gst_rate, terms_blocks, options_notes, notes, view_count, last_viewed_at, sent_at, declined_at, decline_reason, accepted_by_name, accepted_by_title, accepted_at, acceptance_ip, created_by
 `
 
-type UpdateNoteParams struct {
-	Title    string    `json:"title"`
-	Category string    `json:"category"`
-	Content  string    `json:"content"`
-	ID       uuid.UUID `json:"id"`
+type UpdateQuotationTotalsParams struct {
+	ID        uuid.UUID   `json:"id"`
+	Subtotal  money.Money `json:"subtotal"`
+	GstAmount money.Money `json:"gst_amount"`
+	Total     money.Money `json:"total"`
 }
 
-func (q *Queries) UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, error) {
-	row := q.db.QueryRowContext(ctx, updateNote,
-		arg.Title,
-		arg.Category,
-		arg.Content,
+func (q *Queries) UpdateQuotationTotals(ctx context.Context, arg UpdateQuotationTotalsParams) (Quotation, error) {
+	row := q.db.QueryRowContext(ctx, updateQuotationTotals,
 		arg.ID,
+		arg.Subtotal,
+		arg.GstAmount,
+		arg.Total,
 	)
-	var i Note
+	var i Quotation
 	err := row.Scan(
 		&i.ID,
-		&i.Created,
-		&i.Updated,
-		&i.UserID,
-		&i.Title,
-		&i.Category,
-		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.ClientID,
+		&i.TemplateID,
+		&i.QuotationNumber,
+		&i.Slug,
+		&i.QuotationName,
+		&i.Status,
+		&i.ClientBusinessName,
+		&i.ClientContactName,
+		&i.ClientEmail,
+		&i.ClientPhone,
+		&i.ClientAddress,
+		&i.SiteAddress,
+		&i.SiteReference,
+		&i.PreparedDate,
+		&i.ExpiryDate,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.DiscountDescription,
+		&i.GstAmount,
+		&i.Total,
+		&i.GstRegistered,
+		&i.GstRate,
+		&i.TermsBlocks,
+		&i.OptionsNotes,
+		&i.Notes,
+		&i.ViewCount,
+		&i.LastViewedAt,
+		&i.SentAt,
+		&i.DeclinedAt,
+		&i.DeclineReason,
+		&i.AcceptedByName,
+		&i.AcceptedByTitle,
+		&i.AcceptedAt,
+		&i.AcceptanceIp,
+		&i.CreatedBy,
 	)
 	return i, err
 }
 
 const updateToken = `-- name: UpdateToken :exec
-update tokens set expires = $1 where id = $2 returning id, expires, target, callback
+update tokens set expires = $1 where id = $2 returning id, expires, target, callback, family, created, rotated, user_agent, ip
 `
 
 type UpdateTokenParams struct {
@@ -987,3 +9007,168 @@ func (q *Queries) UpdateUserSubscription(ctx context.Context, arg UpdateUserSubs
 	)
 	return err
 }
+
+const updateUserTOTPSecret = `-- name: UpdateUserTOTPSecret :exec
+UPDATE user_totp SET secret = $2, updated_at = CURRENT_TIMESTAMP WHERE user_id = $1
+`
+
+type UpdateUserTOTPSecretParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Secret string    `json:"secret"`
+}
+
+func (q *Queries) UpdateUserTOTPSecret(ctx context.Context, arg UpdateUserTOTPSecretParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserTOTPSecret, arg.UserID, arg.Secret)
+	return err
+}
+
+const updateUserTOTPStep = `-- name: UpdateUserTOTPStep :execrows
+UPDATE user_totp
+SET last_step = $1, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $2 AND last_step < $1
+`
+
+type UpdateUserTOTPStepParams struct {
+	LastStep int64     `json:"last_step"`
+	UserID   uuid.UUID `json:"user_id"`
+}
+
+// Records an accepted code. Only a later time step than the last accepted
+// one is recorded, so each code is accepted once.
+func (q *Queries) UpdateUserTOTPStep(ctx context.Context, arg UpdateUserTOTPStepParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserTOTPStep, arg.LastStep, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertDocumentNumbering = `-- name: UpsertDocumentNumbering :one
+INSERT INTO agency_document_numbering (id, agency_id, document_type, format, reset_yearly, sequence_year)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (agency_id, document_type) DO UPDATE
+SET format = EXCLUDED.format,
+    reset_yearly = EXCLUDED.reset_yearly,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING id, created_at, updated_at, agency_id, document_type, format, reset_yearly, sequence_year
+`
+
+type UpsertDocumentNumberingParams struct {
+	ID           uuid.UUID `json:"id"`
+	AgencyID     uuid.UUID `json:"agency_id"`
+	DocumentType string    `json:"document_type"`
+	Format       string    `json:"format"`
+	ResetYearly  bool      `json:"reset_yearly"`
+	SequenceYear int32     `json:"sequence_year"`
+}
+
+func (q *Queries) UpsertDocumentNumbering(ctx context.Context, arg UpsertDocumentNumberingParams) (AgencyDocumentNumbering, error) {
+	row := q.db.QueryRowContext(ctx, upsertDocumentNumbering,
+		arg.ID,
+		arg.AgencyID,
+		arg.DocumentType,
+		arg.Format,
+		arg.ResetYearly,
+		arg.SequenceYear,
+	)
+	var i AgencyDocumentNumbering
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AgencyID,
+		&i.DocumentType,
+		&i.Format,
+		&i.ResetYearly,
+		&i.SequenceYear,
+	)
+	return i, err
+}
+
+const upsertEmailSuppression = `-- name: UpsertEmailSuppression :one
+INSERT INTO email_suppressions (id, agency_id, email, reason, detail)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (agency_id, email) DO UPDATE
+SET reason = EXCLUDED.reason,
+    detail = EXCLUDED.detail
+RETURNING id, created_at, agency_id, email, reason, detail
+`
+
+type UpsertEmailSuppressionParams struct {
+	ID       uuid.UUID `json:"id"`
+	AgencyID uuid.UUID `json:"agency_id"`
+	Email    string    `json:"email"`
+	Reason   string    `json:"reason"`
+	Detail   string    `json:"detail"`
+}
+
+// Suppresses an address, replacing the reason of one already suppressed
+func (q *Queries) UpsertEmailSuppression(ctx context.Context, arg UpsertEmailSuppressionParams) (EmailSuppression, error) {
+	row := q.db.QueryRowContext(ctx, upsertEmailSuppression,
+		arg.ID,
+		arg.AgencyID,
+		arg.Email,
+		arg.Reason,
+		arg.Detail,
+	)
+	var i EmailSuppression
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.AgencyID,
+		&i.Email,
+		&i.Reason,
+		&i.Detail,
+	)
+	return i, err
+}
+
+const upsertPendingUserTOTP = `-- name: UpsertPendingUserTOTP :one
+INSERT INTO user_totp (user_id, secret)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET secret = EXCLUDED.secret, last_step = 0, updated_at = CURRENT_TIMESTAMP
+WHERE user_totp.enabled_at IS NULL
+RETURNING user_id, created_at, updated_at, secret, enabled_at, last_step, failed_attempts, locked_until
+`
+
+type UpsertPendingUserTOTPParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Secret string    `json:"secret"`
+}
+
+// Starts enrolment with a new secret. An enabled secret is never replaced:
+// no row is returned for it.
+func (q *Queries) UpsertPendingUserTOTP(ctx context.Context, arg UpsertPendingUserTOTPParams) (UserTotp, error) {
+	row := q.db.QueryRowContext(ctx, upsertPendingUserTOTP, arg.UserID, arg.Secret)
+	var i UserTotp
+	err := row.Scan(
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Secret,
+		&i.EnabledAt,
+		&i.LastStep,
+		&i.FailedAttempts,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const useUserRecoveryCode = `-- name: UseUserRecoveryCode :execrows
+UPDATE user_recovery_codes SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+`
+
+type UseUserRecoveryCodeParams struct {
+	UserID   uuid.UUID `json:"user_id"`
+	CodeHash string    `json:"code_hash"`
+}
+
+func (q *Queries) UseUserRecoveryCode(ctx context.Context, arg UseUserRecoveryCodeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, useUserRecoveryCode, arg.UserID, arg.CodeHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
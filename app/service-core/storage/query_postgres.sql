@@ -10,6 +10,45 @@ update tokens set expires = $1 where id = $2 returning *;
 -- name: DeleteTokens :exec
 delete from tokens where expires < current_timestamp;
 
+-- name: InsertSessionToken :one
+-- Inserts a refresh token in a session's family of tokens
+insert into tokens (id, expires, target, callback, family, user_agent, ip)
+values ($1, $2, $3, '', $4, $5, $6)
+returning *;
+
+-- name: RotateToken :one
+-- Marks a refresh token as exchanged. Only one exchange of a token succeeds.
+update tokens set rotated = current_timestamp
+where id = $1 and rotated is null and target <> ''
+returning *;
+
+-- name: RevokeTokenFamily :exec
+update tokens set target = '' where family = sqlc.arg(family) and family <> '';
+
+-- name: SelectSessionActive :one
+-- A session is active while any token of its family is unrevoked and unexpired
+select exists (
+    select 1 from tokens
+    where family = sqlc.arg(family) and family <> '' and target <> '' and expires > current_timestamp
+)::boolean as active;
+
+-- name: SelectUserSessions :many
+-- Returns the current token of each of a user's active sessions, with when
+-- the session was started
+select t.*, (select min(f.created) from tokens f where f.family = t.family)::timestamptz as started
+from tokens t
+where t.target = sqlc.arg(user_id) and t.family <> '' and t.rotated is null and t.expires > current_timestamp
+order by t.created desc;
+
+-- name: RevokeUserSession :execrows
+update tokens set target = ''
+where family = sqlc.arg(family) and family <> '' and target = sqlc.arg(user_id);
+
+-- name: RevokeUserSessions :exec
+-- Revokes all of a user's sessions other than the one kept, if any
+update tokens set target = ''
+where target = sqlc.arg(user_id) and family <> '' and family <> sqlc.arg(keep_family);
+
 -- name: SelectUsers :many
 select * from users;
 
@@ -68,7 +107,7 @@ select * from files where user_id = $1;
 select * from files where id = $1;
 
 -- name: InsertFile :one
-insert into files (id, user_id, file_key, file_name, file_size, content_type) values ($1, $2, $3, $4, $5, $6) returning *;
+insert into files (id, user_id, file_key, file_name, file_size, content_type, agency_id) values ($1, $2, $3, $4, $5, $6, $7) returning *;
 
 -- name: DeleteFile :exec
 delete from files where id = $1;
@@ -76,14 +115,97 @@ delete from files where id = $1;
 -- name: SelectEmails :many
 select * from emails where user_id = $1;
 
+-- name: SelectEmailsByStatus :many
+select * from emails where user_id = $1 and status = $2 order by created desc;
+
 -- name: InsertEmail :one
-insert into emails (id, user_id, email_to, email_from, email_subject, email_body) values ($1, $2, $3, $4, $5, $6) returning *;
+insert into emails (id, user_id, email_to, email_from, email_subject, email_body, email_log_id, agency_id, unsubscribe_token) values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning *;
 
 -- name: SelectEmailAttachments :many
 select * from email_attachments where email_id = $1;
 
 -- name: InsertEmailAttachment :one
-insert into email_attachments (id, email_id, file_name, content_type) values ($1, $2, $3, $4) returning *;
+insert into email_attachments (id, email_id, file_name, content_type, file_id) values ($1, $2, $3, $4, $5) returning *;
+
+-- name: ClaimEmails :many
+-- Takes the emails that are due for sending, and those whose worker's lease
+-- has run out, leasing them to the caller. Emails claimed by another
+-- worker are skipped.
+update emails set locked_until = sqlc.arg(locked_until), updated = current_timestamp
+where id in (
+    select id from emails
+    where status = 'pending'
+      and next_attempt_at <= current_timestamp
+      and (locked_until is null or locked_until < current_timestamp)
+    order by next_attempt_at
+    limit sqlc.arg(row_limit)
+    for update skip locked
+)
+returning *;
+
+-- name: UpdateEmailSent :one
+-- Only applies while the caller still holds the lease it claimed the email
+-- with, so a worker whose lease ran out cannot overwrite the outcome of the
+-- worker that took the email over
+update emails
+set status = 'sent', provider_message_id = sqlc.arg(provider_message_id), sent_at = current_timestamp, locked_until = null, updated = current_timestamp
+where id = sqlc.arg(id) and status = 'pending' and locked_until = sqlc.arg(claimed_until)
+returning *;
+
+-- name: UpdateEmailFailed :one
+-- Records a failed attempt. The email is retried at next_attempt_at while
+-- its status is pending. Like UpdateEmailSent, it only applies under the
+-- caller's lease.
+update emails
+set status = sqlc.arg(status),
+    retry_count = retry_count + 1,
+    error_message = sqlc.arg(error_message),
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    locked_until = null,
+    updated = current_timestamp
+where id = sqlc.arg(id) and status = 'pending' and locked_until = sqlc.arg(claimed_until)
+returning *;
+
+-- name: RetryEmail :one
+-- Queues a failed email to be sent again, with a fresh set of retries
+update emails
+set status = 'pending', retry_count = 0, next_attempt_at = current_timestamp, updated = current_timestamp
+where id = $1 and user_id = $2 and status = 'failed'
+returning *;
+
+-- name: SelectEmailLogAgencyID :one
+select agency_id from email_logs where id = $1;
+
+-- name: UpdateEmailLogDelivery :exec
+-- Mirrors an outbox email's delivery onto the email log it was sent for
+update email_logs
+set status = sqlc.arg(status),
+    retry_count = sqlc.arg(retry_count),
+    error_message = sqlc.narg(error_message),
+    provider_message_id = sqlc.narg(provider_message_id),
+    sent_at = sqlc.narg(sent_at)
+where id = sqlc.arg(id);
+
+-- name: UpdateEmailLogEvent :many
+-- Records an event a provider reported for the emails it sent with a
+-- message ID, returning their agencies and recipients. Events can arrive
+-- out of order, so a status only moves forward: delivered, then opened. A
+-- bounce or a complaint overrides them, and a complaint is never replaced.
+update email_logs
+set status = case
+        when sqlc.arg(event)::text = 'complained' then 'complained'
+        when sqlc.arg(event)::text = 'bounced' and status <> 'complained' then 'bounced'
+        when sqlc.arg(event)::text = 'opened' and status in ('pending', 'sent', 'delivered') then 'opened'
+        when sqlc.arg(event)::text = 'delivered' and status in ('pending', 'sent') then 'delivered'
+        else status
+    end,
+    delivered_at = case when sqlc.arg(event)::text = 'delivered' then coalesce(delivered_at, sqlc.arg(occurred_at)::timestamptz) else delivered_at end,
+    opened_at = case when sqlc.arg(event)::text = 'opened' then coalesce(opened_at, sqlc.arg(occurred_at)::timestamptz) else opened_at end,
+    bounced_at = case when sqlc.arg(event)::text = 'bounced' then coalesce(bounced_at, sqlc.arg(occurred_at)::timestamptz) else bounced_at end,
+    complained_at = case when sqlc.arg(event)::text = 'complained' then coalesce(complained_at, sqlc.arg(occurred_at)::timestamptz) else complained_at end,
+    error_message = case when sqlc.arg(event)::text = 'bounced' then sqlc.arg(reason)::text else error_message end
+where provider_message_id = sqlc.arg(provider_message_id)::text
+returning agency_id, recipient_email;
 
 -- name: CountNotes :one
 select count(*) from notes where user_id = $1;
@@ -103,6 +225,253 @@ update notes set title = $1, category = $2, content = $3 where id = $4 returning
 -- name: DeleteNote :exec
 delete from notes where id = $1;
 
+-- =============================================================================
+-- Agency Membership Queries
+-- =============================================================================
+
+-- name: SelectAgencyMembership :one
+-- A membership counts once it is active in an active agency. Invited
+-- members must also have accepted; memberships created directly, such as
+-- the founding owner's, need no acceptance.
+SELECT m.* FROM agency_memberships m
+JOIN agencies a ON a.id = m.agency_id
+WHERE m.user_id = $1 AND m.agency_id = $2
+    AND m.status = 'active' AND a.status = 'active'
+    AND (m.accepted_at IS NOT NULL OR m.invited_at IS NULL);
+
+-- name: SelectDefaultAgencyMembership :one
+-- The agency a user works in by default: their default agency, else the
+-- one where they hold the highest role
+SELECT m.* FROM agency_memberships m
+JOIN agencies a ON a.id = m.agency_id
+JOIN users u ON u.id = m.user_id
+WHERE m.user_id = $1
+    AND m.status = 'active' AND a.status = 'active'
+    AND (m.accepted_at IS NOT NULL OR m.invited_at IS NULL)
+ORDER BY
+    (m.agency_id = u.default_agency_id) IS TRUE DESC,
+    CASE m.role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 ELSE 2 END,
+    m.created_at
+LIMIT 1;
+
+-- =============================================================================
+-- API Key Queries
+-- =============================================================================
+
+-- name: InsertAPIKey :one
+INSERT INTO api_keys (id, user_id, agency_id, name, prefix, key_hash, access, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: SelectUserAPIKeys :many
+-- A user's personal keys; agency keys are listed with their agency
+SELECT * FROM api_keys
+WHERE user_id = $1 AND agency_id IS NULL
+ORDER BY created_at DESC;
+
+-- name: SelectAgencyAPIKeys :many
+SELECT * FROM api_keys
+WHERE agency_id = sqlc.arg(agency_id)::uuid
+ORDER BY created_at DESC;
+
+-- name: SelectAPIKeyByHash :one
+-- The key with the user it acts as
+SELECT k.*, u.email, u.avatar, u.access AS user_access, u.subscription_end, u.suspended
+FROM api_keys k
+JOIN users u ON u.id = k.user_id
+WHERE k.key_hash = $1;
+
+-- name: RevokeUserAPIKey :one
+UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)::uuid AND user_id = sqlc.arg(user_id)::uuid
+    AND agency_id IS NULL AND revoked_at IS NULL
+RETURNING *;
+
+-- name: RevokeAgencyAPIKey :one
+UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)::uuid AND agency_id = sqlc.arg(agency_id)::uuid AND revoked_at IS NULL
+RETURNING *;
+
+-- name: UpdateAPIKeyLastUsed :exec
+-- Usage is recorded at most once a minute so busy keys do not write on
+-- every request
+UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute');
+
+-- =============================================================================
+-- Two-Factor Queries
+-- =============================================================================
+
+-- name: SelectUserTOTP :one
+SELECT * FROM user_totp WHERE user_id = $1;
+
+-- name: UpsertPendingUserTOTP :one
+-- Starts enrolment with a new secret. An enabled secret is never replaced:
+-- no row is returned for it.
+INSERT INTO user_totp (user_id, secret)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET secret = EXCLUDED.secret, last_step = 0, updated_at = CURRENT_TIMESTAMP
+WHERE user_totp.enabled_at IS NULL
+RETURNING *;
+
+-- name: EnableUserTOTP :one
+UPDATE user_totp
+SET enabled_at = CURRENT_TIMESTAMP, last_step = sqlc.arg(last_step), updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg(user_id) AND enabled_at IS NULL
+RETURNING *;
+
+-- name: UpdateUserTOTPStep :execrows
+-- Records an accepted code. Only a later time step than the last accepted
+-- one is recorded, so each code is accepted once.
+UPDATE user_totp
+SET last_step = sqlc.arg(last_step), updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg(user_id) AND last_step < sqlc.arg(last_step);
+
+-- name: UpdateUserTOTPSecret :exec
+UPDATE user_totp SET secret = $2, updated_at = CURRENT_TIMESTAMP WHERE user_id = $1;
+
+-- name: RecordUserTOTPFailure :exec
+-- Counts an invalid code. The user's codes are locked until locked_until
+-- once max_attempts have been invalid in a row, and the count starts over.
+UPDATE user_totp
+SET failed_attempts = CASE WHEN failed_attempts + 1 >= sqlc.arg(max_attempts)::integer THEN 0 ELSE failed_attempts + 1 END,
+    locked_until = CASE WHEN failed_attempts + 1 >= sqlc.arg(max_attempts)::integer THEN sqlc.arg(locked_until)::timestamptz ELSE locked_until END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg(user_id);
+
+-- name: ResetUserTOTPFailures :exec
+UPDATE user_totp SET failed_attempts = 0, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND failed_attempts > 0;
+
+-- name: DeleteUserTOTP :exec
+DELETE FROM user_totp WHERE user_id = $1;
+
+-- name: InsertUserRecoveryCode :exec
+INSERT INTO user_recovery_codes (id, user_id, code_hash) VALUES ($1, $2, $3);
+
+-- name: DeleteUserRecoveryCodes :exec
+DELETE FROM user_recovery_codes WHERE user_id = $1;
+
+-- name: UseUserRecoveryCode :execrows
+UPDATE user_recovery_codes SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg(user_id) AND code_hash = sqlc.arg(code_hash) AND used_at IS NULL;
+
+-- name: CountUserRecoveryCodes :one
+-- Counts the recovery codes a user has left
+SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: InsertUsedSessionToken :execrows
+-- Records a session token as used. No row is inserted for a token used
+-- before.
+INSERT INTO used_session_tokens (id, expires_at) VALUES ($1, $2)
+ON CONFLICT (id) DO NOTHING;
+
+-- name: DeleteExpiredUsedSessionTokens :exec
+DELETE FROM used_session_tokens WHERE expires_at < CURRENT_TIMESTAMP;
+
+-- =============================================================================
+-- Passkey Queries
+-- =============================================================================
+
+-- name: InsertPasskey :one
+INSERT INTO passkeys (id, user_id, name, credential_id, public_key, algorithm, sign_count, transports)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: SelectUserPasskeys :many
+SELECT * FROM passkeys WHERE user_id = $1 ORDER BY created_at;
+
+-- name: SelectPasskeyByCredentialID :one
+SELECT * FROM passkeys WHERE credential_id = $1;
+
+-- name: CountUserPasskeys :one
+SELECT COUNT(*) FROM passkeys WHERE user_id = $1;
+
+-- name: UpdatePasskeyUsed :execrows
+UPDATE passkeys
+SET sign_count = sqlc.arg(sign_count), last_used_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+  AND (sign_count < sqlc.arg(sign_count) OR (sign_count = 0 AND sqlc.arg(sign_count) = 0));
+
+-- name: DeleteUserPasskey :execrows
+DELETE FROM passkeys WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id);
+
+-- name: InsertPasskeyChallenge :exec
+INSERT INTO passkey_challenges (id, expires_at, purpose, user_id, return_url)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ConsumePasskeyChallenge :one
+-- Deletes a challenge and returns it, so each can only be answered once
+DELETE FROM passkey_challenges WHERE id = $1 RETURNING *;
+
+-- name: DeleteExpiredPasskeyChallenges :exec
+DELETE FROM passkey_challenges WHERE expires_at < CURRENT_TIMESTAMP;
+
+-- =============================================================================
+-- Email Template Queries
+-- =============================================================================
+
+-- name: SelectEmailTemplate :one
+-- Returns the latest version of an agency's template
+SELECT * FROM email_templates
+WHERE agency_id = $1 AND name = $2
+ORDER BY version DESC
+LIMIT 1;
+
+-- name: SelectEmailTemplates :many
+-- Returns the latest version of each of an agency's templates
+SELECT DISTINCT ON (name) * FROM email_templates
+WHERE agency_id = $1
+ORDER BY name, version DESC;
+
+-- name: SelectEmailTemplateVersions :many
+SELECT * FROM email_templates
+WHERE agency_id = $1 AND name = $2
+ORDER BY version DESC;
+
+-- name: InsertEmailTemplate :one
+-- Adds the next version of an agency's template
+INSERT INTO email_templates (id, agency_id, name, version, subject, body, created_by)
+VALUES (
+    sqlc.arg(id), sqlc.arg(agency_id), sqlc.arg(name),
+    (SELECT COALESCE(MAX(version), 0) + 1 FROM email_templates WHERE agency_id = sqlc.arg(agency_id) AND name = sqlc.arg(name)),
+    sqlc.arg(subject), sqlc.arg(body), sqlc.narg(created_by)
+)
+RETURNING *;
+
+-- name: DeleteEmailTemplates :execrows
+-- Removes every version of an agency's template, going back to the
+-- built-in one
+DELETE FROM email_templates WHERE agency_id = $1 AND name = $2;
+
+-- =============================================================================
+-- Email Suppression Queries
+-- =============================================================================
+
+-- name: SelectEmailSuppression :one
+SELECT * FROM email_suppressions WHERE agency_id = $1 AND email = $2;
+
+-- name: SelectEmailSuppressions :many
+SELECT * FROM email_suppressions
+WHERE agency_id = $1
+ORDER BY created_at DESC;
+
+-- name: UpsertEmailSuppression :one
+-- Suppresses an address, replacing the reason of one already suppressed
+INSERT INTO email_suppressions (id, agency_id, email, reason, detail)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (agency_id, email) DO UPDATE
+SET reason = EXCLUDED.reason,
+    detail = EXCLUDED.detail
+RETURNING *;
+
+-- name: DeleteEmailSuppression :execrows
+DELETE FROM email_suppressions WHERE id = $1 AND agency_id = $2;
+
+-- name: SelectEmailByUnsubscribeToken :one
+SELECT * FROM emails WHERE unsubscribe_token = $1;
+
 -- =============================================================================
 -- Agency Billing Queries (Platform Subscriptions)
 -- =============================================================================
@@ -148,4 +517,1189 @@ SET
     subscription_id = '',
     subscription_end = NULL,
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+-- =============================================================================
+-- Agency Activity Log Queries
+-- =============================================================================
+
+-- name: InsertActivityLog :exec
+INSERT INTO agency_activity_log (id, agency_id, user_id, action, entity_type, entity_id, old_values, new_values, metadata)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- =============================================================================
+-- Consultation Queries
+-- =============================================================================
+
+-- name: SelectConsultation :one
+SELECT * FROM consultations
+WHERE id = $1;
+
+-- name: SelectConsultationForUpdate :one
+SELECT * FROM consultations
+WHERE id = $1
+FOR UPDATE;
+
+-- name: CountConsultations :one
+SELECT count(*) FROM consultations
+WHERE agency_id = sqlc.arg(agency_id)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text);
+
+-- name: SelectConsultations :many
+SELECT * FROM consultations
+WHERE agency_id = sqlc.arg(agency_id)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+ORDER BY updated_at DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: InsertConsultation :one
+INSERT INTO consultations (
+    id,
+    user_id,
+    agency_id,
+    client_id,
+    business_name,
+    contact_person,
+    email,
+    phone,
+    website,
+    social_linkedin,
+    social_facebook,
+    social_instagram,
+    industry,
+    business_type,
+    website_status,
+    primary_challenges,
+    urgency_level,
+    primary_goals,
+    conversion_goal,
+    budget_range,
+    timeline,
+    design_styles,
+    admired_websites,
+    consultation_notes,
+    custom_data,
+    created_by,
+    status,
+    completion_percentage
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
+    $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28
+)
+RETURNING *;
+
+-- name: UpdateConsultation :one
+UPDATE consultations
+SET
+    client_id = $2,
+    business_name = $3,
+    contact_person = $4,
+    email = $5,
+    phone = $6,
+    website = $7,
+    social_linkedin = $8,
+    social_facebook = $9,
+    social_instagram = $10,
+    industry = $11,
+    business_type = $12,
+    website_status = $13,
+    primary_challenges = $14,
+    urgency_level = $15,
+    primary_goals = $16,
+    conversion_goal = $17,
+    budget_range = $18,
+    timeline = $19,
+    design_styles = $20,
+    admired_websites = $21,
+    consultation_notes = $22,
+    custom_data = $23,
+    status = $24,
+    completion_percentage = $25,
+    completed_at = $26,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: SelectConsultationVersions :many
+SELECT * FROM consultation_versions
+WHERE consultation_id = $1
+ORDER BY version_number DESC;
+
+-- name: SelectConsultationVersion :one
+SELECT * FROM consultation_versions
+WHERE consultation_id = $1 AND version_number = $2;
+
+-- name: SelectLatestConsultationVersionNumber :one
+SELECT COALESCE(MAX(version_number), 0)::integer AS version_number
+FROM consultation_versions
+WHERE consultation_id = $1;
+
+-- name: InsertConsultationVersion :one
+INSERT INTO consultation_versions (
+    id,
+    consultation_id,
+    user_id,
+    agency_id,
+    version_number,
+    status,
+    completion_percentage,
+    change_summary,
+    changed_fields,
+    snapshot
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- =============================================================================
+-- Proposal Queries
+-- =============================================================================
+
+-- name: CountProposals :one
+SELECT count(*) FROM proposals
+WHERE agency_id = sqlc.arg(agency_id)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text);
+
+-- name: SelectProposals :many
+SELECT * FROM proposals
+WHERE agency_id = sqlc.arg(agency_id)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: SelectProposal :one
+SELECT * FROM proposals
+WHERE id = $1;
+
+-- name: SelectProposalBySlug :one
+SELECT * FROM proposals
+WHERE slug = $1;
+
+-- name: InsertProposal :one
+INSERT INTO proposals (
+    id,
+    agency_id,
+    consultation_id,
+    client_id,
+    proposal_number,
+    slug,
+    status,
+    client_business_name,
+    client_contact_name,
+    client_email,
+    client_phone,
+    client_website,
+    title,
+    cover_image,
+    performance_data,
+    opportunity_content,
+    current_issues,
+    compliance_issues,
+    roi_analysis,
+    performance_standards,
+    local_advantage_content,
+    proposed_pages,
+    timeline,
+    closing_content,
+    selected_package_id,
+    selected_addons,
+    custom_pricing,
+    valid_until,
+    executive_summary,
+    next_steps,
+    consultation_pain_points,
+    consultation_goals,
+    consultation_challenges,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
+    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34
+) RETURNING *;
+
+-- name: UpdateProposal :one
+-- The status guard mirrors proposal.Status.IsEditable, so a proposal frozen
+-- after it was read is not changed.
+UPDATE proposals
+SET
+    client_business_name = $2,
+    client_contact_name = $3,
+    client_email = $4,
+    client_phone = $5,
+    client_website = $6,
+    title = $7,
+    cover_image = $8,
+    performance_data = $9,
+    current_issues = $10,
+    compliance_issues = $11,
+    performance_standards = $12,
+    local_advantage_content = $13,
+    closing_content = $14,
+    executive_summary = $15,
+    selected_package_id = $16,
+    selected_addons = $17,
+    custom_pricing = $18,
+    valid_until = $19,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND agency_id = $20 AND status NOT IN ('accepted', 'declined', 'expired')
+RETURNING *;
+
+-- name: UpdateProposalOpportunity :one
+UPDATE proposals
+SET opportunity_content = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND agency_id = $3 AND status NOT IN ('accepted', 'declined', 'expired')
+RETURNING *;
+
+-- name: UpdateProposalRoiAnalysis :one
+UPDATE proposals
+SET roi_analysis = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND agency_id = $3 AND status NOT IN ('accepted', 'declined', 'expired')
+RETURNING *;
+
+-- name: UpdateProposalProposedPages :one
+UPDATE proposals
+SET proposed_pages = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND agency_id = $3 AND status NOT IN ('accepted', 'declined', 'expired')
+RETURNING *;
+
+-- name: UpdateProposalTimeline :one
+UPDATE proposals
+SET timeline = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND agency_id = $3 AND status NOT IN ('accepted', 'declined', 'expired')
+RETURNING *;
+
+-- name: UpdateProposalNextSteps :one
+UPDATE proposals
+SET next_steps = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND agency_id = $3 AND status NOT IN ('accepted', 'declined', 'expired')
+RETURNING *;
+
+-- name: UpdateProposalStatus :one
+UPDATE proposals
+SET
+    status = sqlc.arg(status),
+    sent_at = COALESCE(sqlc.narg(sent_at), sent_at),
+    accepted_at = COALESCE(sqlc.narg(accepted_at), accepted_at),
+    declined_at = COALESCE(sqlc.narg(declined_at), declined_at),
+    revision_requested_at = COALESCE(sqlc.narg(revision_requested_at), revision_requested_at),
+    client_comments = sqlc.arg(client_comments),
+    decline_reason = sqlc.arg(decline_reason),
+    revision_request_notes = sqlc.arg(revision_request_notes),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status)
+RETURNING *;
+
+-- name: RecordProposalView :one
+UPDATE proposals
+SET
+    view_count = view_count + 1,
+    last_viewed_at = CURRENT_TIMESTAMP,
+    status = CASE WHEN status = 'sent' THEN 'viewed' ELSE status END
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteProposal :exec
+DELETE FROM proposals
+WHERE id = $1;
+
+-- =============================================================================
+-- Agency Package & Pricing Queries
+-- =============================================================================
+
+-- name: SelectAgencyProfile :one
+SELECT * FROM agency_profiles
+WHERE agency_id = $1;
+
+-- name: SelectAgencyPackage :one
+SELECT * FROM agency_packages
+WHERE id = $1;
+
+-- name: SelectAgencyAddonsByIDs :many
+SELECT * FROM agency_addons
+WHERE agency_id = sqlc.arg(agency_id) AND id = ANY(sqlc.arg(ids)::uuid[])
+ORDER BY display_order, name;
+
+-- =============================================================================
+-- Document Queries
+-- =============================================================================
+
+-- name: SelectAgency :one
+SELECT * FROM agencies
+WHERE id = $1;
+
+-- name: SelectAgencyDocumentBranding :one
+SELECT * FROM agency_document_branding
+WHERE agency_id = sqlc.arg(agency_id) AND document_type = sqlc.arg(document_type);
+
+-- name: SelectInvoice :one
+SELECT * FROM invoices
+WHERE id = $1;
+
+-- name: SelectInvoiceLineItems :many
+SELECT * FROM invoice_line_items
+WHERE invoice_id = $1
+ORDER BY sort_order, created_at;
+
+-- name: UpdateInvoicePdf :exec
+UPDATE invoices
+SET
+    pdf_url = sqlc.arg(pdf_url),
+    pdf_generated_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
+
+-- name: SelectContract :one
+SELECT * FROM contracts
+WHERE id = $1;
+
+-- name: UpdateContractPdf :exec
+UPDATE contracts
+SET
+    signed_pdf_url = sqlc.arg(signed_pdf_url),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
+
+-- =============================================================================
+-- Invoice Queries
+-- =============================================================================
+
+-- name: CountInvoices :one
+SELECT count(*) FROM invoices
+WHERE agency_id = sqlc.arg(agency_id)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text);
+
+-- name: SelectInvoices :many
+SELECT * FROM invoices
+WHERE agency_id = sqlc.arg(agency_id)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: SelectInvoiceBySlug :one
+SELECT * FROM invoices
+WHERE slug = $1;
+
+-- name: InsertInvoice :one
+INSERT INTO invoices (
+    id,
+    agency_id,
+    proposal_id,
+    contract_id,
+    client_id,
+    invoice_number,
+    slug,
+    status,
+    client_business_name,
+    client_contact_name,
+    client_email,
+    client_phone,
+    client_address,
+    client_abn,
+    issue_date,
+    due_date,
+    subtotal,
+    discount_amount,
+    discount_description,
+    gst_amount,
+    total,
+    gst_registered,
+    gst_rate,
+    payment_terms,
+    payment_terms_custom,
+    notes,
+    public_notes,
+    created_by,
+    quotation_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
+    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29
+) RETURNING *;
+
+-- name: UpdateInvoice :one
+UPDATE invoices
+SET
+    client_business_name = $2,
+    client_contact_name = $3,
+    client_email = $4,
+    client_phone = $5,
+    client_address = $6,
+    client_abn = $7,
+    issue_date = $8,
+    due_date = $9,
+    discount_amount = $10,
+    discount_description = $11,
+    payment_terms = $12,
+    payment_terms_custom = $13,
+    notes = $14,
+    public_notes = $15,
+    online_payment_enabled = $16,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateInvoiceTotals :one
+UPDATE invoices
+SET
+    subtotal = sqlc.arg(subtotal),
+    gst_amount = sqlc.arg(gst_amount),
+    total = sqlc.arg(total),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateInvoiceStatus :one
+UPDATE invoices
+SET
+    status = sqlc.arg(status),
+    sent_at = COALESCE(sqlc.narg(sent_at), sent_at),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status)
+RETURNING *;
+
+-- name: RecordInvoicePayment :one
+UPDATE invoices
+SET
+    status = sqlc.arg(status),
+    amount_paid = sqlc.arg(amount_paid),
+    paid_at = COALESCE(sqlc.narg(paid_at), paid_at),
+    payment_method = sqlc.arg(payment_method),
+    payment_reference = sqlc.arg(payment_reference),
+    payment_notes = sqlc.arg(payment_notes),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status)
+RETURNING *;
+
+-- name: RecordInvoiceView :one
+UPDATE invoices
+SET
+    view_count = view_count + 1,
+    last_viewed_at = CURRENT_TIMESTAMP,
+    status = CASE WHEN status = 'sent' THEN 'viewed' ELSE status END
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteInvoice :exec
+DELETE FROM invoices
+WHERE id = $1;
+
+-- name: SelectInvoiceLineItem :one
+SELECT * FROM invoice_line_items
+WHERE id = $1;
+
+-- name: InsertInvoiceLineItem :one
+INSERT INTO invoice_line_items (
+    id,
+    invoice_id,
+    description,
+    quantity,
+    unit_price,
+    amount,
+    is_taxable,
+    sort_order,
+    category,
+    package_id,
+    addon_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING *;
+
+-- name: UpdateInvoiceLineItem :one
+UPDATE invoice_line_items
+SET
+    description = $2,
+    quantity = $3,
+    unit_price = $4,
+    amount = $5,
+    is_taxable = $6,
+    sort_order = $7,
+    category = $8,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteInvoiceLineItem :exec
+DELETE FROM invoice_line_items
+WHERE id = $1;
+
+-- =============================================================================
+-- Contract Queries
+-- =============================================================================
+
+-- name: CountContracts :one
+SELECT count(*) FROM contracts
+WHERE agency_id = sqlc.arg(agency_id)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text);
+
+-- name: SelectContracts :many
+SELECT * FROM contracts
+WHERE agency_id = sqlc.arg(agency_id)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: SelectContractBySlug :one
+SELECT * FROM contracts
+WHERE slug = $1;
+
+-- name: InsertContract :one
+INSERT INTO contracts (
+    id,
+    agency_id,
+    proposal_id,
+    template_id,
+    client_id,
+    contract_number,
+    slug,
+    status,
+    client_business_name,
+    client_contact_name,
+    client_email,
+    client_phone,
+    client_address,
+    services_description,
+    commencement_date,
+    completion_date,
+    special_conditions,
+    total_price,
+    price_includes_gst,
+    payment_terms,
+    generated_cover_html,
+    generated_terms_html,
+    generated_schedule_html,
+    valid_until,
+    agency_signatory_name,
+    agency_signatory_title,
+    included_schedule_ids,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
+    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28
+) RETURNING *;
+
+-- Content is frozen once a contract is sent or signed, and an edit only
+-- applies to the version it was made against
+-- name: UpdateContract :one
+UPDATE contracts
+SET
+    client_business_name = $2,
+    client_contact_name = $3,
+    client_email = $4,
+    client_phone = $5,
+    client_address = $6,
+    services_description = $7,
+    commencement_date = $8,
+    completion_date = $9,
+    special_conditions = $10,
+    total_price = $11,
+    price_includes_gst = $12,
+    payment_terms = $13,
+    generated_cover_html = $14,
+    generated_terms_html = $15,
+    generated_schedule_html = $16,
+    valid_until = $17,
+    agency_signatory_name = $18,
+    agency_signatory_title = $19,
+    included_schedule_ids = $20,
+    version = version + 1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND version = $21 AND status = 'draft' AND content_hash IS NULL
+RETURNING *;
+
+-- name: UpdateContractStatus :one
+UPDATE contracts
+SET
+    status = sqlc.arg(status),
+    sent_at = COALESCE(sqlc.narg(sent_at), sent_at),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status)
+RETURNING *;
+
+-- name: RecordContractView :one
+UPDATE contracts
+SET
+    view_count = view_count + 1,
+    last_viewed_at = CURRENT_TIMESTAMP,
+    status = CASE WHEN status = 'sent' THEN 'viewed' ELSE status END
+WHERE id = $1
+RETURNING *;
+
+-- name: SignContractAsAgency :one
+UPDATE contracts
+SET
+    agency_signatory_name = sqlc.arg(signatory_name),
+    agency_signatory_title = sqlc.arg(signatory_title),
+    agency_signed_at = sqlc.arg(signed_at),
+    agency_signature_ip = sqlc.arg(ip_address),
+    agency_signature_user_agent = sqlc.arg(user_agent),
+    content_hash = sqlc.arg(content_hash),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+  AND version = sqlc.arg(version)
+  AND agency_signed_at IS NULL
+  AND (content_hash IS NULL OR content_hash = sqlc.arg(content_hash))
+RETURNING *;
+
+-- name: SignContractAsClient :one
+UPDATE contracts
+SET
+    status = 'signed',
+    client_signatory_name = sqlc.arg(signatory_name),
+    client_signatory_title = sqlc.arg(signatory_title),
+    client_signed_at = sqlc.arg(signed_at),
+    client_signature_ip = sqlc.arg(ip_address),
+    client_signature_user_agent = sqlc.arg(user_agent),
+    content_hash = sqlc.arg(content_hash),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+  AND version = sqlc.arg(version)
+  AND client_signed_at IS NULL
+  AND status IN ('sent', 'viewed')
+  AND (content_hash IS NULL OR content_hash = sqlc.arg(content_hash))
+RETURNING *;
+
+-- name: DeleteContract :exec
+DELETE FROM contracts
+WHERE id = $1;
+
+-- name: SelectContractTemplate :one
+SELECT * FROM contract_templates
+WHERE id = $1;
+
+-- name: SelectDefaultContractTemplate :one
+SELECT * FROM contract_templates
+WHERE agency_id = $1 AND is_default = true AND is_active = true
+ORDER BY updated_at DESC
+LIMIT 1;
+
+-- name: SelectContractSchedules :many
+SELECT * FROM contract_schedules
+WHERE template_id = $1 AND is_active = true
+ORDER BY display_order, name;
+
+-- name: InsertContractSignature :one
+INSERT INTO contract_signatures (
+    id,
+    contract_id,
+    agency_id,
+    party,
+    signatory_name,
+    signatory_title,
+    signed_at,
+    ip_address,
+    user_agent,
+    user_id,
+    content_hash,
+    previous_hash,
+    signature_hash
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING *;
+
+-- name: SelectContractSignatures :many
+SELECT * FROM contract_signatures
+WHERE contract_id = $1
+ORDER BY signed_at, created_at;
+
+-- =============================================================================
+-- Quotation Queries
+-- =============================================================================
+
+-- name: CountQuotations :one
+SELECT count(*) FROM quotations
+WHERE agency_id = sqlc.arg(agency_id)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text);
+
+-- name: SelectQuotations :many
+SELECT * FROM quotations
+WHERE agency_id = sqlc.arg(agency_id)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: SelectQuotation :one
+SELECT * FROM quotations
+WHERE id = $1;
+
+-- name: SelectQuotationBySlug :one
+SELECT * FROM quotations
+WHERE slug = $1;
+
+-- name: InsertQuotation :one
+INSERT INTO quotations (
+    id,
+    agency_id,
+    client_id,
+    template_id,
+    quotation_number,
+    slug,
+    quotation_name,
+    status,
+    client_business_name,
+    client_contact_name,
+    client_email,
+    client_phone,
+    client_address,
+    site_address,
+    site_reference,
+    prepared_date,
+    expiry_date,
+    subtotal,
+    discount_amount,
+    discount_description,
+    gst_amount,
+    total,
+    gst_registered,
+    gst_rate,
+    terms_blocks,
+    options_notes,
+    notes,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
+    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28
+) RETURNING *;
+
+-- name: UpdateQuotation :one
+UPDATE quotations
+SET
+    client_id = $2,
+    quotation_name = $3,
+    client_business_name = $4,
+    client_contact_name = $5,
+    client_email = $6,
+    client_phone = $7,
+    client_address = $8,
+    site_address = $9,
+    site_reference = $10,
+    prepared_date = $11,
+    expiry_date = $12,
+    discount_amount = $13,
+    discount_description = $14,
+    terms_blocks = $15,
+    options_notes = $16,
+    notes = $17,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateQuotationTotals :one
+UPDATE quotations
+SET
+    subtotal = $2,
+    gst_amount = $3,
+    total = $4,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateQuotationStatus :one
+UPDATE quotations
+SET
+    status = sqlc.arg(status),
+    sent_at = COALESCE(sqlc.narg(sent_at), sent_at),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status)
+RETURNING *;
+
+-- name: RecordQuotationView :one
+UPDATE quotations
+SET
+    view_count = view_count + 1,
+    last_viewed_at = CURRENT_TIMESTAMP,
+    status = CASE WHEN status = 'sent' THEN 'viewed' ELSE status END
+WHERE id = $1
+RETURNING *;
+
+-- name: AcceptQuotation :one
+UPDATE quotations
+SET
+    status = 'accepted',
+    accepted_by_name = sqlc.arg(accepted_by_name),
+    accepted_by_title = sqlc.arg(accepted_by_title),
+    accepted_at = sqlc.arg(accepted_at),
+    acceptance_ip = sqlc.arg(acceptance_ip),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND status IN ('sent', 'viewed')
+RETURNING *;
+
+-- name: DeclineQuotation :one
+UPDATE quotations
+SET
+    status = 'declined',
+    declined_at = sqlc.arg(declined_at),
+    decline_reason = sqlc.arg(decline_reason),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND status IN ('sent', 'viewed')
+RETURNING *;
+
+-- Quotations still open after their expiry date are marked expired
+-- name: ExpireQuotations :execrows
+UPDATE quotations
+SET
+    status = 'expired',
+    updated_at = CURRENT_TIMESTAMP
+WHERE status IN ('sent', 'viewed') AND expiry_date < sqlc.arg(before);
+
+-- name: DeleteQuotation :exec
+DELETE FROM quotations
+WHERE id = $1;
+
+-- name: SelectQuotationScopeSections :many
+SELECT * FROM quotation_scope_sections
+WHERE quotation_id = $1
+ORDER BY sort_order, created_at;
+
+-- name: InsertQuotationScopeSection :one
+INSERT INTO quotation_scope_sections (
+    id,
+    quotation_id,
+    title,
+    work_items,
+    section_price,
+    section_gst,
+    section_total,
+    sort_order,
+    scope_template_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: DeleteQuotationScopeSections :exec
+DELETE FROM quotation_scope_sections
+WHERE quotation_id = $1;
+
+-- name: SelectQuotationTemplate :one
+SELECT * FROM quotation_templates
+WHERE id = $1;
+
+-- name: SelectQuotationTemplateSections :many
+SELECT
+    quotation_scope_templates.id AS scope_template_id,
+    quotation_scope_templates.name,
+    quotation_scope_templates.work_items,
+    quotation_scope_templates.default_price,
+    quotation_template_sections.default_section_price,
+    quotation_template_sections.sort_order
+FROM quotation_template_sections
+JOIN quotation_scope_templates ON quotation_scope_templates.id = quotation_template_sections.scope_template_id
+WHERE quotation_template_sections.template_id = $1
+  AND quotation_scope_templates.is_active = true
+ORDER BY quotation_template_sections.sort_order;
+
+-- name: SelectQuotationTemplateTerms :many
+SELECT
+    quotation_terms_templates.id AS terms_template_id,
+    quotation_terms_templates.title,
+    quotation_terms_templates.content,
+    quotation_template_terms.sort_order
+FROM quotation_template_terms
+JOIN quotation_terms_templates ON quotation_terms_templates.id = quotation_template_terms.terms_template_id
+WHERE quotation_template_terms.template_id = $1
+  AND quotation_terms_templates.is_active = true
+ORDER BY quotation_template_terms.sort_order;
+
+-- =============================================================================
+-- Client Queries
+-- =============================================================================
+
+-- name: CountClients :one
+SELECT count(*) FROM clients
+WHERE agency_id = sqlc.arg(agency_id)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+  AND (
+    sqlc.arg(search)::text = ''
+    OR business_name ILIKE '%' || sqlc.arg(search)::text || '%'
+    OR email ILIKE '%' || sqlc.arg(search)::text || '%'
+    OR contact_name ILIKE '%' || sqlc.arg(search)::text || '%'
+  );
+
+-- name: SelectClients :many
+SELECT * FROM clients
+WHERE agency_id = sqlc.arg(agency_id)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+  AND (
+    sqlc.arg(search)::text = ''
+    OR business_name ILIKE '%' || sqlc.arg(search)::text || '%'
+    OR email ILIKE '%' || sqlc.arg(search)::text || '%'
+    OR contact_name ILIKE '%' || sqlc.arg(search)::text || '%'
+  )
+ORDER BY business_name ASC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: SelectAgencyClients :many
+SELECT * FROM clients
+WHERE agency_id = $1
+ORDER BY created_at ASC;
+
+-- name: SelectClient :one
+SELECT * FROM clients
+WHERE id = $1;
+
+-- name: SelectClientByEmail :one
+SELECT * FROM clients
+WHERE agency_id = sqlc.arg(agency_id) AND lower(email) = lower(sqlc.arg(email)::text);
+
+-- name: InsertClient :one
+INSERT INTO clients (id, agency_id, business_name, email, phone, contact_name, notes, abn, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: UpdateClient :one
+UPDATE clients
+SET
+    business_name = $2,
+    email = $3,
+    phone = $4,
+    contact_name = $5,
+    notes = $6,
+    abn = $7,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateClientStatus :one
+UPDATE clients
+SET
+    status = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteClient :exec
+DELETE FROM clients
+WHERE id = $1;
+
+-- name: CountClientDocuments :one
+SELECT
+    (SELECT count(*) FROM consultations c WHERE c.client_id = sqlc.arg(client_id)::uuid) AS consultations,
+    (SELECT count(*) FROM proposals p WHERE p.client_id = sqlc.arg(client_id)::uuid) AS proposals,
+    (SELECT count(*) FROM contracts ct WHERE ct.client_id = sqlc.arg(client_id)::uuid) AS contracts,
+    (SELECT count(*) FROM invoices i WHERE i.client_id = sqlc.arg(client_id)::uuid) AS invoices,
+    (SELECT count(*) FROM quotations q WHERE q.client_id = sqlc.arg(client_id)::uuid) AS quotations,
+    (SELECT count(*) FROM form_submissions fs WHERE fs.client_id = sqlc.arg(client_id)::uuid) AS form_submissions;
+
+-- name: ReassignConsultationsClient :execrows
+UPDATE consultations
+SET client_id = sqlc.arg(to_client_id), updated_at = CURRENT_TIMESTAMP
+WHERE client_id = sqlc.arg(from_client_id);
+
+-- name: ReassignProposalsClient :execrows
+UPDATE proposals
+SET client_id = sqlc.arg(to_client_id), updated_at = CURRENT_TIMESTAMP
+WHERE client_id = sqlc.arg(from_client_id);
+
+-- name: ReassignContractsClient :execrows
+UPDATE contracts
+SET client_id = sqlc.arg(to_client_id), updated_at = CURRENT_TIMESTAMP
+WHERE client_id = sqlc.arg(from_client_id);
+
+-- name: ReassignInvoicesClient :execrows
+UPDATE invoices
+SET client_id = sqlc.arg(to_client_id), updated_at = CURRENT_TIMESTAMP
+WHERE client_id = sqlc.arg(from_client_id);
+
+-- name: ReassignQuotationsClient :execrows
+UPDATE quotations
+SET client_id = sqlc.arg(to_client_id), updated_at = CURRENT_TIMESTAMP
+WHERE client_id = sqlc.arg(from_client_id);
+
+-- name: ReassignFormSubmissionsClient :execrows
+UPDATE form_submissions
+SET client_id = sqlc.arg(to_client_id)
+WHERE client_id = sqlc.arg(from_client_id);
+
+-- =============================================================================
+-- Form Queries
+-- =============================================================================
+
+-- name: SelectAgencyForm :one
+SELECT * FROM agency_forms WHERE id = $1;
+
+-- name: SelectFieldOptionSets :many
+-- System option sets first so an agency's own set replaces one with the
+-- same slug
+SELECT * FROM field_option_sets
+WHERE agency_id = sqlc.arg(agency_id)::uuid OR agency_id IS NULL
+ORDER BY agency_id NULLS FIRST, slug;
+
+-- name: SelectAgencyFormForUpdate :one
+SELECT * FROM agency_forms WHERE id = $1 FOR UPDATE;
+
+-- name: UpdateAgencyFormSchema :one
+UPDATE agency_forms
+SET schema = sqlc.arg(schema),
+    ui_config = sqlc.arg(ui_config),
+    previous_schema = sqlc.arg(previous_schema),
+    version = sqlc.arg(version),
+    is_customized = sqlc.arg(is_customized),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: InsertAgencyFormVersion :one
+INSERT INTO agency_form_versions (id, form_id, agency_id, version, schema, ui_config, changes, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: SelectAgencyFormVersions :many
+SELECT * FROM agency_form_versions
+WHERE form_id = $1
+ORDER BY version DESC;
+
+-- name: SelectAgencyFormVersion :one
+SELECT * FROM agency_form_versions
+WHERE form_id = $1 AND version = $2;
+
+-- name: SelectDraftFormSubmissions :many
+SELECT * FROM form_submissions
+WHERE form_id = sqlc.arg(form_id)::uuid AND status = 'draft'
+ORDER BY created_at
+FOR UPDATE;
+
+-- name: UpdateFormSubmissionVersion :exec
+UPDATE form_submissions
+SET data = sqlc.arg(data),
+    metadata = sqlc.arg(metadata),
+    form_version = sqlc.arg(form_version),
+    current_step = sqlc.arg(current_step),
+    completion_percentage = sqlc.arg(completion_percentage)
+WHERE id = sqlc.arg(id);
+
+-- name: UpdateAgencyFormMapping :one
+UPDATE agency_forms
+SET consultation_mapping = sqlc.arg(consultation_mapping), updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: SelectAgencyOwnerID :one
+SELECT user_id FROM agency_memberships
+WHERE agency_id = $1 AND role = 'owner' AND status = 'active'
+ORDER BY created_at
+LIMIT 1;
+
+-- name: SelectFormSubmission :one
+SELECT * FROM form_submissions WHERE id = $1;
+
+-- name: SelectFormSubmissionForUpdate :one
+SELECT * FROM form_submissions WHERE id = $1 FOR UPDATE;
+
+-- name: CompleteFormSubmission :one
+UPDATE form_submissions
+SET data = sqlc.arg(data),
+    status = 'completed',
+    current_step = sqlc.arg(current_step),
+    completion_percentage = 100,
+    submitted_at = CURRENT_TIMESTAMP,
+    last_activity_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND status = 'draft'
+RETURNING *;
+
+-- name: SelectUnprocessedFormSubmissions :many
+-- Completed submissions waiting to be processed, skipping any that have
+-- failed too often to retry automatically
+SELECT * FROM form_submissions
+WHERE status = 'completed'
+  AND processed_at IS NULL
+  AND processing_attempts < sqlc.arg(max_attempts)
+ORDER BY submitted_at NULLS LAST, created_at
+LIMIT sqlc.arg(row_limit);
+
+-- name: UpdateFormSubmissionProcessed :one
+UPDATE form_submissions
+SET client_id = sqlc.arg(client_id)::uuid,
+    consultation_id = sqlc.arg(consultation_id)::uuid,
+    client_business_name = sqlc.arg(client_business_name),
+    client_email = sqlc.arg(client_email),
+    status = 'processed',
+    processed_at = CURRENT_TIMESTAMP,
+    processing_attempts = processing_attempts + 1,
+    processing_error = ''
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateFormSubmissionFailed :exec
+UPDATE form_submissions
+SET processing_attempts = processing_attempts + 1,
+    processing_error = sqlc.arg(processing_error)
+WHERE id = sqlc.arg(id);
+
+-- =============================================================================
+-- Document Numbering Queries
+-- =============================================================================
+
+-- name: LockAgencyNumbering :one
+SELECT proposal_prefix, next_proposal_number,
+       contract_prefix, next_contract_number,
+       invoice_prefix, next_invoice_number,
+       quotation_prefix, next_quotation_number
+FROM agency_profiles
+WHERE agency_id = $1
+FOR UPDATE;
+
+-- name: SelectAgencyNumbering :one
+SELECT proposal_prefix, next_proposal_number,
+       contract_prefix, next_contract_number,
+       invoice_prefix, next_invoice_number,
+       quotation_prefix, next_quotation_number
+FROM agency_profiles
+WHERE agency_id = $1;
+
+-- name: SetNextDocumentNumber :exec
+UPDATE agency_profiles
+SET next_proposal_number = CASE WHEN CAST(sqlc.arg(document_type) AS TEXT) = 'proposal' THEN sqlc.arg(next_number) ELSE next_proposal_number END,
+    next_contract_number = CASE WHEN CAST(sqlc.arg(document_type) AS TEXT) = 'contract' THEN sqlc.arg(next_number) ELSE next_contract_number END,
+    next_invoice_number = CASE WHEN CAST(sqlc.arg(document_type) AS TEXT) = 'invoice' THEN sqlc.arg(next_number) ELSE next_invoice_number END,
+    next_quotation_number = CASE WHEN CAST(sqlc.arg(document_type) AS TEXT) = 'quotation' THEN sqlc.arg(next_number) ELSE next_quotation_number END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE agency_id = sqlc.arg(agency_id);
+
+-- name: SelectDocumentNumberings :many
+SELECT * FROM agency_document_numbering
+WHERE agency_id = $1
+ORDER BY document_type;
+
+-- name: SelectDocumentNumbering :one
+SELECT * FROM agency_document_numbering
+WHERE agency_id = $1 AND document_type = $2;
+
+-- name: UpsertDocumentNumbering :one
+INSERT INTO agency_document_numbering (id, agency_id, document_type, format, reset_yearly, sequence_year)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (agency_id, document_type) DO UPDATE
+SET format = EXCLUDED.format,
+    reset_yearly = EXCLUDED.reset_yearly,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: InsertDocumentNumbering :exec
+INSERT INTO agency_document_numbering (id, agency_id, document_type, format, reset_yearly, sequence_year)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (agency_id, document_type) DO UPDATE
+SET sequence_year = EXCLUDED.sequence_year,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: UpdateDocumentSequenceYear :exec
+UPDATE agency_document_numbering
+SET sequence_year = $3, updated_at = CURRENT_TIMESTAMP
+WHERE agency_id = $1 AND document_type = $2;
+
+-- name: SelectDocumentNumbers :many
+SELECT proposal_number AS number FROM proposals
+WHERE sqlc.arg(document_type)::text = 'proposal' AND proposals.agency_id = sqlc.arg(agency_id)::uuid
+UNION ALL
+SELECT contract_number FROM contracts
+WHERE sqlc.arg(document_type)::text = 'contract' AND contracts.agency_id = sqlc.arg(agency_id)::uuid
+UNION ALL
+SELECT invoice_number FROM invoices
+WHERE sqlc.arg(document_type)::text = 'invoice' AND invoices.agency_id = sqlc.arg(agency_id)::uuid
+UNION ALL
+SELECT quotation_number FROM quotations
+WHERE sqlc.arg(document_type)::text = 'quotation' AND quotations.agency_id = sqlc.arg(agency_id)::uuid
+ORDER BY number;
+
+-- name: DocumentNumberExists :one
+SELECT EXISTS (
+    SELECT 1 FROM proposals WHERE sqlc.arg(document_type)::text = 'proposal' AND proposals.agency_id = sqlc.arg(agency_id)::uuid AND proposal_number = sqlc.arg(number)::text
+    UNION ALL
+    SELECT 1 FROM contracts WHERE sqlc.arg(document_type)::text = 'contract' AND contracts.agency_id = sqlc.arg(agency_id)::uuid AND contract_number = sqlc.arg(number)::text
+    UNION ALL
+    SELECT 1 FROM invoices WHERE sqlc.arg(document_type)::text = 'invoice' AND invoices.agency_id = sqlc.arg(agency_id)::uuid AND invoice_number = sqlc.arg(number)::text
+    UNION ALL
+    SELECT 1 FROM quotations WHERE sqlc.arg(document_type)::text = 'quotation' AND quotations.agency_id = sqlc.arg(agency_id)::uuid AND quotation_number = sqlc.arg(number)::text
+);
@@ -350,6 +350,9 @@ create table if not exists agency_profiles (
     next_contract_number integer not null default 1,
     proposal_prefix varchar(20) not null default 'PROP',
     next_proposal_number integer not null default 1,
+    quotation_prefix varchar(20) not null default 'QUO',
+    next_quotation_number integer not null default 1,
+    default_quotation_validity_days integer not null default 60,
 
     -- Stripe Connect
     stripe_account_id varchar(255),
@@ -540,6 +543,9 @@ create table if not exists agency_forms (
     is_customized boolean not null default false,
     previous_schema jsonb,
 
+    -- Submission Processing (consultation field -> form field name)
+    consultation_mapping jsonb not null default '{}',
+
     -- Metadata
     version integer not null default 1,
     created_at timestamptz not null default current_timestamp,
@@ -552,6 +558,28 @@ create table if not exists agency_forms (
 create index if not exists idx_agency_forms_agency_type on agency_forms(agency_id, form_type);
 create index if not exists idx_agency_forms_active on agency_forms(agency_id, is_active);
 
+-- create "agency_form_versions" table - Published form schemas
+create table if not exists agency_form_versions (
+    id uuid primary key not null default gen_random_uuid(),
+    form_id uuid not null references agency_forms(id) on delete cascade,
+    agency_id uuid not null references agencies(id) on delete cascade,
+    version integer not null,
+
+    -- Schema as published
+    schema jsonb not null,
+    ui_config jsonb not null default '{}',
+
+    -- Field changes from the previous version
+    changes jsonb not null default '[]',
+
+    created_at timestamptz not null default current_timestamp,
+    created_by uuid references users(id) on delete set null,
+
+    unique(form_id, version)
+);
+
+create index if not exists idx_agency_form_versions_agency_id on agency_form_versions(agency_id);
+
 -- create "field_option_sets" table - Reusable dropdown options
 create table if not exists field_option_sets (
     id uuid primary key not null default gen_random_uuid(),
@@ -613,7 +641,11 @@ create table if not exists form_submissions (
     processed_at timestamptz,
 
     -- Form version at time of submission (for schema evolution)
-    form_version integer not null default 1
+    form_version integer not null default 1,
+
+    -- Processing retries
+    processing_attempts integer not null default 0,
+    processing_error text not null default ''
 );
 
 create index if not exists idx_form_submissions_form_id on form_submissions(form_id);
@@ -622,6 +654,7 @@ create index if not exists idx_form_submissions_status on form_submissions(statu
 create index if not exists idx_form_submissions_submitted_at on form_submissions(submitted_at);
 create index if not exists idx_form_submissions_client_id on form_submissions(client_id);
 create index if not exists idx_form_submissions_slug on form_submissions(slug);
+create index if not exists idx_form_submissions_unprocessed on form_submissions(submitted_at) where status = 'completed' and processed_at is null;
 
 -- create "files" table
 create table if not exists files (
@@ -632,7 +665,8 @@ create table if not exists files (
     file_key text not null,
     file_name text not null,
     file_size bigint not null,
-    content_type text not null
+    content_type text not null,
+    agency_id uuid references agencies(id) on delete cascade  -- migration 038
 );
 
 -- create "emails" table
@@ -755,6 +789,7 @@ create table if not exists consultation_versions (
     -- Version metadata
     change_summary text,
     changed_fields jsonb not null default '[]',
+    snapshot jsonb,
 
     -- Timestamps (NOT NULL with default)
     created_at timestamptz not null default current_timestamp,
@@ -798,11 +833,13 @@ create table if not exists clients (
     phone varchar(50),
     contact_name text,
     notes text,
+    abn varchar(20) not null default '',
 
     -- Status: 'active' | 'archived'
     status varchar(20) not null default 'active',
 
     -- Metadata
+    created_by uuid references users(id) on delete set null,  -- migration 037
     created_at timestamptz not null default current_timestamp,
     updated_at timestamptz not null default current_timestamp,
 
@@ -812,6 +849,7 @@ create table if not exists clients (
 create index if not exists idx_clients_agency_id on clients(agency_id);
 create index if not exists idx_clients_email on clients(agency_id, email);
 create index if not exists idx_clients_business_name on clients(agency_id, business_name);
+create index if not exists idx_clients_abn on clients(agency_id, abn) where abn <> '';
 
 -- =============================================================================
 -- PROPOSALS (V2 Document Generation)
@@ -1045,6 +1083,11 @@ create table if not exists contracts (
 
     created_by uuid references users(id) on delete set null,
 
+    -- Signing audit (migration 023)
+    agency_signature_ip varchar(50),
+    agency_signature_user_agent text,
+    content_hash varchar(64),  -- SHA-256 of the content fixed at the first signature
+
     constraint valid_contract_status check (status in ('draft', 'sent', 'viewed', 'signed', 'completed', 'expired', 'terminated')),
     constraint contracts_agency_number_unique unique (agency_id, contract_number)
 );
@@ -1055,6 +1098,34 @@ create index if not exists idx_contracts_status on contracts(status);
 create index if not exists idx_contracts_slug on contracts(slug);
 create index if not exists idx_contracts_created_at on contracts(created_at desc);
 
+-- create "contract_signatures" table - Append-only signing records (migration 023)
+create table if not exists contract_signatures (
+    id uuid primary key not null default gen_random_uuid(),
+    created_at timestamptz not null default current_timestamp,
+
+    contract_id uuid not null references contracts(id) on delete cascade,
+    agency_id uuid not null references agencies(id) on delete cascade,
+
+    party varchar(20) not null,  -- agency, client
+    signatory_name varchar(255) not null,
+    signatory_title varchar(100) not null default '',
+    signed_at timestamptz not null,
+    ip_address varchar(50) not null default '',
+    user_agent text not null default '',
+    user_id uuid references users(id) on delete set null,  -- Set for agency signatures
+
+    -- Tamper evidence: signature_hash covers the content hash, the signature
+    -- details and the previous signature's hash
+    content_hash varchar(64) not null,
+    previous_hash varchar(64) not null default '',
+    signature_hash varchar(64) not null,
+
+    constraint valid_signature_party check (party in ('agency', 'client'))
+);
+
+create index if not exists idx_contract_signatures_contract on contract_signatures(contract_id);
+create unique index if not exists idx_contract_signatures_party on contract_signatures(contract_id, party);
+
 -- create "invoices" table
 create table if not exists invoices (
     id uuid primary key not null default gen_random_uuid(),
@@ -1105,6 +1176,7 @@ create table if not exists invoices (
     payment_method varchar(50),
     payment_reference text,
     payment_notes text,
+    amount_paid decimal(10,2) not null default 0.00,
 
     pdf_url text,
     pdf_generated_at timestamptz,
@@ -1118,7 +1190,7 @@ create table if not exists invoices (
 
     created_by uuid references users(id) on delete set null,
 
-    constraint valid_invoice_status check (status in ('draft', 'sent', 'viewed', 'paid', 'overdue', 'cancelled', 'refunded')),
+    constraint valid_invoice_status check (status in ('draft', 'sent', 'viewed', 'partially_paid', 'paid', 'overdue', 'void', 'cancelled', 'refunded')),
     constraint invoices_agency_number_unique unique (agency_id, invoice_number)
 );
 
@@ -1178,15 +1250,17 @@ create table if not exists email_logs (
     has_attachment boolean not null default false,
     attachment_filename varchar(255),
 
-    -- Resend tracking
-    resend_message_id varchar(100),
+    -- Provider tracking (migration 033)
+    provider_message_id varchar(255),
 
-    -- Status: pending, sent, delivered, opened, bounced, failed
+    -- Status: pending, sent, delivered, opened, bounced, complained, failed
     status varchar(50) not null default 'pending',
 
     sent_at timestamptz,
     delivered_at timestamptz,
     opened_at timestamptz,
+    bounced_at timestamptz,  -- migration 033
+    complained_at timestamptz,  -- migration 033
 
     -- Error handling
     error_message text,
@@ -1203,6 +1277,7 @@ create index if not exists idx_email_logs_invoice_id on email_logs(invoice_id);
 create index if not exists idx_email_logs_contract_id on email_logs(contract_id);
 create index if not exists idx_email_logs_status on email_logs(status);
 create index if not exists idx_email_logs_created_at on email_logs(created_at);
+create index if not exists idx_email_logs_provider_message_id on email_logs(provider_message_id);
 
 -- Deferred FKs for form_submissions (tables defined after form_submissions)
 alter table form_submissions add constraint form_submissions_client_id_fkey
@@ -1256,3 +1331,333 @@ create index if not exists idx_questionnaire_responses_contract_id on questionna
 create index if not exists idx_questionnaire_responses_proposal_id on questionnaire_responses(proposal_id);
 create index if not exists idx_questionnaire_responses_status on questionnaire_responses(agency_id, status);
 
+-- create "quotation_scope_templates" table - Reusable work item blocks (migration 019)
+create table if not exists quotation_scope_templates (
+    id uuid primary key not null default gen_random_uuid(),
+    created_at timestamptz not null default current_timestamp,
+    updated_at timestamptz not null default current_timestamp,
+    agency_id uuid not null references agencies(id) on delete cascade,
+    name varchar(255) not null,
+    slug varchar(100) not null,
+    description text not null default '',
+    category varchar(100),
+    work_items jsonb not null default '[]'::jsonb,  -- Array of work item strings
+    default_price decimal(10,2),
+    is_active boolean not null default true,
+    sort_order integer not null default 0,
+    created_by uuid references users(id) on delete set null
+);
+
+create index if not exists idx_quotation_scope_templates_agency on quotation_scope_templates(agency_id);
+create unique index if not exists idx_quotation_scope_templates_agency_slug on quotation_scope_templates(agency_id, slug);
+
+-- create "quotation_terms_templates" table - Reusable terms blocks (migration 019)
+create table if not exists quotation_terms_templates (
+    id uuid primary key not null default gen_random_uuid(),
+    created_at timestamptz not null default current_timestamp,
+    updated_at timestamptz not null default current_timestamp,
+    agency_id uuid not null references agencies(id) on delete cascade,
+    title varchar(255) not null,
+    content text not null,
+    is_default boolean not null default false,
+    sort_order integer not null default 0,
+    is_active boolean not null default true,
+    created_by uuid references users(id) on delete set null
+);
+
+create index if not exists idx_quotation_terms_templates_agency on quotation_terms_templates(agency_id);
+
+-- create "quotation_templates" table - Parent templates combining scope and terms blocks (migration 019)
+create table if not exists quotation_templates (
+    id uuid primary key not null default gen_random_uuid(),
+    created_at timestamptz not null default current_timestamp,
+    updated_at timestamptz not null default current_timestamp,
+    agency_id uuid not null references agencies(id) on delete cascade,
+    name varchar(255) not null,
+    description text not null default '',
+    category varchar(100),
+    default_validity_days integer,
+    is_default boolean not null default false,
+    is_active boolean not null default true,
+    sort_order integer not null default 0,
+    created_by uuid references users(id) on delete set null
+);
+
+create index if not exists idx_quotation_templates_agency on quotation_templates(agency_id);
+create index if not exists idx_quotation_templates_agency_active on quotation_templates(agency_id, is_active);
+
+-- create "quotation_template_sections" table - Junction: template -> scope templates
+create table if not exists quotation_template_sections (
+    id uuid primary key not null default gen_random_uuid(),
+    template_id uuid not null references quotation_templates(id) on delete cascade,
+    scope_template_id uuid not null references quotation_scope_templates(id) on delete cascade,
+    default_section_price decimal(10,2),
+    sort_order integer not null default 0
+);
+
+create index if not exists idx_quotation_template_sections_template on quotation_template_sections(template_id);
+create unique index if not exists idx_quotation_template_sections_unique on quotation_template_sections(template_id, scope_template_id);
+
+-- create "quotation_template_terms" table - Junction: template -> terms templates
+create table if not exists quotation_template_terms (
+    id uuid primary key not null default gen_random_uuid(),
+    template_id uuid not null references quotation_templates(id) on delete cascade,
+    terms_template_id uuid not null references quotation_terms_templates(id) on delete cascade,
+    sort_order integer not null default 0
+);
+
+create index if not exists idx_quotation_template_terms_template on quotation_template_terms(template_id);
+create unique index if not exists idx_quotation_template_terms_unique on quotation_template_terms(template_id, terms_template_id);
+
+-- create "quotations" table - Itemised quotes for trade agencies (migration 019)
+create table if not exists quotations (
+    id uuid primary key not null default gen_random_uuid(),
+    created_at timestamptz not null default current_timestamp,
+    updated_at timestamptz not null default current_timestamp,
+
+    agency_id uuid not null references agencies(id) on delete cascade,
+    client_id uuid references clients(id) on delete set null,
+    template_id uuid references quotation_templates(id) on delete set null,
+
+    quotation_number varchar(50) not null,
+    slug varchar(100) not null unique,
+    quotation_name text not null default '',
+    status varchar(50) not null default 'draft',
+
+    -- Client snapshot
+    client_business_name text not null default '',
+    client_contact_name text not null default '',
+    client_email varchar(255) not null default '',
+    client_phone varchar(50) not null default '',
+    client_address text not null default '',
+    site_address text not null default '',
+    site_reference text not null default '',
+
+    -- Dates
+    prepared_date timestamptz not null,
+    expiry_date timestamptz not null,
+
+    -- Financials
+    subtotal decimal(10,2) not null,
+    discount_amount decimal(10,2) not null default 0.00,
+    discount_description text not null default '',
+    gst_amount decimal(10,2) not null default 0.00,
+    total decimal(10,2) not null,
+    gst_registered boolean not null default true,
+    gst_rate decimal(5,2) not null default 10.00,
+
+    -- Content
+    terms_blocks jsonb not null default '[]'::jsonb,
+    options_notes text not null default '',
+    notes text not null default '',
+
+    -- Tracking
+    view_count integer not null default 0,
+    last_viewed_at timestamptz,
+    sent_at timestamptz,
+    declined_at timestamptz,
+    decline_reason text not null default '',
+    accepted_by_name varchar(255),
+    accepted_by_title varchar(255),
+    accepted_at timestamptz,
+    acceptance_ip varchar(50),
+
+    created_by uuid references users(id) on delete set null
+);
+
+-- Indexes for quotations
+create index if not exists idx_quotations_agency on quotations(agency_id);
+create index if not exists idx_quotations_client on quotations(client_id);
+create index if not exists idx_quotations_status on quotations(status);
+create index if not exists idx_quotations_slug on quotations(slug);
+create unique index if not exists idx_quotations_agency_number on quotations(agency_id, quotation_number);
+
+-- create "quotation_scope_sections" table - Priced scope sections of a quotation
+create table if not exists quotation_scope_sections (
+    id uuid primary key not null default gen_random_uuid(),
+    created_at timestamptz not null default current_timestamp,
+    quotation_id uuid not null references quotations(id) on delete cascade,
+    title text not null,
+    work_items jsonb not null default '[]'::jsonb,  -- Array of work item strings
+    section_price decimal(10,2),
+    section_gst decimal(10,2),
+    section_total decimal(10,2),
+    sort_order integer not null default 0,
+    scope_template_id uuid references quotation_scope_templates(id) on delete set null
+);
+
+create index if not exists idx_quotation_scope_sections_quotation on quotation_scope_sections(quotation_id);
+
+-- Link invoices and emails to their source quotation (migration 019)
+alter table invoices add column if not exists quotation_id uuid references quotations(id) on delete set null;
+alter table email_logs add column if not exists quotation_id uuid references quotations(id) on delete set null;
+
+-- create "agency_document_numbering" table - Document number formats per agency (migration 022)
+-- Counters live in agency_profiles.next_*_number
+create table if not exists agency_document_numbering (
+    id uuid primary key not null default gen_random_uuid(),
+    created_at timestamptz not null default current_timestamp,
+    updated_at timestamptz not null default current_timestamp,
+
+    agency_id uuid not null references agencies(id) on delete cascade,
+    document_type varchar(20) not null,  -- proposal, contract, invoice, quotation
+    format varchar(100) not null default '{prefix}-{yyyy}-{seq:04}',
+    reset_yearly boolean not null default false,
+    sequence_year integer not null default 0,  -- Year of the last allocation
+
+    constraint valid_numbering_document_type check (document_type in ('proposal', 'contract', 'invoice', 'quotation'))
+);
+
+create unique index if not exists idx_agency_document_numbering_type on agency_document_numbering(agency_id, document_type);
+
+-- create "api_keys" table - Hashed API keys for programmatic access (migration 028)
+-- Keys with an agency_id belong to the agency, others to the user
+create table if not exists api_keys (
+    id uuid primary key not null default gen_random_uuid(),
+    created_at timestamptz not null default current_timestamp,
+    updated_at timestamptz not null default current_timestamp,
+
+    user_id uuid not null references users(id) on delete cascade,
+    agency_id uuid references agencies(id) on delete cascade,
+
+    name text not null,
+    prefix text not null,  -- Start of the key, for display
+    key_hash text not null unique,  -- SHA-256 of the key
+    access bigint not null,  -- Scopes as an access bitmask
+
+    expires_at timestamptz,
+    last_used_at timestamptz,
+    revoked_at timestamptz
+);
+
+create index if not exists idx_api_keys_user_id on api_keys(user_id);
+create index if not exists idx_api_keys_agency_id on api_keys(agency_id) where agency_id is not null;
+
+-- Refresh token families (migration 029)
+-- Refresh tokens of one sign-in share a family, the session's ID; rotated is
+-- set once a token has been exchanged for the next one in its family
+alter table tokens add column if not exists family text not null default '';
+alter table tokens add column if not exists created timestamptz not null default current_timestamp;
+alter table tokens add column if not exists rotated timestamptz;
+alter table tokens add column if not exists user_agent text not null default '';
+alter table tokens add column if not exists ip text not null default '';
+
+create index if not exists idx_tokens_family on tokens(family) where family <> '';
+create index if not exists idx_tokens_target on tokens(target) where family <> '';
+
+-- create "user_totp" table - Authenticator app enrolment (migration 030)
+-- The secret is pending until enabled_at is set by confirming a code
+create table if not exists user_totp (
+    user_id uuid primary key not null references users(id) on delete cascade,
+    created_at timestamptz not null default current_timestamp,
+    updated_at timestamptz not null default current_timestamp,
+
+    secret text not null,  -- TOTP secret, encrypted with TOTP_ENCRYPTION_KEY
+    enabled_at timestamptz,
+    last_step bigint not null default 0,  -- Time step of the last accepted code
+    failed_attempts integer not null default 0,  -- Invalid codes in a row (migration 039)
+    locked_until timestamptz  -- No codes are accepted until then (migration 039)
+);
+
+-- create "user_recovery_codes" table - Single-use two-factor recovery codes (migration 030)
+create table if not exists user_recovery_codes (
+    id uuid primary key not null default gen_random_uuid(),
+    created_at timestamptz not null default current_timestamp,
+
+    user_id uuid not null references users(id) on delete cascade,
+    code_hash text not null,  -- SHA-256 of the code
+    used_at timestamptz
+);
+
+create index if not exists idx_user_recovery_codes_user_id on user_recovery_codes(user_id);
+
+-- create "used_session_tokens" table - session tokens sign-in was completed with (migration 039)
+create table if not exists used_session_tokens (
+    id uuid primary key not null,  -- Token ID (jti)
+    expires_at timestamptz not null
+);
+
+create index if not exists idx_used_session_tokens_expires_at on used_session_tokens(expires_at);
+
+-- create "passkeys" table - WebAuthn credentials for sign-in and second factor (migration 031)
+create table if not exists passkeys (
+    id uuid primary key not null default gen_random_uuid(),
+    created_at timestamptz not null default current_timestamp,
+    updated_at timestamptz not null default current_timestamp,
+
+    user_id uuid not null references users(id) on delete cascade,
+
+    name text not null,
+    credential_id text not null unique,  -- Base64url credential ID
+    public_key bytea not null,  -- SubjectPublicKeyInfo (DER)
+    algorithm integer not null,  -- COSE algorithm
+    sign_count bigint not null default 0,  -- Last authenticator signature counter
+    transports jsonb not null default '[]',
+
+    last_used_at timestamptz
+);
+
+create index if not exists idx_passkeys_user_id on passkeys(user_id);
+
+-- create "passkey_challenges" table - challenges of WebAuthn ceremonies in progress (migration 031)
+create table if not exists passkey_challenges (
+    id text primary key not null,  -- Base64url challenge
+    created_at timestamptz not null default current_timestamp,
+    expires_at timestamptz not null,
+
+    purpose text not null,  -- 'register', 'login' or 'verify'
+    user_id uuid references users(id) on delete cascade,  -- Null for sign-in
+    return_url text not null default ''
+);
+
+create index if not exists idx_passkey_challenges_expires_at on passkey_challenges(expires_at);
+
+-- Email outbox (migration 032)
+alter table emails add column if not exists status text not null default 'pending';  -- 'pending', 'sent' or 'failed'
+alter table emails add column if not exists retry_count integer not null default 0;
+alter table emails add column if not exists error_message text not null default '';
+alter table emails add column if not exists next_attempt_at timestamptz not null default current_timestamp;
+alter table emails add column if not exists locked_until timestamptz;  -- Lease of the worker sending the email
+alter table emails add column if not exists sent_at timestamptz;
+alter table emails add column if not exists email_log_id uuid references email_logs(id) on delete set null;
+
+alter table email_attachments add column if not exists file_id uuid;
+
+create index if not exists idx_emails_outbox on emails(next_attempt_at) where status = 'pending';
+create index if not exists idx_emails_user_status on emails(user_id, status);
+
+-- Email provider events (migration 033)
+alter table emails add column if not exists provider_message_id text not null default '';
+
+-- create "email_templates" table - agency overrides of the built-in email templates (migration 034)
+create table if not exists email_templates (
+    id uuid primary key not null,
+    created_at timestamptz not null default current_timestamp,
+    agency_id uuid not null references agencies(id) on delete cascade,
+
+    name text not null,
+    version integer not null,  -- The latest version is the one that is sent
+    subject text not null,  -- Text template
+    body text not null,  -- HTML template of the content inside the layout
+
+    created_by uuid references users(id) on delete set null,
+    unique (agency_id, name, version)
+);
+
+-- create "email_suppressions" table - addresses an agency no longer sends to (migration 035)
+create table if not exists email_suppressions (
+    id uuid primary key not null,
+    created_at timestamptz not null default current_timestamp,
+    agency_id uuid not null references agencies(id) on delete cascade,
+
+    email text not null,  -- Lower case
+    reason text not null,  -- 'bounced', 'complained', 'unsubscribed' or 'manual'
+    detail text not null default '',
+
+    unique (agency_id, email)
+);
+
+alter table emails add column if not exists agency_id uuid references agencies(id) on delete set null;
+alter table emails add column if not exists unsubscribe_token text;  -- One-click unsubscribe of bulk emails
+
+create unique index if not exists idx_emails_unsubscribe_token on emails(unsubscribe_token);
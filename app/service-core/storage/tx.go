@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"app/pkg"
+	"context"
+	"service-core/storage/query"
+)
+
+// InTx runs fn with queries bound to a new transaction. The transaction is
+// committed if fn succeeds and rolled back otherwise, and fn's error is
+// returned as is.
+func (s *Storage) InTx(ctx context.Context, fn func(q query.Querier) error) error {
+	tx, err := s.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return pkg.InternalError{Message: "Error starting transaction", Err: err}
+	}
+	defer tx.Rollback()
+	if err := fn(query.New(tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return pkg.InternalError{Message: "Error committing transaction", Err: err}
+	}
+	return nil
+}
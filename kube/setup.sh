@@ -75,9 +75,10 @@ kubectl create secret generic regcred \
     --from-file=.dockerconfigjson=$DOCKER_CONFIG_JSON \
     --type=kubernetes.io/dockerconfigjson
 
-echo "Creating a CronJob secret..."
+echo "Creating the API secrets..."
 kubectl create secret generic api-secrets \
-  --from-literal=task-token=$TASK_TOKEN
+  --from-literal=task-token=$TASK_TOKEN \
+  --from-literal=totp-encryption-key=$TOTP_ENCRYPTION_KEY
 
 # Uncomment if using Google Cloud SQL
 # echo "Creating a PostgreSQL secret..."
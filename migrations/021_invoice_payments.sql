@@ -0,0 +1,14 @@
+-- Migration 021: Partial payments and void status for invoices
+-- Tracks the running amount paid so an invoice can be settled over several
+-- payments, and adds the partially_paid and void statuses used by the Go
+-- invoice service. Legacy cancelled/refunded values stay valid.
+
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS amount_paid DECIMAL(10,2) NOT NULL DEFAULT 0.00;
+
+-- Invoices already marked paid were paid in full
+UPDATE invoices SET amount_paid = total WHERE status = 'paid' AND amount_paid = 0;
+
+ALTER TABLE invoices DROP CONSTRAINT IF EXISTS valid_invoice_status;
+ALTER TABLE invoices ADD CONSTRAINT valid_invoice_status CHECK (
+    status IN ('draft', 'sent', 'viewed', 'partially_paid', 'paid', 'overdue', 'void', 'cancelled', 'refunded')
+);
@@ -0,0 +1,23 @@
+-- Migration 022: Per-agency document number formats
+-- Counters stay in agency_profiles (next_*_number); this table holds the
+-- optional format template and yearly reset settings used by the Go
+-- numbering service. Agencies without a row keep the PREFIX-YYYY-NNNN format.
+
+CREATE TABLE IF NOT EXISTS agency_document_numbering (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    agency_id UUID NOT NULL REFERENCES agencies(id) ON DELETE CASCADE,
+    document_type VARCHAR(20) NOT NULL,
+    format VARCHAR(100) NOT NULL DEFAULT '{prefix}-{yyyy}-{seq:04}',
+    reset_yearly BOOLEAN NOT NULL DEFAULT false,
+    -- Year of the last allocation, used to detect the yearly reset
+    sequence_year INTEGER NOT NULL DEFAULT 0,
+
+    CONSTRAINT valid_numbering_document_type CHECK (
+        document_type IN ('proposal', 'contract', 'invoice', 'quotation')
+    )
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_agency_document_numbering_type
+    ON agency_document_numbering(agency_id, document_type);
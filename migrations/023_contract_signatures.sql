@@ -0,0 +1,35 @@
+-- Migration 023: Tamper-evident contract signatures
+-- content_hash is the SHA-256 of the contract content fixed at the first
+-- signature. Each signature is recorded in contract_signatures with a hash
+-- chained to the previous signature, so any later edit to the contract or
+-- to a signature record can be detected.
+
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS agency_signature_ip VARCHAR(50);
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS agency_signature_user_agent TEXT;
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+
+CREATE TABLE IF NOT EXISTS contract_signatures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    contract_id UUID NOT NULL REFERENCES contracts(id) ON DELETE CASCADE,
+    agency_id UUID NOT NULL REFERENCES agencies(id) ON DELETE CASCADE,
+    party VARCHAR(20) NOT NULL,
+    signatory_name VARCHAR(255) NOT NULL,
+    signatory_title VARCHAR(100) NOT NULL DEFAULT '',
+    signed_at TIMESTAMPTZ NOT NULL,
+    ip_address VARCHAR(50) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    -- Set for agency signatures made by a logged in user
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    content_hash VARCHAR(64) NOT NULL,
+    previous_hash VARCHAR(64) NOT NULL DEFAULT '',
+    signature_hash VARCHAR(64) NOT NULL,
+
+    CONSTRAINT valid_signature_party CHECK (party IN ('agency', 'client'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_contract_signatures_contract
+    ON contract_signatures(contract_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contract_signatures_party
+    ON contract_signatures(contract_id, party);
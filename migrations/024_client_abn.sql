@@ -0,0 +1,8 @@
+-- Migration 024: Client ABN
+-- Stores the client's Australian Business Number so duplicate clients can be
+-- detected by ABN as well as by email and business name.
+
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS abn VARCHAR(20) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_clients_abn
+    ON clients(agency_id, abn) WHERE abn <> '';
@@ -0,0 +1,7 @@
+-- Migration 025: Consultation version snapshots
+-- Each consultation version stores the full consultation as it was after the
+-- change, so any version can be restored. changed_fields holds a structured
+-- diff of the change. Versions written before this migration have no
+-- snapshot and cannot be restored.
+
+ALTER TABLE consultation_versions ADD COLUMN IF NOT EXISTS snapshot JSONB;
@@ -0,0 +1,31 @@
+-- Migration 026: Agency form versions
+-- Every published form schema is kept so submissions pinned to an earlier
+-- version can still be rendered and validated. changes holds the field
+-- changes from the version before it. Existing forms are backfilled with
+-- their current schema, and with previous_schema as the version before it.
+
+CREATE TABLE IF NOT EXISTS agency_form_versions (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES agency_forms(id) ON DELETE CASCADE,
+    agency_id UUID NOT NULL REFERENCES agencies(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    schema JSONB NOT NULL,
+    ui_config JSONB NOT NULL DEFAULT '{}',
+    changes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    UNIQUE(form_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_agency_form_versions_agency_id ON agency_form_versions(agency_id);
+
+INSERT INTO agency_form_versions (form_id, agency_id, version, schema, ui_config, created_at, created_by)
+SELECT id, agency_id, version, schema, ui_config, updated_at, created_by
+FROM agency_forms
+ON CONFLICT (form_id, version) DO NOTHING;
+
+INSERT INTO agency_form_versions (form_id, agency_id, version, schema, ui_config, created_at, created_by)
+SELECT id, agency_id, version - 1, previous_schema, ui_config, created_at, created_by
+FROM agency_forms
+WHERE previous_schema IS NOT NULL AND version > 1
+ON CONFLICT (form_id, version) DO NOTHING;
@@ -0,0 +1,13 @@
+-- Migration 027: Form submission processing
+-- Completed submissions are processed into a client and a consultation.
+-- consultation_mapping maps consultation fields to the form fields that
+-- answer them; unmapped forms fall back to matching field names. Failed
+-- processing is recorded on the submission so it can be retried.
+
+ALTER TABLE agency_forms ADD COLUMN IF NOT EXISTS consultation_mapping JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE form_submissions ADD COLUMN IF NOT EXISTS processing_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE form_submissions ADD COLUMN IF NOT EXISTS processing_error TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_form_submissions_unprocessed
+    ON form_submissions(submitted_at) WHERE status = 'completed' AND processed_at IS NULL;
@@ -0,0 +1,27 @@
+-- Migration 028: API keys
+-- Named keys for programmatic access, owned by a user or, when agency_id is
+-- set, by an agency. Only a SHA-256 hash of each key is stored; prefix is
+-- the start of the key, kept so users can tell their keys apart. access is
+-- the key's scopes as an access bitmask, capped at request time by the
+-- creating user's current access.
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    agency_id UUID REFERENCES agencies(id) ON DELETE CASCADE,
+
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    access BIGINT NOT NULL,
+
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_agency_id ON api_keys(agency_id) WHERE agency_id IS NOT NULL;
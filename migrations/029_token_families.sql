@@ -0,0 +1,15 @@
+-- Migration 029: Refresh token families
+-- Every sign-in starts a family of refresh tokens that share the session's
+-- ID. Refreshing marks the presented token rotated and issues the next one
+-- in the family; a rotated token presented again revokes the whole family.
+-- Revoked tokens keep the existing convention of an empty target. The
+-- client columns describe the session in the user's list of sessions.
+
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS family TEXT NOT NULL DEFAULT '';
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS created TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS rotated TIMESTAMPTZ;
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '';
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS ip TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_tokens_family ON tokens(family) WHERE family <> '';
+CREATE INDEX IF NOT EXISTS idx_tokens_target ON tokens(target) WHERE family <> '';
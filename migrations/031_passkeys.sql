@@ -0,0 +1,41 @@
+-- Migration 031: Passkeys
+-- WebAuthn credentials registered by users, used to sign in without email
+-- or as a second factor. credential_id is the base64url credential ID the
+-- authenticator returns; public_key is the credential's public key in
+-- SubjectPublicKeyInfo (DER) form, and algorithm its COSE algorithm.
+-- sign_count is the authenticator's last signature counter, used to detect
+-- cloned authenticators.
+-- passkey_challenges holds the challenge of each ceremony in progress until
+-- it completes or expires. Challenges are kept apart from tokens, which the
+-- magic link and OAuth callbacks accept as state.
+
+CREATE TABLE IF NOT EXISTS passkeys (
+    id UUID PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    name TEXT NOT NULL,
+    credential_id TEXT NOT NULL UNIQUE,
+    public_key BYTEA NOT NULL,
+    algorithm INTEGER NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    transports JSONB NOT NULL DEFAULT '[]',
+
+    last_used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_passkeys_user_id ON passkeys(user_id);
+
+CREATE TABLE IF NOT EXISTS passkey_challenges (
+    id TEXT PRIMARY KEY NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+
+    purpose TEXT NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    return_url TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_passkey_challenges_expires_at ON passkey_challenges(expires_at);
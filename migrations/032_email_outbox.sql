@@ -0,0 +1,24 @@
+-- Migration 032: Email outbox
+-- Emails are queued before they are sent, and a worker pool delivers them
+-- with retries. status follows email_logs: pending until sent, then sent,
+-- or failed once retries are exhausted. retry_count and error_message
+-- record failed attempts, and next_attempt_at when the next is due.
+-- locked_until is the lease of the worker sending an email, after which
+-- another worker may take it over. Existing emails were sent synchronously.
+-- email_log_id links an email sent for an agency to its email_logs entry,
+-- which is kept up to date with the email's delivery. Attachments keep the
+-- ID of their file so it can be fetched at send time.
+
+ALTER TABLE emails ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'sent';
+ALTER TABLE emails ADD COLUMN IF NOT EXISTS retry_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE emails ADD COLUMN IF NOT EXISTS error_message TEXT NOT NULL DEFAULT '';
+ALTER TABLE emails ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE emails ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+ALTER TABLE emails ADD COLUMN IF NOT EXISTS sent_at TIMESTAMPTZ;
+ALTER TABLE emails ADD COLUMN IF NOT EXISTS email_log_id UUID REFERENCES email_logs(id) ON DELETE SET NULL;
+ALTER TABLE emails ALTER COLUMN status SET DEFAULT 'pending';
+
+ALTER TABLE email_attachments ADD COLUMN IF NOT EXISTS file_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_emails_outbox ON emails(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_emails_user_status ON emails(user_id, status);
@@ -0,0 +1,16 @@
+-- Migration 033: Email provider events
+-- Providers report what happened to an email after it was accepted through
+-- signed webhooks, matched on the message ID they returned when it was
+-- sent. resend_message_id held the ID when Resend was the only provider,
+-- so it is renamed and widened for the longer IDs of SES and SendGrid.
+-- email_logs gains the time an email bounced or was marked as spam; the
+-- bounce reason is kept in error_message.
+
+ALTER TABLE email_logs RENAME COLUMN resend_message_id TO provider_message_id;
+ALTER TABLE email_logs ALTER COLUMN provider_message_id TYPE VARCHAR(255);
+ALTER TABLE email_logs ADD COLUMN IF NOT EXISTS bounced_at TIMESTAMPTZ;
+ALTER TABLE email_logs ADD COLUMN IF NOT EXISTS complained_at TIMESTAMPTZ;
+
+ALTER TABLE emails ADD COLUMN IF NOT EXISTS provider_message_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_email_logs_provider_message_id ON email_logs(provider_message_id);
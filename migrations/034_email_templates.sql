@@ -0,0 +1,18 @@
+-- Migration 034: Email templates
+-- Agencies can override the built-in email templates. Each save adds a
+-- version, and the latest version of a template is the one that is sent;
+-- removing all versions goes back to the built-in template. subject is a
+-- text template, and body the HTML content that is placed in the shared
+-- layout.
+
+CREATE TABLE IF NOT EXISTS email_templates (
+    id UUID PRIMARY KEY NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    agency_id UUID NOT NULL REFERENCES agencies(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    UNIQUE (agency_id, name, version)
+);
@@ -0,0 +1,23 @@
+-- Migration 035: Email suppressions
+-- Addresses an agency no longer sends to. Hard bounces and spam complaints
+-- reported by provider webhooks add an address, as does a recipient
+-- unsubscribing; agencies can also add and remove addresses themselves.
+-- email is stored lower case.
+--
+-- Outbox emails record the agency they were sent for, and bulk emails an
+-- unguessable token for their one-click unsubscribe link (RFC 8058).
+
+CREATE TABLE IF NOT EXISTS email_suppressions (
+    id UUID PRIMARY KEY NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    agency_id UUID NOT NULL REFERENCES agencies(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    reason TEXT NOT NULL,  -- 'bounced', 'complained', 'unsubscribed' or 'manual'
+    detail TEXT NOT NULL DEFAULT '',
+    UNIQUE (agency_id, email)
+);
+
+ALTER TABLE emails ADD COLUMN IF NOT EXISTS agency_id UUID REFERENCES agencies(id) ON DELETE SET NULL;
+ALTER TABLE emails ADD COLUMN IF NOT EXISTS unsubscribe_token TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_emails_unsubscribe_token ON emails(unsubscribe_token);
@@ -0,0 +1,12 @@
+-- Migration 036: Grant agency access to existing users
+-- Proposals, invoices, settings, contracts, quotations, clients,
+-- consultations and forms each added access bits (bits 16 to 42, mask
+-- 0x7FFFFFF0000). New users are created with them, but users created
+-- before the bits existed were not, so were refused those endpoints.
+-- Agency roles still limit what each user can do within an agency.
+-- Users with no access (0) are left without.
+
+UPDATE users
+SET access = access | 8796092956672
+WHERE access <> 0
+  AND access & 8796092956672 <> 8796092956672;
@@ -0,0 +1,7 @@
+-- Migration 037: Client creator
+-- Agency members may only change the records they created, so clients
+-- record who created them like the other agency records. Clients created
+-- before this, or from a form submission, have no creator and can only be
+-- changed by agency owners and admins.
+
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id) ON DELETE SET NULL;
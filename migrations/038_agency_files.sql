@@ -0,0 +1,7 @@
+-- Migration 038: Agency files
+-- Generated documents, such as invoice, contract and proposal PDFs, belong
+-- to the agency they were generated for rather than only to the user who
+-- generated them, so the agency's other members can download them. Files
+-- with no agency stay personal to their owner.
+
+ALTER TABLE files ADD COLUMN IF NOT EXISTS agency_id UUID REFERENCES agencies(id) ON DELETE CASCADE;
@@ -0,0 +1,15 @@
+-- Migration 039: TOTP lockout and single-use session tokens
+-- Invalid two-factor codes are counted per user, and enough of them in a
+-- row lock the user's codes for a while. The session token a user
+-- completes sign-in with is recorded once used, so it cannot be replayed;
+-- records are kept only until the token expires.
+
+ALTER TABLE user_totp ADD COLUMN IF NOT EXISTS failed_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_totp ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS used_session_tokens (
+    id UUID PRIMARY KEY NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_used_session_tokens_expires_at ON used_session_tokens(expires_at);
@@ -0,0 +1,48 @@
+/**
+ * E2E helpers for the development mailbox of the admin service
+ *
+ * With EMAIL_PROVIDER=mailbox, core sends every email to the admin's
+ * in-process SMTP server instead of delivering it. These helpers read the
+ * emails back, so tests can follow magic links and check proposal emails.
+ */
+
+import { expect, type APIRequestContext } from "@playwright/test";
+
+const MAILBOX_URL = process.env.MAILBOX_URL ?? "http://localhost:3001";
+
+export type MailboxMessage = {
+	id: string;
+	receivedAt: string;
+	from: string;
+	to: string[];
+	subject: string;
+	headers: Record<string, string[]>;
+	html: string;
+	text: string;
+	attachments: Array<{ filename: string; contentType: string; size: number }>;
+	links: string[];
+};
+
+/** Removes every email, so a test only sees the emails it causes */
+export async function clearMailbox(request: APIRequestContext): Promise<void> {
+	const res = await request.delete(`${MAILBOX_URL}/api/mailbox/messages`);
+	expect(res.ok()).toBeTruthy();
+}
+
+/** Returns the newest email sent to an address, waiting for it to arrive */
+export async function waitForEmail(request: APIRequestContext, to: string): Promise<MailboxMessage> {
+	const res = await request.get(`${MAILBOX_URL}/api/mailbox/messages`, {
+		params: { to, wait: "5s" },
+	});
+	expect(res.ok()).toBeTruthy();
+	const messages: MailboxMessage[] = await res.json();
+	expect(messages, `no email sent to ${to}`).not.toHaveLength(0);
+	return messages[0];
+}
+
+/** Returns the first link in an email that matches a pattern, such as the sign in link */
+export function findLink(message: MailboxMessage, pattern: RegExp): string {
+	const link = message.links.find((l) => pattern.test(l));
+	expect(link, `no link matching ${pattern} in "${message.subject}"`).toBeDefined();
+	return link as string;
+}
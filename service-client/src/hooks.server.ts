@@ -100,7 +100,12 @@ export const handle: Handle = async ({ event, resolve }) => {
 	}
 
 	// Check if we are on the 2FA login page
-	if (event.url.pathname === "/login/phone" || event.url.pathname === "/login/verify") {
+	if (
+		event.url.pathname === "/login/phone" ||
+		event.url.pathname === "/login/verify" ||
+		event.url.pathname === "/login/totp" ||
+		event.url.pathname === "/login/passkey"
+	) {
 		const session_token = event.cookies.get("session_token");
 		if (!session_token) {
 			throw redirect(302, "/login");
@@ -209,6 +209,7 @@ export const createClient = command(CreateClientSchema, async (data) => {
 			phone: data.phone || null,
 			contactName: data.contactName || null,
 			notes: data.notes || null,
+			createdBy: context.userId,
 		})
 		.returning();
 
@@ -669,9 +669,9 @@ export const updateContract = command(UpdateContractSchema, async (data) => {
 		throw new Error("Permission denied");
 	}
 
-	// Only lock after signing - allow edits to sent/viewed contracts
-	if (["signed", "completed"].includes(existing.status)) {
-		throw new Error("Cannot modify signed contract");
+	// Content is frozen once sent, so the client signs what they were shown
+	if (existing.status !== "draft" || existing.contentHash) {
+		throw new Error("Only unsigned draft contracts can be modified");
 	}
 
 	// Build update object
@@ -717,12 +717,24 @@ export const updateContract = command(UpdateContractSchema, async (data) => {
 	if (data.includedScheduleIds !== undefined)
 		updates["includedScheduleIds"] = data.includedScheduleIds;
 
+	// Only applies to the version that was read, so concurrent edits and
+	// signatures cannot overwrite each other
 	const [contract] = await db
 		.update(contracts)
-		.set(updates)
-		.where(eq(contracts.id, data.contractId))
+		.set({ ...updates, version: sql`${contracts.version} + 1` })
+		.where(
+			and(
+				eq(contracts.id, data.contractId),
+				eq(contracts.version, existing.version),
+				eq(contracts.status, "draft"),
+			),
+		)
 		.returning();
 
+	if (!contract) {
+		throw new Error("Contract was changed, please reload and try again");
+	}
+
 	// Log activity
 	await logActivity("contract.updated", "contract", data.contractId, {
 		oldValues: { clientBusinessName: existing.clientBusinessName },
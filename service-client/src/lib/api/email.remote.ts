@@ -368,7 +368,7 @@ export const sendProposalEmail = command(SendProposalEmailSchema, async (data) =
 			.update(emailLogs)
 			.set({
 				status: result.success ? "sent" : "failed",
-				resendMessageId: result.messageId,
+				providerMessageId: result.messageId,
 				sentAt: result.success ? new Date() : null,
 				errorMessage: result.error,
 			})
@@ -509,7 +509,7 @@ export const sendInvoiceEmail = command(SendInvoiceEmailSchema, async (data) =>
 			.update(emailLogs)
 			.set({
 				status: result.success ? "sent" : "failed",
-				resendMessageId: result.messageId,
+				providerMessageId: result.messageId,
 				sentAt: result.success ? new Date() : null,
 				errorMessage: result.error,
 			})
@@ -665,7 +665,7 @@ export const sendInvoiceReminder = command(SendInvoiceReminderSchema, async (dat
 			.update(emailLogs)
 			.set({
 				status: result.success ? "sent" : "failed",
-				resendMessageId: result.messageId,
+				providerMessageId: result.messageId,
 				sentAt: result.success ? new Date() : null,
 				errorMessage: result.error,
 			})
@@ -791,7 +791,7 @@ export const sendContractEmail = command(SendContractEmailSchema, async (data) =
 			.update(emailLogs)
 			.set({
 				status: result.success ? "sent" : "failed",
-				resendMessageId: result.messageId,
+				providerMessageId: result.messageId,
 				sentAt: result.success ? new Date() : null,
 				errorMessage: result.error,
 			})
@@ -929,7 +929,7 @@ export const sendQuotationEmail = command(SendQuotationEmailSchema, async (data)
 			.update(emailLogs)
 			.set({
 				status: result.success ? "sent" : "failed",
-				resendMessageId: result.messageId,
+				providerMessageId: result.messageId,
 				sentAt: result.success ? new Date() : null,
 				errorMessage: result.error,
 			})
@@ -1016,7 +1016,7 @@ export const resendEmail = command(ResendEmailSchema, async (data) => {
 			.update(emailLogs)
 			.set({
 				status: result.success ? "sent" : "failed",
-				resendMessageId: result.messageId,
+				providerMessageId: result.messageId,
 				sentAt: result.success ? new Date() : null,
 				errorMessage: result.error,
 			})
@@ -1157,7 +1157,7 @@ export const sendFormEmail = command(SendFormEmailSchema, async (data) => {
 			.update(emailLogs)
 			.set({
 				status: result.success ? "sent" : "failed",
-				resendMessageId: result.messageId,
+				providerMessageId: result.messageId,
 				sentAt: result.success ? new Date() : null,
 				errorMessage: result.error,
 			})
@@ -55,8 +55,10 @@ const InvoiceStatusSchema = v.picklist([
 	"draft",
 	"sent",
 	"viewed",
+	"partially_paid",
 	"paid",
 	"overdue",
+	"void",
 	"cancelled",
 	"refunded",
 ]);
@@ -1522,7 +1524,7 @@ export const recordPayment = command(RecordPaymentSchema, async (data) => {
 					subject: clientEmail.subject,
 					bodyHtml: clientEmail.bodyHtml,
 					status: clientResult.success ? "sent" : "failed",
-					resendMessageId: clientResult.messageId || null,
+					providerMessageId: clientResult.messageId || null,
 				});
 			}
 
@@ -1544,7 +1546,7 @@ export const recordPayment = command(RecordPaymentSchema, async (data) => {
 					subject: agencyNotification.subject,
 					bodyHtml: agencyNotification.bodyHtml,
 					status: agencyResult.success ? "sent" : "failed",
-					resendMessageId: agencyResult.messageId || null,
+					providerMessageId: agencyResult.messageId || null,
 				});
 			}
 		})
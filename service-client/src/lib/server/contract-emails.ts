@@ -118,7 +118,7 @@ export async function sendContractEmailToClient(
 			.update(emailLogs)
 			.set({
 				status: result.success ? "sent" : "failed",
-				resendMessageId: result.messageId,
+				providerMessageId: result.messageId,
 				sentAt: result.success ? new Date() : null,
 				errorMessage: result.error,
 			})
@@ -199,7 +199,7 @@ export async function sendContractSignedEmails(
 			bodyHtml: clientEmail.bodyHtml,
 			hasAttachment: false,
 			status: clientResult.success ? "sent" : "failed",
-			resendMessageId: clientResult.messageId,
+			providerMessageId: clientResult.messageId,
 			sentAt: clientResult.success ? new Date() : null,
 			errorMessage: clientResult.error,
 		});
@@ -224,7 +224,7 @@ export async function sendContractSignedEmails(
 				bodyHtml: agencyEmail.bodyHtml,
 				hasAttachment: false,
 				status: agencyResult.success ? "sent" : "failed",
-				resendMessageId: agencyResult.messageId,
+				providerMessageId: agencyResult.messageId,
 				sentAt: agencyResult.success ? new Date() : null,
 				errorMessage: agencyResult.error,
 			});
@@ -18,6 +18,7 @@ import {
 	unique,
 	decimal,
 	index,
+	customType,
 } from "drizzle-orm/pg-core";
 import type { RawFormSchema } from "$lib/types/form-builder";
 
@@ -333,6 +334,104 @@ export const agencyProfiles = pgTable("agency_profiles", {
 	onboardingCompletedAt: timestamp("onboarding_completed_at", { withTimezone: true }),
 });
 
+// Agency Document Numbering table - Number format per document type (counters stay on agency_profiles)
+export const agencyDocumentNumbering = pgTable(
+	"agency_document_numbering",
+	{
+		id: uuid("id").primaryKey().defaultRandom(),
+		createdAt: timestamp("created_at", { withTimezone: true }).notNull().defaultNow(),
+		updatedAt: timestamp("updated_at", { withTimezone: true }).notNull().defaultNow(),
+
+		agencyId: uuid("agency_id")
+			.notNull()
+			.references(() => agencies.id, { onDelete: "cascade" }),
+		documentType: varchar("document_type", { length: 20 }).notNull(), // proposal, contract, invoice, quotation
+		format: varchar("format", { length: 100 }).notNull().default("{prefix}-{yyyy}-{seq:04}"),
+		resetYearly: boolean("reset_yearly").notNull().default(false),
+		sequenceYear: integer("sequence_year").notNull().default(0), // Year of the last allocation
+	},
+	(table) => ({
+		uniqueAgencyDocumentType: unique().on(table.agencyId, table.documentType),
+	}),
+);
+
+// API Keys table - Hashed keys for programmatic access (migration 028)
+// Keys with an agencyId belong to the agency, others to the user
+export const apiKeys = pgTable("api_keys", {
+	id: uuid("id").primaryKey().defaultRandom(),
+	createdAt: timestamp("created_at", { withTimezone: true }).notNull().defaultNow(),
+	updatedAt: timestamp("updated_at", { withTimezone: true }).notNull().defaultNow(),
+
+	userId: uuid("user_id")
+		.notNull()
+		.references(() => users.id, { onDelete: "cascade" }),
+	agencyId: uuid("agency_id").references(() => agencies.id, { onDelete: "cascade" }),
+
+	name: text("name").notNull(),
+	prefix: text("prefix").notNull(), // Start of the key, for display
+	keyHash: text("key_hash").notNull().unique(), // SHA-256 of the key
+	access: bigint("access", { mode: "number" }).notNull(), // Scopes as an access bitmask
+
+	expiresAt: timestamp("expires_at", { withTimezone: true }),
+	lastUsedAt: timestamp("last_used_at", { withTimezone: true }),
+	revokedAt: timestamp("revoked_at", { withTimezone: true }),
+});
+
+// User TOTP table - Authenticator app enrolment (migration 030)
+// The secret is pending until enabledAt is set by confirming a code
+export const userTotp = pgTable("user_totp", {
+	userId: uuid("user_id")
+		.primaryKey()
+		.references(() => users.id, { onDelete: "cascade" }),
+	createdAt: timestamp("created_at", { withTimezone: true }).notNull().defaultNow(),
+	updatedAt: timestamp("updated_at", { withTimezone: true }).notNull().defaultNow(),
+
+	secret: text("secret").notNull(), // TOTP secret, encrypted with core's TOTP_ENCRYPTION_KEY
+	enabledAt: timestamp("enabled_at", { withTimezone: true }),
+	lastStep: bigint("last_step", { mode: "number" }).notNull().default(0), // Time step of the last accepted code
+	failedAttempts: integer("failed_attempts").notNull().default(0), // Invalid codes in a row (migration 039)
+	lockedUntil: timestamp("locked_until", { withTimezone: true }), // No codes are accepted until then (migration 039)
+});
+
+// User Recovery Codes table - Single-use two-factor recovery codes (migration 030)
+export const userRecoveryCodes = pgTable("user_recovery_codes", {
+	id: uuid("id").primaryKey().defaultRandom(),
+	createdAt: timestamp("created_at", { withTimezone: true }).notNull().defaultNow(),
+
+	userId: uuid("user_id")
+		.notNull()
+		.references(() => users.id, { onDelete: "cascade" }),
+	codeHash: text("code_hash").notNull(), // SHA-256 of the code
+	usedAt: timestamp("used_at", { withTimezone: true }),
+});
+
+// Binary column, for passkey public keys
+const bytea = customType<{ data: Buffer }>({
+	dataType() {
+		return "bytea";
+	},
+});
+
+// Passkeys table - WebAuthn credentials for sign-in and second factor (migration 031)
+export const passkeys = pgTable("passkeys", {
+	id: uuid("id").primaryKey().defaultRandom(),
+	createdAt: timestamp("created_at", { withTimezone: true }).notNull().defaultNow(),
+	updatedAt: timestamp("updated_at", { withTimezone: true }).notNull().defaultNow(),
+
+	userId: uuid("user_id")
+		.notNull()
+		.references(() => users.id, { onDelete: "cascade" }),
+
+	name: text("name").notNull(),
+	credentialId: text("credential_id").notNull().unique(), // Base64url credential ID
+	publicKey: bytea("public_key").notNull(), // SubjectPublicKeyInfo (DER)
+	algorithm: integer("algorithm").notNull(), // COSE algorithm
+	signCount: bigint("sign_count", { mode: "number" }).notNull().default(0), // Last authenticator signature counter
+	transports: jsonb("transports").$type<string[]>().notNull().default([]),
+
+	lastUsedAt: timestamp("last_used_at", { withTimezone: true }),
+});
+
 // Agency Packages table - Configurable pricing tiers per agency
 export const agencyPackages = pgTable(
 	"agency_packages",
@@ -489,6 +588,9 @@ export const agencyForms = pgTable(
 		isCustomized: boolean("is_customized").notNull().default(false),
 		previousSchema: jsonb("previous_schema"),
 
+		// Submission Processing (consultation field -> form field name)
+		consultationMapping: jsonb("consultation_mapping").$type<Record<string, string>>().notNull().default({}),
+
 		// Metadata
 		version: integer("version").notNull().default(1),
 		createdAt: timestamp("created_at", { withTimezone: true }).notNull().defaultNow(),
@@ -502,6 +604,36 @@ export const agencyForms = pgTable(
 	}),
 );
 
+// Agency Form Versions table - Published form schemas, kept for submissions
+// pinned to an earlier version
+export const agencyFormVersions = pgTable(
+	"agency_form_versions",
+	{
+		id: uuid("id").primaryKey().defaultRandom(),
+		formId: uuid("form_id")
+			.notNull()
+			.references(() => agencyForms.id, { onDelete: "cascade" }),
+		agencyId: uuid("agency_id")
+			.notNull()
+			.references(() => agencies.id, { onDelete: "cascade" }),
+		version: integer("version").notNull(),
+
+		// Schema as published
+		schema: jsonb("schema").$type<RawFormSchema>().notNull(),
+		uiConfig: jsonb("ui_config").notNull().default({}),
+
+		// Field changes from the previous version
+		changes: jsonb("changes").notNull().default([]),
+
+		createdAt: timestamp("created_at", { withTimezone: true }).notNull().defaultNow(),
+		createdBy: uuid("created_by").references(() => users.id, { onDelete: "set null" }),
+	},
+	(table) => ({
+		uniqueFormVersion: unique().on(table.formId, table.version),
+		agencyIdx: index("agency_form_versions_agency_idx").on(table.agencyId),
+	}),
+);
+
 // Clients table - Client information per agency
 export const clients = pgTable(
 	"clients",
@@ -517,6 +649,7 @@ export const clients = pgTable(
 		phone: varchar("phone", { length: 50 }),
 		contactName: text("contact_name"),
 		notes: text("notes"),
+		abn: varchar("abn", { length: 20 }).notNull().default(""),
 
 		// Status: 'active' | 'archived'
 		status: varchar("status", { length: 20 }).notNull().default("active"),
@@ -524,6 +657,7 @@ export const clients = pgTable(
 		// Metadata
 		createdAt: timestamp("created_at", { withTimezone: true }).notNull().defaultNow(),
 		updatedAt: timestamp("updated_at", { withTimezone: true }).notNull().defaultNow(),
+		createdBy: uuid("created_by").references(() => users.id, { onDelete: "set null" }), // Who created the client (migration 037)
 	},
 	(table) => ({
 		uniqueAgencyEmail: unique().on(table.agencyId, table.email),
@@ -580,6 +714,10 @@ export const formSubmissions = pgTable(
 
 		// Form version at time of submission (for schema evolution)
 		formVersion: integer("form_version").notNull().default(1),
+
+		// Processing retries
+		processingAttempts: integer("processing_attempts").notNull().default(0),
+		processingError: text("processing_error").notNull().default(""),
 	},
 	(table) => ({
 		formIdx: index("form_submissions_form_idx").on(table.formId),
@@ -913,6 +1051,8 @@ export const contracts = pgTable(
 		agencySignatoryName: varchar("agency_signatory_name", { length: 255 }),
 		agencySignatoryTitle: varchar("agency_signatory_title", { length: 100 }),
 		agencySignedAt: timestamp("agency_signed_at", { withTimezone: true }),
+		agencySignatureIp: varchar("agency_signature_ip", { length: 50 }),
+		agencySignatureUserAgent: text("agency_signature_user_agent"),
 
 		// Client signature
 		clientSignatoryName: varchar("client_signatory_name", { length: 255 }),
@@ -947,6 +1087,9 @@ export const contracts = pgTable(
 
 		// Creator
 		createdBy: uuid("created_by").references(() => users.id, { onDelete: "set null" }),
+
+		// SHA-256 of the signed content, fixed at the first signature
+		contentHash: varchar("content_hash", { length: 64 }),
 	},
 	(table) => ({
 		agencyIdx: index("contracts_agency_idx").on(table.agencyId),
@@ -959,6 +1102,38 @@ export const contracts = pgTable(
 	}),
 );
 
+// Contract Signatures table - Hash-chained signing records (one per party)
+export const contractSignatures = pgTable(
+	"contract_signatures",
+	{
+		id: uuid("id").primaryKey().defaultRandom(),
+		createdAt: timestamp("created_at", { withTimezone: true }).notNull().defaultNow(),
+
+		contractId: uuid("contract_id")
+			.notNull()
+			.references(() => contracts.id, { onDelete: "cascade" }),
+		agencyId: uuid("agency_id")
+			.notNull()
+			.references(() => agencies.id, { onDelete: "cascade" }),
+		party: varchar("party", { length: 20 }).notNull(), // agency, client
+		signatoryName: varchar("signatory_name", { length: 255 }).notNull(),
+		signatoryTitle: varchar("signatory_title", { length: 100 }).notNull().default(""),
+		signedAt: timestamp("signed_at", { withTimezone: true }).notNull(),
+		ipAddress: varchar("ip_address", { length: 50 }).notNull().default(""),
+		userAgent: text("user_agent").notNull().default(""),
+		userId: uuid("user_id").references(() => users.id, { onDelete: "set null" }),
+
+		// Hash chain
+		contentHash: varchar("content_hash", { length: 64 }).notNull(),
+		previousHash: varchar("previous_hash", { length: 64 }).notNull().default(""),
+		signatureHash: varchar("signature_hash", { length: 64 }).notNull(),
+	},
+	(table) => ({
+		contractIdx: index("idx_contract_signatures_contract").on(table.contractId),
+		uniqueContractParty: unique("idx_contract_signatures_party").on(table.contractId, table.party),
+	}),
+);
+
 // =============================================================================
 // INVOICES (V2 Document Generation)
 // =============================================================================
@@ -986,7 +1161,7 @@ export const invoices = pgTable(
 		invoiceNumber: varchar("invoice_number", { length: 50 }).notNull(),
 		slug: varchar("slug", { length: 100 }).notNull().unique(),
 
-		// Status workflow: draft, sent, viewed, paid, overdue, cancelled, refunded
+		// Status workflow: draft, sent, viewed, partially_paid, paid, overdue, void (legacy: cancelled, refunded)
 		status: varchar("status", { length: 50 }).notNull().default("draft"),
 
 		// Client info (snapshot at invoice creation)
@@ -1032,6 +1207,7 @@ export const invoices = pgTable(
 		paymentMethod: varchar("payment_method", { length: 50 }), // bank_transfer, card, cash, other
 		paymentReference: text("payment_reference"), // Transaction ID, cheque number, etc.
 		paymentNotes: text("payment_notes"),
+		amountPaid: decimal("amount_paid", { precision: 10, scale: 2 }).notNull().default("0.00"),
 
 		// PDF storage
 		pdfUrl: text("pdf_url"),
@@ -1132,16 +1308,18 @@ export const emailLogs = pgTable(
 		hasAttachment: boolean("has_attachment").notNull().default(false),
 		attachmentFilename: varchar("attachment_filename", { length: 255 }),
 
-		// Resend tracking
-		resendMessageId: varchar("resend_message_id", { length: 100 }),
+		// Provider tracking
+		providerMessageId: varchar("provider_message_id", { length: 255 }),
 
 		// Status
 		status: varchar("status", { length: 50 }).notNull().default("pending"),
-		// Values: 'pending', 'sent', 'delivered', 'opened', 'bounced', 'failed'
+		// Values: 'pending', 'sent', 'delivered', 'opened', 'bounced', 'complained', 'failed'
 
 		sentAt: timestamp("sent_at", { withTimezone: true }),
 		deliveredAt: timestamp("delivered_at", { withTimezone: true }),
 		openedAt: timestamp("opened_at", { withTimezone: true }),
+		bouncedAt: timestamp("bounced_at", { withTimezone: true }),
+		complainedAt: timestamp("complained_at", { withTimezone: true }),
 
 		// Error handling
 		errorMessage: text("error_message"),
@@ -1545,6 +1723,8 @@ export const consultationVersions = pgTable(
 		// Version metadata
 		changeSummary: text("change_summary"),
 		changedFields: jsonb("changed_fields").notNull().default([]),
+		// Full consultation after the change, used to restore the version
+		snapshot: jsonb("snapshot"),
 
 		// Timestamps
 		createdAt: timestamp("created_at", { withTimezone: true }).notNull().defaultNow(),
@@ -1593,6 +1773,19 @@ export type FreemiumReason =
 export type AgencyProfile = typeof agencyProfiles.$inferSelect;
 export type AgencyProfileInsert = typeof agencyProfiles.$inferInsert;
 
+// Agency Document Numbering types
+export type AgencyDocumentNumbering = typeof agencyDocumentNumbering.$inferSelect;
+
+// API Key types
+export type ApiKey = typeof apiKeys.$inferSelect;
+
+// Two-factor types
+export type UserTotp = typeof userTotp.$inferSelect;
+export type UserRecoveryCode = typeof userRecoveryCodes.$inferSelect;
+
+// Passkey types
+export type Passkey = typeof passkeys.$inferSelect;
+
 // Agency Package types
 export type AgencyPackage = typeof agencyPackages.$inferSelect;
 export type AgencyPackageInsert = typeof agencyPackages.$inferInsert;
@@ -1665,6 +1858,7 @@ export type ContractScheduleInsert = typeof contractSchedules.$inferInsert;
 // Contract types
 export type Contract = typeof contracts.$inferSelect;
 export type ContractInsert = typeof contracts.$inferInsert;
+export type ContractSignature = typeof contractSignatures.$inferSelect;
 export type ContractStatus =
 	| "draft"
 	| "sent"
@@ -1683,8 +1877,10 @@ export type InvoiceStatus =
 	| "draft"
 	| "sent"
 	| "viewed"
+	| "partially_paid"
 	| "paid"
 	| "overdue"
+	| "void"
 	| "cancelled"
 	| "refunded";
 export type InvoicePaymentMethod = "bank_transfer" | "card" | "cash" | "other";
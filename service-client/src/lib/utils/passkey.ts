@@ -0,0 +1,58 @@
+import { env } from "$env/dynamic/public";
+
+type JSONCredentialStatic = {
+	parseRequestOptionsFromJSON(options: unknown): PublicKeyCredentialRequestOptions;
+};
+
+/**
+ * Whether the browser can sign in with passkeys
+ */
+export function passkeysSupported(): boolean {
+	return (
+		typeof window !== "undefined" &&
+		"PublicKeyCredential" in window &&
+		"parseRequestOptionsFromJSON" in PublicKeyCredential
+	);
+}
+
+/**
+ * Fetches request options from a core endpoint, asks the browser for a
+ * passkey and returns the signed assertion in its JSON form
+ */
+export async function getPasskeyAssertion(path: string, body: unknown = {}): Promise<unknown> {
+	const res = await fetch(env.PUBLIC_CORE_URL + path, {
+		method: "POST",
+		credentials: "include",
+		headers: { "Content-Type": "application/json" },
+		body: JSON.stringify(body),
+	});
+	if (!res.ok) {
+		throw new Error("Could not start passkey sign-in");
+	}
+	const parser = PublicKeyCredential as unknown as JSONCredentialStatic;
+	const options = parser.parseRequestOptionsFromJSON(await res.json());
+	const credential = (await navigator.credentials.get({
+		publicKey: options,
+	})) as PublicKeyCredential | null;
+	if (!credential) {
+		throw new Error("No passkey was chosen");
+	}
+	return credential.toJSON();
+}
+
+/**
+ * Posts a passkey assertion to a core sign-in endpoint, which sets the
+ * session cookies
+ */
+export async function postPasskeyAssertion(path: string, assertion: unknown): Promise<Response> {
+	const res = await fetch(env.PUBLIC_CORE_URL + path, {
+		method: "POST",
+		credentials: "include",
+		headers: { "Content-Type": "application/json" },
+		body: JSON.stringify(assertion),
+	});
+	if (!res.ok) {
+		throw new Error("Passkey sign-in failed");
+	}
+	return res;
+}
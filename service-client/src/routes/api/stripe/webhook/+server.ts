@@ -348,7 +348,7 @@ async function sendInvoicePaidNotifications(
 			subject: clientEmail.subject,
 			bodyHtml: clientEmail.bodyHtml,
 			status: clientResult.success ? "sent" : "failed",
-			resendMessageId: clientResult.messageId || null,
+			providerMessageId: clientResult.messageId || null,
 		});
 	}
 
@@ -370,7 +370,7 @@ async function sendInvoicePaidNotifications(
 			subject: agencyNotification.subject,
 			bodyHtml: agencyNotification.bodyHtml,
 			status: agencyResult.success ? "sent" : "failed",
-			resendMessageId: agencyResult.messageId || null,
+			providerMessageId: agencyResult.messageId || null,
 		});
 	}
 }
@@ -213,7 +213,7 @@ export const actions: Actions = {
 						subject: clientEmail.subject,
 						bodyHtml: clientEmail.bodyHtml,
 						status: clientResult.success ? "sent" : "failed",
-						resendMessageId: clientResult.messageId || null,
+						providerMessageId: clientResult.messageId || null,
 					});
 				}
 
@@ -237,7 +237,7 @@ export const actions: Actions = {
 						subject: agencyNotification.subject,
 						bodyHtml: agencyNotification.bodyHtml,
 						status: agencyResult.success ? "sent" : "failed",
-						resendMessageId: agencyResult.messageId || null,
+						providerMessageId: agencyResult.messageId || null,
 					});
 				}
 			})
@@ -180,7 +180,7 @@ export const actions: Actions = {
 						subject: emailTemplate.subject,
 						bodyHtml: emailTemplate.bodyHtml,
 						status: emailResult.success ? "sent" : "failed",
-						resendMessageId: emailResult.messageId || null,
+						providerMessageId: emailResult.messageId || null,
 					});
 				})
 				.catch((err) => {
@@ -260,7 +260,7 @@ export const actions: Actions = {
 						subject: emailTemplate.subject,
 						bodyHtml: emailTemplate.bodyHtml,
 						status: emailResult.success ? "sent" : "failed",
-						resendMessageId: emailResult.messageId || null,
+						providerMessageId: emailResult.messageId || null,
 					});
 				})
 				.catch((err) => {
@@ -340,7 +340,7 @@ export const actions: Actions = {
 						subject: emailTemplate.subject,
 						bodyHtml: emailTemplate.bodyHtml,
 						status: emailResult.success ? "sent" : "failed",
-						resendMessageId: emailResult.messageId || null,
+						providerMessageId: emailResult.messageId || null,
 					});
 				})
 				.catch((err) => {